@@ -33,18 +33,24 @@ import (
 	"github.com/horizoncd/horizon/core/config"
 	accessctl "github.com/horizoncd/horizon/core/controller/access"
 	accesstokenctl "github.com/horizoncd/horizon/core/controller/accesstoken"
+	admissionpolicyctl "github.com/horizoncd/horizon/core/controller/admissionpolicy"
+	analyticsctl "github.com/horizoncd/horizon/core/controller/analytics"
 	applicationctl "github.com/horizoncd/horizon/core/controller/application"
 	applicationregionctl "github.com/horizoncd/horizon/core/controller/applicationregion"
 	badgectl "github.com/horizoncd/horizon/core/controller/badge"
 	"github.com/horizoncd/horizon/core/controller/build"
+	cloudeventctl "github.com/horizoncd/horizon/core/controller/cloudevent"
 	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
 	codectl "github.com/horizoncd/horizon/core/controller/code"
+	deploywindowctl "github.com/horizoncd/horizon/core/controller/deploywindow"
 	environmentctl "github.com/horizoncd/horizon/core/controller/environment"
 	environmentregionctl "github.com/horizoncd/horizon/core/controller/environmentregion"
 	envtemplatectl "github.com/horizoncd/horizon/core/controller/envtemplate"
 	eventctl "github.com/horizoncd/horizon/core/controller/event"
 	groupctl "github.com/horizoncd/horizon/core/controller/group"
+	hookctl "github.com/horizoncd/horizon/core/controller/hook"
 	idpctl "github.com/horizoncd/horizon/core/controller/idp"
+	imagectl "github.com/horizoncd/horizon/core/controller/image"
 	memberctl "github.com/horizoncd/horizon/core/controller/member"
 	oauthservicectl "github.com/horizoncd/horizon/core/controller/oauth"
 	oauthappctl "github.com/horizoncd/horizon/core/controller/oauthapp"
@@ -52,10 +58,13 @@ import (
 	prctl "github.com/horizoncd/horizon/core/controller/pipelinerun"
 	regionctl "github.com/horizoncd/horizon/core/controller/region"
 	registryctl "github.com/horizoncd/horizon/core/controller/registry"
+	releasetrainctl "github.com/horizoncd/horizon/core/controller/releasetrain"
 	roltctl "github.com/horizoncd/horizon/core/controller/role"
 	scopectl "github.com/horizoncd/horizon/core/controller/scope"
 	tagctl "github.com/horizoncd/horizon/core/controller/tag"
+	teamctl "github.com/horizoncd/horizon/core/controller/team"
 	templatectl "github.com/horizoncd/horizon/core/controller/template"
+	templatemigrationctl "github.com/horizoncd/horizon/core/controller/templatemigration"
 	templateschematagctl "github.com/horizoncd/horizon/core/controller/templateschematag"
 	terminalctl "github.com/horizoncd/horizon/core/controller/terminal"
 	userctl "github.com/horizoncd/horizon/core/controller/user"
@@ -84,23 +93,31 @@ import (
 	"github.com/horizoncd/horizon/core/http/api/v1/template"
 	accessv2 "github.com/horizoncd/horizon/core/http/api/v2/access"
 	accesstokenv2 "github.com/horizoncd/horizon/core/http/api/v2/accesstoken"
+	admissionpolicyv2 "github.com/horizoncd/horizon/core/http/api/v2/admissionpolicy"
+	analyticsv2 "github.com/horizoncd/horizon/core/http/api/v2/analytics"
 	applicationregionv2 "github.com/horizoncd/horizon/core/http/api/v2/applicationregion"
 	"github.com/horizoncd/horizon/core/http/api/v2/badge"
 	clusterv2 "github.com/horizoncd/horizon/core/http/api/v2/cluster"
 	codev2 "github.com/horizoncd/horizon/core/http/api/v2/code"
+	deploywindowv2 "github.com/horizoncd/horizon/core/http/api/v2/deploywindow"
 	environmentv2 "github.com/horizoncd/horizon/core/http/api/v2/environment"
 	environmentregionv2 "github.com/horizoncd/horizon/core/http/api/v2/environmentregion"
 	eventv2 "github.com/horizoncd/horizon/core/http/api/v2/event"
 	groupv2 "github.com/horizoncd/horizon/core/http/api/v2/group"
+	hookv2 "github.com/horizoncd/horizon/core/http/api/v2/hook"
 	idpv2 "github.com/horizoncd/horizon/core/http/api/v2/idp"
+	imagev2 "github.com/horizoncd/horizon/core/http/api/v2/image"
 	memberv2 "github.com/horizoncd/horizon/core/http/api/v2/member"
 	oauthappv2 "github.com/horizoncd/horizon/core/http/api/v2/oauthapp"
 	pipelinerunv2 "github.com/horizoncd/horizon/core/http/api/v2/pipelinerun"
 	regionv2 "github.com/horizoncd/horizon/core/http/api/v2/region"
 	registryv2 "github.com/horizoncd/horizon/core/http/api/v2/registry"
+	releasetrainv2 "github.com/horizoncd/horizon/core/http/api/v2/releasetrain"
 	rolev2 "github.com/horizoncd/horizon/core/http/api/v2/role"
 	scopev2 "github.com/horizoncd/horizon/core/http/api/v2/scope"
 	tagv2 "github.com/horizoncd/horizon/core/http/api/v2/tag"
+	teamv2 "github.com/horizoncd/horizon/core/http/api/v2/team"
+	templatemigrationv2 "github.com/horizoncd/horizon/core/http/api/v2/templatemigration"
 	templateschematagv2 "github.com/horizoncd/horizon/core/http/api/v2/templateschematag"
 	terminalv2 "github.com/horizoncd/horizon/core/http/api/v2/terminal"
 	userv2 "github.com/horizoncd/horizon/core/http/api/v2/user"
@@ -108,20 +125,32 @@ import (
 	"github.com/horizoncd/horizon/core/middleware"
 	"github.com/horizoncd/horizon/core/middleware/auth"
 	"github.com/horizoncd/horizon/core/middleware/requestid"
+	gitlib "github.com/horizoncd/horizon/lib/git"
 	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	accessauditrecorder "github.com/horizoncd/horizon/pkg/accessaudit/recorder"
 	"github.com/horizoncd/horizon/pkg/admission"
+	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
 	"github.com/horizoncd/horizon/pkg/cd"
 	clustermetrcis "github.com/horizoncd/horizon/pkg/cluster/metrics"
 	admissionconfig "github.com/horizoncd/horizon/pkg/config/admission"
+	gitlabconfig "github.com/horizoncd/horizon/pkg/config/gitlab"
+	deploywindowservice "github.com/horizoncd/horizon/pkg/deploywindow/service"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	"github.com/horizoncd/horizon/pkg/grafana"
+	"github.com/horizoncd/horizon/pkg/hook"
 	"github.com/horizoncd/horizon/pkg/jobs"
 	"github.com/horizoncd/horizon/pkg/jobs/autofree"
+	"github.com/horizoncd/horizon/pkg/jobs/cipoller"
 	"github.com/horizoncd/horizon/pkg/jobs/clean"
 	"github.com/horizoncd/horizon/pkg/jobs/eventhandler"
 	"github.com/horizoncd/horizon/pkg/jobs/grafanasync"
+	"github.com/horizoncd/horizon/pkg/jobs/imageretention"
 	"github.com/horizoncd/horizon/pkg/jobs/k8sevent"
+	"github.com/horizoncd/horizon/pkg/jobs/promotion"
+	"github.com/horizoncd/horizon/pkg/jobs/releasetrain"
+	"github.com/horizoncd/horizon/pkg/jobs/schedule"
+	"github.com/horizoncd/horizon/pkg/jobs/templatemigration"
 	jobwebhook "github.com/horizoncd/horizon/pkg/jobs/webhook"
 	prservice "github.com/horizoncd/horizon/pkg/pr/service"
 	"github.com/horizoncd/horizon/pkg/regioninformers"
@@ -172,6 +201,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
 	templateschemarepo "github.com/horizoncd/horizon/pkg/templaterelease/schema/repo"
+	templatevalidate "github.com/horizoncd/horizon/pkg/templaterelease/validate"
 	"github.com/horizoncd/horizon/pkg/templaterepo"
 	userservice "github.com/horizoncd/horizon/pkg/user/service"
 	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
@@ -236,6 +266,24 @@ func InitAdmissionWebhook(config admissionconfig.Admission) {
 	admission.NewHTTPWebhooks(config)
 }
 
+// newGitopsRepoLib returns the backend which stores gitops repos according to its kind
+func newGitopsRepoLib(config gitlabconfig.GitopsRepoConfig) (gitlablib.Interface, error) {
+	switch config.Kind {
+	case "", gitlabconfig.KindGitlab:
+		return gitlablib.New(config.Token, config.URL)
+	case gitlabconfig.KindGit:
+		return gitlib.New(gitlib.Config{
+			RootDir:       config.RootDir,
+			URL:           config.URL,
+			Username:      config.Username,
+			Token:         config.Token,
+			DefaultBranch: config.DefaultBranch,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported kind of gitops repo: %s", config.Kind)
+	}
+}
+
 func InitLog(flags *Flags) {
 	if flags.Environment == "production" {
 		logrus.SetFormatter(&logrus.JSONFormatter{})
@@ -327,7 +375,10 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	// init manager parameter
 	manager := managerparam.InitManager(mysqlDB)
 
-	gitlabGitops, err := gitlablib.New(coreConfig.GitopsRepoConfig.Token, coreConfig.GitopsRepoConfig.URL)
+	// evaluate the admission policies in db besides the http webhooks
+	admission.Register(admissionmodels.KindValidating, admission.NewPolicyWebhook(manager))
+
+	gitlabGitops, err := newGitopsRepoLib(coreConfig.GitopsRepoConfig)
 	if err != nil {
 		panic(err)
 	}
@@ -366,6 +417,11 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 
 	templateSchemaGetter := templateschemarepo.NewSchemaGetter(ctx, templateRepo, manager)
 
+	templateValidator, err := templatevalidate.NewValidator(coreConfig.TemplateValidation)
+	if err != nil {
+		panic(err)
+	}
+
 	outputGetter, err := output.NewOutPutGetter(ctx, templateRepo, manager)
 	if err != nil {
 		panic(err)
@@ -388,12 +444,23 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		coreConfig.Oauth.AccessTokenExpireIn,
 		coreConfig.Oauth.RefreshTokenExpireIn)
 
-	roleService, err := role.NewFileRoleFrom2(context.TODO(), roleConfig)
-	if err != nil {
-		panic(err)
+	var roleService role.Service
+	if coreConfig.RoleStore.Database {
+		dbRoleService, err := role.NewDBRoleService(ctx, manager.RoleMgr, roleConfig, coreConfig.RoleStore)
+		if err != nil {
+			panic(err)
+		}
+		// every replica reloads roles by itself, so it's not a job run in the single instance
+		go dbRoleService.Sync(ctx)
+		roleService = dbRoleService
+	} else {
+		roleService, err = role.NewFileRoleFrom2(context.TODO(), roleConfig)
+		if err != nil {
+			panic(err)
+		}
 	}
 	mservice := memberservice.NewService(roleService, oauthManager, manager)
-	rbacAuthorizer := rbac.NewAuthorizer(roleService, mservice)
+	rbacAuthorizer := rbac.NewAuthorizer(roleService, mservice, manager.TokenMgr)
 
 	// init scope service
 	scopeFile, err := os.OpenFile(flags.ScopeRoleFile, os.O_RDONLY, 0644)
@@ -445,7 +512,11 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	applicationSvc := applicationservice.NewService(groupSvc, manager)
 	clusterSvc := clusterservice.NewService(applicationSvc, clusterGitRepo, manager)
 	userSvc := userservice.NewService(manager)
-	tokenSvc := tokenservice.NewService(manager, coreConfig.TokenConfig)
+	deployWindowSvc := deploywindowservice.NewService(manager)
+	tokenSvc, err := tokenservice.NewService(manager, coreConfig.TokenConfig)
+	if err != nil {
+		panic(err)
+	}
 
 	// init kube client
 	_, client, err := kube.BuildClient(coreConfig.KubeConfig)
@@ -468,10 +539,12 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		EventSvc:             eventSvc,
 		UserSvc:              userSvc,
 		TokenSvc:             tokenSvc,
+		DeployWindowSvc:      deployWindowSvc,
 		RoleService:          roleService,
 		ScopeService:         scopeService,
 		ApplicationGitRepo:   applicationGitRepo,
 		TemplateSchemaGetter: templateSchemaGetter,
+		TemplateValidator:    templateValidator,
 		CD: cd.NewCD(regionInformers, clusterGitRepo, coreConfig.ArgoCDMapper,
 			coreConfig.GitopsRepoConfig.DefaultBranch),
 		K8sUtil:        cd.NewK8sUtil(regionInformers, manager.EventMgr),
@@ -482,25 +555,11 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		GitGetter:      gitGetter,
 		GrafanaService: grafanaService,
 		BuildSchema:    buildSchema,
+		Hook:           hook.New(coreConfig.HookConfig, manager.HookMgr),
 	}
+	go parameter.Hook.Process()
 
-	var (
-		authnSkippers = []middleware.Skipper{
-			middleware.MethodAndPathSkipper("*",
-				regexp.MustCompile("(^/apis/front/.*)|(^/health)|(^/metrics)|(^/apis/login)|"+
-					"(^/apis/core/v[12]/roles)|(^/apis/internal/.*)|(^/login/oauth/authorize)|(^/login/oauth/access_token)")),
-			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/idps/endpoints")),
-			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/login/callback")),
-			middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v[12]/logout")),
-			middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v[12]/users/login")),
-			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/users/self")),
-		}
-		authzSkippers = []middleware.Skipper{
-			middleware.MethodAndPathSkipper("*",
-				regexp.MustCompile("^/apis/core/v[12]/templates$")),
-		}
-	)
-	authzSkippers = append(authzSkippers, authnSkippers...)
+	authnSkippers, authzSkippers := authSkippers()
 
 	var (
 		// init controller
@@ -515,7 +574,7 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		codeGitCtl           = codectl.NewController(gitGetter)
 		tagCtl               = tagctl.NewController(parameter)
 		templateSchemaTagCtl = templateschematagctl.NewController(parameter)
-		accessCtl            = accessctl.NewController(rbacAuthorizer, authzSkippers...)
+		accessCtl            = accessctl.NewController(rbacAuthorizer, parameter, authzSkippers...)
 		applicationRegionCtl = applicationregionctl.NewController(parameter)
 		groupCtl             = groupctl.NewController(parameter)
 		oauthCheckerCtl      = oauthcheckctl.NewOauthChecker(parameter)
@@ -533,6 +592,14 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		webhookCtl           = webhookctl.NewController(parameter)
 		eventCtl             = eventctl.NewController(parameter)
 		badgeCtl             = badgectl.NewController(parameter)
+		admissionPolicyCtl   = admissionpolicyctl.NewController(parameter)
+		deployWindowCtl      = deploywindowctl.NewController(parameter)
+		releaseTrainCtl      = releasetrainctl.NewController(rbacAuthorizer, parameter)
+		teamCtl              = teamctl.NewController(parameter)
+		imageCtl             = imagectl.NewController(parameter)
+		analyticsCtl         = analyticsctl.NewController(parameter)
+		hookCtl              = hookctl.NewController(parameter)
+		templateMigrationCtl = templatemigrationctl.NewController(parameter, clusterCtl)
 	)
 
 	var (
@@ -593,6 +660,14 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		userAPIV2              = userv2.NewAPI(userCtl, store)
 		webhookAPIV2           = webhookv2.NewAPI(webhookCtl)
 		badgeAPIV2             = badge.NewAPI(badgeCtl)
+		admissionPolicyAPIV2   = admissionpolicyv2.NewAPI(admissionPolicyCtl)
+		deployWindowAPIV2      = deploywindowv2.NewAPI(deployWindowCtl)
+		releaseTrainAPIV2      = releasetrainv2.NewAPI(releaseTrainCtl)
+		teamAPIV2              = teamv2.NewAPI(teamCtl)
+		imageAPIV2             = imagev2.NewAPI(imageCtl)
+		analyticsAPIV2         = analyticsv2.NewAPI(analyticsCtl)
+		hookAPIV2              = hookv2.NewAPI(hookCtl)
+		templateMigrationAPIV2 = templatemigrationv2.NewAPI(templateMigrationCtl)
 	)
 
 	// start jobs
@@ -606,8 +681,25 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		grafanasync.Run(ctx, coreConfig, manager, client)
 	}
 	k8seventJob := k8sevent.New(coreConfig.KubernetesEvent, regionInformers, manager, mysqlDB)
+	promoter, err := promotion.New(coreConfig.Promotion, manager, clusterCtl, eventSvc)
+	if err != nil {
+		panic(err)
+	}
+	scheduleJob := func(ctx context.Context) {
+		schedule.Run(ctx, coreConfig.Schedule, prCtl)
+	}
+	releaseTrainDriver := releasetrain.New(coreConfig.ReleaseTrain, manager, clusterCtl, prCtl)
+	imageRetentionJob := imageretention.New(coreConfig.ImageRetention, manager, imageCtl)
+	ciPollerJob := cipoller.New(coreConfig.CIPoller, manager, tektonFty,
+		cloudeventctl.NewController(tektonFty, parameter))
+	templateMigrator := templatemigration.New(coreConfig.TemplateMigration, manager, clusterCtl)
 	go jobs.Run(ctx, &coreConfig.JobConfig, eventHandlerJob, webhookJob,
-		k8seventJob.Run, cleaner.Run, autoFreeJob, grafanaSyncJob)
+		k8seventJob.Run, cleaner.Run, autoFreeJob, grafanaSyncJob, promoter.Run, scheduleJob,
+		releaseTrainDriver.Run, imageRetentionJob.Run, ciPollerJob.Run, templateMigrator.Run)
+
+	// the denied requests are recorded by every instance
+	auditRecorder := accessauditrecorder.New(manager.AccessAuditMgr)
+	go auditRecorder.Run(ctx)
 
 	// init server
 	r := gin.New()
@@ -629,14 +721,15 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/metrics")),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/apis/front/v1/terminal")),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/apis/front/v2/buildschema")),
-			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/login/oauth/access_token")),
+			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/login/oauth/(access_token|introspect|revoke)")),
+			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/\\.well-known/")),
 			middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/apis/internal/v2/.*")),
 			middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/idps/endpoints")),
 			middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v[12]/users/login"))),
 		prehandlemiddle.Middleware(r, manager),
-		auth.Middleware(rbacAuthorizer, authzSkippers...),
+		auth.Middleware(rbacAuthorizer, auditRecorder, authzSkippers...),
 		tagmiddle.Middleware(),
-		admissionmiddle.Middleware(authzSkippers...),
+		admissionmiddle.Middleware(applicationCtl, clusterCtl, groupCtl, authzSkippers...),
 	}
 	r.Use(middlewares...)
 
@@ -706,6 +799,14 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 		userAPIV2,
 		webhookAPIV2,
 		badgeAPIV2,
+		admissionPolicyAPIV2,
+		deployWindowAPIV2,
+		releaseTrainAPIV2,
+		teamAPIV2,
+		imageAPIV2,
+		analyticsAPIV2,
+		hookAPIV2,
+		templateMigrationAPIV2,
 	}
 
 	// start cloud event server
@@ -727,6 +828,31 @@ func Init(ctx context.Context, flags *Flags, coreConfig *config.Config) {
 	log.Print(r.Run(fmt.Sprintf(":%d", coreConfig.ServerConfig.Port)))
 }
 
+// authSkippers returns the skippers of authentication and authorization,
+// requests skipping authentication skip authorization as well
+func authSkippers() (authnSkippers, authzSkippers []middleware.Skipper) {
+	authnSkippers = []middleware.Skipper{
+		middleware.MethodAndPathSkipper("*",
+			regexp.MustCompile("(^/apis/front/.*)|(^/health)|(^/metrics)|(^/apis/login)|"+
+				"(^/apis/internal/.*)|(^/login/oauth/authorize)|(^/login/oauth/(access_token|introspect|revoke))")),
+		middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/\\.well-known/")),
+		middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/roles")),
+		middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/idps/endpoints")),
+		middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/login/callback")),
+		middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v[12]/logout")),
+		middleware.MethodAndPathSkipper(http.MethodPost, regexp.MustCompile("^/apis/core/v[12]/users/login")),
+		middleware.MethodAndPathSkipper(http.MethodGet, regexp.MustCompile("^/apis/core/v[12]/users/self")),
+	}
+	authzSkippers = []middleware.Skipper{
+		middleware.MethodAndPathSkipper("*",
+			regexp.MustCompile("^/apis/core/v[12]/templates$")),
+		// roles are managed by admins, which is checked in the controller
+		middleware.MethodAndPathSkipper("*", regexp.MustCompile("^/apis/core/v2/roles")),
+	}
+	authzSkippers = append(authzSkippers, authnSkippers...)
+	return authnSkippers, authzSkippers
+}
+
 // Run runs the agent.
 func Run(flags *Flags) {
 	ctx, cancelFunc := context.WithCancel(context.Background())
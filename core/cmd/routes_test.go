@@ -0,0 +1,253 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/horizoncd/horizon/core/common"
+	accessapi "github.com/horizoncd/horizon/core/http/api/v1/access"
+	"github.com/horizoncd/horizon/core/http/api/v1/accesstoken"
+	"github.com/horizoncd/horizon/core/http/api/v1/application"
+	"github.com/horizoncd/horizon/core/http/api/v1/applicationregion"
+	"github.com/horizoncd/horizon/core/http/api/v1/cluster"
+	codeapi "github.com/horizoncd/horizon/core/http/api/v1/code"
+	"github.com/horizoncd/horizon/core/http/api/v1/environment"
+	"github.com/horizoncd/horizon/core/http/api/v1/environmentregion"
+	"github.com/horizoncd/horizon/core/http/api/v1/envtemplate"
+	"github.com/horizoncd/horizon/core/http/api/v1/event"
+	"github.com/horizoncd/horizon/core/http/api/v1/group"
+	"github.com/horizoncd/horizon/core/http/api/v1/idp"
+	"github.com/horizoncd/horizon/core/http/api/v1/member"
+	"github.com/horizoncd/horizon/core/http/api/v1/oauthapp"
+	"github.com/horizoncd/horizon/core/http/api/v1/oauthserver"
+	"github.com/horizoncd/horizon/core/http/api/v1/pipelinerun"
+	"github.com/horizoncd/horizon/core/http/api/v1/region"
+	"github.com/horizoncd/horizon/core/http/api/v1/registry"
+	roleapi "github.com/horizoncd/horizon/core/http/api/v1/role"
+	"github.com/horizoncd/horizon/core/http/api/v1/scope"
+	"github.com/horizoncd/horizon/core/http/api/v1/tag"
+	"github.com/horizoncd/horizon/core/http/api/v1/template"
+	templateschematagapi "github.com/horizoncd/horizon/core/http/api/v1/templateschematag"
+	terminalapi "github.com/horizoncd/horizon/core/http/api/v1/terminal"
+	"github.com/horizoncd/horizon/core/http/api/v1/user"
+	"github.com/horizoncd/horizon/core/http/api/v1/webhook"
+	accessv2 "github.com/horizoncd/horizon/core/http/api/v2/access"
+	accesstokenv2 "github.com/horizoncd/horizon/core/http/api/v2/accesstoken"
+	admissionpolicyv2 "github.com/horizoncd/horizon/core/http/api/v2/admissionpolicy"
+	analyticsv2 "github.com/horizoncd/horizon/core/http/api/v2/analytics"
+	appv2 "github.com/horizoncd/horizon/core/http/api/v2/application"
+	applicationregionv2 "github.com/horizoncd/horizon/core/http/api/v2/applicationregion"
+	"github.com/horizoncd/horizon/core/http/api/v2/badge"
+	buildAPI "github.com/horizoncd/horizon/core/http/api/v2/build"
+	clusterv2 "github.com/horizoncd/horizon/core/http/api/v2/cluster"
+	codev2 "github.com/horizoncd/horizon/core/http/api/v2/code"
+	deploywindowv2 "github.com/horizoncd/horizon/core/http/api/v2/deploywindow"
+	environmentv2 "github.com/horizoncd/horizon/core/http/api/v2/environment"
+	environmentregionv2 "github.com/horizoncd/horizon/core/http/api/v2/environmentregion"
+	envtemplatev2 "github.com/horizoncd/horizon/core/http/api/v2/envtemplate"
+	eventv2 "github.com/horizoncd/horizon/core/http/api/v2/event"
+	groupv2 "github.com/horizoncd/horizon/core/http/api/v2/group"
+	hookv2 "github.com/horizoncd/horizon/core/http/api/v2/hook"
+	idpv2 "github.com/horizoncd/horizon/core/http/api/v2/idp"
+	imagev2 "github.com/horizoncd/horizon/core/http/api/v2/image"
+	memberv2 "github.com/horizoncd/horizon/core/http/api/v2/member"
+	oauthappv2 "github.com/horizoncd/horizon/core/http/api/v2/oauthapp"
+	pipelinerunv2 "github.com/horizoncd/horizon/core/http/api/v2/pipelinerun"
+	regionv2 "github.com/horizoncd/horizon/core/http/api/v2/region"
+	registryv2 "github.com/horizoncd/horizon/core/http/api/v2/registry"
+	releasetrainv2 "github.com/horizoncd/horizon/core/http/api/v2/releasetrain"
+	rolev2 "github.com/horizoncd/horizon/core/http/api/v2/role"
+	scopev2 "github.com/horizoncd/horizon/core/http/api/v2/scope"
+	tagv2 "github.com/horizoncd/horizon/core/http/api/v2/tag"
+	teamv2 "github.com/horizoncd/horizon/core/http/api/v2/team"
+	templatev2 "github.com/horizoncd/horizon/core/http/api/v2/template"
+	templatemigrationv2 "github.com/horizoncd/horizon/core/http/api/v2/templatemigration"
+	templateschematagv2 "github.com/horizoncd/horizon/core/http/api/v2/templateschematag"
+	terminalv2 "github.com/horizoncd/horizon/core/http/api/v2/terminal"
+	userv2 "github.com/horizoncd/horizon/core/http/api/v2/user"
+	webhookv2 "github.com/horizoncd/horizon/core/http/api/v2/webhook"
+	"github.com/horizoncd/horizon/core/middleware"
+	authmiddle "github.com/horizoncd/horizon/core/middleware/auth"
+	prehandlemiddle "github.com/horizoncd/horizon/core/middleware/prehandle"
+	"github.com/horizoncd/horizon/lib/orm"
+	memberservicemock "github.com/horizoncd/horizon/mock/pkg/member/service"
+	accessauditmanager "github.com/horizoncd/horizon/pkg/accessaudit/manager"
+	accessauditmodels "github.com/horizoncd/horizon/pkg/accessaudit/models"
+	"github.com/horizoncd/horizon/pkg/accessaudit/recorder"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	"github.com/horizoncd/horizon/pkg/rbac"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
+)
+
+// routers returns all the routers registered by Init, the controllers are not needed to list routes
+func routers() []RegisterRouter {
+	return []RegisterRouter{
+		group.NewAPI(nil), user.NewAPI(nil, nil), application.NewAPI(nil), envtemplate.NewAPI(nil),
+		cluster.NewAPI(nil), pipelinerun.NewAPI(nil), environment.NewAPI(nil), region.NewAPI(nil, nil),
+		environmentregion.NewAPI(nil), registry.NewAPI(nil), member.NewAPI(nil, nil), roleapi.NewAPI(nil),
+		terminalapi.NewAPI(nil), codeapi.NewAPI(nil), tag.NewAPI(nil), templateschematagapi.NewAPI(nil),
+		template.NewAPI(nil, nil), accessapi.NewAPI(nil), applicationregion.NewAPI(nil), oauthapp.NewAPI(nil),
+		oauthserver.NewAPI(nil, nil, "", nil), idp.NewAPI(nil, nil), accesstoken.NewAPI(nil, nil, nil),
+		scope.NewAPI(nil), webhook.NewAPI(nil), event.NewAPI(nil),
+
+		groupv2.NewAPI(nil), accessv2.NewAPI(nil), accesstokenv2.NewAPI(nil, nil, nil), appv2.NewAPI(nil),
+		applicationregionv2.NewAPI(nil), buildAPI.NewAPI(nil), clusterv2.NewAPI(nil), codev2.NewAPI(nil),
+		environmentv2.NewAPI(nil), environmentregionv2.NewAPI(nil), envtemplatev2.NewAPI(nil),
+		eventv2.NewAPI(nil), idpv2.NewAPI(nil, nil), memberv2.NewAPI(nil, nil), oauthappv2.NewAPI(nil),
+		pipelinerunv2.NewAPI(nil), regionv2.NewAPI(nil, nil), registryv2.NewAPI(nil), rolev2.NewAPI(nil),
+		scopev2.NewAPI(nil), tagv2.NewAPI(nil), templatev2.NewAPI(nil, nil), templateschematagv2.NewAPI(nil),
+		terminalv2.NewAPI(nil), userv2.NewAPI(nil, nil), webhookv2.NewAPI(nil), badge.NewAPI(nil),
+		admissionpolicyv2.NewAPI(nil), deploywindowv2.NewAPI(nil), releasetrainv2.NewAPI(nil), teamv2.NewAPI(nil),
+		imagev2.NewAPI(nil), analyticsv2.NewAPI(nil), hookv2.NewAPI(nil), templatemigrationv2.NewAPI(nil),
+	}
+}
+
+type recordAuthorizer struct {
+	rbac.Authorizer
+	called   bool
+	decision auth.Decision
+}
+
+func (a *recordAuthorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.Decision, string, error) {
+	decision, reason, err := a.Authorizer.Authorize(ctx, attr)
+	a.called, a.decision = true, decision
+	return decision, reason, err
+}
+
+// TestRoutesAuthorized requests every registered route as a user who is not a member of any resource,
+// every request changing resources must be authorized, unless it's one of the routes below
+func TestRoutesAuthorized(t *testing.T) {
+	// routes which every user can change, such as their own personal access tokens
+	writable := map[string]bool{
+		"POST /apis/core/v1/personalaccesstokens": true,
+		"POST /apis/core/v2/personalaccesstokens": true,
+		"POST /apis/core/v2/teams":                true,
+	}
+
+	// 1. all the routers are listed
+	registered := make(map[string]bool)
+	for _, router := range routers() {
+		registered[reflect.TypeOf(router).Elem().PkgPath()] = true
+	}
+	apiRoot := filepath.Join("..", "http", "api")
+	err := filepath.Walk(apiRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if strings.Contains(string(content), ") RegisterRoute(") {
+			rel, err := filepath.Rel(apiRoot, filepath.Dir(path))
+			if err != nil {
+				return err
+			}
+			pkg := "github.com/horizoncd/horizon/core/http/api/" + filepath.ToSlash(rel)
+			assert.True(t, registered[pkg], "routes of %s are not listed", pkg)
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+
+	// 2. init the authorizer
+	content, err := ioutil.ReadFile(filepath.Join("..", "..", "roles.yaml"))
+	assert.Nil(t, err)
+	var roleConfig roleconfig.Config
+	assert.Nil(t, yaml.Unmarshal(content, &roleConfig))
+	roleService, err := role.NewFileRoleFrom2(context.Background(), roleConfig)
+	assert.Nil(t, err)
+	mockCtl := gomock.NewController(t)
+	memberService := memberservicemock.NewMockService(mockCtl)
+	memberService.EXPECT().GetMemberOfResource(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&tokenmodels.Token{}, &accessauditmodels.AccessAudit{}))
+	authorizer := &recordAuthorizer{
+		Authorizer: rbac.NewAuthorizer(roleService, memberService, tokenmanager.New(db)),
+	}
+
+	// 3. request every route, the handlers are never reached
+	_, authzSkippers := authSkippers()
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(common.UserContextKey(), &userauth.DefaultInfo{Name: "tony", ID: 1})
+	}, prehandlemiddle.Middleware(r, nil), authmiddle.Middleware(authorizer,
+		recorder.New(accessauditmanager.New(db)), authzSkippers...),
+		func(c *gin.Context) {
+			c.AbortWithStatus(http.StatusNoContent)
+		})
+	for _, router := range routers() {
+		router.RegisterRoute(r)
+	}
+
+	var allowed []string
+	for _, route := range r.Routes() {
+		parts := strings.Split(route.Path, "/")
+		for i, part := range parts {
+			switch {
+			case part == ":"+common.ParamResourceType:
+				parts[i] = common.ResourceGroup
+			case strings.HasPrefix(part, ":"), strings.HasPrefix(part, "*"):
+				parts[i] = "1"
+			}
+		}
+		req := httptest.NewRequest(route.Method, strings.Join(parts, "/"), nil)
+		if skipped(req, authzSkippers) {
+			continue
+		}
+
+		authorizer.called = false
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		if !authorizer.called || route.Method == http.MethodGet {
+			continue
+		}
+		key := route.Method + " " + route.Path
+		if authorizer.decision == auth.DecisionAllow && !writable[key] {
+			allowed = append(allowed, key)
+		}
+	}
+	sort.Strings(allowed)
+	assert.Empty(t, allowed, "routes are allowed without authorization")
+}
+
+func skipped(req *http.Request, skippers []middleware.Skipper) bool {
+	for _, skipper := range skippers {
+		if skipper(req) {
+			return true
+		}
+	}
+	return false
+}
@@ -24,4 +24,7 @@ const (
 	ApplicationQueryID               = "id"
 
 	ApplicationQueryWithDeleted = "withDeleted"
+	// ApplicationQueryInvisible is used to hide the applications invisible to the current user,
+	// the value is *groupmodels.Invisible
+	ApplicationQueryInvisible = "invisible"
 )
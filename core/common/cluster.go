@@ -34,6 +34,8 @@ const (
 	ClusterQueryTailLines     = "tailLines"
 	ClusterQueryExtraOwner    = "extraOwner"
 	ClusterQueryHard          = "hard"
+	// ClusterQueryDryRun is used to validate and render a cluster without creating or updating it.
+	ClusterQueryDryRun = "dryRun"
 
 	// ClusterQueryIsFavorite is used to query cluster with favorite for current user only.
 	ClusterQueryIsFavorite = "isFavorite"
@@ -41,6 +43,11 @@ const (
 	ClusterQueryWithFavorite = "withFavorite"
 	ClusterQueryUpdatedAfter = "updatedAfter"
 	ClusterQueryOnlyDeleted  = "onlyDeleted"
+	// ClusterQueryInvisible is used to hide the clusters of the applications invisible to the current user,
+	// the value is *groupmodels.Invisible
+	ClusterQueryInvisible = "invisible"
+	// ClusterQueryOverrideDeployWindow is used by admins to deploy while deploy windows hold deployments.
+	ClusterQueryOverrideDeployWindow = "overrideDeployWindow"
 )
 
 const (
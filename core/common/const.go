@@ -109,7 +109,37 @@ const (
 	ResourceWebhook    = "webhooks"
 	ResourceWebhookLog = "webhooklogs"
 
+	// ResourceMember members are authorized by the member info of the resources that they belong to
 	ResourceMember = "members"
+
+	// ResourceAdmissionPolicy currently admission policies do not have direct member info, will
+	// use the member info of the groups that they belong to
+	ResourceAdmissionPolicy = "admissionpolicies"
+
+	// ResourceEnvironment environments are readable by everyone, and only admins could change them
+	ResourceEnvironment = "environments"
+
+	// ResourceUser users are readable by everyone, and only admins could change them
+	ResourceUser = "users"
+
+	// ResourceAccessToken currently resource access tokens do not have direct member info, will
+	// use the member info of the resources that their robots belong to
+	ResourceAccessToken = "accesstokens"
+
+	// ResourcePersonalAccessToken personal access tokens are only managed by the users who own them
+	ResourcePersonalAccessToken = "personalaccesstokens"
+
+	// ResourceDeployWindow currently deploy windows do not have direct member info, will
+	// use the member info of the groups that they belong to
+	ResourceDeployWindow = "deploywindows"
+
+	// ResourceReleaseTrain and ResourceReleaseTrainRun currently do not have direct member info, will
+	// use the member info of the applications that they belong to
+	ResourceReleaseTrain    = "releasetrains"
+	ResourceReleaseTrainRun = "releasetrainruns"
+
+	// ResourceTeam teams are authorized by the roles of the users in them
+	ResourceTeam = "teams"
 )
 
 const (
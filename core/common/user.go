@@ -26,9 +26,10 @@ import (
 )
 
 const (
-	UserQueryName = "filter"
-	UserQueryType = "userType"
-	UserQueryID   = "id"
+	UserQueryName  = "filter"
+	UserQueryType  = "userType"
+	UserQueryID    = "id"
+	UserQueryEmail = "email"
 )
 
 const (
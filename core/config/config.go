@@ -22,22 +22,31 @@ import (
 	"github.com/horizoncd/horizon/pkg/config/argocd"
 	"github.com/horizoncd/horizon/pkg/config/authenticate"
 	"github.com/horizoncd/horizon/pkg/config/autofree"
+	"github.com/horizoncd/horizon/pkg/config/cipoller"
 	"github.com/horizoncd/horizon/pkg/config/clean"
 	"github.com/horizoncd/horizon/pkg/config/db"
 	"github.com/horizoncd/horizon/pkg/config/eventhandler"
 	"github.com/horizoncd/horizon/pkg/config/git"
 	"github.com/horizoncd/horizon/pkg/config/gitlab"
 	"github.com/horizoncd/horizon/pkg/config/grafana"
+	"github.com/horizoncd/horizon/pkg/config/hook"
+	"github.com/horizoncd/horizon/pkg/config/imageretention"
 	"github.com/horizoncd/horizon/pkg/config/job"
 	"github.com/horizoncd/horizon/pkg/config/k8sevent"
 	"github.com/horizoncd/horizon/pkg/config/oauth"
 	"github.com/horizoncd/horizon/pkg/config/pprof"
+	"github.com/horizoncd/horizon/pkg/config/promotion"
 	"github.com/horizoncd/horizon/pkg/config/redis"
+	"github.com/horizoncd/horizon/pkg/config/releasetrain"
+	"github.com/horizoncd/horizon/pkg/config/role"
+	"github.com/horizoncd/horizon/pkg/config/schedule"
 	"github.com/horizoncd/horizon/pkg/config/server"
 	"github.com/horizoncd/horizon/pkg/config/session"
 	"github.com/horizoncd/horizon/pkg/config/tekton"
 	"github.com/horizoncd/horizon/pkg/config/template"
+	"github.com/horizoncd/horizon/pkg/config/templatemigration"
 	"github.com/horizoncd/horizon/pkg/config/templaterepo"
+	"github.com/horizoncd/horizon/pkg/config/templatevalidation"
 	"github.com/horizoncd/horizon/pkg/config/token"
 	"github.com/horizoncd/horizon/pkg/config/webhook"
 
@@ -45,30 +54,39 @@ import (
 )
 
 type Config struct {
-	ServerConfig           server.Config           `yaml:"serverConfig"`
-	CloudEventServerConfig server.Config           `yaml:"cloudEventServerConfig"`
-	JobConfig              job.Config              `yaml:"jobConfig"`
-	PProf                  pprof.Config            `yaml:"pprofConfig"`
-	DBConfig               db.Config               `yaml:"dbConfig"`
-	SessionConfig          session.Config          `yaml:"sessionConfig"`
-	GitopsRepoConfig       gitlab.GitopsRepoConfig `yaml:"gitopsRepoConfig"`
-	ArgoCDMapper           argocd.Mapper           `yaml:"argoCDMapper"`
-	RedisConfig            redis.Redis             `yaml:"redisConfig"`
-	TektonMapper           tekton.Mapper           `yaml:"tektonMapper"`
-	TemplateRepo           templaterepo.Repo       `yaml:"templateRepo"`
-	AccessSecretKeys       authenticate.KeysConfig `yaml:"accessSecretKeys"`
-	GrafanaConfig          grafana.Config          `yaml:"grafanaConfig"`
-	Oauth                  oauth.Server            `yaml:"oauth"`
-	AutoFreeConfig         autofree.Config         `yaml:"autoFree"`
-	KubeConfig             string                  `yaml:"kubeconfig"`
-	WebhookConfig          webhook.Config          `yaml:"webhook"`
-	EventHandlerConfig     eventhandler.Config     `yaml:"eventHandler"`
-	CodeGitRepos           []*git.Repo             `yaml:"gitRepos"`
-	TokenConfig            token.Config            `yaml:"tokenConfig"`
-	TemplateUpgradeMapper  template.UpgradeMapper  `yaml:"templateUpgradeMapper"`
-	KubernetesEvent        k8sevent.Config         `yaml:"kubernetesEvent"`
-	Clean                  clean.Config            `yaml:"clean"`
-	Admission              admission.Admission     `yaml:"admission"`
+	ServerConfig           server.Config             `yaml:"serverConfig"`
+	CloudEventServerConfig server.Config             `yaml:"cloudEventServerConfig"`
+	JobConfig              job.Config                `yaml:"jobConfig"`
+	PProf                  pprof.Config              `yaml:"pprofConfig"`
+	DBConfig               db.Config                 `yaml:"dbConfig"`
+	SessionConfig          session.Config            `yaml:"sessionConfig"`
+	GitopsRepoConfig       gitlab.GitopsRepoConfig   `yaml:"gitopsRepoConfig"`
+	ArgoCDMapper           argocd.Mapper             `yaml:"argoCDMapper"`
+	RedisConfig            redis.Redis               `yaml:"redisConfig"`
+	TektonMapper           tekton.Mapper             `yaml:"tektonMapper"`
+	TemplateRepo           templaterepo.Repo         `yaml:"templateRepo"`
+	AccessSecretKeys       authenticate.KeysConfig   `yaml:"accessSecretKeys"`
+	GrafanaConfig          grafana.Config            `yaml:"grafanaConfig"`
+	Oauth                  oauth.Server              `yaml:"oauth"`
+	AutoFreeConfig         autofree.Config           `yaml:"autoFree"`
+	KubeConfig             string                    `yaml:"kubeconfig"`
+	WebhookConfig          webhook.Config            `yaml:"webhook"`
+	EventHandlerConfig     eventhandler.Config       `yaml:"eventHandler"`
+	CodeGitRepos           []*git.Repo               `yaml:"gitRepos"`
+	TokenConfig            token.Config              `yaml:"tokenConfig"`
+	TemplateUpgradeMapper  template.UpgradeMapper    `yaml:"templateUpgradeMapper"`
+	KubernetesEvent        k8sevent.Config           `yaml:"kubernetesEvent"`
+	Clean                  clean.Config              `yaml:"clean"`
+	Admission              admission.Admission       `yaml:"admission"`
+	Promotion              promotion.Config          `yaml:"promotion"`
+	Schedule               schedule.Config           `yaml:"schedule"`
+	ReleaseTrain           releasetrain.Config       `yaml:"releaseTrain"`
+	ImageRetention         imageretention.Config     `yaml:"imageRetention"`
+	RoleStore              role.StoreConfig          `yaml:"roleStore"`
+	HookConfig             hook.Config               `yaml:"hook"`
+	CIPoller               cipoller.Config           `yaml:"ciPoller"`
+	TemplateMigration      templatemigration.Config  `yaml:"templateMigration"`
+	TemplateValidation     templatevalidation.Config `yaml:"templateValidation"`
 }
 
 func LoadConfig(configFilePath string) (*Config, error) {
@@ -113,7 +131,7 @@ func LoadConfig(configFilePath string) (*Config, error) {
 		config.WebhookConfig.ClientTimeout = 30
 	}
 	if config.WebhookConfig.IdleWaitInterval <= 0 {
-		config.WebhookConfig.IdleWaitInterval = 2
+		config.WebhookConfig.IdleWaitInterval = 5
 	}
 	if config.WebhookConfig.WorkerReconcileInterval <= 0 {
 		config.WebhookConfig.WorkerReconcileInterval = 5
@@ -121,6 +139,27 @@ func LoadConfig(configFilePath string) (*Config, error) {
 	if config.WebhookConfig.ResponseBodyTruncateSize <= 0 {
 		config.WebhookConfig.ResponseBodyTruncateSize = 16384
 	}
+	if config.WebhookConfig.MaxAttempts <= 0 {
+		config.WebhookConfig.MaxAttempts = 5
+	}
+	if config.WebhookConfig.RetryInitialInterval <= 0 {
+		config.WebhookConfig.RetryInitialInterval = 10
+	}
+	if config.WebhookConfig.RetryMaxInterval <= 0 {
+		config.WebhookConfig.RetryMaxInterval = 3600
+	}
+	if config.WebhookConfig.RetryMultiplier < 1 {
+		config.WebhookConfig.RetryMultiplier = 2
+	}
+	if config.WebhookConfig.WorkerConcurrency <= 0 {
+		config.WebhookConfig.WorkerConcurrency = 4
+	}
+	if config.WebhookConfig.RateLimitPerHost <= 0 {
+		config.WebhookConfig.RateLimitPerHost = 10
+	}
+	if config.WebhookConfig.RateLimitBurst <= 0 {
+		config.WebhookConfig.RateLimitBurst = 20
+	}
 
 	return &config, nil
 }
@@ -19,32 +19,47 @@ import (
 	"net/http"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/core/middleware"
 	"github.com/horizoncd/horizon/core/middleware/prehandle"
+	"github.com/horizoncd/horizon/lib/q"
+	accessauditmanager "github.com/horizoncd/horizon/pkg/accessaudit/manager"
 	hauth "github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/rbac"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 )
 
 type Controller interface {
 	// Review return access review results for apis
 	Review(ctx context.Context, apis []API) (map[string]map[string]*ReviewResult, error)
+	// Explain tells how the decision on the request of the user is made,
+	// users could explain their own requests while only admins could explain the others'
+	Explain(ctx context.Context, request *ExplainRequest) (*ExplainResult, error)
+	// ListAudits lists the requests denied by the authorizer, only admins could list them
+	ListAudits(ctx context.Context, query *q.Query) ([]*Audit, int64, error)
 }
 
 type controller struct {
 	requestInfoFty hauth.RequestInfoFactory
 	authorizer     rbac.Authorizer
 	skippers       []middleware.Skipper
+	userMgr        usermanager.Manager
+	auditMgr       accessauditmanager.Manager
 }
 
 var _ Controller = (*controller)(nil)
 
-func NewController(authorizer rbac.Authorizer,
+func NewController(authorizer rbac.Authorizer, param *param.Param,
 	skippers ...middleware.Skipper) Controller {
 	return &controller{
 		requestInfoFty: prehandle.RequestInfoFty,
 		authorizer:     authorizer,
 		skippers:       skippers,
+		userMgr:        param.UserMgr,
+		auditMgr:       param.AccessAuditMgr,
 	}
 }
 
@@ -110,3 +125,65 @@ func (c *controller) Review(ctx context.Context, apis []API) (map[string]map[str
 
 	return reviewResponse, nil
 }
+
+func (c *controller) Explain(ctx context.Context, request *ExplainRequest) (*ExplainResult, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, perror.WithMessage(err, "failed to get user info")
+	}
+	if request.Verb == "" || request.Resource == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "verb and resource should not be empty")
+	}
+
+	// explain the request as the user in question
+	user := currentUser
+	if request.UserID != 0 && request.UserID != currentUser.GetID() {
+		if !currentUser.IsAdmin() {
+			return nil, perror.Wrap(herrors.ErrForbidden, "only admin can explain the requests of others")
+		}
+		userInDB, err := c.userMgr.GetUserByID(ctx, request.UserID)
+		if err != nil {
+			return nil, err
+		}
+		user = &userauth.DefaultInfo{
+			Name:     userInDB.Name,
+			FullName: userInDB.FullName,
+			ID:       userInDB.ID,
+			Email:    userInDB.Email,
+			Admin:    userInDB.Admin,
+		}
+		ctx = common.WithContext(ctx, user)
+	}
+
+	explanation, err := c.authorizer.Explain(ctx, hauth.AttributesRecord{
+		User:            user,
+		Verb:            request.Verb,
+		APIGroup:        common.GroupCore,
+		Resource:        request.Resource,
+		SubResource:     request.SubResource,
+		Name:            request.Name,
+		Scope:           request.Scope,
+		ResourceRequest: true,
+	})
+	if err != nil {
+		return nil, perror.WithMessagef(err, "failed to explain, resource: %s, name: %s",
+			request.Resource, request.Name)
+	}
+	return ofExplanation(request, explanation), nil
+}
+
+func (c *controller) ListAudits(ctx context.Context, query *q.Query) ([]*Audit, int64, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, 0, perror.WithMessage(err, "failed to get user info")
+	}
+	if !currentUser.IsAdmin() {
+		return nil, 0, perror.Wrap(herrors.ErrForbidden, "only admin can list the access audits")
+	}
+
+	audits, total, err := c.auditMgr.List(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	return ofAudits(audits), total, nil
+}
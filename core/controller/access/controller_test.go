@@ -25,14 +25,19 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/core/middleware"
 	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	accessauditmodels "github.com/horizoncd/horizon/pkg/accessaudit/models"
 	applicationmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
+	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	"github.com/horizoncd/horizon/pkg/rbac"
 	roleservice "github.com/horizoncd/horizon/pkg/rbac/role"
@@ -67,6 +72,9 @@ func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&usermodels.User{}); err != nil {
 		panic(err)
 	}
+	if err := db.AutoMigrate(&accessauditmodels.AccessAudit{}); err != nil {
+		panic(err)
+	}
 
 	roleService, err := roleservice.NewFileRole(context.Background(), strings.NewReader(roleConfig))
 	if err != nil {
@@ -81,16 +89,16 @@ func TestMain(m *testing.M) {
 		ID:   uint(110),
 	})
 
-	rbacAuthorizer := rbac.NewAuthorizer(roleService, memberService)
+	rbacAuthorizer := rbac.NewAuthorizer(roleService, memberService, manager.TokenMgr)
 	skippers := middleware.MethodAndPathSkipper("*",
 		regexp.MustCompile("(^/apis/front/.*)|(^/health)|(^/metrics)|(^/apis/login)|"+
 			"(^/apis/core/v1/roles)|(^/apis/internal/.*)"))
-	c = NewController(rbacAuthorizer, skippers)
+	c = NewController(rbacAuthorizer, &param.Param{Manager: manager}, skippers)
 
 	group, err = manager.GroupMgr.Create(ctx, &groupmodels.Group{
 		Name:            "group",
 		Path:            "/group",
-		VisibilityLevel: "private",
+		VisibilityLevel: groupmodels.VisibilityPublic,
 	})
 	if err != nil {
 		panic(err)
@@ -248,6 +256,80 @@ func TestController_GetAccesses_Admin(t *testing.T) {
 	}
 }
 
+func TestController_Explain(t *testing.T) {
+	owner, err := manager.UserMgr.Create(ctx, &usermodels.User{
+		Name: "explained",
+	})
+	assert.Nil(t, err)
+	_, err = manager.MemberMgr.Create(ctx, &membermodels.Member{
+		ResourceType: common.ResourceGroup,
+		ResourceID:   group.ID,
+		Role:         "owner",
+		MemberType:   membermodels.MemberUser,
+		MemberNameID: owner.ID,
+	})
+	assert.Nil(t, err)
+	ownerCtx := common.WithContext(ctx, &userauth.DefaultInfo{ID: owner.ID, Name: owner.Name})
+	adminCtx := common.WithContext(ctx, &userauth.DefaultInfo{ID: 1000, Admin: true})
+
+	_, err = c.Explain(ownerCtx, &ExplainRequest{Resource: common.ResourceCluster})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// the member of the cluster is inherited from its group
+	request := &ExplainRequest{
+		Verb:        "get",
+		Resource:    common.ResourceCluster,
+		SubResource: "shell",
+		Name:        fmt.Sprintf("%d", cluster.ID),
+	}
+	result, err := c.Explain(ownerCtx, request)
+	assert.Nil(t, err)
+	assert.True(t, result.Allowed)
+	assert.True(t, result.Inherited)
+	assert.Equal(t, common.ResourceGroup, result.Member.ResourceType)
+	assert.Equal(t, group.ID, result.Member.ResourceID)
+	assert.Equal(t, "owner", result.Role)
+	assert.Equal(t, 2, result.RuleIndex)
+	assert.NotNil(t, result.Rule)
+
+	// only admins could explain the requests of others
+	request = &ExplainRequest{
+		UserID:      owner.ID,
+		Verb:        "create",
+		Resource:    common.ResourceCluster,
+		SubResource: "templateschematags",
+		Name:        fmt.Sprintf("%d", cluster.ID),
+	}
+	_, err = c.Explain(common.WithContext(ctx, &userauth.DefaultInfo{ID: 1001}), request)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	result, err = c.Explain(adminCtx, request)
+	assert.Nil(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, "owner", result.Role)
+	assert.Equal(t, -1, result.RuleIndex)
+	assert.Nil(t, result.Rule)
+}
+
+func TestController_ListAudits(t *testing.T) {
+	_, err := manager.AccessAuditMgr.Create(ctx, &accessauditmodels.AccessAudit{
+		UserID:   2000,
+		Verb:     "delete",
+		Resource: common.ResourceCluster,
+		Name:     fmt.Sprintf("%d", cluster.ID),
+		Reason:   rbac.MemberNotExist,
+	})
+	assert.Nil(t, err)
+
+	query := q.New(q.KeyWords{common.AccessAuditQueryByUser: uint(2000)})
+	_, _, err = c.ListAudits(common.WithContext(ctx, &userauth.DefaultInfo{ID: 2000}), query)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	audits, total, err := c.ListAudits(common.WithContext(ctx, &userauth.DefaultInfo{ID: 1000, Admin: true}), query)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, rbac.MemberNotExist, audits[0].Reason)
+}
+
 const roleConfig = `RolePriorityRankDesc:
   - pe
   - owner
@@ -14,6 +14,17 @@
 
 package access
 
+import (
+	"strconv"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/accessaudit/models"
+	"github.com/horizoncd/horizon/pkg/auth"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/rbac"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+)
+
 type API struct {
 	URL    string `json:"url"`
 	Method string `json:"method"`
@@ -28,3 +39,91 @@ type ReviewResult struct {
 type ReviewRequest struct {
 	APIs []API `json:"apis"`
 }
+
+type ExplainRequest struct {
+	// UserID is the user whose request is explained, the current user if it is empty
+	UserID      uint   `json:"userID"`
+	Verb        string `json:"verb"`
+	Resource    string `json:"resource"`
+	SubResource string `json:"subResource"`
+	Name        string `json:"name"`
+	Scope       string `json:"scope"`
+}
+
+type ExplainResult struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+	// Member is the binding the decision came from, it is empty if the decision does not depend on members
+	Member *Member `json:"member,omitempty"`
+	// Inherited tells whether the member is bound to an ancestor of the resource, such as a parent group
+	Inherited bool   `json:"inherited"`
+	Role      string `json:"role,omitempty"`
+	// RuleIndex is the index of the rule allowing the request in the role, -1 if no rule allows it
+	RuleIndex int               `json:"ruleIndex"`
+	Rule      *types.PolicyRule `json:"rule,omitempty"`
+}
+
+type Member struct {
+	ID           uint                    `json:"id"`
+	ResourceType string                  `json:"resourceType"`
+	ResourceID   uint                    `json:"resourceID"`
+	Role         string                  `json:"role"`
+	MemberType   membermodels.MemberType `json:"memberType"`
+	// MemberNameID userID or teamID
+	MemberNameID uint `json:"memberNameID"`
+}
+
+type Audit struct {
+	ID          uint      `json:"id"`
+	UserID      uint      `json:"userID"`
+	UserName    string    `json:"userName"`
+	Verb        string    `json:"verb"`
+	Resource    string    `json:"resource"`
+	SubResource string    `json:"subResource"`
+	Name        string    `json:"name"`
+	Path        string    `json:"path"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func ofExplanation(request *ExplainRequest, explanation *rbac.Explanation) *ExplainResult {
+	result := &ExplainResult{
+		Allowed:   explanation.Decision == auth.DecisionAllow,
+		Reason:    explanation.Reason,
+		Role:      explanation.Role,
+		RuleIndex: explanation.RuleIndex,
+		Rule:      explanation.Rule,
+	}
+	if member := explanation.Member; member != nil {
+		result.Member = &Member{
+			ID:           member.ID,
+			ResourceType: string(member.ResourceType),
+			ResourceID:   member.ResourceID,
+			Role:         member.Role,
+			MemberType:   member.MemberType,
+			MemberNameID: member.MemberNameID,
+		}
+		result.Inherited = string(member.ResourceType) != request.Resource ||
+			strconv.FormatUint(uint64(member.ResourceID), 10) != request.Name
+	}
+	return result
+}
+
+func ofAudits(audits []*models.AccessAudit) []*Audit {
+	result := make([]*Audit, 0, len(audits))
+	for _, audit := range audits {
+		result = append(result, &Audit{
+			ID:          audit.ID,
+			UserID:      audit.UserID,
+			UserName:    audit.UserName,
+			Verb:        audit.Verb,
+			Resource:    audit.Resource,
+			SubResource: audit.SubResource,
+			Name:        audit.Name,
+			Path:        audit.Path,
+			Reason:      audit.Reason,
+			CreatedAt:   audit.CreatedAt,
+		})
+	}
+	return result
+}
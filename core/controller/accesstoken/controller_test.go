@@ -101,9 +101,14 @@ func TestMain(m *testing.M) {
 	oauthMgr := oauthmanager.NewManager(oauthAppDAO, tokenStore, generator.NewAuthorizeGenerator(),
 		authorizeCodeExpireIn, accessTokenExpireIn, refreshTokenExpireIn)
 
+	tokenSvc, err := tokenservice.NewService(manager, token.Config{})
+	if err != nil {
+		panic(err)
+	}
+
 	parameter := &param.Param{
 		Manager:       manager,
-		TokenSvc:      tokenservice.NewService(manager, token.Config{}),
+		TokenSvc:      tokenSvc,
 		MemberService: memberservice.NewService(roleSvc, oauthMgr, manager),
 	}
 
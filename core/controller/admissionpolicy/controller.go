@@ -0,0 +1,148 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admissionpolicy
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/admission"
+	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
+	policymanager "github.com/horizoncd/horizon/pkg/admissionpolicy/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _maxNameLength = 64
+
+type Controller interface {
+	CreatePolicy(ctx context.Context, groupID uint, request *CreatePolicyRequest) (*Policy, error)
+	ListPolicies(ctx context.Context, groupID uint, query *q.Query) ([]*Policy, int64, error)
+	GetPolicy(ctx context.Context, id uint) (*Policy, error)
+	UpdatePolicy(ctx context.Context, id uint, request *UpdatePolicyRequest) (*Policy, error)
+	DeletePolicy(ctx context.Context, id uint) error
+}
+
+type controller struct {
+	policyMgr policymanager.Manager
+	groupMgr  groupmanager.Manager
+}
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		policyMgr: param.AdmissionPolicyMgr,
+		groupMgr:  param.GroupMgr,
+	}
+}
+
+func (c *controller) CreatePolicy(ctx context.Context, groupID uint,
+	request *CreatePolicyRequest) (*Policy, error) {
+	const op = "admission policy controller: create"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.groupMgr.GetByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+	policy := request.toModel(groupID)
+	if err := validatePolicy(policy.Name, splitItems(policy.Operations), policy.Expression); err != nil {
+		return nil, err
+	}
+
+	policy, err := c.policyMgr.Create(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	return ofPolicyModel(policy), nil
+}
+
+func (c *controller) ListPolicies(ctx context.Context, groupID uint,
+	query *q.Query) ([]*Policy, int64, error) {
+	const op = "admission policy controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	policies, total, err := c.policyMgr.List(ctx, groupID, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	result := make([]*Policy, 0, len(policies))
+	for _, policy := range policies {
+		result = append(result, ofPolicyModel(policy))
+	}
+	return result, total, nil
+}
+
+func (c *controller) GetPolicy(ctx context.Context, id uint) (*Policy, error) {
+	const op = "admission policy controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	policy, err := c.policyMgr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofPolicyModel(policy), nil
+}
+
+func (c *controller) UpdatePolicy(ctx context.Context, id uint,
+	request *UpdatePolicyRequest) (*Policy, error) {
+	const op = "admission policy controller: update"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	policy, err := c.policyMgr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	policy = request.toModel(policy)
+	if err := validatePolicy(policy.Name, splitItems(policy.Operations), policy.Expression); err != nil {
+		return nil, err
+	}
+
+	policy, err = c.policyMgr.Update(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	return ofPolicyModel(policy), nil
+}
+
+func (c *controller) DeletePolicy(ctx context.Context, id uint) error {
+	const op = "admission policy controller: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.policyMgr.Get(ctx, id); err != nil {
+		return err
+	}
+	return c.policyMgr.Delete(ctx, id)
+}
+
+func validatePolicy(name string, operations []string, expression string) error {
+	if name == "" || len(name) > _maxNameLength {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"name must not be empty and no longer than %d characters", _maxNameLength)
+	}
+	for _, operation := range operations {
+		switch admissionmodels.Operation(operation) {
+		case admissionmodels.OperationCreate, admissionmodels.OperationUpdate,
+			admissionmodels.OperationDelete, admissionmodels.Operation(admissionmodels.MatchAll):
+		default:
+			return perror.Wrapf(herrors.ErrParamInvalid, "invalid operation: %s", operation)
+		}
+	}
+	if _, err := admission.CompilePolicy(expression); err != nil {
+		return err
+	}
+	return nil
+}
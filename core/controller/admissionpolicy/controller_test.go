@@ -0,0 +1,103 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admissionpolicy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	policymodels "github.com/horizoncd/horizon/pkg/admissionpolicy/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+func TestController(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&groupmodels.Group{}, &policymodels.AdmissionPolicy{}))
+	assert.Nil(t, db.Create(&groupmodels.Group{Model: global.Model{ID: 1},
+		Name: "a", Path: "a", TraversalIDs: "1"}).Error)
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name:  "Tony",
+		ID:    1,
+		Admin: true,
+	})
+	ctrl := NewController(&param.Param{Manager: managerparam.InitManager(db)})
+
+	request := &CreatePolicyRequest{
+		Name:       "replicas",
+		Enabled:    true,
+		Resources:  []string{"applications/clusters", " clusters "},
+		Operations: []string{"create", "update"},
+		Expression: "object.replicas >= 2",
+		Message:    "replicas must be >= 2",
+	}
+	policy, err := ctrl.CreatePolicy(ctx, 1, request)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), policy.GroupID)
+	assert.Equal(t, []string{"applications/clusters", "clusters"}, policy.Resources)
+
+	// invalid requests
+	_, err = ctrl.CreatePolicy(ctx, 2, request)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	for _, invalid := range []*CreatePolicyRequest{
+		{Name: "", Expression: "true"},
+		{Name: "op", Operations: []string{"get"}, Expression: "true"},
+		{Name: "syntax", Expression: "object.replicas >="},
+		{Name: "type", Expression: "resource"},
+	} {
+		_, err = ctrl.CreatePolicy(ctx, 1, invalid)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err), invalid.Name)
+	}
+
+	enabled := false
+	expression := "true"
+	policy, err = ctrl.UpdatePolicy(ctx, policy.ID, &UpdatePolicyRequest{
+		Enabled:    &enabled,
+		Expression: &expression,
+	})
+	assert.Nil(t, err)
+	assert.False(t, policy.Enabled)
+	assert.Equal(t, "true", policy.Expression)
+	assert.Equal(t, "replicas must be >= 2", policy.Message)
+
+	invalid := "1 +"
+	_, err = ctrl.UpdatePolicy(ctx, policy.ID, &UpdatePolicyRequest{Expression: &invalid})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	policies, total, err := ctrl.ListPolicies(ctx, 1, q.New(q.KeyWords{common.Enabled: false}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, policy.ID, policies[0].ID)
+	_, total, err = ctrl.ListPolicies(ctx, 1, q.New(q.KeyWords{common.Enabled: true}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+
+	assert.Nil(t, ctrl.DeletePolicy(ctx, policy.ID))
+	_, err = ctrl.GetPolicy(ctx, policy.ID)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
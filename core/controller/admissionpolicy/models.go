@@ -0,0 +1,124 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admissionpolicy
+
+import (
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/admission"
+	"github.com/horizoncd/horizon/pkg/admissionpolicy/models"
+)
+
+type CreatePolicyRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Enabled     bool     `json:"enabled"`
+	Resources   []string `json:"resources"`
+	Operations  []string `json:"operations"`
+	Expression  string   `json:"expression"`
+	Message     string   `json:"message"`
+}
+
+type UpdatePolicyRequest struct {
+	Name        *string  `json:"name"`
+	Description *string  `json:"description"`
+	Enabled     *bool    `json:"enabled"`
+	Resources   []string `json:"resources"`
+	Operations  []string `json:"operations"`
+	Expression  *string  `json:"expression"`
+	Message     *string  `json:"message"`
+}
+
+type Policy struct {
+	CreatePolicyRequest
+	ID        uint      `json:"id"`
+	GroupID   uint      `json:"groupID"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (r *CreatePolicyRequest) toModel(groupID uint) *models.AdmissionPolicy {
+	return &models.AdmissionPolicy{
+		GroupID:     groupID,
+		Name:        r.Name,
+		Description: r.Description,
+		Enabled:     r.Enabled,
+		Resources:   joinItems(r.Resources),
+		Operations:  joinItems(r.Operations),
+		Expression:  r.Expression,
+		Message:     r.Message,
+	}
+}
+
+func (r *UpdatePolicyRequest) toModel(policy *models.AdmissionPolicy) *models.AdmissionPolicy {
+	if r.Name != nil {
+		policy.Name = *r.Name
+	}
+	if r.Description != nil {
+		policy.Description = *r.Description
+	}
+	if r.Enabled != nil {
+		policy.Enabled = *r.Enabled
+	}
+	if r.Resources != nil {
+		policy.Resources = joinItems(r.Resources)
+	}
+	if r.Operations != nil {
+		policy.Operations = joinItems(r.Operations)
+	}
+	if r.Expression != nil {
+		policy.Expression = *r.Expression
+	}
+	if r.Message != nil {
+		policy.Message = *r.Message
+	}
+	return policy
+}
+
+func ofPolicyModel(policy *models.AdmissionPolicy) *Policy {
+	return &Policy{
+		CreatePolicyRequest: CreatePolicyRequest{
+			Name:        policy.Name,
+			Description: policy.Description,
+			Enabled:     policy.Enabled,
+			Resources:   splitItems(policy.Resources),
+			Operations:  splitItems(policy.Operations),
+			Expression:  policy.Expression,
+			Message:     policy.Message,
+		},
+		ID:        policy.ID,
+		GroupID:   policy.GroupID,
+		CreatedAt: policy.CreatedAt,
+		UpdatedAt: policy.UpdatedAt,
+	}
+}
+
+func joinItems(items []string) string {
+	trimmed := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			trimmed = append(trimmed, item)
+		}
+	}
+	return strings.Join(trimmed, admission.PolicySeparator)
+}
+
+func splitItems(items string) []string {
+	if items == "" {
+		return []string{}
+	}
+	return strings.Split(items, admission.PolicySeparator)
+}
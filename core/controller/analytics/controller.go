@@ -0,0 +1,242 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"context"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	pipelinemanager "github.com/horizoncd/horizon/pkg/pr/pipeline/manager"
+	pipelinemodels "github.com/horizoncd/horizon/pkg/pr/pipeline/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	_defaultPeriod = 30 * 24 * time.Hour
+	// _maxStatsPeriod limits the period of trends and hotspots, which load the stats of every step
+	_maxStatsPeriod = 90 * 24 * time.Hour
+	_maxDORAPeriod  = 366 * 24 * time.Hour
+
+	_defaultHotspotLimit = 10
+	_maxHotspotLimit     = 100
+)
+
+type Controller interface {
+	// GetPipelineTrends returns the success rates and durations of the pipelines, tasks and steps of application
+	// in the windows of the period
+	GetPipelineTrends(ctx context.Context, applicationID uint, request *TrendsRequest) (*Trends, error)
+	// GetPipelineHotspots returns the slowest and the most failing steps of the pipelines of application
+	GetPipelineHotspots(ctx context.Context, applicationID uint, request *HotspotsRequest) (*Hotspots, error)
+	GetApplicationDORAMetrics(ctx context.Context, applicationID uint, period *Period) (*DORAMetrics, error)
+	// GetGroupDORAMetrics returns the DORA metrics of the applications of group and its subgroups
+	GetGroupDORAMetrics(ctx context.Context, groupID uint, period *Period) (*DORAMetrics, error)
+	GetEnvironmentDORAMetrics(ctx context.Context, environmentID uint, period *Period) (*DORAMetrics, error)
+}
+
+type controller struct {
+	appMgr      appmanager.Manager
+	clusterMgr  clustermanager.Manager
+	groupMgr    groupmanager.Manager
+	envMgr      envmanager.Manager
+	prMgr       *prmanager.PRManager
+	pipelineMgr pipelinemanager.Manager
+}
+
+var _ Controller = (*controller)(nil)
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		appMgr:      param.ApplicationMgr,
+		clusterMgr:  param.ClusterMgr,
+		groupMgr:    param.GroupMgr,
+		envMgr:      param.EnvMgr,
+		prMgr:       param.PRMgr,
+		pipelineMgr: param.PipelineMgr,
+	}
+}
+
+func (c *controller) GetPipelineTrends(ctx context.Context, applicationID uint,
+	request *TrendsRequest) (_ *Trends, err error) {
+	const op = "analytics controller: get pipeline trends"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	var interval time.Duration
+	switch request.Interval {
+	case "", IntervalDay:
+		request.Interval, interval = IntervalDay, 24*time.Hour
+	case IntervalWeek:
+		interval = 7 * 24 * time.Hour
+	default:
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "interval must be %s or %s", IntervalDay, IntervalWeek)
+	}
+	query, err := c.statsQuery(ctx, applicationID, request.Cluster, &request.Period)
+	if err != nil {
+		return nil, err
+	}
+	pipelines, err := c.pipelineMgr.ListPipelines(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := c.pipelineMgr.ListTasks(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := c.pipelineMgr.ListSteps(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return trends(&request.Period, request.Interval, interval, pipelines, tasks, steps), nil
+}
+
+func (c *controller) GetPipelineHotspots(ctx context.Context, applicationID uint,
+	request *HotspotsRequest) (_ *Hotspots, err error) {
+	const op = "analytics controller: get pipeline hotspots"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if request.Limit == 0 {
+		request.Limit = _defaultHotspotLimit
+	}
+	if request.Limit < 0 || request.Limit > _maxHotspotLimit {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "limit must be between 1 and %d", _maxHotspotLimit)
+	}
+	query, err := c.statsQuery(ctx, applicationID, request.Cluster, &request.Period)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := c.pipelineMgr.ListSteps(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return hotspots(steps, request.Limit), nil
+}
+
+// statsQuery checks the request and returns the query of the stats of the pipelines of application
+func (c *controller) statsQuery(ctx context.Context, applicationID uint, cluster string,
+	period *Period) (*pipelinemodels.StatsQuery, error) {
+	if err := checkPeriod(period, _maxStatsPeriod); err != nil {
+		return nil, err
+	}
+	application, err := c.appMgr.GetByID(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if cluster != "" {
+		clusterModel, err := c.clusterMgr.GetByName(ctx, cluster)
+		if err != nil {
+			return nil, err
+		}
+		if clusterModel.ApplicationID != applicationID {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"cluster %s does not belong to application %s", cluster, application.Name)
+		}
+	}
+	return &pipelinemodels.StatsQuery{
+		Application: application.Name,
+		Cluster:     cluster,
+		Since:       period.Since,
+		Until:       period.Until,
+	}, nil
+}
+
+func (c *controller) GetApplicationDORAMetrics(ctx context.Context, applicationID uint,
+	period *Period) (_ *DORAMetrics, err error) {
+	const op = "analytics controller: get application dora metrics"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.appMgr.GetByID(ctx, applicationID); err != nil {
+		return nil, err
+	}
+	return c.doraMetrics(ctx, &prmodels.DeployQuery{ApplicationIDs: []uint{applicationID}}, period)
+}
+
+func (c *controller) GetGroupDORAMetrics(ctx context.Context, groupID uint,
+	period *Period) (_ *DORAMetrics, err error) {
+	const op = "analytics controller: get group dora metrics"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.groupMgr.GetByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+	groups, err := c.groupMgr.GetSubGroupsByGroupIDs(ctx, []uint{groupID})
+	if err != nil {
+		return nil, err
+	}
+	groupIDs := make([]uint, 0, len(groups))
+	for _, group := range groups {
+		groupIDs = append(groupIDs, group.ID)
+	}
+	applications, err := c.appMgr.GetByGroupIDs(ctx, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	applicationIDs := make([]uint, 0, len(applications))
+	for _, application := range applications {
+		applicationIDs = append(applicationIDs, application.ID)
+	}
+	return c.doraMetrics(ctx, &prmodels.DeployQuery{ApplicationIDs: applicationIDs}, period)
+}
+
+func (c *controller) GetEnvironmentDORAMetrics(ctx context.Context, environmentID uint,
+	period *Period) (_ *DORAMetrics, err error) {
+	const op = "analytics controller: get environment dora metrics"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	environment, err := c.envMgr.GetByID(ctx, environmentID)
+	if err != nil {
+		return nil, err
+	}
+	return c.doraMetrics(ctx, &prmodels.DeployQuery{EnvironmentName: environment.Name}, period)
+}
+
+func (c *controller) doraMetrics(ctx context.Context, query *prmodels.DeployQuery,
+	period *Period) (*DORAMetrics, error) {
+	if err := checkPeriod(period, _maxDORAPeriod); err != nil {
+		return nil, err
+	}
+	var deploys []*prmodels.Deploy
+	// a group without applications has no deploys
+	if query.ApplicationIDs == nil || len(query.ApplicationIDs) > 0 {
+		query.Since, query.Until = period.Since, period.Until
+		var err error
+		if deploys, err = c.prMgr.PipelineRun.ListDeploys(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+	return doraMetrics(period, deploys), nil
+}
+
+// checkPeriod sets the default period, and checks it is positive and not longer than maxPeriod
+func checkPeriod(period *Period, maxPeriod time.Duration) error {
+	if period.Until.IsZero() {
+		period.Until = time.Now()
+	}
+	if period.Since.IsZero() {
+		period.Since = period.Until.Add(-_defaultPeriod)
+	}
+	if !period.Since.Before(period.Until) || period.Until.Sub(period.Since) > maxPeriod {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"period must be positive and not longer than %d days", int(maxPeriod.Hours()/24))
+	}
+	return nil
+}
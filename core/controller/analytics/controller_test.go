@@ -0,0 +1,221 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	pipelinemodels "github.com/horizoncd/horizon/pkg/pr/pipeline/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+func TestController(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&groupmodels.Group{}, &appmodels.Application{}, &clustermodels.Cluster{},
+		&envmodels.Environment{}, &prmodels.Pipelinerun{}, &pipelinemodels.Pipeline{}, &pipelinemodels.Task{},
+		&pipelinemodels.Step{}))
+	assert.Nil(t, db.Create(&groupmodels.Group{Model: global.Model{ID: 1}, Name: "root", Path: "root",
+		TraversalIDs: "1"}).Error)
+	assert.Nil(t, db.Create(&groupmodels.Group{Model: global.Model{ID: 2}, Name: "sub", Path: "sub",
+		ParentID: 1, TraversalIDs: "1,2"}).Error)
+	assert.Nil(t, db.Create(&appmodels.Application{Model: global.Model{ID: 1}, Name: "app", GroupID: 1}).Error)
+	assert.Nil(t, db.Create(&appmodels.Application{Model: global.Model{ID: 2}, Name: "app2", GroupID: 2}).Error)
+	assert.Nil(t, db.Create(&envmodels.Environment{Model: global.Model{ID: 1}, Name: "test"}).Error)
+	assert.Nil(t, db.Create(&envmodels.Environment{Model: global.Model{ID: 2}, Name: "online"}).Error)
+	assert.Nil(t, db.Create(&clustermodels.Cluster{Model: global.Model{ID: 1}, ApplicationID: 1,
+		Name: "app-test", EnvironmentName: "test"}).Error)
+	assert.Nil(t, db.Create(&clustermodels.Cluster{Model: global.Model{ID: 2}, ApplicationID: 1,
+		Name: "app-online", EnvironmentName: "online"}).Error)
+	assert.Nil(t, db.Create(&clustermodels.Cluster{Model: global.Model{ID: 3}, ApplicationID: 2,
+		Name: "app2-online", EnvironmentName: "online"}).Error)
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	mgr := managerparam.InitManager(db)
+	c := &controller{
+		appMgr:      mgr.ApplicationMgr,
+		clusterMgr:  mgr.ClusterMgr,
+		groupMgr:    mgr.GroupMgr,
+		envMgr:      mgr.EnvMgr,
+		prMgr:       mgr.PRMgr,
+		pipelineMgr: mgr.PipelineMgr,
+	}
+
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	period := Period{Since: since, Until: since.Add(2 * 24 * time.Hour)}
+
+	// 1. trends and hotspots of the stats of pipelines
+	for i, run := range []struct {
+		cluster      string
+		day          int
+		result       string
+		build, image uint
+	}{
+		{"app-test", 0, "ok", 100, 10},
+		{"app-test", 0, "failed", 300, 0},
+		{"app-test", 0, "ok", 200, 20},
+		{"app-online", 1, "ok", 400, 30},
+		{"app-online", 3, "ok", 500, 40},
+	} {
+		startedAt := since.Add(time.Duration(run.day)*24*time.Hour + time.Duration(i)*time.Minute)
+		stats := []interface{}{
+			&pipelinemodels.Pipeline{PipelinerunID: uint(i), Application: "app", Cluster: run.cluster,
+				Result: run.result, Duration: run.build + run.image, StartedAt: startedAt},
+			&pipelinemodels.Task{PipelinerunID: uint(i), Application: "app", Cluster: run.cluster, Task: "build",
+				Result: run.result, Duration: run.build + run.image, StartedAt: startedAt},
+			&pipelinemodels.Step{PipelinerunID: uint(i), Application: "app", Cluster: run.cluster, Task: "build",
+				Step: "compile", Result: run.result, Duration: run.build, StartedAt: startedAt},
+		}
+		if run.result == "ok" {
+			stats = append(stats, &pipelinemodels.Step{PipelinerunID: uint(i), Application: "app",
+				Cluster: run.cluster, Task: "build", Step: "image", Result: run.result, Duration: run.image,
+				StartedAt: startedAt})
+		}
+		for _, s := range stats {
+			assert.Nil(t, db.Create(s).Error)
+		}
+	}
+
+	trends, err := c.GetPipelineTrends(ctx, 1, &TrendsRequest{Period: period})
+	assert.Nil(t, err)
+	assert.Equal(t, IntervalDay, trends.Interval)
+	assert.Equal(t, 2, len(trends.Windows))
+	first := trends.Windows[0]
+	assert.Equal(t, since, first.Start)
+	assert.Equal(t, &DurationStats{Runs: 3, Failures: 1, SuccessRate: 2.0 / 3, P50: 220, P90: 300}, first.Pipeline)
+	assert.Equal(t, 1, len(first.Tasks))
+	assert.Equal(t, 2, len(first.Steps))
+	assert.Equal(t, &DurationStats{Task: "build", Step: "compile", Runs: 3, Failures: 1,
+		SuccessRate: 2.0 / 3, P50: 200, P90: 300}, first.Steps[0])
+	assert.Equal(t, 1, trends.Windows[1].Pipeline.Runs)
+
+	trends, err = c.GetPipelineTrends(ctx, 1, &TrendsRequest{Period: period, Cluster: "app-online",
+		Interval: IntervalWeek})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(trends.Windows))
+	assert.Equal(t, 1, trends.Windows[0].Pipeline.Runs)
+
+	_, err = c.GetPipelineTrends(ctx, 1, &TrendsRequest{Period: period, Interval: "hour"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.GetPipelineTrends(ctx, 1, &TrendsRequest{Period: period, Cluster: "app2-online"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = c.GetPipelineTrends(ctx, 1, &TrendsRequest{
+		Period: Period{Since: since, Until: since.Add(_maxStatsPeriod + time.Hour)},
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	hotspots, err := c.GetPipelineHotspots(ctx, 1, &HotspotsRequest{Period: period, Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(hotspots.SlowestSteps))
+	assert.Equal(t, "compile", hotspots.SlowestSteps[0].Step)
+	assert.Equal(t, uint(400), hotspots.SlowestSteps[0].P90)
+	assert.Equal(t, 1, len(hotspots.MostFailingSteps))
+	assert.Equal(t, 1, hotspots.MostFailingSteps[0].Failures)
+
+	// 2. dora metrics of the deploys
+	at := func(hours int) *time.Time {
+		t := since.Add(time.Duration(hours) * time.Hour)
+		return &t
+	}
+	prs := []*prmodels.Pipelinerun{
+		// the commit is deployed to the test cluster at first, and then to the online cluster
+		{ClusterID: 1, Action: prmodels.ActionBuildDeploy, Status: "ok", GitCommit: "c1",
+			CreatedAt: *at(0), FinishedAt: at(1)},
+		// the failed build is not a change
+		{ClusterID: 1, Action: prmodels.ActionBuildDeploy, Status: "failed", GitCommit: "c4",
+			CreatedAt: *at(2), FinishedAt: at(3)},
+		{ClusterID: 2, Action: prmodels.ActionDeploy, Status: "failed", GitCommit: "c1",
+			CreatedAt: *at(4), FinishedAt: at(5)},
+		{ClusterID: 2, Action: prmodels.ActionDeploy, Status: "ok", GitCommit: "c1",
+			CreatedAt: *at(6), FinishedAt: at(7)},
+		// the online cluster is rolled back after the deploy of another commit
+		{ClusterID: 2, Action: prmodels.ActionBuildDeploy, Status: "ok", GitCommit: "c2",
+			CreatedAt: *at(10), FinishedAt: at(11)},
+		{ClusterID: 2, Action: prmodels.ActionRollback, Status: "ok", GitCommit: "c1",
+			CreatedAt: *at(12), FinishedAt: at(13)},
+		// restarts are not deploys, and the restart not after a change is not a remediation
+		{ClusterID: 2, Action: prmodels.ActionRestart, Status: "ok", CreatedAt: *at(14), FinishedAt: at(15)},
+		// running pipelineruns are not finished
+		{ClusterID: 3, Action: prmodels.ActionBuildDeploy, Status: "running", CreatedAt: *at(14)},
+		// the deploy task of the build failed
+		{ClusterID: 3, Action: prmodels.ActionBuildDeploy, Status: "failed", GitCommit: "c3",
+			CreatedAt: *at(16), FinishedAt: at(17)},
+		{ClusterID: 3, Action: prmodels.ActionBuildDeploy, Status: "ok", GitCommit: "c3",
+			CreatedAt: *at(20), FinishedAt: at(22)},
+	}
+	for _, pr := range prs {
+		assert.Nil(t, db.Create(pr).Error)
+	}
+	for _, task := range []*pipelinemodels.Task{
+		{PipelinerunID: prs[1].ID, Task: "build", Result: "failed"},
+		{PipelinerunID: prs[8].ID, Task: "build", Result: "ok"},
+		{PipelinerunID: prs[8].ID, Task: "deploy", Result: "failed"},
+	} {
+		assert.Nil(t, db.Create(task).Error)
+	}
+
+	metrics, err := c.GetApplicationDORAMetrics(ctx, 1, &period)
+	assert.Nil(t, err)
+	assert.Equal(t, &DORAMetrics{
+		Since:   period.Since,
+		Until:   period.Until,
+		Deploys: 4,
+		// 4 deploys in 2 days
+		DeployFrequency: 2,
+		// lead times of c1 are 1h and 7h, and of c2 is 1h
+		LeadTime: 3600,
+		// the failed deploy of c1 and the rolled back deploy of c2 in 4 changes
+		ChangeFailureRate: 0.5,
+		Incidents:         2,
+		MeanTimeToRestore: 7200,
+	}, metrics)
+
+	metrics, err = c.GetGroupDORAMetrics(ctx, 2, &period)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, metrics.Deploys)
+	assert.Equal(t, uint(21600), metrics.LeadTime)
+	assert.Equal(t, 0.5, metrics.ChangeFailureRate)
+	assert.Equal(t, 1, metrics.Incidents)
+	assert.Equal(t, uint(18000), metrics.MeanTimeToRestore)
+	metrics, err = c.GetGroupDORAMetrics(ctx, 1, &period)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, metrics.Deploys)
+
+	metrics, err = c.GetEnvironmentDORAMetrics(ctx, 2, &period)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, metrics.Deploys)
+	assert.Equal(t, 0.6, metrics.ChangeFailureRate)
+
+	_, err = c.GetEnvironmentDORAMetrics(ctx, 3, &period)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
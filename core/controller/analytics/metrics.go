@@ -0,0 +1,263 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	pipelinemodels "github.com/horizoncd/horizon/pkg/pr/pipeline/models"
+)
+
+// durations collects the durations and results of the runs of a pipeline, a task or a step
+type durations struct {
+	task, step string
+	values     []uint
+	failures   int
+}
+
+func (d *durations) add(duration uint, result string) {
+	d.values = append(d.values, duration)
+	if result != string(prmodels.StatusOK) {
+		d.failures++
+	}
+}
+
+func (d *durations) stats() *DurationStats {
+	stats := &DurationStats{Task: d.task, Step: d.step, Runs: len(d.values), Failures: d.failures}
+	if stats.Runs == 0 {
+		return stats
+	}
+	stats.SuccessRate = float64(stats.Runs-stats.Failures) / float64(stats.Runs)
+	values := make([]uint, len(d.values))
+	copy(values, d.values)
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	stats.P50, stats.P90 = percentile(values, 0.5), percentile(values, 0.9)
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of the sorted values
+func percentile(sorted []uint, p float64) uint {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// durationsGroup collects the durations by task and step, in the order they first run
+type durationsGroup struct {
+	keys []string
+	m    map[string]*durations
+}
+
+func newDurationsGroup() *durationsGroup {
+	return &durationsGroup{keys: make([]string, 0), m: make(map[string]*durations)}
+}
+
+func (g *durationsGroup) add(task, step string, duration uint, result string) {
+	key := task + "/" + step
+	d, ok := g.m[key]
+	if !ok {
+		d = &durations{task: task, step: step}
+		g.keys = append(g.keys, key)
+		g.m[key] = d
+	}
+	d.add(duration, result)
+}
+
+func (g *durationsGroup) list() []*DurationStats {
+	list := make([]*DurationStats, 0, len(g.keys))
+	for _, key := range g.keys {
+		list = append(list, g.m[key].stats())
+	}
+	return list
+}
+
+type trendWindow struct {
+	start    time.Time
+	pipeline *durations
+	tasks    *durationsGroup
+	steps    *durationsGroup
+}
+
+func trends(period *Period, intervalName string, interval time.Duration, pipelines []*pipelinemodels.Pipeline,
+	tasks []*pipelinemodels.Task, steps []*pipelinemodels.Step) *Trends {
+	windows := make([]*trendWindow, 0)
+	for start := period.Since; start.Before(period.Until); start = start.Add(interval) {
+		windows = append(windows, &trendWindow{
+			start:    start,
+			pipeline: &durations{},
+			tasks:    newDurationsGroup(),
+			steps:    newDurationsGroup(),
+		})
+	}
+	windowOf := func(startedAt time.Time) *trendWindow {
+		i := int(startedAt.Sub(period.Since) / interval)
+		if i < 0 || i >= len(windows) {
+			return nil
+		}
+		return windows[i]
+	}
+	for _, pipeline := range pipelines {
+		if w := windowOf(pipeline.StartedAt); w != nil {
+			w.pipeline.add(pipeline.Duration, pipeline.Result)
+		}
+	}
+	for _, task := range tasks {
+		if w := windowOf(task.StartedAt); w != nil {
+			w.tasks.add(task.Task, "", task.Duration, task.Result)
+		}
+	}
+	for _, step := range steps {
+		if w := windowOf(step.StartedAt); w != nil {
+			w.steps.add(step.Task, step.Step, step.Duration, step.Result)
+		}
+	}
+
+	result := &Trends{Interval: intervalName, Windows: make([]*TrendWindow, 0, len(windows))}
+	for _, w := range windows {
+		result.Windows = append(result.Windows, &TrendWindow{
+			Start:    w.start,
+			Pipeline: w.pipeline.stats(),
+			Tasks:    w.tasks.list(),
+			Steps:    w.steps.list(),
+		})
+	}
+	return result
+}
+
+func hotspots(steps []*pipelinemodels.Step, limit int) *Hotspots {
+	group := newDurationsGroup()
+	for _, step := range steps {
+		group.add(step.Task, step.Step, step.Duration, step.Result)
+	}
+	list := group.list()
+
+	slowest := make([]*DurationStats, len(list))
+	copy(slowest, list)
+	sort.SliceStable(slowest, func(i, j int) bool { return slowest[i].P90 > slowest[j].P90 })
+
+	failing := make([]*DurationStats, 0)
+	for _, stats := range list {
+		if stats.Failures > 0 {
+			failing = append(failing, stats)
+		}
+	}
+	sort.SliceStable(failing, func(i, j int) bool {
+		if failing[i].Failures != failing[j].Failures {
+			return failing[i].Failures > failing[j].Failures
+		}
+		return failing[i].SuccessRate < failing[j].SuccessRate
+	})
+
+	if len(slowest) > limit {
+		slowest = slowest[:limit]
+	}
+	if len(failing) > limit {
+		failing = failing[:limit]
+	}
+	return &Hotspots{SlowestSteps: slowest, MostFailingSteps: failing}
+}
+
+// doraMetrics computes the DORA metrics of the deploys ordered by finish time
+func doraMetrics(period *Period, deploys []*prmodels.Deploy) *DORAMetrics {
+	metrics := &DORAMetrics{Since: period.Since, Until: period.Until}
+
+	type commitKey struct {
+		applicationID uint
+		commit        string
+	}
+	firstCreated := make(map[commitKey]time.Time)
+	byCluster := make(map[uint][]*prmodels.Deploy)
+	clusterIDs := make([]uint, 0)
+	for _, deploy := range deploys {
+		if deploy.FinishedAt == nil {
+			continue
+		}
+		if deploy.GitCommit != "" {
+			key := commitKey{deploy.ApplicationID, deploy.GitCommit}
+			if created, ok := firstCreated[key]; !ok || deploy.CreatedAt.Before(created) {
+				firstCreated[key] = deploy.CreatedAt
+			}
+		}
+		// the pipelineruns failed before deploying, such as the failed builds, never changed the cluster
+		if deploy.Status != string(prmodels.StatusOK) && !deploy.DeployFailed {
+			continue
+		}
+		if _, ok := byCluster[deploy.ClusterID]; !ok {
+			clusterIDs = append(clusterIDs, deploy.ClusterID)
+		}
+		byCluster[deploy.ClusterID] = append(byCluster[deploy.ClusterID], deploy)
+	}
+
+	leadTimes := make([]uint, 0)
+	changes, failedChanges := 0, 0
+	var restoreTime time.Duration
+	for _, clusterID := range clusterIDs {
+		clusterDeploys := byCluster[clusterID]
+		var failedAt *time.Time
+		for i, deploy := range clusterDeploys {
+			ok := deploy.Status == string(prmodels.StatusOK)
+			// rollbacks and restarts are remediation of changes, but not changes themselves
+			change := deploy.Action == prmodels.ActionBuildDeploy || deploy.Action == prmodels.ActionDeploy
+			if ok && deploy.Action != prmodels.ActionRestart {
+				metrics.Deploys++
+				if deploy.GitCommit != "" && change {
+					created := firstCreated[commitKey{deploy.ApplicationID, deploy.GitCommit}]
+					leadTimes = append(leadTimes, uint(deploy.FinishedAt.Sub(created).Seconds()))
+				}
+			}
+
+			if ok && failedAt != nil {
+				metrics.Incidents++
+				restoreTime += deploy.FinishedAt.Sub(*failedAt)
+				failedAt = nil
+			}
+			if !change {
+				continue
+			}
+			changes++
+			remediated := ok && i+1 < len(clusterDeploys) &&
+				(clusterDeploys[i+1].Action == prmodels.ActionRollback ||
+					clusterDeploys[i+1].Action == prmodels.ActionRestart)
+			if !ok || remediated {
+				failedChanges++
+				if failedAt == nil {
+					failedAt = deploy.FinishedAt
+				}
+			}
+		}
+	}
+
+	days := period.Until.Sub(period.Since).Hours() / 24
+	metrics.DeployFrequency = float64(metrics.Deploys) / days
+	if len(leadTimes) > 0 {
+		sort.Slice(leadTimes, func(i, j int) bool { return leadTimes[i] < leadTimes[j] })
+		metrics.LeadTime = percentile(leadTimes, 0.5)
+	}
+	if changes > 0 {
+		metrics.ChangeFailureRate = float64(failedChanges) / float64(changes)
+	}
+	if metrics.Incidents > 0 {
+		metrics.MeanTimeToRestore = uint(restoreTime.Seconds()) / uint(metrics.Incidents)
+	}
+	return metrics
+}
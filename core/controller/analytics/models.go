@@ -0,0 +1,97 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"time"
+)
+
+const (
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+// Period is the period of time to analyze, the last 30 days by default
+type Period struct {
+	Since time.Time
+	Until time.Time
+}
+
+type TrendsRequest struct {
+	Period
+	// Cluster is optional, all the clusters of the application are analyzed if it is empty
+	Cluster string
+	// Interval is the length of the windows starting from Since, day or week
+	Interval string
+}
+
+type HotspotsRequest struct {
+	Period
+	// Cluster is optional, all the clusters of the application are analyzed if it is empty
+	Cluster string
+	// Limit is the count of the steps in each list
+	Limit int
+}
+
+// DurationStats is the stats of the runs of a pipeline, a task or a step
+type DurationStats struct {
+	Task        string  `json:"task,omitempty"`
+	Step        string  `json:"step,omitempty"`
+	Runs        int     `json:"runs"`
+	Failures    int     `json:"failures"`
+	SuccessRate float64 `json:"successRate"`
+	// P50 and P90 are the percentiles of the durations in seconds
+	P50 uint `json:"p50"`
+	P90 uint `json:"p90"`
+}
+
+type TrendWindow struct {
+	Start    time.Time        `json:"start"`
+	Pipeline *DurationStats   `json:"pipeline"`
+	Tasks    []*DurationStats `json:"tasks"`
+	Steps    []*DurationStats `json:"steps"`
+}
+
+type Trends struct {
+	Interval string         `json:"interval"`
+	Windows  []*TrendWindow `json:"windows"`
+}
+
+type Hotspots struct {
+	// SlowestSteps are the steps with the largest p90 durations
+	SlowestSteps []*DurationStats `json:"slowestSteps"`
+	// MostFailingSteps are the steps failed the most times
+	MostFailingSteps []*DurationStats `json:"mostFailingSteps"`
+}
+
+type DORAMetrics struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	// Deploys is the count of the successful builddeploy, deploy and rollback pipelineruns
+	Deploys int `json:"deploys"`
+	// DeployFrequency is the count of successful deploys per day
+	DeployFrequency float64 `json:"deployFrequency"`
+	// LeadTime is the median of the seconds from the first pipelinerun of a commit of the application
+	// in the period to the successful deploys of the commit
+	LeadTime uint `json:"leadTime"`
+	// ChangeFailureRate is the rate of the builddeploy and deploy pipelineruns which failed when deploying,
+	// or were rolled back or restarted right after they succeeded. The builds failed are not changes
+	ChangeFailureRate float64 `json:"changeFailureRate"`
+	// Incidents is the count of the failed changes of clusters restored by successful pipelineruns later
+	Incidents int `json:"incidents"`
+	// MeanTimeToRestore is the mean of the seconds from a failed change of a cluster
+	// to its next successful deploy, rollback or restart
+	MeanTimeToRestore uint `json:"meanTimeToRestore"`
+}
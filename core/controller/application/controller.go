@@ -35,9 +35,11 @@ import (
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	groupsvc "github.com/horizoncd/horizon/pkg/group/service"
 	"github.com/horizoncd/horizon/pkg/member"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
 	pipelinemanager "github.com/horizoncd/horizon/pkg/pr/pipeline/manager"
 	pipelinemodels "github.com/horizoncd/horizon/pkg/pr/pipeline/models"
@@ -94,6 +96,7 @@ type controller struct {
 	clusterMgr           clustermanager.Manager
 	userSvc              usersvc.Service
 	memberManager        member.Manager
+	memberSvc            memberservice.Service
 	eventSvc             eventservice.Service
 	tagMgr               tagmanager.Manager
 	applicationRegionMgr applicationregionmanager.Manager
@@ -115,6 +118,7 @@ func NewController(param *param.Param) Controller {
 		clusterMgr:           param.ClusterMgr,
 		userSvc:              param.UserSvc,
 		memberManager:        param.MemberMgr,
+		memberSvc:            param.MemberService,
 		eventSvc:             param.EventSvc,
 		tagMgr:               param.TagMgr,
 		applicationRegionMgr: param.ApplicationRegionMgr,
@@ -192,10 +196,11 @@ func (c *controller) GetApplicationV2(ctx context.Context, id uint) (_ *GetAppli
 	}
 
 	resp := &GetApplicationResponseV2{
-		ID:          id,
-		Name:        app.Name,
-		Description: app.Description,
-		Priority:    string(app.Priority),
+		ID:              id,
+		Name:            app.Name,
+		Description:     app.Description,
+		Priority:        string(app.Priority),
+		VisibilityLevel: app.VisibilityLevel,
 		Git: func() *codemodels.Git {
 			if app.GitURL == "" {
 				return nil
@@ -347,6 +352,11 @@ func (c *controller) CreateApplicationV2(ctx context.Context, groupID uint,
 			return nil, err
 		}
 	}
+	if request.VisibilityLevel != nil {
+		if err := validateVisibility(*request.VisibilityLevel); err != nil {
+			return nil, err
+		}
+	}
 	if request.Git != nil {
 		if err := validate.CheckGitURL(request.Git.URL); err != nil {
 			return nil, err
@@ -527,6 +537,11 @@ func (c *controller) UpdateApplicationV2(ctx context.Context, id uint,
 			return err
 		}
 	}
+	if request.VisibilityLevel != nil {
+		if err := validateVisibility(*request.VisibilityLevel); err != nil {
+			return err
+		}
+	}
 	if request.Git != nil {
 		if err := validate.CheckGitURL(request.Git.URL); err != nil {
 			return err
@@ -702,6 +717,14 @@ func validatePriority(priority string) error {
 	return nil
 }
 
+// validateVisibility validate visibility level
+func validateVisibility(level string) error {
+	if !groupmodels.IsValidVisibility(level) {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid visibilityLevel: %s", level)
+	}
+	return nil
+}
+
 // validateApplicationName validate application name
 // 1. name length must be less than 40
 // 2. name must match pattern ^(([a-z][-a-z0-9]*)?[a-z0-9])?$
@@ -774,6 +797,18 @@ func (c *controller) List(ctx context.Context, query *q.Query) (
 		}
 	}
 
+	// hide the applications invisible to the current user
+	invisible, err := c.memberSvc.ListInvisible(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if invisible != nil {
+		if query.Keywords == nil {
+			query.Keywords = q.KeyWords{}
+		}
+		query.Keywords[common.ApplicationQueryInvisible] = invisible
+	}
+
 	listApplicationResp = []*ListApplicationResponse{}
 	// 1. get application in db
 	count, applications, err := c.applicationMgr.List(ctx, subGroupIDs, query)
@@ -37,10 +37,12 @@ import (
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	groupservice "github.com/horizoncd/horizon/pkg/group/service"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	teammodels "github.com/horizoncd/horizon/pkg/team/models"
 	tmodels "github.com/horizoncd/horizon/pkg/template/models"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
@@ -303,6 +305,9 @@ func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&appregionmodels.ApplicationRegion{}); err != nil {
 		panic(err)
 	}
+	if err := db.AutoMigrate(&teammodels.Team{}, &teammodels.TeamMember{}); err != nil {
+		panic(err)
+	}
 	ctx = context.TODO()
 	ctx = context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
 		Name: "Tony",
@@ -682,8 +687,9 @@ func TestListUserApplication(t *testing.T) {
 	}
 
 	c = NewController(&param.Param{
-		Manager:  manager,
-		GroupSvc: groupservice.NewService(manager),
+		Manager:       manager,
+		GroupSvc:      groupservice.NewService(manager),
+		MemberService: memberservice.NewService(nil, nil, manager),
 	})
 
 	// nolint
@@ -749,4 +755,17 @@ func TestListUserApplication(t *testing.T) {
 	for _, resp := range resps {
 		t.Logf("%v", resp)
 	}
+
+	// private applications are hidden from non-members
+	privateApp := *applications[4]
+	privateApp.VisibilityLevel = groupmodels.VisibilityPrivate
+	_, err = manager.ApplicationMgr.UpdateByID(ctx, privateApp.ID, &privateApp)
+	assert.Nil(t, err)
+	_, count, err = c.List(ctx, &q.Query{
+		Keywords:   q.KeyWords{common.ApplicationQueryName: "appFu"},
+		PageNumber: 1,
+		PageSize:   common.DefaultPageSize,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, count)
 }
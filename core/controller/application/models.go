@@ -19,6 +19,7 @@ import (
 
 	"github.com/horizoncd/horizon/pkg/application/models"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 )
@@ -90,6 +91,7 @@ func (m *CreateApplicationRequest) toApplicationModel(groupID uint) *models.Appl
 		GitRef:          m.Git.Ref(),
 		Template:        m.Template.Name,
 		TemplateRelease: m.Template.Release,
+		VisibilityLevel: groupmodels.VisibilityPublic,
 	}
 }
 
@@ -104,6 +106,7 @@ func (m *UpdateApplicationRequest) toApplicationModel(appExistsInDB *models.Appl
 		GitRefType:      appExistsInDB.GitRefType,
 		Template:        appExistsInDB.Template,
 		TemplateRelease: appExistsInDB.TemplateRelease,
+		VisibilityLevel: appExistsInDB.VisibilityLevel,
 	}
 	application.Description = m.Description
 	if m.Priority != "" {
@@ -19,6 +19,7 @@ import (
 
 	"github.com/horizoncd/horizon/pkg/application/models"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 )
 
@@ -30,6 +31,8 @@ type GetApplicationResponseV2 struct {
 	Tags        tagmodels.TagsBasic `json:"tags,omitempty"`
 	Git         *codemodels.Git     `json:"git"`
 	Image       string              `json:"image"`
+	// VisibilityLevel the visibility level of the application itself, the groups' levels are inherited
+	VisibilityLevel string `json:"visibilityLevel"`
 
 	BuildConfig    map[string]interface{}   `json:"buildConfig"`
 	TemplateInfo   *codemodels.TemplateInfo `json:"templateInfo"`
@@ -54,6 +57,8 @@ type CreateOrUpdateApplicationRequestV2 struct {
 	BuildConfig    map[string]interface{}   `json:"buildConfig"`
 	TemplateInfo   *codemodels.TemplateInfo `json:"templateInfo"`
 	TemplateConfig map[string]interface{}   `json:"templateConfig"`
+	// VisibilityLevel public, internal or private, public by default
+	VisibilityLevel *string `json:"visibilityLevel"`
 
 	// TODO(remove it): only for internal usage
 	ExtraMembers map[string]string `json:"extraMembers"`
@@ -112,6 +117,12 @@ func (req *CreateOrUpdateApplicationRequestV2) CreateToApplicationModel(groupID
 			}
 			return ""
 		}(),
+		VisibilityLevel: func() string {
+			if req.VisibilityLevel != nil && *req.VisibilityLevel != "" {
+				return *req.VisibilityLevel
+			}
+			return groupmodels.VisibilityPublic
+		}(),
 	}
 }
 
@@ -127,6 +138,7 @@ func (req *CreateOrUpdateApplicationRequestV2) UpdateToApplicationModel(
 		Image:           appExistsInDB.Image,
 		Template:        appExistsInDB.Template,
 		TemplateRelease: appExistsInDB.TemplateRelease,
+		VisibilityLevel: appExistsInDB.VisibilityLevel,
 	}
 	application.Description = req.Description
 	if req.Priority != nil {
@@ -145,6 +157,9 @@ func (req *CreateOrUpdateApplicationRequestV2) UpdateToApplicationModel(
 		application.Template = req.TemplateInfo.Name
 		application.TemplateRelease = req.TemplateInfo.Release
 	}
+	if req.VisibilityLevel != nil && *req.VisibilityLevel != "" {
+		application.VisibilityLevel = *req.VisibilityLevel
+	}
 	return application
 }
 
@@ -23,6 +23,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
 	"github.com/horizoncd/horizon/pkg/cluster/metrics/tekton"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
 	perror "github.com/horizoncd/horizon/pkg/errors"
@@ -71,18 +72,17 @@ func (c *controller) CloudEvent(ctx context.Context, wpr *WrappedPipelineRun) (e
 	const op = "cloudEvent controller: cloudEvent"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	horizonMetaData, err := c.getHorizonMetaData(ctx, wpr)
+	horizonMetaData, cluster, err := c.getHorizonMetaData(ctx, wpr)
 	if err != nil {
 		return err
 	}
 	log.Infof(ctx, "got cloudEvent of pipelineRun %v, event id: %v",
 		horizonMetaData.PipelinerunID, horizonMetaData.EventID)
 
-	environment := horizonMetaData.Environment
 	pipelinerunID := horizonMetaData.PipelinerunID
 
-	// 1. collect log & pipelinerun object
-	tektonCollector, err := c.tektonFty.GetTektonCollector(environment)
+	// 1. collect log & pipelinerun object by the CI backend of the cluster
+	tektonCollector, err := c.tektonFty.GetTektonCollector(cluster.EnvironmentName, cluster.Template)
 	if err != nil {
 		return err
 	}
@@ -110,6 +110,11 @@ func (c *controller) CloudEvent(ctx context.Context, wpr *WrappedPipelineRun) (e
 		return err
 	}
 
+	// 3. store logs of steps for searching, the pipelinerun is collected even if it fails
+	if err := c.createStepLogs(ctx, result.StepLogs, horizonMetaData); err != nil {
+		log.Errorf(ctx, "failed to create step logs of pipelinerun %v, err: %v", pipelinerunID, err)
+	}
+
 	// format Pipeline results
 	pipelineResult := tekton.FormatPipelineResults(wpr.PipelineRun)
 
@@ -131,6 +136,26 @@ func (c *controller) CloudEvent(ctx context.Context, wpr *WrappedPipelineRun) (e
 	return nil
 }
 
+func (c *controller) createStepLogs(ctx context.Context, stepLogs []*collector.StepLog,
+	data *global.HorizonMetaData) error {
+	logs := make([]*prmodels.StepLog, 0, len(stepLogs))
+	for i, stepLog := range stepLogs {
+		logs = append(logs, &prmodels.StepLog{
+			PipelinerunID: data.PipelinerunID,
+			ApplicationID: data.ApplicationID,
+			ClusterID:     data.ClusterID,
+			Task:          stepLog.Task,
+			Step:          stepLog.Step,
+			Sequence:      i,
+			StartedAt:     stepLog.StartedAt,
+			FinishedAt:    stepLog.FinishedAt,
+			LineCount:     len(stepLog.Lines),
+			Content:       strings.Join(stepLog.Lines, "\n"),
+		})
+	}
+	return c.prMgr.StepLog.Create(ctx, logs)
+}
+
 // TODO remove this function in the future
 // check cluster's build type, change tasks' and steps' values if needed
 func (c *controller) handleJibBuild(ctx context.Context, result *tekton.PipelineResults,
@@ -174,23 +199,23 @@ func (c *controller) handleJibBuild(ctx context.Context, result *tekton.Pipeline
 
 // getHorizonMetaData resolves info about this pipelinerun
 func (c *controller) getHorizonMetaData(ctx context.Context, wpr *WrappedPipelineRun) (
-	*global.HorizonMetaData, error) {
+	*global.HorizonMetaData, *clustermodels.Cluster, error) {
 	eventID := wpr.PipelineRun.Labels[common.TektonTriggersEventIDKey]
 	pipelinerun, err := c.prMgr.PipelineRun.GetByCIEventID(ctx, eventID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	cluster, err := c.clusterMgr.GetByID(ctx, pipelinerun.ClusterID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	user, err := c.userMgr.GetUserByID(ctx, pipelinerun.CreatedBy)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	return &global.HorizonMetaData{
@@ -204,5 +229,5 @@ func (c *controller) getHorizonMetaData(ctx context.Context, wpr *WrappedPipelin
 		Region:        cluster.RegionName,
 		Template:      cluster.Template,
 		EventID:       eventID,
-	}, nil
+	}, cluster, nil
 }
@@ -87,6 +87,9 @@ func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&trmodels.TemplateRelease{}); err != nil {
 		panic(err)
 	}
+	if err := db.AutoMigrate(&prmodels.StepLog{}); err != nil {
+		panic(err)
+	}
 	ctx = context.TODO()
 	ctx = context.WithValue(ctx, common.UserContextKey(), &userauth.DefaultInfo{
 		Name: "Tony",
@@ -226,8 +229,9 @@ func Test(t *testing.T) {
 	tektonFty := tektonftymock.NewMockFactory(mockCtl)
 	tekton := tektonmock.NewMockInterface(mockCtl)
 	tektonCollector := tektoncollectormock.NewMockInterface(mockCtl)
-	tektonFty.EXPECT().GetTekton(gomock.Any()).Return(tekton, nil).AnyTimes()
-	tektonFty.EXPECT().GetTektonCollector(gomock.Any()).Return(tektonCollector, nil).AnyTimes()
+	tektonFty.EXPECT().GetTekton(gomock.Any(), gomock.Any()).Return(tekton, nil).AnyTimes()
+	// the collector is of the CI backend of the cluster's template
+	tektonFty.EXPECT().GetTektonCollector("test", "javaapp").Return(tektonCollector, nil).AnyTimes()
 
 	tektonCollector.EXPECT().Collect(ctx, gomock.Any(), gomock.Any()).Return(&collector.CollectResult{
 		Bucket:    "bucket",
@@ -246,6 +250,9 @@ func Test(t *testing.T) {
 				Time: tt,
 			}
 		}(),
+		StepLogs: []*collector.StepLog{
+			{Task: "build", Step: "compile", Lines: []string{"compiling", "done"}},
+		},
 	}, nil)
 
 	templateReleaseMgr := trmock.NewMockManager(mockCtl)
@@ -264,8 +271,10 @@ func Test(t *testing.T) {
 		Name: "user",
 	})
 	cluster, _ := manager.ClusterMgr.Create(ctx, &clustermodels.Cluster{
-		ApplicationID: application.ID,
-		Name:          "cluster",
+		ApplicationID:   application.ID,
+		Name:            "cluster",
+		EnvironmentName: "test",
+		Template:        "javaapp",
 	}, nil, nil)
 	pipelinerunMgr := manager.PRMgr.PipelineRun
 	_, err := pipelinerunMgr.Create(ctx, &prmodels.Pipelinerun{
@@ -292,4 +301,11 @@ func Test(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, pr.Status, "ok")
 	assert.Equal(t, pr.LogObject, "log-object")
+
+	stepLogs, err := manager.PRMgr.StepLog.ListByPipelinerunID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(stepLogs))
+	assert.Equal(t, application.ID, stepLogs[0].ApplicationID)
+	assert.Equal(t, 2, stepLogs[0].LineCount)
+	assert.Equal(t, "compiling\ndone", stepLogs[0].Content)
 }
@@ -39,6 +39,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/config/grafana"
 	"github.com/horizoncd/horizon/pkg/config/template"
 	"github.com/horizoncd/horizon/pkg/config/token"
+	deploywindowservice "github.com/horizoncd/horizon/pkg/deploywindow/service"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	environmentregionmapper "github.com/horizoncd/horizon/pkg/environmentregion/manager"
@@ -46,7 +47,9 @@ import (
 	grafanaservice "github.com/horizoncd/horizon/pkg/grafana"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	groupsvc "github.com/horizoncd/horizon/pkg/group/service"
+	imagepolicymanager "github.com/horizoncd/horizon/pkg/imagepolicy/manager"
 	"github.com/horizoncd/horizon/pkg/member"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
@@ -116,6 +119,11 @@ type Controller interface {
 	CreateClusterV2(ctx context.Context, params *CreateClusterParamsV2) (*CreateClusterResponseV2, error)
 	GetClusterV2(ctx context.Context, clusterID uint) (*GetClusterResponseV2, error)
 	UpdateClusterV2(ctx context.Context, clusterID uint, r *UpdateClusterRequestV2, mergePatch bool) error
+	// DryRunCreateClusterV2 and DryRunUpdateClusterV2 validate the request and render the manifests
+	// of cluster without writing anything
+	DryRunCreateClusterV2(ctx context.Context, params *CreateClusterParamsV2) (*DryRunClusterResponseV2, error)
+	DryRunUpdateClusterV2(ctx context.Context, clusterID uint,
+		r *UpdateClusterRequestV2, mergePatch bool) (*DryRunClusterResponseV2, error)
 	// InternalDeployV2 deploy only used by internal system
 	InternalDeployV2(ctx context.Context, clusterID uint,
 		r *InternalDeployRequestV2) (_ *InternalDeployResponseV2, err error)
@@ -126,6 +134,9 @@ type Controller interface {
 	GetStep(ctx context.Context, clusterID uint) (resp *GetStepResponse, err error)
 	// Deprecated: for internal usage, v1 to v2
 	Upgrade(ctx context.Context, clusterID uint) error
+	// UpgradeTemplateRelease upgrades the template release of cluster and keeps its values, which are validated
+	// against the schema of target release, nothing is written if dryRun is true
+	UpgradeTemplateRelease(ctx context.Context, clusterID uint, release string, dryRun bool) error
 	ToggleLikeStatus(ctx context.Context, clusterID uint, like *WhetherLike) (err error)
 	CreatePipelineRun(ctx context.Context, clusterID uint, r *CreatePipelineRunRequest) (*prmodels.PipelineBasic, error)
 }
@@ -154,9 +165,11 @@ type controller struct {
 	pipelineMgr           pipelinemanager.Manager
 	tektonFty             factory.Factory
 	registryFty           registryfty.RegistryGetter
+	imagePolicyMgr        imagepolicymanager.Manager
 	userManager           usermanager.Manager
 	userSvc               usersvc.Service
 	memberManager         member.Manager
+	memberSvc             memberservice.Service
 	groupManager          groupmanager.Manager
 	schemaTagManager      templateschematagmanager.Manager
 	tagMgr                tagmanager.Manager
@@ -169,6 +182,7 @@ type controller struct {
 	templateUpgradeMapper template.UpgradeMapper
 	collectionManager     collectionmanager.Manager
 	clusterSvc            clusterservice.Service
+	deployWindowSvc       deploywindowservice.Service
 }
 
 var _ Controller = (*controller)(nil)
@@ -198,9 +212,11 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		pipelineMgr:           param.PipelineMgr,
 		tektonFty:             param.TektonFty,
 		registryFty:           registryfty.Fty,
+		imagePolicyMgr:        param.ImagePolicyMgr,
 		userManager:           param.UserMgr,
 		userSvc:               param.UserSvc,
 		memberManager:         param.MemberMgr,
+		memberSvc:             param.MemberService,
 		groupManager:          param.GroupMgr,
 		schemaTagManager:      param.ClusterSchemaTagMgr,
 		tagMgr:                param.TagMgr,
@@ -213,5 +229,6 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		templateUpgradeMapper: config.TemplateUpgradeMapper,
 		collectionManager:     param.CollectionMgr,
 		clusterSvc:            param.ClusterSvc,
+		deployWindowSvc:       param.DeployWindowSvc,
 	}
 }
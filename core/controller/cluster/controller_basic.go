@@ -112,6 +112,18 @@ func (c *controller) List(ctx context.Context, query *q.Query) ([]*ListClusterWi
 		}
 	}
 
+	// hide the clusters of the applications invisible to the current user
+	invisible, err := c.memberSvc.ListInvisible(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if invisible != nil {
+		if query.Keywords == nil {
+			query.Keywords = q.KeyWords{}
+		}
+		query.Keywords[common.ClusterQueryInvisible] = invisible
+	}
+
 	count, clusters, err := c.clusterMgr.List(ctx, query, applicationIDs...)
 	if err != nil {
 		return nil, 0,
@@ -760,7 +772,16 @@ func (c *controller) GetClusterByName(ctx context.Context,
 		return nil, err
 	}
 
-	// 3. get full path
+	// 3. hide the cluster invisible to the current user
+	invisible, err := c.memberSvc.ListInvisible(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !invisible.ClusterVisible(cluster.ID, application.ID, application.GroupID, application.VisibilityLevel) {
+		return nil, herrors.NewErrNotFound(herrors.ClusterInDB, fmt.Sprintf("cluster not found, name = %s", clusterName))
+	}
+
+	// 4. get full path
 	group, err := c.groupSvc.GetChildByID(ctx, application.GroupID)
 	if err != nil {
 		return nil, err
@@ -28,6 +28,7 @@ import (
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 
 	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
 	mockcd "github.com/horizoncd/horizon/mock/pkg/cd"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
@@ -36,9 +37,11 @@ import (
 	badgemodels "github.com/horizoncd/horizon/pkg/badge/models"
 	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	envmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	groupservice "github.com/horizoncd/horizon/pkg/group/service"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 	registrydao "github.com/horizoncd/horizon/pkg/registry/dao"
 	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
@@ -104,6 +107,7 @@ func testListClusterByNameFuzzily(t *testing.T) {
 		applicationSvc: applicationservice.NewService(groupservice.NewService(manager), manager),
 		groupManager:   manager.GroupMgr,
 		memberManager:  manager.MemberMgr,
+		memberSvc:      memberservice.NewService(nil, nil, manager),
 		eventSvc:       eventservice.New(manager),
 		commitGetter:   commitGetter,
 	}
@@ -118,6 +122,20 @@ func testListClusterByNameFuzzily(t *testing.T) {
 		b, _ := json.Marshal(resp)
 		t.Logf("%v", string(b))
 	}
+
+	// clusters of private applications are hidden from non-members
+	privateApp := *applications[4]
+	privateApp.VisibilityLevel = groupmodels.VisibilityPrivate
+	_, err = manager.ApplicationMgr.UpdateByID(ctx, privateApp.ID, &privateApp)
+	assert.Nil(t, err)
+	outsiderCtx := common.WithContext(ctx, &userauth.DefaultInfo{Name: "outsider", ID: 999})
+	resps, count, err = c.List(outsiderCtx, &q.Query{Keywords: q.KeyWords{common.ClusterQueryName: "fuzzilyCluster"}})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, count)
+	assert.Equal(t, "fuzzilyCluster3", resps[0].Name)
+	_, err = c.GetClusterByName(outsiderCtx, "fuzzilyCluster4")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
 }
 
 func testListUserClustersByNameFuzzily(t *testing.T) {
@@ -221,6 +239,7 @@ func testListUserClustersByNameFuzzily(t *testing.T) {
 		applicationSvc: applicationservice.NewService(groupservice.NewService(manager), manager),
 		groupManager:   manager.GroupMgr,
 		memberManager:  manager.MemberMgr,
+		memberSvc:      memberservice.NewService(nil, nil, manager),
 		eventSvc:       eventservice.New(manager),
 		commitGetter:   commitGetter,
 	}
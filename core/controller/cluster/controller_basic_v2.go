@@ -25,11 +25,14 @@ import (
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	"github.com/horizoncd/horizon/pkg/cd"
 	"github.com/horizoncd/horizon/pkg/cluster/gitrepo"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	collectionmodels "github.com/horizoncd/horizon/pkg/collection/models"
+	envregionmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	"github.com/horizoncd/horizon/pkg/git"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
 	"github.com/horizoncd/horizon/pkg/templaterelease/models"
 	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	"github.com/horizoncd/horizon/pkg/util/jsonschema"
@@ -41,6 +44,7 @@ import (
 	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
 
 	"github.com/horizoncd/horizon/pkg/util/wlog"
+	"github.com/pmezard/go-difflib/difflib"
 )
 
 func (c *controller) GetClusterStatusV2(ctx context.Context, clusterID uint) (*StatusResponseV2, error) {
@@ -102,6 +106,137 @@ func (c *controller) CreateClusterV2(ctx context.Context,
 	const op = "cluster controller: create cluster v2"
 	defer wlog.Start(ctx, op).StopPrint()
 
+	// 1. validate create req and get the infos of cluster
+	info, err := c.prepareCreateClusterV2(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	application, buildTemplateInfo, envEntity, regionEntity, tr := info.application,
+		info.buildTemplateInfo, info.envEntity, info.regionEntity, info.templateRelease
+
+	// 2. customize db infos
+	cluster, tags := params.toClusterModel(application,
+		envEntity, buildTemplateInfo, info.template, info.expireSeconds)
+
+	// 3. update db and tags
+	clusterResp, err := c.clusterMgr.Create(ctx, cluster, tags, params.ExtraMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. create git repo
+	err = c.clusterGitRepo.CreateCluster(ctx, &gitrepo.CreateClusterParams{
+		BaseParams: &gitrepo.BaseParams{
+			ClusterID:           clusterResp.ID,
+			Cluster:             clusterResp.Name,
+			PipelineJSONBlob:    buildTemplateInfo.BuildConfig,
+			ApplicationJSONBlob: buildTemplateInfo.TemplateConfig,
+			TemplateRelease:     tr,
+			Application:         application,
+			Environment:         params.Environment,
+			RegionEntity:        regionEntity,
+			Version:             common.MetaVersion2,
+		},
+		Tags: tags,
+	})
+	if err != nil {
+		// Prevent errors like "project has already been taken" caused by automatic retries due to api timeouts
+		if deleteErr := c.clusterGitRepo.DeleteCluster(ctx, application.Name, cluster.Name, cluster.ID); deleteErr != nil {
+			if _, ok := perror.Cause(deleteErr).(*herrors.HorizonErrNotFound); !ok {
+				err = perror.WithMessage(err, deleteErr.Error())
+			}
+		}
+		if deleteErr := c.clusterMgr.DeleteByID(ctx, cluster.ID); deleteErr != nil {
+			err = perror.WithMessage(err, deleteErr.Error())
+		}
+		return nil, err
+	}
+	cluster.Status = common.ClusterStatusEmpty
+	updateClusterResp, err := c.clusterMgr.UpdateByID(ctx, cluster.ID, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. get full path
+	group, err := c.groupSvc.GetChildByID(ctx, application.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	fullPath := fmt.Sprintf("%v/%v/%v", group.FullPath, application.Name, cluster.Name)
+
+	ret := &CreateClusterResponseV2{
+		ID:   updateClusterResp.ID,
+		Name: updateClusterResp.Name,
+		Application: &Application{
+			ID:   application.ID,
+			Name: application.Name,
+		},
+		Scope: &Scope{
+			Environment: cluster.EnvironmentName,
+			Region:      cluster.RegionName,
+		},
+		FullPath:      fullPath,
+		ApplicationID: application.ID,
+		CreatedAt:     updateClusterResp.CreatedAt,
+		UpdatedAt:     updateClusterResp.UpdatedAt,
+	}
+
+	// 6. record event
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, ret.ID,
+		eventmodels.ClusterCreated, nil)
+	c.eventSvc.RecordMemberCreatedEvent(ctx, common.ResourceCluster, ret.ID)
+	// 7. customize response
+	return ret, nil
+}
+
+// DryRunCreateClusterV2 validates the create request like CreateClusterV2 and renders the manifests of cluster,
+// nothing is written to db or git repo
+func (c *controller) DryRunCreateClusterV2(ctx context.Context,
+	params *CreateClusterParamsV2) (*DryRunClusterResponseV2, error) {
+	const op = "cluster controller: dry run create cluster v2"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	info, err := c.prepareCreateClusterV2(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	_, tags := params.toClusterModel(info.application,
+		info.envEntity, info.buildTemplateInfo, info.template, info.expireSeconds)
+
+	rendered, err := c.clusterGitRepo.RenderCluster(ctx, &gitrepo.RenderClusterParams{
+		BaseParams: &gitrepo.BaseParams{
+			Cluster:             params.Name,
+			PipelineJSONBlob:    info.buildTemplateInfo.BuildConfig,
+			ApplicationJSONBlob: info.buildTemplateInfo.TemplateConfig,
+			TemplateRelease:     info.templateRelease,
+			Application:         info.application,
+			Environment:         params.Environment,
+			RegionEntity:        info.regionEntity,
+			Version:             common.MetaVersion2,
+		},
+		Create: true,
+		Tags:   tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ofRenderedCluster(rendered)
+}
+
+type createClusterInfoV2 struct {
+	application       *appmodels.Application
+	buildTemplateInfo *BuildTemplateInfo
+	envEntity         *envregionmodels.EnvironmentRegion
+	regionEntity      *regionmodels.RegionEntity
+	expireSeconds     uint
+	template          *templatemodels.Template
+	templateRelease   *models.TemplateRelease
+}
+
+// prepareCreateClusterV2 validates the create request and gets the infos to create the cluster
+func (c *controller) prepareCreateClusterV2(ctx context.Context,
+	params *CreateClusterParamsV2) (*createClusterInfoV2, error) {
 	// 1. check exist
 	exists, err := c.clusterMgr.CheckClusterExists(ctx, params.Name)
 	if err != nil {
@@ -174,81 +309,15 @@ func (c *controller) CreateClusterV2(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
-
-	// 8. customize db infos
-	cluster, tags := params.toClusterModel(application,
-		envEntity, buildTemplateInfo, template, expireSeconds)
-
-	// 9. update db and tags
-	clusterResp, err := c.clusterMgr.Create(ctx, cluster, tags, params.ExtraMembers)
-	if err != nil {
-		return nil, err
-	}
-
-	// 10. create git repo
-	err = c.clusterGitRepo.CreateCluster(ctx, &gitrepo.CreateClusterParams{
-		BaseParams: &gitrepo.BaseParams{
-			ClusterID:           clusterResp.ID,
-			Cluster:             clusterResp.Name,
-			PipelineJSONBlob:    buildTemplateInfo.BuildConfig,
-			ApplicationJSONBlob: buildTemplateInfo.TemplateConfig,
-			TemplateRelease:     tr,
-			Application:         application,
-			Environment:         params.Environment,
-			RegionEntity:        regionEntity,
-			Version:             common.MetaVersion2,
-		},
-		Tags: tags,
-	})
-	if err != nil {
-		// Prevent errors like "project has already been taken" caused by automatic retries due to api timeouts
-		if deleteErr := c.clusterGitRepo.DeleteCluster(ctx, application.Name, cluster.Name, cluster.ID); deleteErr != nil {
-			if _, ok := perror.Cause(deleteErr).(*herrors.HorizonErrNotFound); !ok {
-				err = perror.WithMessage(err, deleteErr.Error())
-			}
-		}
-		if deleteErr := c.clusterMgr.DeleteByID(ctx, cluster.ID); deleteErr != nil {
-			err = perror.WithMessage(err, deleteErr.Error())
-		}
-		return nil, err
-	}
-	cluster.Status = common.ClusterStatusEmpty
-	updateClusterResp, err := c.clusterMgr.UpdateByID(ctx, cluster.ID, cluster)
-	if err != nil {
-		return nil, err
-	}
-
-	// 11. get full path
-	group, err := c.groupSvc.GetChildByID(ctx, application.GroupID)
-	if err != nil {
-		return nil, err
-	}
-
-	fullPath := fmt.Sprintf("%v/%v/%v", group.FullPath, application.Name, cluster.Name)
-
-	ret := &CreateClusterResponseV2{
-		ID:   updateClusterResp.ID,
-		Name: updateClusterResp.Name,
-		Application: &Application{
-			ID:   application.ID,
-			Name: application.Name,
-		},
-		Scope: &Scope{
-			Environment: cluster.EnvironmentName,
-			Region:      cluster.RegionName,
-		},
-		FullPath:      fullPath,
-		ApplicationID: application.ID,
-		CreatedAt:     updateClusterResp.CreatedAt,
-		UpdatedAt:     updateClusterResp.UpdatedAt,
-	}
-
-	// 12. record event
-	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, ret.ID,
-		eventmodels.ClusterCreated, nil)
-	c.eventSvc.RecordMemberCreatedEvent(ctx, common.ResourceCluster, ret.ID)
-	// 13. customize response
-	return ret, nil
+	return &createClusterInfoV2{
+		application:       application,
+		buildTemplateInfo: buildTemplateInfo,
+		envEntity:         envEntity,
+		regionEntity:      regionEntity,
+		expireSeconds:     expireSeconds,
+		template:          template,
+		templateRelease:   tr,
+	}, nil
 }
 
 func (c *controller) GetClusterV2(ctx context.Context, clusterID uint) (*GetClusterResponseV2, error) {
@@ -368,26 +437,131 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 	const op = "cluster controller: update cluster v2"
 	defer wlog.Start(ctx, op).StopPrint()
 
+	// 1. validate update request and get the infos of cluster
+	info, err := c.prepareUpdateClusterV2(ctx, clusterID, r, mergePatch)
+	if err != nil {
+		return err
+	}
+	cluster, application, environmentName, regionName, templateInfo := info.cluster,
+		info.application, info.environmentName, info.regionName, info.templateInfo
+
+	// 2. update in git repo
+	if err = c.clusterGitRepo.UpdateCluster(ctx, &gitrepo.UpdateClusterParams{
+		BaseParams: &gitrepo.BaseParams{
+			ClusterID:           cluster.ID,
+			Cluster:             cluster.Name,
+			PipelineJSONBlob:    info.buildConfig,
+			ApplicationJSONBlob: info.templateConfig,
+			TemplateRelease:     info.templateRelease,
+			Application:         application,
+			Environment:         environmentName,
+			RegionEntity:        info.regionEntity,
+			Version:             common.MetaVersion2,
+		}}); err != nil {
+		return err
+	}
+
+	// 3. record event
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, cluster.ID,
+		eventmodels.ClusterUpdated, nil)
+
+	// 4. update cluster in db
+	clusterModel, tags := r.toClusterModel(cluster, info.expireSeconds, environmentName,
+		regionName, templateInfo.Name, templateInfo.Release)
+	_, err = c.clusterMgr.UpdateByID(ctx, clusterID, clusterModel)
+	if err != nil {
+		return err
+	}
+
+	// 5. update cluster tags
+	tagsInDB, err := c.tagMgr.ListByResourceTypeID(ctx, common.ResourceCluster, clusterID)
+	if err != nil {
+		return err
+	}
+	if r.Tags != nil && !tagmodels.Tags(tags).Eq(tagsInDB) {
+		if err := c.clusterGitRepo.UpdateTags(ctx, application.Name, cluster.Name, cluster.Template, tags); err != nil {
+			return err
+		}
+		if err := c.tagMgr.UpsertByResourceTypeID(ctx, common.ResourceCluster, clusterID, r.Tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DryRunUpdateClusterV2 validates the update request like UpdateClusterV2 and renders the manifests of cluster
+// with the diff against the deployed ones, nothing is written to db or git repo
+func (c *controller) DryRunUpdateClusterV2(ctx context.Context, clusterID uint,
+	r *UpdateClusterRequestV2, mergePatch bool) (*DryRunClusterResponseV2, error) {
+	const op = "cluster controller: dry run update cluster v2"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	info, err := c.prepareUpdateClusterV2(ctx, clusterID, r, mergePatch)
+	if err != nil {
+		return nil, err
+	}
+	var tags []*tagmodels.Tag
+	if r.Tags != nil {
+		_, tags = r.toClusterModel(info.cluster, info.expireSeconds, info.environmentName,
+			info.regionName, info.templateInfo.Name, info.templateInfo.Release)
+	}
+
+	rendered, err := c.clusterGitRepo.RenderCluster(ctx, &gitrepo.RenderClusterParams{
+		BaseParams: &gitrepo.BaseParams{
+			ClusterID:           info.cluster.ID,
+			Cluster:             info.cluster.Name,
+			PipelineJSONBlob:    info.buildConfig,
+			ApplicationJSONBlob: info.templateConfig,
+			TemplateRelease:     info.templateRelease,
+			Application:         info.application,
+			Environment:         info.environmentName,
+			RegionEntity:        info.regionEntity,
+			Version:             common.MetaVersion2,
+		},
+		Tags: tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ofRenderedCluster(rendered)
+}
+
+type updateClusterInfoV2 struct {
+	cluster         *clustermodels.Cluster
+	application     *appmodels.Application
+	regionEntity    *regionmodels.RegionEntity
+	environmentName string
+	regionName      string
+	expireSeconds   uint
+	templateInfo    *codemodels.TemplateInfo
+	templateRelease *models.TemplateRelease
+	buildConfig     map[string]interface{}
+	templateConfig  map[string]interface{}
+}
+
+// prepareUpdateClusterV2 validates the update request and gets the infos to update the cluster
+func (c *controller) prepareUpdateClusterV2(ctx context.Context, clusterID uint,
+	r *UpdateClusterRequestV2, mergePatch bool) (*updateClusterInfoV2, error) {
 	// validate request
 	if r.Git != nil && r.Git.URL != "" {
 		if err := validate.CheckGitURL(r.Git.URL); err != nil {
-			return err
+			return nil, err
 		}
 	}
 	if r.Image != nil && *r.Image != "" {
 		if err := validate.CheckImageURL(*r.Image); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	// 1. get cluster and application from db
 	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 2. check if we should update region and env
@@ -405,11 +579,11 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 		}
 		regionEntity, err = c.regionMgr.GetRegionEntity(ctx, regionName)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		_, err = c.envRegionMgr.GetByEnvironmentAndRegion(ctx, environmentName, regionName)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -418,7 +592,7 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 	if r.ExpireTime != "" {
 		expireSeconds, err = c.toExpireSeconds(ctx, r.ExpireTime, environmentName)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -441,7 +615,7 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 		return templateInfo, tr, nil
 	}()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	buildConfig, templateConfig, err := func() (map[string]interface{}, map[string]interface{}, error) {
@@ -474,7 +648,7 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 		return buildConfig, templateConfig, nil
 	}()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 5. validate update Request
@@ -491,51 +665,38 @@ func (c *controller) UpdateClusterV2(ctx context.Context, clusterID uint,
 		return info.Validate(ctx, c.templateSchemaGetter, renderValues, c.buildSchema)
 	}()
 	if err != nil {
-		return err
-	}
-
-	// 6. update in git repo
-	if err = c.clusterGitRepo.UpdateCluster(ctx, &gitrepo.UpdateClusterParams{
-		BaseParams: &gitrepo.BaseParams{
-			ClusterID:           cluster.ID,
-			Cluster:             cluster.Name,
-			PipelineJSONBlob:    buildConfig,
-			ApplicationJSONBlob: templateConfig,
-			TemplateRelease:     templateRelease,
-			Application:         application,
-			Environment:         environmentName,
-			RegionEntity:        regionEntity,
-			Version:             common.MetaVersion2,
-		}}); err != nil {
-		return err
-	}
-
-	// 7. record event
-	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, cluster.ID,
-		eventmodels.ClusterUpdated, nil)
-
-	// 8. update cluster in db
-	clusterModel, tags := r.toClusterModel(cluster, expireSeconds, environmentName,
-		regionName, templateInfo.Name, templateInfo.Release)
-	_, err = c.clusterMgr.UpdateByID(ctx, clusterID, clusterModel)
-	if err != nil {
-		return err
+		return nil, err
 	}
+	return &updateClusterInfoV2{
+		cluster:         cluster,
+		application:     application,
+		regionEntity:    regionEntity,
+		environmentName: environmentName,
+		regionName:      regionName,
+		expireSeconds:   expireSeconds,
+		templateInfo:    templateInfo,
+		templateRelease: templateRelease,
+		buildConfig:     buildConfig,
+		templateConfig:  templateConfig,
+	}, nil
+}
 
-	// 9. update cluster tags
-	tagsInDB, err := c.tagMgr.ListByResourceTypeID(ctx, common.ResourceCluster, clusterID)
+// ofRenderedCluster converts the rendered cluster to response, the diff is empty if nothing changed
+func ofRenderedCluster(rendered *gitrepo.RenderedCluster) (*DryRunClusterResponseV2, error) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(rendered.DeployedManifest),
+		B:        difflib.SplitLines(rendered.Manifest),
+		FromFile: "deployed",
+		ToFile:   "dryRun",
+		Context:  3,
+	})
 	if err != nil {
-		return err
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
 	}
-	if r.Tags != nil && !tagmodels.Tags(tags).Eq(tagsInDB) {
-		if err := c.clusterGitRepo.UpdateTags(ctx, application.Name, cluster.Name, cluster.Template, tags); err != nil {
-			return err
-		}
-		if err := c.tagMgr.UpsertByResourceTypeID(ctx, common.ResourceCluster, clusterID, r.Tags); err != nil {
-			return err
-		}
-	}
-	return nil
+	return &DryRunClusterResponseV2{
+		Manifest: rendered.Manifest,
+		Diff:     diff,
+	}, nil
 }
 
 type BuildTemplateInfo struct {
@@ -751,6 +912,14 @@ func (c *controller) createPipelineRun(ctx context.Context, clusterID uint,
 	case prmodels.ActionDeploy:
 		action = prmodels.ActionDeploy
 
+		if r.Image != "" {
+			imageURL = r.Image
+			if r.Git != nil && r.Git.Commit != "" {
+				gitRefType, gitRef, codeCommitID = codemodels.GitRefTypeCommit, r.Git.Commit, r.Git.Commit
+			}
+			break
+		}
+
 		clusterFiles, err := c.clusterGitRepo.GetCluster(ctx, application.Name, cluster.Name, cluster.Template)
 		if err != nil {
 			return nil, err
@@ -814,6 +983,11 @@ func (c *controller) createPipelineRun(ctx context.Context, clusterID uint,
 		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported action %v", r.Action)
 	}
 
+	if r.ScheduledAt != nil && !r.ScheduledAt.After(time.Now()) {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"scheduled time %v is not in the future", r.ScheduledAt)
+	}
+
 	return &prmodels.Pipelinerun{
 		ClusterID:        clusterID,
 		Action:           action,
@@ -828,5 +1002,6 @@ func (c *controller) createPipelineRun(ctx context.Context, clusterID uint,
 		LastConfigCommit: lastConfigCommitSHA,
 		ConfigCommit:     configCommitSHA,
 		RollbackFrom:     rollbackFrom,
+		ScheduledAt:      r.ScheduledAt,
 	}, nil
 }
@@ -52,6 +52,10 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 		return nil, err
 	}
 
+	if err := c.deployWindowSvc.EnsureDeployable(ctx, cluster); err != nil {
+		return nil, err
+	}
+
 	if cluster.GitURL == "" {
 		return nil, herrors.ErrBuildDeployNotSupported
 	}
@@ -124,7 +128,7 @@ func (c *controller) BuildDeploy(ctx context.Context, clusterID uint,
 	}
 
 	// 4. create pipelinerun in k8s
-	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
+	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName, cluster.Template)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,120 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	amodels "github.com/horizoncd/horizon/pkg/application/models"
+	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// blockCritical returns whether the image policy of application blocks deploying images with critical vulnerabilities
+func (c *controller) blockCritical(ctx context.Context, application *amodels.Application) (bool, error) {
+	policy, err := c.imagePolicyMgr.GetByApplicationID(ctx, application.ID)
+	if err != nil {
+		return false, err
+	}
+	return policy != nil && policy.BlockCritical, nil
+}
+
+// checkImageVulnerabilities blocks deploying the image with critical vulnerabilities if the image policy
+// of application requires. As the vulnerabilities of images not pushed to the registry of region,
+// not found or not scanned are unknown, they are blocked as well.
+func (c *controller) checkImageVulnerabilities(ctx context.Context, application *amodels.Application,
+	cluster *cmodels.Cluster, imageURL string) error {
+	if imageURL == "" {
+		return nil
+	}
+	block, err := c.blockCritical(ctx, application)
+	if err != nil || !block {
+		return err
+	}
+	return c.scanImage(ctx, application, cluster, imageURL)
+}
+
+// checkDeployedImageVulnerabilities checks the image in the pipeline output of cluster,
+// which is deployed again when the cluster is restarted
+func (c *controller) checkDeployedImageVulnerabilities(ctx context.Context, application *amodels.Application,
+	cluster *cmodels.Cluster) error {
+	block, err := c.blockCritical(ctx, application)
+	if err != nil || !block {
+		return err
+	}
+	output, err := c.clusterGitRepo.GetPipelineOutput(ctx, application.Name, cluster.Name, cluster.Template)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok ||
+			perror.Cause(err) == herrors.ErrPipelineOutputEmpty {
+			// nothing is built or deployed by horizon
+			return nil
+		}
+		return err
+	}
+	outputMap, _ := output.(map[string]interface{})
+	imageURL, _ := outputMap["image"].(string)
+	if imageURL == "" {
+		return nil
+	}
+	return c.scanImage(ctx, application, cluster, imageURL)
+}
+
+// scanImage returns an error if the image has critical vulnerabilities or its vulnerabilities are unknown
+func (c *controller) scanImage(ctx context.Context, application *amodels.Application,
+	cluster *cmodels.Cluster, imageURL string) error {
+	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, cluster.RegionName)
+	if err != nil {
+		return err
+	}
+	if regionEntity.Registry == nil {
+		return perror.Wrapf(herrors.ErrImageNotScanned,
+			"region %s has no registry to scan image %s", cluster.RegionName, imageURL)
+	}
+	config := &registry.Config{
+		Server:             regionEntity.Registry.Server,
+		Token:              regionEntity.Registry.Token,
+		Path:               regionEntity.Registry.Path,
+		InsecureSkipVerify: regionEntity.Registry.InsecureSkipTLSVerify,
+		Kind:               regionEntity.Registry.Kind,
+	}
+	tag, ok := registry.ParseImageTag(config, imageURL, application.Name, cluster.Name)
+	if !ok {
+		return perror.Wrapf(herrors.ErrImageNotScanned,
+			"image %s is not in the registry of region %s", imageURL, cluster.RegionName)
+	}
+	rg, err := c.registryFty.GetRegistryByConfig(ctx, config)
+	if err != nil {
+		return err
+	}
+	imageTag, err := rg.GetImageTag(ctx, application.Name, cluster.Name, tag)
+	if err != nil {
+		return err
+	}
+	if imageTag == nil {
+		return perror.Wrapf(herrors.ErrImageNotScanned,
+			"image %s is not found in the registry of region %s", imageURL, cluster.RegionName)
+	}
+	if !imageTag.ScanSummary.Scanned() {
+		return perror.Wrapf(herrors.ErrImageNotScanned, "image %s is not scanned yet", imageURL)
+	}
+	if imageTag.ScanSummary.HasCritical() {
+		return perror.Wrapf(herrors.ErrImageVulnerable,
+			"image %s has %d critical vulnerabilities", imageURL,
+			imageTag.ScanSummary.Summary[registry.SeverityCritical])
+	}
+	return nil
+}
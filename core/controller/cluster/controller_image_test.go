@@ -0,0 +1,112 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	clustergitrepomock "github.com/horizoncd/horizon/mock/pkg/cluster/gitrepo"
+	registrymock "github.com/horizoncd/horizon/mock/pkg/cluster/registry"
+	registryftymock "github.com/horizoncd/horizon/mock/pkg/cluster/registry/factory"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	imagepolicymodels "github.com/horizoncd/horizon/pkg/imagepolicy/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+)
+
+func TestCheckImageVulnerabilities(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	rg := registrymock.NewMockRegistry(mockCtl)
+	registryFty := registryftymock.NewMockRegistryGetter(mockCtl)
+	registryFty.EXPECT().GetRegistryByConfig(gomock.Any(), gomock.Any()).Return(rg, nil).AnyTimes()
+	clusterGitRepo := clustergitrepomock.NewMockClusterGitRepo(mockCtl)
+
+	c := &controller{
+		imagePolicyMgr: manager.ImagePolicyMgr,
+		regionMgr:      manager.RegionMgr,
+		registryFty:    registryFty,
+		clusterGitRepo: clusterGitRepo,
+	}
+
+	registryID, err := manager.RegistryMgr.Create(ctx, &registrymodels.Registry{
+		Server: "https://harbor.example.com",
+		Path:   "library",
+	})
+	assert.Nil(t, err)
+	region, err := manager.RegionMgr.Create(ctx, &regionmodels.Region{
+		Name:        "TestCheckImageVulnerabilities",
+		DisplayName: "TestCheckImageVulnerabilities",
+		RegistryID:  registryID,
+	})
+	assert.Nil(t, err)
+	application := &appmodels.Application{Name: "app"}
+	application.ID = 1024
+	cluster := &clustermodels.Cluster{Name: "cluster", RegionName: region.Name, Template: "javaapp"}
+	image := "harbor.example.com/library/app/cluster:v1"
+
+	// nothing is checked without policy
+	assert.Nil(t, c.checkImageVulnerabilities(ctx, application, cluster, "nginx:latest"))
+	assert.Nil(t, c.checkDeployedImageVulnerabilities(ctx, application, cluster))
+
+	_, err = manager.ImagePolicyMgr.Upsert(ctx, &imagepolicymodels.ImagePolicy{
+		ApplicationID: application.ID,
+		BlockCritical: true,
+	})
+	assert.Nil(t, err)
+
+	// images whose vulnerabilities are unknown are blocked
+	err = c.checkImageVulnerabilities(ctx, application, cluster, "nginx:latest")
+	assert.Equal(t, herrors.ErrImageNotScanned, perror.Cause(err))
+
+	rg.EXPECT().GetImageTag(gomock.Any(), "app", "cluster", "v1").Return(nil, nil).Times(1)
+	err = c.checkImageVulnerabilities(ctx, application, cluster, image)
+	assert.Equal(t, herrors.ErrImageNotScanned, perror.Cause(err))
+
+	rg.EXPECT().GetImageTag(gomock.Any(), "app", "cluster", "v1").Return(&registry.ImageTag{
+		Name:        "v1",
+		ScanSummary: &registry.ScanSummary{Status: "Running"},
+	}, nil).Times(1)
+	err = c.checkImageVulnerabilities(ctx, application, cluster, image)
+	assert.Equal(t, herrors.ErrImageNotScanned, perror.Cause(err))
+
+	rg.EXPECT().GetImageTag(gomock.Any(), "app", "cluster", "v1").Return(&registry.ImageTag{
+		Name: "v1",
+		ScanSummary: &registry.ScanSummary{Status: registry.ScanStatusSuccess,
+			Severity: registry.SeverityCritical, Summary: map[string]int{registry.SeverityCritical: 2}},
+	}, nil).Times(1)
+	err = c.checkImageVulnerabilities(ctx, application, cluster, image)
+	assert.Equal(t, herrors.ErrImageVulnerable, perror.Cause(err))
+
+	// the deployed image is checked when restarting
+	clusterGitRepo.EXPECT().GetPipelineOutput(gomock.Any(), "app", "cluster", "javaapp").
+		Return(map[string]interface{}{"image": image}, nil).Times(1)
+	rg.EXPECT().GetImageTag(gomock.Any(), "app", "cluster", "v1").Return(&registry.ImageTag{
+		Name: "v1",
+		ScanSummary: &registry.ScanSummary{Status: registry.ScanStatusSuccess,
+			Severity: registry.SeverityHigh, Summary: map[string]int{registry.SeverityHigh: 1}},
+	}, nil).Times(1)
+	assert.Nil(t, c.checkDeployedImageVulnerabilities(ctx, application, cluster))
+
+	clusterGitRepo.EXPECT().GetPipelineOutput(gomock.Any(), "app", "cluster", "javaapp").
+		Return(nil, perror.Wrap(herrors.ErrPipelineOutputEmpty, "")).Times(1)
+	assert.Nil(t, c.checkDeployedImageVulnerabilities(ctx, application, cluster))
+}
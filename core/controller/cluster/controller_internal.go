@@ -56,6 +56,9 @@ func (c *controller) InternalDeploy(ctx context.Context, clusterID uint,
 	}
 
 	// 3. update image in git repo
+	if err := c.checkImageVulnerabilities(ctx, application, cluster, pr.ImageURL); err != nil {
+		return nil, err
+	}
 	tr, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx, cluster.Template, cluster.TemplateRelease)
 	if err != nil {
 		return nil, err
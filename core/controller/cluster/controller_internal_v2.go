@@ -104,9 +104,13 @@ func (c *controller) InternalDeployV2(ctx context.Context, clusterID uint,
 		return nil, err
 	}
 
-	// 3. update pipeline output in git repo if builddeploy for gitImport and deploy for imageDeploy
+	// 3. update pipeline output in git repo if builddeploy for gitImport, deploy for imageDeploy,
+	// and deploy promoting an image built before for gitImport
 	if (pr.Action == prmodels.ActionBuildDeploy && pr.GitURL != "") ||
-		(pr.Action == prmodels.ActionDeploy && pr.GitURL == "") {
+		(pr.Action == prmodels.ActionDeploy && (pr.GitURL == "" || pr.ImageURL != "")) {
+		if err := c.checkImageVulnerabilities(ctx, application, cluster, pr.ImageURL); err != nil {
+			return nil, err
+		}
 		log.Infof(ctx, "pipeline %v output content: %+v", r.PipelinerunID, r.Output)
 		commit, err := c.clusterGitRepo.UpdatePipelineOutput(ctx, application.Name, cluster.Name,
 			tr.ChartName, r.Output)
@@ -50,6 +50,10 @@ func (c *controller) Restart(ctx context.Context, clusterID uint) (_ *Pipelineru
 		return nil, err
 	}
 
+	if err := c.deployWindowSvc.EnsureDeployable(ctx, cluster); err != nil {
+		return nil, err
+	}
+
 	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
 	if err != nil {
 		return nil, err
@@ -59,6 +63,9 @@ func (c *controller) Restart(ctx context.Context, clusterID uint) (_ *Pipelineru
 	if cluster.Status == common.ClusterStatusFreed {
 		return nil, herrors.ErrFreedClusterNotSupportedRestart
 	}
+	if err := c.checkDeployedImageVulnerabilities(ctx, application, cluster); err != nil {
+		return nil, err
+	}
 
 	// 1. get config commit now
 	lastConfigCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
@@ -128,6 +135,10 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 	if err != nil {
 		return nil, err
 	}
+
+	if err := c.deployWindowSvc.EnsureDeployable(ctx, cluster); err != nil {
+		return nil, err
+	}
 	application, err := c.applicationMgr.GetByID(ctx, cluster.ApplicationID)
 	if err != nil {
 		return nil, err
@@ -152,12 +163,22 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 		if err != nil {
 			return nil, err
 		}
+		// the image built before is deployed with the changed config
+		if err := c.checkDeployedImageVulnerabilities(ctx, application, cluster); err != nil {
+			return nil, err
+		}
 		commit, err := c.commitGetter.GetCommit(ctx, cluster.GitURL, cluster.GitRefType, cluster.GitRef)
 		if err == nil {
 			codeCommitID = commit.ID
 		}
 	} else if cluster.Image != "" {
 		imageURL, err = getDeployImage(cluster.Image, r.ImageTag)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.checkImageVulnerabilities(ctx, application, cluster, imageURL); err != nil {
+			return nil, err
+		}
 	}
 
 	// 2. create pipeline record
@@ -202,7 +223,7 @@ func (c *controller) Deploy(ctx context.Context, clusterID uint,
 	if clusterFiles.PipelineJSONBlob != nil {
 		pipelineJSONBlob = clusterFiles.PipelineJSONBlob
 	}
-	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
+	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName, cluster.Template)
 	if err != nil {
 		return nil, err
 	}
@@ -303,6 +324,10 @@ func (c *controller) Rollback(ctx context.Context,
 		return nil, err
 	}
 
+	if err := c.deployWindowSvc.EnsureDeployable(ctx, cluster); err != nil {
+		return nil, err
+	}
+
 	if pipelinerun.ClusterID != cluster.ID {
 		return nil, perror.Wrapf(herrors.ErrParamInvalid,
 			"the pipelinerun with id: %v is not belongs to cluster: %v", r.PipelinerunID, clusterID)
@@ -312,6 +337,9 @@ func (c *controller) Rollback(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkImageVulnerabilities(ctx, application, cluster, pipelinerun.ImageURL); err != nil {
+		return nil, err
+	}
 
 	// 2. get config commit now
 	lastConfigCommit, err := c.clusterGitRepo.GetConfigCommit(ctx, application.Name, cluster.Name)
@@ -703,6 +731,31 @@ func (c *controller) Upgrade(ctx context.Context, clusterID uint) error {
 	return nil
 }
 
+func (c *controller) UpgradeTemplateRelease(ctx context.Context, clusterID uint,
+	release string, dryRun bool) error {
+	const op = "cluster controller: upgrade template release"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	// the empty template config is merged into the values in git repo, so that they are kept as they are
+	r := &UpdateClusterRequestV2{
+		Description: cluster.Description,
+		TemplateInfo: &codemodels.TemplateInfo{
+			Name:    cluster.Template,
+			Release: release,
+		},
+		TemplateConfig: map[string]interface{}{},
+	}
+	if dryRun {
+		_, err = c.prepareUpdateClusterV2(ctx, clusterID, r, true)
+		return err
+	}
+	return c.UpdateClusterV2(ctx, clusterID, r, true)
+}
+
 func (c *controller) updatePipelineRunStatus(ctx context.Context,
 	action string, prID uint, pState prmodels.PipelineStatus, revision string) error {
 	if err := c.prMgr.PipelineRun.UpdateStatusByID(ctx, prID, pState); err != nil {
@@ -272,7 +272,7 @@ func (c *controller) getLatestPipelinerunByClusterID(ctx context.Context,
 
 func (c *controller) getLatestPipelineRunObject(ctx context.Context, cluster *clustermodels.Cluster,
 	pipelinerun *prmodels.Pipelinerun) (*v1beta1.PipelineRun, error) {
-	tektonCollector, err := c.tektonFty.GetTektonCollector(cluster.EnvironmentName)
+	tektonCollector, err := c.tektonFty.GetTektonCollector(cluster.EnvironmentName, cluster.Template)
 	if err != nil {
 		return nil, err
 	}
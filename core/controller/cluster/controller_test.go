@@ -62,6 +62,9 @@ import (
 	gitconfig "github.com/horizoncd/horizon/pkg/config/git"
 	templateconfig "github.com/horizoncd/horizon/pkg/config/template"
 	tokenconfig "github.com/horizoncd/horizon/pkg/config/token"
+	hctx "github.com/horizoncd/horizon/pkg/context"
+	deploywindowmodels "github.com/horizoncd/horizon/pkg/deploywindow/models"
+	deploywindowservice "github.com/horizoncd/horizon/pkg/deploywindow/service"
 	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
 	"github.com/horizoncd/horizon/pkg/environment/service"
 	envregionmodels "github.com/horizoncd/horizon/pkg/environmentregion/models"
@@ -71,7 +74,9 @@ import (
 	"github.com/horizoncd/horizon/pkg/git/gitlab"
 	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	groupservice "github.com/horizoncd/horizon/pkg/group/service"
+	imagepolicymodels "github.com/horizoncd/horizon/pkg/imagepolicy/models"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
@@ -81,6 +86,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/server/global"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
 	tmodel "github.com/horizoncd/horizon/pkg/tag/models"
+	teammodels "github.com/horizoncd/horizon/pkg/team/models"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	gitlabschema "github.com/horizoncd/horizon/pkg/templaterelease/schema/gitlab"
@@ -460,7 +466,9 @@ func TestMain(m *testing.M) {
 		&registrymodels.Registry{}, eventmodels.Event{}, &templatemodels.Template{},
 		&regionmodels.Region{}, &envregionmodels.EnvironmentRegion{}, &eventmodels.Event{},
 		&prmodels.Pipelinerun{}, &schematagmodel.ClusterTemplateSchemaTag{}, &tmodel.Tag{},
-		&envmodels.Environment{}, &tokenmodels.Token{}, &badgemodels.Badge{}); err != nil {
+		&envmodels.Environment{}, &tokenmodels.Token{}, &badgemodels.Badge{},
+		&teammodels.Team{}, &teammodels.TeamMember{}, &imagepolicymodels.ImagePolicy{},
+		&deploywindowmodels.DeployWindow{}); err != nil {
 		panic(err)
 	}
 	ctx = context.TODO()
@@ -637,10 +645,17 @@ func test(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, env)
 
+	tokenSvc, err := tokenservice.NewService(manager, tokenconfig.Config{
+		JwtSigningKey:         "horizon",
+		CallbackTokenExpireIn: time.Hour * 2,
+	})
+	assert.Nil(t, err)
+
 	c = &controller{
 		clusterMgr:           manager.ClusterMgr,
 		clusterGitRepo:       clusterGitRepo,
 		clusterSvc:           cluterservice.NewService(appSvc, clusterGitRepo, manager),
+		deployWindowSvc:      deploywindowservice.NewService(manager),
 		commitGetter:         commitGetter,
 		cd:                   cd,
 		k8sutil:              k8sutil,
@@ -663,10 +678,9 @@ func test(t *testing.T) {
 		applicationGitRepo:   applicationGitRepo,
 		eventSvc:             eventservice.New(manager),
 		memberManager:        manager.MemberMgr,
-		tokenSvc: tokenservice.NewService(manager, tokenconfig.Config{
-			JwtSigningKey:         "horizon",
-			CallbackTokenExpireIn: time.Hour * 2,
-		}),
+		memberSvc:            memberservice.NewService(nil, nil, manager),
+		tokenSvc:             tokenSvc,
+		imagePolicyMgr:       manager.ImagePolicyMgr,
 	}
 
 	commitGetter.EXPECT().GetHTTPLink(gomock.Any()).Return("https://cloudnative.com:22222/demo/springboot-demo", nil).AnyTimes()
@@ -857,12 +871,12 @@ func test(t *testing.T) {
 	t.Logf("%v", getByName)
 
 	tekton := tektonmock.NewMockInterface(mockCtl)
-	tektonFty.EXPECT().GetTekton(gomock.Any()).Return(tekton, nil).AnyTimes()
+	tektonFty.EXPECT().GetTekton(gomock.Any(), gomock.Any()).Return(tekton, nil).AnyTimes()
 	tekton.EXPECT().CreatePipelineRun(ctx, gomock.Any()).Return("abc", nil).Times(2)
 	tekton.EXPECT().GetPipelineRunByID(ctx, gomock.Any()).Return(pr, nil).AnyTimes()
 	tektonCollector := tektoncollectormock.NewMockInterface(mockCtl)
 
-	tektonFty.EXPECT().GetTektonCollector(gomock.Any()).Return(tektonCollector, nil).AnyTimes()
+	tektonFty.EXPECT().GetTektonCollector(gomock.Any(), gomock.Any()).Return(tektonCollector, nil).AnyTimes()
 	tektonCollector.EXPECT().GetPipelineRun(ctx, gomock.Any()).Return(pr, nil).AnyTimes()
 
 	commitGetter.EXPECT().GetCommit(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(&git.Commit{
@@ -981,6 +995,34 @@ func test(t *testing.T) {
 	clusterGitRepo.EXPECT().UpdateRestartTime(ctx, gomock.Any(), gomock.Any(),
 		gomock.Any()).Return("update-image-commit", nil)
 
+	// deny window of group holds restart, only admins can override it
+	window, err := manager.DeployWindowMgr.Create(ctx, &deploywindowmodels.DeployWindow{
+		ResourceType: common.ResourceGroup,
+		ResourceID:   group.ID,
+		Name:         "always",
+		Enabled:      true,
+		Type:         deploywindowmodels.TypeDeny,
+		Schedule:     "* * * * *",
+		Duration:     3600,
+	})
+	assert.Nil(t, err)
+	_, err = c.Restart(ctx, resp.ID)
+	assert.Equal(t, herrors.ErrDeployWindowClosed, perror.Cause(err))
+	overrideCtx := context.WithValue(ctx, hctx.DeployWindowOverride, true)
+	_, err = c.Restart(overrideCtx, resp.ID)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	overrideCtx = context.WithValue(overrideCtx, common.UserContextKey(), &userauth.DefaultInfo{
+		Name:  "Tony",
+		ID:    uint(1),
+		Admin: true,
+	})
+	cluster, err := manager.ClusterMgr.GetByID(ctx, resp.ID)
+	assert.Nil(t, err)
+	assert.Nil(t, c.deployWindowSvc.EnsureDeployable(overrideCtx, cluster))
+	window.Enabled = false
+	_, err = manager.DeployWindowMgr.Update(ctx, window)
+	assert.Nil(t, err)
+
 	restartResp, err := c.Restart(ctx, resp.ID)
 	assert.Nil(t, err)
 	assert.NotNil(t, resp)
@@ -1438,7 +1480,7 @@ func testV2(t *testing.T) {
 			Manifest:     nil,
 			BuildConf:    pipelineJSONBlob,
 			TemplateConf: applicationJSONBlob,
-		}, nil).Times(1)
+		}, nil).AnyTimes()
 	clusterGitRepo.EXPECT().CreateCluster(ctx, gomock.Any()).Return(nil).Times(1)
 
 	createClusterName := "app-cluster2"
@@ -1463,6 +1505,43 @@ func testV2(t *testing.T) {
 		TemplateConfig: applicationJSONBlob,
 		ExtraMembers:   nil,
 	}
+	// dry run create, nothing is created
+	deployment := `---
+# Source: app-cluster2/charts/rollout/templates/deployment.yaml
+kind: Deployment
+spec:
+  replicas: 1
+`
+	clusterGitRepo.EXPECT().RenderCluster(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, params *gitrepo.RenderClusterParams) (*gitrepo.RenderedCluster, error) {
+			assert.True(t, params.Create)
+			assert.Equal(t, createClusterName, params.Cluster)
+			assert.Equal(t, 1, len(params.Tags))
+			return &gitrepo.RenderedCluster{Manifest: deployment}, nil
+		}).Times(1)
+	templateSchemaGetter.EXPECT().GetTemplateSchema(gomock.Any(), templateName, "v1.0.0", gomock.Any()).
+		Return(&trschema.Schemas{
+			Application: &trschema.Schema{
+				JSONSchema: applicationSchema,
+			},
+			Pipeline: &trschema.Schema{
+				JSONSchema: pipelineSchema,
+			},
+		}, nil).Times(1)
+	dryRunResp, err := c.DryRunCreateClusterV2(ctx, &CreateClusterParamsV2{
+		CreateClusterRequestV2: createReq,
+		ApplicationID:          application.ID,
+		Environment:            "test2",
+		Region:                 "hz",
+		MergePatch:             false,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, deployment, dryRunResp.Manifest)
+	assert.True(t, strings.Contains(dryRunResp.Diff, "+  replicas: 1"))
+	exists, err := manager.ClusterMgr.CheckClusterExists(ctx, createClusterName)
+	assert.Nil(t, err)
+	assert.False(t, exists)
+
 	resp, err := c.CreateClusterV2(ctx, &CreateClusterParamsV2{
 		CreateClusterRequestV2: createReq,
 		ApplicationID:          application.ID,
@@ -1548,6 +1627,38 @@ func testV2(t *testing.T) {
 				JSONSchema: pipelineSchema,
 			},
 		}, nil).Times(1)
+
+	// dry run update, nothing is updated
+	clusterGitRepo.EXPECT().GetCluster(ctx, applicationName, createClusterName, templateName).Return(&gitrepo.ClusterFiles{
+		PipelineJSONBlob:    pipelineJSONBlob,
+		ApplicationJSONBlob: applicationJSONBlob,
+		Manifest:            manifest,
+	}, nil).Times(1)
+	templateSchemaGetter.EXPECT().GetTemplateSchema(gomock.Any(), templateName, "v1.0.0", gomock.Any()).
+		Return(&trschema.Schemas{
+			Application: &trschema.Schema{
+				JSONSchema: applicationSchema,
+			},
+			Pipeline: &trschema.Schema{
+				JSONSchema: pipelineSchema,
+			},
+		}, nil).Times(1)
+	clusterGitRepo.EXPECT().RenderCluster(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, params *gitrepo.RenderClusterParams) (*gitrepo.RenderedCluster, error) {
+			assert.False(t, params.Create)
+			assert.Equal(t, "value2", params.Tags[0].Value)
+			return &gitrepo.RenderedCluster{
+				Manifest:         strings.Replace(deployment, "replicas: 1", "replicas: 2", 1),
+				DeployedManifest: deployment,
+			}, nil
+		}).Times(1)
+	dryRunResp, err = c.DryRunUpdateClusterV2(ctx, getClusterResp.ID, updateRequestV2, false)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(dryRunResp.Diff, "-  replicas: 1\n+  replicas: 2\n"))
+	tags, err := manager.TagMgr.ListByResourceTypeID(ctx, common.ResourceCluster, getClusterResp.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "value1", tags[0].Value)
+
 	err = c.UpdateClusterV2(ctx, getClusterResp.ID, updateRequestV2, false)
 	t.Logf("%+v", err)
 	assert.Nil(t, err)
@@ -140,6 +140,14 @@ type CreateClusterResponseV2 struct {
 	UpdatedAt     time.Time    `json:"updatedAt"`
 }
 
+// DryRunClusterResponseV2 is the result of creating or updating a cluster in dry run mode
+type DryRunClusterResponseV2 struct {
+	// Manifest is the kubernetes manifests rendered with the request
+	Manifest string `json:"manifest"`
+	// Diff is the unified diff from the manifests currently deployed to Manifest
+	Diff string `json:"diff"`
+}
+
 type UpdateClusterRequestV2 struct {
 	// basic infos
 	Description string `json:"description"`
@@ -245,6 +253,10 @@ type CreatePipelineRunRequest struct {
 	// for build deploy
 	Git      *BuildDeployRequestGit `json:"git,omitempty"`
 	ImageTag string                 `json:"imageTag,omitempty"`
+	// Image is deployed as it is for deploy, it promotes the image built and tested by another pipelinerun
+	Image string `json:"image,omitempty"`
 	// for rollback
 	PipelinerunID uint `json:"pipelinerunID,omitempty"`
+	// ScheduledAt queues the pipelinerun to be executed automatically at the time
+	ScheduledAt *time.Time `json:"scheduledAt,omitempty"`
 }
@@ -0,0 +1,184 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploywindow
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/deploywindow"
+	windowmanager "github.com/horizoncd/horizon/pkg/deploywindow/manager"
+	"github.com/horizoncd/horizon/pkg/deploywindow/models"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _maxNameLength = 64
+
+type Controller interface {
+	CreateWindow(ctx context.Context, resourceType string, resourceID uint,
+		request *CreateWindowRequest) (*Window, error)
+	ListWindows(ctx context.Context, resourceType string, resourceID uint, query *q.Query) ([]*Window, int64, error)
+	GetWindow(ctx context.Context, id uint) (*Window, error)
+	UpdateWindow(ctx context.Context, id uint, request *UpdateWindowRequest) (*Window, error)
+	DeleteWindow(ctx context.Context, id uint) error
+}
+
+type controller struct {
+	windowMgr windowmanager.Manager
+	groupMgr  groupmanager.Manager
+	envMgr    envmanager.Manager
+}
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		windowMgr: param.DeployWindowMgr,
+		groupMgr:  param.GroupMgr,
+		envMgr:    param.EnvMgr,
+	}
+}
+
+func (c *controller) CreateWindow(ctx context.Context, resourceType string, resourceID uint,
+	request *CreateWindowRequest) (*Window, error) {
+	const op = "deploy window controller: create"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if err := c.checkResource(ctx, resourceType, resourceID); err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	window := request.toModel(resourceType, resourceID)
+	if err := validateWindow(window); err != nil {
+		return nil, err
+	}
+	window.CreatedBy = currentUser.GetID()
+	window.UpdatedBy = currentUser.GetID()
+
+	window, err = c.windowMgr.Create(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+	return ofWindowModel(window), nil
+}
+
+func (c *controller) ListWindows(ctx context.Context, resourceType string, resourceID uint,
+	query *q.Query) ([]*Window, int64, error) {
+	const op = "deploy window controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	windows, total, err := c.windowMgr.List(ctx, resourceType, resourceID, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	result := make([]*Window, 0, len(windows))
+	for _, window := range windows {
+		result = append(result, ofWindowModel(window))
+	}
+	return result, total, nil
+}
+
+func (c *controller) GetWindow(ctx context.Context, id uint) (*Window, error) {
+	const op = "deploy window controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	window, err := c.windowMgr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofWindowModel(window), nil
+}
+
+func (c *controller) UpdateWindow(ctx context.Context, id uint,
+	request *UpdateWindowRequest) (*Window, error) {
+	const op = "deploy window controller: update"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	window, err := c.windowMgr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	window = request.toModel(window)
+	if err := validateWindow(window); err != nil {
+		return nil, err
+	}
+	window.UpdatedBy = currentUser.GetID()
+
+	window, err = c.windowMgr.Update(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+	return ofWindowModel(window), nil
+}
+
+func (c *controller) DeleteWindow(ctx context.Context, id uint) error {
+	const op = "deploy window controller: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.windowMgr.Get(ctx, id); err != nil {
+		return err
+	}
+	return c.windowMgr.Delete(ctx, id)
+}
+
+// checkResource checks the resource which the window belongs to exists,
+// and only admins are allowed to manage the windows of environments, since environments are not authed.
+func (c *controller) checkResource(ctx context.Context, resourceType string, resourceID uint) error {
+	switch resourceType {
+	case common.ResourceGroup:
+		_, err := c.groupMgr.GetByID(ctx, resourceID)
+		return err
+	case common.ResourceEnvironment:
+		currentUser, err := common.UserFromContext(ctx)
+		if err != nil {
+			return err
+		}
+		if !currentUser.IsAdmin() {
+			return perror.Wrap(herrors.ErrForbidden, "only admin can manage the deploy windows of environments")
+		}
+		_, err = c.envMgr.GetByID(ctx, resourceID)
+		return err
+	default:
+		return perror.Wrapf(herrors.ErrParamInvalid, "unsupported resource type: %s", resourceType)
+	}
+}
+
+func validateWindow(window *models.DeployWindow) error {
+	if window.Name == "" || len(window.Name) > _maxNameLength {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"name must not be empty and no longer than %d characters", _maxNameLength)
+	}
+	if window.Type != models.TypeAllow && window.Type != models.TypeDeny {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid type: %s", window.Type)
+	}
+	if window.Duration == 0 {
+		return perror.Wrap(herrors.ErrParamInvalid, "duration must be positive")
+	}
+	if _, err := deploywindow.Parse(window); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploywindow
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/deploywindow/models"
+)
+
+type CreateWindowRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	// Type is allow or deny
+	Type string `json:"type"`
+	// Schedule is a standard cron expression, the window opens every time it fires
+	Schedule string `json:"schedule"`
+	// Duration is the seconds the window keeps open
+	Duration uint   `json:"duration"`
+	TimeZone string `json:"timeZone"`
+}
+
+type UpdateWindowRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Enabled     *bool   `json:"enabled"`
+	Type        *string `json:"type"`
+	Schedule    *string `json:"schedule"`
+	Duration    *uint   `json:"duration"`
+	TimeZone    *string `json:"timeZone"`
+}
+
+type Window struct {
+	CreateWindowRequest
+	ID           uint      `json:"id"`
+	ResourceType string    `json:"resourceType"`
+	ResourceID   uint      `json:"resourceID"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func (r *CreateWindowRequest) toModel(resourceType string, resourceID uint) *models.DeployWindow {
+	return &models.DeployWindow{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Name:         r.Name,
+		Description:  r.Description,
+		Enabled:      r.Enabled,
+		Type:         r.Type,
+		Schedule:     r.Schedule,
+		Duration:     r.Duration,
+		TimeZone:     r.TimeZone,
+	}
+}
+
+func (r *UpdateWindowRequest) toModel(window *models.DeployWindow) *models.DeployWindow {
+	if r.Name != nil {
+		window.Name = *r.Name
+	}
+	if r.Description != nil {
+		window.Description = *r.Description
+	}
+	if r.Enabled != nil {
+		window.Enabled = *r.Enabled
+	}
+	if r.Type != nil {
+		window.Type = *r.Type
+	}
+	if r.Schedule != nil {
+		window.Schedule = *r.Schedule
+	}
+	if r.Duration != nil {
+		window.Duration = *r.Duration
+	}
+	if r.TimeZone != nil {
+		window.TimeZone = *r.TimeZone
+	}
+	return window
+}
+
+func ofWindowModel(window *models.DeployWindow) *Window {
+	return &Window{
+		CreateWindowRequest: CreateWindowRequest{
+			Name:        window.Name,
+			Description: window.Description,
+			Enabled:     window.Enabled,
+			Type:        window.Type,
+			Schedule:    window.Schedule,
+			Duration:    window.Duration,
+			TimeZone:    window.TimeZone,
+		},
+		ID:           window.ID,
+		ResourceType: window.ResourceType,
+		ResourceID:   window.ResourceID,
+		CreatedAt:    window.CreatedAt,
+		UpdatedAt:    window.UpdatedAt,
+	}
+}
@@ -100,10 +100,17 @@ func NewController(param *param.Param) Controller {
 // GetChildren get children of a group, including subgroups and applications
 func (c *controller) GetChildren(ctx context.Context, id uint, pageNumber, pageSize int) (
 	[]*service.Child, int64, error) {
+	invisible, err := c.memberSvc.ListInvisible(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !invisible.GroupVisible(id) {
+		return nil, 0, herrors.NewErrNotFound(herrors.GroupInDB, fmt.Sprintf("group not found, id = %d", id))
+	}
+
 	var parent *models.Group
 	var full *service.Full
 	if id > 0 {
-		var err error
 		parent, err = c.groupManager.GetByID(ctx, id)
 		if err != nil {
 			return nil, 0, err
@@ -116,7 +123,7 @@ func (c *controller) GetChildren(ctx context.Context, id uint, pageNumber, pageS
 	}
 
 	// query children
-	children, count, err := c.groupManager.GetChildren(ctx, id, pageNumber, pageSize)
+	children, count, err := c.groupManager.GetChildren(ctx, id, invisible, pageNumber, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -134,7 +141,9 @@ func (c *controller) GetChildren(ctx context.Context, id uint, pageNumber, pageS
 	}
 	childrenCountMap := map[uint]int{}
 	for _, g := range groups {
-		childrenCountMap[g.ParentID]++
+		if invisible.GroupVisible(g.ID) {
+			childrenCountMap[g.ParentID]++
+		}
 	}
 
 	// format GroupChild
@@ -177,7 +186,12 @@ func (c *controller) SearchGroups(ctx context.Context, params *SearchParams) ([]
 	if err != nil {
 		return nil, 0, err
 	}
-	if matchedGroups == nil {
+	invisible, err := c.memberSvc.ListInvisible(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	matchedGroups = filterVisibleGroups(invisible, matchedGroups)
+	if len(matchedGroups) == 0 {
 		return []*service.Child{}, 0, nil
 	}
 
@@ -220,6 +234,20 @@ func (c *controller) SearchChildren(ctx context.Context, params *SearchParams) (
 	if err != nil {
 		return nil, 0, err
 	}
+
+	// hide the groups and applications invisible to the current user
+	invisible, err := c.memberSvc.ListInvisible(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	matchedGroups = filterVisibleGroups(invisible, matchedGroups)
+	visibleApplications := make([]*appmodels.Application, 0, len(matchedApplications))
+	for _, application := range matchedApplications {
+		if invisible.ApplicationVisible(application.ID, application.GroupID, application.VisibilityLevel) {
+			visibleApplications = append(visibleApplications, application)
+		}
+	}
+	matchedApplications = visibleApplications
 	var groupIDs []uint
 	for _, application := range matchedApplications {
 		groupIDs = append(groupIDs, application.GroupID)
@@ -246,13 +274,31 @@ func (c *controller) SearchChildren(ctx context.Context, params *SearchParams) (
 	return childrenWithLevelStruct, int64(len(childrenWithLevelStruct)), nil
 }
 
+// filterVisibleGroups filters out the groups invisible to the current user
+func filterVisibleGroups(invisible *models.Invisible, groups []*models.Group) []*models.Group {
+	visibleGroups := make([]*models.Group, 0, len(groups))
+	for _, group := range groups {
+		if invisible.GroupVisible(group.ID) {
+			visibleGroups = append(visibleGroups, group)
+		}
+	}
+	return visibleGroups
+}
+
 // GetSubGroups get subgroups of a group
 func (c *controller) GetSubGroups(ctx context.Context, id uint, pageNumber, pageSize int) (
 	[]*service.Child, int64, error) {
+	invisible, err := c.memberSvc.ListInvisible(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !invisible.GroupVisible(id) {
+		return nil, 0, herrors.NewErrNotFound(herrors.GroupInDB, fmt.Sprintf("group not found, id = %d", id))
+	}
+
 	var parent *models.Group
 	var full *service.Full
 	if id > 0 {
-		var err error
 		parent, err = c.groupManager.GetByID(ctx, id)
 		if err != nil {
 			return nil, 0, err
@@ -265,7 +311,7 @@ func (c *controller) GetSubGroups(ctx context.Context, id uint, pageNumber, page
 	}
 
 	// query subGroups
-	subGroups, count, err := c.groupManager.GetSubGroups(ctx, id, pageNumber, pageSize)
+	subGroups, count, err := c.groupManager.GetSubGroups(ctx, id, invisible, pageNumber, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -281,7 +327,9 @@ func (c *controller) GetSubGroups(ctx context.Context, id uint, pageNumber, page
 	}
 	childrenCountMap := map[uint]int{}
 	for _, g := range groups {
-		childrenCountMap[g.ParentID]++
+		if invisible.GroupVisible(g.ID) {
+			childrenCountMap[g.ParentID]++
+		}
 	}
 
 	// format GroupChild
@@ -309,8 +357,19 @@ func (c *controller) GetSubGroups(ctx context.Context, id uint, pageNumber, page
 
 // UpdateBasic update basic info of a group, including name, path, description and visibilityLevel
 func (c *controller) UpdateBasic(ctx context.Context, id uint, updateGroup *UpdateGroup) error {
+	if !models.IsValidVisibility(updateGroup.VisibilityLevel) {
+		return perror.Wrapf(herrors.ErrParamInvalid, "invalid visibilityLevel: %s", updateGroup.VisibilityLevel)
+	}
 	group := convertUpdateGroupToGroup(updateGroup)
 	group.ID = id
+	if group.VisibilityLevel == "" {
+		// keep the visibility level unchanged if not specified
+		groupInDB, err := c.groupManager.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		group.VisibilityLevel = groupInDB.VisibilityLevel
+	}
 
 	err := c.groupManager.UpdateBasic(ctx, group)
 	if err != nil {
@@ -332,7 +391,13 @@ func (c *controller) Transfer(ctx context.Context, id, newParentID uint) error {
 
 // CreateGroup add a group
 func (c *controller) CreateGroup(ctx context.Context, newGroup *NewGroup) (uint, error) {
+	if !models.IsValidVisibility(newGroup.VisibilityLevel) {
+		return 0, perror.Wrapf(herrors.ErrParamInvalid, "invalid visibilityLevel: %s", newGroup.VisibilityLevel)
+	}
 	groupEntity := convertNewGroupToGroup(newGroup)
+	if groupEntity.VisibilityLevel == "" {
+		groupEntity.VisibilityLevel = models.VisibilityPublic
+	}
 
 	group, err := c.groupManager.Create(ctx, groupEntity)
 	if err != nil {
@@ -358,6 +423,11 @@ func (c *controller) GetByFullPath(ctx context.Context,
 	}
 
 	if resourceType == "" {
+		invisible, err := c.memberSvc.ListInvisible(ctx)
+		if err != nil {
+			return nil, err
+		}
+
 		// resourcePath: /a/b => {a, b}
 		paths := strings.Split(resourcePath[1:], "/")
 		groups, err := c.groupManager.GetByPaths(ctx, paths)
@@ -375,6 +445,9 @@ func (c *controller) GetByFullPath(ctx context.Context,
 		for k, v := range idToFull {
 			// resourcePath pointing to a group
 			if v.FullPath == resourcePath {
+				if !invisible.GroupVisible(k) {
+					return nil, perror.Wrap(errNotMatch, errMsg)
+				}
 				g := idToGroup[k]
 				child := service.ConvertGroupToChild(g, v)
 				return child, nil
@@ -389,6 +462,9 @@ func (c *controller) GetByFullPath(ctx context.Context,
 		if app != nil && err == nil {
 			appParentFull, ok := idToFull[app.GroupID]
 			if ok && fmt.Sprintf("%s/%s", appParentFull.FullPath, app.Name) == resourcePath {
+				if !invisible.ApplicationVisible(app.ID, app.GroupID, app.VisibilityLevel) {
+					return nil, perror.Wrap(errNotMatch, errMsg)
+				}
 				return service.ConvertApplicationToChild(app, &service.Full{
 					FullName: fmt.Sprintf("%s/%s", appParentFull.FullName, app.Name),
 					FullPath: fmt.Sprintf("%s/%s", appParentFull.FullPath, app.Name),
@@ -410,6 +486,9 @@ func (c *controller) GetByFullPath(ctx context.Context,
 		}
 		appParentFull, ok := idToFull[app.GroupID]
 		if ok && fmt.Sprintf("%s/%s/%s", appParentFull.FullPath, app.Name, cluster.Name) == resourcePath {
+			if !invisible.ClusterVisible(cluster.ID, app.ID, app.GroupID, app.VisibilityLevel) {
+				return nil, perror.Wrap(errNotMatch, errMsg)
+			}
 			return service.ConvertClusterToChild(cluster, &service.Full{
 				FullName: fmt.Sprintf("%s/%s/%s", appParentFull.FullName, app.Name, cluster.Name),
 				FullPath: fmt.Sprintf("%s/%s/%s", appParentFull.FullPath, app.Name, cluster.Name),
@@ -38,12 +38,15 @@ import (
 	"github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/group/service"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/server/global"
+	teammodels "github.com/horizoncd/horizon/pkg/team/models"
 	tmodels "github.com/horizoncd/horizon/pkg/template/models"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	callbacks "github.com/horizoncd/horizon/pkg/util/ormcallbacks"
 )
 
@@ -52,7 +55,8 @@ var (
 	db, _    = orm.NewSqliteDB("")
 	ctx      = context.TODO()
 	manager  = managerparam.InitManager(db)
-	groupCtl = NewController(&param.Param{Manager: manager})
+	groupCtl = NewController(&param.Param{Manager: manager,
+		MemberService: memberservice.NewService(nil, nil, manager)})
 )
 
 func GroupValueEqual(g1, g2 *models.Group) bool {
@@ -92,6 +96,11 @@ func init() {
 		fmt.Printf("%+v", err)
 		os.Exit(1)
 	}
+	err = db.AutoMigrate(&usermodels.User{}, &teammodels.Team{}, &teammodels.TeamMember{})
+	if err != nil {
+		fmt.Printf("%+v", err)
+		os.Exit(1)
+	}
 
 	callbacks.RegisterCustomCallbacks(db)
 }
@@ -0,0 +1,64 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hook
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	hookmanager "github.com/horizoncd/horizon/pkg/hook/manager"
+	"github.com/horizoncd/horizon/pkg/hook/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// ListDeliveries lists the deliveries of the hook events oldest first,
+	// the pending ones are the backlog of the handlers
+	ListDeliveries(ctx context.Context, query *models.DeliveryQuery, pagination *q.Query) (int, []*Delivery, error)
+}
+
+type controller struct {
+	hookMgr hookmanager.Manager
+}
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		hookMgr: param.HookMgr,
+	}
+}
+
+func (c *controller) ListDeliveries(ctx context.Context, query *models.DeliveryQuery,
+	pagination *q.Query) (int, []*Delivery, error) {
+	const op = "hook controller: list deliveries"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	switch query.Status {
+	case "", models.StatusPending, models.StatusSucceeded:
+	default:
+		return 0, nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid status: %s", query.Status)
+	}
+	total, deliveries, err := c.hookMgr.ListDeliveries(ctx, query, pagination)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp := make([]*Delivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		resp = append(resp, ofDelivery(delivery))
+	}
+	return total, resp, nil
+}
@@ -0,0 +1,56 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/hook/models"
+)
+
+// Delivery is the delivery of a hook event to a handler
+type Delivery struct {
+	ID        uint            `json:"id"`
+	EventID   uint            `json:"eventID"`
+	EventType string          `json:"eventType"`
+	Event     json.RawMessage `json:"event"`
+	RequestID string          `json:"requestID"`
+	Handler   string          `json:"handler"`
+	Status    string          `json:"status"`
+	// FailedTimes and ErrorMessage are the count and the last error of the failed attempts
+	FailedTimes   uint      `json:"failedTimes"`
+	ErrorMessage  string    `json:"errorMessage"`
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+func ofDelivery(delivery *models.HookDeliveryWithEvent) *Delivery {
+	return &Delivery{
+		ID:            delivery.ID,
+		EventID:       delivery.EventID,
+		EventType:     delivery.EventType,
+		Event:         json.RawMessage(delivery.Content),
+		RequestID:     delivery.RequestID,
+		Handler:       delivery.Handler,
+		Status:        delivery.Status,
+		FailedTimes:   delivery.FailedTimes,
+		ErrorMessage:  delivery.ErrorMessage,
+		NextAttemptAt: delivery.NextAttemptAt,
+		CreatedAt:     delivery.CreatedAt,
+		UpdatedAt:     delivery.UpdatedAt,
+	}
+}
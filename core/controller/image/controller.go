@@ -0,0 +1,275 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package image
+
+import (
+	"context"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	registryfty "github.com/horizoncd/horizon/pkg/cluster/registry/factory"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	policymanager "github.com/horizoncd/horizon/pkg/imagepolicy/manager"
+	"github.com/horizoncd/horizon/pkg/imagepolicy/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
+	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Controller interface {
+	// ListApplicationImages lists the image tags of the clusters of application
+	ListApplicationImages(ctx context.Context, applicationID uint) ([]*ClusterImages, error)
+	ListClusterImages(ctx context.Context, clusterID uint) (*ClusterImages, error)
+	GetPolicy(ctx context.Context, applicationID uint) (*Policy, error)
+	UpdatePolicy(ctx context.Context, applicationID uint, policy *Policy) (*Policy, error)
+	// Retain deletes the image tags of the clusters of application beyond the retain count of its policy,
+	// the images currently deployed are retained, it returns the count of deleted tags
+	Retain(ctx context.Context, applicationID uint) (int, error)
+}
+
+type controller struct {
+	policyMgr   policymanager.Manager
+	appMgr      appmanager.Manager
+	clusterMgr  clustermanager.Manager
+	regionMgr   regionmanager.Manager
+	prMgr       *prmanager.PRManager
+	registryFty registryfty.RegistryGetter
+}
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		policyMgr:   param.ImagePolicyMgr,
+		appMgr:      param.ApplicationMgr,
+		clusterMgr:  param.ClusterMgr,
+		regionMgr:   param.RegionMgr,
+		prMgr:       param.PRMgr,
+		registryFty: registryfty.Fty,
+	}
+}
+
+func (c *controller) ListApplicationImages(ctx context.Context, applicationID uint) ([]*ClusterImages, error) {
+	const op = "image controller: list application images"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	application, err := c.appMgr.GetByID(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	_, clusters, err := c.clusterMgr.ListByApplicationID(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	deployed, err := c.deployedImages(ctx, clusters)
+	if err != nil {
+		return nil, err
+	}
+	images := make([]*ClusterImages, 0, len(clusters))
+	for _, cluster := range clusters {
+		clusterImages, err := c.listClusterImages(ctx, application.Name, cluster.Cluster, deployed)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, clusterImages)
+	}
+	return images, nil
+}
+
+func (c *controller) ListClusterImages(ctx context.Context, clusterID uint) (*ClusterImages, error) {
+	const op = "image controller: list cluster images"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	application, err := c.appMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	// images of the cluster may be deployed by the other clusters of application
+	_, clusters, err := c.clusterMgr.ListByApplicationID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	deployed, err := c.deployedImages(ctx, clusters)
+	if err != nil {
+		return nil, err
+	}
+	return c.listClusterImages(ctx, application.Name, cluster, deployed)
+}
+
+func (c *controller) listClusterImages(ctx context.Context, appName string,
+	cluster *clustermodels.Cluster, deployed []string) (*ClusterImages, error) {
+	images := &ClusterImages{
+		ClusterID: cluster.ID,
+		Cluster:   cluster.Name,
+		Tags:      make([]*ImageTag, 0),
+	}
+	rg, config, err := c.registryOf(ctx, cluster.RegionName)
+	if err != nil || rg == nil {
+		return images, err
+	}
+	tags, err := rg.ListImageTags(ctx, appName, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	protected := protectedTags(config, appName, cluster.Name, deployed)
+	for _, tag := range tags {
+		images.Tags = append(images.Tags, &ImageTag{
+			ImageTag: tag,
+			Deployed: protected[tag.Name],
+		})
+	}
+	return images, nil
+}
+
+func (c *controller) GetPolicy(ctx context.Context, applicationID uint) (*Policy, error) {
+	const op = "image controller: get policy"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.appMgr.GetByID(ctx, applicationID); err != nil {
+		return nil, err
+	}
+	policy, err := c.policyMgr.GetByApplicationID(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	return ofPolicy(policy), nil
+}
+
+func (c *controller) UpdatePolicy(ctx context.Context, applicationID uint, policy *Policy) (*Policy, error) {
+	const op = "image controller: update policy"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if policy.RetainCount < 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "retain count must not be negative")
+	}
+	if _, err := c.appMgr.GetByID(ctx, applicationID); err != nil {
+		return nil, err
+	}
+	updated, err := c.policyMgr.Upsert(ctx, &models.ImagePolicy{
+		ApplicationID: applicationID,
+		RetainCount:   policy.RetainCount,
+		BlockCritical: policy.BlockCritical,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ofPolicy(updated), nil
+}
+
+func (c *controller) Retain(ctx context.Context, applicationID uint) (int, error) {
+	const op = "image controller: retain"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	policy, err := c.policyMgr.GetByApplicationID(ctx, applicationID)
+	if err != nil || policy == nil || policy.RetainCount <= 0 {
+		return 0, err
+	}
+	application, err := c.appMgr.GetByID(ctx, applicationID)
+	if err != nil {
+		return 0, err
+	}
+	_, clusters, err := c.clusterMgr.ListByApplicationID(ctx, applicationID)
+	if err != nil {
+		return 0, err
+	}
+	deployed, err := c.deployedImages(ctx, clusters)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, cluster := range clusters {
+		rg, config, err := c.registryOf(ctx, cluster.RegionName)
+		if err != nil {
+			return deleted, err
+		}
+		if rg == nil {
+			continue
+		}
+		tags, err := rg.ListImageTags(ctx, application.Name, cluster.Name)
+		if err != nil {
+			return deleted, err
+		}
+		protected := protectedTags(config, application.Name, cluster.Name, deployed)
+		for _, tag := range expiredTags(tags, policy.RetainCount, protected) {
+			if err := rg.DeleteImageTag(ctx, application.Name, cluster.Name, tag.Name); err != nil {
+				return deleted, err
+			}
+			log.Infof(ctx, "deleted image tag %s of cluster %s by retention", tag.Name, cluster.Name)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// deployedImages returns the images currently deployed by clusters,
+// which are the images of clusters and the images of their latest succeeded pipelineruns
+func (c *controller) deployedImages(ctx context.Context,
+	clusters []*clustermodels.ClusterWithRegion) ([]string, error) {
+	images := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		if cluster.Image != "" {
+			images = append(images, cluster.Image)
+		}
+		pr, err := c.prMgr.PipelineRun.GetFirstCanRollbackPipelinerun(ctx, cluster.ID)
+		if err != nil {
+			return nil, err
+		}
+		if pr != nil && pr.ImageURL != "" {
+			images = append(images, pr.ImageURL)
+		}
+	}
+	return images, nil
+}
+
+// registryOf returns the registry of region, nil if the region has no registry
+func (c *controller) registryOf(ctx context.Context, regionName string) (registry.Registry, *registry.Config, error) {
+	regionEntity, err := c.regionMgr.GetRegionEntity(ctx, regionName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if regionEntity.Registry == nil {
+		return nil, nil, nil
+	}
+	config := &registry.Config{
+		Server:             regionEntity.Registry.Server,
+		Token:              regionEntity.Registry.Token,
+		Path:               regionEntity.Registry.Path,
+		InsecureSkipVerify: regionEntity.Registry.InsecureSkipTLSVerify,
+		Kind:               regionEntity.Registry.Kind,
+	}
+	rg, err := c.registryFty.GetRegistryByConfig(ctx, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rg, config, nil
+}
+
+func protectedTags(config *registry.Config, appName, clusterName string, deployed []string) map[string]bool {
+	protected := make(map[string]bool)
+	for _, image := range deployed {
+		if tag, ok := registry.ParseImageTag(config, image, appName, clusterName); ok {
+			protected[tag] = true
+		}
+	}
+	return protected
+}
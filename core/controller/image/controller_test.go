@@ -0,0 +1,159 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package image
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	registrymock "github.com/horizoncd/horizon/mock/pkg/cluster/registry"
+	registryftymock "github.com/horizoncd/horizon/mock/pkg/cluster/registry/factory"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/imagepolicy/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	registrymodels "github.com/horizoncd/horizon/pkg/registry/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
+)
+
+func TestController(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{}, &regionmodels.Region{},
+		&registrymodels.Registry{}, &prmodels.Pipelinerun{}, &templatemodels.Template{}, &models.ImagePolicy{}))
+	assert.Nil(t, db.Create(&registrymodels.Registry{Model: global.Model{ID: 1},
+		Server: "https://harbor.com", Path: "library", Kind: "harbor"}).Error)
+	assert.Nil(t, db.Create(&regionmodels.Region{Model: global.Model{ID: 1},
+		Name: "hz", RegistryID: 1}).Error)
+	assert.Nil(t, db.Create(&appmodels.Application{Model: global.Model{ID: 1}, Name: "app"}).Error)
+	assert.Nil(t, db.Create(&clustermodels.Cluster{Model: global.Model{ID: 1}, ApplicationID: 1,
+		Name: "app-test", RegionName: "hz", Image: "harbor.com/library/app/app-test:v2"}).Error)
+	assert.Nil(t, db.Create(&clustermodels.Cluster{Model: global.Model{ID: 2}, ApplicationID: 1,
+		Name: "app-online", RegionName: "hz"}).Error)
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	mgr := managerparam.InitManager(db)
+	// the online cluster is deployed with the image promoted from the test cluster
+	_, err = mgr.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: 2,
+		Action:    prmodels.ActionDeploy,
+		Status:    string(prmodels.StatusOK),
+		ImageURL:  "harbor.com/library/app/app-test:v1",
+	})
+	assert.Nil(t, err)
+
+	mockCtl := gomock.NewController(t)
+	rg := registrymock.NewMockRegistry(mockCtl)
+	registryFty := registryftymock.NewMockRegistryGetter(mockCtl)
+	registryFty.EXPECT().GetRegistryByConfig(gomock.Any(), gomock.Any()).Return(rg, nil).AnyTimes()
+	now := time.Now()
+	tags := make([]*registry.ImageTag, 0)
+	for i, name := range []string{"v4", "v3", "v2", "v1"} {
+		tags = append(tags, &registry.ImageTag{Name: name, PushedAt: now.Add(-time.Duration(i) * time.Hour)})
+	}
+	rg.EXPECT().ListImageTags(gomock.Any(), "app", "app-test").Return(tags, nil).AnyTimes()
+	rg.EXPECT().ListImageTags(gomock.Any(), "app", "app-online").Return([]*registry.ImageTag{}, nil).AnyTimes()
+
+	c := &controller{
+		policyMgr:   mgr.ImagePolicyMgr,
+		appMgr:      mgr.ApplicationMgr,
+		clusterMgr:  mgr.ClusterMgr,
+		regionMgr:   mgr.RegionMgr,
+		prMgr:       mgr.PRMgr,
+		registryFty: registryFty,
+	}
+
+	images, err := c.ListClusterImages(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(images.Tags))
+	deployed := make([]string, 0)
+	for _, tag := range images.Tags {
+		if tag.Deployed {
+			deployed = append(deployed, tag.Name)
+		}
+	}
+	assert.Equal(t, []string{"v2", "v1"}, deployed)
+	appImages, err := c.ListApplicationImages(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(appImages))
+
+	// nothing is deleted without a policy
+	deleted, err := c.Retain(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, deleted)
+	policy, err := c.GetPolicy(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, &Policy{}, policy)
+
+	_, err = c.UpdatePolicy(ctx, 1, &Policy{RetainCount: -1})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	policy, err = c.UpdatePolicy(ctx, 1, &Policy{RetainCount: 1, BlockCritical: true})
+	assert.Nil(t, err)
+	assert.Equal(t, &Policy{RetainCount: 1, BlockCritical: true}, policy)
+	policy, err = c.UpdatePolicy(ctx, 1, &Policy{RetainCount: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, &Policy{RetainCount: 1}, policy)
+
+	// the latest v4 is retained, and the deployed v2 and v1 are protected
+	rg.EXPECT().DeleteImageTag(gomock.Any(), "app", "app-test", "v3").Return(nil).Times(1)
+	deleted, err = c.Retain(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, deleted)
+}
+
+func TestExpiredTags(t *testing.T) {
+	tags := []*registry.ImageTag{{Name: "v4"}, {Name: "v3"}, {Name: "v2"}, {Name: "v1"}}
+	names := func(tags []*registry.ImageTag) []string {
+		ret := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			ret = append(ret, tag.Name)
+		}
+		return ret
+	}
+	assert.Equal(t, []string{"v2", "v1"}, names(expiredTags(tags, 2, nil)))
+	assert.Equal(t, []string{"v1"}, names(expiredTags(tags, 2, map[string]bool{"v4": true})))
+	assert.Equal(t, []string{}, names(expiredTags(tags, 4, nil)))
+	assert.Equal(t, []string{"v4", "v3", "v1"}, names(expiredTags(tags, 0, map[string]bool{"v2": true})))
+}
+
+func TestParseImageTag(t *testing.T) {
+	config := &registry.Config{Server: "https://harbor.com/", Path: "library"}
+	for image, expected := range map[string]string{
+		"harbor.com/library/app/app-test:v1":        "v1",
+		"harbor.com/library/app/app-test-canary:v1": "",
+		"harbor.com/other/app/app-test:v1":          "",
+		"harbor.com/library/app/app-test:":          "",
+		"harbor.com/library/app/app-test@sha256:1":  "",
+	} {
+		tag, ok := registry.ParseImageTag(config, image, "app", "app-test")
+		assert.Equal(t, expected != "", ok, image)
+		assert.Equal(t, expected, tag, image)
+	}
+}
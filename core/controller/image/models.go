@@ -0,0 +1,68 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package image
+
+import (
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	"github.com/horizoncd/horizon/pkg/imagepolicy/models"
+)
+
+type ClusterImages struct {
+	ClusterID uint        `json:"clusterID"`
+	Cluster   string      `json:"cluster"`
+	Tags      []*ImageTag `json:"tags"`
+}
+
+type ImageTag struct {
+	*registry.ImageTag
+	// Deployed is true if the image is currently deployed by a cluster of the application
+	Deployed bool `json:"deployed"`
+}
+
+type Policy struct {
+	// RetainCount is the count of the latest tags retained in the repository of each cluster,
+	// besides the images currently deployed, 0 disables the retention
+	RetainCount int `json:"retainCount"`
+	// BlockCritical blocks deploying images with critical vulnerabilities
+	BlockCritical bool `json:"blockCritical"`
+}
+
+func ofPolicy(policy *models.ImagePolicy) *Policy {
+	if policy == nil {
+		return &Policy{}
+	}
+	return &Policy{
+		RetainCount:   policy.RetainCount,
+		BlockCritical: policy.BlockCritical,
+	}
+}
+
+// expiredTags returns the tags beyond the retain count, tags are sorted by push time desc,
+// and the protected ones are neither counted nor expired
+func expiredTags(tags []*registry.ImageTag, retainCount int, protected map[string]bool) []*registry.ImageTag {
+	expired := make([]*registry.ImageTag, 0)
+	retained := 0
+	for _, tag := range tags {
+		if protected[tag.Name] {
+			continue
+		}
+		if retained < retainCount {
+			retained++
+			continue
+		}
+		expired = append(expired, tag)
+	}
+	return expired
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/horizoncd/horizon/pkg/member/models"
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/param"
+	teammanager "github.com/horizoncd/horizon/pkg/team/manager"
 	tmanager "github.com/horizoncd/horizon/pkg/template/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
@@ -67,9 +68,9 @@ type Member struct {
 	// MemberType user or group
 	MemberType models.MemberType `json:"memberType"`
 
-	// MemberName username or groupName
+	// MemberName username or teamName
 	MemberName string `json:"memberName"`
-	// MemberNameID userID or groupID
+	// MemberNameID userID or teamID
 	MemberNameID uint `json:"memberNameID"`
 
 	// Role the role name that bind
@@ -104,6 +105,7 @@ type converter struct {
 	clusterSvc     clusterservice.Service
 	templateMgr    tmanager.Manager
 	releaseMgr     trmanager.Manager
+	teamMgr        teammanager.Manager
 }
 
 func New(param *param.Param) ConvertMemberHelp {
@@ -114,6 +116,7 @@ func New(param *param.Param) ConvertMemberHelp {
 		clusterSvc:     param.ClusterSvc,
 		templateMgr:    param.TemplateMgr,
 		releaseMgr:     param.TemplateReleaseMgr,
+		teamMgr:        param.TeamMgr,
 	}
 }
 
@@ -132,8 +135,11 @@ func (c *converter) ConvertMember(ctx context.Context, member *models.Member) (_
 		}
 		memberInfo = user.Name
 	} else {
-		// TODO(tom) covert groupID to GroupName
-		return nil, errors.New("group member not support yet")
+		team, err := c.teamMgr.Get(ctx, member.MemberNameID)
+		if err != nil {
+			return nil, err
+		}
+		memberInfo = team.Name
 	}
 
 	return &Member{
@@ -149,13 +155,18 @@ func (c *converter) ConvertMember(ctx context.Context, member *models.Member) (_
 	}, nil
 }
 func (c *converter) ConvertMembers(ctx context.Context, members []models.Member) ([]Member, error) {
-	var userIDs []uint
+	var userIDs, teamIDs []uint
 
 	for _, member := range members {
-		if member.MemberType != models.MemberUser {
-			return nil, errors.New("Only Support User MemberType yet")
+		switch member.MemberType {
+		case models.MemberUser:
+			userIDs = append(userIDs, member.MemberNameID, member.GrantedBy)
+		case models.MemberGroup:
+			teamIDs = append(teamIDs, member.MemberNameID)
+			userIDs = append(userIDs, member.GrantedBy)
+		default:
+			return nil, errors.New("unsupported member type")
 		}
-		userIDs = append(userIDs, member.MemberNameID, member.GrantedBy)
 	}
 	users, err := c.userManager.GetUserByIDs(ctx, userIDs)
 	if err != nil {
@@ -165,6 +176,16 @@ func (c *converter) ConvertMembers(ctx context.Context, members []models.Member)
 	for _, userItem := range users {
 		userIDToName[userItem.ID] = userItem.Name
 	}
+	teamIDToName := make(map[uint]string)
+	if len(teamIDs) > 0 {
+		teams, err := c.teamMgr.GetByIDs(ctx, teamIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, team := range teams {
+			teamIDToName[team.ID] = team.Name
+		}
+	}
 	var retMembers []Member
 	for _, member := range members {
 		var resourceName, resourcePath string
@@ -211,10 +232,14 @@ func (c *converter) ConvertMembers(ctx context.Context, members []models.Member)
 		default:
 			return nil, fmt.Errorf("%s is not support now", member.ResourceType)
 		}
+		memberName := userIDToName[member.MemberNameID]
+		if member.MemberType == models.MemberGroup {
+			memberName = teamIDToName[member.MemberNameID]
+		}
 		retMembers = append(retMembers, Member{
 			ID:           member.ID,
 			MemberType:   member.MemberType,
-			MemberName:   userIDToName[member.MemberNameID],
+			MemberName:   memberName,
 			MemberNameID: member.MemberNameID,
 			ResourceType: member.ResourceType,
 			ResourceID:   member.ResourceID,
@@ -15,19 +15,30 @@
 package oauth
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/oauth/manager"
 	oauthmodel "github.com/horizoncd/horizon/pkg/oauth/models"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/token/generator"
+	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 	"golang.org/x/net/context"
 )
 
+const robotEmailSuffix = "@noreply.com"
+
 type AuthorizeReq struct {
 	ClientID     string
 	Scope        string
@@ -35,6 +46,12 @@ type AuthorizeReq struct {
 	State        string
 	UserIdentity uint
 
+	// CodeChallenge and CodeChallengeMethod are for PKCE, ref: rfc7636
+	CodeChallenge       string
+	CodeChallengeMethod string
+	// Nonce is returned in the ID token, ref: OpenID Connect Core 1.0
+	Nonce string
+
 	Request *http.Request
 }
 
@@ -54,7 +71,8 @@ type BaseTokenReq struct {
 
 type AccessTokenReq struct {
 	BaseTokenReq
-	Code string
+	Code         string
+	CodeVerifier string
 }
 
 type RefreshTokenReq struct {
@@ -62,12 +80,47 @@ type RefreshTokenReq struct {
 	RefreshToken string
 }
 
+type ClientCredentialsTokenReq struct {
+	ClientID     string
+	ClientSecret string
+	Scope        string
+
+	Request *http.Request
+}
+
+// ClientTokenReq is the request of a client on one of its tokens
+type ClientTokenReq struct {
+	ClientID     string
+	ClientSecret string
+	Token        string
+}
+
 type AccessTokenResponse struct {
 	AccessToken  string        `json:"access_token"`
-	RefreshToken string        `json:"refresh_token"`
+	RefreshToken string        `json:"refresh_token,omitempty"`
 	ExpiresIn    time.Duration `json:"expires_in"`
 	Scope        string        `json:"scope"`
 	TokenType    string        `json:"token_type"`
+	// IDToken is issued when the openid scope is granted
+	IDToken string `json:"id_token,omitempty"`
+}
+
+// OIDCProvider is the identity of horizon as an OIDC provider
+type OIDCProvider struct {
+	Issuer string
+	JWKS   *tokenservice.JWKS
+}
+
+// IntrospectionResponse ref: rfc7662
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	Subject   string `json:"sub,omitempty"`
 }
 
 type Controller interface {
@@ -76,16 +129,31 @@ type Controller interface {
 	// GenAccessToken Access Token GenOauthTokensRequest,ref:rfc6750
 	GenAccessToken(ctx context.Context, req *AccessTokenReq) (*AccessTokenResponse, error)
 	RefreshToken(ctx context.Context, req *RefreshTokenReq) (*AccessTokenResponse, error)
+	// GenClientCredentialsToken generates a token acting as the robot of the app, ref: rfc6749 4.4
+	GenClientCredentialsToken(ctx context.Context, req *ClientCredentialsTokenReq) (*AccessTokenResponse, error)
+	// IntrospectToken ref: rfc7662, only the tokens issued to the client are active
+	IntrospectToken(ctx context.Context, req *ClientTokenReq) (*IntrospectionResponse, error)
+	// RevokeToken ref: rfc7009, confidential clients must be authenticated by their secrets,
+	// while public clients revoke their tokens by client id only
+	RevokeToken(ctx context.Context, req *ClientTokenReq) error
+	// GetOIDCProvider returns the issuer and signing keys for OIDC discovery
+	GetOIDCProvider(ctx context.Context) (*OIDCProvider, error)
 }
 
 func NewController(param *param.Param) Controller {
-	return &controller{oauthManager: param.OauthManager}
+	return &controller{
+		oauthManager: param.OauthManager,
+		userManager:  param.UserMgr,
+		tokenSvc:     param.TokenSvc,
+	}
 }
 
 var _ Controller = &controller{}
 
 type controller struct {
 	oauthManager manager.Manager
+	userManager  usermanager.Manager
+	tokenSvc     tokenservice.Service
 }
 
 func (c *controller) GenAuthorizeCode(ctx context.Context, req *AuthorizeReq) (*AuthorizeCodeResponse, error) {
@@ -101,6 +169,10 @@ func (c *controller) GenAuthorizeCode(ctx context.Context, req *AuthorizeReq) (*
 		Scope:        req.Scope,
 		UserIdentify: req.UserIdentity,
 		Request:      req.Request,
+
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		Nonce:               req.Nonce,
 	})
 	if err != nil {
 		return nil, err
@@ -143,6 +215,7 @@ func (c *controller) GenAccessToken(ctx context.Context, req *AccessTokenReq) (*
 		ClientID:              req.ClientID,
 		ClientSecret:          req.ClientSecret,
 		Code:                  req.Code,
+		CodeVerifier:          req.CodeVerifier,
 		RedirectURL:           req.RedirectURL,
 		Request:               req.Request,
 		AccessTokenGenerator:  accessTokenGenerator,
@@ -151,13 +224,39 @@ func (c *controller) GenAccessToken(ctx context.Context, req *AccessTokenReq) (*
 	if err != nil {
 		return nil, err
 	}
-	return &AccessTokenResponse{
+	resp := &AccessTokenResponse{
 		AccessToken:  tokens.AccessToken.Code,
 		RefreshToken: tokens.RefreshToken.Code,
 		ExpiresIn:    tokens.AccessToken.ExpiresIn,
 		Scope:        tokens.AccessToken.Scope,
 		TokenType:    "bearer",
-	}, nil
+	}
+	if manager.IsOpenIDScope(tokens.AccessToken.Scope) && c.tokenSvc.Issuer() != "" {
+		resp.IDToken, err = c.genIDToken(ctx, req.ClientID, tokens)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// genIDToken ref: https://openid.net/specs/openid-connect-core-1_0.html#IDToken
+func (c *controller) genIDToken(ctx context.Context, clientID string,
+	tokens *manager.OauthTokensResponse) (string, error) {
+	user, err := c.userManager.GetUserByID(ctx, tokens.AccessToken.UserID)
+	if err != nil {
+		return "", err
+	}
+	return c.tokenSvc.CreateIDToken(&tokenservice.IDTokenClaims{
+		Nonce:             tokens.Nonce,
+		Name:              user.FullName,
+		PreferredUsername: user.Name,
+		Email:             user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  strconv.FormatUint(uint64(user.ID), 10),
+			Audience: jwt.ClaimStrings{clientID},
+		},
+	})
 }
 
 func (c *controller) RefreshToken(ctx context.Context,
@@ -188,3 +287,118 @@ func (c *controller) RefreshToken(ctx context.Context,
 		TokenType:    "bearer",
 	}, nil
 }
+
+func (c *controller) GenClientCredentialsToken(ctx context.Context,
+	req *ClientCredentialsTokenReq) (*AccessTokenResponse, error) {
+	const op = "oauth controller: GenClientCredentialsToken"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	app, err := c.oauthManager.AuthenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	robot, err := c.robotOf(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	accessTokenGenerator, err := c.getAccessTokenGenerator(ctx, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := c.oauthManager.GenClientCredentialsToken(ctx, &manager.OauthTokensRequest{
+		ClientID:             req.ClientID,
+		Scope:                req.Scope,
+		UserID:               robot.ID,
+		Request:              req.Request,
+		AccessTokenGenerator: accessTokenGenerator,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &AccessTokenResponse{
+		AccessToken: token.Code,
+		ExpiresIn:   token.ExpiresIn,
+		Scope:       token.Scope,
+		TokenType:   "bearer",
+	}, nil
+}
+
+// robotOf returns the robot user which the app acts as in the client credentials grant,
+// it is created on the first grant, and gains permissions by being added to members like other users
+func (c *controller) robotOf(ctx context.Context, app *oauthmodel.OauthApp) (*usermodels.User, error) {
+	fullName := fmt.Sprintf("oauthapp_%s_robot", app.ClientID)
+	email := fullName + robotEmailSuffix
+	_, robots, err := c.userManager.List(ctx, q.New(q.KeyWords{
+		common.UserQueryEmail: email,
+		common.UserQueryType:  []uint{usermodels.UserTypeRobot},
+	}))
+	if err != nil {
+		return nil, err
+	}
+	if len(robots) > 0 {
+		return robots[0], nil
+	}
+	return c.userManager.Create(ctx, &usermodels.User{
+		Name:     app.Name,
+		FullName: fullName,
+		Email:    email,
+		UserType: usermodels.UserTypeRobot,
+	})
+}
+
+func (c *controller) IntrospectToken(ctx context.Context, req *ClientTokenReq) (*IntrospectionResponse, error) {
+	const op = "oauth controller: IntrospectToken"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.oauthManager.AuthenticateClient(ctx, req.ClientID, req.ClientSecret); err != nil {
+		return nil, err
+	}
+	token, err := c.oauthManager.IntrospectToken(ctx, req.ClientID, req.Token)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return &IntrospectionResponse{Active: false}, nil
+	}
+	user, err := c.userManager.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return &IntrospectionResponse{Active: false}, nil
+		}
+		return nil, err
+	}
+
+	resp := &IntrospectionResponse{
+		Active:   true,
+		Scope:    token.Scope,
+		ClientID: token.ClientID,
+		Username: user.Name,
+		IssuedAt: token.CreatedAt.Unix(),
+		Subject:  strconv.FormatUint(uint64(token.UserID), 10),
+	}
+	if !strings.HasPrefix(req.Token, generator.RefreshTokenPrefix) {
+		resp.TokenType = "bearer"
+	}
+	if token.ExpiresIn > 0 {
+		resp.ExpiresAt = token.CreatedAt.Add(token.ExpiresIn).Unix()
+	}
+	return resp, nil
+}
+
+func (c *controller) RevokeToken(ctx context.Context, req *ClientTokenReq) error {
+	const op = "oauth controller: RevokeToken"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.oauthManager.RevokeToken(ctx, req.ClientID, req.ClientSecret, req.Token)
+}
+
+func (c *controller) GetOIDCProvider(ctx context.Context) (*OIDCProvider, error) {
+	if c.tokenSvc.Issuer() == "" {
+		return nil, perror.Wrap(herrors.ErrOIDCNotEnabled, "failed to get oidc provider")
+	}
+	return &OIDCProvider{
+		Issuer: c.tokenSvc.Issuer(),
+		JWKS:   c.tokenSvc.JWKS(),
+	}, nil
+}
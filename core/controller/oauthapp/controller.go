@@ -15,22 +15,37 @@
 package oauthapp
 
 import (
+	"strings"
 	"time"
 
 	"golang.org/x/net/context"
 
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/oauth/manager"
 	"github.com/horizoncd/horizon/pkg/oauth/models"
+	"github.com/horizoncd/horizon/pkg/oauth/scope"
 	"github.com/horizoncd/horizon/pkg/param"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
 
+const (
+	// ClientTypeConfidential is the default client type, the clients authenticate themselves by secrets
+	ClientTypeConfidential = "confidential"
+	// ClientTypePublic is for the clients which can't keep secrets, such as native or browser apps
+	ClientTypePublic = "public"
+)
+
 type CreateOauthAPPRequest struct {
 	Name        string `json:"name"`
 	Desc        string `json:"desc"`
 	HomeURL     string `json:"homeURL"`
 	RedirectURL string `json:"redirectURL"`
+	// ClientType is confidential or public, confidential by default
+	ClientType string `json:"clientType"`
+	// Scopes are granted to the app in the client credentials grant
+	Scopes []string `json:"scopes"`
 }
 
 type APPBasicInfo struct {
@@ -40,6 +55,8 @@ type APPBasicInfo struct {
 	HomeURL     string    `json:"homeURL"`
 	ClientID    string    `json:"clientID"`
 	RedirectURL string    `json:"redirectURL"`
+	ClientType  string    `json:"clientType"`
+	Scopes      []string  `json:"scopes"`
 	UpdatedBy   uint      `json:"updatedBy"`
 	UpdatedAt   time.Time `json:"updatedAt"`
 }
@@ -62,12 +79,14 @@ func NewController(param *param.Param) Controller {
 	return &controller{
 		oauthManager: param.OauthManager,
 		userManager:  param.UserMgr,
+		scopeService: param.ScopeService,
 	}
 }
 
 type controller struct {
 	oauthManager manager.Manager
 	userManager  usermanager.Manager
+	scopeService scope.Service
 }
 
 type SecretBasic struct {
@@ -137,6 +156,13 @@ func (c *controller) Create(ctx context.Context, groupID uint, request CreateOau
 	defer wlog.Start(ctx, op).StopPrint()
 
 	// TODO: check if have the permission to create
+	clientType, err := ofClientType(request.ClientType)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkScopes(request.Scopes); err != nil {
+		return nil, err
+	}
 	oauthApp, err := c.oauthManager.CreateOauthApp(ctx, &manager.CreateOAuthAppReq{
 		Name:        request.Name,
 		RedirectURI: request.RedirectURL,
@@ -145,21 +171,62 @@ func (c *controller) Create(ctx context.Context, groupID uint, request CreateOau
 		OwnerType:   models.GroupOwnerType,
 		OwnerID:     groupID,
 		APPType:     models.DirectOAuthAPP,
+		ClientType:  clientType,
+		Scopes:      request.Scopes,
 	})
 	if err != nil {
 		return nil, err
 	}
-	resp := &APPBasicInfo{
-		AppID:       oauthApp.ID,
-		AppName:     oauthApp.Name,
-		Desc:        oauthApp.Desc,
-		HomeURL:     oauthApp.Desc,
-		ClientID:    oauthApp.ClientID,
-		RedirectURL: oauthApp.RedirectURL,
-		UpdatedBy:   oauthApp.UpdatedBy,
-		UpdatedAt:   oauthApp.UpdatedAt,
+	return ofOauthApp(oauthApp), nil
+}
+
+func ofOauthApp(app *models.OauthApp) *APPBasicInfo {
+	clientType := ClientTypeConfidential
+	if app.IsPublicClient() {
+		clientType = ClientTypePublic
+	}
+	return &APPBasicInfo{
+		AppID:       app.ID,
+		AppName:     app.Name,
+		Desc:        app.Desc,
+		HomeURL:     app.HomeURL,
+		ClientID:    app.ClientID,
+		RedirectURL: app.RedirectURL,
+		ClientType:  clientType,
+		Scopes:      strings.Fields(app.Scopes),
+		UpdatedBy:   app.UpdatedBy,
+		UpdatedAt:   app.UpdatedAt,
+	}
+}
+
+// ofClientType converts the client type of the request, it's 0 if not set
+func ofClientType(clientType string) (models.ClientType, error) {
+	switch clientType {
+	case "":
+		return 0, nil
+	case ClientTypeConfidential:
+		return models.ConfidentialClient, nil
+	case ClientTypePublic:
+		return models.PublicClient, nil
+	}
+	return 0, perror.Wrapf(herrors.ErrParamInvalid, "invalid client type: %s", clientType)
+}
+
+// checkScopes checks the scopes granted to the app are defined
+func (c *controller) checkScopes(scopes []string) error {
+	for _, s := range scopes {
+		found := false
+		for _, name := range c.scopeService.GetAllScopeNames() {
+			if s == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return perror.Wrapf(herrors.ErrParamInvalid, "invalid scope: %s", s)
+		}
 	}
-	return resp, err
+	return nil
 }
 
 func (c *controller) Get(ctx context.Context, clientID string) (*APPBasicInfo, error) {
@@ -170,17 +237,7 @@ func (c *controller) Get(ctx context.Context, clientID string) (*APPBasicInfo, e
 	if err != nil {
 		return nil, err
 	}
-	resp := &APPBasicInfo{
-		AppID:       oauthApp.ID,
-		AppName:     oauthApp.Name,
-		Desc:        oauthApp.Desc,
-		HomeURL:     oauthApp.HomeURL,
-		ClientID:    oauthApp.ClientID,
-		RedirectURL: oauthApp.RedirectURL,
-		UpdatedBy:   oauthApp.UpdatedBy,
-		UpdatedAt:   oauthApp.UpdatedAt,
-	}
-	return resp, err
+	return ofOauthApp(oauthApp), nil
 }
 
 func (c *controller) List(ctx context.Context, groupID uint) ([]APPBasicInfo, error) {
@@ -192,17 +249,8 @@ func (c *controller) List(ctx context.Context, groupID uint) ([]APPBasicInfo, er
 		return nil, err
 	}
 	var appInfos = make([]APPBasicInfo, 0)
-	for _, app := range apps {
-		appInfos = append(appInfos, APPBasicInfo{
-			AppID:       app.ID,
-			AppName:     app.Name,
-			Desc:        app.Desc,
-			HomeURL:     app.HomeURL,
-			ClientID:    app.ClientID,
-			RedirectURL: app.RedirectURL,
-			UpdatedBy:   app.UpdatedBy,
-			UpdatedAt:   app.UpdatedAt,
-		})
+	for i := range apps {
+		appInfos = append(appInfos, *ofOauthApp(&apps[i]))
 	}
 	return appInfos, nil
 }
@@ -211,25 +259,33 @@ func (c *controller) Update(ctx context.Context, info APPBasicInfo) (*APPBasicIn
 	const op = "oauth  app controller  Update"
 	defer wlog.Start(ctx, op).StopPrint()
 
+	clientType, err := ofClientType(info.ClientType)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkScopes(info.Scopes); err != nil {
+		return nil, err
+	}
+	if clientType == 0 {
+		// the client type is kept if not set
+		app, err := c.oauthManager.GetOAuthApp(ctx, info.ClientID)
+		if err != nil {
+			return nil, err
+		}
+		clientType = app.ClientType
+	}
 	app, err := c.oauthManager.UpdateOauthApp(ctx, info.ClientID, manager.UpdateOauthAppReq{
 		Name:        info.AppName,
 		HomeURL:     info.HomeURL,
 		RedirectURI: info.RedirectURL,
 		Desc:        info.Desc,
+		ClientType:  clientType,
+		Scopes:      info.Scopes,
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &APPBasicInfo{
-		AppID:       app.ID,
-		AppName:     app.Name,
-		Desc:        app.Desc,
-		HomeURL:     app.HomeURL,
-		ClientID:    app.ClientID,
-		RedirectURL: app.RedirectURL,
-		UpdatedBy:   app.UpdatedBy,
-		UpdatedAt:   app.UpdatedAt,
-	}, nil
+	return ofOauthApp(app), nil
 }
 
 func (c *controller) Delete(ctx context.Context, clientID string) error {
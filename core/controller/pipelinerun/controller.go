@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/config"
@@ -38,10 +39,12 @@ import (
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
 	"github.com/horizoncd/horizon/pkg/config/token"
+	deploywindowservice "github.com/horizoncd/horizon/pkg/deploywindow/service"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
 	membermanager "github.com/horizoncd/horizon/pkg/member"
 	"github.com/horizoncd/horizon/pkg/param"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
@@ -60,6 +63,14 @@ import (
 type Controller interface {
 	GetPipelinerunLog(ctx context.Context, pipelinerunID uint) (*collector.Log, error)
 	GetClusterLatestLog(ctx context.Context, clusterID uint) (*collector.Log, error)
+	// GetStepLogs pages through the lines of the log of pipelinerun split by task and step
+	GetStepLogs(ctx context.Context, pipelinerunID uint, request *StepLogRequest) (*StepLogResponse, error)
+	// SearchClusterStepLogs searches the steps of the pipelineruns of cluster whose log contains the keyword
+	SearchClusterStepLogs(ctx context.Context, clusterID uint,
+		request *SearchStepLogsRequest) (int, []*StepLogMatch, error)
+	// SearchApplicationStepLogs searches the steps of the pipelineruns of application whose log contains the keyword
+	SearchApplicationStepLogs(ctx context.Context, applicationID uint,
+		request *SearchStepLogsRequest) (int, []*StepLogMatch, error)
 	GetDiff(ctx context.Context, pipelinerunID uint) (*GetDiffResponse, error)
 	GetPipelinerun(ctx context.Context, pipelinerunID uint) (*prmodels.PipelineBasic, error)
 	ListPipelineruns(ctx context.Context, clusterID uint, canRollback bool,
@@ -71,8 +82,11 @@ type Controller interface {
 	GetCheckRunByID(ctx context.Context, checkRunID uint) (*prmodels.CheckRun, error)
 	UpdateCheckRunByID(ctx context.Context, checkRunID uint, request *CreateOrUpdateCheckRunRequest) error
 
-	// Execute runs a pipelineRun only if its state is ready.
+	// Execute runs a pipelineRun only if its state is ready, and its scheduled time has come
+	// and deploy windows allow. Force overrides all of them and runs a pending pipelineRun too.
 	Execute(ctx context.Context, pipelinerunID uint, force bool) error
+	// ExecuteScheduled runs the scheduled or held pipelineRuns whose time has come when deploy windows allow.
+	ExecuteScheduled(ctx context.Context) error
 	// Cancel withdraws a pipelineRun only if its state is pending.
 	Cancel(ctx context.Context, pipelinerunID uint) error
 
@@ -101,6 +115,8 @@ type controller struct {
 	eventSvc           eventservice.Service
 	cd                 cd.CD
 	clusterSvc         clusterservice.Service
+	groupMgr           groupmanager.Manager
+	deployWindowSvc    deploywindowservice.Service
 }
 
 var _ Controller = (*controller)(nil)
@@ -124,6 +140,8 @@ func NewController(config *config.Config, param *param.Param) Controller {
 		eventSvc:           param.EventSvc,
 		cd:                 param.CD,
 		clusterSvc:         param.ClusterSvc,
+		groupMgr:           param.GroupMgr,
+		deployWindowSvc:    param.DeployWindowSvc,
 	}
 }
 
@@ -146,7 +164,7 @@ func (c *controller) GetPipelinerunLog(ctx context.Context, pipelinerunID uint)
 		return nil, errors.E(op, fmt.Errorf("%v action has no log", pr.Action))
 	}
 
-	return c.getPipelinerunLog(ctx, pr, cluster.EnvironmentName)
+	return c.getPipelinerunLog(ctx, pr, cluster)
 }
 
 func (c *controller) GetClusterLatestLog(ctx context.Context, clusterID uint) (_ *collector.Log, err error) {
@@ -166,17 +184,17 @@ func (c *controller) GetClusterLatestLog(ctx context.Context, clusterID uint) (_
 	if err != nil {
 		return nil, errors.E(op, err)
 	}
-	return c.getPipelinerunLog(ctx, pr, cluster.EnvironmentName)
+	return c.getPipelinerunLog(ctx, pr, cluster)
 }
 
 func (c *controller) getPipelinerunLog(ctx context.Context, pr *prmodels.Pipelinerun,
-	environment string) (_ *collector.Log, err error) {
+	cluster *clustermodels.Cluster) (_ *collector.Log, err error) {
 	const op = "pipeline controller: get pipelinerun log"
 	defer wlog.Start(ctx, op).StopPrint()
 
-	tektonCollector, err := c.tektonFty.GetTektonCollector(environment)
+	tektonCollector, err := c.tektonFty.GetTektonCollector(cluster.EnvironmentName, cluster.Template)
 	if err != nil {
-		return nil, perror.WithMessagef(err, "failed to get tekton collector for %s", environment)
+		return nil, perror.WithMessagef(err, "failed to get tekton collector for %s", cluster.EnvironmentName)
 	}
 
 	return tektonCollector.GetPipelineRunLog(ctx, pr)
@@ -305,7 +323,7 @@ func (c *controller) StopPipelinerun(ctx context.Context, pipelinerunID uint) (e
 		return errors.E(op, err)
 	}
 
-	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
+	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName, cluster.Template)
 	if err != nil {
 		return errors.E(op, err)
 	}
@@ -333,7 +351,7 @@ func (c *controller) StopPipelinerunForCluster(ctx context.Context, clusterID ui
 		return errors.E(op, err)
 	}
 
-	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
+	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName, cluster.Template)
 	if err != nil {
 		return errors.E(op, err)
 	}
@@ -382,6 +400,21 @@ func (c *controller) Execute(ctx context.Context, pipelinerunID uint, force bool
 		if pr.Status != string(prmodels.StatusReady) {
 			return perror.Wrapf(herrors.ErrParamInvalid, "pipelinerun is not ready to execute")
 		}
+		now := time.Now()
+		if pr.ScheduledAt != nil && pr.ScheduledAt.After(now) {
+			return perror.Wrapf(herrors.ErrParamInvalid, "pipelinerun is scheduled at %v", pr.ScheduledAt)
+		}
+		window, err := c.checkDeployWindows(ctx, pr, now)
+		if err != nil {
+			return err
+		}
+		if window != nil {
+			if err := c.hold(ctx, pr, window, now); err != nil {
+				return err
+			}
+			return perror.Wrapf(herrors.ErrParamInvalid,
+				"pipelinerun is held in pending by deploy window %s", window.Name)
+		}
 	}
 
 	err = c.execute(ctx, pr)
@@ -431,7 +464,7 @@ func (c *controller) executeDeploy(ctx context.Context, application *appmodels.A
 	}
 
 	// 2. create pipelinerun in k8s
-	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName)
+	tektonClient, err := c.tektonFty.GetTekton(cluster.EnvironmentName, cluster.Template)
 	if err != nil {
 		return err
 	}
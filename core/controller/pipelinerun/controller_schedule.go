@@ -0,0 +1,136 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	deploywindowmodels "github.com/horizoncd/horizon/pkg/deploywindow/models"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+func (c *controller) ExecuteScheduled(ctx context.Context) error {
+	const op = "pipelinerun controller: execute scheduled pipelineruns"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	now := time.Now()
+	prs, err := c.prMgr.PipelineRun.ListScheduled(ctx, now)
+	if err != nil {
+		return err
+	}
+	for _, pr := range prs {
+		if err := c.executeScheduled(ctx, pr, now); err != nil {
+			log.Errorf(ctx, "failed to execute scheduled pipelinerun %d, err: %+v", pr.ID, err)
+		}
+	}
+	return nil
+}
+
+// executeScheduled runs the pipelinerun as its creator, once its checks are passed and deploy windows allow
+func (c *controller) executeScheduled(ctx context.Context, pr *prmodels.Pipelinerun, now time.Time) error {
+	creator, err := c.userMgr.GetUserByID(ctx, pr.CreatedBy)
+	if err != nil {
+		return err
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     creator.Name,
+		FullName: creator.FullName,
+		ID:       creator.ID,
+		Email:    creator.Email,
+		Admin:    creator.Admin,
+	})
+
+	if pr.Status == string(prmodels.StatusPending) {
+		status, err := c.calculatePrSuccessStatus(ctx, pr)
+		if err != nil {
+			return err
+		}
+		if status != prmodels.StatusReady {
+			return nil
+		}
+	}
+
+	window, err := c.checkDeployWindows(ctx, pr, now)
+	if err != nil {
+		return err
+	}
+	if window != nil {
+		if pr.Status == string(prmodels.StatusReady) {
+			return c.hold(ctx, pr, window, now)
+		}
+		return nil
+	}
+
+	if err := c.execute(ctx, pr); err != nil {
+		c.createMessage(ctx, pr.ID, fmt.Sprintf("Failed to execute as scheduled: %v", err))
+		if updateErr := c.prMgr.PipelineRun.UpdateStatusByID(ctx, pr.ID, prmodels.StatusFailed); updateErr != nil {
+			log.Errorf(ctx, "failed to update status of pipelinerun %d, err: %v", pr.ID, updateErr)
+		}
+		return err
+	}
+	c.createMessage(ctx, pr.ID, "Executed as scheduled")
+	c.eventSvc.CreateEventIgnoreError(ctx, common.ResourcePipelinerun, pr.ID,
+		eventmodels.PipelinerunExecuted, nil)
+	return nil
+}
+
+// checkDeployWindows returns the deploy window which holds the pipelinerun at the time
+func (c *controller) checkDeployWindows(ctx context.Context, pr *prmodels.Pipelinerun,
+	now time.Time) (*deploywindowmodels.DeployWindow, error) {
+	cluster, err := c.clusterMgr.GetByID(ctx, pr.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	return c.deployWindowSvc.Check(ctx, cluster, now)
+}
+
+// hold puts the pipelinerun back to pending, it's executed by ExecuteScheduled when deploy windows allow
+func (c *controller) hold(ctx context.Context, pr *prmodels.Pipelinerun,
+	window *deploywindowmodels.DeployWindow, now time.Time) error {
+	columns := map[string]interface{}{
+		"status": string(prmodels.StatusPending),
+	}
+	if pr.ScheduledAt == nil {
+		columns["scheduled_at"] = now
+	}
+	if err := c.prMgr.PipelineRun.UpdateColumns(ctx, pr.ID, columns); err != nil {
+		return err
+	}
+	c.createMessage(ctx, pr.ID, fmt.Sprintf("Held in pending by %s deploy window %s", window.Type, window.Name))
+	return nil
+}
+
+func (c *controller) createMessage(ctx context.Context, pipelinerunID uint, content string) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		log.Errorf(ctx, "failed to get current user, err: %v", err)
+		return
+	}
+	if _, err := c.prMgr.Message.Create(ctx, &prmodels.PRMessage{
+		PipelineRunID: pipelinerunID,
+		Content:       content,
+		CreatedBy:     currentUser.GetID(),
+		UpdatedBy:     currentUser.GetID(),
+	}); err != nil {
+		log.Errorf(ctx, "failed to create message of pipelinerun %d, err: %v", pipelinerunID, err)
+	}
+}
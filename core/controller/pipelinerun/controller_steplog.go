@@ -0,0 +1,243 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinerun
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	_defaultLinePageSize = 500
+	_maxLinePageSize     = 5000
+	// _maxMatchedLines is the max count of matched lines returned for each step by search
+	_maxMatchedLines     = 10
+	_defaultSearchPeriod = 7 * 24 * time.Hour
+	_maxSearchPeriod     = 31 * 24 * time.Hour
+)
+
+func (c *controller) GetStepLogs(ctx context.Context, pipelinerunID uint,
+	request *StepLogRequest) (_ *StepLogResponse, err error) {
+	const op = "pipelinerun controller: get step logs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	pr, err := c.prMgr.PipelineRun.GetByID(ctx, pipelinerunID)
+	if err != nil {
+		return nil, err
+	}
+	stepLogs, err := c.getStepLogs(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &StepLogResponse{
+		Steps: make([]*Step, 0, len(stepLogs)),
+		Lines: make([]*LogLine, 0),
+	}
+	lines := make([]*LogLine, 0)
+	for _, stepLog := range stepLogs {
+		resp.Steps = append(resp.Steps, &Step{
+			Task:       stepLog.Task,
+			Step:       stepLog.Step,
+			StartedAt:  stepLog.StartedAt,
+			FinishedAt: stepLog.FinishedAt,
+			LineCount:  len(stepLog.Lines),
+		})
+		if (request.Task != "" && request.Task != stepLog.Task) ||
+			(request.Step != "" && request.Step != stepLog.Step) {
+			continue
+		}
+		lines = append(lines, matchLines(stepLog, request.Keyword)...)
+	}
+	resp.Total = len(lines)
+
+	pageNumber, pageSize := request.PageNumber, request.PageSize
+	if pageNumber < 1 {
+		pageNumber = common.DefaultPageNumber
+	}
+	if pageSize < 1 {
+		pageSize = _defaultLinePageSize
+	}
+	if pageSize > _maxLinePageSize {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "page size must not be larger than %d", _maxLinePageSize)
+	}
+	if start := (pageNumber - 1) * pageSize; start < len(lines) {
+		end := start + pageSize
+		if end > len(lines) {
+			end = len(lines)
+		}
+		resp.Lines = lines[start:end]
+	}
+	return resp, nil
+}
+
+// getStepLogs returns the step logs stored when the pipelinerun is collected,
+// or splits the log from collector if they are not stored, such as the pipelinerun is still running
+func (c *controller) getStepLogs(ctx context.Context, pr *prmodels.Pipelinerun) ([]*collector.StepLog, error) {
+	stored, err := c.prMgr.StepLog.ListByPipelinerunID(ctx, pr.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(stored) > 0 {
+		stepLogs := make([]*collector.StepLog, 0, len(stored))
+		for _, s := range stored {
+			stepLogs = append(stepLogs, &collector.StepLog{
+				Task:       s.Task,
+				Step:       s.Step,
+				StartedAt:  s.StartedAt,
+				FinishedAt: s.FinishedAt,
+				Lines:      splitLines(s.Content),
+			})
+		}
+		return stepLogs, nil
+	}
+
+	// only builddeploy and deploy have logs
+	if pr.Action != prmodels.ActionBuildDeploy && pr.Action != prmodels.ActionDeploy {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "%v action has no log", pr.Action)
+	}
+	cluster, err := c.clusterMgr.GetByID(ctx, pr.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	tektonCollector, err := c.tektonFty.GetTektonCollector(cluster.EnvironmentName, cluster.Template)
+	if err != nil {
+		return nil, perror.WithMessagef(err, "failed to get tekton collector for %s", cluster.EnvironmentName)
+	}
+	l, err := tektonCollector.GetPipelineRunLog(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	stepLogs, err := collector.ReadStepLogs(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+	// times of steps are nice to have, so the log is returned without them if the pipelinerun is not found
+	tektonPipelineRun, err := tektonCollector.GetPipelineRun(ctx, pr)
+	if err != nil {
+		log.Warningf(ctx, "failed to get pipelinerun %d for times of steps, err: %v", pr.ID, err)
+	} else {
+		collector.SetStepTimes(stepLogs, tektonPipelineRun)
+	}
+	return stepLogs, nil
+}
+
+func (c *controller) SearchClusterStepLogs(ctx context.Context, clusterID uint,
+	request *SearchStepLogsRequest) (_ int, _ []*StepLogMatch, err error) {
+	const op = "pipelinerun controller: search cluster step logs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.clusterMgr.GetByID(ctx, clusterID); err != nil {
+		return 0, nil, err
+	}
+	return c.searchStepLogs(ctx, &prmodels.StepLogSearch{ClusterID: clusterID}, request)
+}
+
+func (c *controller) SearchApplicationStepLogs(ctx context.Context, applicationID uint,
+	request *SearchStepLogsRequest) (_ int, _ []*StepLogMatch, err error) {
+	const op = "pipelinerun controller: search application step logs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.appMgr.GetByID(ctx, applicationID); err != nil {
+		return 0, nil, err
+	}
+	return c.searchStepLogs(ctx, &prmodels.StepLogSearch{ApplicationID: applicationID}, request)
+}
+
+func (c *controller) searchStepLogs(ctx context.Context, search *prmodels.StepLogSearch,
+	request *SearchStepLogsRequest) (int, []*StepLogMatch, error) {
+	if request.Keyword == "" {
+		return 0, nil, perror.Wrap(herrors.ErrParamInvalid, "keyword is required")
+	}
+	until := request.Until
+	if until.IsZero() {
+		until = time.Now()
+	}
+	since := request.Since
+	if since.IsZero() {
+		since = until.Add(-_defaultSearchPeriod)
+	}
+	if !since.Before(until) || until.Sub(since) > _maxSearchPeriod {
+		return 0, nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"period to search must be positive and not longer than %v", _maxSearchPeriod)
+	}
+	search.Keyword, search.Task, search.Step = request.Keyword, request.Task, request.Step
+	search.Since, search.Until = since, until
+
+	total, stepLogs, err := c.prMgr.StepLog.Search(ctx, search, &q.Query{
+		PageNumber: request.PageNumber,
+		PageSize:   request.PageSize,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	matches := make([]*StepLogMatch, 0, len(stepLogs))
+	for _, s := range stepLogs {
+		lines := matchLines(&collector.StepLog{
+			Task:  s.Task,
+			Step:  s.Step,
+			Lines: splitLines(s.Content),
+		}, request.Keyword)
+		match := &StepLogMatch{
+			PipelinerunID: s.PipelinerunID,
+			ClusterID:     s.ClusterID,
+			Task:          s.Task,
+			Step:          s.Step,
+			StartedAt:     s.StartedAt,
+			CreatedAt:     s.CreatedAt,
+			MatchedCount:  len(lines),
+			Lines:         lines,
+		}
+		if len(lines) > _maxMatchedLines {
+			match.Lines = lines[:_maxMatchedLines]
+		}
+		matches = append(matches, match)
+	}
+	return total, matches, nil
+}
+
+// matchLines returns the lines of step containing keyword, all the lines if keyword is empty
+func matchLines(stepLog *collector.StepLog, keyword string) []*LogLine {
+	lines := make([]*LogLine, 0)
+	for i, line := range stepLog.Lines {
+		if keyword != "" && !strings.Contains(line, keyword) {
+			continue
+		}
+		lines = append(lines, &LogLine{
+			Task:    stepLog.Task,
+			Step:    stepLog.Step,
+			Number:  i + 1,
+			Content: line,
+		})
+	}
+	return lines
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return []string{}
+	}
+	return strings.Split(content, "\n")
+}
@@ -26,6 +26,9 @@ import (
 	cdmock "github.com/horizoncd/horizon/mock/pkg/cd"
 	applicationservice "github.com/horizoncd/horizon/pkg/application/service"
 	clusterservice "github.com/horizoncd/horizon/pkg/cluster/service"
+	deploywindowmodels "github.com/horizoncd/horizon/pkg/deploywindow/models"
+	deploywindowservice "github.com/horizoncd/horizon/pkg/deploywindow/service"
+	environmentmodels "github.com/horizoncd/horizon/pkg/environment/models"
 	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
 	eventservice "github.com/horizoncd/horizon/pkg/event/service"
 	groupservice "github.com/horizoncd/horizon/pkg/group/service"
@@ -271,8 +274,8 @@ func Test(t *testing.T) {
 	tektonFty := tektonftymock.NewMockFactory(mockCtl)
 	tekton := tektonmock.NewMockInterface(mockCtl)
 	tektonCollector := tektoncollectormock.NewMockInterface(mockCtl)
-	tektonFty.EXPECT().GetTekton(gomock.Any()).Return(tekton, nil).AnyTimes()
-	tektonFty.EXPECT().GetTektonCollector(gomock.Any()).Return(tektonCollector, nil).AnyTimes()
+	tektonFty.EXPECT().GetTekton(gomock.Any(), gomock.Any()).Return(tekton, nil).AnyTimes()
+	tektonFty.EXPECT().GetTektonCollector(gomock.Any(), gomock.Any()).Return(tektonCollector, nil).AnyTimes()
 
 	envMgr := manager.EnvMgr
 
@@ -405,7 +408,9 @@ func TestExecutePipelineRun(t *testing.T) {
 	if err := db.AutoMigrate(&applicationmodel.Application{}, &clustermodel.Cluster{},
 		&regionmodels.Region{}, &membermodels.Member{}, &registrymodels.Registry{},
 		&prmodels.Pipelinerun{}, &groupmodels.Group{}, &prmodels.Check{},
-		&usermodel.User{}, &trmodels.TemplateRelease{}, &eventmodels.Event{}); err != nil {
+		&usermodel.User{}, &trmodels.TemplateRelease{}, &eventmodels.Event{},
+		&environmentmodels.Environment{}, &deploywindowmodels.DeployWindow{},
+		&prmodels.PRMessage{}, &prmodels.CheckRun{}); err != nil {
 		panic(err)
 	}
 	mgr := managerparam.InitManager(db)
@@ -420,7 +425,7 @@ func TestExecutePipelineRun(t *testing.T) {
 	mockTektonInterface := tektonmock.NewMockInterface(mockCtl)
 
 	mockFactory := tektonftymock.NewMockFactory(mockCtl)
-	mockFactory.EXPECT().GetTekton(gomock.Any()).Return(mockTektonInterface, nil).AnyTimes()
+	mockFactory.EXPECT().GetTekton(gomock.Any(), gomock.Any()).Return(mockTektonInterface, nil).AnyTimes()
 	tokenConfig := token.Config{
 		JwtSigningKey:         "hello",
 		CallbackTokenExpireIn: 24 * time.Hour,
@@ -432,6 +437,8 @@ func TestExecutePipelineRun(t *testing.T) {
 	eventSvc := eventservice.New(mgr)
 	applicationSvc := applicationservice.NewService(groupSvc, mgr)
 	clusterSvc := clusterservice.NewService(applicationSvc, mockClusterGitRepo, mgr)
+	tokenSvc, err := tokenservice.NewService(mgr, tokenConfig)
+	assert.Nil(t, err)
 
 	ctrl := controller{
 		prMgr:              mgr.PRMgr,
@@ -440,13 +447,17 @@ func TestExecutePipelineRun(t *testing.T) {
 		envMgr:             mgr.EnvMgr,
 		regionMgr:          mgr.RegionMgr,
 		tektonFty:          mockFactory,
-		tokenSvc:           tokenservice.NewService(mgr, tokenConfig),
+		tokenSvc:           tokenSvc,
 		tokenConfig:        tokenConfig,
 		clusterGitRepo:     mockClusterGitRepo,
 		templateReleaseMgr: mgr.TemplateReleaseMgr,
 		cd:                 mockCD,
 		clusterSvc:         clusterSvc,
 		eventSvc:           eventSvc,
+		prSvc:              prservice.NewService(mgr),
+		userMgr:            mgr.UserMgr,
+		groupMgr:           mgr.GroupMgr,
+		deployWindowSvc:    deploywindowservice.NewService(mgr),
 	}
 
 	_, err1 := mgr.EventMgr.CreateEvent(ctx, &eventmodels.Event{
@@ -456,7 +467,7 @@ func TestExecutePipelineRun(t *testing.T) {
 	})
 	assert.NoError(t, err1)
 
-	_, err := mgr.UserMgr.Create(ctx, &usermodel.User{
+	_, err = mgr.UserMgr.Create(ctx, &usermodel.User{
 		Name: "Tony",
 	})
 	assert.NoError(t, err)
@@ -610,6 +621,65 @@ func TestExecutePipelineRun(t *testing.T) {
 
 	err = ctrl.Cancel(ctx, prDeployReady.ID)
 	assert.NotNil(t, err)
+
+	// scheduled pipelinerun is not executed before its time
+	future := time.Now().Add(time.Hour)
+	prScheduled, err := mgr.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID:   cluster.ID,
+		Action:      prmodels.ActionRestart,
+		Status:      string(pipelinemodel.StatusReady),
+		ScheduledAt: &future,
+		CreatedBy:   1,
+	})
+	assert.NoError(t, err)
+	err = ctrl.Execute(ctx, prScheduled.ID, false)
+	assert.NotNil(t, err)
+
+	// deny window of group holds the pipelinerun in pending
+	window, err := mgr.DeployWindowMgr.Create(ctx, &deploywindowmodels.DeployWindow{
+		ResourceType: common.ResourceGroup,
+		ResourceID:   group.ID,
+		Name:         "always",
+		Enabled:      true,
+		Type:         deploywindowmodels.TypeDeny,
+		Schedule:     "* * * * *",
+		Duration:     3600,
+	})
+	assert.NoError(t, err)
+
+	prHeld, err := mgr.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    prmodels.ActionRestart,
+		Status:    string(pipelinemodel.StatusReady),
+		CreatedBy: 1,
+	})
+	assert.NoError(t, err)
+	err = ctrl.Execute(ctx, prHeld.ID, false)
+	assert.NotNil(t, err)
+	prHeld, err = mgr.PRMgr.PipelineRun.GetByID(ctx, prHeld.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, string(pipelinemodel.StatusPending), prHeld.Status)
+	assert.NotNil(t, prHeld.ScheduledAt)
+
+	err = ctrl.ExecuteScheduled(ctx)
+	assert.NoError(t, err)
+	prHeld, err = mgr.PRMgr.PipelineRun.GetByID(ctx, prHeld.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, string(pipelinemodel.StatusPending), prHeld.Status)
+
+	// held pipelinerun is executed once the window closes
+	window.Enabled = false
+	_, err = mgr.DeployWindowMgr.Update(ctx, window)
+	assert.NoError(t, err)
+	err = ctrl.ExecuteScheduled(ctx)
+	assert.NoError(t, err)
+	prHeld, err = mgr.PRMgr.PipelineRun.GetByID(ctx, prHeld.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, string(pipelinemodel.StatusOK), prHeld.Status)
+
+	prScheduled, err = mgr.PRMgr.PipelineRun.GetByID(ctx, prScheduled.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, string(pipelinemodel.StatusReady), prScheduled.Status)
 }
 
 func TestCheckRun(t *testing.T) {
@@ -742,3 +812,85 @@ func TestMessage(t *testing.T) {
 	assert.Equal(t, messages[0].Content, "first")
 	assert.Equal(t, messages[1].Content, "second")
 }
+
+func TestStepLogs(t *testing.T) {
+	db, _ := orm.NewSqliteDB("")
+	if err := db.AutoMigrate(&prmodels.Pipelinerun{}, &prmodels.StepLog{}, &clustermodel.Cluster{},
+		&applicationmodel.Application{}, &membermodels.Member{}); err != nil {
+		panic(err)
+	}
+	param := managerparam.InitManager(db)
+	mockCtl := gomock.NewController(t)
+	tektonFty := tektonftymock.NewMockFactory(mockCtl)
+	tektonCollector := tektoncollectormock.NewMockInterface(mockCtl)
+	tektonFty.EXPECT().GetTektonCollector(gomock.Any(), gomock.Any()).Return(tektonCollector, nil).AnyTimes()
+
+	app, err := param.ApplicationMgr.Create(ctx, &applicationmodel.Application{Name: "app"}, nil)
+	assert.Nil(t, err)
+	cluster, err := param.ClusterMgr.Create(ctx, &clustermodel.Cluster{
+		ApplicationID:   app.ID,
+		Name:            "cluster",
+		EnvironmentName: "test",
+	}, nil, nil)
+	assert.Nil(t, err)
+
+	ctrl := controller{
+		prMgr:      param.PRMgr,
+		appMgr:     param.ApplicationMgr,
+		clusterMgr: param.ClusterMgr,
+		tektonFty:  tektonFty,
+	}
+
+	// the log is split from collector if step logs are not stored
+	running, err := param.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    prmodels.ActionBuildDeploy,
+		Status:    string(prmodels.StatusRunning),
+	})
+	assert.Nil(t, err)
+	tektonCollector.EXPECT().GetPipelineRunLog(gomock.Any(), gomock.Any()).Return(&collector.Log{
+		LogBytes: []byte("[build : git] cloning\n[build : compile] [ERROR] BUILD FAILURE\n"),
+	}, nil)
+	tektonCollector.EXPECT().GetPipelineRun(gomock.Any(), gomock.Any()).Return(nil, nil)
+	resp, err := ctrl.GetStepLogs(ctx, running.ID, &StepLogRequest{Step: "compile"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(resp.Steps))
+	assert.Equal(t, 1, resp.Total)
+	assert.Equal(t, "[ERROR] BUILD FAILURE", resp.Lines[0].Content)
+
+	finished, err := param.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: cluster.ID,
+		Action:    prmodels.ActionBuildDeploy,
+		Status:    string(prmodels.StatusFailed),
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, param.PRMgr.StepLog.Create(ctx, []*prmodels.StepLog{
+		{PipelinerunID: finished.ID, ApplicationID: app.ID, ClusterID: cluster.ID, Task: "build", Step: "git",
+			Sequence: 0, Content: "cloning"},
+		{PipelinerunID: finished.ID, ApplicationID: app.ID, ClusterID: cluster.ID, Task: "build", Step: "compile",
+			Sequence: 1, Content: "line1\n[ERROR] BUILD FAILURE\nline3\n[ERROR] BUILD FAILURE"},
+	}))
+	resp, err = ctrl.GetStepLogs(ctx, finished.ID, &StepLogRequest{PageNumber: 2, PageSize: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, resp.Total)
+	assert.Equal(t, 2, len(resp.Lines))
+	assert.Equal(t, "compile", resp.Lines[0].Step)
+	assert.Equal(t, 2, resp.Lines[0].Number)
+	_, err = ctrl.GetStepLogs(ctx, finished.ID, &StepLogRequest{PageSize: _maxLinePageSize + 1})
+	assert.NotNil(t, err)
+
+	total, matches, err := ctrl.SearchApplicationStepLogs(ctx, app.ID, &SearchStepLogsRequest{Keyword: "BUILD FAILURE"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, finished.ID, matches[0].PipelinerunID)
+	assert.Equal(t, 2, matches[0].MatchedCount)
+	assert.Equal(t, 4, matches[0].Lines[1].Number)
+	total, _, err = ctrl.SearchClusterStepLogs(ctx, cluster.ID, &SearchStepLogsRequest{
+		Keyword: "BUILD FAILURE",
+		Until:   time.Now().Add(-time.Hour),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, total)
+	_, _, err = ctrl.SearchClusterStepLogs(ctx, cluster.ID, &SearchStepLogsRequest{})
+	assert.NotNil(t, err)
+}
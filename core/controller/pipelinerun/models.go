@@ -71,3 +71,61 @@ type CreateOrUpdateCheckRunRequest struct {
 	ExternalID string `json:"externalId"`
 	DetailURL  string `json:"detailUrl"`
 }
+
+type StepLogRequest struct {
+	Task string
+	Step string
+	// Keyword filters the lines containing it
+	Keyword string
+	// PageNumber and PageSize page through the lines
+	PageNumber int
+	PageSize   int
+}
+
+type StepLogResponse struct {
+	// Steps are all the steps of pipelinerun in the order of output
+	Steps []*Step `json:"steps"`
+	// Total is the count of the lines matching request
+	Total int        `json:"total"`
+	Lines []*LogLine `json:"lines"`
+}
+
+type Step struct {
+	Task       string     `json:"task"`
+	Step       string     `json:"step"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	LineCount  int        `json:"lineCount"`
+}
+
+type LogLine struct {
+	Task string `json:"task"`
+	Step string `json:"step"`
+	// Number is the line number in the step, starting from 1
+	Number  int    `json:"number"`
+	Content string `json:"content"`
+}
+
+type SearchStepLogsRequest struct {
+	Keyword string
+	Task    string
+	Step    string
+	// Since and Until limit the time of pipelineruns, the last 7 days by default
+	Since      time.Time
+	Until      time.Time
+	PageNumber int
+	PageSize   int
+}
+
+// StepLogMatch is a step of pipelinerun whose log contains the keyword
+type StepLogMatch struct {
+	PipelinerunID uint       `json:"pipelinerunID"`
+	ClusterID     uint       `json:"clusterID"`
+	Task          string     `json:"task"`
+	Step          string     `json:"step"`
+	StartedAt     *time.Time `json:"startedAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	// MatchedCount is the count of the lines containing the keyword, only the first ones are returned in Lines
+	MatchedCount int        `json:"matchedCount"`
+	Lines        []*LogLine `json:"lines"`
+}
@@ -0,0 +1,501 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasetrain
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	"github.com/horizoncd/horizon/pkg/auth"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	"github.com/horizoncd/horizon/pkg/param"
+	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/rbac"
+	trainmanager "github.com/horizoncd/horizon/pkg/releasetrain/manager"
+	"github.com/horizoncd/horizon/pkg/releasetrain/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _maxNameLength = 64
+
+type Controller interface {
+	CreateTrain(ctx context.Context, applicationID uint, request *CreateTrainRequest) (*Train, error)
+	ListTrains(ctx context.Context, applicationID uint, query *q.Query) ([]*Train, int64, error)
+	GetTrain(ctx context.Context, id uint) (*Train, error)
+	UpdateTrain(ctx context.Context, id uint, request *CreateTrainRequest) (*Train, error)
+	DeleteTrain(ctx context.Context, id uint) error
+
+	// StartRun starts to release by the release train, the stages are driven by the release train job
+	StartRun(ctx context.Context, trainID uint, request *StartRunRequest) (*Run, error)
+	ListRuns(ctx context.Context, trainID uint, query *q.Query) ([]*Run, int64, error)
+	GetRun(ctx context.Context, runID uint) (*Run, error)
+	// ApproveRun passes the approval gate of the current stage
+	ApproveRun(ctx context.Context, runID uint) error
+	// CancelRun stops the run, the pipelineruns of the current stage are cancelled if they are not executed
+	CancelRun(ctx context.Context, runID uint) error
+}
+
+type controller struct {
+	authorizer rbac.Authorizer
+	trainMgr   trainmanager.Manager
+	appMgr     appmanager.Manager
+	clusterMgr clustermanager.Manager
+	envMgr     envmanager.Manager
+	prMgr      *prmanager.PRManager
+	eventSvc   eventservice.Service
+}
+
+func NewController(authorizer rbac.Authorizer, param *param.Param) Controller {
+	return &controller{
+		authorizer: authorizer,
+		trainMgr:   param.ReleaseTrainMgr,
+		appMgr:     param.ApplicationMgr,
+		clusterMgr: param.ClusterMgr,
+		envMgr:     param.EnvMgr,
+		prMgr:      param.PRMgr,
+		eventSvc:   param.EventSvc,
+	}
+}
+
+func (c *controller) CreateTrain(ctx context.Context, applicationID uint,
+	request *CreateTrainRequest) (*Train, error) {
+	const op = "release train controller: create"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.appMgr.GetByID(ctx, applicationID); err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stages, err := c.validateTrain(ctx, applicationID, request)
+	if err != nil {
+		return nil, err
+	}
+	train := &models.ReleaseTrain{
+		ApplicationID: applicationID,
+		Name:          request.Name,
+		Description:   request.Description,
+		CreatedBy:     currentUser.GetID(),
+		UpdatedBy:     currentUser.GetID(),
+	}
+	train, err = c.trainMgr.Create(ctx, train, stages)
+	if err != nil {
+		return nil, err
+	}
+	return ofTrainModel(train, stages), nil
+}
+
+func (c *controller) ListTrains(ctx context.Context, applicationID uint,
+	query *q.Query) ([]*Train, int64, error) {
+	const op = "release train controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	trains, total, err := c.trainMgr.List(ctx, applicationID, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	result := make([]*Train, 0, len(trains))
+	for _, train := range trains {
+		stages, err := c.trainMgr.ListStages(ctx, train.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		result = append(result, ofTrainModel(train, stages))
+	}
+	return result, total, nil
+}
+
+func (c *controller) GetTrain(ctx context.Context, id uint) (*Train, error) {
+	const op = "release train controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	train, err := c.trainMgr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	stages, err := c.trainMgr.ListStages(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofTrainModel(train, stages), nil
+}
+
+func (c *controller) UpdateTrain(ctx context.Context, id uint,
+	request *CreateTrainRequest) (*Train, error) {
+	const op = "release train controller: update"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	train, err := c.trainMgr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stages, err := c.validateTrain(ctx, train.ApplicationID, request)
+	if err != nil {
+		return nil, err
+	}
+	train.Name = request.Name
+	train.Description = request.Description
+	train.UpdatedBy = currentUser.GetID()
+	train, err = c.trainMgr.Update(ctx, train, stages)
+	if err != nil {
+		return nil, err
+	}
+	return ofTrainModel(train, stages), nil
+}
+
+func (c *controller) DeleteTrain(ctx context.Context, id uint) error {
+	const op = "release train controller: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.trainMgr.Get(ctx, id); err != nil {
+		return err
+	}
+	running, err := c.getRunningRun(ctx, id)
+	if err != nil {
+		return err
+	}
+	if running != nil {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"release train is running by run %d, cancel it first", running.ID)
+	}
+	return c.trainMgr.Delete(ctx, id)
+}
+
+func (c *controller) StartRun(ctx context.Context, trainID uint, request *StartRunRequest) (*Run, error) {
+	const op = "release train controller: start run"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	train, err := c.trainMgr.Get(ctx, trainID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Action != prmodels.ActionDeploy && request.Action != prmodels.ActionBuildDeploy {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "unsupported action: %s", request.Action)
+	}
+	running, err := c.getRunningRun(ctx, trainID)
+	if err != nil {
+		return nil, err
+	}
+	if running != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "release train is already running by run %d", running.ID)
+	}
+	stages, err := c.trainMgr.ListStages(ctx, trainID)
+	if err != nil {
+		return nil, err
+	}
+	if len(stages) == 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "release train has no stages")
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// the stages are deployed as the creator of run, who must be allowed to deploy all of the clusters
+	specs := make([]models.StageSpec, 0, len(stages))
+	for _, stage := range stages {
+		specs = append(specs, stage.StageSpec)
+	}
+	if err := c.checkDeployPermission(ctx, train.ApplicationID, specs, request.Action); err != nil {
+		return nil, err
+	}
+
+	gitRefType, gitRef := request.gitRef()
+	run := &models.Run{
+		ReleaseTrainID: train.ID,
+		ApplicationID:  train.ApplicationID,
+		Title:          request.Title,
+		Description:    request.Description,
+		Action:         request.Action,
+		ImageTag:       request.ImageTag,
+		GitRefType:     gitRefType,
+		GitRef:         gitRef,
+		Status:         models.RunStatusRunning,
+		CreatedBy:      currentUser.GetID(),
+		UpdatedBy:      currentUser.GetID(),
+	}
+	stageRuns := make([]*models.StageRun, 0, len(stages))
+	for _, stage := range stages {
+		stageRuns = append(stageRuns, &models.StageRun{
+			Sequence:  stage.Sequence,
+			StageSpec: stage.StageSpec,
+			Status:    models.StageStatusPending,
+		})
+	}
+	run, err = c.trainMgr.CreateRun(ctx, run, stageRuns)
+	if err != nil {
+		return nil, err
+	}
+	return ofRunModel(run, stageRuns), nil
+}
+
+func (c *controller) ListRuns(ctx context.Context, trainID uint, query *q.Query) ([]*Run, int64, error) {
+	const op = "release train controller: list runs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	runs, total, err := c.trainMgr.ListRuns(ctx, trainID, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	result := make([]*Run, 0, len(runs))
+	for _, run := range runs {
+		stageRuns, err := c.trainMgr.ListStageRuns(ctx, run.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		result = append(result, ofRunModel(run, stageRuns))
+	}
+	return result, total, nil
+}
+
+func (c *controller) GetRun(ctx context.Context, runID uint) (*Run, error) {
+	const op = "release train controller: get run"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	run, err := c.trainMgr.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	stageRuns, err := c.trainMgr.ListStageRuns(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	return ofRunModel(run, stageRuns), nil
+}
+
+func (c *controller) ApproveRun(ctx context.Context, runID uint) error {
+	const op = "release train controller: approve run"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	run, stageRun, err := c.getCurrentStage(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if !stageRun.RequireApproval || stageRun.ApprovedBy != 0 ||
+		(stageRun.Status != models.StageStatusPending && stageRun.Status != models.StageStatusWaitingApproval) {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"stage %s of run %d is not waiting for approval", stageRun.Name, run.ID)
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if currentUser.GetID() == run.CreatedBy {
+		return perror.Wrapf(herrors.ErrForbidden, "run %d cannot be approved by its creator", run.ID)
+	}
+	stageRun.ApprovedBy = currentUser.GetID()
+	stageRun.Status = models.StageStatusPending
+	return c.trainMgr.UpdateStageRun(ctx, stageRun)
+}
+
+func (c *controller) CancelRun(ctx context.Context, runID uint) error {
+	const op = "release train controller: cancel run"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	run, stageRun, err := c.getCurrentStage(ctx, runID)
+	if err != nil {
+		return err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, pipelinerunID := range models.ParseIDs(stageRun.PipelinerunIDs) {
+		pr, err := c.prMgr.PipelineRun.GetByID(ctx, pipelinerunID)
+		if err != nil {
+			return err
+		}
+		if pr.Status != string(prmodels.StatusPending) && pr.Status != string(prmodels.StatusReady) {
+			continue
+		}
+		if err := c.prMgr.PipelineRun.UpdateStatusByID(ctx, pr.ID, prmodels.StatusCancelled); err != nil {
+			return err
+		}
+		c.eventSvc.CreateEventIgnoreError(ctx, common.ResourcePipelinerun, pr.ID,
+			eventmodels.PipelinerunCancelled, nil)
+	}
+
+	now := time.Now()
+	stageRun.Status = models.StageStatusCancelled
+	stageRun.FinishedAt = &now
+	if err := c.trainMgr.UpdateStageRun(ctx, stageRun); err != nil {
+		return err
+	}
+	run.Status = models.RunStatusCancelled
+	run.Message = "cancelled by " + currentUser.GetName()
+	run.UpdatedBy = currentUser.GetID()
+	return c.trainMgr.UpdateRun(ctx, run)
+}
+
+// getCurrentStage returns the running run and its stage in progress
+func (c *controller) getCurrentStage(ctx context.Context, runID uint) (*models.Run, *models.StageRun, error) {
+	run, err := c.trainMgr.GetRun(ctx, runID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if run.Status != models.RunStatusRunning {
+		return nil, nil, perror.Wrapf(herrors.ErrParamInvalid, "run %d is %s", run.ID, run.Status)
+	}
+	stageRuns, err := c.trainMgr.ListStageRuns(ctx, runID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if run.CurrentStage >= len(stageRuns) {
+		return nil, nil, perror.Wrapf(herrors.ErrParamInvalid, "stage %d of run %d not found",
+			run.CurrentStage, run.ID)
+	}
+	return run, stageRuns[run.CurrentStage], nil
+}
+
+func (c *controller) getRunningRun(ctx context.Context, trainID uint) (*models.Run, error) {
+	runs, _, err := c.trainMgr.ListRuns(ctx, trainID, &q.Query{PageNumber: 1, PageSize: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) > 0 && runs[0].Status == models.RunStatusRunning {
+		return runs[0], nil
+	}
+	return nil, nil
+}
+
+// validateTrain validates the request, and the clusters and environments of stages must belong to the application
+func (c *controller) validateTrain(ctx context.Context, applicationID uint,
+	request *CreateTrainRequest) ([]*models.Stage, error) {
+	if request.Name == "" || len(request.Name) > _maxNameLength {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"name must not be empty and no longer than %d characters", _maxNameLength)
+	}
+	if len(request.Stages) == 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "stages must not be empty")
+	}
+	stages := make([]*models.Stage, 0, len(request.Stages))
+	for i, stage := range request.Stages {
+		if stage == nil || stage.Name == "" {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "name of stage %d must not be empty", i)
+		}
+		if (stage.Environment == "") == (len(stage.Clusters) == 0) {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"stage %s must specify either environment or clusters", stage.Name)
+		}
+		if stage.Environment != "" {
+			if _, err := c.envMgr.GetByName(ctx, stage.Environment); err != nil {
+				return nil, err
+			}
+		}
+		for _, clusterID := range stage.Clusters {
+			cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+			if err != nil {
+				return nil, err
+			}
+			if cluster.ApplicationID != applicationID {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid,
+					"cluster %s does not belong to the application", cluster.Name)
+			}
+		}
+		stages = append(stages, &models.Stage{StageSpec: stage.toSpec()})
+	}
+	specs := make([]models.StageSpec, 0, len(stages))
+	for _, stage := range stages {
+		specs = append(specs, stage.StageSpec)
+	}
+	if err := c.checkDeployPermission(ctx, applicationID, specs, prmodels.ActionDeploy); err != nil {
+		return nil, err
+	}
+	return stages, nil
+}
+
+// checkDeployPermission checks the current user is allowed to do the action on every cluster of the stages
+func (c *controller) checkDeployPermission(ctx context.Context, applicationID uint,
+	specs []models.StageSpec, action string) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	for _, spec := range specs {
+		clusters, err := c.listClusters(ctx, applicationID, spec)
+		if err != nil {
+			return err
+		}
+		for _, cluster := range clusters {
+			decision, reason, err := c.authorizer.Authorize(ctx, auth.AttributesRecord{
+				User:            currentUser,
+				Verb:            "create",
+				APIGroup:        common.GroupCore,
+				Resource:        common.ResourceCluster,
+				SubResource:     action,
+				Name:            strconv.Itoa(int(cluster.ID)),
+				Scope:           fmt.Sprintf("%s/%s", cluster.EnvironmentName, cluster.RegionName),
+				ResourceRequest: true,
+			})
+			if err != nil {
+				return err
+			}
+			if decision != auth.DecisionAllow {
+				return perror.Wrapf(herrors.ErrForbidden,
+					"%s of cluster %s is not allowed: %s", action, cluster.Name, reason)
+			}
+		}
+	}
+	return nil
+}
+
+// listClusters returns the clusters of stage, they are the clusters of application in the environment if not specified
+func (c *controller) listClusters(ctx context.Context, applicationID uint,
+	spec models.StageSpec) ([]*clustermodels.Cluster, error) {
+	if spec.Clusters != "" {
+		clusters := make([]*clustermodels.Cluster, 0)
+		for _, clusterID := range models.ParseIDs(spec.Clusters) {
+			cluster, err := c.clusterMgr.GetByID(ctx, clusterID)
+			if err != nil {
+				return nil, err
+			}
+			clusters = append(clusters, cluster)
+		}
+		return clusters, nil
+	}
+	_, clustersWithRegion, err := c.clusterMgr.List(ctx, &q.Query{
+		Keywords: q.KeyWords{
+			common.ParamApplicationID:      applicationID,
+			common.ClusterQueryEnvironment: spec.Environment,
+		},
+		WithoutPagination: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	clusters := make([]*clustermodels.Cluster, 0, len(clustersWithRegion))
+	for _, cluster := range clustersWithRegion {
+		clusters = append(clusters, cluster.Cluster)
+	}
+	return clusters, nil
+}
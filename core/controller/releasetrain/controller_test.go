@@ -0,0 +1,195 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasetrain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	"github.com/horizoncd/horizon/pkg/auth"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/rbac"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/releasetrain/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
+)
+
+// fakeAuthorizer denies the requests to the resources in denied
+type fakeAuthorizer struct {
+	rbac.Authorizer
+	denied map[string]bool
+}
+
+func (f *fakeAuthorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.Decision, string, error) {
+	if f.denied[attr.GetName()] {
+		return auth.DecisionDeny, "denied", nil
+	}
+	return auth.DecisionAllow, "", nil
+}
+
+func TestController(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&appmodels.Application{}, &clustermodels.Cluster{}, &envmodels.Environment{},
+		&prmodels.Pipelinerun{}, &eventmodels.Event{}, &models.ReleaseTrain{}, &models.Stage{},
+		&models.Run{}, &models.StageRun{}, &templatemodels.Template{}, &regionmodels.Region{}))
+	assert.Nil(t, db.Create(&regionmodels.Region{Name: "hz"}).Error)
+	assert.Nil(t, db.Create(&appmodels.Application{Model: global.Model{ID: 1}, Name: "app"}).Error)
+	assert.Nil(t, db.Create(&clustermodels.Cluster{Model: global.Model{ID: 1},
+		ApplicationID: 1, Name: "app-online", EnvironmentName: "online", RegionName: "hz"}).Error)
+	assert.Nil(t, db.Create(&clustermodels.Cluster{Model: global.Model{ID: 2},
+		ApplicationID: 2, Name: "other-online", EnvironmentName: "online", RegionName: "hz"}).Error)
+	assert.Nil(t, db.Create(&clustermodels.Cluster{Model: global.Model{ID: 3},
+		ApplicationID: 1, Name: "app-denied", EnvironmentName: "online", RegionName: "hz"}).Error)
+	assert.Nil(t, db.Create(&envmodels.Environment{Name: "test"}).Error)
+	assert.Nil(t, db.Create(&envmodels.Environment{Name: "online"}).Error)
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	mgr := managerparam.InitManager(db)
+	ctrl := NewController(&fakeAuthorizer{denied: map[string]bool{"3": true}},
+		&param.Param{Manager: mgr, EventSvc: eventservice.New(mgr)})
+
+	request := &CreateTrainRequest{
+		Name: "release",
+		Stages: []*Stage{
+			{Name: "test", Environment: "test"},
+			{Name: "online", Clusters: []uint{1}, RequireApproval: true, SoakTime: 600},
+		},
+	}
+	train, err := ctrl.CreateTrain(ctx, 1, request)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), train.ApplicationID)
+	assert.Equal(t, request.Stages, train.Stages)
+
+	// invalid requests
+	_, err = ctrl.CreateTrain(ctx, 2, request)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	for _, invalid := range []*CreateTrainRequest{
+		{Name: "", Stages: request.Stages},
+		{Name: "empty"},
+		{Name: "both", Stages: []*Stage{{Name: "a", Environment: "test", Clusters: []uint{1}}}},
+		{Name: "neither", Stages: []*Stage{{Name: "a"}}},
+		{Name: "other", Stages: []*Stage{{Name: "a", Clusters: []uint{2}}}},
+	} {
+		_, err = ctrl.CreateTrain(ctx, 1, invalid)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err), invalid.Name)
+	}
+	_, err = ctrl.CreateTrain(ctx, 1, &CreateTrainRequest{Name: "env",
+		Stages: []*Stage{{Name: "a", Environment: "nowhere"}}})
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	// deploy permission is required on every cluster
+	_, err = ctrl.CreateTrain(ctx, 1, &CreateTrainRequest{Name: "denied",
+		Stages: []*Stage{{Name: "a", Clusters: []uint{3}}}})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	_, err = ctrl.CreateTrain(ctx, 1, &CreateTrainRequest{Name: "denied",
+		Stages: []*Stage{{Name: "a", Environment: "online"}}})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+
+	request.Stages = request.Stages[1:]
+	request.Description = "online only"
+	train, err = ctrl.UpdateTrain(ctx, train.ID, request)
+	assert.Nil(t, err)
+	assert.Equal(t, "online only", train.Description)
+	assert.Equal(t, 1, len(train.Stages))
+	trains, total, err := ctrl.ListTrains(ctx, 1, &q.Query{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, train.Stages, trains[0].Stages)
+
+	// runs
+	_, err = ctrl.StartRun(ctx, train.ID, &StartRunRequest{Action: prmodels.ActionRollback})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	run, err := ctrl.StartRun(ctx, train.ID, &StartRunRequest{
+		Title:  "v1",
+		Action: prmodels.ActionBuildDeploy,
+		Git:    &Git{Tag: "v1"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, models.RunStatusRunning, run.Status)
+	assert.Equal(t, &Git{Tag: "v1"}, run.Git)
+	assert.Equal(t, models.StageStatusPending, run.Stages[0].Status)
+	_, err = ctrl.StartRun(ctx, train.ID, &StartRunRequest{Action: prmodels.ActionDeploy})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	err = ctrl.DeleteTrain(ctx, train.ID)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// the creator of run cannot approve it
+	err = ctrl.ApproveRun(ctx, run.ID)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	approverCtx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Jerry",
+		ID:   2,
+	})
+	err = ctrl.ApproveRun(approverCtx, run.ID)
+	assert.Nil(t, err)
+	err = ctrl.ApproveRun(approverCtx, run.ID)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	pr, err := mgr.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: 1,
+		Status:    string(prmodels.StatusReady),
+	})
+	assert.Nil(t, err)
+	stageRuns, err := mgr.ReleaseTrainMgr.ListStageRuns(ctx, run.ID)
+	assert.Nil(t, err)
+	stageRuns[0].Status = models.StageStatusRunning
+	stageRuns[0].PipelinerunIDs = models.FormatIDs([]uint{pr.ID})
+	assert.Nil(t, mgr.ReleaseTrainMgr.UpdateStageRun(ctx, stageRuns[0]))
+
+	err = ctrl.CancelRun(ctx, run.ID)
+	assert.Nil(t, err)
+	run, err = ctrl.GetRun(ctx, run.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.RunStatusCancelled, run.Status)
+	assert.Equal(t, models.StageStatusCancelled, run.Stages[0].Status)
+	assert.Equal(t, uint(2), run.Stages[0].ApprovedBy)
+	assert.Equal(t, []uint{pr.ID}, run.Stages[0].Pipelineruns)
+	pr, err = mgr.PRMgr.PipelineRun.GetByID(ctx, pr.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusCancelled), pr.Status)
+	err = ctrl.CancelRun(ctx, run.ID)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	runs, total, err := ctrl.ListRuns(ctx, train.ID, &q.Query{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, run.ID, runs[0].ID)
+
+	err = ctrl.DeleteTrain(ctx, train.ID)
+	assert.Nil(t, err)
+	_, err = ctrl.GetTrain(ctx, train.ID)
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
@@ -0,0 +1,181 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasetrain
+
+import (
+	"time"
+
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	"github.com/horizoncd/horizon/pkg/releasetrain/models"
+)
+
+type Stage struct {
+	Name string `json:"name"`
+	// Environment selects all the clusters of application in the environment when Clusters is empty
+	Environment string `json:"environment,omitempty"`
+	Clusters    []uint `json:"clusters,omitempty"`
+	// RequireApproval requires someone to approve before the stage starts
+	RequireApproval bool `json:"requireApproval"`
+	// SoakTime is the seconds to wait after the previous stage succeeded
+	SoakTime uint `json:"soakTime"`
+}
+
+type CreateTrainRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Stages      []*Stage `json:"stages"`
+}
+
+type Train struct {
+	CreateTrainRequest
+	ID            uint      `json:"id"`
+	ApplicationID uint      `json:"applicationID"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+type Git struct {
+	Branch string `json:"branch,omitempty"`
+	Tag    string `json:"tag,omitempty"`
+	Commit string `json:"commit,omitempty"`
+}
+
+type StartRunRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// Action is deploy or builddeploy
+	Action   string `json:"action"`
+	ImageTag string `json:"imageTag,omitempty"`
+	Git      *Git   `json:"git,omitempty"`
+}
+
+type StageRun struct {
+	Stage
+	Status       string     `json:"status"`
+	Pipelineruns []uint     `json:"pipelineruns,omitempty"`
+	ApprovedBy   uint       `json:"approvedBy,omitempty"`
+	StartedAt    *time.Time `json:"startedAt,omitempty"`
+	FinishedAt   *time.Time `json:"finishedAt,omitempty"`
+	Message      string     `json:"message,omitempty"`
+}
+
+type Run struct {
+	StartRunRequest
+	ID             uint        `json:"id"`
+	ReleaseTrainID uint        `json:"releaseTrainID"`
+	ApplicationID  uint        `json:"applicationID"`
+	Status         string      `json:"status"`
+	CurrentStage   int         `json:"currentStage"`
+	Message        string      `json:"message,omitempty"`
+	Stages         []*StageRun `json:"stages"`
+	CreatedBy      uint        `json:"createdBy"`
+	CreatedAt      time.Time   `json:"createdAt"`
+	UpdatedAt      time.Time   `json:"updatedAt"`
+}
+
+func (s *Stage) toSpec() models.StageSpec {
+	return models.StageSpec{
+		Name:            s.Name,
+		Environment:     s.Environment,
+		Clusters:        models.FormatIDs(s.Clusters),
+		RequireApproval: s.RequireApproval,
+		SoakTime:        s.SoakTime,
+	}
+}
+
+func ofStageSpec(spec models.StageSpec) Stage {
+	return Stage{
+		Name:            spec.Name,
+		Environment:     spec.Environment,
+		Clusters:        models.ParseIDs(spec.Clusters),
+		RequireApproval: spec.RequireApproval,
+		SoakTime:        spec.SoakTime,
+	}
+}
+
+func ofTrainModel(train *models.ReleaseTrain, stages []*models.Stage) *Train {
+	result := &Train{
+		CreateTrainRequest: CreateTrainRequest{
+			Name:        train.Name,
+			Description: train.Description,
+			Stages:      make([]*Stage, 0, len(stages)),
+		},
+		ID:            train.ID,
+		ApplicationID: train.ApplicationID,
+		CreatedAt:     train.CreatedAt,
+		UpdatedAt:     train.UpdatedAt,
+	}
+	for _, stage := range stages {
+		s := ofStageSpec(stage.StageSpec)
+		result.Stages = append(result.Stages, &s)
+	}
+	return result
+}
+
+func (r *StartRunRequest) gitRef() (string, string) {
+	if r.Git == nil {
+		return "", ""
+	}
+	switch {
+	case r.Git.Commit != "":
+		return codemodels.GitRefTypeCommit, r.Git.Commit
+	case r.Git.Tag != "":
+		return codemodels.GitRefTypeTag, r.Git.Tag
+	case r.Git.Branch != "":
+		return codemodels.GitRefTypeBranch, r.Git.Branch
+	}
+	return "", ""
+}
+
+func ofRunModel(run *models.Run, stageRuns []*models.StageRun) *Run {
+	result := &Run{
+		StartRunRequest: StartRunRequest{
+			Title:       run.Title,
+			Description: run.Description,
+			Action:      run.Action,
+			ImageTag:    run.ImageTag,
+		},
+		ID:             run.ID,
+		ReleaseTrainID: run.ReleaseTrainID,
+		ApplicationID:  run.ApplicationID,
+		Status:         run.Status,
+		CurrentStage:   run.CurrentStage,
+		Message:        run.Message,
+		Stages:         make([]*StageRun, 0, len(stageRuns)),
+		CreatedBy:      run.CreatedBy,
+		CreatedAt:      run.CreatedAt,
+		UpdatedAt:      run.UpdatedAt,
+	}
+	switch run.GitRefType {
+	case codemodels.GitRefTypeCommit:
+		result.Git = &Git{Commit: run.GitRef}
+	case codemodels.GitRefTypeTag:
+		result.Git = &Git{Tag: run.GitRef}
+	case codemodels.GitRefTypeBranch:
+		result.Git = &Git{Branch: run.GitRef}
+	}
+	for _, stageRun := range stageRuns {
+		result.Stages = append(result.Stages, &StageRun{
+			Stage:        ofStageSpec(stageRun.StageSpec),
+			Status:       stageRun.Status,
+			Pipelineruns: models.ParseIDs(stageRun.PipelinerunIDs),
+			ApprovedBy:   stageRun.ApprovedBy,
+			StartedAt:    stageRun.StartedAt,
+			FinishedAt:   stageRun.FinishedAt,
+			Message:      stageRun.Message,
+		})
+	}
+	return result
+}
@@ -17,23 +17,48 @@ package role
 import (
 	"context"
 	"net/http"
+	"regexp"
 
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/member"
 	"github.com/horizoncd/horizon/pkg/param"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
+	rolemanager "github.com/horizoncd/horizon/pkg/rbac/role/manager"
 	"github.com/horizoncd/horizon/pkg/rbac/types"
 	"github.com/horizoncd/horizon/pkg/util/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
 )
 
+var _roleNameRegex = regexp.MustCompile(`^[a-z][a-z0-9-]{0,63}$`)
+
 type Controller interface {
 	ListRole(ctx context.Context) ([]types.Role, error)
+	// GetRole gets the role stored in database
+	GetRole(ctx context.Context, name string) (*Role, error)
+	// CreateRole creates a role, only available when roles are stored in database
+	CreateRole(ctx context.Context, request *CreateRoleRequest) (*Role, error)
+	// UpdateRole updates a role, only available when roles are stored in database
+	UpdateRole(ctx context.Context, name string, request *UpdateRoleRequest) (*Role, error)
+	// DeleteRole deletes a role which is neither builtin, default nor bound to members
+	DeleteRole(ctx context.Context, name string) error
 }
 
 func NewController(param *param.Param) Controller {
-	return &controller{roleService: param.RoleService}
+	return &controller{
+		roleService: param.RoleService,
+		roleMgr:     param.RoleMgr,
+		memberMgr:   param.MemberMgr,
+	}
 }
 
 type controller struct {
 	roleService role.Service
+	roleMgr     rolemanager.Manager
+	memberMgr   member.Manager
 }
 
 func (c controller) ListRole(ctx context.Context) ([]types.Role, error) {
@@ -44,3 +69,170 @@ func (c controller) ListRole(ctx context.Context) ([]types.Role, error) {
 	}
 	return roles, nil
 }
+
+func (c controller) GetRole(ctx context.Context, name string) (*Role, error) {
+	const op = "role controller: get role"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.reloadableService(); err != nil {
+		return nil, err
+	}
+	roleModel, err := c.roleMgr.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return ofRoleModel(roleModel)
+}
+
+func (c controller) CreateRole(ctx context.Context, request *CreateRoleRequest) (*Role, error) {
+	const op = "role controller: create role"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	roleService, err := c.reloadableService()
+	if err != nil {
+		return nil, err
+	}
+	currentUser, err := c.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !_roleNameRegex.MatchString(request.Name) {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"invalid name, it must match regex %s", _roleNameRegex.String())
+	}
+	if err := validateRules(request.Rules); err != nil {
+		return nil, err
+	}
+	if _, err := c.roleMgr.Get(ctx, request.Name); err == nil {
+		return nil, perror.Wrapf(herrors.ErrNameConflict, "role %s already exists", request.Name)
+	} else if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+		return nil, err
+	}
+
+	roleModel, err := request.toModel()
+	if err != nil {
+		return nil, err
+	}
+	roleModel.CreatedBy = currentUser.GetID()
+	roleModel.UpdatedBy = currentUser.GetID()
+	roleModel, err = c.roleMgr.Create(ctx, roleModel)
+	if err != nil {
+		return nil, err
+	}
+	c.reload(ctx, roleService)
+	return ofRoleModel(roleModel)
+}
+
+func (c controller) UpdateRole(ctx context.Context, name string, request *UpdateRoleRequest) (*Role, error) {
+	const op = "role controller: update role"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	roleService, err := c.reloadableService()
+	if err != nil {
+		return nil, err
+	}
+	currentUser, err := c.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roleModel, err := c.roleMgr.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if roleModel.IsDefault && request.Default != nil && !*request.Default {
+		return nil, perror.Wrap(herrors.ErrParamInvalid,
+			"there must be a default role, set another role as default instead")
+	}
+	if request.Rules != nil {
+		if err := validateRules(*request.Rules); err != nil {
+			return nil, err
+		}
+	}
+
+	roleModel, err = request.toModel(roleModel)
+	if err != nil {
+		return nil, err
+	}
+	roleModel.UpdatedBy = currentUser.GetID()
+	roleModel, err = c.roleMgr.Update(ctx, roleModel)
+	if err != nil {
+		return nil, err
+	}
+	c.reload(ctx, roleService)
+	return ofRoleModel(roleModel)
+}
+
+func (c controller) DeleteRole(ctx context.Context, name string) error {
+	const op = "role controller: delete role"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	roleService, err := c.reloadableService()
+	if err != nil {
+		return err
+	}
+	if _, err := c.requireAdmin(ctx); err != nil {
+		return err
+	}
+	roleModel, err := c.roleMgr.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	switch name {
+	case role.Owner, role.Maintainer, role.PE, role.Guest:
+		return perror.Wrapf(herrors.ErrParamInvalid, "builtin role %s can not be deleted", name)
+	}
+	if roleModel.IsDefault {
+		return perror.Wrap(herrors.ErrParamInvalid, "default role can not be deleted")
+	}
+	count, err := c.memberMgr.CountByRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return perror.Wrapf(herrors.ErrParamInvalid, "role %s is still bound to %d members", name, count)
+	}
+
+	if err := c.roleMgr.Delete(ctx, name); err != nil {
+		return err
+	}
+	c.reload(ctx, roleService)
+	return nil
+}
+
+// reloadableService returns the role service if roles are stored in database
+func (c controller) reloadableService() (role.ReloadableService, error) {
+	roleService, ok := c.roleService.(role.ReloadableService)
+	if !ok {
+		return nil, perror.Wrap(herrors.ErrParamInvalid,
+			"roles are loaded from file, they can not be changed through API")
+	}
+	return roleService, nil
+}
+
+func (c controller) requireAdmin(ctx context.Context) (userauth.User, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !currentUser.IsAdmin() {
+		return nil, perror.Wrap(herrors.ErrForbidden, "only admin can manage roles")
+	}
+	return currentUser, nil
+}
+
+// reload makes the change take effect in the current replica at once,
+// the other replicas will reload the roles periodically
+func (c controller) reload(ctx context.Context, roleService role.ReloadableService) {
+	if err := roleService.Reload(ctx); err != nil {
+		log.Errorf(ctx, "failed to reload roles, err: %+v", err)
+	}
+}
+
+func validateRules(rules []types.PolicyRule) error {
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return perror.Wrapf(herrors.ErrParamInvalid, "invalid rule %d: %s", i, err.Error())
+		}
+	}
+	return nil
+}
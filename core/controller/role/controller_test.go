@@ -0,0 +1,118 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/rbac/role/models"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+)
+
+func TestController(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&models.Role{}, &membermodels.Member{}))
+	mgr := managerparam.InitManager(db)
+
+	rules := []types.PolicyRule{{
+		Verbs:     []string{"get"},
+		APIGroups: []string{"core"},
+		Resources: []string{"clusters"},
+		Scopes:    []string{"*"},
+	}}
+	config := roleconfig.Config{
+		RolePriorityRankDesc: []string{role.Owner, role.Guest},
+		DefaultRole:          role.Guest,
+		Roles:                []types.Role{{Name: role.Owner, PolicyRules: rules}, {Name: role.Guest, PolicyRules: rules}},
+	}
+	adminCtx := common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "admin", ID: 1, Admin: true})
+	userCtx := common.WithContext(context.Background(), &userauth.DefaultInfo{Name: "tony", ID: 2})
+
+	// roles loaded from file can not be changed
+	fileRoleService, err := role.NewFileRoleFrom2(adminCtx, config)
+	assert.Nil(t, err)
+	ctrl := NewController(&param.Param{Manager: mgr, RoleService: fileRoleService})
+	_, err = ctrl.CreateRole(adminCtx, &CreateRoleRequest{Name: "deployer", Rules: rules})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	roleService, err := role.NewDBRoleService(adminCtx, mgr.RoleMgr, config, roleconfig.StoreConfig{Database: true})
+	assert.Nil(t, err)
+	ctrl = NewController(&param.Param{Manager: mgr, RoleService: roleService})
+
+	request := &CreateRoleRequest{Name: "deployer", Desc: "deploy only", Rules: rules, Rank: 1}
+	_, err = ctrl.CreateRole(userCtx, request)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	_, err = ctrl.CreateRole(adminCtx, &CreateRoleRequest{Name: "Deployer", Rules: rules})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = ctrl.CreateRole(adminCtx, &CreateRoleRequest{Name: "deployer",
+		Rules: []types.PolicyRule{{Verbs: []string{"get"}}}})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	created, err := ctrl.CreateRole(adminCtx, request)
+	assert.Nil(t, err)
+	assert.Equal(t, *request, created.CreateRoleRequest)
+	_, err = ctrl.CreateRole(adminCtx, request)
+	assert.Equal(t, herrors.ErrNameConflict, perror.Cause(err))
+
+	// the role takes effect at once, roles of the same rank keep their creation order
+	roles, err := ctrl.ListRole(adminCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{role.Owner, role.Guest, "deployer"},
+		[]string{roles[0].Name, roles[1].Name, roles[2].Name})
+
+	// there is always a default role
+	isDefault := false
+	_, err = ctrl.UpdateRole(adminCtx, role.Guest, &UpdateRoleRequest{Default: &isDefault})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	isDefault = true
+	updated, err := ctrl.UpdateRole(adminCtx, "deployer", &UpdateRoleRequest{Default: &isDefault})
+	assert.Nil(t, err)
+	assert.True(t, updated.Default)
+	assert.Equal(t, "deploy only", updated.Desc)
+	assert.Equal(t, "deployer", roleService.GetDefaultRole(adminCtx).Name)
+	guest, err := ctrl.GetRole(adminCtx, role.Guest)
+	assert.Nil(t, err)
+	assert.False(t, guest.Default)
+
+	err = ctrl.DeleteRole(adminCtx, "deployer")
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	err = ctrl.DeleteRole(adminCtx, role.Owner)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = ctrl.UpdateRole(adminCtx, role.Guest, &UpdateRoleRequest{Default: &isDefault})
+	assert.Nil(t, err)
+
+	// roles bound to members can not be deleted
+	assert.Nil(t, db.Create(&membermodels.Member{ResourceType: membermodels.TypeGroup, ResourceID: 1,
+		Role: "deployer", MemberNameID: 2}).Error)
+	err = ctrl.DeleteRole(adminCtx, "deployer")
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	assert.Nil(t, db.Where("role = ?", "deployer").Delete(&membermodels.Member{}).Error)
+	assert.Nil(t, ctrl.DeleteRole(adminCtx, "deployer"))
+	_, err = roleService.GetRole(adminCtx, "deployer")
+	assert.Equal(t, role.ErrorRoleNotFound, err)
+}
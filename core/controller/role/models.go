@@ -0,0 +1,100 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/rbac/role/models"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+)
+
+type CreateRoleRequest struct {
+	Name  string             `json:"name"`
+	Desc  string             `json:"desc"`
+	Rules []types.PolicyRule `json:"rules"`
+	// Rank decides the priority of the role, the smaller the higher
+	Rank int `json:"rank"`
+	// Default is the role of users who are not members of the resource
+	Default bool `json:"default"`
+}
+
+type UpdateRoleRequest struct {
+	Desc    *string             `json:"desc"`
+	Rules   *[]types.PolicyRule `json:"rules"`
+	Rank    *int                `json:"rank"`
+	Default *bool               `json:"default"`
+}
+
+type Role struct {
+	CreateRoleRequest
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (r *CreateRoleRequest) toModel() (*models.Role, error) {
+	rules, err := json.Marshal(r.Rules)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Role{
+		Name:        r.Name,
+		Description: r.Desc,
+		Rules:       string(rules),
+		Rank:        r.Rank,
+		IsDefault:   r.Default,
+	}, nil
+}
+
+func (r *UpdateRoleRequest) toModel(role *models.Role) (*models.Role, error) {
+	if r.Desc != nil {
+		role.Description = *r.Desc
+	}
+	if r.Rules != nil {
+		rules, err := json.Marshal(*r.Rules)
+		if err != nil {
+			return nil, err
+		}
+		role.Rules = string(rules)
+	}
+	if r.Rank != nil {
+		role.Rank = *r.Rank
+	}
+	if r.Default != nil {
+		role.IsDefault = *r.Default
+	}
+	return role, nil
+}
+
+func ofRoleModel(role *models.Role) (*Role, error) {
+	var rules []types.PolicyRule
+	if role.Rules != "" {
+		if err := json.Unmarshal([]byte(role.Rules), &rules); err != nil {
+			return nil, err
+		}
+	}
+	return &Role{
+		CreateRoleRequest: CreateRoleRequest{
+			Name:    role.Name,
+			Desc:    role.Description,
+			Rules:   rules,
+			Rank:    role.Rank,
+			Default: role.IsDefault,
+		},
+		CreatedAt: role.CreatedAt,
+		UpdatedAt: role.UpdatedAt,
+	}, nil
+}
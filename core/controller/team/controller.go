@@ -0,0 +1,324 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package team
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/param"
+	teammanager "github.com/horizoncd/horizon/pkg/team/manager"
+	"github.com/horizoncd/horizon/pkg/team/models"
+	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _maxNameLength = 64
+
+type Controller interface {
+	// CreateTeam creates a team, and the current user becomes its owner
+	CreateTeam(ctx context.Context, request *CreateTeamRequest) (*Team, error)
+	// ListTeams lists the teams, users who are not admins only list the teams they belong to
+	ListTeams(ctx context.Context, query *q.Query) ([]*Team, int64, error)
+	GetTeam(ctx context.Context, id uint) (*Team, error)
+	UpdateTeam(ctx context.Context, id uint, request *UpdateTeamRequest) (*Team, error)
+	// DeleteTeam deletes the team, and the members that the team is bound to as well
+	DeleteTeam(ctx context.Context, id uint) error
+	ListMembers(ctx context.Context, id uint) ([]*Member, error)
+	// PostMember adds the user to the team, or updates the user's role if the user is already in it
+	PostMember(ctx context.Context, id uint, request *PostMemberRequest) (*Member, error)
+	// RemoveMember removes the user from the team, the users who are not owners could only leave the team
+	RemoveMember(ctx context.Context, id uint, userID uint) error
+}
+
+type controller struct {
+	teamMgr teammanager.Manager
+	userMgr usermanager.Manager
+}
+
+func NewController(param *param.Param) Controller {
+	return &controller{
+		teamMgr: param.TeamMgr,
+		userMgr: param.UserMgr,
+	}
+}
+
+func (c *controller) CreateTeam(ctx context.Context, request *CreateTeamRequest) (*Team, error) {
+	const op = "team controller: create"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	team := &models.Team{
+		Name:        request.Name,
+		Description: request.Description,
+		CreatedBy:   currentUser.GetID(),
+		UpdatedBy:   currentUser.GetID(),
+	}
+	if err := c.validateTeam(ctx, team); err != nil {
+		return nil, err
+	}
+	owner := &models.TeamMember{
+		UserID:    currentUser.GetID(),
+		Role:      models.RoleOwner,
+		CreatedBy: currentUser.GetID(),
+		UpdatedBy: currentUser.GetID(),
+	}
+	team, err = c.teamMgr.Create(ctx, team, owner)
+	if err != nil {
+		return nil, err
+	}
+	return ofTeamModel(team), nil
+}
+
+func (c *controller) ListTeams(ctx context.Context, query *q.Query) ([]*Team, int64, error) {
+	const op = "team controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !currentUser.IsAdmin() {
+		if query == nil {
+			query = q.New(q.KeyWords{})
+		}
+		if query.Keywords == nil {
+			query.Keywords = q.KeyWords{}
+		}
+		query.Keywords[common.TeamQueryByUser] = currentUser.GetID()
+	}
+	teams, total, err := c.teamMgr.List(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	result := make([]*Team, 0, len(teams))
+	for _, team := range teams {
+		result = append(result, ofTeamModel(team))
+	}
+	return result, total, nil
+}
+
+func (c *controller) GetTeam(ctx context.Context, id uint) (*Team, error) {
+	const op = "team controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	team, err := c.teamMgr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofTeamModel(team), nil
+}
+
+func (c *controller) UpdateTeam(ctx context.Context, id uint, request *UpdateTeamRequest) (*Team, error) {
+	const op = "team controller: update"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	team, err := c.teamMgr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.requireOwner(ctx, id); err != nil {
+		return nil, err
+	}
+	team = request.toModel(team)
+	if err := c.validateTeam(ctx, team); err != nil {
+		return nil, err
+	}
+	team.UpdatedBy = currentUser.GetID()
+
+	team, err = c.teamMgr.Update(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+	return ofTeamModel(team), nil
+}
+
+func (c *controller) DeleteTeam(ctx context.Context, id uint) error {
+	const op = "team controller: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.teamMgr.Get(ctx, id); err != nil {
+		return err
+	}
+	if err := c.requireOwner(ctx, id); err != nil {
+		return err
+	}
+	return c.teamMgr.Delete(ctx, id)
+}
+
+func (c *controller) ListMembers(ctx context.Context, id uint) ([]*Member, error) {
+	const op = "team controller: list members"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.teamMgr.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	members, err := c.teamMgr.ListMembers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]uint, 0, len(members))
+	for _, member := range members {
+		userIDs = append(userIDs, member.UserID)
+	}
+	users, err := c.userMgr.GetUserByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	userMap := make(map[uint]*usermodels.User, len(users))
+	for _, user := range users {
+		userMap[user.ID] = user
+	}
+	result := make([]*Member, 0, len(members))
+	for _, member := range members {
+		result = append(result, ofMemberModel(member, userMap[member.UserID]))
+	}
+	return result, nil
+}
+
+func (c *controller) PostMember(ctx context.Context, id uint, request *PostMemberRequest) (*Member, error) {
+	const op = "team controller: post member"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.teamMgr.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := c.requireOwner(ctx, id); err != nil {
+		return nil, err
+	}
+	if request.Role != models.RoleOwner && request.Role != models.RoleMember {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid role: %s", request.Role)
+	}
+	user, err := c.userMgr.GetUserByID(ctx, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.UserType != usermodels.UserTypeCommon {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "user %d is not a common user", user.ID)
+	}
+	if request.Role != models.RoleOwner {
+		if err := c.requireAnotherOwner(ctx, id, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	member, err := c.teamMgr.UpsertMember(ctx, &models.TeamMember{
+		TeamID:    id,
+		UserID:    user.ID,
+		Role:      request.Role,
+		CreatedBy: currentUser.GetID(),
+		UpdatedBy: currentUser.GetID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ofMemberModel(member, user), nil
+}
+
+func (c *controller) RemoveMember(ctx context.Context, id uint, userID uint) error {
+	const op = "team controller: remove member"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if _, err := c.teamMgr.Get(ctx, id); err != nil {
+		return err
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	// the users in the team are authorized to remove members, but only owners could remove the others
+	if currentUser.GetID() != userID {
+		if err := c.requireOwner(ctx, id); err != nil {
+			return err
+		}
+	}
+	if _, err := c.teamMgr.GetMember(ctx, id, userID); err != nil {
+		return err
+	}
+	if err := c.requireAnotherOwner(ctx, id, userID); err != nil {
+		return err
+	}
+	return c.teamMgr.DeleteMember(ctx, id, userID)
+}
+
+// requireOwner checks the current user is an admin or an owner of the team,
+// it is checked in addition to the team rules of roles in rbac
+func (c *controller) requireOwner(ctx context.Context, id uint) error {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if currentUser.IsAdmin() {
+		return nil
+	}
+	member, err := c.teamMgr.GetMember(ctx, id, currentUser.GetID())
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return perror.Wrap(herrors.ErrForbidden, "only owners of the team can manage it")
+		}
+		return err
+	}
+	if member.Role != models.RoleOwner {
+		return perror.Wrap(herrors.ErrForbidden, "only owners of the team can manage it")
+	}
+	return nil
+}
+
+// requireAnotherOwner makes sure the team still has an owner after the user is no longer the owner
+func (c *controller) requireAnotherOwner(ctx context.Context, id uint, userID uint) error {
+	members, err := c.teamMgr.ListMembers(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if member.Role == models.RoleOwner && member.UserID != userID {
+			return nil
+		}
+	}
+	return perror.Wrap(herrors.ErrParamInvalid, "a team must have at least one owner")
+}
+
+func (c *controller) validateTeam(ctx context.Context, team *models.Team) error {
+	if team.Name == "" || len(team.Name) > _maxNameLength {
+		return perror.Wrapf(herrors.ErrParamInvalid,
+			"name must not be empty and no longer than %d characters", _maxNameLength)
+	}
+	existing, err := c.teamMgr.GetByName(ctx, team.Name)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != team.ID {
+		return perror.Wrapf(herrors.ErrNameConflict, "team %s already exists", team.Name)
+	}
+	return nil
+}
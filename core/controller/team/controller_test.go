@@ -0,0 +1,139 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package team
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/team/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+func TestController(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&usermodels.User{}, &membermodels.Member{},
+		&models.Team{}, &models.TeamMember{}))
+	mgr := managerparam.InitManager(db)
+	users := []*usermodels.User{{Name: "tony"}, {Name: "jerry"}, {Name: "robot", UserType: usermodels.UserTypeRobot}}
+	for _, user := range users {
+		_, err := mgr.UserMgr.Create(context.Background(), user)
+		assert.Nil(t, err)
+	}
+	tony, jerry, robot := users[0], users[1], users[2]
+	userCtx := func(user *usermodels.User) context.Context {
+		return common.WithContext(context.Background(), &userauth.DefaultInfo{
+			Name: user.Name,
+			ID:   user.ID,
+		})
+	}
+	ctrl := NewController(&param.Param{Manager: mgr})
+
+	team, err := ctrl.CreateTeam(userCtx(tony), &CreateTeamRequest{Name: "sre", Description: "sre team"})
+	assert.Nil(t, err)
+	assert.Equal(t, "sre", team.Name)
+	assert.Equal(t, tony.ID, team.CreatedBy)
+
+	_, err = ctrl.CreateTeam(userCtx(jerry), &CreateTeamRequest{Name: "sre"})
+	assert.Equal(t, herrors.ErrNameConflict, perror.Cause(err))
+	_, err = ctrl.CreateTeam(userCtx(jerry), &CreateTeamRequest{})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	members, err := ctrl.ListMembers(userCtx(jerry), team.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(members))
+	assert.Equal(t, tony.Name, members[0].UserName)
+	assert.Equal(t, models.RoleOwner, members[0].Role)
+
+	// only owners manage the team
+	_, err = ctrl.PostMember(userCtx(jerry), team.ID, &PostMemberRequest{UserID: jerry.ID, Role: models.RoleMember})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	_, err = ctrl.PostMember(userCtx(tony), team.ID, &PostMemberRequest{UserID: robot.ID, Role: models.RoleMember})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	_, err = ctrl.PostMember(userCtx(tony), team.ID, &PostMemberRequest{UserID: jerry.ID, Role: "admin"})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	member, err := ctrl.PostMember(userCtx(tony), team.ID, &PostMemberRequest{UserID: jerry.ID, Role: models.RoleMember})
+	assert.Nil(t, err)
+	assert.Equal(t, jerry.Name, member.UserName)
+
+	// users who are not admins only list the teams they belong to
+	_, err = ctrl.CreateTeam(userCtx(tony), &CreateTeamRequest{Name: "dba"})
+	assert.Nil(t, err)
+	teams, total, err := ctrl.ListTeams(userCtx(jerry), q.New(q.KeyWords{common.TeamQueryByUser: tony.ID}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, team.ID, teams[0].ID)
+	_, total, err = ctrl.ListTeams(userCtx(tony), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	_, total, err = ctrl.ListTeams(common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "admin", ID: 100, Admin: true,
+	}), q.New(q.KeyWords{common.TeamQueryByUser: jerry.ID}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+
+	newName := "ops"
+	_, err = ctrl.UpdateTeam(userCtx(jerry), team.ID, &UpdateTeamRequest{Name: &newName})
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	team, err = ctrl.UpdateTeam(userCtx(tony), team.ID, &UpdateTeamRequest{Name: &newName})
+	assert.Nil(t, err)
+	assert.Equal(t, newName, team.Name)
+	assert.Equal(t, "sre team", team.Description)
+
+	// a team must keep an owner
+	_, err = ctrl.PostMember(userCtx(tony), team.ID, &PostMemberRequest{UserID: tony.ID, Role: models.RoleMember})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	err = ctrl.RemoveMember(userCtx(tony), team.ID, tony.ID)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	member, err = ctrl.PostMember(userCtx(tony), team.ID, &PostMemberRequest{UserID: jerry.ID, Role: models.RoleOwner})
+	assert.Nil(t, err)
+	assert.Equal(t, models.RoleOwner, member.Role)
+	assert.Nil(t, ctrl.RemoveMember(userCtx(tony), team.ID, tony.ID))
+	members, err = ctrl.ListMembers(userCtx(jerry), team.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(members))
+	assert.Equal(t, jerry.ID, members[0].UserID)
+
+	// members of the team are deleted together
+	assert.Nil(t, db.Create(&membermodels.Member{ResourceType: membermodels.TypeGroup, ResourceID: 1,
+		Role: "owner", MemberType: membermodels.MemberGroup, MemberNameID: team.ID}).Error)
+	// only owners remove the others from the team, and the users leave the team by themselves
+	_, err = ctrl.PostMember(userCtx(jerry), team.ID, &PostMemberRequest{UserID: tony.ID, Role: models.RoleMember})
+	assert.Nil(t, err)
+	err = ctrl.RemoveMember(userCtx(tony), team.ID, jerry.ID)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	err = ctrl.DeleteTeam(userCtx(tony), team.ID)
+	assert.Equal(t, herrors.ErrForbidden, perror.Cause(err))
+	assert.Nil(t, ctrl.RemoveMember(userCtx(tony), team.ID, tony.ID))
+	assert.Nil(t, ctrl.DeleteTeam(userCtx(jerry), team.ID))
+	_, err = ctrl.GetTeam(userCtx(jerry), team.ID)
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+	var count int64
+	assert.Nil(t, db.Model(&membermodels.Member{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+}
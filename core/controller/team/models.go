@@ -0,0 +1,94 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package team
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/team/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+type CreateTeamRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type UpdateTeamRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+type Team struct {
+	CreateTeamRequest
+	ID        uint      `json:"id"`
+	CreatedBy uint      `json:"createdBy"`
+	UpdatedBy uint      `json:"updatedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type PostMemberRequest struct {
+	UserID uint `json:"userID"`
+	// Role is owner or member
+	Role string `json:"role"`
+}
+
+type Member struct {
+	UserID    uint      `json:"userID"`
+	UserName  string    `json:"userName"`
+	FullName  string    `json:"fullName"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (r *UpdateTeamRequest) toModel(team *models.Team) *models.Team {
+	if r.Name != nil {
+		team.Name = *r.Name
+	}
+	if r.Description != nil {
+		team.Description = *r.Description
+	}
+	return team
+}
+
+func ofTeamModel(team *models.Team) *Team {
+	return &Team{
+		CreateTeamRequest: CreateTeamRequest{
+			Name:        team.Name,
+			Description: team.Description,
+		},
+		ID:        team.ID,
+		CreatedBy: team.CreatedBy,
+		UpdatedBy: team.UpdatedBy,
+		CreatedAt: team.CreatedAt,
+		UpdatedAt: team.UpdatedAt,
+	}
+}
+
+func ofMemberModel(member *models.TeamMember, user *usermodels.User) *Member {
+	m := &Member{
+		UserID:    member.UserID,
+		Role:      member.Role,
+		CreatedAt: member.CreatedAt,
+	}
+	if user != nil {
+		m.UserName = user.Name
+		m.FullName = user.FullName
+		m.Email = user.Email
+	}
+	return m
+}
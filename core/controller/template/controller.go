@@ -45,6 +45,7 @@ import (
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	"github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	"github.com/horizoncd/horizon/pkg/templaterelease/validate"
 	"github.com/horizoncd/horizon/pkg/templaterepo"
 	"github.com/horizoncd/horizon/pkg/util/permission"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
@@ -67,6 +68,7 @@ type Controller interface {
 	CreateTemplate(ctx context.Context, groupID uint, request CreateTemplateRequest) (*Template, error)
 	// CreateRelease downloads template archive and push it to chatmusuem,
 	// then creates a template release in database.
+	// The archive is validated before pushed, the release is created as failed if it's invalid.
 	CreateRelease(ctx context.Context, templateID uint, request CreateReleaseRequest) (*Release, error)
 	// GetTemplate gets template by templateID
 	GetTemplate(ctx context.Context, templateID uint) (*Template, error)
@@ -93,6 +95,7 @@ type controller struct {
 	memberMgr            membermanager.Manager
 	memberSvc            memberservice.Service
 	templateSchemaGetter schema.Getter
+	templateValidator    validate.Validator
 }
 
 var _ Controller = (*controller)(nil)
@@ -104,6 +107,7 @@ func NewController(param *param.Param, repo templaterepo.TemplateRepo) Controlle
 		templateMgr:          param.TemplateMgr,
 		templateReleaseMgr:   param.TemplateReleaseMgr,
 		templateSchemaGetter: param.TemplateSchemaGetter,
+		templateValidator:    param.TemplateValidator,
 		templateRepo:         repo,
 		memberMgr:            param.MemberMgr,
 		memberSvc:            param.MemberService,
@@ -612,19 +616,26 @@ func (c *controller) CreateRelease(ctx context.Context,
 		return nil, err
 	}
 
+	var failures validate.Failures
 	if syncToRepo, ok := ctx.Value(hctx.ReleaseSyncToRepo).(bool); !ok || (ok && syncToRepo) {
 		tag, err := c.getTag(ctx, template.Repository, template.ChartName, release.Name)
 		if err != nil {
 			return nil, err
 		}
 		chartVersion := fmt.Sprintf(common.ChartVersionFormat, release.Name, tag.ShortID)
-		err = c.syncReleaseToRepo(tag.ArchiveData, template.ChartName, chartVersion)
+		failures, err = c.syncReleaseToRepo(ctx, tag.ArchiveData, template.ChartName, chartVersion)
 		if err != nil {
 			return nil, err
 		}
 		release.CommitID = tag.ShortID
-		release.SyncStatus = trmodels.StatusSucceed
-		release.ChartVersion = chartVersion
+		if len(failures) > 0 {
+			// the release is kept with the failures, so that it can be synced again after the chart is fixed
+			release.SyncStatus = trmodels.StatusFailed
+			release.FailedReason = failures.Encode()
+		} else {
+			release.SyncStatus = trmodels.StatusSucceed
+			release.ChartVersion = chartVersion
+		}
 	} else {
 		release.SyncStatus = trmodels.StatusOutOfSync
 	}
@@ -636,6 +647,10 @@ func (c *controller) CreateRelease(ctx context.Context,
 	if newRelease, err = c.templateReleaseMgr.Create(ctx, release); err != nil {
 		return nil, err
 	}
+	if len(failures) > 0 {
+		return nil, perror.Wrapf(herrors.ErrChartValidationFailed,
+			"release %s was not uploaded: %s", release.Name, failures)
+	}
 	return toRelease(newRelease), nil
 }
 
@@ -819,13 +834,18 @@ func (c *controller) SyncReleaseToRepo(ctx context.Context, releaseID uint) erro
 		return err
 	}
 	chartVersion := fmt.Sprintf(common.ChartVersionFormat, release.Name, tag.ShortID)
-	err = c.syncReleaseToRepo(tag.ArchiveData, template.ChartName, chartVersion)
+	failures, err := c.syncReleaseToRepo(ctx, tag.ArchiveData, template.ChartName, chartVersion)
 	if err != nil {
 		_ = c.handleReleaseSyncStatus(ctx, release, tag.ShortID, err.Error())
-	} else {
-		_ = c.handleReleaseSyncStatus(ctx, release, tag.ShortID, "")
+		return err
+	}
+	if len(failures) > 0 {
+		_ = c.handleReleaseSyncStatus(ctx, release, tag.ShortID, failures.Encode())
+		return perror.Wrapf(herrors.ErrChartValidationFailed,
+			"release %s was not uploaded: %s", release.Name, failures)
 	}
-	return err
+	_ = c.handleReleaseSyncStatus(ctx, release, tag.ShortID, "")
+	return nil
 }
 
 func (c *controller) handleReleaseSyncStatus(ctx context.Context,
@@ -840,7 +860,7 @@ func (c *controller) handleReleaseSyncStatus(ctx context.Context,
 	}
 	release.CommitID = commitID
 	release.LastSyncAt = time.Now()
-	return c.templateReleaseMgr.UpdateByID(ctx, release.ID, release)
+	return c.templateReleaseMgr.UpdateSyncStatus(ctx, release.ID, release)
 }
 
 func (c *controller) getTag(ctx context.Context, repository,
@@ -887,15 +907,21 @@ func (c *controller) checkStatusForRelease(ctx context.Context,
 	return release, nil
 }
 
-func (c *controller) syncReleaseToRepo(chartBytes []byte, name, tag string) error {
+// syncReleaseToRepo validates the chart and uploads it to template repo,
+// the chart is not uploaded if any failure is found by validation.
+func (c *controller) syncReleaseToRepo(ctx context.Context,
+	chartBytes []byte, name, tag string) (validate.Failures, error) {
 	chart, err := loader.LoadArchive(bytes.NewReader(chartBytes))
 	if err != nil {
-		return perror.Wrap(herrors.ErrLoadChartArchive, fmt.Sprintf("failed to load archive: %v", err))
+		return nil, perror.Wrap(herrors.ErrLoadChartArchive, fmt.Sprintf("failed to load archive: %v", err))
 	}
 	chart.Metadata.Version = tag
 	chart.Metadata.Name = name
 
-	return c.templateRepo.UploadChart(chart)
+	if failures := c.templateValidator.Validate(ctx, chart); len(failures) > 0 {
+		return failures, nil
+	}
+	return nil, c.templateRepo.UploadChart(chart)
 }
 
 func (c *controller) checkHasOnlyOwnerPermissionForTemplate(ctx context.Context,
@@ -28,6 +28,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
 
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
@@ -44,6 +45,7 @@ import (
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	cmodels "github.com/horizoncd/horizon/pkg/cluster/models"
 	gitconfig "github.com/horizoncd/horizon/pkg/config/git"
+	"github.com/horizoncd/horizon/pkg/config/templatevalidation"
 	hctx "github.com/horizoncd/horizon/pkg/context"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/git"
@@ -59,6 +61,7 @@ import (
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
 	reposchema "github.com/horizoncd/horizon/pkg/templaterelease/schema/repo"
+	"github.com/horizoncd/horizon/pkg/templaterelease/validate"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 )
 
@@ -408,6 +411,79 @@ func TestCreateTemplate(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestCreateReleaseWithInvalidChart(t *testing.T) {
+	createContext()
+	mockCtl := gomock.NewController(t)
+	repo := mock_repo.NewMockTemplateRepo(mockCtl)
+	gitGetter := gitmock.NewMockHelper(mockCtl)
+	validator, err := validate.NewValidator(templatevalidation.Config{Enabled: true})
+	assert.Nil(t, err)
+
+	ctl := &controller{
+		gitgetter:          gitGetter,
+		templateRepo:       repo,
+		templateMgr:        mgr.TemplateMgr,
+		templateReleaseMgr: mgr.TemplateReleaseMgr,
+		templateValidator:  validator,
+	}
+
+	archive := func(replicas string) []byte {
+		chrt := &chart.Chart{
+			Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "javaapp", Version: "0.1.0"},
+			Raw:      []*chart.File{{Name: chartutil.ValuesfileName, Data: []byte("replicas: " + replicas)}},
+			Templates: []*chart.File{{Name: "templates/deployment.yaml", Data: []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+spec:
+  replicas: {{ .Values.replicas }}`)}},
+		}
+		dir := t.TempDir()
+		file, err := chartutil.Save(chrt, dir)
+		assert.Nil(t, err)
+		data, err := ioutil.ReadFile(file)
+		assert.Nil(t, err)
+		return data
+	}
+
+	template, err := mgr.TemplateMgr.Create(ctx, &tmodels.Template{
+		Name:       "javaapp",
+		ChartName:  "javaapp",
+		Repository: templateRepo,
+	})
+	assert.Nil(t, err)
+
+	// the release is saved with the failures, but not uploaded
+	gitGetter.EXPECT().GetTagArchive(gomock.Any(), templateRepo, "v1.0.0").
+		Return(&git.Tag{ShortID: "abcdef", ArchiveData: archive("two")}, nil)
+	_, err = ctl.CreateRelease(ctx, template.ID, CreateReleaseRequest{Name: "v1.0.0"})
+	assert.Equal(t, herrors.ErrChartValidationFailed, perror.Cause(err))
+
+	releases, err := mgr.TemplateReleaseMgr.ListByTemplateID(ctx, template.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(releases))
+	assert.Equal(t, trmodels.StatusFailed, releases[0].SyncStatus)
+	assert.Equal(t, "", releases[0].ChartVersion)
+	release := toRelease(releases[0])
+	assert.Equal(t, 1, len(release.ValidationFailures))
+	assert.Equal(t, validate.CheckKubernetes, release.ValidationFailures[0].Check)
+	assert.Equal(t, "javaapp/templates/deployment.yaml", release.ValidationFailures[0].File)
+
+	// the release is uploaded after the chart is fixed
+	gitGetter.EXPECT().GetTagArchive(gomock.Any(), templateRepo, "v1.0.0").
+		Return(&git.Tag{ShortID: "123456", ArchiveData: archive("2")}, nil)
+	repo.EXPECT().UploadChart(gomock.Any()).Return(nil)
+	err = ctl.SyncReleaseToRepo(ctx, releases[0].ID)
+	assert.Nil(t, err)
+
+	release, err = ctl.GetRelease(ctx, releases[0].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "Succeed", release.SyncStatus)
+	assert.Equal(t, "v1.0.0-123456", release.ChartVersion)
+	assert.Equal(t, "", release.FailedReason)
+	assert.Nil(t, release.ValidationFailures)
+}
+
 func TestCreateTemplateInNonRootGroup(t *testing.T) {
 	createContext()
 	ctl, _ := createController(t)
@@ -626,6 +702,9 @@ func createController(t *testing.T) (Controller, *mock_repo.MockTemplateRepo) {
 		URL: "https://github.com", Token: ""})
 	assert.Nil(t, err)
 
+	validator, err := validate.NewValidator(templatevalidation.Config{Enabled: true})
+	assert.Nil(t, err)
+
 	ctl := &controller{
 		gitgetter:            githubGetter,
 		templateRepo:         repo,
@@ -634,6 +713,7 @@ func createController(t *testing.T) (Controller, *mock_repo.MockTemplateRepo) {
 		templateReleaseMgr:   mgr.TemplateReleaseMgr,
 		memberMgr:            mgr.MemberMgr,
 		templateSchemaGetter: getter,
+		templateValidator:    validator,
 	}
 	return ctl, repo
 }
@@ -25,6 +25,7 @@ import (
 	tmodels "github.com/horizoncd/horizon/pkg/template/models"
 	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
 	trschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	"github.com/horizoncd/horizon/pkg/templaterelease/validate"
 )
 
 type CreateTemplateRequest struct {
@@ -198,10 +199,12 @@ type Release struct {
 	SyncStatus     string    `json:"syncStatus"`
 	LastSyncAt     time.Time `json:"lastSyncAt"`
 	FailedReason   string    `json:"failedReason"`
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
-	CreatedBy      uint      `json:"createdBy"`
-	UpdatedBy      uint      `json:"updatedBy"`
+	// ValidationFailures is decoded from FailedReason if the release failed in validation
+	ValidationFailures validate.Failures `json:"validationFailures,omitempty"`
+	CreatedAt          time.Time         `json:"createdAt"`
+	UpdatedAt          time.Time         `json:"updatedAt"`
+	CreatedBy          uint              `json:"createdBy"`
+	UpdatedBy          uint              `json:"updatedBy"`
 }
 
 type Releases []*Release
@@ -230,20 +233,21 @@ func toRelease(m *trmodels.TemplateRelease) *Release {
 		return nil
 	}
 	tr := &Release{
-		ID:             m.ID,
-		Name:           m.Name,
-		ChartVersion:   m.ChartVersion,
-		Description:    m.Description,
-		TemplateID:     m.Template,
-		TemplateName:   m.TemplateName,
-		SyncStatusCode: uint8(m.SyncStatus),
-		LastSyncAt:     m.LastSyncAt,
-		CommitID:       m.CommitID,
-		FailedReason:   m.FailedReason,
-		CreatedAt:      m.Model.CreatedAt,
-		UpdatedAt:      m.Model.UpdatedAt,
-		CreatedBy:      m.CreatedBy,
-		UpdatedBy:      m.UpdatedBy,
+		ID:                 m.ID,
+		Name:               m.Name,
+		ChartVersion:       m.ChartVersion,
+		Description:        m.Description,
+		TemplateID:         m.Template,
+		TemplateName:       m.TemplateName,
+		SyncStatusCode:     uint8(m.SyncStatus),
+		LastSyncAt:         m.LastSyncAt,
+		CommitID:           m.CommitID,
+		FailedReason:       m.FailedReason,
+		ValidationFailures: validate.DecodeFailures(m.FailedReason),
+		CreatedAt:          m.Model.CreatedAt,
+		UpdatedAt:          m.Model.UpdatedAt,
+		CreatedBy:          m.CreatedBy,
+		UpdatedBy:          m.UpdatedBy,
 	}
 	switch trmodels.SyncStatus(tr.SyncStatusCode) {
 	case trmodels.StatusSucceed:
@@ -0,0 +1,390 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatemigration
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	appmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param"
+	templatemanager "github.com/horizoncd/horizon/pkg/template/manager"
+	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
+	migrationmanager "github.com/horizoncd/horizon/pkg/templatemigration/manager"
+	"github.com/horizoncd/horizon/pkg/templatemigration/models"
+	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	tagutil "github.com/horizoncd/horizon/pkg/util/tag"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _defaultBatchSize = 10
+
+type Controller interface {
+	// Preview selects the clusters to migrate and checks whether their values are compatible with the target release
+	Preview(ctx context.Context, templateID uint, request *CreateMigrationRequest) ([]*ClusterPreview, error)
+	// Create starts to migrate the selected clusters, they are upgraded batch by batch by the template migration job
+	Create(ctx context.Context, templateID uint, request *CreateMigrationRequest) (*Migration, error)
+	List(ctx context.Context, templateID uint, query *q.Query) ([]*Migration, int64, error)
+	Get(ctx context.Context, id uint) (*Migration, error)
+	// Pause stops the migration after the batch in progress, Resume continues it
+	Pause(ctx context.Context, id uint) error
+	Resume(ctx context.Context, id uint) error
+	// Abort stops the migration, the clusters not upgraded yet are left as they are
+	Abort(ctx context.Context, id uint) error
+}
+
+type controller struct {
+	migrationMgr       migrationmanager.Manager
+	templateMgr        templatemanager.Manager
+	templateReleaseMgr trmanager.Manager
+	clusterMgr         clustermanager.Manager
+	appMgr             appmanager.Manager
+	groupMgr           groupmanager.Manager
+	envMgr             envmanager.Manager
+	clusterCtl         clusterctl.Controller
+}
+
+func NewController(param *param.Param, clusterCtl clusterctl.Controller) Controller {
+	return &controller{
+		migrationMgr:       param.TemplateMigrationMgr,
+		templateMgr:        param.TemplateMgr,
+		templateReleaseMgr: param.TemplateReleaseMgr,
+		clusterMgr:         param.ClusterMgr,
+		appMgr:             param.ApplicationMgr,
+		groupMgr:           param.GroupMgr,
+		envMgr:             param.EnvMgr,
+		clusterCtl:         clusterCtl,
+	}
+}
+
+func (c *controller) Preview(ctx context.Context, templateID uint,
+	request *CreateMigrationRequest) ([]*ClusterPreview, error) {
+	const op = "template migration controller: preview"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	template, err := c.templateMgr.GetByID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	clusters, err := c.selectClusters(ctx, template, request)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*ClusterPreview, 0, len(clusters))
+	for _, cluster := range clusters {
+		preview := &ClusterPreview{
+			ClusterID:   cluster.ID,
+			Cluster:     cluster.Name,
+			Environment: cluster.EnvironmentName,
+			FromRelease: cluster.TemplateRelease,
+			Compatible:  true,
+		}
+		if err := c.clusterCtl.UpgradeTemplateRelease(ctx, cluster.ID,
+			request.TargetRelease, true); err != nil {
+			preview.Compatible = false
+			preview.Message = err.Error()
+		}
+		result = append(result, preview)
+	}
+	return result, nil
+}
+
+func (c *controller) Create(ctx context.Context, templateID uint,
+	request *CreateMigrationRequest) (*Migration, error) {
+	const op = "template migration controller: create"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	template, err := c.templateMgr.GetByID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range []string{models.StatusRunning, models.StatusPaused} {
+		migrations, err := c.migrationMgr.ListByStatus(ctx, status)
+		if err != nil {
+			return nil, err
+		}
+		for _, migration := range migrations {
+			if migration.TemplateID == templateID {
+				return nil, perror.Wrapf(herrors.ErrParamInvalid,
+					"template is being migrated by migration %d, abort it first", migration.ID)
+			}
+		}
+	}
+	clusters, err := c.selectClusters(ctx, template, request)
+	if err != nil {
+		return nil, err
+	}
+	if len(clusters) == 0 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "no clusters to migrate")
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := request.BatchSize
+	if batchSize == 0 {
+		batchSize = _defaultBatchSize
+	}
+	migration := &models.Migration{
+		TemplateID:     template.ID,
+		Template:       template.Name,
+		SourceReleases: request.SourceReleases,
+		TargetRelease:  request.TargetRelease,
+		GroupID:        request.GroupID,
+		Environment:    request.Environment,
+		TagSelector:    request.TagSelector,
+		BatchSize:      batchSize,
+		Status:         models.StatusRunning,
+		CreatedBy:      currentUser.GetID(),
+		UpdatedBy:      currentUser.GetID(),
+	}
+	migrationClusters := make([]*models.MigrationCluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		migrationClusters = append(migrationClusters, &models.MigrationCluster{
+			ClusterID:   cluster.ID,
+			Cluster:     cluster.Name,
+			FromRelease: cluster.TemplateRelease,
+			Status:      models.ClusterStatusPending,
+		})
+	}
+	migration, err = c.migrationMgr.Create(ctx, migration, migrationClusters)
+	if err != nil {
+		return nil, err
+	}
+	return ofMigrationModel(migration, migrationClusters, true), nil
+}
+
+func (c *controller) List(ctx context.Context, templateID uint, query *q.Query) ([]*Migration, int64, error) {
+	const op = "template migration controller: list"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	migrations, total, err := c.migrationMgr.List(ctx, templateID, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	result := make([]*Migration, 0, len(migrations))
+	for _, migration := range migrations {
+		clusters, err := c.migrationMgr.ListClusters(ctx, migration.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		result = append(result, ofMigrationModel(migration, clusters, false))
+	}
+	return result, total, nil
+}
+
+func (c *controller) Get(ctx context.Context, id uint) (*Migration, error) {
+	const op = "template migration controller: get"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	migration, err := c.migrationMgr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	clusters, err := c.migrationMgr.ListClusters(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ofMigrationModel(migration, clusters, true), nil
+}
+
+func (c *controller) Pause(ctx context.Context, id uint) error {
+	const op = "template migration controller: pause"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.transit(ctx, id, models.StatusRunning, models.StatusPaused)
+}
+
+func (c *controller) Resume(ctx context.Context, id uint) error {
+	const op = "template migration controller: resume"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return c.transit(ctx, id, models.StatusPaused, models.StatusRunning)
+}
+
+func (c *controller) Abort(ctx context.Context, id uint) error {
+	const op = "template migration controller: abort"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	migration, err := c.migrationMgr.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if migration.Status != models.StatusRunning && migration.Status != models.StatusPaused {
+		return perror.Wrapf(herrors.ErrParamInvalid, "migration %d is %s", migration.ID, migration.Status)
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	// abort the migration first, so that no more clusters are upgraded by the job
+	from := migration.Status
+	migration.Status = models.StatusAborted
+	migration.Message = "aborted by " + currentUser.GetName()
+	migration.UpdatedBy = currentUser.GetID()
+	updated, err := c.migrationMgr.Update(ctx, migration, models.StatusRunning, models.StatusPaused)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return perror.Wrapf(herrors.ErrParamInvalid, "migration %d is not %s anymore", migration.ID, from)
+	}
+
+	clusters, err := c.migrationMgr.ListClusters(ctx, id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, cluster := range clusters {
+		if cluster.Status != models.ClusterStatusPending {
+			continue
+		}
+		cluster.Status = models.ClusterStatusAborted
+		cluster.FinishedAt = &now
+		if err := c.migrationMgr.UpdateCluster(ctx, cluster); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transit changes the status of migration, it fails if the migration is not in the status from
+func (c *controller) transit(ctx context.Context, id uint, from, to string) error {
+	migration, err := c.migrationMgr.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if migration.Status != from {
+		return perror.Wrapf(herrors.ErrParamInvalid, "migration %d is %s", migration.ID, migration.Status)
+	}
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	migration.Status = to
+	migration.Message = ""
+	migration.UpdatedBy = currentUser.GetID()
+	// the migration may be changed by the job or others meanwhile
+	updated, err := c.migrationMgr.Update(ctx, migration, from)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return perror.Wrapf(herrors.ErrParamInvalid, "migration %d is not %s anymore", migration.ID, from)
+	}
+	return nil
+}
+
+// selectClusters returns the clusters of template matching the request, in the order of their ids
+func (c *controller) selectClusters(ctx context.Context, template *templatemodels.Template,
+	request *CreateMigrationRequest) ([]*clustermodels.ClusterWithRegion, error) {
+	if request.TargetRelease == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "targetRelease must not be empty")
+	}
+	if _, err := c.templateReleaseMgr.GetByTemplateNameAndRelease(ctx,
+		template.Name, request.TargetRelease); err != nil {
+		return nil, err
+	}
+	var constraint *semver.Constraints
+	if request.SourceReleases != "" {
+		var err error
+		constraint, err = semver.NewConstraint(request.SourceReleases)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"invalid sourceReleases %s: %v", request.SourceReleases, err)
+		}
+	}
+
+	keywords := q.KeyWords{
+		common.ClusterQueryByTemplate: template.Name,
+	}
+	if request.Environment != "" {
+		if _, err := c.envMgr.GetByName(ctx, request.Environment); err != nil {
+			return nil, err
+		}
+		keywords[common.ClusterQueryEnvironment] = request.Environment
+	}
+	if request.TagSelector != "" {
+		tagSelectors, err := tagutil.ParseTagSelector(request.TagSelector)
+		if err != nil {
+			return nil, err
+		}
+		keywords[common.ClusterQueryTagSelector] = tagSelectors
+	}
+	_, clusters, err := c.clusterMgr.List(ctx, &q.Query{
+		Keywords:          keywords,
+		WithoutPagination: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var applicationIDs map[uint]struct{}
+	if request.GroupID != 0 {
+		groups, err := c.groupMgr.GetSubGroupsByGroupIDs(ctx, []uint{request.GroupID})
+		if err != nil {
+			return nil, err
+		}
+		groupIDs := make([]uint, 0, len(groups))
+		for _, group := range groups {
+			groupIDs = append(groupIDs, group.ID)
+		}
+		applications, err := c.appMgr.GetByGroupIDs(ctx, groupIDs)
+		if err != nil {
+			return nil, err
+		}
+		applicationIDs = make(map[uint]struct{}, len(applications))
+		for _, application := range applications {
+			applicationIDs[application.ID] = struct{}{}
+		}
+	}
+
+	result := make([]*clustermodels.ClusterWithRegion, 0, len(clusters))
+	for _, cluster := range clusters {
+		if cluster.TemplateRelease == request.TargetRelease {
+			continue
+		}
+		if applicationIDs != nil {
+			if _, ok := applicationIDs[cluster.ApplicationID]; !ok {
+				continue
+			}
+		}
+		if constraint != nil {
+			version, err := semver.NewVersion(cluster.TemplateRelease)
+			if err != nil || !constraint.Check(version) {
+				continue
+			}
+		}
+		result = append(result, cluster)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
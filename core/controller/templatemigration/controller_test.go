@@ -0,0 +1,194 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatemigration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	envmodels "github.com/horizoncd/horizon/pkg/environment/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/param"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	regionmodels "github.com/horizoncd/horizon/pkg/region/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
+	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
+	"github.com/horizoncd/horizon/pkg/templatemigration/models"
+	trmodels "github.com/horizoncd/horizon/pkg/templaterelease/models"
+)
+
+// fakeClusterCtl treats the values of cluster 2 as incompatible with the target release
+type fakeClusterCtl struct {
+	clusterctl.Controller
+}
+
+func (f *fakeClusterCtl) UpgradeTemplateRelease(ctx context.Context, clusterID uint,
+	release string, dryRun bool) error {
+	if clusterID == 2 {
+		return errors.New("replicas is required")
+	}
+	return nil
+}
+
+func TestController(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&templatemodels.Template{}, &trmodels.TemplateRelease{},
+		&groupmodels.Group{}, &appmodels.Application{}, &clustermodels.Cluster{}, &envmodels.Environment{},
+		&regionmodels.Region{}, &tagmodels.Tag{}, &models.Migration{}, &models.MigrationCluster{}))
+	assert.Nil(t, db.Create(&templatemodels.Template{Model: global.Model{ID: 1}, Name: "javaapp"}).Error)
+	for _, release := range []string{"v1.0.0", "v1.1.0", "v2.0.0"} {
+		assert.Nil(t, db.Create(&trmodels.TemplateRelease{Template: 1, TemplateName: "javaapp",
+			Name: release}).Error)
+	}
+	assert.Nil(t, db.Create(&groupmodels.Group{Model: global.Model{ID: 1}, Name: "a", TraversalIDs: "1"}).Error)
+	assert.Nil(t, db.Create(&groupmodels.Group{Model: global.Model{ID: 2}, Name: "b", ParentID: 1,
+		TraversalIDs: "1,2"}).Error)
+	assert.Nil(t, db.Create(&groupmodels.Group{Model: global.Model{ID: 3}, Name: "c", TraversalIDs: "3"}).Error)
+	assert.Nil(t, db.Create(&appmodels.Application{Model: global.Model{ID: 1}, Name: "app", GroupID: 2}).Error)
+	assert.Nil(t, db.Create(&appmodels.Application{Model: global.Model{ID: 2}, Name: "other", GroupID: 3}).Error)
+	for _, env := range []string{"test", "online"} {
+		assert.Nil(t, db.Create(&envmodels.Environment{Name: env}).Error)
+	}
+	assert.Nil(t, db.Create(&regionmodels.Region{Name: "hz"}).Error)
+	for _, cluster := range []*clustermodels.Cluster{
+		{Model: global.Model{ID: 1}, ApplicationID: 1, Name: "app-test", EnvironmentName: "test",
+			Template: "javaapp", TemplateRelease: "v1.0.0"},
+		{Model: global.Model{ID: 2}, ApplicationID: 1, Name: "app-online", EnvironmentName: "online",
+			Template: "javaapp", TemplateRelease: "v1.1.0"},
+		{Model: global.Model{ID: 3}, ApplicationID: 2, Name: "other-test", EnvironmentName: "test",
+			Template: "javaapp", TemplateRelease: "v1.0.0"},
+		{Model: global.Model{ID: 4}, ApplicationID: 1, Name: "app-test2", EnvironmentName: "test",
+			Template: "javaapp", TemplateRelease: "v2.0.0"},
+		{Model: global.Model{ID: 5}, ApplicationID: 1, Name: "app-test3", EnvironmentName: "test",
+			Template: "javaapp", TemplateRelease: "v0.9.0"},
+		{Model: global.Model{ID: 6}, ApplicationID: 1, Name: "app-test4", EnvironmentName: "test",
+			Template: "nodeapp", TemplateRelease: "v1.0.0"},
+	} {
+		cluster.RegionName = "hz"
+		assert.Nil(t, db.Create(cluster).Error)
+	}
+	assert.Nil(t, db.Create(&tagmodels.Tag{ResourceID: 3, ResourceType: common.ResourceCluster,
+		Key: "tier", Value: "core"}).Error)
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	mgr := managerparam.InitManager(db)
+	ctrl := NewController(&param.Param{Manager: mgr}, &fakeClusterCtl{})
+
+	clusterIDs := func(previews []*ClusterPreview) []uint {
+		ids := make([]uint, 0, len(previews))
+		for _, preview := range previews {
+			ids = append(ids, preview.ClusterID)
+		}
+		return ids
+	}
+
+	// preview
+	request := &CreateMigrationRequest{SourceReleases: ">= v1.0.0, < v2.0.0", TargetRelease: "v2.0.0"}
+	previews, err := ctrl.Preview(ctx, 1, request)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{1, 2, 3}, clusterIDs(previews))
+	assert.True(t, previews[0].Compatible)
+	assert.False(t, previews[1].Compatible)
+	assert.Equal(t, "replicas is required", previews[1].Message)
+	assert.Equal(t, "v1.1.0", previews[1].FromRelease)
+
+	previews, err = ctrl.Preview(ctx, 1, &CreateMigrationRequest{TargetRelease: "v2.0.0"})
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{1, 2, 3, 5}, clusterIDs(previews))
+	previews, err = ctrl.Preview(ctx, 1, &CreateMigrationRequest{TargetRelease: "v2.0.0", GroupID: 1,
+		SourceReleases: request.SourceReleases})
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{1, 2}, clusterIDs(previews))
+	previews, err = ctrl.Preview(ctx, 1, &CreateMigrationRequest{TargetRelease: "v2.0.0", Environment: "test",
+		SourceReleases: request.SourceReleases})
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{1, 3}, clusterIDs(previews))
+	previews, err = ctrl.Preview(ctx, 1, &CreateMigrationRequest{TargetRelease: "v2.0.0", TagSelector: "tier=core"})
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{3}, clusterIDs(previews))
+
+	// invalid requests
+	for _, invalid := range []*CreateMigrationRequest{
+		{},
+		{TargetRelease: "v2.0.0", SourceReleases: "latest"},
+	} {
+		_, err = ctrl.Preview(ctx, 1, invalid)
+		assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+	}
+	for _, notFound := range []*CreateMigrationRequest{
+		{TargetRelease: "v3.0.0"},
+		{TargetRelease: "v2.0.0", Environment: "nowhere"},
+	} {
+		_, err = ctrl.Preview(ctx, 1, notFound)
+		_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+		assert.True(t, ok)
+	}
+	_, err = ctrl.Create(ctx, 1, &CreateMigrationRequest{TargetRelease: "v2.0.0", Environment: "online",
+		GroupID: 3})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	// create and control the migration
+	request.GroupID = 1
+	migration, err := ctrl.Create(ctx, 1, request)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusRunning, migration.Status)
+	assert.Equal(t, "javaapp", migration.Template)
+	assert.Equal(t, uint(_defaultBatchSize), migration.BatchSize)
+	assert.Equal(t, Progress{Total: 2, Pending: 2}, migration.Progress)
+	assert.Equal(t, 2, len(migration.Clusters))
+	_, err = ctrl.Create(ctx, 1, request)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	assert.Nil(t, ctrl.Pause(ctx, migration.ID))
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(ctrl.Pause(ctx, migration.ID)))
+	assert.Nil(t, ctrl.Resume(ctx, migration.ID))
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(ctrl.Resume(ctx, migration.ID)))
+
+	clusters, err := mgr.TemplateMigrationMgr.ListClusters(ctx, migration.ID)
+	assert.Nil(t, err)
+	clusters[0].Status = models.ClusterStatusSucceeded
+	assert.Nil(t, mgr.TemplateMigrationMgr.UpdateCluster(ctx, clusters[0]))
+	assert.Nil(t, ctrl.Abort(ctx, migration.ID))
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(ctrl.Abort(ctx, migration.ID)))
+	migration, err = ctrl.Get(ctx, migration.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusAborted, migration.Status)
+	assert.Equal(t, "aborted by Tony", migration.Message)
+	assert.Equal(t, Progress{Total: 2, Succeeded: 1, Aborted: 1}, migration.Progress)
+	assert.Equal(t, models.ClusterStatusAborted, migration.Clusters[1].Status)
+	assert.NotNil(t, migration.Clusters[1].FinishedAt)
+
+	migrations, total, err := ctrl.List(ctx, 1, &q.Query{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, migration.Progress, migrations[0].Progress)
+	assert.Nil(t, migrations[0].Clusters)
+}
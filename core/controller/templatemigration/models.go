@@ -0,0 +1,122 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatemigration
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/templatemigration/models"
+)
+
+type CreateMigrationRequest struct {
+	// SourceReleases is a semver constraint of the releases to migrate from, such as ">= v1.0.0, < v2.0.0",
+	// all the releases except the target one are selected if it is empty
+	SourceReleases string `json:"sourceReleases"`
+	TargetRelease  string `json:"targetRelease"`
+	// GroupID, Environment and TagSelector select the clusters to migrate, they are ignored if empty
+	GroupID     uint   `json:"groupID,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	TagSelector string `json:"tagSelector,omitempty"`
+	// BatchSize is the number of clusters to upgrade at a time, 10 by default
+	BatchSize uint `json:"batchSize"`
+}
+
+type ClusterPreview struct {
+	ClusterID   uint   `json:"clusterID"`
+	Cluster     string `json:"cluster"`
+	Environment string `json:"environment"`
+	FromRelease string `json:"fromRelease"`
+	// Compatible is false if the values of cluster are invalid against the schema of target release
+	Compatible bool   `json:"compatible"`
+	Message    string `json:"message,omitempty"`
+}
+
+type Progress struct {
+	Total     int `json:"total"`
+	Pending   int `json:"pending"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Aborted   int `json:"aborted"`
+}
+
+type MigrationCluster struct {
+	ClusterID   uint       `json:"clusterID"`
+	Cluster     string     `json:"cluster"`
+	FromRelease string     `json:"fromRelease"`
+	Status      string     `json:"status"`
+	Message     string     `json:"message,omitempty"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+}
+
+type Migration struct {
+	CreateMigrationRequest
+	ID         uint                `json:"id"`
+	TemplateID uint                `json:"templateID"`
+	Template   string              `json:"template"`
+	Status     string              `json:"status"`
+	Message    string              `json:"message,omitempty"`
+	Progress   Progress            `json:"progress"`
+	Clusters   []*MigrationCluster `json:"clusters,omitempty"`
+	CreatedBy  uint                `json:"createdBy"`
+	CreatedAt  time.Time           `json:"createdAt"`
+	UpdatedAt  time.Time           `json:"updatedAt"`
+}
+
+// ofMigrationModel converts the migration to response, the clusters are omitted unless withClusters is true
+func ofMigrationModel(migration *models.Migration, clusters []*models.MigrationCluster,
+	withClusters bool) *Migration {
+	result := &Migration{
+		CreateMigrationRequest: CreateMigrationRequest{
+			SourceReleases: migration.SourceReleases,
+			TargetRelease:  migration.TargetRelease,
+			GroupID:        migration.GroupID,
+			Environment:    migration.Environment,
+			TagSelector:    migration.TagSelector,
+			BatchSize:      migration.BatchSize,
+		},
+		ID:         migration.ID,
+		TemplateID: migration.TemplateID,
+		Template:   migration.Template,
+		Status:     migration.Status,
+		Message:    migration.Message,
+		Progress:   Progress{Total: len(clusters)},
+		CreatedBy:  migration.CreatedBy,
+		CreatedAt:  migration.CreatedAt,
+		UpdatedAt:  migration.UpdatedAt,
+	}
+	for _, cluster := range clusters {
+		switch cluster.Status {
+		case models.ClusterStatusPending:
+			result.Progress.Pending++
+		case models.ClusterStatusSucceeded:
+			result.Progress.Succeeded++
+		case models.ClusterStatusFailed:
+			result.Progress.Failed++
+		case models.ClusterStatusAborted:
+			result.Progress.Aborted++
+		}
+		if withClusters {
+			result.Clusters = append(result.Clusters, &MigrationCluster{
+				ClusterID:   cluster.ClusterID,
+				Cluster:     cluster.Cluster,
+				FromRelease: cluster.FromRelease,
+				Status:      cluster.Status,
+				Message:     cluster.Message,
+				FinishedAt:  cluster.FinishedAt,
+			})
+		}
+	}
+	return result
+}
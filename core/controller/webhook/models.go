@@ -40,6 +40,7 @@ type UpdateWebhookRequest struct {
 	Description      *string  `json:"description"`
 	Secret           *string  `json:"secret"`
 	Triggers         []string `json:"triggers"`
+	MaxAttempts      *uint    `json:"maxAttempts"`
 }
 
 type CreateWebhookRequest struct {
@@ -49,6 +50,7 @@ type CreateWebhookRequest struct {
 	Description      string   `json:"description"`
 	Secret           string   `json:"secret"`
 	Triggers         []string `json:"triggers"`
+	MaxAttempts      uint     `json:"maxAttempts"`
 }
 
 type Webhook struct {
@@ -61,21 +63,23 @@ type Webhook struct {
 }
 
 type LogSummary struct {
-	ID           uint                  `json:"id"`
-	WebhookID    uint                  `json:"webhookID"`
-	EventID      uint                  `json:"eventID"`
-	URL          string                `json:"url"`
-	Status       string                `json:"status"`
-	ResourceType string                `json:"resourceType"`
-	ResourceName string                `json:"resourceName"`
-	ResourceID   uint                  `json:"resourceID"`
-	EventType    string                `json:"eventType"`
-	Extra        *string               `json:"extra"`
-	ErrorMessage string                `json:"errorMessage"`
-	CreatedAt    time.Time             `json:"createdAt"`
-	CreatedBy    *usermodels.UserBasic `json:"createdBy,omitempty"`
-	UpdatedAt    time.Time             `json:"updatedAt"`
-	UpdatedBy    *usermodels.UserBasic `json:"updatedBy,omitempty"`
+	ID            uint                  `json:"id"`
+	WebhookID     uint                  `json:"webhookID"`
+	EventID       uint                  `json:"eventID"`
+	URL           string                `json:"url"`
+	Status        string                `json:"status"`
+	ResourceType  string                `json:"resourceType"`
+	ResourceName  string                `json:"resourceName"`
+	ResourceID    uint                  `json:"resourceID"`
+	EventType     string                `json:"eventType"`
+	Extra         *string               `json:"extra"`
+	ErrorMessage  string                `json:"errorMessage"`
+	Attempts      uint                  `json:"attempts"`
+	NextAttemptAt *time.Time            `json:"nextAttemptAt,omitempty"`
+	CreatedAt     time.Time             `json:"createdAt"`
+	CreatedBy     *usermodels.UserBasic `json:"createdBy,omitempty"`
+	UpdatedAt     time.Time             `json:"updatedAt"`
+	UpdatedBy     *usermodels.UserBasic `json:"updatedBy,omitempty"`
 }
 
 type Log struct {
@@ -105,6 +109,9 @@ func (w *UpdateWebhookRequest) toModel(wm *wmodels.Webhook) *wmodels.Webhook {
 	if len(w.Triggers) > 0 {
 		wm.Triggers = JoinTriggers(w.Triggers)
 	}
+	if w.MaxAttempts != nil {
+		wm.MaxAttempts = *w.MaxAttempts
+	}
 	return wm
 }
 
@@ -131,6 +138,7 @@ func (w *CreateWebhookRequest) toModel(ctx context.Context,
 		Description:      w.Description,
 		Secret:           w.Secret,
 		Triggers:         JoinTriggers(w.Triggers),
+		MaxAttempts:      w.MaxAttempts,
 	}
 	return wm, nil
 }
@@ -204,6 +212,7 @@ func ofWebhookModel(wm *wmodels.Webhook) *Webhook {
 			Description:      wm.Description,
 			Secret:           wm.Secret,
 			Triggers:         ParseTriggerStr(wm.Triggers),
+			MaxAttempts:      wm.MaxAttempts,
 		},
 		ID:        wm.ID,
 		CreatedAt: wm.CreatedAt,
@@ -215,18 +224,20 @@ func ofWebhookModel(wm *wmodels.Webhook) *Webhook {
 
 func ofWebhookLogSummaryModel(wm *wmodels.WebhookLogWithEventInfo) *LogSummary {
 	wl := &LogSummary{
-		ID:           wm.ID,
-		WebhookID:    wm.WebhookID,
-		EventID:      wm.EventID,
-		URL:          wm.URL,
-		ResourceType: wm.ResourceType,
-		ResourceID:   wm.ResourceID,
-		ResourceName: wm.ResourceName,
-		EventType:    wm.EventType,
-		Status:       wm.Status,
-		ErrorMessage: wm.ErrorMessage,
-		CreatedAt:    wm.CreatedAt,
-		UpdatedAt:    wm.UpdatedAt,
+		ID:            wm.ID,
+		WebhookID:     wm.WebhookID,
+		EventID:       wm.EventID,
+		URL:           wm.URL,
+		ResourceType:  wm.ResourceType,
+		ResourceID:    wm.ResourceID,
+		ResourceName:  wm.ResourceName,
+		EventType:     wm.EventType,
+		Status:        wm.Status,
+		ErrorMessage:  wm.ErrorMessage,
+		Attempts:      wm.Attempts,
+		NextAttemptAt: wm.NextAttemptAt,
+		CreatedAt:     wm.CreatedAt,
+		UpdatedAt:     wm.UpdatedAt,
 	}
 	return wl
 }
@@ -234,14 +245,16 @@ func ofWebhookLogSummaryModel(wm *wmodels.WebhookLogWithEventInfo) *LogSummary {
 func ofWebhookLogModel(wm *wmodels.WebhookLog) *Log {
 	wl := &Log{
 		LogSummary: LogSummary{
-			ID:           wm.ID,
-			WebhookID:    wm.WebhookID,
-			EventID:      wm.EventID,
-			URL:          wm.URL,
-			Status:       wm.Status,
-			ErrorMessage: wm.ErrorMessage,
-			CreatedAt:    wm.CreatedAt,
-			UpdatedAt:    wm.UpdatedAt,
+			ID:            wm.ID,
+			WebhookID:     wm.WebhookID,
+			EventID:       wm.EventID,
+			URL:           wm.URL,
+			Status:        wm.Status,
+			ErrorMessage:  wm.ErrorMessage,
+			Attempts:      wm.Attempts,
+			NextAttemptAt: wm.NextAttemptAt,
+			CreatedAt:     wm.CreatedAt,
+			UpdatedAt:     wm.UpdatedAt,
 		},
 		RequestHeaders:  wm.RequestHeaders,
 		RequestData:     wm.RequestData,
@@ -29,6 +29,7 @@ var (
 	GitlabClient              = sourceType{name: "GitlabClient"}
 	GitlabResource            = sourceType{name: "GitlabResource"}
 	GithubResource            = sourceType{name: "GithubResource"}
+	GitRepoResource           = sourceType{name: "GitRepoResource"}
 	ClusterInDB               = sourceType{name: "ClusterInDB"}
 	CollectionInDB            = sourceType{name: "CollectionInDB"}
 	ClusterStateInArgo        = sourceType{name: "ClusterStateInArgo"}
@@ -75,6 +76,19 @@ var (
 	CheckInDB                 = sourceType{name: "CheckInDB"}
 	CheckRunInDB              = sourceType{name: "CheckRunInDB"}
 	PRMessageInDB             = sourceType{name: "PRMessageInDB"}
+	StepLogInDB               = sourceType{name: "StepLogInDB"}
+	AdmissionPolicyInDB       = sourceType{name: "AdmissionPolicyInDB"}
+	DeployWindowInDB          = sourceType{name: "DeployWindowInDB"}
+	ReleaseTrainInDB          = sourceType{name: "ReleaseTrainInDB"}
+	ReleaseTrainRunInDB       = sourceType{name: "ReleaseTrainRunInDB"}
+	TeamInDB                  = sourceType{name: "TeamInDB"}
+	TeamMemberInDB            = sourceType{name: "TeamMemberInDB"}
+	RoleInDB                  = sourceType{name: "RoleInDB"}
+	AccessAuditInDB           = sourceType{name: "AccessAuditInDB"}
+	ImagePolicyInDB           = sourceType{name: "ImagePolicyInDB"}
+	HookEventInDB             = sourceType{name: "HookEventInDB"}
+	HookDeliveryInDB          = sourceType{name: "HookDeliveryInDB"}
+	TemplateMigrationInDB     = sourceType{name: "TemplateMigrationInDB"}
 
 	// S3
 	PipelinerunLog = sourceType{name: "PipelinerunLog"}
@@ -86,6 +100,8 @@ var (
 	TektonClient    = sourceType{name: "TektonClient"}
 	TektonCollector = sourceType{name: "TektonCollector"}
 
+	PipelinerunInJenkins = sourceType{name: "PipelinerunInJenkins"}
+
 	HelmRepo  = sourceType{name: "HelmRepo"}
 	OAuthInDB = sourceType{name: "OauthAppClient"}
 	TokenInDB = sourceType{name: "TokenInDB"}
@@ -224,6 +240,7 @@ var (
 	ErrShouldBuildDeployFirst          = errors.New("clusters with build config should build and deploy first")
 	ErrBuildDeployNotSupported         = errors.New("builddeploy is not supported for this cluster")
 	ErrFreedClusterNotSupportedRestart = errors.New("freed cluster is not supported to restart")
+	ErrDeployWindowClosed              = errors.New("deployments are held by deploy window")
 
 	// pipelinerun
 
@@ -249,6 +266,8 @@ var (
 	// git
 	ErrBranchAndCommitEmpty      = errors.New("branch and commit cannot be empty at the same time")
 	ErrGitlabInterfaceCallFailed = errors.New("failed to call gitlab interface")
+	ErrGitRepoInternal           = errors.New("git repo internal")
+	ErrGitMergeConflict          = errors.New("git merge conflict")
 
 	// pipeline
 	ErrPipelineOutputEmpty = errors.New("pipeline output is empty")
@@ -261,6 +280,8 @@ var (
 
 	// helm
 	ErrLoadChartArchive = errors.New("failed to load archive")
+	// ErrChartValidationFailed used when the chart of a template release does not pass the validation
+	ErrChartValidationFailed = errors.New("chart validation failed")
 
 	// group
 	// ErrHasChildren used when delete a group which still has some children
@@ -280,6 +301,13 @@ var (
 	ErrAuthorizationHeaderNotFound = errors.New("AuthorizationHeader not found")
 	ErrOAuthTokenFormatError       = errors.New("Oauth token format error")
 	ErrOAuthNotGroupOwnerType      = errors.New("not group oauth app")
+	ErrOIDCNotEnabled              = errors.New("horizon is not configured as an OIDC provider")
+
+	// ErrImageVulnerable used when deploying an image with critical vulnerabilities
+	ErrImageVulnerable = errors.New("image has critical vulnerabilities")
+	// ErrImageNotScanned used when deploying an image whose vulnerabilities are unknown
+	// while critical vulnerabilities are blocked
+	ErrImageNotScanned = errors.New("image is not scanned for vulnerabilities")
 
 	// ErrRegistryUsedByRegions used when deleting a registry that is still used by regions
 	ErrRegistryUsedByRegions = errors.New("cannot delete a registry when used by regions")
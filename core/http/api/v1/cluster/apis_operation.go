@@ -15,6 +15,7 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/horizoncd/horizon/core/controller/cluster"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	hctx "github.com/horizoncd/horizon/pkg/context"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
@@ -52,8 +54,21 @@ func (a *API) BuildDeploy(c *gin.Context) {
 		return
 	}
 
-	resp, err := a.clusterCtl.BuildDeploy(c, uint(clusterID), request)
+	ctx, err := withDeployWindowOverride(c)
 	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	resp, err := a.clusterCtl.BuildDeploy(ctx, uint(clusterID), request)
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 			if e.Source == herrors.ClusterInDB {
 				response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
@@ -215,8 +230,21 @@ func (a *API) Restart(c *gin.Context) {
 		return
 	}
 
-	resp, err := a.clusterCtl.Restart(c, uint(clusterID))
+	ctx, err := withDeployWindowOverride(c)
 	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	resp, err := a.clusterCtl.Restart(ctx, uint(clusterID))
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if perror.Cause(err) == herrors.ErrFreedClusterNotSupportedRestart {
 			response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
 			return
@@ -247,8 +275,21 @@ func (a *API) Deploy(c *gin.Context) {
 		return
 	}
 
-	resp, err := a.clusterCtl.Deploy(c, uint(clusterID), request)
+	ctx, err := withDeployWindowOverride(c)
 	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	resp, err := a.clusterCtl.Deploy(ctx, uint(clusterID), request)
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		switch e := perror.Cause(err).(type) {
 		case *herrors.HorizonErrNotFound:
 			if e.Source == herrors.ClusterInDB {
@@ -445,8 +486,21 @@ func (a *API) Rollback(c *gin.Context) {
 		return
 	}
 
-	resp, err := a.clusterCtl.Rollback(c, uint(clusterID), request)
+	ctx, err := withDeployWindowOverride(c)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	resp, err := a.clusterCtl.Rollback(ctx, uint(clusterID), request)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrDeployWindowClosed {
+			response.AbortWithRPCError(c, rpcerror.BadRequestError.WithErrMsg(err.Error()))
+			return
+		}
+		if perror.Cause(err) == herrors.ErrForbidden {
+			response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+			return
+		}
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
 			return
@@ -628,3 +682,17 @@ func (a *API) Upgrade(c *gin.Context) {
 	}
 	response.Success(c)
 }
+
+// withDeployWindowOverride returns the context with the deploy window override requested by query,
+// the override is only allowed for admins, which is checked by controller
+func withDeployWindowOverride(c *gin.Context) (context.Context, error) {
+	overrideStr := c.Query(common.ClusterQueryOverrideDeployWindow)
+	if overrideStr == "" {
+		return c, nil
+	}
+	override, err := strconv.ParseBool(overrideStr)
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(c, hctx.DeployWindowOverride, override), nil
+}
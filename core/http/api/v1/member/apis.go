@@ -439,9 +439,7 @@ func (a *API) validatePostMember(ctx context.Context,
 
 func validMemberType(memberType membermodels.MemberType) error {
 	switch memberType {
-	case membermodels.MemberUser:
-	case membermodels.MemberGroup:
-		return fmt.Errorf("this type of member is not supported yet")
+	case membermodels.MemberUser, membermodels.MemberGroup:
 	default:
 		return fmt.Errorf("invalid memberType")
 	}
@@ -42,10 +42,20 @@ const (
 	KeyCode         = "code"
 	KeyRefreshToken = "refresh_token"
 	KeyClientSecret = "client_secret"
+	KeyToken        = "token"
 
-	KeyGrantType          = "grant_type"
-	GrantTypeAuthCode     = "authorization_code"
-	GrantTypeRefreshToken = "refresh_token"
+	// PKCE, ref: rfc7636
+	KeyCodeChallenge       = "code_challenge"
+	KeyCodeChallengeMethod = "code_challenge_method"
+	KeyCodeVerifier        = "code_verifier"
+
+	// OIDC, ref: OpenID Connect Core 1.0
+	KeyNonce = "nonce"
+
+	KeyGrantType               = "grant_type"
+	GrantTypeAuthCode          = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
 
 	Authorized = "1"
 )
@@ -80,6 +90,10 @@ type AuthorizationPageParams struct {
 	Scope       string
 	ClientName  string
 	ScopeBasic  []ScopeBasic
+
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Nonce               string
 }
 
 func (a *API) HandleAuthorizationGetReq(c *gin.Context) {
@@ -143,6 +157,10 @@ func (a *API) HandleAuthorizationGetReq(c *gin.Context) {
 		Scope:       c.Query(KeyScope),
 		RedirectURL: c.Query(KeyRedirectURI),
 		ScopeBasic:  scopeInfo(),
+
+		CodeChallenge:       c.Query(KeyCodeChallenge),
+		CodeChallengeMethod: c.Query(KeyCodeChallengeMethod),
+		Nonce:               c.Query(KeyNonce),
 	}
 	authTemplate, err := template.ParseFiles(a.oauthHTMLLocation)
 	if err != nil {
@@ -206,6 +224,10 @@ func (a *API) handlerPostAuthorizationReq(c *gin.Context) {
 			State:        c.PostForm(KeyState),
 			UserIdentity: user.GetID(),
 			Request:      c.Request,
+
+			CodeChallenge:       c.PostForm(KeyCodeChallenge),
+			CodeChallengeMethod: c.PostForm(KeyCodeChallengeMethod),
+			Nonce:               c.PostForm(KeyNonce),
 		})
 		if err != nil {
 			causeErr := perror.Cause(err)
@@ -241,76 +263,130 @@ func (a *API) HandleAccessTokenReq(c *gin.Context) {
 		return
 	}
 
-	keys := []string{
-		KeyClientID,
-		KeyClientSecret,
-		KeyRedirectURI,
-	}
-	if grantType == GrantTypeAuthCode {
-		keys = append(keys, KeyCode)
-	} else if grantType == GrantTypeRefreshToken {
-		keys = append(keys, KeyRefreshToken)
-	} else {
+	var keys []string
+	switch grantType {
+	case GrantTypeAuthCode:
+		// client secret is not required for the public clients using PKCE
+		keys = []string{KeyClientID, KeyRedirectURI, KeyCode}
+	case GrantTypeRefreshToken:
+		keys = []string{KeyClientID, KeyClientSecret, KeyRedirectURI, KeyRefreshToken}
+	case GrantTypeClientCredentials:
+		keys = []string{KeyClientID, KeyClientSecret}
+	default:
 		response.AbortWithRequestError(c, common.InvalidRequestParam, "grant_type not supported")
 		return
 	}
 
 	// check post form keys exist
-	var err error
-	checkKeysExist := func(keys []string) bool {
-		for _, key := range keys {
-			if _, ok := c.GetPostForm(key); !ok {
-				err = fmt.Errorf("%s not exist", key)
-				log.Warning(c, err.Error())
-				return false
-			}
-		}
-		return true
-	}
-	if !checkKeysExist(keys) {
+	if err := checkPostFormKeysExist(c, keys); err != nil {
 		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
 		return
 	}
 
-	var tokenResponse *oauth.AccessTokenResponse
+	var (
+		tokenResponse *oauth.AccessTokenResponse
+		err           error
+	)
 	baseTokenReq := oauth.BaseTokenReq{
 		ClientID:     c.PostForm(KeyClientID),
 		ClientSecret: c.PostForm(KeyClientSecret),
 		RedirectURL:  c.PostForm(KeyRedirectURI),
 		Request:      c.Request,
 	}
-	if grantType == GrantTypeAuthCode {
+	switch grantType {
+	case GrantTypeAuthCode:
 		tokenResponse, err = a.oAuthServer.GenAccessToken(c, &oauth.AccessTokenReq{
 			BaseTokenReq: baseTokenReq,
 			Code:         c.PostForm(KeyCode),
+			CodeVerifier: c.PostForm(KeyCodeVerifier),
 		})
-	} else {
+	case GrantTypeRefreshToken:
 		tokenResponse, err = a.oAuthServer.RefreshToken(c, &oauth.RefreshTokenReq{
 			BaseTokenReq: baseTokenReq,
 			RefreshToken: c.PostForm(KeyRefreshToken),
 		})
+	default:
+		tokenResponse, err = a.oAuthServer.GenClientCredentialsToken(c, &oauth.ClientCredentialsTokenReq{
+			ClientID:     c.PostForm(KeyClientID),
+			ClientSecret: c.PostForm(KeyClientSecret),
+			Scope:        c.PostForm(KeyScope),
+			Request:      c.Request,
+		})
 	}
 	if err != nil {
-		causeErr := perror.Cause(err)
-		log.Warning(c, err.Error())
-		switch causeErr {
-		case herrors.ErrOAuthSecretNotValid, herrors.ErrOAuthReqNotValid:
-			response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
-			return
-		case herrors.ErrOAuthCodeExpired, herrors.ErrOAuthRefreshTokenExpired:
-			response.AbortWithUnauthorized(c, common.CodeExpired, err.Error())
-			return
-		default:
-			if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
-				if e.Source == herrors.OAuthInDB || e.Source == herrors.TokenInDB {
-					response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
-					return
-				}
+		abortWithOAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tokenResponse)
+}
+
+// HandleIntrospectionReq ref: rfc7662
+func (a *API) HandleIntrospectionReq(c *gin.Context) {
+	if err := checkPostFormKeysExist(c, []string{KeyClientID, KeyClientSecret, KeyToken}); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	resp, err := a.oAuthServer.IntrospectToken(c, &oauth.ClientTokenReq{
+		ClientID:     c.PostForm(KeyClientID),
+		ClientSecret: c.PostForm(KeyClientSecret),
+		Token:        c.PostForm(KeyToken),
+	})
+	if err != nil {
+		abortWithOAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// HandleRevocationReq ref: rfc7009
+func (a *API) HandleRevocationReq(c *gin.Context) {
+	if err := checkPostFormKeysExist(c, []string{KeyClientID, KeyToken}); err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+
+	if err := a.oAuthServer.RevokeToken(c, &oauth.ClientTokenReq{
+		ClientID:     c.PostForm(KeyClientID),
+		ClientSecret: c.PostForm(KeyClientSecret),
+		Token:        c.PostForm(KeyToken),
+	}); err != nil {
+		abortWithOAuthError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func checkPostFormKeysExist(c *gin.Context, keys []string) error {
+	for _, key := range keys {
+		if _, ok := c.GetPostForm(key); !ok {
+			err := fmt.Errorf("%s not exist", key)
+			log.Warning(c, err.Error())
+			return err
+		}
+	}
+	return nil
+}
+
+func abortWithOAuthError(c *gin.Context, err error) {
+	causeErr := perror.Cause(err)
+	log.Warning(c, err.Error())
+	switch causeErr {
+	case herrors.ErrOAuthSecretNotValid, herrors.ErrOAuthReqNotValid:
+		response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
+		return
+	case herrors.ErrOAuthCodeExpired, herrors.ErrOAuthRefreshTokenExpired:
+		response.AbortWithUnauthorized(c, common.CodeExpired, err.Error())
+		return
+	default:
+		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			if e.Source == herrors.OAuthInDB || e.Source == herrors.TokenInDB {
+				response.AbortWithUnauthorized(c, common.Unauthorized, err.Error())
+				return
 			}
-			response.AbortWithInternalError(c, err.Error())
-			log.Error(c, err.Error())
-			return
 		}
+		response.AbortWithInternalError(c, err.Error())
+		log.Error(c, err.Error())
+		return
 	}
-	c.JSON(http.StatusOK, tokenResponse)
 }
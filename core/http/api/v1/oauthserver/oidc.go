@@ -0,0 +1,96 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauthserver
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	oauthmanager "github.com/horizoncd/horizon/pkg/oauth/manager"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// ProviderMetadata ref: https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata
+type ProviderMetadata struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+func (a *API) HandleOIDCDiscoveryReq(c *gin.Context) {
+	provider, err := a.oAuthServer.GetOIDCProvider(c)
+	if err != nil {
+		abortWithOIDCError(c, err)
+		return
+	}
+
+	issuer := strings.TrimSuffix(provider.Issuer, "/")
+	algorithms := make([]string, 0, len(provider.JWKS.Keys))
+	for _, key := range provider.JWKS.Keys {
+		algorithms = append(algorithms, key.Algorithm)
+	}
+	c.JSON(http.StatusOK, &ProviderMetadata{
+		Issuer:                            provider.Issuer,
+		AuthorizationEndpoint:             issuer + BasicPath + AuthorizePath,
+		TokenEndpoint:                     issuer + BasicPath + AccessTokenPath,
+		IntrospectionEndpoint:             issuer + BasicPath + IntrospectPath,
+		RevocationEndpoint:                issuer + BasicPath + RevokePath,
+		JWKSURI:                           issuer + JWKSPath,
+		ScopesSupported:                   append([]string{oauthmanager.ScopeOpenID}, a.scopeService.GetAllScopeNames()...),
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{GrantTypeAuthCode, GrantTypeRefreshToken, GrantTypeClientCredentials},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  algorithms,
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_post", "none"},
+		CodeChallengeMethodsSupported: []string{oauthmanager.CodeChallengeMethodPlain,
+			oauthmanager.CodeChallengeMethodS256},
+		ClaimsSupported: []string{"iss", "sub", "aud", "exp", "iat", "nonce",
+			"name", "preferred_username", "email"},
+	})
+}
+
+// HandleJWKSReq ref: rfc7517
+func (a *API) HandleJWKSReq(c *gin.Context) {
+	provider, err := a.oAuthServer.GetOIDCProvider(c)
+	if err != nil {
+		abortWithOIDCError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, provider.JWKS)
+}
+
+func abortWithOIDCError(c *gin.Context, err error) {
+	if perror.Cause(err) == herrors.ErrOIDCNotEnabled {
+		response.AbortWithNotExistError(c, err.Error())
+		return
+	}
+	log.Error(c, err.Error())
+	response.AbortWithInternalError(c, err.Error())
+}
@@ -25,6 +25,11 @@ const (
 	BasicPath       = "/login/oauth"
 	AuthorizePath   = "/authorize"
 	AccessTokenPath = "/access_token"
+	IntrospectPath  = "/introspect"
+	RevokePath      = "/revoke"
+
+	DiscoveryPath = "/.well-known/openid-configuration"
+	JWKSPath      = "/.well-known/jwks.json"
 )
 
 func (a *API) RegisterRoute(engine *gin.Engine) {
@@ -43,7 +48,29 @@ func (a *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     AccessTokenPath,
 			Method:      http.MethodPost,
 			HandlerFunc: a.HandleAccessTokenReq,
+		}, {
+			Pattern:     IntrospectPath,
+			Method:      http.MethodPost,
+			HandlerFunc: a.HandleIntrospectionReq,
+		}, {
+			Pattern:     RevokePath,
+			Method:      http.MethodPost,
+			HandlerFunc: a.HandleRevocationReq,
 		},
 	}
 	route.RegisterRoutes(apiGroup, routes)
+
+	wellKnownGroup := engine.Group("")
+	var wellKnownRoutes = route.Routes{
+		{
+			Pattern:     DiscoveryPath,
+			Method:      http.MethodGet,
+			HandlerFunc: a.HandleOIDCDiscoveryReq,
+		}, {
+			Pattern:     JWKSPath,
+			Method:      http.MethodGet,
+			HandlerFunc: a.HandleJWKSReq,
+		},
+	}
+	route.RegisterRoutes(wellKnownGroup, wellKnownRoutes)
 }
@@ -192,6 +192,12 @@ func (a *API) CreateTemplate(c *gin.Context) {
 
 	_, err = a.templateCtl.CreateRelease(c, template.ID, createRequest.CreateReleaseRequest)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrChartValidationFailed {
+			// the template is kept with the failed release, which could be synced again after the chart is fixed
+			log.WithFiled(c, "op", op).Infof("chart of release %s is invalid: %s", createRequest.Name, err)
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("%s", err)))
+			return
+		}
 		defer func() { _ = a.templateCtl.DeleteTemplate(c, template.ID) }()
 
 		if perror.Cause(err) == herrors.ErrParamInvalid {
@@ -388,6 +394,11 @@ func (a *API) CreateRelease(c *gin.Context) {
 
 	var release *templatectl.Release
 	if release, err = a.templateCtl.CreateRelease(c, uint(templateID), createRequest); err != nil {
+		if perror.Cause(err) == herrors.ErrChartValidationFailed {
+			log.WithFiled(c, "op", op).Infof("chart of release %s is invalid: %s", createRequest.Name, err)
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("%s", err)))
+			return
+		}
 		if perror.Cause(err) == herrors.ErrParamInvalid {
 			log.WithFiled(c, "op", op).Infof("could not parse gitlab url: %s", err)
 			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("failed parsing gitlab URL: %s", err)))
@@ -557,6 +568,11 @@ func (a *API) SyncReleaseToRepo(c *gin.Context) {
 	}
 
 	if err = a.templateCtl.SyncReleaseToRepo(c, uint(releaseID)); err != nil {
+		if perror.Cause(err) == herrors.ErrChartValidationFailed {
+			log.WithFiled(c, "op", op).Infof("chart of release %d is invalid: %s", releaseID, err)
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("%s", err)))
+			return
+		}
 		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
 			log.WithFiled(c, "op", op).Infof("release with ID %d not found", releaseID)
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(fmt.Sprintf("not found: %s", err)))
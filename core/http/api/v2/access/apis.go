@@ -16,9 +16,14 @@ package access
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/controller/access"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
 	"github.com/horizoncd/horizon/pkg/util/log"
@@ -58,3 +63,66 @@ func (a *API) AccessReview(c *gin.Context) {
 
 	response.SuccessWithData(c, reviewResp)
 }
+
+func (a *API) Explain(c *gin.Context) {
+	const op = "access: explain"
+
+	var request access.ExplainRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(fmt.Sprintf("request body is invalid, err: %v", err)))
+		return
+	}
+
+	resp, err := a.accessCtl.Explain(c, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) ListAudits(c *gin.Context) {
+	const op = "access: list audits"
+	keywords := q.KeyWords{}
+	if userIDStr := c.Query(common.AccessAuditQueryByUser); userIDStr != "" {
+		userID, err := strconv.ParseUint(userIDStr, 10, 0)
+		if err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.
+				WithErrMsgf("invalid user id: %s", userIDStr))
+			return
+		}
+		keywords[common.AccessAuditQueryByUser] = uint(userID)
+	}
+	if resource := c.Query(common.AccessAuditQueryByResource); resource != "" {
+		keywords[common.AccessAuditQueryByResource] = resource
+	}
+	if name := c.Query(common.AccessAuditQueryByName); name != "" {
+		keywords[common.AccessAuditQueryByName] = name
+	}
+
+	query := q.New(keywords).WithPagination(c)
+	items, total, err := a.accessCtl.ListAudits(c, query)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Items: items,
+		Total: total,
+	})
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if perror.Cause(err) == herrors.ErrParamInvalid {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	} else if perror.Cause(err) == herrors.ErrForbidden {
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	} else if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
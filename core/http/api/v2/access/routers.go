@@ -31,7 +31,23 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     "/accessreview",
 			HandlerFunc: api.AccessReview,
 		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     "/accessreview/explain",
+			HandlerFunc: api.Explain,
+		},
 	}
 
 	route.RegisterRoutes(frontGroup, frontRoutes)
+
+	coreGroup := engine.Group("/apis/core/v2")
+	var coreRoutes = route.Routes{
+		{
+			Method:      http.MethodGet,
+			Pattern:     "/accessaudits",
+			HandlerFunc: api.ListAudits,
+		},
+	}
+
+	route.RegisterRoutes(coreGroup, coreRoutes)
 }
@@ -0,0 +1,181 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analytics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/analytics"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_environmentParam = "environment"
+	_sinceQuery       = "since"
+	_untilQuery       = "until"
+	_clusterQuery     = "cluster"
+	_intervalQuery    = "interval"
+	_limitQuery       = "limit"
+)
+
+type API struct {
+	analyticsCtl analytics.Controller
+}
+
+func NewAPI(ctl analytics.Controller) *API {
+	return &API{
+		analyticsCtl: ctl,
+	}
+}
+
+func (a *API) GetPipelineTrends(c *gin.Context) {
+	const op = "analytics: get pipeline trends"
+	applicationID, ok := parseID(c, common.ParamApplicationID)
+	if !ok {
+		return
+	}
+	period, ok := parsePeriod(c)
+	if !ok {
+		return
+	}
+
+	resp, err := a.analyticsCtl.GetPipelineTrends(c, applicationID, &analytics.TrendsRequest{
+		Period:   *period,
+		Cluster:  c.Query(_clusterQuery),
+		Interval: c.Query(_intervalQuery),
+	})
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) GetPipelineHotspots(c *gin.Context) {
+	const op = "analytics: get pipeline hotspots"
+	applicationID, ok := parseID(c, common.ParamApplicationID)
+	if !ok {
+		return
+	}
+	period, ok := parsePeriod(c)
+	if !ok {
+		return
+	}
+	var limit int
+	if limitStr := c.Query(_limitQuery); limitStr != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitStr); err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.
+				WithErrMsgf("invalid %s: %s", _limitQuery, limitStr))
+			return
+		}
+	}
+
+	resp, err := a.analyticsCtl.GetPipelineHotspots(c, applicationID, &analytics.HotspotsRequest{
+		Period:  *period,
+		Cluster: c.Query(_clusterQuery),
+		Limit:   limit,
+	})
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) GetApplicationDORAMetrics(c *gin.Context) {
+	a.getDORAMetrics(c, "analytics: get application dora metrics", common.ParamApplicationID,
+		a.analyticsCtl.GetApplicationDORAMetrics)
+}
+
+func (a *API) GetGroupDORAMetrics(c *gin.Context) {
+	a.getDORAMetrics(c, "analytics: get group dora metrics", common.ParamGroupID,
+		a.analyticsCtl.GetGroupDORAMetrics)
+}
+
+func (a *API) GetEnvironmentDORAMetrics(c *gin.Context) {
+	a.getDORAMetrics(c, "analytics: get environment dora metrics", _environmentParam,
+		a.analyticsCtl.GetEnvironmentDORAMetrics)
+}
+
+func (a *API) getDORAMetrics(c *gin.Context, op, param string,
+	get func(ctx context.Context, id uint, period *analytics.Period) (*analytics.DORAMetrics, error)) {
+	id, ok := parseID(c, param)
+	if !ok {
+		return
+	}
+	period, ok := parsePeriod(c)
+	if !ok {
+		return
+	}
+
+	resp, err := get(c, id, period)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func parseID(c *gin.Context, param string) (uint, bool) {
+	idStr := c.Param(param)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid %s: %s", param, idStr))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parsePeriod parses the period in RFC3339, the zero values are defaulted by the controller
+func parsePeriod(c *gin.Context) (*analytics.Period, bool) {
+	period := &analytics.Period{}
+	for query, t := range map[string]*time.Time{_sinceQuery: &period.Since, _untilQuery: &period.Until} {
+		value := c.Query(query)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			response.AbortWithRPCError(c, rpcerror.ParamError.
+				WithErrMsgf("invalid %s: %s", query, value))
+			return nil, false
+		}
+		*t = parsed
+	}
+	return period, true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if perror.Cause(err) == herrors.ErrParamInvalid {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	} else if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
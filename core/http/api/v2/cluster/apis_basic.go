@@ -212,6 +212,17 @@ func (a *API) Create(c *gin.Context) {
 		}
 	}
 
+	dryRun := false
+	dryRunStr := c.Request.URL.Query().Get(common.ClusterQueryDryRun)
+	if dryRunStr != "" {
+		dryRun, err = strconv.ParseBool(dryRunStr)
+		if err != nil {
+			response.AbortWithRequestError(c, common.InvalidRequestParam,
+				fmt.Sprintf("dryRun is invalid, err: %v", err))
+			return
+		}
+	}
+
 	extraOwners := c.QueryArray(common.ClusterQueryExtraOwner)
 
 	var request *cluster.CreateClusterRequestV2
@@ -235,13 +246,19 @@ func (a *API) Create(c *gin.Context) {
 		request.ExtraMembers[extraOwner] = role.Owner
 	}
 
-	resp, err := a.clusterCtl.CreateClusterV2(c, &cluster.CreateClusterParamsV2{
+	params := &cluster.CreateClusterParamsV2{
 		CreateClusterRequestV2: request,
 		ApplicationID:          uint(applicationID),
 		Environment:            environment,
 		Region:                 region,
 		MergePatch:             mergePatch,
-	})
+	}
+	var resp interface{}
+	if dryRun {
+		resp, err = a.clusterCtl.DryRunCreateClusterV2(c, params)
+	} else {
+		resp, err = a.clusterCtl.CreateClusterV2(c, params)
+	}
 	if err != nil {
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ApplicationInDB {
 			log.WithFiled(c, "op", op).Warningf("err = %+v, request = %+v", err, request)
@@ -284,13 +301,29 @@ func (a *API) Update(c *gin.Context) {
 		}
 	}
 
+	dryRun := false
+	dryRunStr := c.Request.URL.Query().Get(common.ClusterQueryDryRun)
+	if dryRunStr != "" {
+		dryRun, err = strconv.ParseBool(dryRunStr)
+		if err != nil {
+			response.AbortWithRequestError(c, common.InvalidRequestParam,
+				fmt.Sprintf("dryRun is invalid, err: %v", err))
+			return
+		}
+	}
+
 	var request *cluster.UpdateClusterRequestV2
 	if err := c.ShouldBindJSON(&request); err != nil {
 		response.AbortWithRequestError(c, common.InvalidRequestBody,
 			fmt.Sprintf("request body is invalid, err: %v", err))
 		return
 	}
-	err = a.clusterCtl.UpdateClusterV2(c, uint(clusterID), request, mergePatch)
+	var resp *cluster.DryRunClusterResponseV2
+	if dryRun {
+		resp, err = a.clusterCtl.DryRunUpdateClusterV2(c, uint(clusterID), request, mergePatch)
+	} else {
+		err = a.clusterCtl.UpdateClusterV2(c, uint(clusterID), request, mergePatch)
+	}
 	if err != nil {
 		if e, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok && e.Source == herrors.ClusterInDB {
 			response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
@@ -307,6 +340,10 @@ func (a *API) Update(c *gin.Context) {
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
 		return
 	}
+	if dryRun {
+		response.SuccessWithData(c, resp)
+		return
+	}
 	response.Success(c)
 }
 
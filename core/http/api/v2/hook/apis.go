@@ -0,0 +1,66 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hook
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/controller/hook"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/hook/models"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_handlerQuery = "handler"
+	_statusQuery  = "status"
+)
+
+type API struct {
+	hookCtl hook.Controller
+}
+
+func NewAPI(ctl hook.Controller) *API {
+	return &API{
+		hookCtl: ctl,
+	}
+}
+
+func (a *API) ListDeliveries(c *gin.Context) {
+	const op = "hook: list deliveries"
+	query := &models.DeliveryQuery{
+		Handler: c.Query(_handlerQuery),
+		Status:  c.Query(_statusQuery),
+	}
+
+	total, deliveries, err := a.hookCtl.ListDeliveries(c, query, q.New(nil).WithPagination(c))
+	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
+		log.WithFiled(c, "op", op).Errorf("%+v", err)
+		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Items: deliveries,
+		Total: int64(total),
+	})
+}
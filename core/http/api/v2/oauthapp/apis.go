@@ -55,6 +55,10 @@ func (a *API) CreateOauthApp(c *gin.Context) {
 	}
 	resp, err := a.oauthAppController.Create(c, uint(groupID), *req)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
 		log.Errorf(c, "%s err, error = %s", op, err.Error())
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
 		return
@@ -118,6 +122,10 @@ func (a *API) UpdateOauthApp(c *gin.Context) {
 	}
 	oauthApp, err := a.oauthAppController.Update(c, *req)
 	if err != nil {
+		if perror.Cause(err) == herrors.ErrParamInvalid {
+			response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+			return
+		}
 		log.Errorf(c, "%s err, error = %s", op, err.Error())
 		response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
 		return
@@ -17,6 +17,7 @@ package pipelinerun
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	prctl "github.com/horizoncd/horizon/core/controller/pipelinerun"
@@ -38,6 +39,11 @@ const (
 	_clusterIDParam     = "clusterID"
 	_canRollbackParam   = "canRollback"
 	_pipelineStatus     = "status"
+	_taskParam          = "task"
+	_stepParam          = "step"
+	_keywordParam       = "keyword"
+	_sinceParam         = "since"
+	_untilParam         = "until"
 )
 
 type API struct {
@@ -301,6 +307,113 @@ func (a *API) ListPrMessages(c *gin.Context) {
 	})
 }
 
+func (a *API) GetStepLogs(c *gin.Context) {
+	pageNumber, pageSize, err := parseLinePageParam(c)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	a.withPipelinerunID(c, func(prID uint) {
+		resp, err := a.prCtl.GetStepLogs(c, prID, &prctl.StepLogRequest{
+			Task:       c.Query(_taskParam),
+			Step:       c.Query(_stepParam),
+			Keyword:    c.Query(_keywordParam),
+			PageNumber: pageNumber,
+			PageSize:   pageSize,
+		})
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		response.SuccessWithData(c, resp)
+	})
+}
+
+func (a *API) SearchClusterStepLogs(c *gin.Context) {
+	clusterID, err := strconv.ParseUint(c.Param(_clusterIDParam), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	a.searchStepLogs(c, func(request *prctl.SearchStepLogsRequest) (int, []*prctl.StepLogMatch, error) {
+		return a.prCtl.SearchClusterStepLogs(c, uint(clusterID), request)
+	})
+}
+
+func (a *API) SearchApplicationStepLogs(c *gin.Context) {
+	applicationID, err := strconv.ParseUint(c.Param(common.ParamApplicationID), 10, 0)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	a.searchStepLogs(c, func(request *prctl.SearchStepLogsRequest) (int, []*prctl.StepLogMatch, error) {
+		return a.prCtl.SearchApplicationStepLogs(c, uint(applicationID), request)
+	})
+}
+
+func (a *API) searchStepLogs(c *gin.Context,
+	search func(request *prctl.SearchStepLogsRequest) (int, []*prctl.StepLogMatch, error)) {
+	pageNumber, pageSize, err := request.GetPageParam(c)
+	if err != nil {
+		response.AbortWithRequestError(c, common.InvalidRequestParam, err.Error())
+		return
+	}
+	req := &prctl.SearchStepLogsRequest{
+		Keyword:    c.Query(_keywordParam),
+		Task:       c.Query(_taskParam),
+		Step:       c.Query(_stepParam),
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+	}
+	for param, t := range map[string]*time.Time{_sinceParam: &req.Since, _untilParam: &req.Until} {
+		if value := c.Query(param); value != "" {
+			if *t, err = time.Parse(time.RFC3339, value); err != nil {
+				response.AbortWithRequestError(c, common.InvalidRequestParam,
+					fmt.Sprintf("invalid %s: %s", param, value))
+				return
+			}
+		}
+	}
+
+	total, matches, err := search(req)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Total: int64(total),
+		Items: matches,
+	})
+}
+
+// parseLinePageParam parses the page params of log lines, which allow larger pages than the common ones
+func parseLinePageParam(c *gin.Context) (int, int, error) {
+	var pageNumber, pageSize int
+	var err error
+	if value := c.Query(common.PageNumber); value != "" {
+		if pageNumber, err = strconv.Atoi(value); err != nil || pageNumber <= 0 {
+			return 0, 0, fmt.Errorf("invalid param, pageNumber: %s", value)
+		}
+	}
+	if value := c.Query(common.PageSize); value != "" {
+		if pageSize, err = strconv.Atoi(value); err != nil || pageSize <= 0 {
+			return 0, 0, fmt.Errorf("invalid param, pageSize: %s", value)
+		}
+	}
+	return pageNumber, pageSize, nil
+}
+
+func abortWithError(c *gin.Context, err error) {
+	if perror.Cause(err) == herrors.ErrParamInvalid {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	} else if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	response.AbortWithError(c, err)
+}
+
 func (a *API) withPipelinerunID(c *gin.Context, f func(pipelineRunID uint)) {
 	idStr := c.Param(_pipelinerunIDParam)
 	id, err := strconv.ParseUint(idStr, 10, 0)
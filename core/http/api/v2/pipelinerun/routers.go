@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/pkg/server/route"
 
 	"github.com/gin-gonic/gin"
@@ -31,6 +32,18 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Method:      http.MethodGet,
 			Pattern:     fmt.Sprintf("/pipelineruns/:%v/log", _pipelinerunIDParam),
 			HandlerFunc: api.Log,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/pipelineruns/:%v/steplogs", _pipelinerunIDParam),
+			HandlerFunc: api.GetStepLogs,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/clusters/:%v/steplogs", _clusterIDParam),
+			HandlerFunc: api.SearchClusterStepLogs,
+		}, {
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/applications/:%v/steplogs", common.ParamApplicationID),
+			HandlerFunc: api.SearchApplicationStepLogs,
 		}, {
 			Method:      http.MethodPost,
 			Pattern:     fmt.Sprintf("/pipelineruns/:%v/stop", _pipelinerunIDParam),
@@ -0,0 +1,240 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasetrain
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/releasetrain"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+type API struct {
+	trainCtl releasetrain.Controller
+}
+
+func NewAPI(ctl releasetrain.Controller) *API {
+	return &API{
+		trainCtl: ctl,
+	}
+}
+
+func (a *API) Create(c *gin.Context) {
+	const op = "release train: create"
+	applicationID, ok := parseID(c, common.ParamApplicationID)
+	if !ok {
+		return
+	}
+
+	var request releasetrain.CreateTrainRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	resp, err := a.trainCtl.CreateTrain(c, applicationID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) List(c *gin.Context) {
+	const op = "release train: list"
+	applicationID, ok := parseID(c, common.ParamApplicationID)
+	if !ok {
+		return
+	}
+
+	items, total, err := a.trainCtl.ListTrains(c, applicationID, q.New(nil).WithPagination(c))
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Items: items,
+		Total: total,
+	})
+}
+
+func (a *API) Get(c *gin.Context) {
+	const op = "release train: get"
+	id, ok := parseID(c, _trainIDParam)
+	if !ok {
+		return
+	}
+
+	resp, err := a.trainCtl.GetTrain(c, id)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Update(c *gin.Context) {
+	const op = "release train: update"
+	id, ok := parseID(c, _trainIDParam)
+	if !ok {
+		return
+	}
+
+	var request releasetrain.CreateTrainRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	resp, err := a.trainCtl.UpdateTrain(c, id, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Delete(c *gin.Context) {
+	const op = "release train: delete"
+	id, ok := parseID(c, _trainIDParam)
+	if !ok {
+		return
+	}
+
+	if err := a.trainCtl.DeleteTrain(c, id); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) StartRun(c *gin.Context) {
+	const op = "release train: start run"
+	id, ok := parseID(c, _trainIDParam)
+	if !ok {
+		return
+	}
+
+	var request releasetrain.StartRunRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	resp, err := a.trainCtl.StartRun(c, id, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) ListRuns(c *gin.Context) {
+	const op = "release train: list runs"
+	id, ok := parseID(c, _trainIDParam)
+	if !ok {
+		return
+	}
+
+	items, total, err := a.trainCtl.ListRuns(c, id, q.New(nil).WithPagination(c))
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Items: items,
+		Total: total,
+	})
+}
+
+func (a *API) GetRun(c *gin.Context) {
+	const op = "release train: get run"
+	id, ok := parseID(c, _runIDParam)
+	if !ok {
+		return
+	}
+
+	resp, err := a.trainCtl.GetRun(c, id)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) ApproveRun(c *gin.Context) {
+	const op = "release train: approve run"
+	id, ok := parseID(c, _runIDParam)
+	if !ok {
+		return
+	}
+
+	if err := a.trainCtl.ApproveRun(c, id); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) CancelRun(c *gin.Context) {
+	const op = "release train: cancel run"
+	id, ok := parseID(c, _runIDParam)
+	if !ok {
+		return
+	}
+
+	if err := a.trainCtl.CancelRun(c, id); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func parseID(c *gin.Context, param string) (uint, bool) {
+	idStr := c.Param(param)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid %s: %s", param, idStr))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if perror.Cause(err) == herrors.ErrParamInvalid {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	} else if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	} else if perror.Cause(err) == herrors.ErrForbidden {
+		response.AbortWithRPCError(c, rpcerror.ForbiddenError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
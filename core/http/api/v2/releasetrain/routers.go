@@ -0,0 +1,87 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasetrain
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+const (
+	_trainIDParam = "trainID"
+	_runIDParam   = "runID"
+)
+
+func (a *API) RegisterRoute(engine *gin.Engine) {
+	group := engine.Group("/apis/core/v2")
+	var routers = route.Routes{
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/applications/:%v/releasetrains", common.ParamApplicationID),
+			HandlerFunc: a.Create,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/applications/:%v/releasetrains", common.ParamApplicationID),
+			HandlerFunc: a.List,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/releasetrains/:%v", _trainIDParam),
+			HandlerFunc: a.Get,
+		},
+		{
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/releasetrains/:%v", _trainIDParam),
+			HandlerFunc: a.Update,
+		},
+		{
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/releasetrains/:%v", _trainIDParam),
+			HandlerFunc: a.Delete,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/releasetrains/:%v/runs", _trainIDParam),
+			HandlerFunc: a.StartRun,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/releasetrains/:%v/runs", _trainIDParam),
+			HandlerFunc: a.ListRuns,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/releasetrainruns/:%v", _runIDParam),
+			HandlerFunc: a.GetRun,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/releasetrainruns/:%v/approve", _runIDParam),
+			HandlerFunc: a.ApproveRun,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/releasetrainruns/:%v/cancel", _runIDParam),
+			HandlerFunc: a.CancelRun,
+		},
+	}
+	route.RegisterRoutes(group, routers)
+}
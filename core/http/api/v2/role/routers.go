@@ -15,12 +15,15 @@
 package role
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/horizoncd/horizon/pkg/server/route"
 )
 
+const _roleNameParam = "roleName"
+
 // RegisterRoutes register routes
 func (api *API) RegisterRoute(engine *gin.Engine) {
 	apiGroup := engine.Group("/apis/core/v2")
@@ -31,6 +34,26 @@ func (api *API) RegisterRoute(engine *gin.Engine) {
 			Pattern:     "/roles",
 			HandlerFunc: api.ListRole,
 		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     "/roles",
+			HandlerFunc: api.CreateRole,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/roles/:%v", _roleNameParam),
+			HandlerFunc: api.GetRole,
+		},
+		{
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/roles/:%v", _roleNameParam),
+			HandlerFunc: api.UpdateRole,
+		},
+		{
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/roles/:%v", _roleNameParam),
+			HandlerFunc: api.DeleteRole,
+		},
 	}
 	route.RegisterRoutes(apiGroup, routes)
 }
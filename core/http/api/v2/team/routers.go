@@ -0,0 +1,76 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package team
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+const (
+	_teamIDParam = "teamID"
+	_userIDParam = "userID"
+)
+
+func (a *API) RegisterRoute(engine *gin.Engine) {
+	group := engine.Group("/apis/core/v2")
+	var routers = route.Routes{
+		{
+			Method:      http.MethodPost,
+			Pattern:     "/teams",
+			HandlerFunc: a.Create,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     "/teams",
+			HandlerFunc: a.List,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/teams/:%v", _teamIDParam),
+			HandlerFunc: a.Get,
+		},
+		{
+			Method:      http.MethodPut,
+			Pattern:     fmt.Sprintf("/teams/:%v", _teamIDParam),
+			HandlerFunc: a.Update,
+		},
+		{
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/teams/:%v", _teamIDParam),
+			HandlerFunc: a.Delete,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/teams/:%v/members", _teamIDParam),
+			HandlerFunc: a.ListMembers,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/teams/:%v/members", _teamIDParam),
+			HandlerFunc: a.PostMember,
+		},
+		{
+			Method:      http.MethodDelete,
+			Pattern:     fmt.Sprintf("/teams/:%v/members/:%v", _teamIDParam, _userIDParam),
+			HandlerFunc: a.RemoveMember,
+		},
+	}
+	route.RegisterRoutes(group, routers)
+}
@@ -0,0 +1,181 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatemigration
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/core/controller/templatemigration"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/server/response"
+	"github.com/horizoncd/horizon/pkg/server/rpcerror"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+type API struct {
+	migrationCtl templatemigration.Controller
+}
+
+func NewAPI(ctl templatemigration.Controller) *API {
+	return &API{
+		migrationCtl: ctl,
+	}
+}
+
+func (a *API) Preview(c *gin.Context) {
+	const op = "template migration: preview"
+	templateID, ok := parseID(c, _templateIDParam)
+	if !ok {
+		return
+	}
+
+	var request templatemigration.CreateMigrationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	resp, err := a.migrationCtl.Preview(c, templateID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Create(c *gin.Context) {
+	const op = "template migration: create"
+	templateID, ok := parseID(c, _templateIDParam)
+	if !ok {
+		return
+	}
+
+	var request templatemigration.CreateMigrationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid request body, err: %s", err.Error()))
+		return
+	}
+
+	resp, err := a.migrationCtl.Create(c, templateID, &request)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) List(c *gin.Context) {
+	const op = "template migration: list"
+	templateID, ok := parseID(c, _templateIDParam)
+	if !ok {
+		return
+	}
+
+	items, total, err := a.migrationCtl.List(c, templateID, q.New(nil).WithPagination(c))
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, response.DataWithTotal{
+		Items: items,
+		Total: total,
+	})
+}
+
+func (a *API) Get(c *gin.Context) {
+	const op = "template migration: get"
+	id, ok := parseID(c, _migrationIDParam)
+	if !ok {
+		return
+	}
+
+	resp, err := a.migrationCtl.Get(c, id)
+	if err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.SuccessWithData(c, resp)
+}
+
+func (a *API) Pause(c *gin.Context) {
+	const op = "template migration: pause"
+	id, ok := parseID(c, _migrationIDParam)
+	if !ok {
+		return
+	}
+
+	if err := a.migrationCtl.Pause(c, id); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) Resume(c *gin.Context) {
+	const op = "template migration: resume"
+	id, ok := parseID(c, _migrationIDParam)
+	if !ok {
+		return
+	}
+
+	if err := a.migrationCtl.Resume(c, id); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func (a *API) Abort(c *gin.Context) {
+	const op = "template migration: abort"
+	id, ok := parseID(c, _migrationIDParam)
+	if !ok {
+		return
+	}
+
+	if err := a.migrationCtl.Abort(c, id); err != nil {
+		abortWithError(c, op, err)
+		return
+	}
+	response.Success(c)
+}
+
+func parseID(c *gin.Context, param string) (uint, bool) {
+	idStr := c.Param(param)
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		response.AbortWithRPCError(c, rpcerror.ParamError.
+			WithErrMsgf("invalid %s: %s", param, idStr))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func abortWithError(c *gin.Context, op string, err error) {
+	if perror.Cause(err) == herrors.ErrParamInvalid {
+		response.AbortWithRPCError(c, rpcerror.ParamError.WithErrMsg(err.Error()))
+		return
+	} else if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+		response.AbortWithRPCError(c, rpcerror.NotFoundError.WithErrMsg(err.Error()))
+		return
+	}
+	log.WithFiled(c, "op", op).Errorf("%+v", err)
+	response.AbortWithRPCError(c, rpcerror.InternalError.WithErrMsg(err.Error()))
+}
@@ -0,0 +1,71 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatemigration
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/horizoncd/horizon/pkg/server/route"
+)
+
+const (
+	_templateIDParam  = "templateID"
+	_migrationIDParam = "migrationID"
+)
+
+func (a *API) RegisterRoute(engine *gin.Engine) {
+	group := engine.Group("/apis/core/v2")
+	var routers = route.Routes{
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/templates/:%v/migrations/preview", _templateIDParam),
+			HandlerFunc: a.Preview,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/templates/:%v/migrations", _templateIDParam),
+			HandlerFunc: a.Create,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/templates/:%v/migrations", _templateIDParam),
+			HandlerFunc: a.List,
+		},
+		{
+			Method:      http.MethodGet,
+			Pattern:     fmt.Sprintf("/templatemigrations/:%v", _migrationIDParam),
+			HandlerFunc: a.Get,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/templatemigrations/:%v/pause", _migrationIDParam),
+			HandlerFunc: a.Pause,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/templatemigrations/:%v/resume", _migrationIDParam),
+			HandlerFunc: a.Resume,
+		},
+		{
+			Method:      http.MethodPost,
+			Pattern:     fmt.Sprintf("/templatemigrations/:%v/abort", _migrationIDParam),
+			HandlerFunc: a.Abort,
+		},
+	}
+	route.RegisterRoutes(group, routers)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/horizoncd/horizon/core/cmd"
 
 	// for image registry
+	_ "github.com/horizoncd/horizon/pkg/cluster/registry/dockerregistry"
 	_ "github.com/horizoncd/horizon/pkg/cluster/registry/harbor/v1"
 	_ "github.com/horizoncd/horizon/pkg/cluster/registry/harbor/v2"
 
@@ -29,6 +30,7 @@ import (
 
 	// for template repo
 	_ "github.com/horizoncd/horizon/pkg/templaterepo/chartmuseumbase"
+	_ "github.com/horizoncd/horizon/pkg/templaterepo/oci"
 
 	// for k8s workload
 	_ "github.com/horizoncd/horizon/pkg/workload/deployment"
@@ -2,24 +2,38 @@ package admission
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/controller/application"
+	"github.com/horizoncd/horizon/core/controller/cluster"
+	"github.com/horizoncd/horizon/core/controller/group"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/core/middleware"
 	admissionwebhook "github.com/horizoncd/horizon/pkg/admission"
 	admissionmodels "github.com/horizoncd/horizon/pkg/admission/models"
 	"github.com/horizoncd/horizon/pkg/auth"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/server/response"
 	"github.com/horizoncd/horizon/pkg/server/rpcerror"
 	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
-// Middleware to validate and mutate admission request
-func Middleware(skippers ...middleware.Skipper) gin.HandlerFunc {
+// Middleware to validate and mutate admission request, the current resource is loaded
+// as the old object of update and delete requests
+func Middleware(applicationCtl application.Controller, clusterCtl cluster.Controller,
+	groupCtl group.Controller, skippers ...middleware.Skipper) gin.HandlerFunc {
+	loader := &oldObjectLoader{
+		applicationCtl: applicationCtl,
+		clusterCtl:     clusterCtl,
+		groupCtl:       groupCtl,
+	}
 	return middleware.New(func(c *gin.Context) {
 		// get auth record
 		record, ok := c.Get(common.ContextAuthRecord)
@@ -74,6 +88,30 @@ func Middleware(skippers ...middleware.Skipper) gin.HandlerFunc {
 			Object:      object,
 			Options:     options,
 		}
+		admissionRequest.OldObject, err = loader.load(c, admissionRequest)
+		if err != nil {
+			log.Errorf(c, "failed to load the old object: %v", err)
+			response.AbortWithRPCError(c,
+				rpcerror.InternalError.WithErrMsg(fmt.Sprintf("load the old object failed, err: %v", err)))
+			return
+		}
+		// mutating webhooks run before validating ones, and the patched object replaces the request body
+		patched, err := admissionwebhook.Mutating(c, admissionRequest)
+		if err != nil {
+			response.AbortWithRPCError(c,
+				rpcerror.ParamError.WithErrMsg(fmt.Sprintf("admission mutating failed: %v", err)))
+			return
+		}
+		if patched {
+			bodyBytes, err = json.Marshal(admissionRequest.Object)
+			if err != nil {
+				response.AbortWithRPCError(c,
+					rpcerror.ParamError.WithErrMsg(fmt.Sprintf("marshal mutated request body failed, err: %v", err)))
+				return
+			}
+			c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
+			c.Request.ContentLength = int64(len(bodyBytes))
+		}
 		if err := admissionwebhook.Validating(c, admissionRequest); err != nil {
 			response.AbortWithRPCError(c,
 				rpcerror.ParamError.WithErrMsg(fmt.Sprintf("admission validating failed: %v", err)))
@@ -82,3 +120,60 @@ func Middleware(skippers ...middleware.Skipper) gin.HandlerFunc {
 		c.Next()
 	}, skippers...)
 }
+
+type oldObjectLoader struct {
+	applicationCtl application.Controller
+	clusterCtl     cluster.Controller
+	groupCtl       group.Controller
+}
+
+// load returns the current resource in the form of its get api, nil if the request
+// is not an update or delete of application, cluster or group
+func (l *oldObjectLoader) load(ctx context.Context, req *admissionwebhook.Request) (interface{}, error) {
+	if req.SubResource != "" ||
+		!(req.Operation.Eq(admissionmodels.OperationUpdate) || req.Operation.Eq(admissionmodels.OperationDelete)) {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(req.Name, 10, 0)
+	if err != nil {
+		return nil, nil
+	}
+
+	var resource interface{}
+	switch req.Resource {
+	case common.ResourceApplication:
+		if req.Version == "v2" {
+			resource, err = l.applicationCtl.GetApplicationV2(ctx, uint(id))
+		} else {
+			resource, err = l.applicationCtl.GetApplication(ctx, uint(id))
+		}
+	case common.ResourceCluster:
+		if req.Version == "v2" {
+			resource, err = l.clusterCtl.GetClusterV2(ctx, uint(id))
+		} else {
+			resource, err = l.clusterCtl.GetCluster(ctx, uint(id))
+		}
+	case common.ResourceGroup:
+		resource, err = l.groupCtl.GetByID(ctx, uint(id))
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		// let the api tell the resource is not found
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// decode it the same as the object in request body
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	var oldObject interface{}
+	if err := json.Unmarshal(data, &oldObject); err != nil {
+		return nil, err
+	}
+	return oldObject, nil
+}
@@ -19,6 +19,8 @@ import (
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/core/middleware"
+	"github.com/horizoncd/horizon/pkg/accessaudit/models"
+	"github.com/horizoncd/horizon/pkg/accessaudit/recorder"
 	"github.com/horizoncd/horizon/pkg/auth"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/rbac"
@@ -27,7 +29,8 @@ import (
 	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
-func Middleware(authorizer rbac.Authorizer, skipMatchers ...middleware.Skipper) gin.HandlerFunc {
+func Middleware(authorizer rbac.Authorizer, auditRecorder recorder.Recorder,
+	skipMatchers ...middleware.Skipper) gin.HandlerFunc {
 	return middleware.New(func(c *gin.Context) {
 		// get user
 		currentUser, err := common.UserFromContext(c)
@@ -67,6 +70,17 @@ func Middleware(authorizer rbac.Authorizer, skipMatchers ...middleware.Skipper)
 		}
 		if decision == auth.DecisionDeny {
 			log.Warningf(c, "denied request with reason = %s", reason)
+			// the denial is audited for troubleshooting asynchronously, it should not slow down the response
+			auditRecorder.Record(c, &models.AccessAudit{
+				UserID:      currentUser.GetID(),
+				UserName:    currentUser.GetName(),
+				Verb:        authRecord.Verb,
+				Resource:    authRecord.Resource,
+				SubResource: authRecord.SubResource,
+				Name:        authRecord.Name,
+				Path:        authRecord.Path,
+				Reason:      reason,
+			})
 			response.AbortWithForbiddenError(c, common.Forbidden, reason)
 			return
 		}
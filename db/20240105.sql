@@ -73,7 +73,7 @@ CREATE TABLE `tb_group`
     `name`             varchar(128)        NOT NULL DEFAULT '',
     `path`             varchar(32)         NOT NULL DEFAULT '',
     `description`      varchar(256)                 DEFAULT NULL,
-    `visibility_level` varchar(16)         NOT NULL COMMENT 'public or private',
+    `visibility_level` varchar(16)         NOT NULL DEFAULT 'public' COMMENT 'public, internal or private',
     `parent_id`        bigint(20)          NOT NULL DEFAULT '0' COMMENT 'ID of the parent group',
     `traversal_ids`    varchar(32)         NOT NULL DEFAULT '' COMMENT 'ID path from the root, like 1,2,3',
     `region_selector`  varchar(512)        NOT NULL DEFAULT '' COMMENT 'used for filtering kubernetes',
@@ -147,7 +147,7 @@ CREATE TABLE `tb_template_release`
     `only_owner`    tinyint(1)          NOT NULL DEFAULT '0',
     `chart_version` varchar(256)        NOT NULL DEFAULT '' COMMENT 'chart version on template repository',
     `sync_status`   varchar(64)         NOT NULL DEFAULT 'status_unknown' COMMENT 'shows sync status',
-    `failed_reason` varchar(2048)       NOT NULL DEFAULT '' COMMENT 'failed reason at last time',
+    `failed_reason` text                NOT NULL COMMENT 'failed reason at last time, or the failures found by validation in json',
     `commit_id`     varchar(256)        NOT NULL DEFAULT '' COMMENT 'commit id at last sync',
     `last_sync_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
     `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -197,6 +197,7 @@ CREATE TABLE `tb_application`
     `git_ref_type`     varchar(64)                  DEFAULT NULL,
     `template`         varchar(64)         NOT NULL COMMENT 'template name',
     `template_release` varchar(64)         NOT NULL COMMENT 'template release',
+    `visibility_level` varchar(16)         NOT NULL DEFAULT 'public' COMMENT 'public, internal or private',
     `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
     `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     `deleted_ts`       bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
@@ -381,6 +382,7 @@ CREATE TABLE `tb_pipelinerun`
     `ci_event_id`        varchar(36)         NOT NULL DEFAULT '' COMMENT 'event id returned from ci component',
     `started_at`         datetime                     DEFAULT NULL COMMENT 'start time of this pipelinerun',
     `finished_at`        datetime                     DEFAULT NULL COMMENT 'finish time of this pipelinerun',
+    `scheduled_at`       datetime                     DEFAULT NULL COMMENT 'time to execute this pipelinerun automatically',
     `rollback_from`      bigint(20) unsigned          DEFAULT NULL COMMENT 'the pipelinerun id that this pipelinerun rollback from',
     `created_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
     `updated_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
@@ -388,7 +390,8 @@ CREATE TABLE `tb_pipelinerun`
     PRIMARY KEY (`id`),
     KEY `idx_cluster_action` (`cluster_id`, `action`),
     KEY `idx_cluster_config_commit` (`cluster_id`, `config_commit`),
-    KEY `idx_ci_event_id` (`ci_event_id`)
+    KEY `idx_ci_event_id` (`ci_event_id`),
+    KEY `idx_scheduled_at` (`scheduled_at`)
 ) ENGINE = InnoDB
   AUTO_INCREMENT = 1
   DEFAULT CHARSET = utf8mb4;
@@ -486,6 +489,8 @@ CREATE TABLE `tb_oauth_app`
     `home_url`     varchar(256)                 DEFAULT NULL COMMENT 'the oauth app home url',
     `description`  varchar(256)                 DEFAULT NULL COMMENT 'the desc of app',
     `app_type`     tinyint(1)          NOT NULL DEFAULT '1' COMMENT '1 for HorizonOAuthAPP, 2 for DirectOAuthAPP',
+    `client_type`  tinyint(1)          NOT NULL DEFAULT '1' COMMENT '1 for confidential client, 2 for public client',
+    `scopes`       varchar(1024)       NOT NULL DEFAULT '' COMMENT 'scopes granted in client credentials grant',
     `owner_type`   tinyint(1)          NOT NULL DEFAULT '1' COMMENT '1 for group, 2 for user',
     `owner_id`     bigint(20)                   DEFAULT NULL COMMENT 'group owner id',
     `created_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created_at',
@@ -520,6 +525,9 @@ CREATE TABLE `tb_token`
     `client_id`    varchar(256)                 DEFAULT NULL COMMENT 'oauth app client',
     `redirect_uri` varchar(256)                 DEFAULT NULL,
     `state`        varchar(256)                 DEFAULT NULL COMMENT ' authorize_code state info',
+    `code_challenge`        varchar(128) NOT NULL DEFAULT '' COMMENT 'PKCE code challenge of authorization code',
+    `code_challenge_method` varchar(16)  NOT NULL DEFAULT '' COMMENT 'PKCE code challenge method, plain or S256',
+    `nonce`                 varchar(256) NOT NULL DEFAULT '' COMMENT 'OIDC nonce of authorization code',
     `code`         varchar(256)        NOT NULL DEFAULT '' COMMENT 'private-token-code/authorize_code/access_token/refresh-token',
     `created_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
     `expires_in`   bigint(20)                   DEFAULT NULL,
@@ -624,6 +632,7 @@ CREATE TABLE `tb_webhook`
     `triggers`           text                NOT NULL,
     `resource_type`      varchar(256)        NOT NULL DEFAULT '',
     `resource_id`        bigint(20)          NOT NULL DEFAULT '0',
+    `max_attempts`       int(11) unsigned    NOT NULL DEFAULT '0' COMMENT 'max attempts to send a log, 0 means using the default in config',
     `created_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
     `updated_at`         datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     `created_by`         bigint(20) unsigned NOT NULL DEFAULT '0',
@@ -645,6 +654,8 @@ CREATE TABLE `tb_webhook_log`
     `response_body`    text                NOT NULL,
     `status`           varchar(256)        NOT NULL,
     `error_message`    text                NOT NULL,
+    `attempts`         int(11) unsigned    NOT NULL DEFAULT '0' COMMENT 'attempts made to send the log',
+    `next_attempt_at`  datetime                     DEFAULT NULL COMMENT 'time of the next attempt',
     `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
     `created_by`       bigint(20) unsigned NOT NULL DEFAULT '0',
     `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
@@ -687,3 +698,333 @@ CREATE TABLE `tb_badge`
 ) ENGINE = InnoDB
   AUTO_INCREMENT = 1
   DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_admission_policy`
+(
+    `id`          bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `group_id`    bigint(20) unsigned NOT NULL COMMENT 'group id, the policy applies to the group and its descendants',
+    `name`        varchar(64)         NOT NULL DEFAULT '' COMMENT 'policy name',
+    `description` varchar(256)        NOT NULL DEFAULT '' COMMENT 'policy description',
+    `enabled`     tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'only enabled policies are evaluated',
+    `resources`   varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comma separated resources, empty means all',
+    `operations`  varchar(64)         NOT NULL DEFAULT '' COMMENT 'comma separated operations, empty means all',
+    `expression`  text                NOT NULL COMMENT 'CEL expression which must be true to admit a request',
+    `message`     varchar(512)        NOT NULL DEFAULT '' COMMENT 'message returned when a request is denied',
+    `created_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`  bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`  bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`  bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_group_name_deleted_ts` (`group_id`, `name`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_deploy_window`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `resource_type` varchar(64)         NOT NULL DEFAULT '' COMMENT 'groups or environments',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the group or environment',
+    `name`          varchar(64)         NOT NULL DEFAULT '' COMMENT 'window name',
+    `description`   varchar(256)        NOT NULL DEFAULT '' COMMENT 'window description',
+    `enabled`       tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'only enabled windows are evaluated',
+    `type`          varchar(16)         NOT NULL DEFAULT '' COMMENT 'allow or deny',
+    `schedule`      varchar(128)        NOT NULL DEFAULT '' COMMENT 'cron expression on which the window opens',
+    `duration`      int(11) unsigned    NOT NULL DEFAULT '0' COMMENT 'seconds the window keeps open',
+    `time_zone`     varchar(64)         NOT NULL DEFAULT '' COMMENT 'time zone of schedule, empty means local',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`    bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_resource_name_deleted_ts` (`resource_type`, `resource_id`, `name`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_release_train`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `application_id` bigint(20) unsigned NOT NULL COMMENT 'id of the application',
+    `name`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'release train name',
+    `description`    varchar(256)        NOT NULL DEFAULT '' COMMENT 'release train description',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`     bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`     bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`     bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_application_name_deleted_ts` (`application_id`, `name`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_release_train_stage`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `release_train_id` bigint(20) unsigned NOT NULL COMMENT 'id of the release train',
+    `sequence`         int(11)             NOT NULL DEFAULT '0' COMMENT 'order of the stage, starting from 0',
+    `name`             varchar(64)         NOT NULL DEFAULT '' COMMENT 'stage name',
+    `environment`      varchar(128)        NOT NULL DEFAULT '' COMMENT 'environment of the clusters to deploy',
+    `clusters`         varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comma separated ids of the clusters to deploy',
+    `require_approval` tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'whether approval is required before the stage',
+    `soak_time`        int(11) unsigned    NOT NULL DEFAULT '0' COMMENT 'seconds to wait after the previous stage',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`       bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    KEY `idx_release_train_id` (`release_train_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_release_train_run`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `release_train_id` bigint(20) unsigned NOT NULL COMMENT 'id of the release train',
+    `application_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the application',
+    `title`            varchar(256)        NOT NULL DEFAULT '' COMMENT 'title of the pipelineruns',
+    `description`      varchar(2048)       NOT NULL DEFAULT '' COMMENT 'description of the pipelineruns',
+    `action`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'deploy or builddeploy',
+    `image_tag`        varchar(256)        NOT NULL DEFAULT '' COMMENT 'image tag to deploy',
+    `git_ref_type`     varchar(64)         NOT NULL DEFAULT '' COMMENT 'git ref type to build',
+    `git_ref`          varchar(128)        NOT NULL DEFAULT '' COMMENT 'git ref to build',
+    `status`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'running, succeeded, failed or cancelled',
+    `current_stage`    int(11)             NOT NULL DEFAULT '0' COMMENT 'sequence of the stage in progress',
+    `message`          varchar(1024)       NOT NULL DEFAULT '' COMMENT 'message of the status',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`       bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`       bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`       bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    KEY `idx_release_train_id` (`release_train_id`),
+    KEY `idx_status` (`status`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_release_train_stage_run`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `run_id`           bigint(20) unsigned NOT NULL COMMENT 'id of the release train run',
+    `sequence`         int(11)             NOT NULL DEFAULT '0' COMMENT 'order of the stage, starting from 0',
+    `name`             varchar(64)         NOT NULL DEFAULT '' COMMENT 'stage name',
+    `environment`      varchar(128)        NOT NULL DEFAULT '' COMMENT 'environment of the clusters to deploy',
+    `clusters`         varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comma separated ids of the clusters to deploy',
+    `require_approval` tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'whether approval is required before the stage',
+    `soak_time`        int(11) unsigned    NOT NULL DEFAULT '0' COMMENT 'seconds to wait after the previous stage',
+    `status`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'status of the stage',
+    `pipelinerun_ids`  varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comma separated ids of the pipelineruns',
+    `approved_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'approver',
+    `started_at`       datetime                     DEFAULT NULL COMMENT 'time the pipelineruns are created',
+    `finished_at`      datetime                     DEFAULT NULL COMMENT 'time the stage finished',
+    `message`          varchar(1024)       NOT NULL DEFAULT '' COMMENT 'message of the status',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`       bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    KEY `idx_run_id` (`run_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_team`
+(
+    `id`          bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`        varchar(64)         NOT NULL DEFAULT '' COMMENT 'team name',
+    `description` varchar(256)        NOT NULL DEFAULT '' COMMENT 'team description',
+    `created_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`  bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`  bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`  bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_name_deleted_ts` (`name`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_team_member`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `team_id`    bigint(20) unsigned NOT NULL COMMENT 'id of the team',
+    `user_id`    bigint(20) unsigned NOT NULL COMMENT 'id of the user',
+    `role`       varchar(64)         NOT NULL DEFAULT '' COMMENT 'owner or member',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts` bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_team_user_deleted_ts` (`team_id`, `user_id`, `deleted_ts`),
+    KEY `idx_user_id` (`user_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_role`
+(
+    `id`          bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `name`        varchar(64)         NOT NULL DEFAULT '' COMMENT 'role name',
+    `description` varchar(256)        NOT NULL DEFAULT '' COMMENT 'role description',
+    `rules`       text                         COMMENT 'json encoded policy rules',
+    `rank`        int(11)             NOT NULL DEFAULT '0' COMMENT 'priority of the role, the smaller the higher',
+    `is_default`  tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'role of the users who are not members',
+    `version`     int(11) unsigned    NOT NULL DEFAULT '0' COMMENT 'version of the roles file which the role is seeded from',
+    `created_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`  bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`  bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`  bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_name_deleted_ts` (`name`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_access_audit`
+(
+    `id`           bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `user_id`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'user whose request is denied',
+    `user_name`    varchar(64)         NOT NULL DEFAULT '' COMMENT 'name of the user',
+    `verb`         varchar(64)         NOT NULL DEFAULT '' COMMENT 'verb of the request',
+    `resource`     varchar(64)         NOT NULL DEFAULT '' COMMENT 'resource type of the request',
+    `sub_resource` varchar(64)         NOT NULL DEFAULT '' COMMENT 'sub resource of the request',
+    `name`         varchar(64)         NOT NULL DEFAULT '' COMMENT 'id of the resource',
+    `path`         varchar(512)        NOT NULL DEFAULT '' COMMENT 'path of the request',
+    `reason`       varchar(512)        NOT NULL DEFAULT '' COMMENT 'reason of the denial',
+    `created_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`   bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    KEY `idx_user_id` (`user_id`),
+    KEY `idx_resource_name` (`resource`, `name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_image_policy`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `application_id` bigint(20) unsigned NOT NULL COMMENT 'id of the application',
+    `retain_count`   int(11)             NOT NULL DEFAULT '0' COMMENT 'count of the latest tags retained for each cluster, 0 disables retention',
+    `block_critical` tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'whether to block deploying images with critical vulnerabilities',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`     bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`     bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`     bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_application_id` (`application_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_pipelinerun_step_log`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `pipelinerun_id` bigint(20) unsigned NOT NULL COMMENT 'id of the pipelinerun',
+    `application_id` bigint(20) unsigned NOT NULL COMMENT 'id of the application',
+    `cluster_id`     bigint(20) unsigned NOT NULL COMMENT 'id of the cluster',
+    `task`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'task of the step',
+    `step`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'name of the step',
+    `sequence`       int(11)             NOT NULL DEFAULT '0' COMMENT 'order of the step in the log of pipelinerun',
+    `started_at`     datetime                     DEFAULT NULL COMMENT 'start time of the step',
+    `finished_at`    datetime                     DEFAULT NULL COMMENT 'finish time of the step',
+    `line_count`     int(11)             NOT NULL DEFAULT '0' COMMENT 'count of the lines of the step',
+    `content`        mediumtext          NOT NULL COMMENT 'lines of the step joined by line breaks',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`     bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    KEY `idx_pipelinerun_id` (`pipelinerun_id`),
+    KEY `idx_application_id_created_at` (`application_id`, `created_at`),
+    KEY `idx_cluster_id_created_at` (`cluster_id`, `created_at`),
+    KEY `idx_created_at` (`created_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_hook_event`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `event_type` varchar(64)         NOT NULL DEFAULT '' COMMENT 'type of the event',
+    `content`    text                NOT NULL COMMENT 'event encoded in json',
+    `request_id` varchar(64)         NOT NULL DEFAULT '' COMMENT 'id of the request pushing the event',
+    `user`       varchar(1024)       NOT NULL DEFAULT '' COMMENT 'user pushing the event encoded in json',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts` bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    KEY `idx_created_at` (`created_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_hook_delivery`
+(
+    `id`              bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `event_id`        bigint(20) unsigned NOT NULL COMMENT 'id of the hook event',
+    `handler`         varchar(128)        NOT NULL DEFAULT '' COMMENT 'name of the handler processing the event',
+    `status`          varchar(16)         NOT NULL DEFAULT '' COMMENT 'pending or succeeded',
+    `failed_times`    int(11) unsigned    NOT NULL DEFAULT '0' COMMENT 'times the handler failed to process the event',
+    `error_message`   text COMMENT 'error of the last failure',
+    `next_attempt_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'earliest time to process the event',
+    `lease_owner`     varchar(128)        NOT NULL DEFAULT '' COMMENT 'replica processing the event',
+    `lease_expire_at` datetime                     DEFAULT NULL COMMENT 'time when other replicas could take over the event',
+    `created_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`      bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    KEY `idx_event_id` (`event_id`),
+    KEY `idx_status_next_attempt_at` (`status`, `next_attempt_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_template_migration`
+(
+    `id`              bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `template_id`     bigint(20) unsigned NOT NULL COMMENT 'id of the template',
+    `template`        varchar(64)         NOT NULL DEFAULT '' COMMENT 'name of the template',
+    `source_releases` varchar(256)        NOT NULL DEFAULT '' COMMENT 'semver constraint of releases to migrate from',
+    `target_release`  varchar(64)         NOT NULL DEFAULT '' COMMENT 'release to migrate to',
+    `group_id`        bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'group of the clusters to migrate',
+    `environment`     varchar(64)         NOT NULL DEFAULT '' COMMENT 'environment of the clusters to migrate',
+    `tag_selector`    varchar(512)        NOT NULL DEFAULT '' COMMENT 'tag selector of the clusters to migrate',
+    `batch_size`      int(11) unsigned    NOT NULL DEFAULT '0' COMMENT 'number of clusters to upgrade at a time',
+    `status`          varchar(64)         NOT NULL DEFAULT '' COMMENT 'running, paused, succeeded, failed or aborted',
+    `message`         varchar(1024)       NOT NULL DEFAULT '' COMMENT 'message of the status',
+    `created_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`      bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    KEY `idx_template_id` (`template_id`),
+    KEY `idx_status` (`status`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_template_migration_cluster`
+(
+    `id`           bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `migration_id` bigint(20) unsigned NOT NULL COMMENT 'id of the template migration',
+    `cluster_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the cluster',
+    `cluster`      varchar(64)         NOT NULL DEFAULT '' COMMENT 'name of the cluster',
+    `from_release` varchar(64)         NOT NULL DEFAULT '' COMMENT 'release of the cluster before migration',
+    `status`       varchar(64)         NOT NULL DEFAULT '' COMMENT 'pending, succeeded, failed or aborted',
+    `message`      varchar(1024)       NOT NULL DEFAULT '' COMMENT 'message of the status',
+    `finished_at`  datetime                     DEFAULT NULL COMMENT 'time when the cluster is upgraded',
+    `created_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`   bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    KEY `idx_migration_id` (`migration_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
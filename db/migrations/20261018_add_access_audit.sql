@@ -0,0 +1,34 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+CREATE TABLE `tb_access_audit`
+(
+    `id`           bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `user_id`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'user whose request is denied',
+    `user_name`    varchar(64)         NOT NULL DEFAULT '' COMMENT 'name of the user',
+    `verb`         varchar(64)         NOT NULL DEFAULT '' COMMENT 'verb of the request',
+    `resource`     varchar(64)         NOT NULL DEFAULT '' COMMENT 'resource type of the request',
+    `sub_resource` varchar(64)         NOT NULL DEFAULT '' COMMENT 'sub resource of the request',
+    `name`         varchar(64)         NOT NULL DEFAULT '' COMMENT 'id of the resource',
+    `path`         varchar(512)        NOT NULL DEFAULT '' COMMENT 'path of the request',
+    `reason`       varchar(512)        NOT NULL DEFAULT '' COMMENT 'reason of the denial',
+    `created_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`   bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    KEY `idx_user_id` (`user_id`),
+    KEY `idx_resource_name` (`resource`, `name`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,35 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+CREATE TABLE `tb_admission_policy`
+(
+    `id`          bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `group_id`    bigint(20) unsigned NOT NULL COMMENT 'group id, the policy applies to the group and its descendants',
+    `name`        varchar(64)         NOT NULL DEFAULT '' COMMENT 'policy name',
+    `description` varchar(256)        NOT NULL DEFAULT '' COMMENT 'policy description',
+    `enabled`     tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'only enabled policies are evaluated',
+    `resources`   varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comma separated resources, empty means all',
+    `operations`  varchar(64)         NOT NULL DEFAULT '' COMMENT 'comma separated operations, empty means all',
+    `expression`  text                NOT NULL COMMENT 'CEL expression which must be true to admit a request',
+    `message`     varchar(512)        NOT NULL DEFAULT '' COMMENT 'message returned when a request is denied',
+    `created_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`  datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`  bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`  bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`  bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_group_name_deleted_ts` (`group_id`, `name`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,40 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+ALTER TABLE tb_pipelinerun
+    ADD column `scheduled_at` datetime DEFAULT NULL COMMENT 'time to execute this pipelinerun automatically',
+    ADD KEY `idx_scheduled_at` (`scheduled_at`);
+
+CREATE TABLE `tb_deploy_window`
+(
+    `id`            bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `resource_type` varchar(64)         NOT NULL DEFAULT '' COMMENT 'groups or environments',
+    `resource_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the group or environment',
+    `name`          varchar(64)         NOT NULL DEFAULT '' COMMENT 'window name',
+    `description`   varchar(256)        NOT NULL DEFAULT '' COMMENT 'window description',
+    `enabled`       tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'only enabled windows are evaluated',
+    `type`          varchar(16)         NOT NULL DEFAULT '' COMMENT 'allow or deny',
+    `schedule`      varchar(128)        NOT NULL DEFAULT '' COMMENT 'cron expression on which the window opens',
+    `duration`      int(11) unsigned    NOT NULL DEFAULT '0' COMMENT 'seconds the window keeps open',
+    `time_zone`     varchar(64)         NOT NULL DEFAULT '' COMMENT 'time zone of schedule, empty means local',
+    `created_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`    datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`    bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`    bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_resource_name_deleted_ts` (`resource_type`, `resource_id`, `name`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
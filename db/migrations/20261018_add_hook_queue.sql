@@ -0,0 +1,50 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+CREATE TABLE `tb_hook_event`
+(
+    `id`         bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `event_type` varchar(64)         NOT NULL DEFAULT '' COMMENT 'type of the event',
+    `content`    text                NOT NULL COMMENT 'event encoded in json',
+    `request_id` varchar(64)         NOT NULL DEFAULT '' COMMENT 'id of the request pushing the event',
+    `user`       varchar(1024)       NOT NULL DEFAULT '' COMMENT 'user pushing the event encoded in json',
+    `created_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts` bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    KEY `idx_created_at` (`created_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_hook_delivery`
+(
+    `id`              bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `event_id`        bigint(20) unsigned NOT NULL COMMENT 'id of the hook event',
+    `handler`         varchar(128)        NOT NULL DEFAULT '' COMMENT 'name of the handler processing the event',
+    `status`          varchar(16)         NOT NULL DEFAULT '' COMMENT 'pending or succeeded',
+    `failed_times`    int(11) unsigned    NOT NULL DEFAULT '0' COMMENT 'times the handler failed to process the event',
+    `error_message`   text COMMENT 'error of the last failure',
+    `next_attempt_at` datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'earliest time to process the event',
+    `lease_owner`     varchar(128)        NOT NULL DEFAULT '' COMMENT 'replica processing the event',
+    `lease_expire_at` datetime                     DEFAULT NULL COMMENT 'time when other replicas could take over the event',
+    `created_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`      bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    KEY `idx_event_id` (`event_id`),
+    KEY `idx_status_next_attempt_at` (`status`, `next_attempt_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,38 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+CREATE TABLE `tb_pipelinerun_step_log`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `pipelinerun_id` bigint(20) unsigned NOT NULL COMMENT 'id of the pipelinerun',
+    `application_id` bigint(20) unsigned NOT NULL COMMENT 'id of the application',
+    `cluster_id`     bigint(20) unsigned NOT NULL COMMENT 'id of the cluster',
+    `task`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'task of the step',
+    `step`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'name of the step',
+    `sequence`       int(11)             NOT NULL DEFAULT '0' COMMENT 'order of the step in the log of pipelinerun',
+    `started_at`     datetime                     DEFAULT NULL COMMENT 'start time of the step',
+    `finished_at`    datetime                     DEFAULT NULL COMMENT 'finish time of the step',
+    `line_count`     int(11)             NOT NULL DEFAULT '0' COMMENT 'count of the lines of the step',
+    `content`        mediumtext          NOT NULL COMMENT 'lines of the step joined by line breaks',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`     bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    KEY `idx_pipelinerun_id` (`pipelinerun_id`),
+    KEY `idx_application_id_created_at` (`application_id`, `created_at`),
+    KEY `idx_cluster_id_created_at` (`cluster_id`, `created_at`),
+    KEY `idx_created_at` (`created_at`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,21 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+ALTER TABLE `tb_token`
+    ADD COLUMN `code_challenge`        varchar(128) NOT NULL DEFAULT '' COMMENT 'PKCE code challenge of authorization code' AFTER `state`,
+    ADD COLUMN `code_challenge_method` varchar(16)  NOT NULL DEFAULT '' COMMENT 'PKCE code challenge method, plain or S256' AFTER `code_challenge`;
+
+ALTER TABLE `tb_oauth_app`
+    ADD COLUMN `client_type` tinyint(1)    NOT NULL DEFAULT '1' COMMENT '1 for confidential client, 2 for public client' AFTER `app_type`,
+    ADD COLUMN `scopes`      varchar(1024) NOT NULL DEFAULT '' COMMENT 'scopes granted in client credentials grant' AFTER `client_type`;
@@ -0,0 +1,101 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+CREATE TABLE `tb_release_train`
+(
+    `id`             bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `application_id` bigint(20) unsigned NOT NULL COMMENT 'id of the application',
+    `name`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'release train name',
+    `description`    varchar(256)        NOT NULL DEFAULT '' COMMENT 'release train description',
+    `created_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`     bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`     bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`     bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_application_name_deleted_ts` (`application_id`, `name`, `deleted_ts`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_release_train_stage`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `release_train_id` bigint(20) unsigned NOT NULL COMMENT 'id of the release train',
+    `sequence`         int(11)             NOT NULL DEFAULT '0' COMMENT 'order of the stage, starting from 0',
+    `name`             varchar(64)         NOT NULL DEFAULT '' COMMENT 'stage name',
+    `environment`      varchar(128)        NOT NULL DEFAULT '' COMMENT 'environment of the clusters to deploy',
+    `clusters`         varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comma separated ids of the clusters to deploy',
+    `require_approval` tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'whether approval is required before the stage',
+    `soak_time`        int(11) unsigned    NOT NULL DEFAULT '0' COMMENT 'seconds to wait after the previous stage',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`       bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    KEY `idx_release_train_id` (`release_train_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_release_train_run`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `release_train_id` bigint(20) unsigned NOT NULL COMMENT 'id of the release train',
+    `application_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the application',
+    `title`            varchar(256)        NOT NULL DEFAULT '' COMMENT 'title of the pipelineruns',
+    `description`      varchar(2048)       NOT NULL DEFAULT '' COMMENT 'description of the pipelineruns',
+    `action`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'deploy or builddeploy',
+    `image_tag`        varchar(256)        NOT NULL DEFAULT '' COMMENT 'image tag to deploy',
+    `git_ref_type`     varchar(64)         NOT NULL DEFAULT '' COMMENT 'git ref type to build',
+    `git_ref`          varchar(128)        NOT NULL DEFAULT '' COMMENT 'git ref to build',
+    `status`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'running, succeeded, failed or cancelled',
+    `current_stage`    int(11)             NOT NULL DEFAULT '0' COMMENT 'sequence of the stage in progress',
+    `message`          varchar(1024)       NOT NULL DEFAULT '' COMMENT 'message of the status',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`       bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`       bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`       bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    KEY `idx_release_train_id` (`release_train_id`),
+    KEY `idx_status` (`status`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_release_train_stage_run`
+(
+    `id`               bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `run_id`           bigint(20) unsigned NOT NULL COMMENT 'id of the release train run',
+    `sequence`         int(11)             NOT NULL DEFAULT '0' COMMENT 'order of the stage, starting from 0',
+    `name`             varchar(64)         NOT NULL DEFAULT '' COMMENT 'stage name',
+    `environment`      varchar(128)        NOT NULL DEFAULT '' COMMENT 'environment of the clusters to deploy',
+    `clusters`         varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comma separated ids of the clusters to deploy',
+    `require_approval` tinyint(1)          NOT NULL DEFAULT '0' COMMENT 'whether approval is required before the stage',
+    `soak_time`        int(11) unsigned    NOT NULL DEFAULT '0' COMMENT 'seconds to wait after the previous stage',
+    `status`           varchar(64)         NOT NULL DEFAULT '' COMMENT 'status of the stage',
+    `pipelinerun_ids`  varchar(1024)       NOT NULL DEFAULT '' COMMENT 'comma separated ids of the pipelineruns',
+    `approved_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'approver',
+    `started_at`       datetime                     DEFAULT NULL COMMENT 'time the pipelineruns are created',
+    `finished_at`      datetime                     DEFAULT NULL COMMENT 'time the stage finished',
+    `message`          varchar(1024)       NOT NULL DEFAULT '' COMMENT 'message of the status',
+    `created_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`       bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    KEY `idx_run_id` (`run_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
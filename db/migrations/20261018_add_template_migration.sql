@@ -0,0 +1,58 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+
+CREATE TABLE `tb_template_migration`
+(
+    `id`              bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `template_id`     bigint(20) unsigned NOT NULL COMMENT 'id of the template',
+    `template`        varchar(64)         NOT NULL DEFAULT '' COMMENT 'name of the template',
+    `source_releases` varchar(256)        NOT NULL DEFAULT '' COMMENT 'semver constraint of releases to migrate from',
+    `target_release`  varchar(64)         NOT NULL DEFAULT '' COMMENT 'release to migrate to',
+    `group_id`        bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'group of the clusters to migrate',
+    `environment`     varchar(64)         NOT NULL DEFAULT '' COMMENT 'environment of the clusters to migrate',
+    `tag_selector`    varchar(512)        NOT NULL DEFAULT '' COMMENT 'tag selector of the clusters to migrate',
+    `batch_size`      int(11) unsigned    NOT NULL DEFAULT '0' COMMENT 'number of clusters to upgrade at a time',
+    `status`          varchar(64)         NOT NULL DEFAULT '' COMMENT 'running, paused, succeeded, failed or aborted',
+    `message`         varchar(1024)       NOT NULL DEFAULT '' COMMENT 'message of the status',
+    `created_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`      datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`      bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    `created_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'creator',
+    `updated_by`      bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'updater',
+    PRIMARY KEY (`id`),
+    KEY `idx_template_id` (`template_id`),
+    KEY `idx_status` (`status`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `tb_template_migration_cluster`
+(
+    `id`           bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+    `migration_id` bigint(20) unsigned NOT NULL COMMENT 'id of the template migration',
+    `cluster_id`   bigint(20) unsigned NOT NULL COMMENT 'id of the cluster',
+    `cluster`      varchar(64)         NOT NULL DEFAULT '' COMMENT 'name of the cluster',
+    `from_release` varchar(64)         NOT NULL DEFAULT '' COMMENT 'release of the cluster before migration',
+    `status`       varchar(64)         NOT NULL DEFAULT '' COMMENT 'pending, succeeded, failed or aborted',
+    `message`      varchar(1024)       NOT NULL DEFAULT '' COMMENT 'message of the status',
+    `finished_at`  datetime                     DEFAULT NULL COMMENT 'time when the cluster is upgraded',
+    `created_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`   datetime            NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `deleted_ts`   bigint(20)                   DEFAULT '0' COMMENT 'deleted timestamp, 0 means not deleted',
+    PRIMARY KEY (`id`),
+    KEY `idx_migration_id` (`migration_id`)
+) ENGINE = InnoDB
+  AUTO_INCREMENT = 1
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,25 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+ALTER TABLE tb_application
+ADD COLUMN `visibility_level` varchar(16) NOT NULL DEFAULT 'public'
+COMMENT 'public, internal or private';
+
+-- the visibility level of groups was never enforced before, reset it to public
+-- to keep the existing groups visible, change it to private explicitly if needed
+ALTER TABLE tb_group
+MODIFY COLUMN `visibility_level` varchar(16) NOT NULL DEFAULT 'public'
+COMMENT 'public, internal or private';
+
+UPDATE tb_group SET visibility_level = 'public' WHERE deleted_ts = 0;
@@ -0,0 +1,20 @@
+-- Copyright © 2023 Horizoncd.
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+ALTER TABLE tb_webhook
+    ADD column `max_attempts` int(11) unsigned NOT NULL DEFAULT '0' COMMENT 'max attempts to send a log, 0 means using the default in config';
+
+ALTER TABLE tb_webhook_log
+    ADD column `attempts`        int(11) unsigned NOT NULL DEFAULT '0' COMMENT 'attempts made to send the log',
+    ADD column `next_attempt_at` datetime                  DEFAULT NULL COMMENT 'time of the next attempt';
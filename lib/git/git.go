@@ -0,0 +1,1580 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package git implements gitlab.Interface on top of plain git repositories,
+// so that gitops repos can be stored without a gitlab instance.
+//
+// Groups are directories under the root directory, and projects are bare
+// repositories named <path>.git inside them. If a remote URL is configured,
+// every project is mirrored to <URL>/<path>.git by the smart http protocol:
+// refs are fetched before each operation and pushed after each write.
+//
+// Only a single replica is supported. The integer ids of groups and projects are
+// derived from their full paths and resolved by walking the root directory, and groups
+// only exist locally, so the ones created by another replica can't be found by their ids.
+// The root directory is locked when started, so another instance sharing it refuses to start.
+// The remote server keeps the projects, which are cloned again if they are missing locally.
+package git
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	_projectSuffix = ".git"
+	// _lockFile in the root dir is locked by the running instance
+	_lockFile      = ".lock"
+	_remoteName    = "origin"
+	_defaultAuthor = "horizon"
+	_defaultEmail  = "horizon@localhost"
+
+	_mergeRequestStateOpened = "opened"
+	_mergeRequestStateClosed = "closed"
+	_mergeRequestStateMerged = "merged"
+	// merge requests are stored as json blobs referenced by refs/horizon/merge-requests/<iid> in the project,
+	// so they survive restarts and are kept by the remote server
+	_mergeRequestRefPrefix = "refs/horizon/merge-requests/"
+)
+
+var _ gitlablib.Interface = (*helper)(nil)
+
+// Config for plain git repositories
+type Config struct {
+	// RootDir is the directory to store groups and repositories
+	RootDir string
+	// URL is the base url of the remote smart http server, optional
+	URL string
+	// Username and Token are used to authenticate with the remote server
+	Username string
+	Token    string
+	// DefaultBranch is the branch created by CreateProject
+	DefaultBranch string
+}
+
+type helper struct {
+	rootDir       string
+	remoteURL     string
+	auth          transport.AuthMethod
+	defaultBranch string
+	author        string
+
+	// ids maps the id of groups and projects to their full path,
+	// ids are derived from the full path, so they are stable across restarts.
+	// All the existing groups and projects are registered when started, and a path
+	// whose id collides with another one is refused to be created.
+	idsLock sync.RWMutex
+	ids     map[int]string
+
+	// rootLock is the locked file which keeps other instances from sharing the root dir
+	rootLock *os.File
+
+	// projectLocks serializes writes to the same project
+	projectLocks sync.Map
+}
+
+// New an instance of plain git repositories with the given config
+func New(config Config) (gitlablib.Interface, error) {
+	if config.RootDir == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "root dir of git repositories cannot be empty")
+	}
+	rootDir, err := filepath.Abs(config.RootDir)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	if err := os.MkdirAll(rootDir, 0755); err != nil {
+		return nil, herrors.NewErrCreateFailed(herrors.GitRepoResource, err.Error())
+	}
+	defaultBranch := config.DefaultBranch
+	if defaultBranch == "" {
+		defaultBranch = "master"
+	}
+	h := &helper{
+		rootDir:       rootDir,
+		remoteURL:     strings.TrimSuffix(config.URL, "/"),
+		defaultBranch: defaultBranch,
+		author:        _defaultAuthor,
+		ids:           map[int]string{},
+	}
+	if config.Username != "" {
+		h.author = config.Username
+	}
+	if config.Token != "" {
+		username := config.Username
+		if username == "" {
+			// most git servers ignore username when a token is used as password
+			username = _defaultAuthor
+		}
+		h.auth = &githttp.BasicAuth{Username: username, Password: config.Token}
+	}
+	if h.rootLock, err = lockRootDir(rootDir); err != nil {
+		return nil, err
+	}
+	if err := h.scan(); err != nil {
+		_ = h.rootLock.Close()
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *helper) GetGroup(ctx context.Context, gid interface{}) (_ *gitlab.Group, err error) {
+	const op = "git: get group"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	groupPath, err := h.resolvePath(gid)
+	if err != nil {
+		return nil, err
+	}
+	if !h.isGroup(groupPath) {
+		return nil, herrors.NewErrNotFound(herrors.GitRepoResource,
+			fmt.Sprintf("group %v not found", gid))
+	}
+	return h.group(groupPath)
+}
+
+func (h *helper) ListGroupProjects(ctx context.Context, gid interface{},
+	page, perPage int) (_ []*gitlab.Project, err error) {
+	const op = "git: list group projects"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if page < 1 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "page cannot be less 1")
+	}
+	if perPage < 1 {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "perPage cannot be less 1")
+	}
+
+	groupPath, err := h.resolvePath(gid)
+	if err != nil {
+		return nil, err
+	}
+	if !h.isGroup(groupPath) {
+		return nil, herrors.NewErrNotFound(herrors.GitRepoResource,
+			fmt.Sprintf("group %v not found", gid))
+	}
+	entries, err := ioutil.ReadDir(h.dir(groupPath))
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasSuffix(entry.Name(), _projectSuffix) {
+			names = append(names, strings.TrimSuffix(entry.Name(), _projectSuffix))
+		}
+	}
+	sort.Strings(names)
+
+	start, end := paginate(len(names), gitlab.ListOptions{Page: page, PerPage: perPage})
+	projects := make([]*gitlab.Project, 0, end-start)
+	for _, name := range names[start:end] {
+		project, err := h.project(path.Join(groupPath, name))
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
+
+func (h *helper) CreateGroup(ctx context.Context, name, groupPath string,
+	parentID *int, visibility string) (_ *gitlab.Group, err error) {
+	const op = "git: create group"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	fullPath := groupPath
+	if parentID != nil {
+		parentPath, err := h.resolvePath(*parentID)
+		if err != nil {
+			return nil, err
+		}
+		if !h.isGroup(parentPath) {
+			return nil, herrors.NewErrNotFound(herrors.GitRepoResource,
+				fmt.Sprintf("parent group %v not found", *parentID))
+		}
+		fullPath = path.Join(parentPath, groupPath)
+	}
+	fullPath, err = cleanPath(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	if h.exists(h.dir(fullPath)) || h.exists(h.repoDir(fullPath)) {
+		return nil, perror.Wrapf(herrors.ErrNameConflict, "path %s has already been taken", fullPath)
+	}
+	if err := h.checkID(fullPath); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(h.dir(fullPath), 0755); err != nil {
+		return nil, herrors.NewErrCreateFailed(herrors.GitRepoResource, err.Error())
+	}
+	return h.group(fullPath)
+}
+
+func (h *helper) DeleteGroup(ctx context.Context, gid interface{}) (err error) {
+	const op = "git: delete group"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	groupPath, err := h.resolvePath(gid)
+	if err != nil {
+		return err
+	}
+	if groupPath == "" || !h.isGroup(groupPath) {
+		return herrors.NewErrNotFound(herrors.GitRepoResource, fmt.Sprintf("group %v not found", gid))
+	}
+	if h.remoteURL != "" {
+		log.Warningf(ctx, "group %s is only deleted locally, remote repositories are kept", groupPath)
+	}
+	if err := os.RemoveAll(h.dir(groupPath)); err != nil {
+		return herrors.NewErrDeleteFailed(herrors.GitRepoResource, err.Error())
+	}
+	h.unregister(groupPath)
+	return nil
+}
+
+func (h *helper) GetProject(ctx context.Context, pid interface{}) (_ *gitlab.Project, err error) {
+	const op = "git: get project"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	projectPath, _, err := h.openRepo(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	return h.project(projectPath)
+}
+
+func (h *helper) CreateProject(ctx context.Context, name string,
+	groupID int, visibility string) (_ *gitlab.Project, err error) {
+	const op = "git: create project"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	groupPath, err := h.resolvePath(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !h.isGroup(groupPath) {
+		return nil, herrors.NewErrNotFound(herrors.GitRepoResource,
+			fmt.Sprintf("group %v not found", groupID))
+	}
+	projectPath, err := cleanPath(path.Join(groupPath, name))
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := h.lockProject(projectPath)
+	defer unlock()
+
+	if h.exists(h.repoDir(projectPath)) || h.exists(h.dir(projectPath)) {
+		return nil, perror.Wrapf(herrors.ErrNameConflict, "path %s has already been taken", projectPath)
+	}
+	if err := h.checkID(projectPath); err != nil {
+		return nil, err
+	}
+
+	repo, err := git.PlainInit(h.repoDir(projectPath), true)
+	if err != nil {
+		return nil, herrors.NewErrCreateFailed(herrors.GitRepoResource, err.Error())
+	}
+	branchRef := plumbing.NewBranchReferenceName(h.defaultBranch)
+	if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branchRef)); err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+
+	// initialize with readme, the same as gitlab does
+	readme, err := writeBlob(repo.Storer, fmt.Sprintf("# %s\n", name))
+	if err != nil {
+		return nil, err
+	}
+	commit, err := h.commit(repo, map[string]treeEntry{"README.md": readme},
+		"Initial commit", nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(branchRef, commit.Hash)); err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+
+	if h.remoteURL != "" {
+		if _, err := repo.CreateRemote(&gitconfig.RemoteConfig{
+			Name: _remoteName,
+			URLs: []string{h.remoteRepoURL(projectPath)},
+		}); err != nil {
+			return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		if err := h.push(ctx, repo, branchRef.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	return h.project(projectPath)
+}
+
+func (h *helper) DeleteProject(ctx context.Context, pid interface{}) (err error) {
+	const op = "git: delete project"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	projectPath, err := h.resolvePath(pid)
+	if err != nil {
+		return err
+	}
+	if !h.exists(h.repoDir(projectPath)) {
+		return herrors.NewErrNotFound(herrors.GitRepoResource, fmt.Sprintf("project %v not found", pid))
+	}
+	if h.remoteURL != "" {
+		log.Warningf(ctx, "project %s is only deleted locally, remote repository is kept", projectPath)
+	}
+
+	unlock := h.lockProject(projectPath)
+	defer unlock()
+
+	if err := os.RemoveAll(h.repoDir(projectPath)); err != nil {
+		return herrors.NewErrDeleteFailed(herrors.GitRepoResource, err.Error())
+	}
+	h.unregister(projectPath)
+	return nil
+}
+
+func (h *helper) GetCommit(ctx context.Context, pid interface{}, commit string) (_ *gitlab.Commit, err error) {
+	const op = "git: get commit"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	_, repo, err := h.openRepo(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	c, err := resolveCommit(repo, commit)
+	if err != nil {
+		return nil, err
+	}
+	return toGitlabCommit(c), nil
+}
+
+func (h *helper) GetBranch(ctx context.Context, pid interface{}, branch string) (_ *gitlab.Branch, err error) {
+	const op = "git: get branch"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	_, repo, err := h.openRepo(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return nil, parseError(err, fmt.Sprintf("branch %s", branch))
+	}
+	return h.toGitlabBranch(repo, ref)
+}
+
+func (h *helper) GetTag(ctx context.Context, pid interface{}, tag string) (_ *gitlab.Tag, err error) {
+	const op = "git: get tag"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	_, repo, err := h.openRepo(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := repo.Reference(plumbing.NewTagReferenceName(tag), true)
+	if err != nil {
+		return nil, parseError(err, fmt.Sprintf("tag %s", tag))
+	}
+	return toGitlabTag(repo, ref)
+}
+
+func (h *helper) CreateBranch(ctx context.Context, pid interface{},
+	branch, fromRef string) (_ *gitlab.Branch, err error) {
+	const op = "git: create branch"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	projectPath, repo, err := h.openRepo(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	unlock := h.lockProject(projectPath)
+	defer unlock()
+
+	if err := h.fetch(ctx, repo); err != nil {
+		return nil, err
+	}
+	refName := plumbing.NewBranchReferenceName(branch)
+	if _, err := repo.Reference(refName, false); err == nil {
+		return nil, perror.Wrapf(herrors.ErrNameConflict, "branch %s already exists", branch)
+	}
+	from, err := resolveCommit(repo, fromRef)
+	if err != nil {
+		return nil, err
+	}
+	ref := plumbing.NewHashReference(refName, from.Hash)
+	if err := repo.Storer.SetReference(ref); err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	if err := h.push(ctx, repo, refName.String()); err != nil {
+		return nil, err
+	}
+	return h.toGitlabBranch(repo, ref)
+}
+
+func (h *helper) DeleteBranch(ctx context.Context, pid interface{}, branch string) (err error) {
+	const op = "git: delete branch"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	projectPath, repo, err := h.openRepo(ctx, pid)
+	if err != nil {
+		return err
+	}
+	unlock := h.lockProject(projectPath)
+	defer unlock()
+
+	return h.deleteBranch(ctx, repo, branch)
+}
+
+func (h *helper) ListBranch(ctx context.Context, pid interface{},
+	listBranchOptions *gitlab.ListBranchesOptions) (_ []*gitlab.Branch, err error) {
+	const op = "git: list branch"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	_, repo, err := h.openRepo(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		search      string
+		listOptions gitlab.ListOptions
+	)
+	if listBranchOptions != nil {
+		listOptions = listBranchOptions.ListOptions
+		if listBranchOptions.Search != nil {
+			search = *listBranchOptions.Search
+		}
+	}
+
+	refs, err := listRefs(repo, true, search)
+	if err != nil {
+		return nil, err
+	}
+	start, end := paginate(len(refs), listOptions)
+	branches := make([]*gitlab.Branch, 0, end-start)
+	for _, ref := range refs[start:end] {
+		branch, err := h.toGitlabBranch(repo, ref)
+		if err != nil {
+			return nil, err
+		}
+		branches = append(branches, branch)
+	}
+	return branches, nil
+}
+
+func (h *helper) ListTag(ctx context.Context, pid interface{},
+	listTagOptions *gitlab.ListTagsOptions) (_ []*gitlab.Tag, err error) {
+	const op = "git: list tag"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	_, repo, err := h.openRepo(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		search      string
+		listOptions gitlab.ListOptions
+	)
+	if listTagOptions != nil {
+		listOptions = listTagOptions.ListOptions
+		if listTagOptions.Search != nil {
+			search = *listTagOptions.Search
+		}
+	}
+
+	refs, err := listRefs(repo, false, search)
+	if err != nil {
+		return nil, err
+	}
+	start, end := paginate(len(refs), listOptions)
+	tags := make([]*gitlab.Tag, 0, end-start)
+	for _, ref := range refs[start:end] {
+		tag, err := toGitlabTag(repo, ref)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (h *helper) CreateMR(ctx context.Context, pid interface{},
+	source, target, title string) (_ *gitlab.MergeRequest, err error) {
+	const op = "git: create mr"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	projectPath, repo, err := h.openRepo(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := h.lockProject(projectPath)
+	defer unlock()
+
+	if err := h.fetch(ctx, repo); err != nil {
+		return nil, err
+	}
+	for _, branch := range []string{source, target} {
+		if _, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true); err != nil {
+			return nil, parseError(err, fmt.Sprintf("branch %s", branch))
+		}
+	}
+	mrs, err := listMRs(repo)
+	if err != nil {
+		return nil, err
+	}
+	projectID, err := h.register(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	iid := 1
+	if len(mrs) > 0 {
+		iid = mrs[len(mrs)-1].IID + 1
+	}
+	now := time.Now()
+	mr := &gitlab.MergeRequest{
+		ID:           iid,
+		IID:          iid,
+		ProjectID:    projectID,
+		Title:        title,
+		State:        _mergeRequestStateOpened,
+		SourceBranch: source,
+		TargetBranch: target,
+		CreatedAt:    &now,
+		UpdatedAt:    &now,
+	}
+	if err := h.saveMR(ctx, repo, mr, false); err != nil {
+		return nil, err
+	}
+	return mr, nil
+}
+
+func (h *helper) ListMRs(ctx context.Context, pid interface{},
+	source, target, state string) (_ []*gitlab.MergeRequest, err error) {
+	_, repo, err := h.openRepo(ctx, pid)
+	if err != nil {
+		return nil, perror.WithMessagef(err, "failed to list merge requests for project: %v", pid)
+	}
+	if err := h.fetch(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	all, err := listMRs(repo)
+	if err != nil {
+		return nil, err
+	}
+	mrs := make([]*gitlab.MergeRequest, 0)
+	for _, mr := range all {
+		if (source == "" || mr.SourceBranch == source) &&
+			(target == "" || mr.TargetBranch == target) &&
+			(state == "" || state == "all" || mr.State == state) {
+			mrs = append(mrs, mr)
+		}
+	}
+	return mrs, nil
+}
+
+func (h *helper) AcceptMR(ctx context.Context, pid interface{}, mrID int,
+	mergeCommitMsg *string, shouldRemoveSourceBranch *bool) (_ *gitlab.MergeRequest, err error) {
+	const op = "git: accept mr"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	projectPath, repo, err := h.openRepo(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := h.lockProject(projectPath)
+	defer unlock()
+
+	if err := h.fetch(ctx, repo); err != nil {
+		return nil, err
+	}
+	mr, err := getMR(repo, projectPath, mrID)
+	if err != nil {
+		return nil, err
+	}
+	if mr.State != _mergeRequestStateOpened {
+		return nil, perror.Wrapf(herrors.ErrGitlabMRNotReady, "merge request %d is %s", mrID, mr.State)
+	}
+	message := fmt.Sprintf("Merge branch '%s' into '%s'", mr.SourceBranch, mr.TargetBranch)
+	if mergeCommitMsg != nil && *mergeCommitMsg != "" {
+		message = *mergeCommitMsg
+	}
+	mergeCommit, err := h.merge(ctx, repo, mr.SourceBranch, mr.TargetBranch, message)
+	if err != nil {
+		return nil, err
+	}
+	if shouldRemoveSourceBranch != nil && *shouldRemoveSourceBranch {
+		if err := h.deleteBranch(ctx, repo, mr.SourceBranch); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	mr.State = _mergeRequestStateMerged
+	mr.MergeCommitSHA = mergeCommit.Hash.String()
+	mr.SHA = mergeCommit.Hash.String()
+	mr.MergedAt = &now
+	mr.UpdatedAt = &now
+	if err := h.saveMR(ctx, repo, mr, true); err != nil {
+		return nil, err
+	}
+	return mr, nil
+}
+
+func (h *helper) CloseMR(ctx context.Context, pid interface{}, mrID int) (_ *gitlab.MergeRequest, err error) {
+	const op = "git: close mr"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	projectPath, repo, err := h.openRepo(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := h.lockProject(projectPath)
+	defer unlock()
+
+	if err := h.fetch(ctx, repo); err != nil {
+		return nil, err
+	}
+	mr, err := getMR(repo, projectPath, mrID)
+	if err != nil {
+		log.Warningf(ctx, "project %v, mr %d have been closed", pid, mrID)
+		return nil, nil
+	}
+
+	now := time.Now()
+	mr.State = _mergeRequestStateClosed
+	mr.ClosedAt = &now
+	mr.UpdatedAt = &now
+	if err := h.saveMR(ctx, repo, mr, true); err != nil {
+		return nil, err
+	}
+	return mr, nil
+}
+
+func (h *helper) WriteFiles(ctx context.Context, pid interface{}, branch, commitMsg string,
+	startBranch *string, actions []gitlablib.CommitAction) (_ *gitlab.Commit, err error) {
+	const op = "git: write files"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	projectPath, repo, err := h.openRepo(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	unlock := h.lockProject(projectPath)
+	defer unlock()
+
+	if err := h.fetch(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	refName := plumbing.NewBranchReferenceName(branch)
+	ref, err := repo.Reference(refName, true)
+	if err != nil {
+		if err != plumbing.ErrReferenceNotFound || startBranch == nil {
+			return nil, parseError(err, fmt.Sprintf("branch %s", branch))
+		}
+		ref, err = repo.Reference(plumbing.NewBranchReferenceName(*startBranch), true)
+		if err != nil {
+			return nil, parseError(err, fmt.Sprintf("branch %s", *startBranch))
+		}
+	}
+	parent, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, parseError(err, fmt.Sprintf("commit %s", ref.Hash()))
+	}
+	files, err := flattenCommit(parent)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyActions(repo.Storer, files, actions); err != nil {
+		return nil, err
+	}
+
+	commit, err := h.commit(repo, files, commitMsg, []plumbing.Hash{parent.Hash})
+	if err != nil {
+		return nil, err
+	}
+	if err := h.updateRef(ctx, repo, refName, ref.Hash(), commit.Hash); err != nil {
+		return nil, err
+	}
+	return toGitlabCommit(commit), nil
+}
+
+func (h *helper) GetFile(ctx context.Context, pid interface{}, ref, filepath string) (_ []byte, err error) {
+	const op = "git: get file"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	_, repo, err := h.openRepo(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := resolveCommit(repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	file, err := commit.File(filepath)
+	if err != nil {
+		return nil, parseError(err, fmt.Sprintf("file %s", filepath))
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	// keep the same as gitlab client, which returns nil for an empty file
+	if content == "" {
+		return nil, nil
+	}
+	return []byte(content), nil
+}
+
+func (h *helper) TransferProject(ctx context.Context, pid interface{}, gid interface{}) (err error) {
+	const op = "git: transfer project"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	projectPath, err := h.resolvePath(pid)
+	if err != nil {
+		return err
+	}
+	groupPath, err := h.resolvePath(gid)
+	if err != nil {
+		return err
+	}
+	if !h.isGroup(groupPath) {
+		return herrors.NewErrNotFound(herrors.GitRepoResource, fmt.Sprintf("group %v not found", gid))
+	}
+	return h.moveProject(ctx, projectPath, path.Join(groupPath, path.Base(projectPath)))
+}
+
+func (h *helper) EditNameAndPathForProject(ctx context.Context, pid interface{},
+	newName, newPath *string) (err error) {
+	const op = "git: edit name and path for project"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	projectPath, err := h.resolvePath(pid)
+	if err != nil {
+		return err
+	}
+	// name of a project is always the same as its path
+	if newPath == nil {
+		return nil
+	}
+	return h.moveProject(ctx, projectPath, path.Join(path.Dir(projectPath), *newPath))
+}
+
+func (h *helper) Compare(ctx context.Context, pid interface{}, from, to string,
+	straight *bool) (_ *gitlab.Compare, err error) {
+	const op = "git: compare branchs"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	_, repo, err := h.openRepo(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	fromCommit, err := resolveCommit(repo, from)
+	if err != nil {
+		return nil, err
+	}
+	toCommit, err := resolveCommit(repo, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// by default, compare with the merge base like 'git diff from...to'
+	if straight == nil || !*straight {
+		base, err := mergeBase(fromCommit, toCommit)
+		if err != nil {
+			return nil, err
+		}
+		if base != nil {
+			fromCommit = base
+		}
+	}
+
+	diffs, err := diffCommits(ctx, fromCommit, toCommit)
+	if err != nil {
+		return nil, err
+	}
+	return &gitlab.Compare{
+		Commit:         toGitlabCommit(toCommit),
+		Diffs:          diffs,
+		CompareSameRef: fromCommit.Hash == toCommit.Hash,
+	}, nil
+}
+
+func (h *helper) GetRepositoryArchive(ctx context.Context, pid interface{}, sha string) ([]byte, error) {
+	const op = "git: get repository archive"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	projectPath, repo, err := h.openRepo(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := resolveCommit(repo, sha)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	prefix := fmt.Sprintf("%s-%s", path.Base(projectPath), commit.Hash.String())
+	err = tree.Files().ForEach(func(file *object.File) error {
+		content, err := file.Contents()
+		if err != nil {
+			return err
+		}
+		if err := tarWriter.WriteHeader(&tar.Header{
+			Name:    path.Join(prefix, file.Name),
+			Mode:    0644,
+			Size:    int64(len(content)),
+			ModTime: commit.Committer.When,
+		}); err != nil {
+			return err
+		}
+		_, err = tarWriter.Write([]byte(content))
+		return err
+	})
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	if err := tarWriter.Close(); err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return buf.Bytes(), nil
+}
+
+// GetHTTPURL returns the base url of repositories,
+// it is a file url if there is no remote server
+func (h *helper) GetHTTPURL(ctx context.Context) string {
+	if h.remoteURL != "" {
+		return h.remoteURL
+	}
+	return "file://" + h.rootDir
+}
+
+func (h *helper) GetCreatedGroup(ctx context.Context, parentID int,
+	parentFullPath string, name string, visibility string) (*gitlab.Group, error) {
+	group, err := h.GetGroup(ctx, fmt.Sprintf("%v/%v", parentFullPath, name))
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+		return h.CreateGroup(ctx, name, name, &parentID, visibility)
+	}
+
+	return group, nil
+}
+
+func (h *helper) openRepo(ctx context.Context, pid interface{}) (string, *git.Repository, error) {
+	projectPath, err := h.resolvePath(pid)
+	if err != nil {
+		return "", nil, err
+	}
+	repoDir := h.repoDir(projectPath)
+	if !h.exists(repoDir) {
+		if h.remoteURL == "" {
+			return "", nil, herrors.NewErrNotFound(herrors.GitRepoResource,
+				fmt.Sprintf("project %v not found", pid))
+		}
+		// the local copy may be lost, e.g. on a new disk, clone it from remote
+		if err := h.clone(ctx, projectPath); err != nil {
+			return "", nil, err
+		}
+	}
+	repo, err := git.PlainOpen(repoDir)
+	if err != nil {
+		return "", nil, parseError(err, fmt.Sprintf("project %v", pid))
+	}
+	if _, err := h.register(projectPath); err != nil {
+		return "", nil, err
+	}
+	return projectPath, repo, nil
+}
+
+func (h *helper) clone(ctx context.Context, projectPath string) error {
+	unlock := h.lockProject(projectPath)
+	defer unlock()
+
+	repoDir := h.repoDir(projectPath)
+	if h.exists(repoDir) {
+		return nil
+	}
+	_, err := git.PlainCloneContext(ctx, repoDir, true, &git.CloneOptions{
+		URL:        h.remoteRepoURL(projectPath),
+		RemoteName: _remoteName,
+		Auth:       h.auth,
+	})
+	if err != nil {
+		_ = os.RemoveAll(repoDir)
+		return parseError(err, fmt.Sprintf("project %s", projectPath))
+	}
+	return nil
+}
+
+// fetch makes local branches and tags up-to-date with the remote server
+func (h *helper) fetch(ctx context.Context, repo *git.Repository) error {
+	if h.remoteURL == "" {
+		return nil
+	}
+	err := repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: _remoteName,
+		RefSpecs: []gitconfig.RefSpec{
+			"+refs/heads/*:refs/heads/*",
+			"+refs/tags/*:refs/tags/*",
+			gitconfig.RefSpec(fmt.Sprintf("+%s*:%s*", _mergeRequestRefPrefix, _mergeRequestRefPrefix)),
+		},
+		Auth:  h.auth,
+		Force: true,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return perror.Wrapf(herrors.ErrGitRepoInternal, "failed to fetch from remote: %s", err.Error())
+	}
+	return nil
+}
+
+// push pushes the given refs to the remote server, a ref starting with ':' will be deleted,
+// and a ref starting with '+' is pushed even if it's not a fast-forward
+func (h *helper) push(ctx context.Context, repo *git.Repository, refs ...string) error {
+	if h.remoteURL == "" {
+		return nil
+	}
+	refSpecs := make([]gitconfig.RefSpec, 0, len(refs))
+	for _, ref := range refs {
+		switch {
+		case strings.HasPrefix(ref, ":"):
+			refSpecs = append(refSpecs, gitconfig.RefSpec(ref))
+		case strings.HasPrefix(ref, "+"):
+			refSpecs = append(refSpecs, gitconfig.RefSpec(fmt.Sprintf("%s:%s", ref, strings.TrimPrefix(ref, "+"))))
+		default:
+			refSpecs = append(refSpecs, gitconfig.RefSpec(fmt.Sprintf("%s:%s", ref, ref)))
+		}
+	}
+	err := repo.PushContext(ctx, &git.PushOptions{
+		RemoteName: _remoteName,
+		RefSpecs:   refSpecs,
+		Auth:       h.auth,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return perror.Wrapf(herrors.ErrGitRepoInternal, "failed to push to remote: %s", err.Error())
+	}
+	return nil
+}
+
+// updateRef moves the branch from old to new and pushes it,
+// it fails if the branch has been changed by others
+func (h *helper) updateRef(ctx context.Context, repo *git.Repository,
+	refName plumbing.ReferenceName, old, new plumbing.Hash) error {
+	var oldRef *plumbing.Reference
+	if _, err := repo.Reference(refName, false); err == nil {
+		oldRef = plumbing.NewHashReference(refName, old)
+	}
+	if err := repo.Storer.CheckAndSetReference(plumbing.NewHashReference(refName, new), oldRef); err != nil {
+		return perror.Wrapf(herrors.ErrGitRepoInternal, "failed to update %s: %s", refName, err.Error())
+	}
+	return h.push(ctx, repo, refName.String())
+}
+
+func (h *helper) deleteBranch(ctx context.Context, repo *git.Repository, branch string) error {
+	refName := plumbing.NewBranchReferenceName(branch)
+	if _, err := repo.Reference(refName, false); err != nil {
+		return parseError(err, fmt.Sprintf("branch %s", branch))
+	}
+	if err := repo.Storer.RemoveReference(refName); err != nil {
+		return herrors.NewErrDeleteFailed(herrors.GitRepoResource, err.Error())
+	}
+	return h.push(ctx, repo, ":"+refName.String())
+}
+
+// merge merges source branch into target branch with a merge commit
+func (h *helper) merge(ctx context.Context, repo *git.Repository,
+	source, target, message string) (*object.Commit, error) {
+	targetRefName := plumbing.NewBranchReferenceName(target)
+	targetRef, err := repo.Reference(targetRefName, true)
+	if err != nil {
+		return nil, parseError(err, fmt.Sprintf("branch %s", target))
+	}
+	sourceRef, err := repo.Reference(plumbing.NewBranchReferenceName(source), true)
+	if err != nil {
+		return nil, parseError(err, fmt.Sprintf("branch %s", source))
+	}
+	targetCommit, err := repo.CommitObject(targetRef.Hash())
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	sourceCommit, err := repo.CommitObject(sourceRef.Hash())
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+
+	// nothing to merge if source is already contained in target
+	merged, err := sourceCommit.IsAncestor(targetCommit)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	if merged || sourceCommit.Hash == targetCommit.Hash {
+		return targetCommit, nil
+	}
+
+	base, err := mergeBase(sourceCommit, targetCommit)
+	if err != nil {
+		return nil, err
+	}
+	baseFiles := map[string]treeEntry{}
+	if base != nil {
+		if baseFiles, err = flattenCommit(base); err != nil {
+			return nil, err
+		}
+	}
+	ours, err := flattenCommit(targetCommit)
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := flattenCommit(sourceCommit)
+	if err != nil {
+		return nil, err
+	}
+	files, conflicts := mergeFiles(baseFiles, ours, theirs)
+	if len(conflicts) > 0 {
+		return nil, perror.Wrapf(herrors.ErrGitMergeConflict,
+			"failed to merge %s into %s, conflicts: %s", source, target, strings.Join(conflicts, ", "))
+	}
+
+	commit, err := h.commit(repo, files, message, []plumbing.Hash{targetCommit.Hash, sourceCommit.Hash})
+	if err != nil {
+		return nil, err
+	}
+	if err := h.updateRef(ctx, repo, targetRefName, targetCommit.Hash, commit.Hash); err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+// commit writes the files as a tree and creates a commit object for it
+func (h *helper) commit(repo *git.Repository, files map[string]treeEntry,
+	message string, parents []plumbing.Hash) (*object.Commit, error) {
+	treeHash, err := writeTree(repo.Storer, files)
+	if err != nil {
+		return nil, err
+	}
+	signature := object.Signature{
+		Name:  h.author,
+		Email: _defaultEmail,
+		When:  time.Now(),
+	}
+	commit := &object.Commit{
+		Author:       signature,
+		Committer:    signature,
+		Message:      message,
+		TreeHash:     treeHash,
+		ParentHashes: parents,
+	}
+	obj := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return repo.CommitObject(hash)
+}
+
+func (h *helper) moveProject(ctx context.Context, from, to string) error {
+	to, err := cleanPath(to)
+	if err != nil {
+		return err
+	}
+	if !h.exists(h.repoDir(from)) {
+		return herrors.NewErrNotFound(herrors.GitRepoResource, fmt.Sprintf("project %s not found", from))
+	}
+	if h.exists(h.repoDir(to)) {
+		return perror.Wrapf(herrors.ErrNameConflict, "path %s has already been taken", to)
+	}
+	if err := h.checkID(to); err != nil {
+		return err
+	}
+
+	unlock := h.lockProject(from)
+	defer unlock()
+
+	if err := os.Rename(h.repoDir(from), h.repoDir(to)); err != nil {
+		return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	h.unregister(from)
+	if _, err := h.register(to); err != nil {
+		return err
+	}
+
+	if h.remoteURL == "" {
+		return nil
+	}
+	// mirror all refs to the new remote repository
+	repo, err := git.PlainOpen(h.repoDir(to))
+	if err != nil {
+		return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	if err := repo.DeleteRemote(_remoteName); err != nil && err != git.ErrRemoteNotFound {
+		return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	if _, err := repo.CreateRemote(&gitconfig.RemoteConfig{
+		Name: _remoteName,
+		URLs: []string{h.remoteRepoURL(to)},
+	}); err != nil {
+		return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	log.Warningf(ctx, "project %s is moved to %s, the old remote repository is kept", from, to)
+	return h.push(ctx, repo, "refs/heads/*", "refs/tags/*")
+}
+
+// saveMR stores the merge request in the project and pushes it, an existing one is overwritten if update is true
+func (h *helper) saveMR(ctx context.Context, repo *git.Repository, mr *gitlab.MergeRequest, update bool) error {
+	content, err := json.Marshal(mr)
+	if err != nil {
+		return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	blob, err := writeBlob(repo.Storer, string(content))
+	if err != nil {
+		return err
+	}
+	refName := mergeRequestRefName(mr.IID)
+	if !update {
+		if _, err := repo.Reference(refName, false); err == nil {
+			return perror.Wrapf(herrors.ErrNameConflict, "merge request %d already exists", mr.IID)
+		}
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(refName, blob.Hash)); err != nil {
+		return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	if update {
+		return h.push(ctx, repo, "+"+refName.String())
+	}
+	return h.push(ctx, repo, refName.String())
+}
+
+func getMR(repo *git.Repository, projectPath string, mrID int) (*gitlab.MergeRequest, error) {
+	ref, err := repo.Reference(mergeRequestRefName(mrID), false)
+	if err != nil {
+		if err == plumbing.ErrReferenceNotFound {
+			return nil, herrors.NewErrNotFound(herrors.GitRepoResource,
+				fmt.Sprintf("merge request %d of project %s not found", mrID, projectPath))
+		}
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return readMR(repo, ref.Hash())
+}
+
+// listMRs lists the merge requests stored in the project in the order of iid
+func listMRs(repo *git.Repository) ([]*gitlab.MergeRequest, error) {
+	iter, err := repo.References()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	mrs := make([]*gitlab.MergeRequest, 0)
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference ||
+			!strings.HasPrefix(ref.Name().String(), _mergeRequestRefPrefix) {
+			return nil
+		}
+		mr, err := readMR(repo, ref.Hash())
+		if err != nil {
+			return err
+		}
+		mrs = append(mrs, mr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(mrs, func(i, j int) bool { return mrs[i].IID < mrs[j].IID })
+	return mrs, nil
+}
+
+func readMR(repo *git.Repository, hash plumbing.Hash) (*gitlab.MergeRequest, error) {
+	blob, err := repo.BlobObject(hash)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	defer func() { _ = reader.Close() }()
+	var mr gitlab.MergeRequest
+	if err := json.NewDecoder(reader).Decode(&mr); err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return &mr, nil
+}
+
+func mergeRequestRefName(iid int) plumbing.ReferenceName {
+	return plumbing.ReferenceName(fmt.Sprintf("%s%d", _mergeRequestRefPrefix, iid))
+}
+
+func (h *helper) lockProject(projectPath string) func() {
+	lock, _ := h.projectLocks.LoadOrStore(projectPath, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	return lock.(*sync.Mutex).Unlock
+}
+
+// idOf derives the id of a group or project from its full path
+func idOf(fullPath string) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(fullPath))
+	return int(hash.Sum32() & 0x7fffffff)
+}
+
+// checkID checks the id of a group or project to create does not collide with an existing one,
+// otherwise the reads and writes by the id would be sent to the other one
+func (h *helper) checkID(fullPath string) error {
+	id := idOf(fullPath)
+	h.idsLock.RLock()
+	defer h.idsLock.RUnlock()
+	if p, ok := h.ids[id]; ok && p != fullPath {
+		return perror.Wrapf(herrors.ErrNameConflict, "id of path %s collides with %s", fullPath, p)
+	}
+	return nil
+}
+
+// register records the id of a group or project and returns it, it fails if the id collides with another one
+func (h *helper) register(fullPath string) (int, error) {
+	id := idOf(fullPath)
+	h.idsLock.Lock()
+	defer h.idsLock.Unlock()
+	if p, ok := h.ids[id]; ok && p != fullPath {
+		return 0, perror.Wrapf(herrors.ErrGitRepoInternal, "id of path %s collides with %s", fullPath, p)
+	}
+	h.ids[id] = fullPath
+	return id, nil
+}
+
+// unregister removes the ids of the group or project and its descendants
+func (h *helper) unregister(fullPath string) {
+	h.idsLock.Lock()
+	defer h.idsLock.Unlock()
+	for id, p := range h.ids {
+		if p == fullPath || strings.HasPrefix(p, fullPath+"/") {
+			delete(h.ids, id)
+		}
+	}
+}
+
+// resolvePath converts an id or a relative path to a clean full path
+func (h *helper) resolvePath(id interface{}) (string, error) {
+	switch v := id.(type) {
+	case string:
+		return cleanPath(v)
+	case int:
+		if p, ok := h.lookup(v); ok {
+			return p, nil
+		}
+		// ids are lost after restart, walk the root dir to recover them
+		if err := h.scan(); err != nil {
+			return "", err
+		}
+		if p, ok := h.lookup(v); ok {
+			return p, nil
+		}
+		return "", herrors.NewErrNotFound(herrors.GitRepoResource, fmt.Sprintf("id %d not found", v))
+	default:
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "invalid id type %T", id)
+	}
+}
+
+func (h *helper) lookup(id int) (string, bool) {
+	h.idsLock.RLock()
+	defer h.idsLock.RUnlock()
+	p, ok := h.ids[id]
+	return p, ok
+}
+
+func (h *helper) scan() error {
+	err := filepath.Walk(h.rootDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() || p == h.rootDir {
+			return nil
+		}
+		rel, err := filepath.Rel(h.rootDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if strings.HasSuffix(rel, _projectSuffix) {
+			if _, err := h.register(strings.TrimSuffix(rel, _projectSuffix)); err != nil {
+				return err
+			}
+			return filepath.SkipDir
+		}
+		_, err = h.register(rel)
+		return err
+	})
+	if err != nil {
+		return perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return nil
+}
+
+func (h *helper) group(fullPath string) (*gitlab.Group, error) {
+	id, err := h.register(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	group := &gitlab.Group{
+		ID:       id,
+		Name:     path.Base(fullPath),
+		Path:     path.Base(fullPath),
+		FullPath: fullPath,
+		FullName: fullPath,
+		WebURL:   fmt.Sprintf("%s/%s", h.GetHTTPURL(context.Background()), fullPath),
+	}
+	if parent := path.Dir(fullPath); parent != "." {
+		if group.ParentID, err = h.register(parent); err != nil {
+			return nil, err
+		}
+	}
+	return group, nil
+}
+
+func (h *helper) project(fullPath string) (*gitlab.Project, error) {
+	id, err := h.register(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	namespace := path.Dir(fullPath)
+	project := &gitlab.Project{
+		ID:                id,
+		Name:              path.Base(fullPath),
+		Path:              path.Base(fullPath),
+		PathWithNamespace: fullPath,
+		DefaultBranch:     h.defaultBranch,
+		HTTPURLToRepo:     fmt.Sprintf("%s/%s%s", h.GetHTTPURL(context.Background()), fullPath, _projectSuffix),
+		WebURL:            fmt.Sprintf("%s/%s", h.GetHTTPURL(context.Background()), fullPath),
+	}
+	if namespace != "." {
+		namespaceID, err := h.register(namespace)
+		if err != nil {
+			return nil, err
+		}
+		project.Namespace = &gitlab.ProjectNamespace{
+			ID:       namespaceID,
+			Name:     path.Base(namespace),
+			Path:     path.Base(namespace),
+			Kind:     "group",
+			FullPath: namespace,
+		}
+	}
+	return project, nil
+}
+
+func (h *helper) toGitlabBranch(repo *git.Repository, ref *plumbing.Reference) (*gitlab.Branch, error) {
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return &gitlab.Branch{
+		Name:    ref.Name().Short(),
+		Commit:  toGitlabCommit(commit),
+		Default: ref.Name().Short() == h.defaultBranch,
+		CanPush: true,
+	}, nil
+}
+
+func (h *helper) isGroup(groupPath string) bool {
+	info, err := os.Stat(h.dir(groupPath))
+	return err == nil && info.IsDir() && !strings.HasSuffix(groupPath, _projectSuffix)
+}
+
+func (h *helper) exists(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
+}
+
+func (h *helper) dir(fullPath string) string {
+	return filepath.Join(h.rootDir, filepath.FromSlash(fullPath))
+}
+
+func (h *helper) repoDir(fullPath string) string {
+	return h.dir(fullPath) + _projectSuffix
+}
+
+func (h *helper) remoteRepoURL(fullPath string) string {
+	return fmt.Sprintf("%s/%s%s", h.remoteURL, fullPath, _projectSuffix)
+}
+
+func resolveCommit(repo *git.Repository, rev string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, parseError(err, fmt.Sprintf("revision %s", rev))
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, parseError(err, fmt.Sprintf("commit %s", hash))
+	}
+	return commit, nil
+}
+
+func listRefs(repo *git.Repository, branch bool, search string) ([]*plumbing.Reference, error) {
+	refs := make([]*plumbing.Reference, 0)
+	iter, err := repo.References()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if (branch && !ref.Name().IsBranch()) || (!branch && !ref.Name().IsTag()) {
+			return nil
+		}
+		if search != "" && !strings.Contains(ref.Name().Short(), search) {
+			return nil
+		}
+		refs = append(refs, ref)
+		return nil
+	})
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].Name().Short() < refs[j].Name().Short()
+	})
+	return refs, nil
+}
+
+func toGitlabTag(repo *git.Repository, ref *plumbing.Reference) (*gitlab.Tag, error) {
+	tag := &gitlab.Tag{Name: ref.Name().Short()}
+	commitHash := ref.Hash()
+	// annotated tag points to a tag object
+	if tagObject, err := repo.TagObject(ref.Hash()); err == nil {
+		tag.Message = tagObject.Message
+		commitHash = tagObject.Target
+	}
+	commit, err := repo.CommitObject(commitHash)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	tag.Commit = toGitlabCommit(commit)
+	return tag, nil
+}
+
+func toGitlabCommit(c *object.Commit) *gitlab.Commit {
+	parents := make([]string, 0, len(c.ParentHashes))
+	for _, parent := range c.ParentHashes {
+		parents = append(parents, parent.String())
+	}
+	authoredDate, committedDate := c.Author.When, c.Committer.When
+	id := c.Hash.String()
+	return &gitlab.Commit{
+		ID:             id,
+		ShortID:        id[:8],
+		Title:          strings.SplitN(c.Message, "\n", 2)[0],
+		AuthorName:     c.Author.Name,
+		AuthorEmail:    c.Author.Email,
+		AuthoredDate:   &authoredDate,
+		CommitterName:  c.Committer.Name,
+		CommitterEmail: c.Committer.Email,
+		CommittedDate:  &committedDate,
+		CreatedAt:      &committedDate,
+		Message:        c.Message,
+		ParentIDs:      parents,
+	}
+}
+
+func mergeBase(a, b *object.Commit) (*object.Commit, error) {
+	bases, err := a.MergeBase(b)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	if len(bases) == 0 {
+		return nil, nil
+	}
+	return bases[0], nil
+}
+
+// paginate returns the bounds of the page, page starts from 1 and perPage defaults to 20 like gitlab
+func paginate(total int, options gitlab.ListOptions) (int, int) {
+	page, perPage := options.Page, options.PerPage
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 20
+	}
+	start := (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+func cleanPath(p string) (string, error) {
+	cleaned := path.Clean(strings.Trim(p, "/"))
+	if cleaned == "." {
+		return "", nil
+	}
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "invalid path %s", p)
+	}
+	return cleaned, nil
+}
+
+func parseError(err error, target string) error {
+	if err == nil {
+		return nil
+	}
+	switch err {
+	case plumbing.ErrReferenceNotFound, plumbing.ErrObjectNotFound, object.ErrFileNotFound,
+		object.ErrDirectoryNotFound, git.ErrRepositoryNotExists, transport.ErrRepositoryNotFound,
+		transport.ErrEmptyRemoteRepository:
+		return herrors.NewErrNotFound(herrors.GitRepoResource, fmt.Sprintf("%s: %s", target, err.Error()))
+	}
+	return perror.Wrapf(herrors.ErrGitRepoInternal, "%s: %s", target, err.Error())
+}
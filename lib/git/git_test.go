@@ -0,0 +1,296 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLib(t *testing.T) (gitlablib.Interface, func()) {
+	dir, err := ioutil.TempDir("", "horizon-git")
+	assert.Nil(t, err)
+	g, err := New(Config{RootDir: dir})
+	assert.Nil(t, err)
+	return g, func() { _ = os.RemoveAll(dir) }
+}
+
+func TestGroupAndProject(t *testing.T) {
+	ctx := context.Background()
+	g, cleanup := newTestLib(t)
+	defer cleanup()
+
+	_, err := g.GetGroup(ctx, "root")
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	root, err := g.CreateGroup(ctx, "root", "root", nil, "private")
+	assert.Nil(t, err)
+	sub, err := g.GetCreatedGroup(ctx, root.ID, root.FullPath, "sub", "private")
+	assert.Nil(t, err)
+	assert.Equal(t, "root/sub", sub.FullPath)
+	assert.Equal(t, root.ID, sub.ParentID)
+
+	// get by id and by path
+	group, err := g.GetGroup(ctx, sub.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, sub.FullPath, group.FullPath)
+
+	project, err := g.CreateProject(ctx, "demo", sub.ID, "private")
+	assert.Nil(t, err)
+	assert.Equal(t, "root/sub/demo", project.PathWithNamespace)
+	_, err = g.CreateProject(ctx, "demo", sub.ID, "private")
+	assert.Equal(t, herrors.ErrNameConflict, perror.Cause(err))
+
+	readme, err := g.GetFile(ctx, project.ID, "master", "README.md")
+	assert.Nil(t, err)
+	assert.Equal(t, "# demo\n", string(readme))
+
+	projects, err := g.ListGroupProjects(ctx, sub.FullPath, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(projects))
+
+	// rename and transfer
+	other, err := g.CreateGroup(ctx, "other", "other", &root.ID, "private")
+	assert.Nil(t, err)
+	newName := "demo-1"
+	assert.Nil(t, g.EditNameAndPathForProject(ctx, project.ID, &newName, &newName))
+	assert.Nil(t, g.TransferProject(ctx, "root/sub/demo-1", other.FullPath))
+	_, err = g.GetProject(ctx, "root/other/demo-1")
+	assert.Nil(t, err)
+	_, err = g.GetProject(ctx, "root/sub/demo")
+	_, ok = perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	assert.Nil(t, g.DeleteProject(ctx, "root/other/demo-1"))
+	assert.Nil(t, g.DeleteGroup(ctx, root.ID))
+}
+
+func TestWriteCompareAndMerge(t *testing.T) {
+	ctx := context.Background()
+	g, cleanup := newTestLib(t)
+	defer cleanup()
+
+	root, err := g.CreateGroup(ctx, "root", "root", nil, "private")
+	assert.Nil(t, err)
+	_, err = g.CreateProject(ctx, "cluster", root.ID, "private")
+	assert.Nil(t, err)
+	pid := "root/cluster"
+
+	_, err = g.CreateBranch(ctx, pid, "gitops", "master")
+	assert.Nil(t, err)
+
+	commit, err := g.WriteFiles(ctx, pid, "gitops", "add values", nil, []gitlablib.CommitAction{
+		{Action: gitlablib.FileCreate, FilePath: "values/application.yaml", Content: "replicas: 1\n"},
+		{Action: gitlablib.FileCreate, FilePath: "Chart.yaml", Content: "name: demo\n"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "add values", commit.Title)
+
+	// creating an existing file fails like gitlab
+	_, err = g.WriteFiles(ctx, pid, "gitops", "add again", nil, []gitlablib.CommitAction{
+		{Action: gitlablib.FileCreate, FilePath: "Chart.yaml", Content: "name: demo\n"},
+	})
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+
+	compare, err := g.Compare(ctx, pid, "master", "gitops", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(compare.Diffs))
+	for _, diff := range compare.Diffs {
+		assert.True(t, diff.NewFile)
+	}
+
+	// restart writes to master directly, which is merged with gitops without conflicts
+	_, err = g.WriteFiles(ctx, pid, "master", "restart", nil, []gitlablib.CommitAction{
+		{Action: gitlablib.FileCreate, FilePath: "restart.yaml", Content: "restartTime: now\n"},
+	})
+	assert.Nil(t, err)
+
+	mr, err := g.CreateMR(ctx, pid, "gitops", "master", "merge gitops")
+	assert.Nil(t, err)
+	mrs, err := g.ListMRs(ctx, pid, "gitops", "master", "opened")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(mrs))
+	merged, err := g.AcceptMR(ctx, pid, mr.IID, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "merged", merged.State)
+
+	master, err := g.GetBranch(ctx, pid, "master")
+	assert.Nil(t, err)
+	assert.Equal(t, merged.MergeCommitSHA, master.Commit.ID)
+	assert.Equal(t, 2, len(master.Commit.ParentIDs))
+	for _, file := range []string{"values/application.yaml", "Chart.yaml", "restart.yaml", "README.md"} {
+		_, err := g.GetFile(ctx, pid, "master", file)
+		assert.Nil(t, err, file)
+	}
+
+	// straight compare between the old commit and gitops shows nothing
+	compare, err = g.Compare(ctx, pid, commit.ID, "gitops", func() *bool { b := true; return &b }())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(compare.Diffs))
+
+	// update and delete, then compare straight to get reverting diffs
+	_, err = g.WriteFiles(ctx, pid, "gitops", "update values", nil, []gitlablib.CommitAction{
+		{Action: gitlablib.FileUpdate, FilePath: "values/application.yaml", Content: "replicas: 2\n"},
+		{Action: gitlablib.FileDelete, FilePath: "Chart.yaml"},
+	})
+	assert.Nil(t, err)
+	compare, err = g.Compare(ctx, pid, "gitops", commit.ID, func() *bool { b := true; return &b }())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(compare.Diffs))
+	for _, diff := range compare.Diffs {
+		switch diff.NewPath {
+		case "Chart.yaml":
+			assert.True(t, diff.NewFile)
+		case "values/application.yaml":
+			assert.False(t, diff.NewFile || diff.DeletedFile || diff.RenamedFile)
+			assert.Contains(t, diff.Diff, "-replicas: 2")
+			assert.Contains(t, diff.Diff, "+replicas: 1")
+		default:
+			t.Fatalf("unexpected diff %s", diff.NewPath)
+		}
+	}
+
+	content, err := g.GetFile(ctx, pid, commit.ID, "values/application.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, "replicas: 1\n", string(content))
+
+	archive, err := g.GetRepositoryArchive(ctx, pid, "gitops")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, archive)
+}
+
+func TestPersistence(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "horizon-git")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	g, err := New(Config{RootDir: dir})
+	assert.Nil(t, err)
+	root, err := g.CreateGroup(ctx, "root", "root", nil, "private")
+	assert.Nil(t, err)
+	project, err := g.CreateProject(ctx, "cluster", root.ID, "private")
+	assert.Nil(t, err)
+	_, err = g.CreateBranch(ctx, project.ID, "gitops", "master")
+	assert.Nil(t, err)
+	mr, err := g.CreateMR(ctx, project.ID, "gitops", "master", "merge gitops")
+	assert.Nil(t, err)
+
+	// another instance sharing the root dir refuses to start
+	_, err = New(Config{RootDir: dir})
+	assert.Equal(t, herrors.ErrGitRepoInternal, perror.Cause(err))
+
+	// the restarted instance resolves the ids and sees the merge request
+	assert.Nil(t, g.(*helper).rootLock.Close())
+	restarted, err := New(Config{RootDir: dir})
+	assert.Nil(t, err)
+	mrs, err := restarted.ListMRs(ctx, project.ID, "gitops", "master", "opened")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(mrs))
+	assert.Equal(t, mr.IID, mrs[0].IID)
+	closed, err := restarted.CloseMR(ctx, project.ID, mr.IID)
+	assert.Nil(t, err)
+	assert.Equal(t, "closed", closed.State)
+
+	mrs, err = restarted.ListMRs(ctx, project.ID, "gitops", "master", "opened")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(mrs))
+	next, err := restarted.CreateMR(ctx, project.ID, "gitops", "master", "merge gitops again")
+	assert.Nil(t, err)
+	assert.Equal(t, mr.IID+1, next.IID)
+}
+
+func TestIDCollision(t *testing.T) {
+	ctx := context.Background()
+	g, cleanup := newTestLib(t)
+	defer cleanup()
+
+	// find two project names whose ids collide
+	var first, second string
+	names := make(map[int]string)
+	for i := 0; first == ""; i++ {
+		name := fmt.Sprintf("p%d", i)
+		id := idOf("root/" + name)
+		if other, ok := names[id]; ok {
+			first, second = other, name
+		}
+		names[id] = name
+	}
+
+	root, err := g.CreateGroup(ctx, "root", "root", nil, "private")
+	assert.Nil(t, err)
+	_, err = g.CreateProject(ctx, first, root.ID, "private")
+	assert.Nil(t, err)
+	_, err = g.CreateProject(ctx, second, root.ID, "private")
+	assert.Equal(t, herrors.ErrNameConflict, perror.Cause(err))
+}
+
+func TestMergeConflict(t *testing.T) {
+	ctx := context.Background()
+	g, cleanup := newTestLib(t)
+	defer cleanup()
+
+	root, err := g.CreateGroup(ctx, "root", "root", nil, "private")
+	assert.Nil(t, err)
+	_, err = g.CreateProject(ctx, "cluster", root.ID, "private")
+	assert.Nil(t, err)
+	pid := "root/cluster"
+	_, err = g.CreateBranch(ctx, pid, "gitops", "master")
+	assert.Nil(t, err)
+
+	for _, branch := range []string{"master", "gitops"} {
+		_, err = g.WriteFiles(ctx, pid, branch, "update readme", nil, []gitlablib.CommitAction{
+			{Action: gitlablib.FileUpdate, FilePath: "README.md", Content: fmt.Sprintf("# %s\n", branch)},
+		})
+		assert.Nil(t, err)
+	}
+
+	mr, err := g.CreateMR(ctx, pid, "gitops", "master", "merge gitops")
+	assert.Nil(t, err)
+	_, err = g.AcceptMR(ctx, pid, mr.IID, nil, nil)
+	assert.Equal(t, herrors.ErrGitMergeConflict, perror.Cause(err))
+
+	closed, err := g.CloseMR(ctx, pid, mr.IID)
+	assert.Nil(t, err)
+	assert.Equal(t, "closed", closed.State)
+}
+
+func TestMergeFiles(t *testing.T) {
+	entry := func(s string) treeEntry {
+		var e treeEntry
+		copy(e.Hash[:], s)
+		return e
+	}
+	base := map[string]treeEntry{"a": entry("a"), "b": entry("b"), "c": entry("c")}
+	ours := map[string]treeEntry{"a": entry("a1"), "b": entry("b"), "c": entry("c1")}
+	theirs := map[string]treeEntry{"a": entry("a"), "c": entry("c2"), "d": entry("d")}
+
+	result, conflicts := mergeFiles(base, ours, theirs)
+	assert.Equal(t, []string{"c"}, conflicts)
+	assert.Equal(t, entry("a1"), result["a"])
+	_, ok := result["b"]
+	assert.False(t, ok)
+	assert.Equal(t, entry("d"), result["d"])
+}
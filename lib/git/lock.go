@@ -0,0 +1,42 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+// +build !windows
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// lockRootDir locks the root dir exclusively, so that another instance sharing the root dir
+// refuses to start. The lock is released when the returned file is closed or the process exits.
+func lockRootDir(rootDir string) (*os.File, error) {
+	file, err := os.OpenFile(filepath.Join(rootDir, _lockFile), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = file.Close()
+		return nil, perror.Wrapf(herrors.ErrGitRepoInternal,
+			"root dir %s is locked by another instance, only a single replica is supported: %v", rootDir, err)
+	}
+	return file, nil
+}
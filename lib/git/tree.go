@@ -0,0 +1,304 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+	"github.com/xanzy/go-gitlab"
+)
+
+// treeEntry is a file in a flattened tree
+type treeEntry struct {
+	Hash plumbing.Hash
+	Mode filemode.FileMode
+}
+
+// flattenCommit returns all files of the commit keyed by their full path
+func flattenCommit(commit *object.Commit) (map[string]treeEntry, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	files := map[string]treeEntry{}
+	err = tree.Files().ForEach(func(file *object.File) error {
+		files[file.Name] = treeEntry{Hash: file.Hash, Mode: file.Mode}
+		return nil
+	})
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return files, nil
+}
+
+func writeBlob(s storer.EncodedObjectStorer, content string) (treeEntry, error) {
+	obj := s.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		return treeEntry{}, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		return treeEntry{}, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	if err := w.Close(); err != nil {
+		return treeEntry{}, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	hash, err := s.SetEncodedObject(obj)
+	if err != nil {
+		return treeEntry{}, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return treeEntry{Hash: hash, Mode: filemode.Regular}, nil
+}
+
+type treeNode struct {
+	files map[string]treeEntry
+	dirs  map[string]*treeNode
+}
+
+// writeTree writes the flattened files as nested tree objects and returns the root tree hash
+func writeTree(s storer.EncodedObjectStorer, files map[string]treeEntry) (plumbing.Hash, error) {
+	root := &treeNode{files: map[string]treeEntry{}, dirs: map[string]*treeNode{}}
+	for p, entry := range files {
+		node := root
+		parts := strings.Split(p, "/")
+		for _, dir := range parts[:len(parts)-1] {
+			child, ok := node.dirs[dir]
+			if !ok {
+				child = &treeNode{files: map[string]treeEntry{}, dirs: map[string]*treeNode{}}
+				node.dirs[dir] = child
+			}
+			node = child
+		}
+		node.files[parts[len(parts)-1]] = entry
+	}
+	return writeTreeNode(s, root)
+}
+
+func writeTreeNode(s storer.EncodedObjectStorer, node *treeNode) (plumbing.Hash, error) {
+	entries := make([]object.TreeEntry, 0, len(node.files)+len(node.dirs))
+	for name, entry := range node.files {
+		entries = append(entries, object.TreeEntry{Name: name, Mode: entry.Mode, Hash: entry.Hash})
+	}
+	for name, child := range node.dirs {
+		hash, err := writeTreeNode(s, child)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		entries = append(entries, object.TreeEntry{Name: name, Mode: filemode.Dir, Hash: hash})
+	}
+	// git sorts entries by name, and a directory is compared as if its name ends with '/'
+	sortKey := func(entry object.TreeEntry) string {
+		if entry.Mode == filemode.Dir {
+			return entry.Name + "/"
+		}
+		return entry.Name
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return sortKey(entries[i]) < sortKey(entries[j])
+	})
+
+	obj := s.NewEncodedObject()
+	if err := (&object.Tree{Entries: entries}).Encode(obj); err != nil {
+		return plumbing.ZeroHash, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	hash, err := s.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	return hash, nil
+}
+
+// applyActions applies commit actions to the flattened files with the same semantics as gitlab
+func applyActions(s storer.EncodedObjectStorer, files map[string]treeEntry,
+	actions []gitlablib.CommitAction) error {
+	for _, action := range actions {
+		filePath, err := cleanPath(action.FilePath)
+		if err != nil {
+			return err
+		}
+		if filePath == "" {
+			return perror.Wrap(herrors.ErrParamInvalid, "file path cannot be empty")
+		}
+		_, exists := files[filePath]
+		switch action.Action {
+		case gitlablib.FileCreate, gitlablib.FileUpdate:
+			if action.Action == gitlablib.FileCreate && exists {
+				return perror.Wrapf(herrors.ErrParamInvalid,
+					"a file with the name %s already exists", filePath)
+			}
+			if action.Action == gitlablib.FileUpdate && !exists {
+				return perror.Wrapf(herrors.ErrParamInvalid,
+					"a file with the name %s does not exist", filePath)
+			}
+			entry, err := writeBlob(s, action.Content)
+			if err != nil {
+				return err
+			}
+			files[filePath] = entry
+		case gitlablib.FileDelete:
+			if !exists {
+				return perror.Wrapf(herrors.ErrParamInvalid,
+					"a file with the name %s does not exist", filePath)
+			}
+			delete(files, filePath)
+		case gitlablib.FileMove:
+			previousPath, err := cleanPath(action.PreviousPath)
+			if err != nil {
+				return err
+			}
+			previous, ok := files[previousPath]
+			if !ok {
+				return perror.Wrapf(herrors.ErrParamInvalid,
+					"a file with the name %s does not exist", previousPath)
+			}
+			if exists {
+				return perror.Wrapf(herrors.ErrParamInvalid,
+					"a file with the name %s already exists", filePath)
+			}
+			delete(files, previousPath)
+			if action.Content != "" {
+				if previous, err = writeBlob(s, action.Content); err != nil {
+					return err
+				}
+			}
+			files[filePath] = previous
+		default:
+			return perror.Wrapf(herrors.ErrParamInvalid, "unsupported file action %s", action.Action)
+		}
+	}
+	return nil
+}
+
+// mergeFiles does a three-way merge on file level, a file changed on both sides
+// in different ways is reported as a conflict
+func mergeFiles(base, ours, theirs map[string]treeEntry) (map[string]treeEntry, []string) {
+	paths := map[string]struct{}{}
+	for _, files := range []map[string]treeEntry{base, ours, theirs} {
+		for p := range files {
+			paths[p] = struct{}{}
+		}
+	}
+
+	same := func(a, b map[string]treeEntry, p string) bool {
+		entryA, okA := a[p]
+		entryB, okB := b[p]
+		return okA == okB && entryA == entryB
+	}
+	take := func(result, from map[string]treeEntry, p string) {
+		if entry, ok := from[p]; ok {
+			result[p] = entry
+		}
+	}
+
+	result := map[string]treeEntry{}
+	conflicts := make([]string, 0)
+	for p := range paths {
+		switch {
+		case same(ours, theirs, p), same(base, theirs, p):
+			take(result, ours, p)
+		case same(base, ours, p):
+			take(result, theirs, p)
+		default:
+			conflicts = append(conflicts, p)
+		}
+	}
+	sort.Strings(conflicts)
+	return result, conflicts
+}
+
+// diffCommits returns diffs between two commits in the format of gitlab
+func diffCommits(ctx context.Context, from, to *object.Commit) ([]*gitlab.Diff, error) {
+	fromTree, err := from.Tree()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	changes, err := object.DiffTreeWithOptions(ctx, fromTree, toTree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+
+	diffs := make([]*gitlab.Diff, 0, len(changes))
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		d := &gitlab.Diff{
+			OldPath: change.From.Name,
+			NewPath: change.To.Name,
+			AMode:   modeString(change.From),
+			BMode:   modeString(change.To),
+		}
+		switch action {
+		case merkletrie.Insert:
+			d.NewFile = true
+			d.OldPath = d.NewPath
+		case merkletrie.Delete:
+			d.DeletedFile = true
+			d.NewPath = d.OldPath
+		case merkletrie.Modify:
+			d.RenamedFile = d.OldPath != d.NewPath
+		}
+
+		patch, err := change.PatchContext(ctx)
+		if err != nil {
+			return nil, perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+		}
+		if d.Diff, err = hunks(patch); err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs, nil
+}
+
+// hunks encodes the patch as unified diff without file headers, the same as gitlab does
+func hunks(patch diff.Patch) (string, error) {
+	var buf bytes.Buffer
+	if err := diff.NewUnifiedEncoder(&buf, diff.DefaultContextLines).Encode(patch); err != nil {
+		return "", perror.Wrap(herrors.ErrGitRepoInternal, err.Error())
+	}
+	content := buf.String()
+	if i := strings.Index(content, "@@"); i >= 0 {
+		return content[i:], nil
+	}
+	return "", nil
+}
+
+func modeString(entry object.ChangeEntry) string {
+	if entry.Name == "" {
+		return "0"
+	}
+	return fmt.Sprintf("%06o", uint32(entry.TreeEntry.Mode))
+}
@@ -271,6 +271,21 @@ func (mr *MockClusterGitRepoMockRecorder) MergeBranch(ctx, application, cluster,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeBranch", reflect.TypeOf((*MockClusterGitRepo)(nil).MergeBranch), ctx, application, cluster, sourceBranch, targetBranch, pipelineRunID)
 }
 
+// RenderCluster mocks base method.
+func (m *MockClusterGitRepo) RenderCluster(ctx context.Context, params *gitrepo.RenderClusterParams) (*gitrepo.RenderedCluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderCluster", ctx, params)
+	ret0, _ := ret[0].(*gitrepo.RenderedCluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenderCluster indicates an expected call of RenderCluster.
+func (mr *MockClusterGitRepoMockRecorder) RenderCluster(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderCluster", reflect.TypeOf((*MockClusterGitRepo)(nil).RenderCluster), ctx, params)
+}
+
 // Rollback mocks base method.
 func (m *MockClusterGitRepo) Rollback(ctx context.Context, application, cluster, commit string) (string, error) {
 	m.ctrl.T.Helper()
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	registry "github.com/horizoncd/horizon/pkg/cluster/registry"
 )
 
 // MockRegistry is a mock of Registry interface.
@@ -47,3 +48,47 @@ func (mr *MockRegistryMockRecorder) DeleteImage(ctx, appName, clusterName interf
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteImage", reflect.TypeOf((*MockRegistry)(nil).DeleteImage), ctx, appName, clusterName)
 }
+
+// DeleteImageTag mocks base method.
+func (m *MockRegistry) DeleteImageTag(ctx context.Context, appName, clusterName, tag string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteImageTag", ctx, appName, clusterName, tag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteImageTag indicates an expected call of DeleteImageTag.
+func (mr *MockRegistryMockRecorder) DeleteImageTag(ctx, appName, clusterName, tag interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteImageTag", reflect.TypeOf((*MockRegistry)(nil).DeleteImageTag), ctx, appName, clusterName, tag)
+}
+
+// GetImageTag mocks base method.
+func (m *MockRegistry) GetImageTag(ctx context.Context, appName, clusterName, tag string) (*registry.ImageTag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImageTag", ctx, appName, clusterName, tag)
+	ret0, _ := ret[0].(*registry.ImageTag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImageTag indicates an expected call of GetImageTag.
+func (mr *MockRegistryMockRecorder) GetImageTag(ctx, appName, clusterName, tag interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageTag", reflect.TypeOf((*MockRegistry)(nil).GetImageTag), ctx, appName, clusterName, tag)
+}
+
+// ListImageTags mocks base method.
+func (m *MockRegistry) ListImageTags(ctx context.Context, appName, clusterName string) ([]*registry.ImageTag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListImageTags", ctx, appName, clusterName)
+	ret0, _ := ret[0].([]*registry.ImageTag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListImageTags indicates an expected call of ListImageTags.
+func (mr *MockRegistryMockRecorder) ListImageTags(ctx, appName, clusterName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListImageTags", reflect.TypeOf((*MockRegistry)(nil).ListImageTags), ctx, appName, clusterName)
+}
@@ -36,31 +36,31 @@ func (m *MockFactory) EXPECT() *MockFactoryMockRecorder {
 }
 
 // GetTekton mocks base method
-func (m *MockFactory) GetTekton(environment string) (tekton.Interface, error) {
+func (m *MockFactory) GetTekton(environment, template string) (tekton.Interface, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTekton", environment)
+	ret := m.ctrl.Call(m, "GetTekton", environment, template)
 	ret0, _ := ret[0].(tekton.Interface)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetTekton indicates an expected call of GetTekton
-func (mr *MockFactoryMockRecorder) GetTekton(environment interface{}) *gomock.Call {
+func (mr *MockFactoryMockRecorder) GetTekton(environment, template interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTekton", reflect.TypeOf((*MockFactory)(nil).GetTekton), environment)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTekton", reflect.TypeOf((*MockFactory)(nil).GetTekton), environment, template)
 }
 
 // GetTektonCollector mocks base method
-func (m *MockFactory) GetTektonCollector(environment string) (collector.Interface, error) {
+func (m *MockFactory) GetTektonCollector(environment, template string) (collector.Interface, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTektonCollector", environment)
+	ret := m.ctrl.Call(m, "GetTektonCollector", environment, template)
 	ret0, _ := ret[0].(collector.Interface)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetTektonCollector indicates an expected call of GetTektonCollector
-func (mr *MockFactoryMockRecorder) GetTektonCollector(environment interface{}) *gomock.Call {
+func (mr *MockFactoryMockRecorder) GetTektonCollector(environment, template interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTektonCollector", reflect.TypeOf((*MockFactory)(nil).GetTektonCollector), environment)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTektonCollector", reflect.TypeOf((*MockFactory)(nil).GetTektonCollector), environment, template)
 }
@@ -35,6 +35,21 @@ func (m *MockManager) EXPECT() *MockManagerMockRecorder {
 	return m.recorder
 }
 
+// CountByRole mocks base method.
+func (m *MockManager) CountByRole(ctx context.Context, role string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByRole", ctx, role)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByRole indicates an expected call of CountByRole.
+func (mr *MockManagerMockRecorder) CountByRole(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByRole", reflect.TypeOf((*MockManager)(nil).CountByRole), ctx, role)
+}
+
 // Create mocks base method.
 func (m *MockManager) Create(ctx context.Context, member *models.Member) (*models.Member, error) {
 	m.ctrl.T.Helper()
@@ -212,6 +227,21 @@ func (mr *MockManagerMockRecorder) ListResourceOfMemberInfoByRole(ctx, resourceT
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListResourceOfMemberInfoByRole", reflect.TypeOf((*MockManager)(nil).ListResourceOfMemberInfoByRole), ctx, resourceType, memberInfo, role)
 }
 
+// ListResourceOfTeams mocks base method.
+func (m *MockManager) ListResourceOfTeams(ctx context.Context, resourceType models.ResourceType, teamIDs []uint) ([]uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListResourceOfTeams", ctx, resourceType, teamIDs)
+	ret0, _ := ret[0].([]uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListResourceOfTeams indicates an expected call of ListResourceOfTeams.
+func (mr *MockManagerMockRecorder) ListResourceOfTeams(ctx, resourceType, teamIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListResourceOfTeams", reflect.TypeOf((*MockManager)(nil).ListResourceOfTeams), ctx, resourceType, teamIDs)
+}
+
 // UpdateByID mocks base method.
 func (m *MockManager) UpdateByID(ctx context.Context, id uint, role string) (*models.Member, error) {
 	m.ctrl.T.Helper()
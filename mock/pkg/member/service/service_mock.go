@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	models0 "github.com/horizoncd/horizon/pkg/group/models"
 	models "github.com/horizoncd/horizon/pkg/member/models"
 	service "github.com/horizoncd/horizon/pkg/member/service"
 )
@@ -81,6 +82,21 @@ func (mr *MockServiceMockRecorder) GetMemberOfResource(ctx, resourceType, resour
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberOfResource", reflect.TypeOf((*MockService)(nil).GetMemberOfResource), ctx, resourceType, resourceID)
 }
 
+// ListInvisible mocks base method.
+func (m *MockService) ListInvisible(ctx context.Context) (*models0.Invisible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInvisible", ctx)
+	ret0, _ := ret[0].(*models0.Invisible)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInvisible indicates an expected call of ListInvisible.
+func (mr *MockServiceMockRecorder) ListInvisible(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvisible", reflect.TypeOf((*MockService)(nil).ListInvisible), ctx)
+}
+
 // ListMember mocks base method.
 func (m *MockService) ListMember(ctx context.Context, resourceType string, resourceID uint) ([]models.Member, error) {
 	m.ctrl.T.Helper()
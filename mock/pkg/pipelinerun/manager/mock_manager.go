@@ -7,6 +7,7 @@ package mock_manager
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	q "github.com/horizoncd/horizon/lib/q"
@@ -190,6 +191,51 @@ func (mr *MockPipelineRunManagerMockRecorder) GetLatestSuccessByClusterID(ctx, c
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestSuccessByClusterID", reflect.TypeOf((*MockPipelineRunManager)(nil).GetLatestSuccessByClusterID), ctx, clusterID)
 }
 
+// ListDeploys mocks base method.
+func (m *MockPipelineRunManager) ListDeploys(ctx context.Context, query *models.DeployQuery) ([]*models.Deploy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeploys", ctx, query)
+	ret0, _ := ret[0].([]*models.Deploy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeploys indicates an expected call of ListDeploys.
+func (mr *MockPipelineRunManagerMockRecorder) ListDeploys(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeploys", reflect.TypeOf((*MockPipelineRunManager)(nil).ListDeploys), ctx, query)
+}
+
+// ListScheduled mocks base method.
+func (m *MockPipelineRunManager) ListScheduled(ctx context.Context, before time.Time) ([]*models.Pipelinerun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduled", ctx, before)
+	ret0, _ := ret[0].([]*models.Pipelinerun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduled indicates an expected call of ListScheduled.
+func (mr *MockPipelineRunManagerMockRecorder) ListScheduled(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduled", reflect.TypeOf((*MockPipelineRunManager)(nil).ListScheduled), ctx, before)
+}
+
+// ListUnfinishedBuilds mocks base method.
+func (m *MockPipelineRunManager) ListUnfinishedBuilds(ctx context.Context, since time.Time) ([]*models.Pipelinerun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUnfinishedBuilds", ctx, since)
+	ret0, _ := ret[0].([]*models.Pipelinerun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUnfinishedBuilds indicates an expected call of ListUnfinishedBuilds.
+func (mr *MockPipelineRunManagerMockRecorder) ListUnfinishedBuilds(ctx, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUnfinishedBuilds", reflect.TypeOf((*MockPipelineRunManager)(nil).ListUnfinishedBuilds), ctx, since)
+}
+
 // UpdateCIEventIDByID mocks base method.
 func (m *MockPipelineRunManager) UpdateCIEventIDByID(ctx context.Context, pipelinerunID uint, ciEventID string) error {
 	m.ctrl.T.Helper()
@@ -171,3 +171,17 @@ func (mr *MockManagerMockRecorder) UpdateByID(ctx, releaseID, release interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByID", reflect.TypeOf((*MockManager)(nil).UpdateByID), ctx, releaseID, release)
 }
+
+// UpdateSyncStatus mocks base method.
+func (m *MockManager) UpdateSyncStatus(ctx context.Context, releaseID uint, release *models1.TemplateRelease) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSyncStatus", ctx, releaseID, release)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSyncStatus indicates an expected call of UpdateSyncStatus.
+func (mr *MockManagerMockRecorder) UpdateSyncStatus(ctx, releaseID, release interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSyncStatus", reflect.TypeOf((*MockManager)(nil).UpdateSyncStatus), ctx, releaseID, release)
+}
@@ -0,0 +1,106 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/accessaudit/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, audit *models.AccessAudit) (*models.AccessAudit, error)
+	BatchCreate(ctx context.Context, audits []*models.AccessAudit) error
+	List(ctx context.Context, query *q.Query) ([]*models.AccessAudit, int64, error)
+	DeleteBefore(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, audit *models.AccessAudit) (*models.AccessAudit, error) {
+	if result := d.db.WithContext(ctx).Create(audit); result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.AccessAuditInDB, result.Error.Error())
+	}
+	return audit, nil
+}
+
+func (d *dao) BatchCreate(ctx context.Context, audits []*models.AccessAudit) error {
+	if len(audits) == 0 {
+		return nil
+	}
+	if result := d.db.WithContext(ctx).Create(audits); result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.AccessAuditInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) List(ctx context.Context, query *q.Query) ([]*models.AccessAudit, int64, error) {
+	var (
+		audits []*models.AccessAudit
+		count  int64
+	)
+	statement := d.db.WithContext(ctx).Model(&models.AccessAudit{})
+	if query != nil {
+		if v, ok := query.Keywords[common.AccessAuditQueryByUser]; ok {
+			statement = statement.Where("user_id = ?", v)
+		}
+		if v, ok := query.Keywords[common.AccessAuditQueryByResource]; ok {
+			statement = statement.Where("resource = ?", v)
+		}
+		if v, ok := query.Keywords[common.AccessAuditQueryByName]; ok {
+			statement = statement.Where("name = ?", v)
+		}
+	}
+	if result := statement.Count(&count); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.AccessAuditInDB, result.Error.Error())
+	}
+	if query != nil {
+		statement = statement.Limit(query.Limit()).Offset(query.Offset())
+	}
+	if result := statement.Order("id desc").Find(&audits); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.AccessAuditInDB, result.Error.Error())
+	}
+	return audits, count, nil
+}
+
+func (d *dao) DeleteBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	var ids []uint
+	result := d.db.WithContext(ctx).Model(&models.AccessAudit{}).Where("created_at < ?", before).
+		Order("id asc").Limit(limit).Pluck("id", &ids)
+	if result.Error != nil {
+		return 0, herrors.NewErrGetFailed(herrors.AccessAuditInDB, result.Error.Error())
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	// audits are only for troubleshooting, so they are deleted physically
+	result = d.db.WithContext(ctx).Unscoped().Where("id in ?", ids).Delete(&models.AccessAudit{})
+	if result.Error != nil {
+		return 0, herrors.NewErrDeleteFailed(herrors.AccessAuditInDB, result.Error.Error())
+	}
+	return int(result.RowsAffected), nil
+}
@@ -0,0 +1,69 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/accessaudit/dao"
+	"github.com/horizoncd/horizon/pkg/accessaudit/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Manager interface {
+	Create(ctx context.Context, audit *models.AccessAudit) (*models.AccessAudit, error)
+	// BatchCreate creates the audits in one statement
+	BatchCreate(ctx context.Context, audits []*models.AccessAudit) error
+	// List lists the audits filtered by user, resource and name, the latest first
+	List(ctx context.Context, query *q.Query) ([]*models.AccessAudit, int64, error)
+	// DeleteBefore deletes at most limit audits created before the time, and returns the count deleted
+	DeleteBefore(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, audit *models.AccessAudit) (*models.AccessAudit, error) {
+	const op = "access audit manager: create"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Create(ctx, audit)
+}
+
+func (m *manager) BatchCreate(ctx context.Context, audits []*models.AccessAudit) error {
+	const op = "access audit manager: batch create"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.BatchCreate(ctx, audits)
+}
+
+func (m *manager) List(ctx context.Context, query *q.Query) ([]*models.AccessAudit, int64, error) {
+	const op = "access audit manager: list"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.List(ctx, query)
+}
+
+func (m *manager) DeleteBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "access audit manager: delete before"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.DeleteBefore(ctx, before, limit)
+}
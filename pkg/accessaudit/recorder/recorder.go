@@ -0,0 +1,106 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recorder
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/accessaudit/manager"
+	"github.com/horizoncd/horizon/pkg/accessaudit/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_defaultQueueSize     = 1024
+	_defaultBatchSize     = 100
+	_defaultFlushInterval = time.Second
+)
+
+// Recorder records the denied requests asynchronously, so that the requests are not slowed down
+// by writing the audits, and the audits are written in batches to spare the database
+type Recorder interface {
+	// Record queues the audit without blocking, the audit is dropped if the queue is full
+	Record(ctx context.Context, audit *models.AccessAudit)
+	// Run writes the queued audits until ctx is done, and the audits left are written before it returns
+	Run(ctx context.Context)
+}
+
+type recorder struct {
+	mgr           manager.Manager
+	queue         chan *models.AccessAudit
+	batchSize     int
+	flushInterval time.Duration
+}
+
+func New(mgr manager.Manager) Recorder {
+	return &recorder{
+		mgr:           mgr,
+		queue:         make(chan *models.AccessAudit, _defaultQueueSize),
+		batchSize:     _defaultBatchSize,
+		flushInterval: _defaultFlushInterval,
+	}
+}
+
+func (r *recorder) Record(ctx context.Context, audit *models.AccessAudit) {
+	select {
+	case r.queue <- audit:
+	default:
+		log.Warningf(ctx, "access audit queue is full, the denied request is dropped, user = %s, path = %s",
+			audit.UserName, audit.Path)
+	}
+}
+
+func (r *recorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*models.AccessAudit, 0, r.batchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := r.mgr.BatchCreate(ctx, batch); err != nil {
+			log.Warningf(ctx, "failed to record %d denied requests, err = %s", len(batch), err.Error())
+		}
+		batch = make([]*models.AccessAudit, 0, r.batchSize)
+	}
+	for {
+		select {
+		case audit := <-r.queue:
+			batch = append(batch, audit)
+			if len(batch) >= r.batchSize {
+				flush(ctx)
+			}
+		case <-ticker.C:
+			flush(ctx)
+		case <-ctx.Done():
+			// ctx is done, so the audits left are written with a new context
+			for {
+				select {
+				case audit := <-r.queue:
+					batch = append(batch, audit)
+					if len(batch) >= r.batchSize {
+						flush(context.Background())
+					}
+				default:
+					flush(context.Background())
+					log.Info(ctx, "access audit recorder stopped")
+					return
+				}
+			}
+		}
+	}
+}
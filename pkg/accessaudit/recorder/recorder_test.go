@@ -0,0 +1,65 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recorder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/accessaudit/manager"
+	"github.com/horizoncd/horizon/pkg/accessaudit/models"
+)
+
+func TestRecorder(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&models.AccessAudit{}))
+	mgr := manager.New(db)
+	r := &recorder{
+		mgr:           mgr,
+		queue:         make(chan *models.AccessAudit, 3),
+		batchSize:     2,
+		flushInterval: time.Hour,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// the audits are dropped if the queue is full
+	for i := 0; i < 4; i++ {
+		r.Record(ctx, &models.AccessAudit{UserID: uint(i), Path: "/apis/core/v2/groups/1"})
+	}
+	assert.Equal(t, 3, len(r.queue))
+
+	// the full batch is written at once, and the one left is written when the recorder stops
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool {
+		_, total, err := mgr.List(ctx, &q.Query{})
+		return err == nil && total == 2
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+	audits, total, err := mgr.List(context.Background(), &q.Query{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, uint(2), audits[0].UserID)
+}
@@ -183,6 +183,8 @@ func NewHTTPWebhooks(config config.Admission) {
 		switch webhook.Kind {
 		case models.KindValidating:
 			Register(models.KindValidating, NewHTTPWebhook(webhook))
+		case models.KindMutating:
+			Register(models.KindMutating, NewHTTPWebhook(webhook))
 		}
 	}
 }
@@ -197,6 +199,14 @@ func NewHTTPWebhook(config config.Webhook) Webhook {
 	}
 }
 
+// Name returns the name of webhook, or the url if name is not set
+func (m *HTTPAdmissionWebhook) Name() string {
+	if m.config.Name != "" {
+		return m.config.Name
+	}
+	return m.config.ClientConfig.URL
+}
+
 // Handle handles the admission request and returns the response
 func (m *HTTPAdmissionWebhook) Handle(ctx context.Context, req *Request) (*Response, error) {
 	resp, err := m.httpclient.Get(ctx, req)
@@ -226,6 +236,7 @@ func NewDummyWebhookServer() *DummyValidatingWebhookServer {
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/validate", webhook.Validating)
+	mux.HandleFunc("/mutate", webhook.Mutating)
 
 	server := httptest.NewServer(mux)
 	webhook.server = server
@@ -297,6 +308,34 @@ func (w *DummyValidatingWebhookServer) validating(req Request, resp *Response) {
 	resp.Allowed = common.BoolPtr(true)
 }
 
+func (w *DummyValidatingWebhookServer) Mutating(resp http.ResponseWriter, req *http.Request) {
+	w.ReadAndResponse(resp, req, w.mutating)
+}
+
+// mutating adds the default scope tag if it does not exist
+func (w *DummyValidatingWebhookServer) mutating(req Request, resp *Response) {
+	resp.Allowed = common.BoolPtr(true)
+	obj, ok := req.Object.(map[string]interface{})
+	if !ok {
+		return
+	}
+	tags, ok := obj["tags"].([]interface{})
+	if !ok {
+		resp.Patch = []byte(`[{"op":"add","path":"/tags","value":[{"key":"scope","value":"default"}]}]`)
+		return
+	}
+	for _, tag := range tags {
+		if t, ok := tag.(map[string]interface{}); ok && t["key"] == "scope" {
+			return
+		}
+	}
+	resp.Patch = []byte(`[{"op":"add","path":"/tags/-","value":{"key":"scope","value":"default"}}]`)
+}
+
+func (w *DummyValidatingWebhookServer) MutatingURL() string {
+	return w.server.URL + "/mutate"
+}
+
 func (w *DummyValidatingWebhookServer) ValidatingURL() string {
 	return w.server.URL + "/validate"
 }
@@ -0,0 +1,296 @@
+package admission
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/admission/models"
+	policymanager "github.com/horizoncd/horizon/pkg/admissionpolicy/manager"
+	policymodels "github.com/horizoncd/horizon/pkg/admissionpolicy/models"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	config "github.com/horizoncd/horizon/pkg/config/admission"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+)
+
+const (
+	PolicyWebhookName = "admission-policy"
+
+	// PolicySeparator separates the resources and operations of a policy
+	PolicySeparator = ","
+)
+
+var (
+	policyEnv     *cel.Env
+	policyEnvErr  error
+	policyEnvOnce sync.Once
+)
+
+// newPolicyEnv declares the variables which can be used in the expressions of policies,
+// they are the fields of admission request, oldObject is the current resource of update and delete
+func newPolicyEnv() (*cel.Env, error) {
+	policyEnvOnce.Do(func() {
+		policyEnv, policyEnvErr = cel.NewEnv(cel.Declarations(
+			decls.NewVar("operation", decls.String),
+			decls.NewVar("resource", decls.String),
+			decls.NewVar("subResource", decls.String),
+			decls.NewVar("name", decls.String),
+			decls.NewVar("version", decls.String),
+			decls.NewVar("object", decls.Dyn),
+			decls.NewVar("oldObject", decls.Dyn),
+			decls.NewVar("options", decls.NewMapType(decls.String, decls.Dyn)),
+		))
+	})
+	return policyEnv, policyEnvErr
+}
+
+// CompilePolicy compiles the CEL expression of a policy, the expression must evaluate to a bool
+func CompilePolicy(expression string) (cel.Program, error) {
+	env, err := newPolicyEnv()
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid expression: %v", issues.Err())
+	}
+	if resultType := ast.ResultType(); resultType.GetPrimitive() != exprpb.Type_BOOL &&
+		resultType.GetDyn() == nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"invalid expression: result type must be bool, but got %v", resultType)
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid expression: %v", err)
+	}
+	return program, nil
+}
+
+type compiledPolicy struct {
+	updatedAt time.Time
+	program   cel.Program
+	matcher   *ResourceMatcher
+}
+
+// PolicyWebhook is a built-in validating webhook evaluating the admission policies in db.
+// Policies of a group apply to the group, its subgroups and the applications and clusters under them.
+type PolicyWebhook struct {
+	policyMgr      policymanager.Manager
+	groupMgr       groupmanager.Manager
+	applicationMgr applicationmanager.Manager
+	clusterMgr     clustermanager.Manager
+
+	lock     sync.Mutex
+	compiled map[uint]*compiledPolicy
+}
+
+func NewPolicyWebhook(manager *managerparam.Manager) *PolicyWebhook {
+	return &PolicyWebhook{
+		policyMgr:      manager.AdmissionPolicyMgr,
+		groupMgr:       manager.GroupMgr,
+		applicationMgr: manager.ApplicationMgr,
+		clusterMgr:     manager.ClusterMgr,
+		compiled:       map[uint]*compiledPolicy{},
+	}
+}
+
+func (w *PolicyWebhook) Name() string {
+	return PolicyWebhookName
+}
+
+func (w *PolicyWebhook) IgnoreError() bool {
+	return false
+}
+
+func (w *PolicyWebhook) Interest(req *Request) bool {
+	switch req.Resource {
+	case common.ResourceGroup, common.ResourceApplication, common.ResourceCluster:
+		return req.Name != ""
+	default:
+		return false
+	}
+}
+
+// Handle evaluates the policies in the order of creation, and denies the request
+// once a policy evaluates to false or fails to evaluate
+func (w *PolicyWebhook) Handle(ctx context.Context, req *Request) (*Response, error) {
+	allowed := true
+	groupIDs, err := w.groupIDsOf(ctx, req)
+	if err != nil {
+		// let the api tell the resource is not found
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return &Response{Allowed: &allowed}, nil
+		}
+		return nil, err
+	}
+	policies, err := w.policyMgr.ListEnabledByGroupIDs(ctx, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return &Response{Allowed: &allowed}, nil
+	}
+
+	vars := policyVariables(req)
+	for _, policy := range policies {
+		compiled, err := w.compile(policy)
+		if err != nil {
+			return nil, perror.WithMessagef(err, "failed to compile admission policy %s", policy.Name)
+		}
+		if !compiled.matcher.Match(req) {
+			continue
+		}
+		passed, err := evalPolicy(compiled.program, vars)
+		if err != nil || !passed {
+			allowed = false
+			message := policy.Message
+			if message == "" {
+				message = fmt.Sprintf("expression %q is not satisfied", policy.Expression)
+			}
+			if err != nil {
+				message = fmt.Sprintf("%s (evaluation error: %v)", message, err)
+			}
+			return &Response{
+				Allowed: &allowed,
+				Result:  fmt.Sprintf("admission policy %s: %s", policy.Name, message),
+			}, nil
+		}
+	}
+	return &Response{Allowed: &allowed}, nil
+}
+
+// groupIDsOf returns the ids of group which the requested resource belongs to and its ancestors
+func (w *PolicyWebhook) groupIDsOf(ctx context.Context, req *Request) ([]uint, error) {
+	id, err := strconv.ParseUint(req.Name, 10, 0)
+	if err != nil {
+		return nil, nil
+	}
+	resourceID := uint(id)
+	if req.Resource == common.ResourceCluster {
+		cluster, err := w.clusterMgr.GetByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		resourceID = cluster.ApplicationID
+	}
+	groupID := resourceID
+	if req.Resource == common.ResourceCluster || req.Resource == common.ResourceApplication {
+		application, err := w.applicationMgr.GetByID(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		groupID = application.GroupID
+	}
+	group, err := w.groupMgr.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs), nil
+}
+
+// compile returns the cached program of policy, it is compiled again after the policy is updated
+func (w *PolicyWebhook) compile(policy *policymodels.AdmissionPolicy) (*compiledPolicy, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if c, ok := w.compiled[policy.ID]; ok && c.updatedAt.Equal(policy.UpdatedAt) {
+		return c, nil
+	}
+	program, err := CompilePolicy(policy.Expression)
+	if err != nil {
+		return nil, err
+	}
+	c := &compiledPolicy{
+		updatedAt: policy.UpdatedAt,
+		program:   program,
+		matcher:   NewResourceMatcher(PolicyRule(policy)),
+	}
+	w.compiled[policy.ID] = c
+	return c, nil
+}
+
+// PolicyRule converts the resources and operations of policy to a rule, empty means matching all
+func PolicyRule(policy *policymodels.AdmissionPolicy) config.Rule {
+	rule := config.Rule{
+		Resources:  []string{models.MatchAll},
+		Operations: []models.Operation{models.Operation(models.MatchAll)},
+		Versions:   []string{models.MatchAll},
+	}
+	if policy.Resources != "" {
+		rule.Resources = strings.Split(policy.Resources, PolicySeparator)
+	}
+	if policy.Operations != "" {
+		rule.Operations = nil
+		for _, operation := range strings.Split(policy.Operations, PolicySeparator) {
+			rule.Operations = append(rule.Operations, models.Operation(operation))
+		}
+	}
+	return rule
+}
+
+func policyVariables(req *Request) map[string]interface{} {
+	options := req.Options
+	if options == nil {
+		options = map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"operation":   string(req.Operation),
+		"resource":    req.Resource,
+		"subResource": req.SubResource,
+		"name":        req.Name,
+		"version":     req.Version,
+		"object":      normalizeNumbers(req.Object),
+		"oldObject":   normalizeNumbers(req.OldObject),
+		"options":     options,
+	}
+}
+
+// normalizeNumbers converts the integral numbers decoded from json to int64,
+// so that expressions like "object.replicas >= 2" work as expected
+func normalizeNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = normalizeNumbers(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = normalizeNumbers(item)
+		}
+		return result
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < math.MaxInt64 {
+			return int64(v)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func evalPolicy(program cel.Program, vars map[string]interface{}) (bool, error) {
+	out, _, err := program.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, perror.Errorf("result of expression is %v, not a bool", out.Value())
+	}
+	return result, nil
+}
@@ -0,0 +1,162 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/pkg/admission/models"
+	policymodels "github.com/horizoncd/horizon/pkg/admissionpolicy/models"
+	appmodels "github.com/horizoncd/horizon/pkg/application/models"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+func newClusterRequest(t *testing.T, environment string, body string) *Request {
+	var object interface{}
+	assert.Nil(t, json.Unmarshal([]byte(body), &object))
+	return &Request{
+		Operation:   models.OperationCreate,
+		Resource:    common.ResourceApplication,
+		Name:        "1",
+		SubResource: common.ResourceCluster,
+		Version:     "v2",
+		Object:      object,
+		Options:     map[string]interface{}{"environment": []string{environment}},
+	}
+}
+
+func TestPolicyWebhook(t *testing.T) {
+	ctx := context.Background()
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&groupmodels.Group{}, &appmodels.Application{},
+		&clustermodels.Cluster{}, &policymodels.AdmissionPolicy{}))
+	manager := managerparam.InitManager(db)
+
+	assert.Nil(t, db.Create([]*groupmodels.Group{
+		{Model: global.Model{ID: 1}, Name: "a", Path: "a", TraversalIDs: "1"},
+		{Model: global.Model{ID: 2}, Name: "b", Path: "b", ParentID: 1, TraversalIDs: "1,2"},
+		{Model: global.Model{ID: 3}, Name: "c", Path: "c", TraversalIDs: "3"},
+	}).Error)
+	assert.Nil(t, db.Create(&appmodels.Application{Model: global.Model{ID: 1}, Name: "app", GroupID: 2}).Error)
+	assert.Nil(t, db.Create(&clustermodels.Cluster{Model: global.Model{ID: 1},
+		Name: "cluster", ApplicationID: 1}).Error)
+
+	replicas, err := manager.AdmissionPolicyMgr.Create(ctx, &policymodels.AdmissionPolicy{
+		GroupID:    1,
+		Name:       "replicas",
+		Enabled:    true,
+		Resources:  "applications/clusters",
+		Operations: "create",
+		Expression: `!("online" in options.environment) || object.templateConfig.app.spec.replicas >= 2`,
+		Message:    "production clusters must set replicas >= 2",
+	})
+	assert.Nil(t, err)
+	_, err = manager.AdmissionPolicyMgr.Create(ctx, &policymodels.AdmissionPolicy{
+		GroupID:    3,
+		Name:       "readonly",
+		Enabled:    true,
+		Expression: "false",
+	})
+	assert.Nil(t, err)
+	_, err = manager.AdmissionPolicyMgr.Create(ctx, &policymodels.AdmissionPolicy{
+		GroupID:    2,
+		Name:       "disabled",
+		Expression: "false",
+	})
+	assert.Nil(t, err)
+
+	webhook := NewPolicyWebhook(manager)
+	admit := func(req *Request) (bool, string) {
+		assert.True(t, webhook.Interest(req))
+		resp, err := webhook.Handle(ctx, req)
+		assert.Nil(t, err)
+		return *resp.Allowed, resp.Result
+	}
+
+	// policies of the ancestor groups are inherited
+	allowed, result := admit(newClusterRequest(t, "online",
+		`{"templateConfig": {"app": {"spec": {"replicas": 1}}}}`))
+	assert.False(t, allowed)
+	assert.Equal(t, "admission policy replicas: production clusters must set replicas >= 2", result)
+
+	allowed, _ = admit(newClusterRequest(t, "online", `{"templateConfig": {"app": {"spec": {"replicas": 2}}}}`))
+	assert.True(t, allowed)
+	allowed, _ = admit(newClusterRequest(t, "test", `{"templateConfig": {"app": {"spec": {"replicas": 1}}}}`))
+	assert.True(t, allowed)
+
+	// errors in evaluation deny the request
+	allowed, result = admit(newClusterRequest(t, "online", `{}`))
+	assert.False(t, allowed)
+	assert.Contains(t, result, "evaluation error")
+
+	// requests not matching resources or operations are skipped
+	allowed, _ = admit(&Request{Operation: models.OperationUpdate,
+		Resource: common.ResourceCluster, Name: "1", Version: "v2"})
+	assert.True(t, allowed)
+
+	// policies of other groups are not applied
+	allowed, result = admit(&Request{Operation: models.OperationUpdate,
+		Resource: common.ResourceGroup, Name: "3"})
+	assert.False(t, allowed)
+	assert.Equal(t, `admission policy readonly: expression "false" is not satisfied`, result)
+	allowed, _ = admit(&Request{Operation: models.OperationUpdate,
+		Resource: common.ResourceGroup, Name: "2"})
+	assert.True(t, allowed)
+
+	// requests on resources not found are left to apis
+	allowed, _ = admit(&Request{Operation: models.OperationUpdate,
+		Resource: common.ResourceCluster, Name: "100"})
+	assert.True(t, allowed)
+
+	// updates are compared with the old objects
+	_, err = manager.AdmissionPolicyMgr.Create(ctx, &policymodels.AdmissionPolicy{
+		GroupID:    1,
+		Name:       "scale-down",
+		Enabled:    true,
+		Resources:  "clusters",
+		Operations: "update",
+		Expression: `object.templateConfig.app.spec.replicas >= oldObject.templateConfig.app.spec.replicas`,
+		Message:    "clusters can't be scaled down",
+	})
+	assert.Nil(t, err)
+	newUpdateRequest := func(body, oldBody string) *Request {
+		var object, oldObject interface{}
+		assert.Nil(t, json.Unmarshal([]byte(body), &object))
+		assert.Nil(t, json.Unmarshal([]byte(oldBody), &oldObject))
+		return &Request{Operation: models.OperationUpdate, Resource: common.ResourceCluster,
+			Name: "1", Version: "v2", Object: object, OldObject: oldObject}
+	}
+	allowed, result = admit(newUpdateRequest(`{"templateConfig": {"app": {"spec": {"replicas": 1}}}}`,
+		`{"templateConfig": {"app": {"spec": {"replicas": 2}}}}`))
+	assert.False(t, allowed)
+	assert.Equal(t, "admission policy scale-down: clusters can't be scaled down", result)
+	allowed, _ = admit(newUpdateRequest(`{"templateConfig": {"app": {"spec": {"replicas": 3}}}}`,
+		`{"templateConfig": {"app": {"spec": {"replicas": 2}}}}`))
+	assert.True(t, allowed)
+
+	// updated policies are compiled again
+	replicas.Expression = "true"
+	_, err = manager.AdmissionPolicyMgr.Update(ctx, replicas)
+	assert.Nil(t, err)
+	allowed, _ = admit(newClusterRequest(t, "online", `{}`))
+	assert.True(t, allowed)
+}
+
+func TestCompilePolicy(t *testing.T) {
+	_, err := CompilePolicy(`resource == "applications" && object.replicas >= 2`)
+	assert.Nil(t, err)
+	_, err = CompilePolicy(`resource == `)
+	assert.NotNil(t, err)
+	_, err = CompilePolicy(`resource`)
+	assert.NotNil(t, err)
+	_, err = CompilePolicy(`unknown == 1`)
+	assert.NotNil(t, err)
+}
@@ -2,7 +2,9 @@ package admission
 
 import (
 	"context"
+	"encoding/json"
 
+	jsonpatch "gopkg.in/evanphx/json-patch.v5"
 	"k8s.io/apimachinery/pkg/util/runtime"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
@@ -13,12 +15,15 @@ import (
 
 var (
 	validatingWebhooks []Webhook
+	mutatingWebhooks   []Webhook
 )
 
 func Register(kind models.Kind, webhook Webhook) {
 	switch kind {
 	case models.KindValidating:
 		validatingWebhooks = append(validatingWebhooks, webhook)
+	case models.KindMutating:
+		mutatingWebhooks = append(mutatingWebhooks, webhook)
 	}
 }
 
@@ -42,19 +47,91 @@ type Request struct {
 type Response struct {
 	Allowed *bool  `json:"allowed"`
 	Result  string `json:"result,omitempty"`
+	// Patch is a JSON patch (RFC 6902) to the request object, only used by mutating webhooks
+	Patch json.RawMessage `json:"patch,omitempty"`
 }
 
 type Webhook interface {
+	Name() string
 	Handle(context.Context, *Request) (*Response, error)
 	IgnoreError() bool
 	Interest(*Request) bool
 }
 
+// Mutating calls the mutating webhooks one by one in the order they are registered,
+// so each webhook sees the object patched by the former ones. It returns true if
+// the object of request is patched.
+func Mutating(ctx context.Context, request *Request) (bool, error) {
+	patched := false
+	for _, webhook := range mutatingWebhooks {
+		if !webhook.Interest(request) {
+			continue
+		}
+		mutated, err := mutate(ctx, webhook, request)
+		if err != nil {
+			if perror.Cause(err) != herrors.ErrForbidden && webhook.IgnoreError() {
+				log.Errorf(ctx, "failed to mutate request by webhook %s: %v", webhook.Name(), err)
+				continue
+			}
+			return false, err
+		}
+		patched = patched || mutated
+	}
+	return patched, nil
+}
+
+func mutate(ctx context.Context, webhook Webhook, request *Request) (bool, error) {
+	response, err := webhook.Handle(ctx, request)
+	if err != nil {
+		return false, err
+	}
+	if response == nil || response.Allowed == nil {
+		return false, perror.New("response is nil or allowed is nil")
+	}
+	if !*response.Allowed {
+		log.Infof(ctx,
+			"request (resource: %s, resourceName: %s, subresource: %s, operation: %s) denied by webhook %s: %s",
+			request.Resource, request.Name, request.SubResource,
+			request.Operation, webhook.Name(), response.Result)
+		return false, perror.Wrapf(herrors.ErrForbidden, "request denied by webhook: %s", response.Result)
+	}
+	if len(response.Patch) == 0 {
+		return false, nil
+	}
+
+	patch, err := jsonpatch.DecodePatch(response.Patch)
+	if err != nil {
+		return false, perror.Wrapf(herrors.ErrParamInvalid, "invalid patch from webhook %s: %v", webhook.Name(), err)
+	}
+	original, err := json.Marshal(request.Object)
+	if err != nil {
+		return false, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	modified, err := patch.Apply(original)
+	if err != nil {
+		return false, perror.Wrapf(herrors.ErrParamInvalid,
+			"failed to apply patch from webhook %s: %v", webhook.Name(), err)
+	}
+	var object interface{}
+	if err := json.Unmarshal(modified, &object); err != nil {
+		return false, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	request.Object = object
+	log.Infof(ctx,
+		"request (resource: %s, resourceName: %s, subresource: %s, operation: %s) mutated by webhook %s, patch: %s",
+		request.Resource, request.Name, request.SubResource,
+		request.Operation, webhook.Name(), string(response.Patch))
+	return true, nil
+}
+
 func Validating(ctx context.Context, request *Request) error {
 	ctx, cancelFunc := context.WithCancel(ctx)
 	defer cancelFunc()
+	if len(validatingWebhooks) == 0 {
+		return nil
+	}
 	finishedCount := 0
-	resCh := make(chan validateResult)
+	resCh := make(chan validateResult, len(validatingWebhooks))
 	for _, webhook := range validatingWebhooks {
 		go func(webhook Webhook) {
 			defer runtime.HandleCrash()
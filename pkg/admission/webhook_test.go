@@ -2,14 +2,18 @@ package admission
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
 	clusterctrl "github.com/horizoncd/horizon/core/controller/cluster"
+	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/pkg/admission/models"
 	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
 	admissionconfig "github.com/horizoncd/horizon/pkg/config/admission"
+	perror "github.com/horizoncd/horizon/pkg/errors"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
+	"github.com/horizoncd/horizon/pkg/util/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -136,3 +140,92 @@ func TestWebhook(t *testing.T) {
 	assert.Error(t, err)
 	t.Logf("error: %v", err)
 }
+
+type patchWebhook struct {
+	name        string
+	patch       string
+	ignoreError bool
+}
+
+func (w *patchWebhook) Name() string { return w.name }
+
+func (w *patchWebhook) Handle(context.Context, *Request) (*Response, error) {
+	return &Response{Allowed: common.BoolPtr(true), Patch: json.RawMessage(w.patch)}, nil
+}
+
+func (w *patchWebhook) IgnoreError() bool { return w.ignoreError }
+
+func (w *patchWebhook) Interest(*Request) bool { return true }
+
+func TestMutatingWebhook(t *testing.T) {
+	ctx := context.Background()
+	validatingWebhooks, mutatingWebhooks = nil, nil
+	defer func() { validatingWebhooks, mutatingWebhooks = nil, nil }()
+
+	server := NewDummyWebhookServer()
+	defer server.Stop()
+
+	rules := []admissionconfig.Rule{
+		{
+			Resources:  []string{"clusters"},
+			Operations: []models.Operation{models.OperationUpdate},
+			Versions:   []string{"v2"},
+		},
+	}
+	NewHTTPWebhooks(admissionconfig.Admission{
+		Webhooks: []admissionconfig.Webhook{
+			{
+				Name:          "default-scope",
+				Kind:          models.KindMutating,
+				FailurePolicy: admissionconfig.FailurePolicyFail,
+				Rules:         rules,
+				ClientConfig:  admissionconfig.ClientConfig{URL: server.MutatingURL()},
+			},
+			{
+				Kind:          models.KindValidating,
+				FailurePolicy: admissionconfig.FailurePolicyFail,
+				Rules:         rules,
+				ClientConfig:  admissionconfig.ClientConfig{URL: server.ValidatingURL()},
+			},
+		},
+	})
+	// webhooks run in the order of registration, so the latter patch wins
+	Register(models.KindMutating, &patchWebhook{name: "first",
+		patch: `[{"op":"add","path":"/description","value":"first"}]`})
+	Register(models.KindMutating, &patchWebhook{name: "second",
+		patch: `[{"op":"replace","path":"/description","value":"second"}]`})
+	// broken patches are skipped with the ignore policy
+	Register(models.KindMutating, &patchWebhook{name: "broken",
+		patch: `[{"op":"remove","path":"/notExist"}]`, ignoreError: true})
+
+	var object interface{}
+	body, _ := json.Marshal(clusterctrl.UpdateClusterRequestV2{
+		Tags: tagmodels.TagsBasic{{Key: "k1", Value: "v1"}},
+	})
+	assert.Nil(t, json.Unmarshal(body, &object))
+	request := &Request{
+		Operation: models.OperationUpdate,
+		Resource:  "clusters",
+		Name:      "1",
+		Version:   "v2",
+		Object:    object,
+	}
+
+	// tag scope is required by the validating webhook and is added by the mutating one
+	patched, err := Mutating(ctx, request)
+	assert.Nil(t, err)
+	assert.True(t, patched)
+	assert.Nil(t, Validating(ctx, request))
+
+	var mutated clusterctrl.UpdateClusterRequestV2
+	body, _ = json.Marshal(request.Object)
+	assert.Nil(t, json.Unmarshal(body, &mutated))
+	assert.Equal(t, "second", mutated.Description)
+	assert.Equal(t, tagmodels.TagsBasic{{Key: "k1", Value: "v1"}, {Key: "scope", Value: "default"}}, mutated.Tags)
+
+	// a broken patch fails the request with the fail policy
+	Register(models.KindMutating, &patchWebhook{name: "broken",
+		patch: `[{"op":"remove","path":"/notExist"}]`})
+	_, err = Mutating(ctx, request)
+	assert.Equal(t, herrors.ErrParamInvalid, perror.Cause(err))
+}
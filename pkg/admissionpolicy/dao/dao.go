@@ -0,0 +1,118 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/admissionpolicy/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, policy *models.AdmissionPolicy) (*models.AdmissionPolicy, error)
+	Get(ctx context.Context, id uint) (*models.AdmissionPolicy, error)
+	List(ctx context.Context, groupID uint, query *q.Query) ([]*models.AdmissionPolicy, int64, error)
+	ListEnabledByGroupIDs(ctx context.Context, groupIDs []uint) ([]*models.AdmissionPolicy, error)
+	Update(ctx context.Context, policy *models.AdmissionPolicy) (*models.AdmissionPolicy, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, policy *models.AdmissionPolicy) (*models.AdmissionPolicy, error) {
+	if result := d.db.WithContext(ctx).Create(policy); result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.AdmissionPolicyInDB, result.Error.Error())
+	}
+	return policy, nil
+}
+
+func (d *dao) Get(ctx context.Context, id uint) (*models.AdmissionPolicy, error) {
+	var policy models.AdmissionPolicy
+	if result := d.db.WithContext(ctx).First(&policy, id); result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.AdmissionPolicyInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.AdmissionPolicyInDB, result.Error.Error())
+	}
+	return &policy, nil
+}
+
+func (d *dao) List(ctx context.Context, groupID uint,
+	query *q.Query) ([]*models.AdmissionPolicy, int64, error) {
+	var (
+		policies []*models.AdmissionPolicy
+		count    int64
+	)
+	statement := d.db.WithContext(ctx).Model(&models.AdmissionPolicy{}).
+		Where("group_id = ?", groupID)
+	if query != nil {
+		if v, ok := query.Keywords[common.Enabled]; ok {
+			statement = statement.Where("enabled = ?", v)
+		}
+	}
+	if result := statement.Count(&count); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.AdmissionPolicyInDB, result.Error.Error())
+	}
+	if query != nil {
+		statement = statement.Limit(query.Limit()).Offset(query.Offset())
+	}
+	if result := statement.Order("id desc").Find(&policies); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.AdmissionPolicyInDB, result.Error.Error())
+	}
+	return policies, count, nil
+}
+
+func (d *dao) ListEnabledByGroupIDs(ctx context.Context, groupIDs []uint) ([]*models.AdmissionPolicy, error) {
+	var policies []*models.AdmissionPolicy
+	if len(groupIDs) == 0 {
+		return policies, nil
+	}
+	if result := d.db.WithContext(ctx).
+		Where("group_id in ?", groupIDs).
+		Where("enabled = ?", true).
+		Order("id").
+		Find(&policies); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.AdmissionPolicyInDB, result.Error.Error())
+	}
+	return policies, nil
+}
+
+func (d *dao) Update(ctx context.Context, policy *models.AdmissionPolicy) (*models.AdmissionPolicy, error) {
+	if result := d.db.WithContext(ctx).Where("id = ?", policy.ID).
+		Select("name", "description", "enabled", "resources", "operations",
+			"expression", "message", "updated_by").
+		Updates(policy); result.Error != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.AdmissionPolicyInDB, result.Error.Error())
+	}
+	return d.Get(ctx, policy.ID)
+}
+
+func (d *dao) Delete(ctx context.Context, id uint) error {
+	if result := d.db.WithContext(ctx).Delete(&models.AdmissionPolicy{}, id); result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.AdmissionPolicyInDB, result.Error.Error())
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/admissionpolicy/dao"
+	"github.com/horizoncd/horizon/pkg/admissionpolicy/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Manager interface {
+	Create(ctx context.Context, policy *models.AdmissionPolicy) (*models.AdmissionPolicy, error)
+	Get(ctx context.Context, id uint) (*models.AdmissionPolicy, error)
+	List(ctx context.Context, groupID uint, query *q.Query) ([]*models.AdmissionPolicy, int64, error)
+	// ListEnabledByGroupIDs lists the enabled policies of the groups in the order of creation
+	ListEnabledByGroupIDs(ctx context.Context, groupIDs []uint) ([]*models.AdmissionPolicy, error)
+	Update(ctx context.Context, policy *models.AdmissionPolicy) (*models.AdmissionPolicy, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, policy *models.AdmissionPolicy) (*models.AdmissionPolicy, error) {
+	const op = "admission policy manager: create"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Create(ctx, policy)
+}
+
+func (m *manager) Get(ctx context.Context, id uint) (*models.AdmissionPolicy, error) {
+	const op = "admission policy manager: get"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Get(ctx, id)
+}
+
+func (m *manager) List(ctx context.Context, groupID uint,
+	query *q.Query) ([]*models.AdmissionPolicy, int64, error) {
+	const op = "admission policy manager: list"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.List(ctx, groupID, query)
+}
+
+func (m *manager) ListEnabledByGroupIDs(ctx context.Context, groupIDs []uint) ([]*models.AdmissionPolicy, error) {
+	const op = "admission policy manager: list enabled by group ids"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListEnabledByGroupIDs(ctx, groupIDs)
+}
+
+func (m *manager) Update(ctx context.Context, policy *models.AdmissionPolicy) (*models.AdmissionPolicy, error) {
+	const op = "admission policy manager: update"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Update(ctx, policy)
+}
+
+func (m *manager) Delete(ctx context.Context, id uint) error {
+	const op = "admission policy manager: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Delete(ctx, id)
+}
@@ -0,0 +1,38 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// AdmissionPolicy is a declarative rule evaluated by the built-in admission webhook.
+// It applies to requests of the group and all of its descendants.
+type AdmissionPolicy struct {
+	global.Model
+	GroupID     uint
+	Name        string
+	Description string
+	Enabled     bool
+	// Resources and Operations are comma separated, "*" matches all
+	Resources  string
+	Operations string
+	// Expression is a CEL expression, the request is admitted only if it evaluates to true
+	Expression string
+	// Message is returned to the user when the request is denied
+	Message   string
+	CreatedBy uint
+	UpdatedBy uint
+}
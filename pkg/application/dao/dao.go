@@ -242,6 +242,7 @@ func (d *dao) UpdateByID(ctx context.Context, id uint, application *models.Appli
 		applicationInDB.Image = application.Image
 		applicationInDB.Template = application.Template
 		applicationInDB.TemplateRelease = application.TemplateRelease
+		applicationInDB.VisibilityLevel = application.VisibilityLevel
 		// 3. save application after updated
 		tx.Save(&applicationInDB)
 
@@ -327,6 +328,12 @@ func (d *dao) List(ctx context.Context, groupIDs []uint,
 				}
 			case corecommon.ApplicationQueryWithDeleted:
 				withDeleted = true
+			case corecommon.ApplicationQueryInvisible:
+				if invisible, ok := v.(*groupmodels.Invisible); ok {
+					if condition, args := InvisibleCondition("a", invisible); condition != "" {
+						statement = statement.Where("not "+condition, args...)
+					}
+				}
 			}
 		}
 		if !withDeleted {
@@ -0,0 +1,53 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"fmt"
+	"strings"
+
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+)
+
+// InvisibleCondition returns the condition matching the applications invisible to a user,
+// alias is the alias of tb_application in the statement. Empty condition means all the applications are visible.
+func InvisibleCondition(alias string, invisible *groupmodels.Invisible) (string, []interface{}) {
+	if invisible == nil || (len(invisible.GroupIDs) == 0 && len(invisible.Levels) == 0) {
+		return "", nil
+	}
+
+	var (
+		restricted []string
+		args       []interface{}
+	)
+	if len(invisible.GroupIDs) != 0 {
+		restricted = append(restricted, fmt.Sprintf("%s.group_id in ?", alias))
+		args = append(args, invisible.GroupIDs)
+	}
+	if len(invisible.Levels) != 0 {
+		restricted = append(restricted, fmt.Sprintf("%s.visibility_level in ?", alias))
+		args = append(args, invisible.Levels)
+	}
+	condition := fmt.Sprintf("(%s)", strings.Join(restricted, " or "))
+	if len(invisible.AuthorizedApplicationIDs) != 0 {
+		condition += fmt.Sprintf(" and %s.id not in ?", alias)
+		args = append(args, invisible.AuthorizedApplicationIDs)
+	}
+	if len(invisible.AuthorizedGroupIDs) != 0 {
+		condition += fmt.Sprintf(" and %s.group_id not in ?", alias)
+		args = append(args, invisible.AuthorizedGroupIDs)
+	}
+	return fmt.Sprintf("(%s)", condition), args
+}
@@ -40,6 +40,9 @@ type Application struct {
 	Image           string
 	Template        string
 	TemplateRelease string
+	// VisibilityLevel public, internal or private, the most restrictive one of
+	// the application and its groups takes effect
+	VisibilityLevel string
 	CreatedBy       uint
 	UpdatedBy       uint
 }
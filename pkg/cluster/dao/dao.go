@@ -25,8 +25,10 @@ import (
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/q"
+	applicationdao "github.com/horizoncd/horizon/pkg/application/dao"
 	"github.com/horizoncd/horizon/pkg/cluster/models"
 	sqlcommon "github.com/horizoncd/horizon/pkg/common"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	membermodels "github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	tagmodels "github.com/horizoncd/horizon/pkg/tag/models"
@@ -294,6 +296,18 @@ func (d *dao) List(ctx context.Context, query *q.Query, userID uint,
 					statement = statement.Where("c.id not in (select resource_id from "+
 						"tb_collection where resource_type = 'clusters' and user_id = ?)", userID)
 				}
+			case common.ClusterQueryInvisible:
+				if invisible, ok := v.(*groupmodels.Invisible); ok {
+					if condition, args := applicationdao.InvisibleCondition("a", invisible); condition != "" {
+						subQuery := "select a.id from tb_application as a where " + condition
+						if len(invisible.AuthorizedClusterIDs) != 0 {
+							args = append(args, invisible.AuthorizedClusterIDs)
+							statement = statement.Where("(c.application_id not in ("+subQuery+") or c.id in ?)", args...)
+						} else {
+							statement = statement.Where("c.application_id not in ("+subQuery+")", args...)
+						}
+					}
+				}
 			case common.ClusterQueryUpdatedAfter:
 				statement = statement.Where("c.updated_at >= ?", v)
 			case common.ClusterQueryOnlyDeleted:
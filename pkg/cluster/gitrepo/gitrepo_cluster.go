@@ -22,6 +22,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
@@ -37,10 +38,12 @@ import (
 	"github.com/horizoncd/horizon/pkg/util/angular"
 	utilcommon "github.com/horizoncd/horizon/pkg/util/common"
 	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/mergemap"
 	timeutil "github.com/horizoncd/horizon/pkg/util/time"
 	"github.com/horizoncd/horizon/pkg/util/wlog"
 	"github.com/xanzy/go-gitlab"
 	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart"
 	kyaml "sigs.k8s.io/yaml"
 )
 
@@ -49,6 +52,10 @@ const (
 	PipelineValueParent = "pipeline"
 )
 
+// valueFiles are the value files of cluster chart, the latter overrides the former
+var valueFiles = []string{common.GitopsFileApplication, common.GitopsFilePipelineOutput,
+	common.GitopsFileEnv, common.GitopsFileBase, common.GitopsFileTags, common.GitopsFileRestart, common.GitopsFileSRE}
+
 type BaseParams struct {
 	ClusterID           uint
 	Cluster             string
@@ -73,6 +80,23 @@ type UpdateClusterParams struct {
 	*BaseParams
 }
 
+type RenderClusterParams struct {
+	*BaseParams
+	// Create means the cluster is to be created, otherwise the value files
+	// not changed by params are read from the gitops branch
+	Create bool
+	// Tags replace the tags of cluster if not nil
+	Tags []*tagmodels.Tag
+}
+
+type RenderedCluster struct {
+	// Manifest is rendered with the value files which would be written by params
+	Manifest string
+	// DeployedManifest is rendered with the value files in the default branch,
+	// it's empty if the cluster has never been deployed
+	DeployedManifest string
+}
+
 type RepoInfo struct {
 	GitRepoURL string
 	ValueFiles []string
@@ -125,6 +149,8 @@ type ClusterGitRepo interface {
 	GetClusterTemplate(ctx context.Context, application, cluster string) (*ClusterTemplate, error)
 	CreateCluster(ctx context.Context, params *CreateClusterParams) error
 	UpdateCluster(ctx context.Context, params *UpdateClusterParams) error
+	// RenderCluster renders the kubernetes manifests of cluster without writing anything to the repo
+	RenderCluster(ctx context.Context, params *RenderClusterParams) (*RenderedCluster, error)
 	DeleteCluster(ctx context.Context, application, cluster string, clusterID uint) error
 	HardDeleteCluster(ctx context.Context, application, cluster string) error
 	// CompareConfig compare config of `from` commit with `to` commit.
@@ -679,6 +705,138 @@ func (g *clusterGitopsRepo) UpdateCluster(ctx context.Context, params *UpdateClu
 	return nil
 }
 
+func (g *clusterGitopsRepo) RenderCluster(ctx context.Context,
+	params *RenderClusterParams) (_ *RenderedCluster, err error) {
+	const op = "cluster git repo: render cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// 1. read the current value files
+	pid := fmt.Sprintf("%v/%v/%v", g.clustersGroup.FullPath, params.Application.Name, params.Cluster)
+	files := make(map[string][]byte)
+	rendered := &RenderedCluster{}
+	if !params.Create {
+		files, err = g.readFiles(ctx, pid, GitOpsBranch, valueFiles)
+		if err != nil {
+			return nil, err
+		}
+		rendered.DeployedManifest, err = g.renderBranch(ctx, pid, g.defaultBranch, getNamespace(params.BaseParams))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. override with the value files which would be written
+	var applicationYAML, baseValueYAML, envValueYAML, tagsYAML, sreValueYAML, restartYAML []byte
+	var err1, err2, err3, err4, err5, err6 error
+	if params.ApplicationJSONBlob != nil {
+		marshal(&applicationYAML, &err1, g.assembleApplicationValue(params.BaseParams))
+	}
+	marshal(&baseValueYAML, &err2, g.assembleBaseValue(params.BaseParams))
+	if params.RegionEntity != nil {
+		marshal(&envValueYAML, &err3, g.assembleEnvValue(params.BaseParams))
+	}
+	if params.Create || params.Tags != nil {
+		marshal(&tagsYAML, &err4, assembleTags(params.TemplateRelease.ChartName, params.Tags))
+	}
+	if params.Create {
+		marshal(&sreValueYAML, &err5, g.assembleSREValue(&CreateClusterParams{BaseParams: params.BaseParams}))
+		marshal(&restartYAML, &err6, assembleRestart(params.TemplateRelease.ChartName))
+	}
+	for _, err := range []error{err1, err2, err3, err4, err5, err6} {
+		if err != nil {
+			return nil, err
+		}
+	}
+	for fileName, content := range map[string][]byte{
+		common.GitopsFileApplication: applicationYAML,
+		common.GitopsFileBase:        baseValueYAML,
+		common.GitopsFileEnv:         envValueYAML,
+		common.GitopsFileTags:        tagsYAML,
+		common.GitopsFileSRE:         sreValueYAML,
+		common.GitopsFileRestart:     restartYAML,
+	} {
+		if content != nil {
+			files[fileName] = content
+		}
+	}
+
+	// 3. render with the chart of template release
+	templateChart, err := g.templateRepo.GetChart(params.TemplateRelease.ChartName,
+		params.TemplateRelease.ChartVersion, params.TemplateRelease.LastSyncAt)
+	if err != nil {
+		return nil, err
+	}
+	rendered.Manifest, err = renderValueFiles(params.Cluster, getNamespace(params.BaseParams), templateChart, files)
+	if err != nil {
+		return nil, err
+	}
+	return rendered, nil
+}
+
+// renderBranch renders the manifests with the chart and value files in the branch,
+// it returns empty if there is no chart in the branch
+func (g *clusterGitopsRepo) renderBranch(ctx context.Context, pid interface{},
+	branch, namespace string) (string, error) {
+	chartBytes, err := g.gitlabLib.GetFile(ctx, pid, branch, common.GitopsFileChart)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return "", nil
+		}
+		return "", err
+	}
+	var clusterChart Chart
+	if err := yaml.Unmarshal(chartBytes, &clusterChart); err != nil {
+		return "", perror.Wrapf(herrors.ErrParamInvalid,
+			"yaml Unmarshal err, file = %s", common.GitopsFileChart)
+	}
+	if len(clusterChart.Dependencies) == 0 {
+		return "", perror.Wrapf(herrors.ErrParamInvalid,
+			"failed to get cluster template from chart")
+	}
+	dependency := clusterChart.Dependencies[0]
+	templateChart, err := g.templateRepo.GetChart(dependency.Name, dependency.Version, time.Time{})
+	if err != nil {
+		return "", err
+	}
+	files, err := g.readFiles(ctx, pid, branch, valueFiles)
+	if err != nil {
+		return "", err
+	}
+	return renderValueFiles(clusterChart.Name, namespace, templateChart, files)
+}
+
+// readFiles reads the files in the branch concurrently, the files not found are ignored
+func (g *clusterGitopsRepo) readFiles(ctx context.Context, pid interface{},
+	branch string, fileNames []string) (map[string][]byte, error) {
+	cases := make([]ReadFileParam, 0, len(fileNames))
+	for _, fileName := range fileNames {
+		cases = append(cases, ReadFileParam{FileName: fileName})
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(cases))
+	for i := 0; i < len(cases); i++ {
+		go func(index int) {
+			defer wg.Done()
+			cases[index].Bytes, cases[index].Err = g.gitlabLib.GetFile(ctx, pid,
+				branch, cases[index].FileName)
+		}(i)
+	}
+	wg.Wait()
+
+	files := make(map[string][]byte, len(cases))
+	for _, oneCase := range cases {
+		if oneCase.Err != nil {
+			if _, ok := perror.Cause(oneCase.Err).(*herrors.HorizonErrNotFound); ok {
+				continue
+			}
+			return nil, oneCase.Err
+		}
+		files[oneCase.FileName] = oneCase.Bytes
+	}
+	return files, nil
+}
+
 func (g *clusterGitopsRepo) DeleteCluster(ctx context.Context,
 	application, cluster string, clusterID uint) (err error) {
 	const op = "cluster git repo: delete cluster"
@@ -1058,8 +1216,7 @@ func (g *clusterGitopsRepo) GetRepoInfo(ctx context.Context, application, cluste
 	repoURL := g.gitlabLib.GetHTTPURL(ctx)
 	return &RepoInfo{
 		GitRepoURL: fmt.Sprintf("%v/%v/%v/%v.git", repoURL, g.clustersGroup.FullPath, application, cluster),
-		ValueFiles: []string{common.GitopsFileApplication, common.GitopsFilePipelineOutput,
-			common.GitopsFileEnv, common.GitopsFileBase, common.GitopsFileTags, common.GitopsFileRestart, common.GitopsFileSRE},
+		ValueFiles: valueFiles,
 	}
 }
 
@@ -1838,6 +1995,37 @@ func (g *clusterGitopsRepo) SyncGitOpsBranch(ctx context.Context, application, c
 	return nil
 }
 
+// renderValueFiles renders the template chart as the dependency of cluster chart,
+// just like argo cd does with the value files
+func renderValueFiles(releaseName, namespace string, templateChart *chart.Chart,
+	files map[string][]byte) (string, error) {
+	values := make(map[string]interface{})
+	for _, fileName := range valueFiles {
+		content, ok := files[fileName]
+		if !ok {
+			continue
+		}
+		var value map[string]interface{}
+		if err := kyaml.Unmarshal(content, &value); err != nil {
+			return "", perror.Wrapf(herrors.ErrParamInvalid, "yaml Unmarshal err, file = %s", fileName)
+		}
+		var err error
+		if values, err = mergemap.Merge(values, value); err != nil {
+			return "", err
+		}
+	}
+
+	clusterChart := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       releaseName,
+			Version:    "1.0.0",
+		},
+	}
+	clusterChart.AddDependency(templateChart)
+	return templaterepo.RenderChart(clusterChart, values, releaseName, namespace)
+}
+
 func renameTemplateName(name string) string {
 	templateName := []byte(name)
 	for i := range templateName {
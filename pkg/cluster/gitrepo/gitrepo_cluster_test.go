@@ -19,14 +19,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/horizoncd/horizon/core/common"
 	herrors "github.com/horizoncd/horizon/core/errors"
+	gitlib "github.com/horizoncd/horizon/lib/git"
 	gitlablib "github.com/horizoncd/horizon/lib/gitlab"
 	gitlablibmock "github.com/horizoncd/horizon/mock/lib/gitlab"
+	templaterepomock "github.com/horizoncd/horizon/mock/pkg/templaterepo"
 	appmodels "github.com/horizoncd/horizon/pkg/application/models"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	templateconfig "github.com/horizoncd/horizon/pkg/config/template"
@@ -40,6 +43,7 @@ import (
 	utilcommon "github.com/horizoncd/horizon/pkg/util/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/xanzy/go-gitlab"
+	"helm.sh/helm/v3/pkg/chart"
 )
 
 /*
@@ -78,6 +82,9 @@ NOTE: when there is no GITLAB_PARAMS_FOR_TEST environment variable, skip this te
 
 NOTE: if your gitlab default branch is main.
 set the env 'defaultBranch' to main
+
+NOTE: when there is no GITLAB_PARAMS_FOR_TEST environment variable,
+the tests run against plain git repositories in a temporary directory.
 */
 
 // nolint
@@ -138,11 +145,6 @@ func TestMain(m *testing.M) {
 		Name: "Tony",
 	})
 
-	param := os.Getenv("GITLAB_PARAMS_FOR_TEST")
-	if param == "" {
-		return
-	}
-
 	defaultBranch = os.Getenv("defaultBranch")
 	if defaultBranch == "" {
 		defaultBranch = "master"
@@ -150,20 +152,37 @@ func TestMain(m *testing.M) {
 
 	defaultVisibility = "public"
 
-	var p *Param
-	if err := json.Unmarshal([]byte(param), &p); err != nil {
-		panic(err)
-	}
-
 	sshURL = "ssh://gitlab.com"
 
-	g, err = gitlablib.New(p.Token, p.BaseURL)
-	if err != nil {
-		panic(err)
-	}
-	rootGroup, err = g.GetGroup(ctx, p.RootGroupName)
-	if err != nil {
-		panic(err)
+	var p *Param
+	if param := os.Getenv("GITLAB_PARAMS_FOR_TEST"); param != "" {
+		if err := json.Unmarshal([]byte(param), &p); err != nil {
+			panic(err)
+		}
+		g, err = gitlablib.New(p.Token, p.BaseURL)
+		if err != nil {
+			panic(err)
+		}
+		rootGroup, err = g.GetGroup(ctx, p.RootGroupName)
+		if err != nil {
+			panic(err)
+		}
+	} else {
+		rootDir, err := ioutil.TempDir("", "horizon-gitops")
+		if err != nil {
+			panic(err)
+		}
+		defer os.RemoveAll(rootDir)
+
+		p = &Param{RootGroupName: "horizon"}
+		g, err = gitlib.New(gitlib.Config{RootDir: rootDir, DefaultBranch: defaultBranch})
+		if err != nil {
+			panic(err)
+		}
+		rootGroup, err = g.CreateGroup(ctx, p.RootGroupName, p.RootGroupName, nil, defaultVisibility)
+		if err != nil {
+			panic(err)
+		}
 	}
 
 	rootGroupName = p.RootGroupName
@@ -176,7 +195,10 @@ func TestMain(m *testing.M) {
 		panic(err)
 	}
 
-	os.Exit(m.Run())
+	code := m.Run()
+	if code != 0 {
+		os.Exit(code)
+	}
 }
 
 func Test(t *testing.T) {
@@ -489,7 +511,7 @@ func TestUpgradeToV2(t *testing.T) {
 	})
 	assert.Nil(t, err)
 	assert.NotNil(t, upgradeCommit)
-	files, err = r.GetCluster(ctx, application, cluster, templateName)
+	files, err = r.GetCluster(ctx, application, cluster, targetTemplate)
 	t.Logf("%+v", files)
 	assert.Nil(t, err)
 	assert.NotNil(t, files.Manifest)
@@ -638,3 +660,107 @@ java:
 	fmt.Println(output)
 	assert.Equal(t, expectedOutput, output)
 }
+
+func TestRenderCluster(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	templateChart := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: templateName, Version: "v1.0.0"},
+		Templates: []*chart.File{
+			{
+				Name: "templates/deployment.yaml",
+				Data: []byte(`kind: Deployment
+metadata:
+  name: {{ .Values.horizon.cluster }}
+  namespace: {{ .Values.env.namespace }}
+spec:
+  replicas: {{ .Values.app.spec.replicas }}`),
+			},
+		},
+	}
+	templateRepo := templaterepomock.NewMockTemplateRepo(mockCtrl)
+	templateRepo.EXPECT().GetLoc().Return("https://harbor.cloudnative.com").AnyTimes()
+	templateRepo.EXPECT().GetChart(templateName, "v1.0.0", gomock.Any()).Return(templateChart, nil).AnyTimes()
+
+	r, err := NewClusterGitlabRepo(ctx, rootGroup, templateRepo, g, defaultBranch, defaultVisibility)
+	assert.Nil(t, err)
+
+	application := "apprender"
+	cluster := "clusterrender"
+	baseParams := &BaseParams{
+		ClusterID:           1,
+		Cluster:             cluster,
+		ApplicationJSONBlob: applicationJSONBlob,
+		TemplateRelease: &trmodels.TemplateRelease{
+			TemplateName: templateName,
+			ChartName:    templateName,
+			ChartVersion: "v1.0.0",
+		},
+		Application: &appmodels.Application{
+			GroupID:  10,
+			Name:     application,
+			Priority: "P0",
+		},
+		Environment: "test",
+		RegionEntity: &regionmodels.RegionEntity{
+			Region: &regionmodels.Region{
+				Name: "hz",
+			},
+			Registry: &registrymodels.Registry{
+				Server: "https://harbor.com",
+			},
+		},
+		Version: common.MetaVersion2,
+	}
+	manifest := func(replicas int) string {
+		return fmt.Sprintf(`---
+# Source: %v/charts/%v/templates/deployment.yaml
+kind: Deployment
+metadata:
+  name: %v
+  namespace: test-10
+spec:
+  replicas: %v
+`, cluster, templateName, cluster, replicas)
+	}
+
+	// 1. render before the cluster is created
+	rendered, err := r.RenderCluster(ctx, &RenderClusterParams{BaseParams: baseParams, Create: true})
+	assert.Nil(t, err)
+	assert.Equal(t, manifest(1), rendered.Manifest)
+	assert.Equal(t, "", rendered.DeployedManifest)
+
+	defer func() {
+		_ = r.DeleteCluster(ctx, application, cluster, 1)
+		_ = g.DeleteProject(ctx, fmt.Sprintf("%v/%v/%v/%v-%v", rootGroupName,
+			common.GitopsGroupRecyclingClusters, application, cluster, 1))
+	}()
+	err = r.CreateCluster(ctx, &CreateClusterParams{BaseParams: baseParams})
+	assert.Nil(t, err)
+	_, err = r.MergeBranch(ctx, application, cluster, GitOpsBranch, r.DefaultBranch(), nil)
+	assert.Nil(t, err)
+
+	// 2. render the update, nothing is written to the repo
+	applicationJSONBlob2 := map[string]interface{}{
+		"app": map[string]interface{}{
+			"spec": map[string]interface{}{"replicas": 3},
+		},
+	}
+	rendered, err = r.RenderCluster(ctx, &RenderClusterParams{BaseParams: &BaseParams{
+		ClusterID:           1,
+		Cluster:             cluster,
+		ApplicationJSONBlob: applicationJSONBlob2,
+		TemplateRelease:     baseParams.TemplateRelease,
+		Application:         baseParams.Application,
+		Environment:         baseParams.Environment,
+		Version:             common.MetaVersion2,
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, manifest(3), rendered.Manifest)
+	assert.Equal(t, manifest(1), rendered.DeployedManifest)
+
+	files, err := r.GetCluster(ctx, application, cluster, templateName)
+	assert.Nil(t, err)
+	assert.Equal(t, applicationJSONBlob, files.ApplicationJSONBlob)
+}
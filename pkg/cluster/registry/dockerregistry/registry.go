@@ -0,0 +1,303 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dockerregistry
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// kind of the registry implementing Docker Registry HTTP API V2,
+// ref: https://docs.docker.com/registry/spec/api/
+const kind = "docker_registry"
+
+// default params
+const (
+	_backoffDuration = 1 * time.Second
+	_retry           = 3
+	_timeout         = 4 * time.Second
+	_pageSize        = 100
+)
+
+const (
+	_headerDigest = "Docker-Content-Digest"
+	_acceptTypes  = "application/vnd.docker.distribution.manifest.v2+json," +
+		"application/vnd.oci.image.manifest.v1+json," +
+		"application/vnd.docker.distribution.manifest.list.v2+json," +
+		"application/vnd.oci.image.index.v1+json"
+)
+
+var _nextLinkPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+func init() {
+	registry.Register(kind, NewDockerRegistry)
+}
+
+// Registry implements Registry by Docker Registry HTTP API V2, registries do not scan images,
+// and the push time of tag is the creation time of image as the registries do not record it
+type Registry struct {
+	// registry server address
+	server string
+	// base64 encoded username:password for basic auth, empty for anonymous access
+	token string
+	// path prefix
+	path string
+	// retryableClient retryable client
+	retryableClient *retryablehttp.Client
+}
+
+func NewDockerRegistry(config *registry.Config) (registry.Registry, error) {
+	transport := http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: config.InsecureSkipVerify,
+		},
+	}
+	return &Registry{
+		server: strings.TrimSuffix(config.Server, "/"),
+		token:  config.Token,
+		path:   config.Path,
+		retryableClient: &retryablehttp.Client{
+			HTTPClient: &http.Client{
+				Transport: &transport,
+				Timeout:   _timeout,
+			},
+			RetryMax:   _retry,
+			CheckRetry: retryablehttp.DefaultRetryPolicy,
+			Backoff: func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+				// wait for this duration if failed
+				return _backoffDuration
+			},
+		},
+	}, nil
+}
+
+type manifest struct {
+	Config struct {
+		Digest string `json:"digest"`
+		Size   int64  `json:"size"`
+	} `json:"config"`
+	Layers []struct {
+		Size int64 `json:"size"`
+	} `json:"layers"`
+	// Manifests are the manifests of platforms if it is a manifest list or an image index
+	Manifests []struct {
+		Size int64 `json:"size"`
+	} `json:"manifests"`
+}
+
+type imageConfig struct {
+	Created time.Time `json:"created"`
+}
+
+func (r *Registry) repository(appName, clusterName string) string {
+	return path.Join(r.path, appName, clusterName)
+}
+
+func (r *Registry) DeleteImage(ctx context.Context, appName string, clusterName string) (err error) {
+	const op = "registry: delete repository"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// the API cannot delete repositories, so the manifests of tags are deleted instead
+	tags, err := r.listTags(ctx, r.repository(appName, clusterName))
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := r.DeleteImageTag(ctx, appName, clusterName, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Registry) ListImageTags(ctx context.Context, appName string,
+	clusterName string) (_ []*registry.ImageTag, err error) {
+	const op = "registry: list image tags"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	repository := r.repository(appName, clusterName)
+	tags, err := r.listTags(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	imageTags := make([]*registry.ImageTag, 0, len(tags))
+	for _, tag := range tags {
+		imageTag, err := r.getImageTag(ctx, repository, tag)
+		if err != nil {
+			return nil, err
+		}
+		if imageTag != nil {
+			imageTags = append(imageTags, imageTag)
+		}
+	}
+	sort.SliceStable(imageTags, func(i, j int) bool {
+		return imageTags[i].PushedAt.After(imageTags[j].PushedAt)
+	})
+	return imageTags, nil
+}
+
+func (r *Registry) GetImageTag(ctx context.Context, appName string,
+	clusterName string, tag string) (_ *registry.ImageTag, err error) {
+	const op = "registry: get image tag"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	return r.getImageTag(ctx, r.repository(appName, clusterName), tag)
+}
+
+// DeleteImageTag deletes the manifest of tag, the other tags of the manifest are deleted as well
+func (r *Registry) DeleteImageTag(ctx context.Context, appName string,
+	clusterName string, tag string) (err error) {
+	const op = "registry: delete image tag"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	repository := r.repository(appName, clusterName)
+	resp, err := r.sendHTTPRequest(ctx, http.MethodHead,
+		fmt.Sprintf("/v2/%s/manifests/%s", repository, tag))
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+	}
+
+	resp, err = r.sendHTTPRequest(ctx, http.MethodDelete,
+		fmt.Sprintf("/v2/%s/manifests/%s", repository, resp.Header.Get(_headerDigest)))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusAccepted || resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+}
+
+func (r *Registry) listTags(ctx context.Context, repository string) ([]string, error) {
+	tags := make([]string, 0)
+	link := fmt.Sprintf("/v2/%s/tags/list?n=%d", repository, _pageSize)
+	for link != "" {
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		resp, found, err := r.getJSON(ctx, link, &page)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			break
+		}
+		tags = append(tags, page.Tags...)
+
+		link = ""
+		if matches := _nextLinkPattern.FindStringSubmatch(resp.Header.Get("Link")); len(matches) > 1 {
+			link = matches[1]
+		}
+	}
+	return tags, nil
+}
+
+func (r *Registry) getImageTag(ctx context.Context, repository string, tag string) (*registry.ImageTag, error) {
+	var m manifest
+	resp, found, err := r.getJSON(ctx, fmt.Sprintf("/v2/%s/manifests/%s", repository, tag), &m)
+	if err != nil || !found {
+		return nil, err
+	}
+	imageTag := &registry.ImageTag{
+		Name:   tag,
+		Digest: resp.Header.Get(_headerDigest),
+		Size:   m.Config.Size,
+	}
+	for _, layer := range m.Layers {
+		imageTag.Size += layer.Size
+	}
+	for _, platform := range m.Manifests {
+		imageTag.Size += platform.Size
+	}
+	if m.Config.Digest != "" {
+		var config imageConfig
+		_, found, err := r.getJSON(ctx, fmt.Sprintf("/v2/%s/blobs/%s", repository, m.Config.Digest), &config)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			imageTag.PushedAt = config.Created
+		}
+	}
+	return imageTag, nil
+}
+
+// getJSON gets the resource of link and decodes it into v, it returns false if the resource is not found
+func (r *Registry) getJSON(ctx context.Context, link string, v interface{}) (*http.Response, bool, error) {
+	resp, err := r.sendHTTPRequest(ctx, http.MethodGet, link)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return nil, false, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		return resp, true, nil
+	case http.StatusNotFound:
+		return resp, false, nil
+	default:
+		return nil, false, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+	}
+}
+
+func (r *Registry) sendHTTPRequest(ctx context.Context, method string, link string) (*http.Response, error) {
+	// the link of next page may be absolute
+	if !strings.HasPrefix(link, "http://") && !strings.HasPrefix(link, "https://") {
+		link = r.server + link
+	}
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRequestFailed, err.Error())
+	}
+	req.Header.Set("Accept", _acceptTypes)
+	if r.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Basic %s", r.token))
+	}
+	retryableReq, err := retryablehttp.FromRequest(req)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRequestFailed, err.Error())
+	}
+	resp, err := r.retryableClient.Do(retryableReq)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRequestFailed, err.Error())
+	}
+	return resp, nil
+}
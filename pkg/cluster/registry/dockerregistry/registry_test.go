@@ -0,0 +1,148 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dockerregistry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+)
+
+const _repository = "library/app/app-cluster"
+
+// fakeRegistry serves the tags of _repository, the tags are listed one per page
+type fakeRegistry struct {
+	// tags maps tag to the digest of its manifest
+	tags    map[string]string
+	created map[string]time.Time
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Basic dG9rZW4=" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	prefix := fmt.Sprintf("/v2/%s/", _repository)
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	resource := strings.TrimPrefix(r.URL.Path, prefix)
+	switch {
+	case resource == "tags/list":
+		tags := make([]string, 0)
+		for tag := range f.tags {
+			tags = append(tags, tag)
+		}
+		last := r.URL.Query().Get("last")
+		page := make([]string, 0)
+		for _, tag := range tags {
+			if tag > last && (len(page) == 0 || tag < page[0]) {
+				page = []string{tag}
+			}
+		}
+		if len(page) > 0 {
+			w.Header().Set("Link", fmt.Sprintf(`</v2/%s/tags/list?n=1&last=%s>; rel="next"`, _repository, page[0]))
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"name": _repository, "tags": page})
+	case strings.HasPrefix(resource, "manifests/"):
+		reference := strings.TrimPrefix(resource, "manifests/")
+		digest, ok := f.tags[reference]
+		if !ok {
+			for tag, d := range f.tags {
+				if d == reference {
+					digest, ok = d, true
+					delete(f.tags, tag)
+				}
+			}
+			if ok && r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set(_headerDigest, digest)
+		if r.Method == http.MethodHead {
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"config": map[string]interface{}{"digest": "config-" + digest, "size": 10},
+			"layers": []map[string]interface{}{{"size": 100}, {"size": 200}},
+		})
+	case strings.HasPrefix(resource, "blobs/config-"):
+		digest := strings.TrimPrefix(resource, "blobs/config-")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"created": f.created[digest]})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	fake := &fakeRegistry{
+		tags: map[string]string{"v1": "sha256:1", "v2": "sha256:2", "latest": "sha256:2"},
+		created: map[string]time.Time{
+			"sha256:1": now.Add(-time.Hour),
+			"sha256:2": now,
+		},
+	}
+	s := httptest.NewServer(fake)
+	defer s.Close()
+
+	r, err := NewDockerRegistry(&registry.Config{
+		Server: s.URL,
+		Token:  "dG9rZW4=",
+		Path:   "library",
+	})
+	assert.Nil(t, err)
+	ctx := context.Background()
+
+	tags, err := r.ListImageTags(ctx, "app", "app-cluster")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(tags))
+	assert.Equal(t, "v1", tags[2].Name)
+	assert.Equal(t, "sha256:1", tags[2].Digest)
+	assert.Equal(t, int64(310), tags[2].Size)
+	assert.True(t, now.Add(-time.Hour).Equal(tags[2].PushedAt))
+	assert.Nil(t, tags[2].ScanSummary)
+
+	tags, err = r.ListImageTags(ctx, "app", "not-exists")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(tags))
+
+	tag, err := r.GetImageTag(ctx, "app", "app-cluster", "v3")
+	assert.Nil(t, err)
+	assert.Nil(t, tag)
+
+	// tags of the same manifest are deleted together
+	assert.Nil(t, r.DeleteImageTag(ctx, "app", "app-cluster", "latest"))
+	tag, err = r.GetImageTag(ctx, "app", "app-cluster", "v2")
+	assert.Nil(t, err)
+	assert.Nil(t, tag)
+	assert.Nil(t, r.DeleteImageTag(ctx, "app", "app-cluster", "latest"))
+
+	assert.Nil(t, r.DeleteImage(ctx, "app", "app-cluster"))
+	assert.Equal(t, 0, len(fake.tags))
+}
@@ -0,0 +1,137 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// tag is the detailed tag of harbor v1 API, the scan overview differs between versions of harbor v1,
+// so the scan summary is not provided
+type tag struct {
+	Digest   string    `json:"digest"`
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Created  time.Time `json:"created"`
+	PushTime time.Time `json:"push_time"`
+}
+
+func (t *tag) imageTag() *registry.ImageTag {
+	imageTag := &registry.ImageTag{
+		Name:     t.Name,
+		Digest:   t.Digest,
+		Size:     t.Size,
+		PushedAt: t.PushTime,
+	}
+	if imageTag.PushedAt.IsZero() {
+		imageTag.PushedAt = t.Created
+	}
+	return imageTag
+}
+
+func (h *Registry) tagsLink(appName, clusterName string) string {
+	link := path.Join("/api/repositories", h.path, appName, clusterName, "tags")
+	return fmt.Sprintf("%s%s", strings.TrimSuffix(h.server, "/"), link)
+}
+
+func (h *Registry) ListImageTags(ctx context.Context, appName string,
+	clusterName string) (_ []*registry.ImageTag, err error) {
+	const op = "registry: list image tags"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	var tags []*tag
+	found, err := h.getJSON(ctx, h.tagsLink(appName, clusterName)+"?detail=true", "listTags", &tags)
+	if err != nil {
+		return nil, err
+	}
+	imageTags := make([]*registry.ImageTag, 0, len(tags))
+	if !found {
+		return imageTags, nil
+	}
+	for _, t := range tags {
+		imageTags = append(imageTags, t.imageTag())
+	}
+	sort.SliceStable(imageTags, func(i, j int) bool {
+		return imageTags[i].PushedAt.After(imageTags[j].PushedAt)
+	})
+	return imageTags, nil
+}
+
+func (h *Registry) GetImageTag(ctx context.Context, appName string,
+	clusterName string, tagName string) (_ *registry.ImageTag, err error) {
+	const op = "registry: get image tag"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	var t tag
+	link := fmt.Sprintf("%s/%s", h.tagsLink(appName, clusterName), url.PathEscape(tagName))
+	found, err := h.getJSON(ctx, link, "getTag", &t)
+	if err != nil || !found {
+		return nil, err
+	}
+	return t.imageTag(), nil
+}
+
+func (h *Registry) DeleteImageTag(ctx context.Context, appName string,
+	clusterName string, tagName string) (err error) {
+	const op = "registry: delete image tag"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	link := fmt.Sprintf("%s/%s", h.tagsLink(appName, clusterName), url.PathEscape(tagName))
+	resp, err := h.sendHTTPRequest(ctx, http.MethodDelete, link, nil, true, "deleteTag")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+}
+
+// getJSON gets the resource of link and decodes it into v, it returns false if the resource is not found
+func (h *Registry) getJSON(ctx context.Context, link string, operation string, v interface{}) (bool, error) {
+	resp, err := h.sendHTTPRequest(ctx, http.MethodGet, link, nil, true, operation)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return false, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+	}
+}
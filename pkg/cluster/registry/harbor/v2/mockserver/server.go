@@ -15,9 +15,12 @@
 package mockserver
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -36,8 +39,19 @@ type ProjectMember struct {
 }
 
 type ProjectRepository struct {
-	Name string
-	Tags []string
+	Name      string
+	Tags      []string
+	Artifacts []*Artifact
+}
+
+type Artifact struct {
+	Digest   string
+	Tags     []string
+	Size     int64
+	PushTime time.Time
+	// Severity is the highest severity of the vulnerabilities, the artifact is not scanned if empty
+	Severity string
+	Summary  map[string]int
 }
 
 type HarborServer struct {
@@ -47,7 +61,7 @@ type HarborServer struct {
 }
 
 func NewHarborServer() *HarborServer {
-	r := mux.NewRouter()
+	r := mux.NewRouter().UseEncodedPath()
 	s := &HarborServer{
 		R:         r,
 		Projects:  map[string]*HarborProject{},
@@ -55,6 +69,14 @@ func NewHarborServer() *HarborServer {
 	}
 	r.Path("/api/v2.0/projects/{project}/repositories/{repository}").
 		Methods(http.MethodDelete).HandlerFunc(s.DeleteRepository)
+	r.Path("/api/v2.0/projects/{project}/repositories/{repository}/artifacts").
+		Methods(http.MethodGet).HandlerFunc(s.ListArtifacts)
+	r.Path("/api/v2.0/projects/{project}/repositories/{repository}/artifacts/{reference}").
+		Methods(http.MethodGet).HandlerFunc(s.GetArtifact)
+	r.Path("/api/v2.0/projects/{project}/repositories/{repository}/artifacts/{reference}").
+		Methods(http.MethodDelete).HandlerFunc(s.DeleteArtifact)
+	r.Path("/api/v2.0/projects/{project}/repositories/{repository}/artifacts/{reference}/tags/{tag}").
+		Methods(http.MethodDelete).HandlerFunc(s.DeleteTag)
 	return s
 }
 
@@ -89,6 +111,15 @@ func (s *HarborServer) PushImage(projectName string, repository string, tag stri
 	if projectName == "" || repository == "" || tag == "" {
 		return
 	}
+	s.PushArtifact(projectName, repository, &Artifact{
+		Digest:   fmt.Sprintf("sha256:%s", tag),
+		Tags:     []string{tag},
+		PushTime: time.Now(),
+	})
+}
+
+// PushArtifact pushes artifact with its tags into repository, the repository is created if not exists
+func (s *HarborServer) PushArtifact(projectName string, repository string, artifact *Artifact) {
 	projectID := ""
 	for _, v := range s.Projects {
 		if v.Name == projectName {
@@ -109,18 +140,22 @@ func (s *HarborServer) PushImage(projectName string, repository string, tag stri
 		}
 	}
 	if repo != nil {
-		s.Projects[projectID].Repositories[index].Tags = append(s.Projects[projectID].Repositories[index].Tags, tag)
+		s.Projects[projectID].Repositories[index].Tags = append(s.Projects[projectID].Repositories[index].Tags,
+			artifact.Tags...)
+		s.Projects[projectID].Repositories[index].Artifacts = append(
+			s.Projects[projectID].Repositories[index].Artifacts, artifact)
 	} else {
 		s.Projects[projectID].Repositories = append(s.Projects[projectID].Repositories, &ProjectRepository{
-			Name: repository,
-			Tags: []string{tag},
+			Name:      repository,
+			Tags:      artifact.Tags,
+			Artifacts: []*Artifact{artifact},
 		})
 	}
 }
 
 func (s *HarborServer) DeleteRepository(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	project, repository := vars["project"], vars["repository"]
+	project, repository := vars["project"], unescape(vars["repository"])
 	var projectID = ""
 	for _, v := range s.Projects {
 		if v.Name == project {
@@ -152,6 +187,159 @@ func (s *HarborServer) DeleteRepository(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 }
 
+func (s *HarborServer) ListArtifacts(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.getRepository(w, r)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	artifacts := repo.Artifacts
+	if page > 0 && pageSize > 0 {
+		start, end := (page-1)*pageSize, page*pageSize
+		if start > len(artifacts) {
+			start = len(artifacts)
+		}
+		if end > len(artifacts) {
+			end = len(artifacts)
+		}
+		artifacts = artifacts[start:end]
+	}
+	items := make([]interface{}, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		items = append(items, artifact.toJSON())
+	}
+	s.responseJSON(w, items)
+}
+
+func (s *HarborServer) GetArtifact(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.getRepository(w, r)
+	if !ok {
+		return
+	}
+	index := repo.findArtifact(unescape(mux.Vars(r)["reference"]))
+	if index == -1 {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("artifact not found"))
+		return
+	}
+	s.responseJSON(w, repo.Artifacts[index].toJSON())
+}
+
+func (s *HarborServer) DeleteArtifact(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.getRepository(w, r)
+	if !ok {
+		return
+	}
+	index := repo.findArtifact(unescape(mux.Vars(r)["reference"]))
+	if index == -1 {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("artifact not found"))
+		return
+	}
+	repo.Artifacts = append(repo.Artifacts[:index], repo.Artifacts[index+1:]...)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *HarborServer) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.getRepository(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	index := repo.findArtifact(unescape(vars["reference"]))
+	if index == -1 {
+		s.responseError(w, http.StatusNotFound, fmt.Errorf("artifact not found"))
+		return
+	}
+	artifact, tag := repo.Artifacts[index], unescape(vars["tag"])
+	for k, v := range artifact.Tags {
+		if v == tag {
+			artifact.Tags = append(artifact.Tags[:k], artifact.Tags[k+1:]...)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+	s.responseError(w, http.StatusNotFound, fmt.Errorf("tag %s not found", tag))
+}
+
+func (s *HarborServer) getRepository(w http.ResponseWriter, r *http.Request) (*ProjectRepository, bool) {
+	vars := mux.Vars(r)
+	project, repository := vars["project"], unescape(vars["repository"])
+	for _, v := range s.Projects {
+		if v.Name != project {
+			continue
+		}
+		for _, repo := range v.Repositories {
+			if repo.Name == repository {
+				return repo, true
+			}
+		}
+	}
+	s.responseError(w, http.StatusNotFound, fmt.Errorf("repository %s not found", repository))
+	return nil, false
+}
+
+func (r *ProjectRepository) findArtifact(reference string) int {
+	for k, artifact := range r.Artifacts {
+		if artifact.Digest == reference {
+			return k
+		}
+		for _, tag := range artifact.Tags {
+			if tag == reference {
+				return k
+			}
+		}
+	}
+	return -1
+}
+
+func (a *Artifact) toJSON() map[string]interface{} {
+	tags := make([]map[string]interface{}, 0, len(a.Tags))
+	for _, tag := range a.Tags {
+		tags = append(tags, map[string]interface{}{
+			"name":      tag,
+			"push_time": a.PushTime,
+		})
+	}
+	artifact := map[string]interface{}{
+		"digest":    a.Digest,
+		"size":      a.Size,
+		"push_time": a.PushTime,
+		"tags":      tags,
+	}
+	if a.Severity != "" {
+		total := 0
+		for _, count := range a.Summary {
+			total += count
+		}
+		artifact["scan_overview"] = map[string]interface{}{
+			"application/vnd.security.vulnerability.report; version=1.1": map[string]interface{}{
+				"scan_status": "Success",
+				"severity":    a.Severity,
+				"summary": map[string]interface{}{
+					"total":   total,
+					"fixable": 0,
+					"summary": a.Summary,
+				},
+			},
+		}
+	}
+	return artifact
+}
+
+func unescape(s string) string {
+	unescaped, err := url.PathUnescape(s)
+	if err != nil {
+		return s
+	}
+	return unescaped
+}
+
+func (s *HarborServer) responseJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
 func (s *HarborServer) responseError(w http.ResponseWriter, code int, err error) {
 	w.WriteHeader(code)
 	if err != nil {
@@ -0,0 +1,198 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/registry"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const _pageSize = 100
+
+// artifact ref: https://github.com/goharbor/harbor/blob/main/api/v2.0/swagger.yaml
+type artifact struct {
+	Digest       string                  `json:"digest"`
+	Size         int64                   `json:"size"`
+	PushTime     time.Time               `json:"push_time"`
+	Tags         []artifactTag           `json:"tags"`
+	ScanOverview map[string]scanOverview `json:"scan_overview"`
+}
+
+type artifactTag struct {
+	Name     string    `json:"name"`
+	PushTime time.Time `json:"push_time"`
+}
+
+type scanOverview struct {
+	ScanStatus string `json:"scan_status"`
+	Severity   string `json:"severity"`
+	Summary    *struct {
+		Total   int            `json:"total"`
+		Fixable int            `json:"fixable"`
+		Summary map[string]int `json:"summary"`
+	} `json:"summary"`
+}
+
+func (a *artifact) imageTag(tag artifactTag) *registry.ImageTag {
+	imageTag := &registry.ImageTag{
+		Name:     tag.Name,
+		Digest:   a.Digest,
+		Size:     a.Size,
+		PushedAt: tag.PushTime,
+	}
+	if imageTag.PushedAt.IsZero() {
+		imageTag.PushedAt = a.PushTime
+	}
+	// there is one report for each mime type, and they are the same in summary
+	for _, overview := range a.ScanOverview {
+		imageTag.ScanSummary = &registry.ScanSummary{
+			Status:   overview.ScanStatus,
+			Severity: overview.Severity,
+		}
+		if overview.Summary != nil {
+			imageTag.ScanSummary.Total = overview.Summary.Total
+			imageTag.ScanSummary.Fixable = overview.Summary.Fixable
+			imageTag.ScanSummary.Summary = overview.Summary.Summary
+		}
+		break
+	}
+	return imageTag
+}
+
+func (h *Registry) artifactsLink(appName, clusterName string) string {
+	link := path.Join("/api/v2.0/projects", h.path, "repositories",
+		url.PathEscape(path.Join(appName, clusterName)), "artifacts")
+	return fmt.Sprintf("%s%s", strings.TrimSuffix(h.server, "/"), link)
+}
+
+func (h *Registry) ListImageTags(ctx context.Context, appName string,
+	clusterName string) (_ []*registry.ImageTag, err error) {
+	const op = "registry: list image tags"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	imageTags := make([]*registry.ImageTag, 0)
+	for page := 1; ; page++ {
+		link := fmt.Sprintf("%s?with_tag=true&with_scan_overview=true&page=%d&page_size=%d",
+			h.artifactsLink(appName, clusterName), page, _pageSize)
+		var artifacts []*artifact
+		found, err := h.getJSON(ctx, link, "listArtifacts", &artifacts)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return imageTags, nil
+		}
+		for _, a := range artifacts {
+			for _, tag := range a.Tags {
+				imageTags = append(imageTags, a.imageTag(tag))
+			}
+		}
+		if len(artifacts) < _pageSize {
+			break
+		}
+	}
+	sort.SliceStable(imageTags, func(i, j int) bool {
+		return imageTags[i].PushedAt.After(imageTags[j].PushedAt)
+	})
+	return imageTags, nil
+}
+
+func (h *Registry) GetImageTag(ctx context.Context, appName string,
+	clusterName string, tag string) (_ *registry.ImageTag, err error) {
+	const op = "registry: get image tag"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	a, err := h.getArtifact(ctx, appName, clusterName, tag)
+	if err != nil || a == nil {
+		return nil, err
+	}
+	for _, t := range a.Tags {
+		if t.Name == tag {
+			return a.imageTag(t), nil
+		}
+	}
+	return nil, nil
+}
+
+func (h *Registry) DeleteImageTag(ctx context.Context, appName string,
+	clusterName string, tag string) (err error) {
+	const op = "registry: delete image tag"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	a, err := h.getArtifact(ctx, appName, clusterName, tag)
+	if err != nil || a == nil {
+		return err
+	}
+	// delete the artifact with its last tag, or it is left untagged
+	link := fmt.Sprintf("%s/%s", h.artifactsLink(appName, clusterName), a.Digest)
+	if len(a.Tags) > 1 {
+		link = fmt.Sprintf("%s/%s/tags/%s", h.artifactsLink(appName, clusterName), a.Digest, url.PathEscape(tag))
+	}
+	resp, err := h.sendHTTPRequest(ctx, http.MethodDelete, link, nil, true, "deleteArtifact")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+}
+
+func (h *Registry) getArtifact(ctx context.Context, appName, clusterName, reference string) (*artifact, error) {
+	link := fmt.Sprintf("%s/%s?with_tag=true&with_scan_overview=true",
+		h.artifactsLink(appName, clusterName), url.PathEscape(reference))
+	var a artifact
+	found, err := h.getJSON(ctx, link, "getArtifact", &a)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// getJSON gets the resource of link and decodes it into v, it returns false if the resource is not found
+func (h *Registry) getJSON(ctx context.Context, link string, operation string, v interface{}) (bool, error) {
+	resp, err := h.sendHTTPRequest(ctx, http.MethodGet, link, nil, true, operation)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return false, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+		}
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, perror.Wrap(herrors.ErrHTTPRespNotAsExpected, common.Response(ctx, resp))
+	}
+}
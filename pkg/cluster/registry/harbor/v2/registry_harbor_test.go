@@ -20,6 +20,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/horizoncd/horizon/pkg/cluster/registry"
 	"github.com/horizoncd/horizon/pkg/cluster/registry/harbor/v2/mockserver"
@@ -49,3 +50,60 @@ func TestByMock(t *testing.T) {
 	err = h.DeleteImage(ctx, "horizon-demo", "horizon-demo-dev")
 	assert.Nil(t, err)
 }
+
+func TestImageTags(t *testing.T) {
+	config.Path = "project2"
+	registry, _ := NewHarborRegistry(config)
+	h := registry.(*Registry)
+	ctx := context.Background()
+
+	now := time.Now()
+	server.CreateProject("project2", nil)
+	server.PushArtifact("project2", "app/app-cluster", &mockserver.Artifact{
+		Digest:   "sha256:v1",
+		Tags:     []string{"v1"},
+		Size:     100,
+		PushTime: now.Add(-time.Hour),
+	})
+	server.PushArtifact("project2", "app/app-cluster", &mockserver.Artifact{
+		Digest:   "sha256:v2",
+		Tags:     []string{"v2", "latest"},
+		Size:     200,
+		PushTime: now,
+		Severity: "Critical",
+		Summary:  map[string]int{"Critical": 2, "High": 1},
+	})
+
+	tags, err := h.ListImageTags(ctx, "app", "app-cluster")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(tags))
+	assert.Equal(t, "v1", tags[2].Name)
+	assert.Nil(t, tags[2].ScanSummary)
+	assert.Equal(t, int64(200), tags[0].Size)
+	assert.True(t, tags[0].ScanSummary.HasCritical())
+	assert.Equal(t, 3, tags[0].ScanSummary.Total)
+
+	tags, err = h.ListImageTags(ctx, "app", "not-exists")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(tags))
+
+	tag, err := h.GetImageTag(ctx, "app", "app-cluster", "latest")
+	assert.Nil(t, err)
+	assert.Equal(t, "sha256:v2", tag.Digest)
+	tag, err = h.GetImageTag(ctx, "app", "app-cluster", "v3")
+	assert.Nil(t, err)
+	assert.Nil(t, tag)
+
+	// the artifact is kept while it has other tags
+	assert.Nil(t, h.DeleteImageTag(ctx, "app", "app-cluster", "latest"))
+	tag, err = h.GetImageTag(ctx, "app", "app-cluster", "v2")
+	assert.Nil(t, err)
+	assert.NotNil(t, tag)
+
+	assert.Nil(t, h.DeleteImageTag(ctx, "app", "app-cluster", "v1"))
+	assert.Nil(t, h.DeleteImageTag(ctx, "app", "app-cluster", "v1"))
+	tags, err = h.ListImageTags(ctx, "app", "app-cluster")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(tags))
+	assert.Equal(t, "v2", tags[0].Name)
+}
@@ -16,6 +16,9 @@ package registry
 
 import (
 	"context"
+	"path"
+	"strings"
+	"time"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	perror "github.com/horizoncd/horizon/pkg/errors"
@@ -45,6 +48,70 @@ func Register(kind string, constructor Constructor) {
 type Registry interface {
 	// DeleteImage delete repository
 	DeleteImage(ctx context.Context, appName string, clusterName string) error
+	// ListImageTags lists the tags of the repository of cluster, newest pushed first
+	ListImageTags(ctx context.Context, appName string, clusterName string) ([]*ImageTag, error)
+	// GetImageTag returns the tag of the repository of cluster, nil if the tag does not exist
+	GetImageTag(ctx context.Context, appName string, clusterName string, tag string) (*ImageTag, error)
+	// DeleteImageTag deletes the tag of the repository of cluster
+	DeleteImageTag(ctx context.Context, appName string, clusterName string, tag string) error
+}
+
+// severities of vulnerabilities
+const (
+	SeverityCritical = "Critical"
+	SeverityHigh     = "High"
+	SeverityMedium   = "Medium"
+	SeverityLow      = "Low"
+	SeverityUnknown  = "Unknown"
+	SeverityNone     = "None"
+)
+
+type ImageTag struct {
+	Name     string    `json:"name"`
+	Digest   string    `json:"digest"`
+	Size     int64     `json:"size"`
+	PushedAt time.Time `json:"pushedAt"`
+	// ScanSummary is nil if the registry does not scan images
+	ScanSummary *ScanSummary `json:"scanSummary,omitempty"`
+}
+
+type ScanSummary struct {
+	// Status is the status of the scan, such as Success, Running and Error
+	Status string `json:"status"`
+	// Severity is the highest severity of the vulnerabilities
+	Severity string `json:"severity"`
+	Total    int    `json:"total"`
+	Fixable  int    `json:"fixable"`
+	// Summary is the count of vulnerabilities by severity
+	Summary map[string]int `json:"summary,omitempty"`
+}
+
+// ScanStatusSuccess is the status of a finished scan
+const ScanStatusSuccess = "Success"
+
+// Scanned returns whether the scan is finished, so that the vulnerabilities are known
+func (s *ScanSummary) Scanned() bool {
+	return s != nil && s.Status == ScanStatusSuccess
+}
+
+// HasCritical returns whether critical vulnerabilities are found
+func (s *ScanSummary) HasCritical() bool {
+	return s != nil && (s.Severity == SeverityCritical || s.Summary[SeverityCritical] > 0)
+}
+
+// ParseImageTag returns the tag of imageURL if it is an image of the repository of cluster in the registry,
+// images are pushed as <server>/<path>/<application>/<cluster>:<tag>
+func ParseImageTag(config *Config, imageURL string, appName string, clusterName string) (string, bool) {
+	server := strings.TrimPrefix(strings.TrimPrefix(config.Server, "http://"), "https://")
+	repository := path.Join(strings.TrimSuffix(server, "/"), config.Path, appName, clusterName) + ":"
+	if !strings.HasPrefix(imageURL, repository) {
+		return "", false
+	}
+	tag := strings.TrimPrefix(imageURL, repository)
+	if tag == "" || strings.ContainsAny(tag, "/@:") {
+		return "", false
+	}
+	return tag, true
 }
 
 type Config struct {
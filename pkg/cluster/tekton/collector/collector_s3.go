@@ -127,6 +127,8 @@ type CollectResult struct {
 	Result         string
 	StartTime      *metav1.Time
 	CompletionTime *metav1.Time
+	// StepLogs are the logs split by task and step, nil if the collector does not store logs
+	StepLogs []*StepLog
 }
 
 func (c *S3Collector) Collect(ctx context.Context, pr *v1beta1.PipelineRun, horizonMetaData *global.HorizonMetaData) (
@@ -168,6 +170,7 @@ func (c *S3Collector) Collect(ctx context.Context, pr *v1beta1.PipelineRun, hori
 		Result:         metadata.PipelineRun.Result,
 		StartTime:      metadata.PipelineRun.StartTime,
 		CompletionTime: metadata.PipelineRun.CompletionTime,
+		StepLogs:       collectLogResult.StepLogs,
 	}
 
 	// delete pipelinerun in k8s
@@ -307,6 +310,7 @@ type CollectLogResult struct {
 	LogObject  string
 	LogURL     string
 	LogContent string
+	StepLogs   []*StepLog
 }
 
 func (c *S3Collector) collectLog(ctx context.Context,
@@ -360,10 +364,18 @@ func (c *S3Collector) collectLog(ctx context.Context,
 	if err := c.s3.PutObject(ctx, logPath, bytes.NewReader(b), nil); err != nil {
 		return nil, perror.Wrap(herrors.ErrS3PutObjFailed, err.Error())
 	}
+	// the log is already saved, so the step logs are best-effort, and they can still be split from the log later
+	stepLogs, err := ParseStepLogs(b)
+	if err != nil {
+		logutil.Warningf(ctx, "failed to split log of pipelineRun %s by steps: %v", pr.Name, err)
+		stepLogs = nil
+	}
+	SetStepTimes(stepLogs, pr)
 	return &CollectLogResult{
 		LogObject:  logPath,
 		LogURL:     logURL,
 		LogContent: string(b),
+		StepLogs:   stepLogs,
 	}, nil
 }
 
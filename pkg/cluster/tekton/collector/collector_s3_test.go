@@ -20,6 +20,7 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -111,6 +112,10 @@ func TestS3Collector_Collect(t *testing.T) {
 	assert.Nil(t, err)
 	b, _ := json.Marshal(collectResult)
 	t.Logf("%v", string(b))
+	assert.Equal(t, 1, len(collectResult.StepLogs))
+	assert.Equal(t, "test-task", collectResult.StepLogs[0].Task)
+	assert.Equal(t, "test-step", collectResult.StepLogs[0].Step)
+	assert.Equal(t, 10, len(collectResult.StepLogs[0].Lines))
 
 	// 1. getLatestPipelineRunLog
 	prModel := &prmodels.Pipelinerun{
@@ -137,3 +142,48 @@ func TestS3Collector_Collect(t *testing.T) {
 		t.Fatalf("pipelineRun objectMeta: expected %v, got %v", objectMeta, obj.Metadata)
 	}
 }
+
+func TestS3Collector_CollectLongLine(t *testing.T) {
+	var pr *v1beta1.PipelineRun
+	if err := json.Unmarshal([]byte(pipelineRunJSON), &pr); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	ctl := gomock.NewController(t)
+	tek := tektonmock.NewMockInterface(ctl)
+	logCh := make(chan log.Log, 1)
+	logCh <- log.Log{Task: "test-task", Step: "test-step", Log: strings.Repeat("a", 2*_mb)}
+	close(logCh)
+	tek.EXPECT().GetPipelineRunLog(ctx, pr).Return(logCh, nil, nil)
+	tek.EXPECT().DeletePipelineRun(ctx, pr).Return(nil)
+
+	backend := s3mem.New()
+	_ = backend.CreateBucket("bucket")
+	faker := gofakes3.New(backend)
+	ts := httptest.NewServer(faker.Server())
+	defer ts.Close()
+
+	d, err := s3.NewDriver(s3.Params{
+		AccessKey:        "accessKey",
+		SecretKey:        "secretKey",
+		Region:           "us-east-1",
+		Endpoint:         ts.URL,
+		Bucket:           "bucket",
+		ContentType:      "text/plain",
+		SkipVerify:       true,
+		S3ForcePathStyle: true,
+	})
+	assert.Nil(t, err)
+
+	// the log is collected even though it cannot be split by steps
+	collectResult, err := NewS3Collector(d, tek).Collect(ctx, pr, &global.HorizonMetaData{
+		Application: "app",
+		Cluster:     "cluster",
+		Environment: "test",
+	})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, collectResult.LogObject)
+	assert.Empty(t, collectResult.StepLogs)
+}
@@ -0,0 +1,140 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	logutil "github.com/horizoncd/horizon/pkg/util/log"
+)
+
+// _eofLog marks the end of the log of a step in the log channel
+const _eofLog = "EOFLOG"
+
+// _stepLinePattern matches the lines of the raw log written as "[task : step] content"
+var _stepLinePattern = regexp.MustCompile(`^\[([^\[\]]*) : ([^\[\]]*)\] ?(.*)$`)
+
+// StepLog is the log of a step of pipelinerun
+type StepLog struct {
+	Task       string     `json:"task"`
+	Step       string     `json:"step"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Lines      []string   `json:"lines"`
+}
+
+type stepLogs struct {
+	logs  []*StepLog
+	index map[string]*StepLog
+}
+
+func (s *stepLogs) append(task, step, line string) {
+	key := task + "/" + step
+	l, ok := s.index[key]
+	if !ok {
+		l = &StepLog{Task: task, Step: step, Lines: make([]string, 0)}
+		s.index[key] = l
+		s.logs = append(s.logs, l)
+	}
+	l.Lines = append(l.Lines, line)
+}
+
+// ParseStepLogs splits the raw log written by the collector by task and step, in the order of output
+func ParseStepLogs(b []byte) ([]*StepLog, error) {
+	s := &stepLogs{logs: make([]*StepLog, 0), index: make(map[string]*StepLog)}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner.Buffer(make([]byte, 0, 64*1024), _mb)
+	for scanner.Scan() {
+		matches := _stepLinePattern.FindStringSubmatch(scanner.Text())
+		// blank lines end the steps, and the others are errors of reading log
+		if matches == nil {
+			continue
+		}
+		s.append(matches[1], matches[2], matches[3])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, perror.Wrap(herrors.ErrReadFailed, err.Error())
+	}
+	return s.logs, nil
+}
+
+// ReadStepLogs reads the log from collector and splits it by task and step, in the order of output
+func ReadStepLogs(ctx context.Context, l *Log) ([]*StepLog, error) {
+	if l.LogBytes != nil {
+		return ParseStepLogs(l.LogBytes)
+	}
+	s := &stepLogs{logs: make([]*StepLog, 0), index: make(map[string]*StepLog)}
+	logC, errC := l.LogChannel, l.ErrChannel
+	for logC != nil || errC != nil {
+		select {
+		case line, ok := <-logC:
+			if !ok {
+				logC = nil
+				continue
+			}
+			if line.Log == _eofLog {
+				continue
+			}
+			s.append(line.Task, line.Step, line.Log)
+		case err, ok := <-errC:
+			if !ok {
+				errC = nil
+				continue
+			}
+			logutil.Warningf(ctx, "failed to read pipelinerun log: %v", err)
+		}
+	}
+	return s.logs, nil
+}
+
+// SetStepTimes sets the start and finish time of steps by the status of their taskruns in pr
+func SetStepTimes(logs []*StepLog, pr *v1beta1.PipelineRun) {
+	if pr == nil {
+		return
+	}
+	type stepTime struct {
+		startedAt, finishedAt *time.Time
+	}
+	times := make(map[string]stepTime)
+	for _, trStatus := range pr.Status.TaskRuns {
+		if trStatus == nil || trStatus.Status == nil {
+			continue
+		}
+		for _, step := range trStatus.Status.Steps {
+			var t stepTime
+			if step.Terminated != nil {
+				startedAt, finishedAt := step.Terminated.StartedAt.Time, step.Terminated.FinishedAt.Time
+				t = stepTime{startedAt: &startedAt, finishedAt: &finishedAt}
+			} else if step.Running != nil {
+				startedAt := step.Running.StartedAt.Time
+				t = stepTime{startedAt: &startedAt}
+			}
+			times[trStatus.PipelineTaskName+"/"+step.Name] = t
+		}
+	}
+	for _, l := range logs {
+		if t, ok := times[l.Task+"/"+l.Step]; ok {
+			l.StartedAt, l.FinishedAt = t.startedAt, t.finishedAt
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/log"
+)
+
+func TestParseStepLogs(t *testing.T) {
+	raw := "[build : git] Cloning into 'app'...\n" +
+		"[build : compile] [INFO] BUILD FAILURE\n" +
+		"\n" +
+		"[build : git] done\n" +
+		"error in getting logs for step image: container not found\n" +
+		"[build : compile] \n"
+	stepLogs, err := ParseStepLogs([]byte(raw))
+	assert.Nil(t, err)
+	assert.Equal(t, []*StepLog{
+		{Task: "build", Step: "git", Lines: []string{"Cloning into 'app'...", "done"}},
+		{Task: "build", Step: "compile", Lines: []string{"[INFO] BUILD FAILURE", ""}},
+	}, stepLogs)
+}
+
+func TestReadStepLogs(t *testing.T) {
+	logC := make(chan log.Log)
+	errC := make(chan error)
+	go func() {
+		defer close(logC)
+		defer close(errC)
+		logC <- log.Log{Task: "build", Step: "git", Log: "line1"}
+		logC <- log.Log{Task: "build", Step: "git", Log: _eofLog}
+		errC <- errors.New("failed to get logs")
+		logC <- log.Log{Task: "deploy", Step: "deploy", Log: "line2"}
+	}()
+	stepLogs, err := ReadStepLogs(context.Background(), &Log{LogChannel: logC, ErrChannel: errC})
+	assert.Nil(t, err)
+	assert.Equal(t, []*StepLog{
+		{Task: "build", Step: "git", Lines: []string{"line1"}},
+		{Task: "deploy", Step: "deploy", Lines: []string{"line2"}},
+	}, stepLogs)
+
+	stepLogs, err = ReadStepLogs(context.Background(), &Log{LogBytes: []byte("[build : git] line1\n")})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(stepLogs))
+}
+
+func TestSetStepTimes(t *testing.T) {
+	startedAt := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	finishedAt := startedAt.Add(time.Minute)
+	pr := &v1beta1.PipelineRun{
+		Status: v1beta1.PipelineRunStatus{
+			PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+				TaskRuns: map[string]*v1beta1.PipelineRunTaskRunStatus{
+					"app-build": {
+						PipelineTaskName: "build",
+						Status: &v1beta1.TaskRunStatus{
+							TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+								Steps: []v1beta1.StepState{
+									{
+										Name: "git",
+										ContainerState: corev1.ContainerState{
+											Terminated: &corev1.ContainerStateTerminated{
+												StartedAt:  metav1.NewTime(startedAt),
+												FinishedAt: metav1.NewTime(finishedAt),
+											},
+										},
+									},
+									{
+										Name: "compile",
+										ContainerState: corev1.ContainerState{
+											Running: &corev1.ContainerStateRunning{
+												StartedAt: metav1.NewTime(finishedAt),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	stepLogs := []*StepLog{
+		{Task: "build", Step: "git"},
+		{Task: "build", Step: "compile"},
+		{Task: "deploy", Step: "deploy"},
+	}
+	SetStepTimes(stepLogs, pr)
+	assert.Equal(t, startedAt, *stepLogs[0].StartedAt)
+	assert.Equal(t, finishedAt, *stepLogs[0].FinishedAt)
+	assert.Equal(t, finishedAt, *stepLogs[1].StartedAt)
+	assert.Nil(t, stepLogs[1].FinishedAt)
+	assert.Nil(t, stepLogs[2].StartedAt)
+}
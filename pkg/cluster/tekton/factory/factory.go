@@ -15,12 +15,14 @@
 package factory
 
 import (
+	"fmt"
 	"sync"
 
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/s3"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton"
 	"github.com/horizoncd/horizon/pkg/cluster/tekton/collector"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/jenkins"
 	tektonconfig "github.com/horizoncd/horizon/pkg/config/tekton"
 	"github.com/horizoncd/horizon/pkg/util/errors"
 )
@@ -31,8 +33,10 @@ const (
 )
 
 type Factory interface {
-	GetTekton(environment string) (tekton.Interface, error)
-	GetTektonCollector(environment string) (collector.Interface, error)
+	// GetTekton returns the CI backend of the template in the environment,
+	// the backend of the environment is returned if the template has no backend of its own
+	GetTekton(environment, template string) (tekton.Interface, error)
+	GetTektonCollector(environment, template string) (collector.Interface, error)
 }
 
 type factory struct {
@@ -49,64 +53,100 @@ func NewFactory(tektonMapper tektonconfig.Mapper) (Factory, error) {
 
 	cache := &sync.Map{}
 	for env, tektonConfig := range tektonMapper {
-		t, err := tekton.NewTekton(tektonConfig)
+		c, err := newTektonCache(tektonConfig)
 		if err != nil {
 			return nil, errors.E(op, err)
 		}
-		var c collector.Interface
-		if tektonConfig.LogStorage.Type == _s3Storage {
-			s3Driver, err := s3.NewDriver(s3.Params{
-				AccessKey:        tektonConfig.LogStorage.AccessKey,
-				SecretKey:        tektonConfig.LogStorage.SecretKey,
-				Region:           tektonConfig.LogStorage.Region,
-				Endpoint:         tektonConfig.LogStorage.Endpoint,
-				Bucket:           tektonConfig.LogStorage.Bucket,
-				DisableSSL:       tektonConfig.LogStorage.DisableSSL,
-				SkipVerify:       tektonConfig.LogStorage.SkipVerify,
-				S3ForcePathStyle: tektonConfig.LogStorage.S3ForcePathStyle,
-				ContentType:      "text/plain",
-			})
+		cache.Store(env, c)
+		for template, templateConfig := range tektonConfig.Templates {
+			if templateConfig.LogStorage == nil {
+				templateConfig.LogStorage = tektonConfig.LogStorage
+			}
+			c, err := newTektonCache(templateConfig)
 			if err != nil {
 				return nil, errors.E(op, err)
 			}
-			c = collector.NewS3Collector(s3Driver, t)
-		} else {
-			c = collector.NewDummyCollector(t)
+			cache.Store(cacheKey(env, template), c)
 		}
-		cache.Store(env, &tektonCache{
-			tekton:          t,
-			tektonCollector: c,
-		})
 	}
 	return &factory{
 		cache: cache,
 	}, nil
 }
 
-func (f factory) GetTekton(environment string) (tekton.Interface, error) {
-	cache, err := f.GetFromCache(environment)
+func newTektonCache(tektonConfig *tektonconfig.Tekton) (*tektonCache, error) {
+	t, err := newBackend(tektonConfig)
+	if err != nil {
+		return nil, err
+	}
+	var c collector.Interface
+	if tektonConfig.LogStorage != nil && tektonConfig.LogStorage.Type == _s3Storage {
+		s3Driver, err := s3.NewDriver(s3.Params{
+			AccessKey:        tektonConfig.LogStorage.AccessKey,
+			SecretKey:        tektonConfig.LogStorage.SecretKey,
+			Region:           tektonConfig.LogStorage.Region,
+			Endpoint:         tektonConfig.LogStorage.Endpoint,
+			Bucket:           tektonConfig.LogStorage.Bucket,
+			DisableSSL:       tektonConfig.LogStorage.DisableSSL,
+			SkipVerify:       tektonConfig.LogStorage.SkipVerify,
+			S3ForcePathStyle: tektonConfig.LogStorage.S3ForcePathStyle,
+			ContentType:      "text/plain",
+		})
+		if err != nil {
+			return nil, err
+		}
+		c = collector.NewS3Collector(s3Driver, t)
+	} else {
+		c = collector.NewDummyCollector(t)
+	}
+	return &tektonCache{
+		tekton:          t,
+		tektonCollector: c,
+	}, nil
+}
+
+func newBackend(tektonConfig *tektonconfig.Tekton) (tekton.Interface, error) {
+	switch tektonConfig.Kind {
+	case "", tektonconfig.KindTekton:
+		return tekton.NewTekton(tektonConfig)
+	case tektonconfig.KindJenkins:
+		return jenkins.New(tektonConfig.Jenkins)
+	default:
+		return nil, fmt.Errorf("unsupported kind of CI backend: %s", tektonConfig.Kind)
+	}
+}
+
+func cacheKey(environment, template string) string {
+	return environment + "/" + template
+}
+
+func (f factory) GetTekton(environment, template string) (tekton.Interface, error) {
+	cache, err := f.GetFromCache(environment, template)
 	if err != nil {
 		return nil, err
 	}
 	return cache.tekton, nil
 }
 
-func (f factory) GetTektonCollector(environment string) (collector.Interface, error) {
-	cache, err := f.GetFromCache(environment)
+func (f factory) GetTektonCollector(environment, template string) (collector.Interface, error) {
+	cache, err := f.GetFromCache(environment, template)
 	if err != nil {
 		return nil, err
 	}
 	return cache.tektonCollector, nil
 }
 
-func (f factory) GetFromCache(environment string) (*tektonCache, error) {
-	var ret interface{}
-	var ok bool
-	if ret, ok = f.cache.Load(environment); !ok {
+func (f factory) GetFromCache(environment, template string) (*tektonCache, error) {
+	if _, ok := f.cache.Load(environment); !ok {
 		// check and use default tekton
-		if ret, ok = f.cache.Load(_default); !ok {
-			return nil, herrors.NewErrNotFound(herrors.Tekton, "default tekton not found")
-		}
+		environment = _default
+	}
+	if ret, ok := f.cache.Load(cacheKey(environment, template)); ok {
+		return ret.(*tektonCache), nil
+	}
+	ret, ok := f.cache.Load(environment)
+	if !ok {
+		return nil, herrors.NewErrNotFound(herrors.Tekton, "default tekton not found")
 	}
 	return ret.(*tektonCache), nil
 }
@@ -0,0 +1,63 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package factory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/jenkins"
+	tektonconfig "github.com/horizoncd/horizon/pkg/config/tekton"
+)
+
+func TestGetTekton(t *testing.T) {
+	newJenkins := func(job string) *tektonconfig.Tekton {
+		return &tektonconfig.Tekton{
+			Kind:    tektonconfig.KindJenkins,
+			Jenkins: &tektonconfig.Jenkins{Server: "https://jenkins.com", Job: job},
+		}
+	}
+	defaultConfig := newJenkins("default")
+	defaultConfig.Templates = map[string]*tektonconfig.Tekton{"javaapp": newJenkins("default-javaapp")}
+	testConfig := newJenkins("test")
+	testConfig.Templates = map[string]*tektonconfig.Tekton{"javaapp": newJenkins("test-javaapp")}
+	fty, err := NewFactory(tektonconfig.Mapper{"default": defaultConfig, "test": testConfig})
+	assert.Nil(t, err)
+
+	for _, c := range []struct {
+		environment, template, expected string
+	}{
+		{"test", "javaapp", "test-javaapp"},
+		{"test", "nodejs", "test"},
+		{"online", "javaapp", "default-javaapp"},
+		{"online", "nodejs", "default"},
+	} {
+		backend, err := fty.GetTekton(c.environment, c.template)
+		assert.Nil(t, err)
+		expected, err := jenkins.New(&tektonconfig.Jenkins{Server: "https://jenkins.com", Job: c.expected})
+		assert.Nil(t, err)
+		assert.Equal(t, expected, backend, c)
+		_, err = fty.GetTektonCollector(c.environment, c.template)
+		assert.Nil(t, err)
+	}
+
+	_, err = NewFactory(tektonconfig.Mapper{"default": {Kind: "unknown"}})
+	assert.NotNil(t, err)
+	fty, err = NewFactory(tektonconfig.Mapper{})
+	assert.Nil(t, err)
+	_, err = fty.GetTekton("test", "javaapp")
+	assert.NotNil(t, err)
+}
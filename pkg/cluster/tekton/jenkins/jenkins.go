@@ -0,0 +1,593 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/log"
+	tektonconfig "github.com/horizoncd/horizon/pkg/config/tekton"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+const (
+	// Task is the task of the pipelineruns built by jenkins, the stages of the builds are its steps
+	Task = "build"
+	// ConsoleStep is the step of the logs out of any stage
+	ConsoleStep = "console"
+
+	// EventIDParam is the parameter of the builds identifying the pipelineruns
+	EventIDParam = "HORIZON_EVENT_ID"
+	// PipelineRunParam is the parameter of the builds carrying the pipelinerun in json, without the token
+	PipelineRunParam = "HORIZON_PIPELINERUN"
+	// TokenParam is the parameter of the builds carrying the token for calling back horizon,
+	// it must be declared as a password parameter of the job, so that it's masked in the builds
+	TokenParam = "HORIZON_TOKEN"
+
+	_labelKeyPipeline = "tekton.dev/pipeline"
+	// _labelKeyBuild is the label of the pipelineruns carrying the number of the builds
+	_labelKeyBuild = "jenkins.io/build"
+	// _labelKeyQueueItem is the label of the pipelineruns carrying the queue item of the builds
+	_labelKeyQueueItem = "jenkins.io/queue-item"
+	_defaultTimeout    = 30 * time.Second
+)
+
+var (
+	_stageStartPattern = regexp.MustCompile(`^\[Pipeline\] \{ \((.*)\)$`)
+	_stageEnd          = "[Pipeline] // stage"
+	_pipelineMarkup    = "[Pipeline] "
+	_queueItemPattern  = regexp.MustCompile(`/queue/item/(\d+)/?$`)
+
+	// errQueueItemCancelled means the build is cancelled before leaving the queue
+	errQueueItemCancelled = errors.New("queue item of jenkins is cancelled")
+)
+
+// Jenkins builds the clusters through a parameterized jenkins job. The builds are presented
+// as pipelineruns with a single task, whose steps are the stages of the builds.
+type Jenkins struct {
+	server  string
+	job     string
+	jobPath string
+	user    string
+	token   string
+	client  *http.Client
+	// builds records the numbers of the builds by their queue items,
+	// the queue items are only kept by jenkins for a while after the builds start
+	builds *sync.Map
+}
+
+var _ tekton.Interface = (*Jenkins)(nil)
+var _ tekton.Poller = (*Jenkins)(nil)
+
+func New(config *tektonconfig.Jenkins) (*Jenkins, error) {
+	if config == nil || config.Server == "" || config.Job == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, "server and job of jenkins are required")
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = _defaultTimeout
+	}
+	// the full name of job "a/b" is at the path "/job/a/job/b"
+	segments := strings.Split(strings.Trim(config.Job, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = "job/" + url.PathEscape(segment)
+	}
+	return &Jenkins{
+		server:  strings.TrimSuffix(config.Server, "/"),
+		job:     strings.Trim(config.Job, "/"),
+		jobPath: "/" + strings.Join(segments, "/"),
+		user:    config.User,
+		token:   config.Token,
+		client:  &http.Client{Timeout: timeout},
+		builds:  &sync.Map{},
+	}, nil
+}
+
+type build struct {
+	Number    int    `json:"number"`
+	Building  bool   `json:"building"`
+	Result    string `json:"result"`
+	Timestamp int64  `json:"timestamp"`
+	Duration  int64  `json:"duration"`
+	Actions   []struct {
+		Parameters []struct {
+			Name  string      `json:"name"`
+			Value interface{} `json:"value"`
+		} `json:"parameters"`
+	} `json:"actions"`
+}
+
+// queueItem is the item of the build in the queue of jenkins
+type queueItem struct {
+	ID         int  `json:"id"`
+	Cancelled  bool `json:"cancelled"`
+	Executable *struct {
+		Number int `json:"number"`
+	} `json:"executable"`
+}
+
+func (b *build) param(name string) string {
+	for _, action := range b.Actions {
+		for _, param := range action.Parameters {
+			if param.Name == name {
+				return fmt.Sprint(param.Value)
+			}
+		}
+	}
+	return ""
+}
+
+// stage is a stage of the build described by the pipeline stage view plugin
+type stage struct {
+	Name            string `json:"name"`
+	Status          string `json:"status"`
+	StartTimeMillis int64  `json:"startTimeMillis"`
+	DurationMillis  int64  `json:"durationMillis"`
+}
+
+// CreatePipelineRun triggers a build, the returned ci event id is made of the queue item of the build
+// and the event id set as the parameter of the build, such as "123/<uuid>"
+func (j *Jenkins) CreatePipelineRun(ctx context.Context, pr *tekton.PipelineRun) (string, error) {
+	const op = "jenkins: create pipelineRun"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	// the token is sent in a password parameter instead of the pipelinerun, which is shown in the builds
+	withoutToken := *pr
+	withoutToken.Token = ""
+	prBytes, err := json.Marshal(withoutToken)
+	if err != nil {
+		return "", perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	eventID := uuid.NewV4().String()
+	params := url.Values{}
+	params.Set(EventIDParam, eventID)
+	params.Set(PipelineRunParam, string(prBytes))
+	params.Set(TokenParam, pr.Token)
+	params.Set("APPLICATION", pr.Application)
+	params.Set("CLUSTER", pr.Cluster)
+	params.Set("ENVIRONMENT", pr.Environment)
+	params.Set("GIT_URL", pr.Git.URL)
+	params.Set("GIT_BRANCH", pr.Git.Branch)
+	params.Set("GIT_TAG", pr.Git.Tag)
+	params.Set("GIT_COMMIT", pr.Git.Commit)
+	params.Set("GIT_SUBFOLDER", pr.Git.Subfolder)
+	params.Set("IMAGE_URL", pr.ImageURL)
+
+	resp, err := j.do(ctx, http.MethodPost, j.jobPath+"/buildWithParameters", params)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusCreated {
+		return "", unexpected(ctx, resp)
+	}
+	// jenkins responds with the location of the queue item of the build
+	matches := _queueItemPattern.FindStringSubmatch(resp.Header.Get("Location"))
+	if matches == nil {
+		return "", perror.Wrapf(herrors.ErrHTTPRespNotAsExpected,
+			"no queue item in location: %s", resp.Header.Get("Location"))
+	}
+	return matches[1] + "/" + eventID, nil
+}
+
+func (j *Jenkins) GetPipelineRunByID(ctx context.Context, ciEventID string) (*v1beta1.PipelineRun, error) {
+	b, err := j.getBuild(ctx, ciEventID)
+	if err != nil {
+		if perror.Cause(err) == errQueueItemCancelled {
+			return j.cancelledPipelineRun(ciEventID), nil
+		}
+		return nil, err
+	}
+	stages, err := j.getStages(ctx, b.Number)
+	if err != nil {
+		return nil, err
+	}
+	return j.pipelineRun(ciEventID, b, stages), nil
+}
+
+func (j *Jenkins) PollPipelineRun(ctx context.Context, ciEventID string) (*v1beta1.PipelineRun, error) {
+	pr, err := j.GetPipelineRunByID(ctx, ciEventID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			// the build is still in queue
+			return nil, nil
+		}
+		return nil, err
+	}
+	if pr.Status.CompletionTime == nil {
+		return nil, nil
+	}
+	return pr, nil
+}
+
+func (j *Jenkins) StopPipelineRun(ctx context.Context, ciEventID string) error {
+	const op = "jenkins: stop pipelineRun"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	b, err := j.getBuild(ctx, ciEventID)
+	if err != nil {
+		if perror.Cause(err) == errQueueItemCancelled {
+			return nil
+		}
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			// cancel the queue item if the build has not started
+			queueID, _, err := parseCIEventID(ciEventID)
+			if err != nil {
+				return err
+			}
+			return j.post(ctx, fmt.Sprintf("/queue/cancelItem?id=%d", queueID))
+		}
+		return err
+	}
+	if !b.Building {
+		return nil
+	}
+	return j.post(ctx, j.buildPath(b.Number)+"/stop")
+}
+
+func (j *Jenkins) GetPipelineRunLogByID(ctx context.Context, ciEventID string) (<-chan log.Log, <-chan error, error) {
+	pr, err := j.GetPipelineRunByID(ctx, ciEventID)
+	if err != nil {
+		return nil, nil, perror.WithMessage(err, "failed to get build of jenkins")
+	}
+	return j.GetPipelineRunLog(ctx, pr)
+}
+
+func (j *Jenkins) GetPipelineRunLog(ctx context.Context, pr *v1beta1.PipelineRun) (<-chan log.Log, <-chan error, error) {
+	if _, ok := pr.Labels[_labelKeyBuild]; !ok && pr.Labels[_labelKeyQueueItem] != "" {
+		// the build is cancelled in queue, it has no logs
+		logC, errC := make(chan log.Log), make(chan error)
+		close(logC)
+		close(errC)
+		return logC, errC, nil
+	}
+	number, err := strconv.Atoi(pr.Labels[_labelKeyBuild])
+	if err != nil {
+		return nil, nil, perror.Wrapf(herrors.ErrParamInvalid, "pipelinerun %s is not built by jenkins", pr.Name)
+	}
+	resp, err := j.do(ctx, http.MethodGet, j.buildPath(number)+"/consoleText", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer func() { _ = resp.Body.Close() }()
+		return nil, nil, unexpected(ctx, resp)
+	}
+
+	logC := make(chan log.Log)
+	errC := make(chan error, 1)
+	go func() {
+		defer func() { _ = resp.Body.Close() }()
+		defer close(logC)
+		defer close(errC)
+		if err := readConsole(resp.Body, pr.Name, logC); err != nil {
+			errC <- err
+		}
+	}()
+	return logC, errC, nil
+}
+
+// DeletePipelineRun does nothing, the builds are kept by the retention of the jenkins job
+func (j *Jenkins) DeletePipelineRun(ctx context.Context, pr *v1beta1.PipelineRun) error {
+	return nil
+}
+
+// readConsole splits the console output of the build by the stages
+func readConsole(r io.Reader, pipeline string, logC chan<- log.Log) error {
+	stages := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if matches := _stageStartPattern.FindStringSubmatch(line); matches != nil {
+			stages = append(stages, matches[1])
+			continue
+		}
+		if line == _stageEnd {
+			if len(stages) > 0 {
+				stages = stages[:len(stages)-1]
+			}
+			continue
+		}
+		if strings.HasPrefix(line, _pipelineMarkup) {
+			continue
+		}
+		step := ConsoleStep
+		if len(stages) > 0 {
+			step = stages[len(stages)-1]
+		}
+		logC <- log.Log{Pipeline: pipeline, Task: Task, Step: step, Log: line}
+	}
+	return scanner.Err()
+}
+
+// parseCIEventID parses the queue item and the event id of the build from the ci event id
+func parseCIEventID(ciEventID string) (int, string, error) {
+	parts := strings.SplitN(ciEventID, "/", 2)
+	if len(parts) != 2 {
+		return 0, "", perror.Wrapf(herrors.ErrParamInvalid, "invalid ci event id of jenkins: %s", ciEventID)
+	}
+	queueID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", perror.Wrapf(herrors.ErrParamInvalid, "invalid ci event id of jenkins: %s", ciEventID)
+	}
+	return queueID, parts[1], nil
+}
+
+// getBuild gets the build of the pipelinerun, it's not found if the build is still in queue
+func (j *Jenkins) getBuild(ctx context.Context, ciEventID string) (*build, error) {
+	queueID, eventID, err := parseCIEventID(ciEventID)
+	if err != nil {
+		return nil, err
+	}
+	number, err := j.getBuildNumber(ctx, queueID)
+	if err != nil {
+		return nil, err
+	}
+	var b build
+	tree := "number,building,result,timestamp,duration,actions[parameters[name,value]]"
+	if err := j.getJSON(ctx, j.buildPath(number)+"/api/json?tree="+url.QueryEscape(tree), &b); err != nil {
+		return nil, err
+	}
+	if b.param(EventIDParam) != eventID {
+		return nil, herrors.NewErrNotFound(herrors.PipelinerunInJenkins,
+			fmt.Sprintf("build %d is not with %s=%s", number, EventIDParam, eventID))
+	}
+	return &b, nil
+}
+
+// getBuildNumber gets the number of the build by its queue item. The builds are searched by the queue item
+// if the queue item has been removed by jenkins, which happens in minutes after the build starts
+func (j *Jenkins) getBuildNumber(ctx context.Context, queueID int) (int, error) {
+	if number, ok := j.builds.Load(queueID); ok {
+		return number.(int), nil
+	}
+
+	var item queueItem
+	err := j.getJSON(ctx, fmt.Sprintf("/queue/item/%d/api/json", queueID), &item)
+	if err == nil {
+		if item.Cancelled {
+			return 0, perror.Wrapf(errQueueItemCancelled, "queue item %d", queueID)
+		}
+		if item.Executable == nil {
+			return 0, herrors.NewErrNotFound(herrors.PipelinerunInJenkins,
+				fmt.Sprintf("build of queue item %d is still in queue", queueID))
+		}
+		j.builds.Store(queueID, item.Executable.Number)
+		return item.Executable.Number, nil
+	}
+	if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+		return 0, err
+	}
+
+	var job struct {
+		Builds []struct {
+			Number  int `json:"number"`
+			QueueID int `json:"queueId"`
+		} `json:"allBuilds"`
+	}
+	if err := j.getJSON(ctx, j.jobPath+"/api/json?tree="+url.QueryEscape("allBuilds[number,queueId]"),
+		&job); err != nil {
+		return 0, err
+	}
+	for _, b := range job.Builds {
+		if b.QueueID == queueID {
+			j.builds.Store(queueID, b.Number)
+			return b.Number, nil
+		}
+	}
+	return 0, herrors.NewErrNotFound(herrors.PipelinerunInJenkins,
+		fmt.Sprintf("no build of queue item %d", queueID))
+}
+
+// getStages returns the stages of the build, it's empty if the pipeline stage view plugin is not installed
+func (j *Jenkins) getStages(ctx context.Context, number int) ([]*stage, error) {
+	var describe struct {
+		Stages []*stage `json:"stages"`
+	}
+	if err := j.getJSON(ctx, j.buildPath(number)+"/wfapi/describe", &describe); err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return describe.Stages, nil
+}
+
+// pipelineRun presents the build as a pipelinerun, so that it is collected as the ones of tekton
+func (j *Jenkins) pipelineRun(ciEventID string, b *build, stages []*stage) *v1beta1.PipelineRun {
+	name := fmt.Sprintf("jenkins-%d", b.Number)
+	pr := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				common.TektonTriggersEventIDKey: ciEventID,
+				_labelKeyBuild:                  strconv.Itoa(b.Number),
+				_labelKeyPipeline:               j.job,
+			},
+		},
+	}
+	startTime := metav1.NewTime(time.UnixMilli(b.Timestamp))
+	pr.Status.StartTime = &startTime
+	reason, status := v1beta1.PipelineRunReasonRunning, corev1.ConditionUnknown
+	if !b.Building {
+		completionTime := metav1.NewTime(time.UnixMilli(b.Timestamp + b.Duration))
+		pr.Status.CompletionTime = &completionTime
+		switch b.Result {
+		case "SUCCESS":
+			reason, status = v1beta1.PipelineRunReasonSuccessful, corev1.ConditionTrue
+		case "ABORTED":
+			reason, status = v1beta1.PipelineRunReasonCancelled, corev1.ConditionFalse
+		default:
+			reason, status = v1beta1.PipelineRunReasonFailed, corev1.ConditionFalse
+		}
+	}
+	pr.Status.SetCondition(&apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: status,
+		Reason: reason.String(),
+	})
+
+	taskRun := &v1beta1.TaskRunStatus{}
+	taskRun.StartTime, taskRun.CompletionTime = pr.Status.StartTime, pr.Status.CompletionTime
+	taskRun.SetCondition(&apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: status,
+		Reason: taskRunReason(reason),
+	})
+	for _, s := range stages {
+		step := v1beta1.StepState{Name: s.Name}
+		startedAt := metav1.NewTime(time.UnixMilli(s.StartTimeMillis))
+		switch s.Status {
+		case "NOT_EXECUTED":
+			continue
+		case "IN_PROGRESS", "PAUSED_PENDING_INPUT":
+			step.Running = &corev1.ContainerStateRunning{StartedAt: startedAt}
+		default:
+			step.Terminated = &corev1.ContainerStateTerminated{
+				Reason:     s.Status,
+				StartedAt:  startedAt,
+				FinishedAt: metav1.NewTime(time.UnixMilli(s.StartTimeMillis + s.DurationMillis)),
+			}
+			if s.Status != "SUCCESS" {
+				step.Terminated.ExitCode = 1
+			}
+		}
+		taskRun.Steps = append(taskRun.Steps, step)
+	}
+	pr.Status.TaskRuns = map[string]*v1beta1.PipelineRunTaskRunStatus{
+		name + "-" + Task: {PipelineTaskName: Task, Status: taskRun},
+	}
+	return pr
+}
+
+// cancelledPipelineRun presents the build cancelled in queue as a cancelled pipelinerun
+func (j *Jenkins) cancelledPipelineRun(ciEventID string) *v1beta1.PipelineRun {
+	queueID, _, _ := parseCIEventID(ciEventID)
+	pr := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("jenkins-queue-%d", queueID),
+			Labels: map[string]string{
+				common.TektonTriggersEventIDKey: ciEventID,
+				_labelKeyQueueItem:              strconv.Itoa(queueID),
+				_labelKeyPipeline:               j.job,
+			},
+		},
+	}
+	now := metav1.Now()
+	pr.Status.StartTime, pr.Status.CompletionTime = &now, &now
+	pr.Status.SetCondition(&apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionFalse,
+		Reason: v1beta1.PipelineRunReasonCancelled.String(),
+	})
+	return pr
+}
+
+func taskRunReason(reason v1beta1.PipelineRunReason) string {
+	switch reason {
+	case v1beta1.PipelineRunReasonSuccessful:
+		return v1beta1.TaskRunReasonSuccessful.String()
+	case v1beta1.PipelineRunReasonCancelled:
+		return v1beta1.TaskRunReasonCancelled.String()
+	case v1beta1.PipelineRunReasonFailed:
+		return v1beta1.TaskRunReasonFailed.String()
+	}
+	return v1beta1.TaskRunReasonRunning.String()
+}
+
+func (j *Jenkins) buildPath(number int) string {
+	return fmt.Sprintf("%s/%d", j.jobPath, number)
+}
+
+func (j *Jenkins) post(ctx context.Context, path string) error {
+	resp, err := j.do(ctx, http.MethodPost, path, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	// jenkins redirects to the build or the queue after stopping
+	if resp.StatusCode >= http.StatusBadRequest {
+		return unexpected(ctx, resp)
+	}
+	return nil
+}
+
+func (j *Jenkins) getJSON(ctx context.Context, path string, v interface{}) error {
+	resp, err := j.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return herrors.NewErrNotFound(herrors.PipelinerunInJenkins, path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return unexpected(ctx, resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return perror.Wrap(herrors.ErrReadFailed, err.Error())
+	}
+	return nil
+}
+
+func (j *Jenkins) do(ctx context.Context, method, path string, form url.Values) (*http.Response, error) {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, j.server+path, body)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRequestFailed, err.Error())
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if j.user != "" {
+		req.SetBasicAuth(j.user, j.token)
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRequestFailed, err.Error())
+	}
+	return resp, nil
+}
+
+func unexpected(ctx context.Context, resp *http.Response) error {
+	message := common.Response(ctx, resp)
+	return perror.Wrapf(herrors.ErrHTTPRespNotAsExpected, "statusCode = %d, message = %s", resp.StatusCode, message)
+}
@@ -0,0 +1,271 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jenkins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"knative.dev/pkg/apis"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/log"
+	tektonconfig "github.com/horizoncd/horizon/pkg/config/tekton"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+const _console = `Started by remote host
+[Pipeline] Start of Pipeline
+[Pipeline] { (Checkout)
+[Pipeline] sh
++ git clone https://github.com/horizoncd/horizon.git
+[Pipeline] }
+[Pipeline] // stage
+[Pipeline] { (Build)
++ make build
+[Pipeline] }
+[Pipeline] // stage
+[Pipeline] End of Pipeline
+Finished: SUCCESS`
+
+// fakeJenkins serves the builds of the job horizon/build, the builds are numbered from 1,
+// and so are their queue items
+type fakeJenkins struct {
+	builds []map[string]interface{}
+	// queued is the count of the queue items not started, the latest ones are queued
+	queued int
+	// expired is whether the queue items are removed
+	expired   bool
+	cancelled []string
+	stopped   []string
+}
+
+func (f *fakeJenkins) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, token, ok := r.BasicAuth(); !ok || user != "horizon" || token != "token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.URL.Path == "/queue/cancelItem" && r.Method == http.MethodPost {
+		f.cancelled = append(f.cancelled, r.URL.Query().Get("id"))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, "/queue/item/") && !f.expired {
+		id, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/queue/item/"), "/api/json"))
+		if err != nil || id > len(f.builds) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		item := map[string]interface{}{"id": id, "cancelled": false}
+		if id <= len(f.builds)-f.queued {
+			item["executable"] = map[string]interface{}{"number": id}
+		}
+		_ = json.NewEncoder(w).Encode(item)
+		return
+	}
+	const prefix = "/job/horizon/job/build/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	resource := strings.TrimPrefix(r.URL.Path, prefix)
+	switch {
+	case resource == "buildWithParameters" && r.Method == http.MethodPost:
+		_ = r.ParseForm()
+		parameters := make([]map[string]string, 0)
+		for name := range r.PostForm {
+			parameters = append(parameters, map[string]string{"name": name, "value": r.PostForm.Get(name)})
+		}
+		f.builds = append(f.builds, map[string]interface{}{
+			"number":    len(f.builds) + 1,
+			"queueId":   len(f.builds) + 1,
+			"building":  true,
+			"timestamp": 1700000000000,
+			"actions":   []interface{}{map[string]interface{}{}, map[string]interface{}{"parameters": parameters}},
+		})
+		w.Header().Set("Location", fmt.Sprintf("http://%s/queue/item/%d/", r.Host, len(f.builds)))
+		w.WriteHeader(http.StatusCreated)
+	case resource == "api/json":
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"allBuilds": f.builds[:len(f.builds)-f.queued]})
+	case strings.HasSuffix(resource, "/api/json"):
+		number, err := strconv.Atoi(strings.TrimSuffix(resource, "/api/json"))
+		if err != nil || number > len(f.builds)-f.queued {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.builds[number-1])
+	case strings.HasSuffix(resource, "/wfapi/describe"):
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"stages": []map[string]interface{}{
+			{"name": "Checkout", "status": "SUCCESS", "startTimeMillis": 1700000001000, "durationMillis": 1000},
+			{"name": "Build", "status": "FAILED", "startTimeMillis": 1700000002000, "durationMillis": 2000},
+			{"name": "Deploy", "status": "NOT_EXECUTED"},
+		}})
+	case strings.HasSuffix(resource, "/consoleText"):
+		_, _ = w.Write([]byte(_console))
+	case strings.HasSuffix(resource, "/stop") && r.Method == http.MethodPost:
+		f.stopped = append(f.stopped, strings.TrimSuffix(resource, "/stop"))
+		w.WriteHeader(http.StatusFound)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeJenkins) param(number int, name string) string {
+	actions := f.builds[number-1]["actions"].([]interface{})
+	for _, param := range actions[1].(map[string]interface{})["parameters"].([]map[string]string) {
+		if param["name"] == name {
+			return param["value"]
+		}
+	}
+	return ""
+}
+
+func TestJenkins(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeJenkins{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	_, err := New(&tektonconfig.Jenkins{Server: server.URL})
+	assert.NotNil(t, err)
+	j, err := New(&tektonconfig.Jenkins{Server: server.URL + "/", User: "horizon", Token: "token",
+		Job: "horizon/build"})
+	assert.Nil(t, err)
+
+	_, err = j.GetPipelineRunByID(ctx, "not-exists")
+	assert.NotNil(t, err)
+	pr, err := j.PollPipelineRun(ctx, "1/not-exists")
+	assert.Nil(t, err)
+	assert.Nil(t, pr)
+
+	fake.queued = 1
+	eventID, err := j.CreatePipelineRun(ctx, &tekton.PipelineRun{
+		Application: "app",
+		Cluster:     "app-test",
+		Environment: "test",
+		Git:         tekton.PipelineRunGit{URL: "https://github.com/horizoncd/horizon.git", Branch: "main"},
+		Token:       "secret",
+	})
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(eventID, "1/"))
+
+	// the token is not in the pipelinerun shown in the build
+	assert.Equal(t, "secret", fake.param(1, TokenParam))
+	assert.NotContains(t, fake.param(1, PipelineRunParam), "secret")
+
+	// the build is still in queue
+	pr, err = j.PollPipelineRun(ctx, eventID)
+	assert.Nil(t, err)
+	assert.Nil(t, pr)
+	assert.Nil(t, j.StopPipelineRun(ctx, eventID))
+	assert.Equal(t, []string{"1"}, fake.cancelled)
+
+	// the build is running
+	fake.queued = 0
+	pr, err = j.PollPipelineRun(ctx, eventID)
+	assert.Nil(t, err)
+	assert.Nil(t, pr)
+	pr, err = j.GetPipelineRunByID(ctx, eventID)
+	assert.Nil(t, err)
+	assert.Equal(t, eventID, pr.Labels[common.TektonTriggersEventIDKey])
+	assert.Equal(t, v1beta1.PipelineRunReasonRunning.String(),
+		pr.Status.GetCondition(apis.ConditionSucceeded).Reason)
+	assert.Nil(t, j.StopPipelineRun(ctx, eventID))
+	assert.Equal(t, []string{"1"}, fake.stopped)
+
+	fake.builds[0]["building"] = false
+	fake.builds[0]["result"] = "FAILURE"
+	fake.builds[0]["duration"] = 5000
+	pr, err = j.PollPipelineRun(ctx, eventID)
+	assert.Nil(t, err)
+	assert.NotNil(t, pr)
+	assert.Equal(t, v1beta1.PipelineRunReasonFailed.String(),
+		pr.Status.GetCondition(apis.ConditionSucceeded).Reason)
+	assert.Equal(t, int64(5), pr.Status.CompletionTime.Unix()-pr.Status.StartTime.Unix())
+	taskRun := pr.Status.TaskRuns["jenkins-1-build"]
+	assert.Equal(t, Task, taskRun.PipelineTaskName)
+	assert.Equal(t, 2, len(taskRun.Status.Steps))
+	assert.Equal(t, int32(0), taskRun.Status.Steps[0].Terminated.ExitCode)
+	assert.Equal(t, int32(1), taskRun.Status.Steps[1].Terminated.ExitCode)
+
+	logC, errC, err := j.GetPipelineRunLog(ctx, pr)
+	assert.Nil(t, err)
+	logs := make([]string, 0)
+	for l := range logC {
+		logs = append(logs, fmt.Sprintf("%s/%s: %s", l.Task, l.Step, l.Log))
+	}
+	assert.Nil(t, <-errC)
+	assert.Equal(t, []string{
+		"build/console: Started by remote host",
+		"build/Checkout: + git clone https://github.com/horizoncd/horizon.git",
+		"build/Build: + make build",
+		"build/console: Finished: SUCCESS",
+	}, logs)
+
+	_, _, err = j.GetPipelineRunLog(ctx, &v1beta1.PipelineRun{})
+	assert.NotNil(t, err)
+}
+
+func TestJenkinsExpiredQueueItems(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeJenkins{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	j, err := New(&tektonconfig.Jenkins{Server: server.URL, User: "horizon", Token: "token",
+		Job: "horizon/build"})
+	assert.Nil(t, err)
+
+	eventIDs := make([]string, 0)
+	for i := 0; i < 3; i++ {
+		eventID, err := j.CreatePipelineRun(ctx, &tekton.PipelineRun{Application: "app"})
+		assert.Nil(t, err)
+		eventIDs = append(eventIDs, eventID)
+	}
+
+	// the builds are found by their queue items after jenkins removes the items
+	fake.expired = true
+	for i, eventID := range eventIDs {
+		pr, err := j.GetPipelineRunByID(ctx, eventID)
+		assert.Nil(t, err)
+		assert.Equal(t, fmt.Sprintf("jenkins-%d", i+1), pr.Name)
+	}
+	_, err = j.GetPipelineRunByID(ctx, "4/"+strings.SplitN(eventIDs[0], "/", 2)[1])
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+}
+
+func TestReadConsole(t *testing.T) {
+	logC := make(chan log.Log)
+	go func() {
+		defer close(logC)
+		assert.Nil(t, readConsole(strings.NewReader(
+			"[Pipeline] { (Outer)\n[Pipeline] { (Inner)\ninner\n[Pipeline] // stage\nouter\n"), "pr", logC))
+	}()
+	steps := make([]string, 0)
+	for l := range logC {
+		steps = append(steps, l.Step+":"+l.Log)
+	}
+	assert.Equal(t, []string{"Inner:inner", "Outer:outer"}, steps)
+}
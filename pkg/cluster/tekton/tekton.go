@@ -35,6 +35,13 @@ type Interface interface {
 	DeletePipelineRun(ctx context.Context, pr *v1beta1.PipelineRun) error
 }
 
+// Poller is implemented by the CI backends which do not send cloud events when their pipelineruns finish,
+// their pipelineruns are polled and collected by horizon instead
+type Poller interface {
+	// PollPipelineRun returns the pipelinerun if it is finished, or nil if it is still running
+	PollPipelineRun(ctx context.Context, ciEventID string) (*v1beta1.PipelineRun, error)
+}
+
 type Tekton struct {
 	server    string
 	namespace string
@@ -89,16 +89,20 @@ const (
 	MemberSingleDelete               = "update tb_member set deleted_ts = ? where ID = ?"
 	MemberHardDeleteByResourceTypeID = "delete from tb_member where resource_type = ?" +
 		" and resource_id = ?"
-	MemberHardDeleteByMemberNameID = "delete from tb_member where membername_id = ?"
+	MemberHardDeleteByMemberNameID = "delete from tb_member where member_type = 0 and membername_id = ?"
 	// todo: fix user_type to query condition
-	MemberSelectAll = "select m.* from tb_member m join tb_user u on m.membername_id = u.id" +
-		" where m.resource_type = ? and m.resource_id = ? and m.deleted_ts = 0"
+	MemberSelectAll = "select m.* from tb_member m" +
+		" left join tb_user u on m.member_type = 0 and m.membername_id = u.id" +
+		" where m.resource_type = ? and m.resource_id = ? and m.deleted_ts = 0" +
+		" and (m.member_type = 1 or u.id is not null)"
 	// todo: fix user_type to query condition
 	MemberSelectByUserEmails = "select tb_member.* from tb_member join tb_user on tb_member.membername_id = tb_user.id" +
 		" where tb_member.resource_type = ? and tb_member.resource_id = ? and tb_user.email in ?" +
 		" and tb_member.member_type = 0 and tb_member.deleted_ts = 0 and tb_user.deleted_ts = 0"
 	MemberListResource = "select resource_id from tb_member where resource_type = ? and" +
-		" membername_id = ? and deleted_ts = 0"
+		" member_type = 0 and membername_id = ? and deleted_ts = 0"
+	MemberListResourceOfTeams = "select resource_id from tb_member where resource_type = ? and" +
+		" member_type = 1 and membername_id in ? and deleted_ts = 0"
 )
 
 /* sql about group */
@@ -108,11 +112,12 @@ const (
 	GroupDelete                 = "update tb_group set deleted_ts = ?, updated_by = ? where id = ?"
 	GroupUpdateBasic            = "update tb_group set name = ?, path = ?, description = ?, visibility_level = ?, " +
 		"updated_by = ? where id = ?"
-	GroupUpdateParentID       = "update tb_group set parent_id = ?, updated_by = ? where id = ?"
-	GroupQueryByID            = "select * from tb_group where id = ? and deleted_ts = 0"
-	GroupQueryByIDs           = "select * from tb_group where id in ? and deleted_ts = 0"
-	GroupQueryByPaths         = "select * from tb_group where path in ? and deleted_ts = 0"
-	GroupQueryByIDNameFuzzily = "select * from tb_group " +
+	GroupUpdateParentID          = "update tb_group set parent_id = ?, updated_by = ? where id = ?"
+	GroupQueryByID               = "select * from tb_group where id = ? and deleted_ts = 0"
+	GroupQueryByIDs              = "select * from tb_group where id in ? and deleted_ts = 0"
+	GroupQueryByVisibilityLevels = "select * from tb_group where visibility_level in ? and deleted_ts = 0"
+	GroupQueryByPaths            = "select * from tb_group where path in ? and deleted_ts = 0"
+	GroupQueryByIDNameFuzzily    = "select * from tb_group " +
 		"where traversal_ids like ? and name like ? and deleted_ts = 0"
 	GroupAll                      = "select * from tb_group where deleted_ts = 0"
 	GroupUpdateTraversalIDs       = "update tb_group set traversal_ids = ?, updated_by = ? where id = ? and deleted_ts = 0"
@@ -121,19 +126,20 @@ const (
 		"where traversal_ids like ? and deleted_ts = 0"
 	GroupQueryByNameOrPathUnderParent = "select * from tb_group where parent_id = ? " +
 		"and (name = ? or path = ?) and deleted_ts = 0"
+	// GroupQueryGroupChildren the placeholders %s are the extra conditions of groups and applications
 	GroupQueryGroupChildren = "" +
 		"select * from (select g.id, g.name, g.path, description, updated_at, 'group' as type from tb_group g " +
-		"where g.parent_id=? and g.deleted_ts = 0 " +
+		"where g.parent_id=? and g.deleted_ts = 0%s " +
 		"union " +
 		"select a.id, a.name, a.name as path, description, updated_at, 'application' as type from tb_application a " +
-		"where a.group_id=? and a.deleted_ts = 0) ga " +
+		"where a.group_id=? and a.deleted_ts = 0%s) ga " +
 		"order by ga.type desc,ga.updated_at desc limit ? offset ?"
 	GroupQueryGroupChildrenCount = "" +
 		"select count(1) from (select g.id, g.name, g.path, description, updated_at, 'group' as type from tb_group g " +
-		"where g.parent_id=? and g.deleted_ts = 0 " +
+		"where g.parent_id=? and g.deleted_ts = 0%s " +
 		"union " +
 		"select a.id, a.name, a.name as path, description, updated_at, 'application' as type from tb_application a " +
-		"where a.group_id=? and a.deleted_ts = 0) ga"
+		"where a.group_id=? and a.deleted_ts = 0%s) ga"
 )
 
 /* sql about application */
@@ -31,6 +31,8 @@ type Rule struct {
 }
 
 type Webhook struct {
+	// Name identifies the webhook in logs, url of client config is used if empty
+	Name          string        `yaml:"name"`
 	Kind          models.Kind   `yaml:"kind"`
 	FailurePolicy FailurePolicy `yaml:"failurePolicy"`
 	Timeout       time.Duration `yaml:"timeout"`
@@ -0,0 +1,24 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cipoller
+
+import "time"
+
+type Config struct {
+	// JobInterval is the interval to poll the builds of the CI backends without cloud events, 30 seconds by default
+	JobInterval time.Duration `yaml:"jobInterval"`
+	// MaxAge is the age of the pipelineruns beyond which their builds are not polled any more, 24 hours by default
+	MaxAge time.Duration `yaml:"maxAge"`
+}
@@ -44,4 +44,11 @@ type Config struct {
 
 	WebhookLogCleanRules []WebhookLogCleanRule `yaml:"webhookLogCleanRules"`
 	EventCleanRules      []EventCleanRule      `yaml:"eventCleanRules"`
+	// StepLogTTL is the time to keep the step logs of pipelineruns, they are kept forever if it is 0
+	StepLogTTL time.Duration `yaml:"stepLogTTL"`
+	// HookEventTTL is the time to keep the hook events acknowledged by all the handlers,
+	// they are kept forever if it is 0
+	HookEventTTL time.Duration `yaml:"hookEventTTL"`
+	// AccessAuditTTL is the time to keep the audits of denied requests, they are kept forever if it is 0
+	AccessAuditTTL time.Duration `yaml:"accessAuditTTL"`
 }
@@ -14,10 +14,21 @@
 
 package gitlab
 
+const (
+	// KindGitlab stores gitops repos in a gitlab instance, it is the default kind
+	KindGitlab = "gitlab"
+	// KindGit stores gitops repos as plain git repositories under RootDir,
+	// and mirrors them to URL by smart http protocol if URL is set, only a single replica is supported
+	KindGit = "git"
+)
+
 // GitopsRepoConfig gitops repo config
 type GitopsRepoConfig struct {
+	Kind              string `yaml:"kind"`
 	URL               string `yaml:"url"`
 	Token             string `yaml:"token"`
+	Username          string `yaml:"username"`
+	RootDir           string `yaml:"rootDir"`
 	RootGroupPath     string `yaml:"rootGroupPath"`
 	DefaultBranch     string `yaml:"defaultBranch"`
 	DefaultVisibility string `yaml:"defaultVisibility"`
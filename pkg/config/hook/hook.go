@@ -0,0 +1,38 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hook
+
+import "time"
+
+const (
+	// QueueMemory buffers the events in memory, they are lost when horizon restarts
+	QueueMemory = "memory"
+	// QueueDB stores the events in database, they are shared by the replicas and survive restarts
+	QueueDB = "db"
+)
+
+type Config struct {
+	// Queue is the queue of the events, QueueMemory by default
+	Queue string `yaml:"queue"`
+	// ChannelSize is the size of the channel buffering the events in memory
+	ChannelSize int `yaml:"channelSize"`
+	// PollInterval is the interval to poll the due events in database, 5 seconds by default
+	PollInterval time.Duration `yaml:"pollInterval"`
+	// BatchSize is the max count of the events polled at once, 20 by default
+	BatchSize int `yaml:"batchSize"`
+	// LeaseDuration is the time for a replica to process an event before others take it over,
+	// 5 minutes by default
+	LeaseDuration time.Duration `yaml:"leaseDuration"`
+}
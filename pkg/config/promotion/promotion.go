@@ -0,0 +1,43 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promotion
+
+import "time"
+
+type Config struct {
+	// Enabled turns on the promotion analyzer, clusters are promoted by hand only when it's off
+	Enabled       bool     `yaml:"enabled"`
+	SupportedEnvs []string `yaml:"supportedEnvs"`
+	// AccountID is the user the analyzer promotes and rollbacks clusters as
+	AccountID   uint          `yaml:"accountID"`
+	JobInterval time.Duration `yaml:"jobInterval"`
+	// AnalysisDelay is how long a step must have been running before its metrics are analyzed
+	AnalysisDelay time.Duration `yaml:"analysisDelay"`
+	// AnalysisTimeout is how long a step can stay inconclusive, because of no metrics or prometheus unavailable,
+	// before its rollout is aborted
+	AnalysisTimeout time.Duration `yaml:"analysisTimeout"`
+	// RolloutTimeout is how long after a deployment finished its rollout is still watched
+	RolloutTimeout time.Duration `yaml:"rolloutTimeout"`
+	BatchSize      int           `yaml:"batchSize"`
+	Queries        []SLOQuery    `yaml:"queries"`
+}
+
+type SLOQuery struct {
+	Name string `yaml:"name"`
+	// Query is a PromQL template, which can refer to .Cluster, .Environment and .Region
+	Query string `yaml:"query"`
+	// Threshold is the max value the result of Query is allowed to reach
+	Threshold float64 `yaml:"threshold"`
+}
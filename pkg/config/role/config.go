@@ -14,10 +14,26 @@
 
 package role
 
-import "github.com/horizoncd/horizon/pkg/rbac/types"
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+)
 
 type Config struct {
+	// Version of the roles, bump it to update the roles stored in database
+	Version              uint         `yaml:"Version"`
 	RolePriorityRankDesc []string     `yaml:"RolePriorityRankDesc"`
 	DefaultRole          string       `yaml:"DefaultRole"`
 	Roles                []types.Role `yaml:"Roles"`
 }
+
+// StoreConfig decides where the roles are stored
+type StoreConfig struct {
+	// Database stores the roles in database, so that they can be changed through API without restart.
+	// The roles file initializes the roles when there is no role in database, and replaces
+	// the roles of the same names in database when its version is newer.
+	Database bool `yaml:"database"`
+	// ReloadInterval is the interval every replica reloads the roles from database
+	ReloadInterval time.Duration `yaml:"reloadInterval"`
+}
@@ -14,13 +14,41 @@
 
 package tekton
 
+import "time"
+
+const (
+	// KindTekton builds through tekton triggers, the pipelineruns are reported by cloud events
+	KindTekton = "tekton"
+	// KindJenkins builds through a parameterized jenkins job, the builds are polled until finished
+	KindJenkins = "jenkins"
+)
+
 type Mapper map[string]*Tekton
 
 type Tekton struct {
+	// Kind is the kind of the CI backend, KindTekton by default
+	Kind       string      `yaml:"kind"`
 	Server     string      `yaml:"server"`
 	Namespace  string      `yaml:"namespace"`
 	Kubeconfig string      `yaml:"kubeconfig"`
 	LogStorage *LogStorage `yaml:"logStorage"`
+	// Jenkins is required when Kind is KindJenkins
+	Jenkins *Jenkins `yaml:"jenkins"`
+	// Templates are the CI backends of the templates building through other engines in the environment,
+	// they inherit the log storage of the environment if not set
+	Templates map[string]*Tekton `yaml:"templates"`
+}
+
+type Jenkins struct {
+	// Server is the url of jenkins, such as https://jenkins.example.com
+	Server string `yaml:"server"`
+	User   string `yaml:"user"`
+	// Token is the api token of the user
+	Token string `yaml:"token"`
+	// Job is the full name of the parameterized job building the clusters, such as horizon/build
+	Job string `yaml:"job"`
+	// Timeout is the timeout of the requests to jenkins, 30 seconds by default
+	Timeout time.Duration `yaml:"timeout"`
 }
 
 type LogStorage struct {
@@ -25,4 +25,12 @@ type Repo struct {
 	KeyFile  string `yaml:"keyFile"`
 	CAFile   string `yaml:"caFile"`
 	RepoName string `yaml:"repoName"`
+	Cache    Cache  `yaml:"cache"`
+}
+
+type Cache struct {
+	// Size is the max count of the charts cached in memory, 100 by default
+	Size int `yaml:"size"`
+	// Dir is the directory the charts are cached in besides memory, charts are not cached on disk if empty
+	Dir string `yaml:"dir"`
 }
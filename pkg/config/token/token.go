@@ -21,4 +21,22 @@ type Config struct {
 	JwtSigningKey string `yaml:"jwtSigningKey"`
 	// CallbackTokenExpireIn is the expiration time of token for tekton callback
 	CallbackTokenExpireIn time.Duration `yaml:"callbackTokenExpireIn"`
+	// Issuer is the OIDC issuer identifier of horizon, such as https://horizon.example.com,
+	// horizon acts as an OIDC provider only when it is set
+	Issuer string `yaml:"issuer"`
+	// IDTokenExpireIn is the expiration time of OIDC ID tokens
+	IDTokenExpireIn time.Duration `yaml:"idTokenExpireIn"`
+	// SigningKeys are the asymmetric keys to sign JWT tokens, the first one is used to sign
+	// new tokens and the others are only used to verify tokens, so keys can be rotated by
+	// prepending a new one and removing the old one after all tokens signed by it expire
+	SigningKeys []SigningKey `yaml:"signingKeys"`
+}
+
+type SigningKey struct {
+	// KeyID is published as kid in JWKS and set in the header of signed tokens
+	KeyID string `yaml:"keyID"`
+	// Algorithm is RS256 or ES256
+	Algorithm string `yaml:"algorithm"`
+	// PrivateKey is the PEM encoded RSA or ECDSA(P-256) private key
+	PrivateKey string `yaml:"privateKey"`
 }
@@ -15,12 +15,26 @@
 package webhook
 
 type Config struct {
-	// seconds for http client timeout
+	// seconds for timeout of each request, including the wait for rate limit
 	ClientTimeout uint `yaml:"clientTimeout"`
-	// seconds to wait when there is no log to proress
+	// seconds to wait for new logs notified before checking logs to retry
 	IdleWaitInterval uint `yaml:"idleWaitInterval"`
 	// seconds to wait after complete a worker reconciliation
 	WorkerReconcileInterval uint `yaml:"workerReconcileInterval"`
 	// bytes limit to truncate for response body
 	ResponseBodyTruncateSize uint `yaml:"responseBodyTruncateSize"`
+	// max attempts to send a webhook log if the webhook does not specify, 1 means no retry
+	MaxAttempts uint `yaml:"maxAttempts"`
+	// seconds to wait before the first retry
+	RetryInitialInterval uint `yaml:"retryInitialInterval"`
+	// seconds limit for the wait between two attempts
+	RetryMaxInterval uint `yaml:"retryMaxInterval"`
+	// factor to multiply the wait by after each retry
+	RetryMultiplier float64 `yaml:"retryMultiplier"`
+	// max concurrent requests of each webhook
+	WorkerConcurrency uint `yaml:"workerConcurrency"`
+	// requests per second allowed to send to each receiver host
+	RateLimitPerHost float64 `yaml:"rateLimitPerHost"`
+	// max requests sent to each receiver host in a burst
+	RateLimitBurst uint `yaml:"rateLimitBurst"`
 }
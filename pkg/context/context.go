@@ -30,4 +30,6 @@ var TemplateListRecursively = &contextKey{}
 
 var ReleaseSyncToRepo = &contextKey{}
 
+var DeployWindowOverride = &contextKey{}
+
 var JWTTokenString = &contextKey{}
@@ -0,0 +1,124 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/deploywindow/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, window *models.DeployWindow) (*models.DeployWindow, error)
+	Get(ctx context.Context, id uint) (*models.DeployWindow, error)
+	List(ctx context.Context, resourceType string, resourceID uint,
+		query *q.Query) ([]*models.DeployWindow, int64, error)
+	ListEnabledByResources(ctx context.Context, environmentID uint,
+		groupIDs []uint) ([]*models.DeployWindow, error)
+	Update(ctx context.Context, window *models.DeployWindow) (*models.DeployWindow, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, window *models.DeployWindow) (*models.DeployWindow, error) {
+	if result := d.db.WithContext(ctx).Create(window); result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.DeployWindowInDB, result.Error.Error())
+	}
+	return window, nil
+}
+
+func (d *dao) Get(ctx context.Context, id uint) (*models.DeployWindow, error) {
+	var window models.DeployWindow
+	if result := d.db.WithContext(ctx).First(&window, id); result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.DeployWindowInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.DeployWindowInDB, result.Error.Error())
+	}
+	return &window, nil
+}
+
+func (d *dao) List(ctx context.Context, resourceType string, resourceID uint,
+	query *q.Query) ([]*models.DeployWindow, int64, error) {
+	var (
+		windows []*models.DeployWindow
+		count   int64
+	)
+	statement := d.db.WithContext(ctx).Model(&models.DeployWindow{}).
+		Where("resource_type = ?", resourceType).
+		Where("resource_id = ?", resourceID)
+	if query != nil {
+		if v, ok := query.Keywords[common.Enabled]; ok {
+			statement = statement.Where("enabled = ?", v)
+		}
+	}
+	if result := statement.Count(&count); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.DeployWindowInDB, result.Error.Error())
+	}
+	if query != nil {
+		statement = statement.Limit(query.Limit()).Offset(query.Offset())
+	}
+	if result := statement.Order("id desc").Find(&windows); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.DeployWindowInDB, result.Error.Error())
+	}
+	return windows, count, nil
+}
+
+func (d *dao) ListEnabledByResources(ctx context.Context, environmentID uint,
+	groupIDs []uint) ([]*models.DeployWindow, error) {
+	var windows []*models.DeployWindow
+	statement := d.db.WithContext(ctx).Where("enabled = ?", true)
+	if len(groupIDs) > 0 {
+		statement = statement.Where(d.db.
+			Where("resource_type = ? and resource_id = ?", common.ResourceEnvironment, environmentID).
+			Or("resource_type = ? and resource_id in ?", common.ResourceGroup, groupIDs))
+	} else {
+		statement = statement.
+			Where("resource_type = ? and resource_id = ?", common.ResourceEnvironment, environmentID)
+	}
+	if result := statement.Order("id").Find(&windows); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.DeployWindowInDB, result.Error.Error())
+	}
+	return windows, nil
+}
+
+func (d *dao) Update(ctx context.Context, window *models.DeployWindow) (*models.DeployWindow, error) {
+	if result := d.db.WithContext(ctx).Where("id = ?", window.ID).
+		Select("name", "description", "enabled", "type", "schedule",
+			"duration", "time_zone", "updated_by").
+		Updates(window); result.Error != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.DeployWindowInDB, result.Error.Error())
+	}
+	return d.Get(ctx, window.ID)
+}
+
+func (d *dao) Delete(ctx context.Context, id uint) error {
+	if result := d.db.WithContext(ctx).Delete(&models.DeployWindow{}, id); result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.DeployWindowInDB, result.Error.Error())
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/deploywindow/dao"
+	"github.com/horizoncd/horizon/pkg/deploywindow/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Manager interface {
+	Create(ctx context.Context, window *models.DeployWindow) (*models.DeployWindow, error)
+	Get(ctx context.Context, id uint) (*models.DeployWindow, error)
+	List(ctx context.Context, resourceType string, resourceID uint,
+		query *q.Query) ([]*models.DeployWindow, int64, error)
+	// ListEnabledByResources lists the enabled windows of the environment and the groups in the order of creation
+	ListEnabledByResources(ctx context.Context, environmentID uint, groupIDs []uint) ([]*models.DeployWindow, error)
+	Update(ctx context.Context, window *models.DeployWindow) (*models.DeployWindow, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, window *models.DeployWindow) (*models.DeployWindow, error) {
+	const op = "deploy window manager: create"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Create(ctx, window)
+}
+
+func (m *manager) Get(ctx context.Context, id uint) (*models.DeployWindow, error) {
+	const op = "deploy window manager: get"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Get(ctx, id)
+}
+
+func (m *manager) List(ctx context.Context, resourceType string, resourceID uint,
+	query *q.Query) ([]*models.DeployWindow, int64, error) {
+	const op = "deploy window manager: list"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.List(ctx, resourceType, resourceID, query)
+}
+
+func (m *manager) ListEnabledByResources(ctx context.Context, environmentID uint,
+	groupIDs []uint) ([]*models.DeployWindow, error) {
+	const op = "deploy window manager: list enabled by resources"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListEnabledByResources(ctx, environmentID, groupIDs)
+}
+
+func (m *manager) Update(ctx context.Context, window *models.DeployWindow) (*models.DeployWindow, error) {
+	const op = "deploy window manager: update"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Update(ctx, window)
+}
+
+func (m *manager) Delete(ctx context.Context, id uint) error {
+	const op = "deploy window manager: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Delete(ctx, id)
+}
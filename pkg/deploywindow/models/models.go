@@ -0,0 +1,43 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+const (
+	TypeAllow = "allow"
+	TypeDeny  = "deny"
+)
+
+// DeployWindow is a cron-style rule deciding when the clusters of an environment or a group
+// are allowed to be deployed. A window opens every time Schedule fires and keeps open for Duration seconds.
+// Deployments are held when any deny window is open, or when there are allow windows but none is open.
+type DeployWindow struct {
+	global.Model
+	// ResourceType is either groups or environments, a window of group applies to its descendants too
+	ResourceType string
+	ResourceID   uint
+	Name         string
+	Description  string
+	Enabled      bool
+	Type         string
+	Schedule     string
+	Duration     uint
+	TimeZone     string
+	CreatedBy    uint
+	UpdatedBy    uint
+}
@@ -0,0 +1,111 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	hctx "github.com/horizoncd/horizon/pkg/context"
+	"github.com/horizoncd/horizon/pkg/deploywindow"
+	deploywindowmanager "github.com/horizoncd/horizon/pkg/deploywindow/manager"
+	"github.com/horizoncd/horizon/pkg/deploywindow/models"
+	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+)
+
+type Service interface {
+	// Check returns the deploy window which holds deployments of the cluster at the time,
+	// the windows of the cluster's environment and of the groups the cluster belongs to are checked.
+	Check(ctx context.Context, cluster *clustermodels.Cluster, t time.Time) (*models.DeployWindow, error)
+	// EnsureDeployable returns ErrDeployWindowClosed if a deploy window holds deployments of the cluster now,
+	// admins can override it by hctx.DeployWindowOverride in the context.
+	EnsureDeployable(ctx context.Context, cluster *clustermodels.Cluster) error
+}
+
+type service struct {
+	appMgr          applicationmanager.Manager
+	groupMgr        groupmanager.Manager
+	envMgr          envmanager.Manager
+	deployWindowMgr deploywindowmanager.Manager
+}
+
+var _ Service = (*service)(nil)
+
+func NewService(manager *managerparam.Manager) Service {
+	return &service{
+		appMgr:          manager.ApplicationMgr,
+		groupMgr:        manager.GroupMgr,
+		envMgr:          manager.EnvMgr,
+		deployWindowMgr: manager.DeployWindowMgr,
+	}
+}
+
+func (s *service) Check(ctx context.Context, cluster *clustermodels.Cluster,
+	t time.Time) (*models.DeployWindow, error) {
+	application, err := s.appMgr.GetByID(ctx, cluster.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	group, err := s.groupMgr.GetByID(ctx, application.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	var environmentID uint
+	environment, err := s.envMgr.GetByName(ctx, cluster.EnvironmentName)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); !ok {
+			return nil, err
+		}
+	} else {
+		environmentID = environment.ID
+	}
+
+	windows, err := s.deployWindowMgr.ListEnabledByResources(ctx, environmentID,
+		groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs))
+	if err != nil {
+		return nil, err
+	}
+	return deploywindow.Check(windows, t)
+}
+
+func (s *service) EnsureDeployable(ctx context.Context, cluster *clustermodels.Cluster) error {
+	if override, ok := ctx.Value(hctx.DeployWindowOverride).(bool); ok && override {
+		currentUser, err := common.UserFromContext(ctx)
+		if err != nil {
+			return err
+		}
+		if !currentUser.IsAdmin() {
+			return perror.Wrap(herrors.ErrForbidden, "only admins can override deploy windows")
+		}
+		return nil
+	}
+
+	window, err := s.Check(ctx, cluster, time.Now())
+	if err != nil {
+		return err
+	}
+	if window != nil {
+		return perror.Wrapf(herrors.ErrDeployWindowClosed,
+			"deployments of cluster %s are held by deploy window %s", cluster.Name, window.Name)
+	}
+	return nil
+}
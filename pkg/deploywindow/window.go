@@ -0,0 +1,86 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploywindow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/deploywindow/models"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// Parse parses the schedule of window in its time zone
+func Parse(window *models.DeployWindow) (cron.Schedule, error) {
+	if window.TimeZone != "" {
+		if _, err := time.LoadLocation(window.TimeZone); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid time zone %s: %v", window.TimeZone, err)
+		}
+	}
+	spec := window.Schedule
+	if window.TimeZone != "" {
+		spec = fmt.Sprintf("CRON_TZ=%s %s", window.TimeZone, window.Schedule)
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid, "invalid schedule %s: %v", window.Schedule, err)
+	}
+	return schedule, nil
+}
+
+// IsOpen tells whether window is open at t, that is the schedule fired within the duration before t
+func IsOpen(window *models.DeployWindow, t time.Time) (bool, error) {
+	schedule, err := Parse(window)
+	if err != nil {
+		return false, err
+	}
+	duration := time.Duration(window.Duration) * time.Second
+	return !schedule.Next(t.Add(-duration)).After(t), nil
+}
+
+// Check returns the window which holds deployments at t, or nil if deployments are allowed.
+// An open deny window always holds deployments, otherwise one of the allow windows must be open if any.
+func Check(windows []*models.DeployWindow, t time.Time) (*models.DeployWindow, error) {
+	var closedAllow *models.DeployWindow
+	allowed := false
+	for _, window := range windows {
+		if !window.Enabled {
+			continue
+		}
+		open, err := IsOpen(window, t)
+		if err != nil {
+			return nil, err
+		}
+		switch window.Type {
+		case models.TypeDeny:
+			if open {
+				return window, nil
+			}
+		case models.TypeAllow:
+			if open {
+				allowed = true
+			} else if closedAllow == nil {
+				closedAllow = window
+			}
+		}
+	}
+	if allowed {
+		return nil, nil
+	}
+	return closedAllow, nil
+}
@@ -0,0 +1,117 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploywindow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/deploywindow/models"
+)
+
+func TestIsOpen(t *testing.T) {
+	// opens at 10:00 every weekday for 8 hours
+	window := &models.DeployWindow{
+		Enabled:  true,
+		Type:     models.TypeAllow,
+		Schedule: "0 10 * * 1-5",
+		Duration: 8 * 3600,
+		TimeZone: "Asia/Shanghai",
+	}
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	assert.Nil(t, err)
+
+	cases := []struct {
+		t    time.Time
+		open bool
+	}{
+		// Monday
+		{time.Date(2026, 10, 19, 9, 59, 0, 0, loc), false},
+		{time.Date(2026, 10, 19, 10, 0, 0, 0, loc), true},
+		{time.Date(2026, 10, 19, 17, 59, 0, 0, loc), true},
+		{time.Date(2026, 10, 19, 18, 1, 0, 0, loc), false},
+		// Sunday
+		{time.Date(2026, 10, 18, 12, 0, 0, 0, loc), false},
+		// the same instant in UTC
+		{time.Date(2026, 10, 19, 2, 30, 0, 0, time.UTC), true},
+	}
+	for _, c := range cases {
+		open, err := IsOpen(window, c.t)
+		assert.Nil(t, err)
+		assert.Equal(t, c.open, open, c.t.String())
+	}
+
+	_, err = IsOpen(&models.DeployWindow{Schedule: "bad"}, time.Now())
+	assert.NotNil(t, err)
+	_, err = IsOpen(&models.DeployWindow{Schedule: "* * * * *", TimeZone: "Nowhere/City"}, time.Now())
+	assert.NotNil(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	workday := &models.DeployWindow{
+		Name:     "workday",
+		Enabled:  true,
+		Type:     models.TypeAllow,
+		Schedule: "0 10 * * 1-5",
+		Duration: 8 * 3600,
+	}
+	weekend := &models.DeployWindow{
+		Name:     "weekend",
+		Enabled:  true,
+		Type:     models.TypeAllow,
+		Schedule: "0 0 * * 6",
+		Duration: 48 * 3600,
+	}
+	lunch := &models.DeployWindow{
+		Name:     "lunch",
+		Enabled:  true,
+		Type:     models.TypeDeny,
+		Schedule: "0 12 * * *",
+		Duration: 3600,
+	}
+	disabled := &models.DeployWindow{
+		Name:     "disabled",
+		Enabled:  false,
+		Type:     models.TypeDeny,
+		Schedule: "* * * * *",
+		Duration: 3600,
+	}
+	monday := func(hour int) time.Time {
+		return time.Date(2026, 10, 19, hour, 30, 0, 0, time.Local)
+	}
+
+	cases := []struct {
+		windows []*models.DeployWindow
+		t       time.Time
+		hold    *models.DeployWindow
+	}{
+		{nil, monday(9), nil},
+		{[]*models.DeployWindow{disabled}, monday(9), nil},
+		{[]*models.DeployWindow{workday}, monday(9), workday},
+		{[]*models.DeployWindow{workday}, monday(11), nil},
+		{[]*models.DeployWindow{workday, lunch}, monday(12), lunch},
+		{[]*models.DeployWindow{lunch}, monday(13), nil},
+		// one of the allow windows is open
+		{[]*models.DeployWindow{weekend, workday}, monday(11), nil},
+		{[]*models.DeployWindow{weekend, workday}, monday(20), weekend},
+	}
+	for i, c := range cases {
+		hold, err := Check(c.windows, c.t)
+		assert.Nil(t, err)
+		assert.Equal(t, c.hold, hold, i)
+	}
+}
@@ -135,6 +135,7 @@ var supportedEvents = map[string]string{
 	models.ClusterFreed:           "Cluster has been freed",
 	models.ClusterRestarted:       "Cluster has been restarted",
 	models.ClusterAction:          "Cluster has triggered an action",
+	models.ClusterAnalyzed:        "Cluster has been promoted or rollbacked by the metrics of its rollout",
 	models.ClusterPodsRescheduled: "Pods has been deleted to reschedule",
 	models.ClusterKubernetesEvent: "Kubernetes event associated with cluster has been triggered",
 	models.MemberCreated:          "New member has been created",
@@ -38,6 +38,7 @@ const (
 	ClusterFreed           string = "clusters_freed"
 	ClusterKubernetesEvent string = "clusters_kubernetes_event"
 	ClusterAction                 = "clusters_action"
+	ClusterAnalyzed        string = "clusters_analyzed"
 	MemberCreated          string = "members_created"
 	MemberUpdated          string = "members_updated"
 	MemberDeleted          string = "members_deleted"
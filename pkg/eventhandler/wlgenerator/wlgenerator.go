@@ -45,6 +45,8 @@ import (
 )
 
 const (
+	// WebhookSecretHeader carried the raw secret in logs generated by old versions,
+	// deliveries are signed by the webhook worker now
 	WebhookSecretHeader      = "X-Horizon-Webhook-Secret"
 	WebhookContentTypeHeader = "Content-Type"
 	WebhookContentType       = "application/json;charset=utf-8"
@@ -104,8 +106,15 @@ type MemberInfo struct {
 	MemberName   string                    `json:"memberName"`
 }
 
+// LogNotifier is notified with webhooks which have new logs to send
+type LogNotifier interface {
+	Notify(webhookIDs ...uint)
+}
+
 // WebhookLogGenerator generates webhook logs by events
 type WebhookLogGenerator struct {
+	notifier LogNotifier
+
 	webhookMgr     webhookmanager.Manager
 	eventMgr       eventmanager.Manager
 	groupMgr       groupmanager.Manager
@@ -116,8 +125,9 @@ type WebhookLogGenerator struct {
 	userMgr        usermanager.Manager
 }
 
-func NewWebhookLogGenerator(manager *managerparam.Manager) *WebhookLogGenerator {
+func NewWebhookLogGenerator(manager *managerparam.Manager, notifier LogNotifier) *WebhookLogGenerator {
 	return &WebhookLogGenerator{
+		notifier:       notifier,
 		webhookMgr:     manager.WebhookMgr,
 		eventMgr:       manager.EventMgr,
 		groupMgr:       manager.GroupMgr,
@@ -264,10 +274,10 @@ func (w *WebhookLogGenerator) listAssociatedResources(ctx context.Context,
 	return dep, resources
 }
 
-// makeRequestHeaders assemble headers of webhook request
-func (w *WebhookLogGenerator) makeRequestHeaders(secret string) (string, error) {
+// makeRequestHeaders assemble headers of webhook request,
+// signature headers are added when the request is sent
+func (w *WebhookLogGenerator) makeRequestHeaders() (string, error) {
 	header := http.Header{}
-	header.Add(WebhookSecretHeader, secret)
 	header.Add(WebhookContentTypeHeader, WebhookContentType)
 	headerByte, err := yaml.Marshal(header)
 	if err != nil {
@@ -437,7 +447,7 @@ func (w *WebhookLogGenerator) Process(ctx context.Context, events []*models.Even
 	}
 	for _, dependencyMap := range conditionsToCreate {
 		for _, dependency := range dependencyMap {
-			headers, err := w.makeRequestHeaders(dependency.webhook.Secret)
+			headers, err := w.makeRequestHeaders()
 			if err != nil {
 				log.Errorf(ctx, fmt.Sprintf("failed to make headers, error: %+v", err))
 				continue
@@ -465,5 +475,14 @@ func (w *WebhookLogGenerator) Process(ctx context.Context, events []*models.Even
 		log.Errorf(ctx, "failed to create webhooks, error: %s", err.Error())
 		return err
 	}
+
+	// 7. notify workers to send the logs
+	if w.notifier != nil {
+		webhookIDs := make([]uint, 0, len(webhookLogs))
+		for _, wl := range webhookLogs {
+			webhookIDs = append(webhookIDs, wl.WebhookID)
+		}
+		w.notifier.Notify(webhookIDs...)
+	}
 	return nil
 }
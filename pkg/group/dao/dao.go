@@ -25,6 +25,7 @@ import (
 	herrors "github.com/horizoncd/horizon/core/errors"
 	"github.com/horizoncd/horizon/lib/orm"
 	"github.com/horizoncd/horizon/lib/q"
+	applicationdao "github.com/horizoncd/horizon/pkg/application/dao"
 	dbcommon "github.com/horizoncd/horizon/pkg/common"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"github.com/horizoncd/horizon/pkg/group/models"
@@ -54,6 +55,8 @@ type DAO interface {
 	GetByPaths(ctx context.Context, paths []string) ([]*models.Group, error)
 	// GetAll return all the groups
 	GetAll(ctx context.Context) ([]*models.Group, error)
+	// ListByVisibilityLevels list the groups of the specified visibility levels
+	ListByVisibilityLevels(ctx context.Context, levels []string) ([]*models.Group, error)
 	// CountByParentID get the count of the records matching the given parentID
 	CountByParentID(ctx context.Context, parentID uint) (int64, error)
 	// UpdateBasic update basic info of a group
@@ -61,9 +64,10 @@ type DAO interface {
 	// ListWithoutPage query groups without paging
 	ListWithoutPage(ctx context.Context, query *q.Query) ([]*models.Group, error)
 	// List query groups with paging
-	List(ctx context.Context, query *q.Query) ([]*models.Group, int64, error)
+	List(ctx context.Context, query *q.Query, invisible *models.Invisible) ([]*models.Group, int64, error)
 	// ListChildren children of a group
-	ListChildren(ctx context.Context, parentID uint, pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error)
+	ListChildren(ctx context.Context, parentID uint, invisible *models.Invisible,
+		pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error)
 	// Transfer move a group under another parent group
 	Transfer(ctx context.Context, id, newParentID uint) error
 	// GetByNameOrPathUnderParent get by name or path under a specified parent
@@ -106,18 +110,31 @@ func (d *dao) GetByIDNameFuzzily(ctx context.Context, id uint, name string) ([]*
 	return groups, result.Error
 }
 
-func (d *dao) ListChildren(ctx context.Context, parentID uint, pageNumber, pageSize int) (
-	[]*models.GroupOrApplication, int64, error) {
+func (d *dao) ListChildren(ctx context.Context, parentID uint, invisible *models.Invisible,
+	pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error) {
 	var gas []*models.GroupOrApplication
 	var count int64
 
-	result := d.db.WithContext(ctx).Raw(dbcommon.GroupQueryGroupChildren, parentID, parentID,
-		pageSize, (pageNumber-1)*pageSize).Scan(&gas)
+	groupCondition, applicationCondition := "", ""
+	args := []interface{}{parentID}
+	if invisible != nil && len(invisible.GroupIDs) != 0 {
+		groupCondition = " and g.id not in ?"
+		args = append(args, invisible.GroupIDs)
+	}
+	args = append(args, parentID)
+	if condition, conditionArgs := applicationdao.InvisibleCondition("a", invisible); condition != "" {
+		applicationCondition = " and not " + condition
+		args = append(args, conditionArgs...)
+	}
+
+	result := d.db.WithContext(ctx).Raw(fmt.Sprintf(dbcommon.GroupQueryGroupChildren, groupCondition,
+		applicationCondition), append(args, pageSize, (pageNumber-1)*pageSize)...).Scan(&gas)
 	if result.Error != nil {
 		return nil, 0, herrors.NewErrGetFailed(herrors.GroupInDB, result.Error.Error())
 	}
 
-	result = d.db.WithContext(ctx).Raw(dbcommon.GroupQueryGroupChildrenCount, parentID, parentID).Scan(&count)
+	result = d.db.WithContext(ctx).Raw(fmt.Sprintf(dbcommon.GroupQueryGroupChildrenCount, groupCondition,
+		applicationCondition), args...).Scan(&count)
 
 	if result.Error != nil {
 		return nil, 0, herrors.NewErrGetFailed(herrors.GroupInDB, result.Error.Error())
@@ -213,6 +230,17 @@ func (d *dao) GetAll(ctx context.Context) ([]*models.Group, error) {
 	return groups, result.Error
 }
 
+func (d *dao) ListByVisibilityLevels(ctx context.Context, levels []string) ([]*models.Group, error) {
+	var groups []*models.Group
+	result := d.db.WithContext(ctx).Raw(dbcommon.GroupQueryByVisibilityLevels, levels).Scan(&groups)
+
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.GroupInDB, result.Error.Error())
+	}
+
+	return groups, nil
+}
+
 func (d *dao) CheckPathUnique(ctx context.Context, group *models.Group) error {
 	queryResult := models.Group{}
 	result := d.db.WithContext(ctx).Raw(dbcommon.GroupQueryByParentIDAndPath,
@@ -388,13 +416,18 @@ func (d *dao) ListWithoutPage(ctx context.Context, query *q.Query) ([]*models.Gr
 	return groups, result.Error
 }
 
-func (d *dao) List(ctx context.Context, query *q.Query) ([]*models.Group, int64, error) {
+func (d *dao) List(ctx context.Context, query *q.Query,
+	invisible *models.Invisible) ([]*models.Group, int64, error) {
 	var groups []*models.Group
 
 	sort := orm.FormatSortExp(query)
 	offset := (query.PageNumber - 1) * query.PageSize
 	var count int64
-	result := d.db.WithContext(ctx).Order(sort).Where(query.Keywords).Offset(offset).Limit(query.PageSize).Find(&groups).
+	statement := d.db.WithContext(ctx).Order(sort).Where(query.Keywords)
+	if invisible != nil && len(invisible.GroupIDs) != 0 {
+		statement = statement.Where("id not in ?", invisible.GroupIDs)
+	}
+	result := statement.Offset(offset).Limit(query.PageSize).Find(&groups).
 		Offset(-1).Count(&count)
 	if result.Error != nil {
 		return nil, 0, herrors.NewErrListFailed(herrors.GroupInDB, result.Error.Error())
@@ -67,16 +67,22 @@ type Manager interface {
 	GetByIDNameFuzzily(ctx context.Context, id uint, name string) ([]*models.Group, error)
 	// GetAll return all the groups
 	GetAll(ctx context.Context) ([]*models.Group, error)
+	// ListByVisibilityLevels list the groups of the specified visibility levels
+	ListByVisibilityLevels(ctx context.Context, levels []string) ([]*models.Group, error)
 	// UpdateBasic update basic info of a group
 	UpdateBasic(ctx context.Context, group *models.Group) error
 	// GetSubGroupsUnderParentIDs get subgroups under the given parent groups without paging
 	GetSubGroupsUnderParentIDs(ctx context.Context, parentIDs []uint) ([]*models.Group, error)
 	// Transfer move a group under another parent group
 	Transfer(ctx context.Context, id, newParentID uint) error
-	// GetSubGroups get subgroups of a parent group, order by updateTime desc by default with paging
-	GetSubGroups(ctx context.Context, id uint, pageNumber, pageSize int) ([]*models.Group, int64, error)
-	// GetChildren get children of a parent group, order by updateTime desc by default with paging
-	GetChildren(ctx context.Context, parentID uint, pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error)
+	// GetSubGroups get subgroups of a parent group except the invisible ones,
+	// order by updateTime desc by default with paging
+	GetSubGroups(ctx context.Context, id uint, invisible *models.Invisible,
+		pageNumber, pageSize int) ([]*models.Group, int64, error)
+	// GetChildren get children of a parent group except the invisible ones,
+	// order by updateTime desc by default with paging
+	GetChildren(ctx context.Context, parentID uint, invisible *models.Invisible,
+		pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error)
 	// GetByNameOrPathUnderParent get by name or path under a specified parent
 	GetByNameOrPathUnderParent(ctx context.Context, name, path string, parentID uint) ([]*models.Group, error)
 	// GetSubGroupsByGroupIDs get groups and its subGroups by specified groupIDs
@@ -115,14 +121,19 @@ func New(db *gorm.DB) Manager {
 	}
 }
 
-func (m manager) GetChildren(ctx context.Context, parentID uint, pageNumber, pageSize int) (
-	[]*models.GroupOrApplication, int64, error) {
-	return m.groupDAO.ListChildren(ctx, parentID, pageNumber, pageSize)
+func (m manager) GetChildren(ctx context.Context, parentID uint, invisible *models.Invisible,
+	pageNumber, pageSize int) ([]*models.GroupOrApplication, int64, error) {
+	return m.groupDAO.ListChildren(ctx, parentID, invisible, pageNumber, pageSize)
 }
 
-func (m manager) GetSubGroups(ctx context.Context, id uint, pageNumber, pageSize int) ([]*models.Group, int64, error) {
+func (m manager) GetSubGroups(ctx context.Context, id uint, invisible *models.Invisible,
+	pageNumber, pageSize int) ([]*models.Group, int64, error) {
 	query := formatListGroupQuery(id, pageNumber, pageSize)
-	return m.groupDAO.List(ctx, query)
+	return m.groupDAO.List(ctx, query, invisible)
+}
+
+func (m manager) ListByVisibilityLevels(ctx context.Context, levels []string) ([]*models.Group, error) {
+	return m.groupDAO.ListByVisibilityLevels(ctx, levels)
 }
 
 func (m manager) Transfer(ctx context.Context, id, newParentID uint) error {
@@ -0,0 +1,101 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+const (
+	// VisibilityPublic public resources are visible to everyone
+	VisibilityPublic = "public"
+	// VisibilityInternal internal resources are visible to everyone except robots of access tokens
+	VisibilityInternal = "internal"
+	// VisibilityPrivate private resources are only visible to their members
+	VisibilityPrivate = "private"
+)
+
+var visibilityRanks = map[string]int{
+	VisibilityPublic:   0,
+	VisibilityInternal: 1,
+	VisibilityPrivate:  2,
+}
+
+// IsValidVisibility returns whether the visibility level is supported, empty means public
+func IsValidVisibility(level string) bool {
+	_, ok := visibilityRanks[level]
+	return ok || level == ""
+}
+
+// MoreRestrictive returns the more restrictive one of the two visibility levels,
+// it's used to inherit the visibility level from parents
+func MoreRestrictive(level1, level2 string) string {
+	if visibilityRanks[level2] > visibilityRanks[level1] {
+		return level2
+	}
+	if level1 == "" {
+		return VisibilityPublic
+	}
+	return level1
+}
+
+// Invisible describes the groups and applications which are invisible to a user
+type Invisible struct {
+	// Levels the visibility levels invisible to the user
+	Levels []string
+	// GroupIDs the groups invisible to the user, including the subgroups of them
+	GroupIDs []uint
+	// AuthorizedGroupIDs the groups which the user is a member of, including the subgroups of them
+	AuthorizedGroupIDs []uint
+	// AuthorizedApplicationIDs the applications which the user is a member of
+	AuthorizedApplicationIDs []uint
+	// AuthorizedClusterIDs the clusters which the user is a member of
+	AuthorizedClusterIDs []uint
+}
+
+// GroupVisible returns whether the group is visible
+func (i *Invisible) GroupVisible(id uint) bool {
+	if i == nil {
+		return true
+	}
+	return !containsID(i.GroupIDs, id)
+}
+
+// ApplicationVisible returns whether the application is visible
+func (i *Invisible) ApplicationVisible(id, groupID uint, level string) bool {
+	if i == nil || containsID(i.AuthorizedApplicationIDs, id) || containsID(i.AuthorizedGroupIDs, groupID) {
+		return true
+	}
+	if containsID(i.GroupIDs, groupID) {
+		return false
+	}
+	for _, invisibleLevel := range i.Levels {
+		if level == invisibleLevel {
+			return false
+		}
+	}
+	return true
+}
+
+// ClusterVisible returns whether the cluster is visible, clusters are visible
+// if their applications are visible or the user is a member of them
+func (i *Invisible) ClusterVisible(id, applicationID, groupID uint, level string) bool {
+	return i == nil || containsID(i.AuthorizedClusterIDs, id) || i.ApplicationVisible(applicationID, groupID, level)
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, item := range ids {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
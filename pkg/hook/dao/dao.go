@@ -0,0 +1,207 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/hook/models"
+)
+
+type DAO interface {
+	// Create creates the event and a pending delivery for each handler
+	Create(ctx context.Context, event *models.HookEvent, handlers []string) (*models.HookEvent, error)
+	ListEventsByIDs(ctx context.Context, ids []uint) ([]*models.HookEvent, error)
+	// ListDueDeliveries lists at most limit pending deliveries of the handlers
+	// which are due and not leased by anyone at the time
+	ListDueDeliveries(ctx context.Context, handlers []string, now time.Time, limit int) ([]*models.HookDelivery, error)
+	// Claim leases the delivery to owner until expireAt, it returns false if the delivery
+	// is acknowledged or leased by others
+	Claim(ctx context.Context, id uint, owner string, now, expireAt time.Time) (bool, error)
+	// Ack marks the delivery succeeded
+	Ack(ctx context.Context, id uint) error
+	// Nack records the failure of the delivery and releases its lease
+	Nack(ctx context.Context, id uint, failedTimes uint, errorMessage string, nextAttemptAt time.Time) error
+	// ListDeliveries lists the deliveries matching query, oldest first
+	ListDeliveries(ctx context.Context, query *models.DeliveryQuery,
+		pagination *q.Query) (int, []*models.HookDeliveryWithEvent, error)
+	// DeleteAcknowledgedBefore deletes at most limit events created before the time and acknowledged
+	// by all the handlers with their deliveries, it returns the count of deleted events
+	DeleteAcknowledgedBefore(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, event *models.HookEvent,
+	handlers []string) (*models.HookEvent, error) {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if result := tx.Create(event); result.Error != nil {
+			return herrors.NewErrInsertFailed(herrors.HookEventInDB, result.Error.Error())
+		}
+		if len(handlers) == 0 {
+			return nil
+		}
+		deliveries := make([]*models.HookDelivery, 0, len(handlers))
+		for _, handler := range handlers {
+			deliveries = append(deliveries, &models.HookDelivery{
+				EventID:       event.ID,
+				Handler:       handler,
+				Status:        models.StatusPending,
+				NextAttemptAt: event.CreatedAt,
+			})
+		}
+		if result := tx.Create(deliveries); result.Error != nil {
+			return herrors.NewErrInsertFailed(herrors.HookDeliveryInDB, result.Error.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (d *dao) ListEventsByIDs(ctx context.Context, ids []uint) ([]*models.HookEvent, error) {
+	var events []*models.HookEvent
+	if result := d.db.WithContext(ctx).Where("id in ?", ids).Find(&events); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.HookEventInDB, result.Error.Error())
+	}
+	return events, nil
+}
+
+func (d *dao) ListDueDeliveries(ctx context.Context, handlers []string,
+	now time.Time, limit int) ([]*models.HookDelivery, error) {
+	var deliveries []*models.HookDelivery
+	if len(handlers) == 0 {
+		return deliveries, nil
+	}
+	result := d.db.WithContext(ctx).
+		Where("handler in ? and status = ? and next_attempt_at <= ?", handlers, models.StatusPending, now).
+		Where("lease_expire_at is null or lease_expire_at < ?", now).
+		Order("next_attempt_at asc, id asc").Limit(limit).Find(&deliveries)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.HookDeliveryInDB, result.Error.Error())
+	}
+	return deliveries, nil
+}
+
+func (d *dao) Claim(ctx context.Context, id uint, owner string, now, expireAt time.Time) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&models.HookDelivery{}).
+		Where("id = ? and status = ?", id, models.StatusPending).
+		Where("lease_expire_at is null or lease_expire_at < ?", now).
+		Updates(map[string]interface{}{
+			"lease_owner":     owner,
+			"lease_expire_at": expireAt,
+		})
+	if result.Error != nil {
+		return false, herrors.NewErrUpdateFailed(herrors.HookDeliveryInDB, result.Error.Error())
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (d *dao) Ack(ctx context.Context, id uint) error {
+	result := d.db.WithContext(ctx).Model(&models.HookDelivery{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          models.StatusSucceeded,
+			"error_message":   "",
+			"lease_owner":     "",
+			"lease_expire_at": nil,
+		})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.HookDeliveryInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) Nack(ctx context.Context, id uint, failedTimes uint,
+	errorMessage string, nextAttemptAt time.Time) error {
+	result := d.db.WithContext(ctx).Model(&models.HookDelivery{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"failed_times":    failedTimes,
+			"error_message":   errorMessage,
+			"next_attempt_at": nextAttemptAt,
+			"lease_owner":     "",
+			"lease_expire_at": nil,
+		})
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.HookDeliveryInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) ListDeliveries(ctx context.Context, query *models.DeliveryQuery,
+	pagination *q.Query) (int, []*models.HookDeliveryWithEvent, error) {
+	var (
+		total      int64
+		deliveries []*models.HookDeliveryWithEvent
+	)
+	statement := d.db.WithContext(ctx).Table("tb_hook_delivery d").
+		Joins("join tb_hook_event e on d.event_id = e.id").
+		Where("d.deleted_ts = 0")
+	if query.Handler != "" {
+		statement = statement.Where("d.handler = ?", query.Handler)
+	}
+	if query.Status != "" {
+		statement = statement.Where("d.status = ?", query.Status)
+	}
+	if err := statement.Count(&total).Error; err != nil {
+		return 0, nil, herrors.NewErrGetFailed(herrors.HookDeliveryInDB, err.Error())
+	}
+	result := statement.Select("d.*, e.event_type, e.content, e.request_id").
+		Order("d.id asc").Limit(pagination.Limit()).Offset(pagination.Offset()).Scan(&deliveries)
+	if result.Error != nil {
+		return 0, nil, herrors.NewErrGetFailed(herrors.HookDeliveryInDB, result.Error.Error())
+	}
+	return int(total), deliveries, nil
+}
+
+func (d *dao) DeleteAcknowledgedBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	var ids []uint
+	pending := d.db.Model(&models.HookDelivery{}).Select("event_id").Where("status <> ?", models.StatusSucceeded)
+	result := d.db.WithContext(ctx).Model(&models.HookEvent{}).
+		Where("created_at < ? and id not in (?)", before, pending).
+		Order("id asc").Limit(limit).Pluck("id", &ids)
+	if result.Error != nil {
+		return 0, herrors.NewErrGetFailed(herrors.HookEventInDB, result.Error.Error())
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	var deleted int64
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the events are never read after acknowledged, so they are deleted physically
+		if result := tx.Unscoped().Where("event_id in ?", ids).Delete(&models.HookDelivery{}); result.Error != nil {
+			return herrors.NewErrDeleteFailed(herrors.HookDeliveryInDB, result.Error.Error())
+		}
+		result := tx.Unscoped().Where("id in ?", ids).Delete(&models.HookEvent{})
+		if result.Error != nil {
+			return herrors.NewErrDeleteFailed(herrors.HookEventInDB, result.Error.Error())
+		}
+		deleted = result.RowsAffected
+		return nil
+	})
+	return int(deleted), err
+}
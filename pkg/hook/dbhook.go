@@ -0,0 +1,281 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	hookconfig "github.com/horizoncd/horizon/pkg/config/hook"
+	"github.com/horizoncd/horizon/pkg/hook/hook"
+	"github.com/horizoncd/horizon/pkg/hook/manager"
+	"github.com/horizoncd/horizon/pkg/hook/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	_defaultPollInterval  = 5 * time.Second
+	_defaultBatchSize     = 20
+	_defaultLeaseDuration = 5 * time.Minute
+)
+
+// New creates the hook with the queue in config
+func New(config hookconfig.Config, mgr manager.Manager, handlers ...EventHandler) hook.Hook {
+	if config.Queue == hookconfig.QueueDB {
+		return NewDBHook(config, mgr, handlers...)
+	}
+	return NewInMemHook(config.ChannelSize, handlers...)
+}
+
+// DBHook stores the events in database with a delivery for each handler, so that the events
+// survive restarts and are processed by any of the replicas. Each handler acknowledges its
+// delivery independently, and the failed ones are retried with backoff until they succeed,
+// so every handler processes an event at least once.
+type DBHook struct {
+	mgr           manager.Manager
+	eventHandlers map[string]EventHandler
+	handlerNames  []string
+	// owner identifies the replica leasing the deliveries
+	owner         string
+	pollInterval  time.Duration
+	batchSize     int
+	leaseDuration time.Duration
+	notify        chan struct{}
+	stop          chan struct{}
+	quit          chan bool
+}
+
+func NewDBHook(config hookconfig.Config, mgr manager.Manager, handlers ...EventHandler) hook.Hook {
+	if config.PollInterval <= 0 {
+		config.PollInterval = _defaultPollInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = _defaultBatchSize
+	}
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = _defaultLeaseDuration
+	}
+	hostname, _ := os.Hostname()
+	h := &DBHook{
+		mgr:           mgr,
+		eventHandlers: make(map[string]EventHandler, len(handlers)),
+		owner:         fmt.Sprintf("%s-%s", hostname, uuid.NewV4().String()),
+		pollInterval:  config.PollInterval,
+		batchSize:     config.BatchSize,
+		leaseDuration: config.LeaseDuration,
+		notify:        make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+		quit:          make(chan bool),
+	}
+	for _, handler := range handlers {
+		name := handler.Name()
+		if _, ok := h.eventHandlers[name]; ok || name == "" {
+			// the deliveries of different handlers would be mixed up
+			panic(fmt.Sprintf("name of hook handler is empty or duplicated: %q", name))
+		}
+		h.eventHandlers[name] = handler
+		h.handlerNames = append(h.handlerNames, name)
+	}
+	return h
+}
+
+func (h *DBHook) Stop() {
+	close(h.stop)
+	log.Info(context.TODO(), "hook stopped")
+}
+
+func (h *DBHook) WaitStop() {
+	<-h.quit
+}
+
+func (h *DBHook) Push(ctx context.Context, event hook.Event) {
+	if len(h.handlerNames) == 0 {
+		log.Infof(ctx, "no handler is registered, event is dropped, eventType = %s", event.EventType)
+		return
+	}
+	content, err := json.Marshal(event.Event)
+	if err != nil {
+		log.Errorf(ctx, "failed to encode event, eventType = %s, event = %+v, err = %v",
+			event.EventType, event.Event, err)
+		return
+	}
+	hookEvent := &models.HookEvent{
+		EventType: string(event.EventType),
+		Content:   string(content),
+	}
+	rid, err := requestid.FromContext(ctx)
+	if err != nil {
+		log.Warning(ctx, "rid not found in ctx")
+	} else {
+		hookEvent.RequestID = rid
+	}
+	ctxUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		log.Warning(ctx, "can not find user in context")
+	} else {
+		user, _ := json.Marshal(&userauth.DefaultInfo{
+			Name:     ctxUser.GetName(),
+			FullName: ctxUser.GetFullName(),
+			ID:       ctxUser.GetID(),
+			Email:    ctxUser.GetEmail(),
+			Admin:    ctxUser.IsAdmin(),
+		})
+		hookEvent.User = string(user)
+	}
+
+	if _, err := h.mgr.Create(ctx, hookEvent, h.handlerNames); err != nil {
+		log.Errorf(ctx, "failed to push event, eventType = %s, event = %s, err = %v",
+			event.EventType, content, err)
+		return
+	}
+	// wake up the processing of this replica without waiting for the next poll
+	select {
+	case h.notify <- struct{}{}:
+	default:
+	}
+	log.Infof(ctx, "pushed event, eventType = %s, event = %+v", event.EventType, event.Event)
+}
+
+func (h *DBHook) Process() {
+	ctx := context.Background()
+	ticker := time.NewTicker(h.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.stop:
+			log.Info(ctx, "process ok")
+			h.quit <- true
+			log.Info(ctx, "hook stopped, ProcessExit")
+			return
+		default:
+		}
+		if h.deliver(ctx) > 0 {
+			// there may be more due deliveries
+			continue
+		}
+		select {
+		case <-h.stop:
+		case <-h.notify:
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver processes a batch of the due deliveries, it returns the count of deliveries claimed by this replica
+func (h *DBHook) deliver(ctx context.Context) int {
+	deliveries, err := h.mgr.ListDueDeliveries(ctx, h.handlerNames, time.Now(), h.batchSize)
+	if err != nil {
+		log.Errorf(ctx, "failed to list due deliveries, err = %v", err)
+		return 0
+	}
+	if len(deliveries) == 0 {
+		return 0
+	}
+	eventIDs := make([]uint, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		eventIDs = append(eventIDs, delivery.EventID)
+	}
+	events, err := h.mgr.ListEventsByIDs(ctx, eventIDs)
+	if err != nil {
+		log.Errorf(ctx, "failed to list events, err = %v", err)
+		return 0
+	}
+	eventMap := make(map[uint]*models.HookEvent, len(events))
+	for _, event := range events {
+		eventMap[event.ID] = event
+	}
+
+	claimed := 0
+	for _, delivery := range deliveries {
+		event, ok := eventMap[delivery.EventID]
+		if !ok {
+			log.Warningf(ctx, "event %d of delivery %d not found", delivery.EventID, delivery.ID)
+			continue
+		}
+		now := time.Now()
+		ok, err := h.mgr.Claim(ctx, delivery.ID, h.owner, now, now.Add(h.leaseDuration))
+		if err != nil {
+			log.Errorf(ctx, "failed to claim delivery %d, err = %v", delivery.ID, err)
+			continue
+		}
+		if !ok {
+			// processed or leased by another replica
+			continue
+		}
+		claimed++
+		h.process(ctx, event, delivery)
+	}
+	return claimed
+}
+
+func (h *DBHook) process(ctx context.Context, event *models.HookEvent, delivery *models.HookDelivery) {
+	eventCtx, err := h.eventCtx(event, delivery)
+	if err == nil {
+		ctx = eventCtx.Ctx
+		log.Infof(ctx, "received event, eventType = %s, event = %+v, handler %s",
+			event.EventType, eventCtx.Event, delivery.Handler)
+		err = h.eventHandlers[delivery.Handler].Process(eventCtx)
+	}
+	if err != nil {
+		log.Errorf(ctx, "handler %s, event %d, err = %s", delivery.Handler, event.ID, err.Error())
+		failedTimes := delivery.FailedTimes + 1
+		if err := h.mgr.Nack(ctx, delivery.ID, failedTimes, err.Error(),
+			time.Now().Add(backoff(failedTimes))); err != nil {
+			// the event is retried after the lease expires
+			log.Errorf(ctx, "failed to nack delivery %d, err = %v", delivery.ID, err)
+		}
+		return
+	}
+	if err := h.mgr.Ack(ctx, delivery.ID); err != nil {
+		// the event is processed again after the lease expires
+		log.Errorf(ctx, "failed to ack delivery %d, err = %v", delivery.ID, err)
+		return
+	}
+	log.Infof(ctx, "processed event, eventType = %s, event = %+v, handler %s",
+		event.EventType, eventCtx.Event, delivery.Handler)
+}
+
+// eventCtx restores the event and the context it is pushed in
+func (h *DBHook) eventCtx(event *models.HookEvent, delivery *models.HookDelivery) (*hook.EventCtx, error) {
+	ctx := context.Background()
+	if event.RequestID != "" {
+		ctx = log.WithContext(ctx, event.RequestID)
+	}
+	if event.User != "" {
+		var user userauth.DefaultInfo
+		if err := json.Unmarshal([]byte(event.User), &user); err != nil {
+			return nil, fmt.Errorf("failed to decode user: %v", err)
+		}
+		ctx = common.WithContext(ctx, &user)
+	}
+	content, err := hook.DecodeEvent(hook.EventType(event.EventType), []byte(event.Content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode event: %v", err)
+	}
+	return &hook.EventCtx{
+		EventType:   hook.EventType(event.EventType),
+		Event:       content,
+		Ctx:         ctx,
+		FailedTimes: delivery.FailedTimes,
+	}, nil
+}
@@ -17,5 +17,7 @@ package handler
 import "github.com/horizoncd/horizon/pkg/hook/hook"
 
 type EventHandler interface {
+	// Name identifies the handler, the deliveries of DBHook are bound to it, so it must be unique and stable
+	Name() string
 	Process(event *hook.EventCtx) error
 }
@@ -16,6 +16,8 @@ package hook
 
 import (
 	"context"
+	"encoding/json"
+	"reflect"
 	"time"
 )
 
@@ -44,3 +46,26 @@ type EventCtx struct {
 	Ctx         context.Context
 	FailedTimes uint
 }
+
+var _eventTypes = map[EventType]reflect.Type{}
+
+// RegisterEvent registers the type of the events of eventType, so that the events stored
+// out of memory are decoded into the type instead of generic maps before handled
+func RegisterEvent(eventType EventType, event interface{}) {
+	_eventTypes[eventType] = reflect.TypeOf(event)
+}
+
+// DecodeEvent decodes the event of eventType from json
+func DecodeEvent(eventType EventType, data []byte) (interface{}, error) {
+	t, ok := _eventTypes[eventType]
+	if !ok {
+		var event interface{}
+		err := json.Unmarshal(data, &event)
+		return event, err
+	}
+	event := reflect.New(t)
+	if err := json.Unmarshal(data, event.Interface()); err != nil {
+		return nil, err
+	}
+	return event.Elem().Interface(), nil
+}
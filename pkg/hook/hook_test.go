@@ -16,13 +16,24 @@ package hook
 
 import (
 	"context"
+	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
 	handlermock "github.com/horizoncd/horizon/mock/pkg/hook/handler"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	hookconfig "github.com/horizoncd/horizon/pkg/config/hook"
 	hhook "github.com/horizoncd/horizon/pkg/hook/hook"
+	"github.com/horizoncd/horizon/pkg/hook/manager"
+	"github.com/horizoncd/horizon/pkg/hook/models"
 	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
@@ -30,6 +41,7 @@ func TestHook(t *testing.T) {
 	mockCtl := gomock.NewController(t)
 	defer mockCtl.Finish()
 	mockHandler := handlermock.NewMockEventHandler(mockCtl)
+	mockHandler.EXPECT().Name().Return("mock").AnyTimes()
 
 	eventHandlers := make([]EventHandler, 0)
 	eventHandlers = append(eventHandlers, mockHandler)
@@ -61,6 +73,80 @@ func TestHook(t *testing.T) {
 	memHook.WaitStop()
 }
 
+type clusterInfo struct {
+	ClusterID uint
+	Cluster   string
+}
+
+func TestDBHook(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&models.HookEvent{}, &models.HookDelivery{}))
+	mgr := manager.New(db)
+	hhook.RegisterEvent(hhook.CreateCluster, &clusterInfo{})
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+	mockHandler := handlermock.NewMockEventHandler(mockCtl)
+	mockHandler.EXPECT().Name().Return("mock").AnyTimes()
+	h := NewDBHook(hookconfig.Config{}, mgr, mockHandler).(*DBHook)
+	// handlers are bound to deliveries by names, so they must be unique
+	assert.Panics(t, func() { NewDBHook(hookconfig.Config{}, mgr, mockHandler, mockHandler) })
+	// another replica sharing the events
+	replica := NewDBHook(hookconfig.Config{}, mgr, mockHandler).(*DBHook)
+
+	ctx := context.WithValue(context.TODO(), requestid.HeaderXRequestID, "123") // nolint
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{Name: "tony", ID: 1})
+	h.Push(ctx, hhook.Event{
+		EventType: hhook.CreateCluster,
+		Event:     &clusterInfo{ClusterID: 1, Cluster: "app-test"},
+	})
+	total, deliveries, err := mgr.ListDeliveries(ctx, &models.DeliveryQuery{Status: models.StatusPending}, &q.Query{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "mock", deliveries[0].Handler)
+
+	// the event is restored with its context, and retried after failing
+	mockHandler.EXPECT().Process(gomock.Any()).DoAndReturn(func(event *hhook.EventCtx) error {
+		assert.Equal(t, &clusterInfo{ClusterID: 1, Cluster: "app-test"}, event.Event)
+		user, err := common.UserFromContext(event.Ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, "tony", user.GetName())
+		return errors.New("gitlab is unavailable")
+	}).Times(1)
+	assert.Equal(t, 1, h.deliver(ctx))
+	assert.Equal(t, 0, replica.deliver(ctx))
+	_, deliveries, err = mgr.ListDeliveries(ctx, &models.DeliveryQuery{}, &q.Query{})
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), deliveries[0].FailedTimes)
+	assert.Equal(t, "gitlab is unavailable", deliveries[0].ErrorMessage)
+	assert.True(t, deliveries[0].NextAttemptAt.After(time.Now()))
+
+	// a delivery leased by a replica is not taken over by others until the lease expires
+	assert.Nil(t, db.Model(&models.HookDelivery{}).Where("id = ?", deliveries[0].ID).
+		Update("next_attempt_at", time.Now()).Error)
+	claimed, err := mgr.Claim(ctx, deliveries[0].ID, "other", time.Now(), time.Now().Add(time.Minute))
+	assert.Nil(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, 0, replica.deliver(ctx))
+	assert.Nil(t, db.Model(&models.HookDelivery{}).Where("id = ?", deliveries[0].ID).
+		Update("lease_expire_at", time.Now().Add(-time.Second)).Error)
+
+	mockHandler.EXPECT().Process(gomock.Any()).DoAndReturn(func(event *hhook.EventCtx) error {
+		assert.Equal(t, uint(1), event.FailedTimes)
+		return nil
+	}).Times(1)
+	assert.Equal(t, 1, replica.deliver(ctx))
+	total, _, err = mgr.ListDeliveries(ctx, &models.DeliveryQuery{Status: models.StatusSucceeded}, &q.Query{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, 0, h.deliver(ctx))
+
+	go h.Process()
+	h.Stop()
+	h.WaitStop()
+}
+
 func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
@@ -17,7 +17,6 @@ package hook
 import (
 	"context"
 	"math"
-	"reflect"
 	"time"
 
 	"github.com/horizoncd/horizon/core/common"
@@ -27,6 +26,8 @@ import (
 )
 
 type EventHandler interface {
+	// Name identifies the handler, the deliveries of DBHook are bound to it, so it must be unique and stable
+	Name() string
 	Process(event *hook.EventCtx) error
 }
 
@@ -89,10 +90,10 @@ func (h *InMemHook) Process() {
 				time.AfterFunc(h.when(event), func() {
 					h.events <- event
 				})
-				log.Errorf(event.Ctx, "handler %s, err = %s", reflect.TypeOf(handlerEntry).Name(), err.Error())
+				log.Errorf(event.Ctx, "handler %s, err = %s", handlerEntry.Name(), err.Error())
 			} else {
 				log.Infof(event.Ctx, "processed event, eventType = %s, event = %+v, handler %s,",
-					event.EventType, event.Event, reflect.TypeOf(handlerEntry).Name())
+					event.EventType, event.Event, handlerEntry.Name())
 			}
 		}
 	}
@@ -103,13 +104,17 @@ func (h *InMemHook) Process() {
 
 func (h *InMemHook) when(event *hook.EventCtx) time.Duration {
 	event.FailedTimes++
+	return backoff(event.FailedTimes)
+}
 
-	backoff := float64(hook.DefaultDelay.Nanoseconds()) * math.Pow(2, float64(event.FailedTimes))
-	if backoff > math.MaxInt64 {
+// backoff returns the delay to retry an event failed for failedTimes, which grows exponentially up to hook.MaxDelay
+func backoff(failedTimes uint) time.Duration {
+	delay := float64(hook.DefaultDelay.Nanoseconds()) * math.Pow(2, float64(failedTimes))
+	if delay > math.MaxInt64 {
 		return hook.MaxDelay
 	}
 
-	calculated := time.Duration(backoff)
+	calculated := time.Duration(delay)
 	if calculated > hook.MaxDelay {
 		return hook.MaxDelay
 	}
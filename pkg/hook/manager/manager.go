@@ -0,0 +1,99 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/hook/dao"
+	"github.com/horizoncd/horizon/pkg/hook/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Manager interface {
+	// Create creates the event and a pending delivery for each handler
+	Create(ctx context.Context, event *models.HookEvent, handlers []string) (*models.HookEvent, error)
+	ListEventsByIDs(ctx context.Context, ids []uint) ([]*models.HookEvent, error)
+	// ListDueDeliveries lists at most limit pending deliveries of the handlers
+	// which are due and not leased by anyone at the time
+	ListDueDeliveries(ctx context.Context, handlers []string, now time.Time, limit int) ([]*models.HookDelivery, error)
+	// Claim leases the delivery to owner until expireAt, it returns false if the delivery
+	// is acknowledged or leased by others
+	Claim(ctx context.Context, id uint, owner string, now, expireAt time.Time) (bool, error)
+	// Ack marks the delivery succeeded
+	Ack(ctx context.Context, id uint) error
+	// Nack records the failure of the delivery and releases its lease
+	Nack(ctx context.Context, id uint, failedTimes uint, errorMessage string, nextAttemptAt time.Time) error
+	// ListDeliveries lists the deliveries matching query, oldest first
+	ListDeliveries(ctx context.Context, query *models.DeliveryQuery,
+		pagination *q.Query) (int, []*models.HookDeliveryWithEvent, error)
+	// DeleteAcknowledgedBefore deletes at most limit events created before the time and acknowledged
+	// by all the handlers with their deliveries, it returns the count of deleted events
+	DeleteAcknowledgedBefore(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, event *models.HookEvent,
+	handlers []string) (*models.HookEvent, error) {
+	const op = "hook manager: create"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Create(ctx, event, handlers)
+}
+
+func (m *manager) ListEventsByIDs(ctx context.Context, ids []uint) ([]*models.HookEvent, error) {
+	return m.dao.ListEventsByIDs(ctx, ids)
+}
+
+func (m *manager) ListDueDeliveries(ctx context.Context, handlers []string,
+	now time.Time, limit int) ([]*models.HookDelivery, error) {
+	return m.dao.ListDueDeliveries(ctx, handlers, now, limit)
+}
+
+func (m *manager) Claim(ctx context.Context, id uint, owner string, now, expireAt time.Time) (bool, error) {
+	return m.dao.Claim(ctx, id, owner, now, expireAt)
+}
+
+func (m *manager) Ack(ctx context.Context, id uint) error {
+	return m.dao.Ack(ctx, id)
+}
+
+func (m *manager) Nack(ctx context.Context, id uint, failedTimes uint,
+	errorMessage string, nextAttemptAt time.Time) error {
+	return m.dao.Nack(ctx, id, failedTimes, errorMessage, nextAttemptAt)
+}
+
+func (m *manager) ListDeliveries(ctx context.Context, query *models.DeliveryQuery,
+	pagination *q.Query) (int, []*models.HookDeliveryWithEvent, error) {
+	const op = "hook manager: list deliveries"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListDeliveries(ctx, query, pagination)
+}
+
+func (m *manager) DeleteAcknowledgedBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "hook manager: delete acknowledged before"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.DeleteAcknowledgedBefore(ctx, before, limit)
+}
@@ -0,0 +1,69 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+const (
+	// StatusPending means the event is not processed by the handler successfully yet
+	StatusPending = "pending"
+	// StatusSucceeded means the handler acknowledged the event
+	StatusSucceeded = "succeeded"
+)
+
+// HookEvent is an event pushed to the hook, it is kept until all the handlers acknowledge it
+type HookEvent struct {
+	global.Model
+	EventType string
+	// Content is the event encoded in json
+	Content   string
+	RequestID string
+	// User is the user pushing the event encoded in json
+	User string
+}
+
+// HookDelivery is the delivery of an event to a handler
+type HookDelivery struct {
+	global.Model
+	EventID      uint `gorm:"index"`
+	Handler      string
+	Status       string
+	FailedTimes  uint
+	ErrorMessage string
+	// NextAttemptAt is the earliest time to process the event
+	NextAttemptAt time.Time
+	// LeaseOwner is processing the event until LeaseExpireAt,
+	// other replicas could take over the delivery after the lease expires
+	LeaseOwner    string
+	LeaseExpireAt *time.Time
+}
+
+// HookDeliveryWithEvent is a delivery with its event
+type HookDeliveryWithEvent struct {
+	HookDelivery
+	EventType string
+	Content   string
+	RequestID string
+}
+
+// DeliveryQuery filters the deliveries, empty fields are ignored
+type DeliveryQuery struct {
+	Handler string
+	Status  string
+}
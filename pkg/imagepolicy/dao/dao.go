@@ -0,0 +1,72 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/imagepolicy/models"
+)
+
+type DAO interface {
+	// GetByApplicationID returns nil if the application has no policy
+	GetByApplicationID(ctx context.Context, applicationID uint) (*models.ImagePolicy, error)
+	// ListRetained lists the policies with retention enabled
+	ListRetained(ctx context.Context) ([]*models.ImagePolicy, error)
+	Upsert(ctx context.Context, policy *models.ImagePolicy) (*models.ImagePolicy, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) GetByApplicationID(ctx context.Context, applicationID uint) (*models.ImagePolicy, error) {
+	var policies []*models.ImagePolicy
+	if result := d.db.WithContext(ctx).Where("application_id = ?", applicationID).
+		Limit(1).Find(&policies); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ImagePolicyInDB, result.Error.Error())
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	return policies[0], nil
+}
+
+func (d *dao) ListRetained(ctx context.Context) ([]*models.ImagePolicy, error) {
+	var policies []*models.ImagePolicy
+	if result := d.db.WithContext(ctx).Where("retain_count > 0").
+		Order("id").Find(&policies); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ImagePolicyInDB, result.Error.Error())
+	}
+	return policies, nil
+}
+
+func (d *dao) Upsert(ctx context.Context, policy *models.ImagePolicy) (*models.ImagePolicy, error) {
+	if result := d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "application_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"retain_count", "block_critical", "updated_by", "updated_at"}),
+	}).Create(policy); result.Error != nil {
+		return nil, herrors.NewErrInsertFailed(herrors.ImagePolicyInDB, result.Error.Error())
+	}
+	return d.GetByApplicationID(ctx, policy.ApplicationID)
+}
@@ -0,0 +1,60 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/imagepolicy/dao"
+	"github.com/horizoncd/horizon/pkg/imagepolicy/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Manager interface {
+	// GetByApplicationID returns nil if the application has no policy
+	GetByApplicationID(ctx context.Context, applicationID uint) (*models.ImagePolicy, error)
+	// ListRetained lists the policies with retention enabled
+	ListRetained(ctx context.Context) ([]*models.ImagePolicy, error)
+	// Upsert creates or updates the policy of application
+	Upsert(ctx context.Context, policy *models.ImagePolicy) (*models.ImagePolicy, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) GetByApplicationID(ctx context.Context, applicationID uint) (*models.ImagePolicy, error) {
+	const op = "image policy manager: get by application id"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetByApplicationID(ctx, applicationID)
+}
+
+func (m *manager) ListRetained(ctx context.Context) ([]*models.ImagePolicy, error) {
+	const op = "image policy manager: list retained"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListRetained(ctx)
+}
+
+func (m *manager) Upsert(ctx context.Context, policy *models.ImagePolicy) (*models.ImagePolicy, error) {
+	const op = "image policy manager: upsert"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Upsert(ctx, policy)
+}
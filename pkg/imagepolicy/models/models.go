@@ -0,0 +1,32 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// ImagePolicy is the policy of the images of an application's clusters
+type ImagePolicy struct {
+	global.Model
+	ApplicationID uint `gorm:"uniqueIndex"`
+	// RetainCount is the count of the latest tags retained in the repository of each cluster,
+	// besides the images currently deployed, 0 disables the retention
+	RetainCount int
+	// BlockCritical blocks deploying images with critical vulnerabilities
+	BlockCritical bool
+	CreatedBy     uint
+	UpdatedBy     uint
+}
@@ -0,0 +1,113 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cipoller
+
+import (
+	"context"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	cloudeventctl "github.com/horizoncd/horizon/core/controller/cloudevent"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton"
+	"github.com/horizoncd/horizon/pkg/cluster/tekton/factory"
+	"github.com/horizoncd/horizon/pkg/config/cipoller"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const (
+	defaultJobInterval = 30 * time.Second
+	defaultMaxAge      = 24 * time.Hour
+)
+
+// Job polls the builds of the CI backends which do not send cloud events,
+// and collects the finished ones as the cloud events of tekton do
+type Job struct {
+	cipoller.Config
+	mgr           *managerparam.Manager
+	tektonFty     factory.Factory
+	cloudEventCtl cloudeventctl.Controller
+}
+
+func New(config cipoller.Config, mgr *managerparam.Manager, tektonFty factory.Factory,
+	cloudEventCtl cloudeventctl.Controller) *Job {
+	if config.JobInterval <= 0 {
+		config.JobInterval = defaultJobInterval
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = defaultMaxAge
+	}
+	return &Job{
+		Config:        config,
+		mgr:           mgr,
+		tektonFty:     tektonFty,
+		cloudEventCtl: cloudEventCtl,
+	}
+}
+
+func (j *Job) Run(ctx context.Context) {
+	log.Infof(ctx, "Starting polling CI builds every %v", j.JobInterval)
+	defer log.Infof(ctx, "Stopping polling CI builds")
+	ticker := time.NewTicker(j.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx := context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			j.process(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (j *Job) process(ctx context.Context) {
+	pipelineruns, err := j.mgr.PRMgr.PipelineRun.ListUnfinishedBuilds(ctx, time.Now().Add(-j.MaxAge))
+	if err != nil {
+		log.Errorf(ctx, "failed to list unfinished builds, err: %v", err)
+		return
+	}
+	for _, pr := range pipelineruns {
+		if err := j.poll(ctx, pr); err != nil {
+			log.Errorf(ctx, "failed to poll build of pipelinerun %d, err: %+v", pr.ID, err)
+		}
+	}
+}
+
+func (j *Job) poll(ctx context.Context, pr *prmodels.Pipelinerun) error {
+	cluster, err := j.mgr.ClusterMgr.GetByID(ctx, pr.ClusterID)
+	if err != nil {
+		return err
+	}
+	t, err := j.tektonFty.GetTekton(cluster.EnvironmentName, cluster.Template)
+	if err != nil {
+		return err
+	}
+	// the builds of tekton are reported by cloud events
+	poller, ok := t.(tekton.Poller)
+	if !ok {
+		return nil
+	}
+	pipelineRun, err := poller.PollPipelineRun(ctx, pr.CIEventID)
+	if err != nil || pipelineRun == nil {
+		return err
+	}
+	return j.cloudEventCtl.CloudEvent(ctx, &cloudeventctl.WrappedPipelineRun{PipelineRun: pipelineRun})
+}
@@ -72,6 +72,9 @@ func (c *Cleaner) Run(ctx context.Context) {
 		current := time.Now()
 		c.webhookLogClean(ctx, current)
 		c.eventClean(ctx, current)
+		c.stepLogClean(ctx, current)
+		c.hookEventClean(ctx, current)
+		c.accessAuditClean(ctx, current)
 	})
 	if err != nil {
 		panic(err)
@@ -230,3 +233,81 @@ func (c *Cleaner) eventClean(ctx context.Context, current time.Time) {
 		_, _ = c.mgr.EventMgr.DeleteEvents(ctx, needDeleted...)
 	}
 }
+
+func (c *Cleaner) stepLogClean(ctx context.Context, current time.Time) {
+	defer runtime.HandleCrash()
+	if c.StepLogTTL <= 0 {
+		return
+	}
+	log.Debugf(ctx, "start to clean step logs")
+	defer log.Debugf(ctx, "finish to clean step logs")
+	before := current.Add(-c.StepLogTTL)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		deleted, err := c.mgr.PRMgr.StepLog.DeleteBefore(ctx, before, c.Batch)
+		if err != nil {
+			log.Errorf(ctx, "failed to delete step logs: %v", err)
+			return
+		}
+		if deleted == 0 {
+			return
+		}
+		log.Infof(ctx, "deleted %d step logs created before %v", deleted, before)
+	}
+}
+
+func (c *Cleaner) hookEventClean(ctx context.Context, current time.Time) {
+	defer runtime.HandleCrash()
+	if c.HookEventTTL <= 0 {
+		return
+	}
+	log.Debugf(ctx, "start to clean hook events")
+	defer log.Debugf(ctx, "finish to clean hook events")
+	before := current.Add(-c.HookEventTTL)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		deleted, err := c.mgr.HookMgr.DeleteAcknowledgedBefore(ctx, before, c.Batch)
+		if err != nil {
+			log.Errorf(ctx, "failed to delete hook events: %v", err)
+			return
+		}
+		if deleted == 0 {
+			return
+		}
+		log.Infof(ctx, "deleted %d hook events created before %v", deleted, before)
+	}
+}
+
+func (c *Cleaner) accessAuditClean(ctx context.Context, current time.Time) {
+	defer runtime.HandleCrash()
+	if c.AccessAuditTTL <= 0 {
+		return
+	}
+	log.Debugf(ctx, "start to clean access audits")
+	defer log.Debugf(ctx, "finish to clean access audits")
+	before := current.Add(-c.AccessAuditTTL)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		deleted, err := c.mgr.AccessAuditMgr.DeleteBefore(ctx, before, c.Batch)
+		if err != nil {
+			log.Errorf(ctx, "failed to delete access audits: %v", err)
+			return
+		}
+		if deleted == 0 {
+			return
+		}
+		log.Infof(ctx, "deleted %d access audits created before %v", deleted, before)
+	}
+}
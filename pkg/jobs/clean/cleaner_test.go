@@ -22,9 +22,14 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/horizoncd/horizon/lib/orm"
+	"github.com/horizoncd/horizon/lib/q"
+	accessauditmodels "github.com/horizoncd/horizon/pkg/accessaudit/models"
 	"github.com/horizoncd/horizon/pkg/config/clean"
 	"github.com/horizoncd/horizon/pkg/event/models"
+	hookmodels "github.com/horizoncd/horizon/pkg/hook/models"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/server/global"
 	wmodels "github.com/horizoncd/horizon/pkg/webhook/models"
 )
 
@@ -164,3 +169,79 @@ func TestEventClean(t *testing.T) {
 	_, err = mgr.WebhookMgr.GetWebhookLog(ctx, webhookNeedToDelete.ID)
 	assert.NotNil(t, err)
 }
+
+func TestStepLogClean(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&prmodels.StepLog{}))
+
+	ctx := context.TODO()
+	mgr := managerparam.InitManager(db)
+	now := time.Now()
+	stepLogs := []*prmodels.StepLog{{PipelinerunID: 1}, {PipelinerunID: 2}, {PipelinerunID: 3}}
+	for i, stepLog := range stepLogs {
+		stepLog.CreatedAt = now.Add(-time.Duration(i*24) * time.Hour)
+	}
+	assert.Nil(t, mgr.PRMgr.StepLog.Create(ctx, stepLogs))
+
+	cleaner := New(clean.Config{Batch: 1, StepLogTTL: 12 * time.Hour}, mgr)
+	cleaner.stepLogClean(ctx, now)
+	kept, err := mgr.PRMgr.StepLog.ListByPipelinerunID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(kept))
+	total, _, err := mgr.PRMgr.StepLog.Search(ctx, &prmodels.StepLogSearch{}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+}
+
+func TestHookEventClean(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&hookmodels.HookEvent{}, &hookmodels.HookDelivery{}))
+
+	ctx := context.TODO()
+	mgr := managerparam.InitManager(db)
+	now := time.Now()
+	// the old acknowledged event is deleted, the old pending one and the new one are kept
+	for i, acked := range []bool{true, false, true} {
+		event, err := mgr.HookMgr.Create(ctx, &hookmodels.HookEvent{
+			Model: global.Model{CreatedAt: now.Add(-time.Duration(48-i*24) * time.Hour)},
+		}, []string{"a", "b"})
+		assert.Nil(t, err)
+		if acked {
+			assert.Nil(t, db.Model(&hookmodels.HookDelivery{}).Where("event_id = ?", event.ID).
+				Update("status", hookmodels.StatusSucceeded).Error)
+		}
+	}
+
+	cleaner := New(clean.Config{Batch: 1, HookEventTTL: 12 * time.Hour}, mgr)
+	cleaner.hookEventClean(ctx, now)
+	total, deliveries, err := mgr.HookMgr.ListDeliveries(ctx, &hookmodels.DeliveryQuery{}, &q.Query{})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, total)
+	for _, delivery := range deliveries {
+		assert.NotEqual(t, uint(1), delivery.EventID)
+	}
+}
+
+func TestAccessAuditClean(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&accessauditmodels.AccessAudit{}))
+
+	ctx := context.TODO()
+	mgr := managerparam.InitManager(db)
+	now := time.Now()
+	audits := []*accessauditmodels.AccessAudit{{UserID: 1}, {UserID: 2}, {UserID: 3}}
+	for i, audit := range audits {
+		audit.CreatedAt = now.Add(-time.Duration(i*24) * time.Hour)
+	}
+	assert.Nil(t, mgr.AccessAuditMgr.BatchCreate(ctx, audits))
+
+	cleaner := New(clean.Config{Batch: 1, AccessAuditTTL: 12 * time.Hour}, mgr)
+	cleaner.accessAuditClean(ctx, now)
+	kept, total, err := mgr.AccessAuditMgr.List(ctx, &q.Query{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, uint(1), kept[0].UserID)
+}
@@ -0,0 +1,106 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imageretention
+
+import (
+	"context"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/common"
+	imagectl "github.com/horizoncd/horizon/core/controller/image"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/config/imageretention"
+	"github.com/horizoncd/horizon/pkg/imagepolicy/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const defaultJobInterval = 24 * time.Hour
+
+// Job deletes the image tags of applications beyond the retain count of their image policies periodically
+type Job struct {
+	imageretention.Config
+	mgr      *managerparam.Manager
+	imageCtl imagectl.Controller
+}
+
+func New(config imageretention.Config, mgr *managerparam.Manager, imageCtl imagectl.Controller) *Job {
+	if config.JobInterval <= 0 {
+		config.JobInterval = defaultJobInterval
+	}
+	return &Job{
+		Config:   config,
+		mgr:      mgr,
+		imageCtl: imageCtl,
+	}
+}
+
+func (j *Job) Run(ctx context.Context) {
+	log.Infof(ctx, "Starting image retention every %v", j.JobInterval)
+	defer log.Infof(ctx, "Stopping image retention")
+	ticker := time.NewTicker(j.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx := context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			j.process(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (j *Job) process(ctx context.Context) {
+	policies, err := j.mgr.ImagePolicyMgr.ListRetained(ctx)
+	if err != nil {
+		log.Errorf(ctx, "failed to list image policies, err: %v", err)
+		return
+	}
+	for _, policy := range policies {
+		if err := j.retain(ctx, policy); err != nil {
+			log.Errorf(ctx, "failed to retain images of application %d, err: %+v", policy.ApplicationID, err)
+		}
+	}
+}
+
+// retain deletes the expired image tags of application as the last updater of its policy
+func (j *Job) retain(ctx context.Context, policy *models.ImagePolicy) error {
+	updater, err := j.mgr.UserMgr.GetUserByID(ctx, policy.UpdatedBy)
+	if err != nil {
+		return err
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     updater.Name,
+		FullName: updater.FullName,
+		ID:       updater.ID,
+		Email:    updater.Email,
+		Admin:    updater.Admin,
+	})
+
+	deleted, err := j.imageCtl.Retain(ctx, policy.ApplicationID)
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Infof(ctx, "deleted %d image tags of application %d", deleted, policy.ApplicationID)
+	}
+	return nil
+}
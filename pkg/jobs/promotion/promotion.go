@@ -0,0 +1,337 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promotion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	clustermodels "github.com/horizoncd/horizon/pkg/cluster/models"
+	"github.com/horizoncd/horizon/pkg/config/promotion"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	promotionanalyzer "github.com/horizoncd/horizon/pkg/promotion"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/workload/rollout"
+)
+
+const (
+	DecisionPromote  = "promote"
+	DecisionRollback = "rollback"
+	DecisionAbort    = "abort"
+
+	actionPromote = "promote"
+	actionAbort   = "abort"
+
+	defaultJobInterval     = 30 * time.Second
+	defaultAnalysisTimeout = 10 * time.Minute
+	defaultRolloutTimeout  = time.Hour
+)
+
+// Decision is recorded as the extra of event when the analyzer promotes or rollbacks a cluster
+type Decision struct {
+	Decision      string                           `json:"decision"`
+	PipelinerunID uint                             `json:"pipelinerunID"`
+	RollbackTo    uint                             `json:"rollbackTo,omitempty"`
+	StepIndex     int                              `json:"stepIndex"`
+	StepTotal     int                              `json:"stepTotal"`
+	Measurements  []*promotionanalyzer.Measurement `json:"measurements"`
+}
+
+// stepState tracks the step of a cluster under analysis
+type stepState struct {
+	pipelinerunID uint
+	index         int
+	since         time.Time
+	decided       bool
+}
+
+// Promoter analyzes the metrics of clusters being rolled out step by step,
+// promotes them when they are healthy and rollbacks them when they are not.
+type Promoter struct {
+	promotion.Config
+	analyzer   promotionanalyzer.Analyzer
+	mgr        *managerparam.Manager
+	clusterCtl clusterctl.Controller
+	eventSvc   eventservice.Service
+	steps      map[uint]*stepState
+}
+
+func New(config promotion.Config, mgr *managerparam.Manager,
+	clusterCtl clusterctl.Controller, eventSvc eventservice.Service) (*Promoter, error) {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 50
+	}
+	if config.JobInterval <= 0 {
+		config.JobInterval = defaultJobInterval
+	}
+	if config.AnalysisTimeout <= 0 {
+		config.AnalysisTimeout = defaultAnalysisTimeout
+	}
+	if config.RolloutTimeout <= 0 {
+		config.RolloutTimeout = defaultRolloutTimeout
+	}
+	analyzer, err := promotionanalyzer.NewAnalyzer(config.Queries)
+	if err != nil {
+		return nil, err
+	}
+	return &Promoter{
+		Config:     config,
+		analyzer:   analyzer,
+		mgr:        mgr,
+		clusterCtl: clusterCtl,
+		eventSvc:   eventSvc,
+		steps:      make(map[uint]*stepState),
+	}, nil
+}
+
+func (p *Promoter) Run(ctx context.Context) {
+	if !p.Enabled {
+		return
+	}
+	// verify account
+	user, err := p.mgr.UserMgr.GetUserByID(ctx, p.AccountID)
+	if err != nil {
+		log.Errorf(ctx, "failed to verify operator, err: %v", err.Error())
+		panic(err)
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     user.Name,
+		FullName: user.FullName,
+		ID:       user.ID,
+		Email:    user.Email,
+		Admin:    user.Admin,
+	})
+
+	log.Infof(ctx, "Starting analyzing rollouts of clusters every %v", p.JobInterval)
+	defer log.Infof(ctx, "Stopping analyzing rollouts of clusters")
+	ticker := time.NewTicker(p.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx = context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			p.process(ctx, time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (p *Promoter) process(ctx context.Context, now time.Time) {
+	query := &q.Query{
+		PageNumber: common.DefaultPageNumber,
+		PageSize:   p.BatchSize,
+		Keywords:   q.KeyWords{},
+	}
+	if len(p.SupportedEnvs) > 0 {
+		query.Keywords[common.ClusterQueryEnvironment] = p.SupportedEnvs
+	}
+	watched := make(map[uint]struct{})
+	for {
+		_, clusters, err := p.mgr.ClusterMgr.List(ctx, query)
+		if err != nil {
+			log.Errorf(ctx, "failed to list clusters, err: %v", err)
+			return
+		}
+		for _, cluster := range clusters {
+			watched[cluster.ID] = struct{}{}
+			if err := p.analyzeCluster(ctx, cluster.Cluster, now); err != nil {
+				log.Errorf(ctx, "failed to analyze rollout of cluster %s, err: %+v", cluster.Name, err)
+			}
+		}
+		if len(clusters) < query.PageSize {
+			break
+		}
+		query.PageNumber++
+	}
+	// forget the clusters which don't exist anymore
+	for clusterID := range p.steps {
+		if _, ok := watched[clusterID]; !ok {
+			delete(p.steps, clusterID)
+		}
+	}
+}
+
+func (p *Promoter) analyzeCluster(ctx context.Context, cluster *clustermodels.Cluster, now time.Time) error {
+	// 1. only the rollout of a deployment finished recently is analyzed,
+	// and the one started by a rollback is left to human.
+	pr, err := p.mgr.PRMgr.PipelineRun.GetLatestByClusterIDAndActions(ctx, cluster.ID,
+		prmodels.ActionBuildDeploy, prmodels.ActionDeploy, prmodels.ActionRollback)
+	if err != nil {
+		return err
+	}
+	if pr == nil || pr.Action == prmodels.ActionRollback || pr.Status != string(prmodels.StatusOK) ||
+		pr.FinishedAt == nil || now.Sub(*pr.FinishedAt) > p.RolloutTimeout {
+		delete(p.steps, cluster.ID)
+		return nil
+	}
+
+	// 2. only a rollout paused at a step is analyzed
+	step, err := p.clusterCtl.GetStep(ctx, cluster.ID)
+	if err != nil {
+		return err
+	}
+	if step.Total == 0 || step.Index >= step.Total || step.ManualPaused {
+		delete(p.steps, cluster.ID)
+		return nil
+	}
+
+	// 3. a step is analyzed after it has been running for a while, and decided only once
+	state, ok := p.steps[cluster.ID]
+	if !ok || state.pipelinerunID != pr.ID || state.index != step.Index {
+		state = &stepState{pipelinerunID: pr.ID, index: step.Index, since: now}
+		p.steps[cluster.ID] = state
+	}
+	if state.decided || now.Sub(state.since) < p.AnalysisDelay {
+		return nil
+	}
+
+	region, err := p.mgr.RegionMgr.GetRegionByName(ctx, cluster.RegionName)
+	if err != nil {
+		return err
+	}
+	result, err := p.analyzer.Analyze(ctx, region.PrometheusURL, &promotionanalyzer.QueryParams{
+		Cluster:     cluster.Name,
+		Environment: cluster.EnvironmentName,
+		Region:      cluster.RegionName,
+	})
+	decision := &Decision{
+		PipelinerunID: pr.ID,
+		StepIndex:     step.Index,
+		StepTotal:     step.Total,
+	}
+	if err != nil || result.Inconclusive {
+		// the step is analyzed again on the next tick, until it's inconclusive for too long
+		if now.Sub(state.since) < p.AnalysisDelay+p.AnalysisTimeout {
+			return err
+		}
+		if result != nil {
+			decision.Measurements = result.Measurements
+		}
+		decision.Decision = DecisionAbort
+		abortErr := p.clusterCtl.ExecuteAction(ctx, cluster.ID, actionAbort, rollout.GVRRollout)
+		state.decided = true
+		p.record(ctx, cluster, decision, abortErr)
+		if abortErr != nil {
+			return abortErr
+		}
+		return err
+	}
+
+	decision.Measurements = result.Measurements
+	if result.Healthy {
+		// the rollout goes on by itself
+		if step.AutoPromote {
+			return nil
+		}
+		decision.Decision = DecisionPromote
+		err = p.clusterCtl.ExecuteAction(ctx, cluster.ID, actionPromote, rollout.GVRRollout)
+	} else {
+		decision.Decision = DecisionRollback
+		err = p.rollback(ctx, cluster.ID, decision)
+	}
+	state.decided = true
+	p.record(ctx, cluster, decision, err)
+	return err
+}
+
+// rollback rollbacks the cluster to the last successful pipelinerun before the current one
+func (p *Promoter) rollback(ctx context.Context, clusterID uint, decision *Decision) error {
+	_, prs, err := p.mgr.PRMgr.PipelineRun.GetByClusterID(ctx, clusterID, true, q.Query{
+		PageNumber: common.DefaultPageNumber,
+		PageSize:   1,
+	})
+	if err != nil {
+		return err
+	}
+	if len(prs) == 0 || prs[0].ID >= decision.PipelinerunID {
+		return fmt.Errorf("no pipelinerun to rollback to before pipelinerun %d", decision.PipelinerunID)
+	}
+	decision.RollbackTo = prs[0].ID
+	_, err = p.clusterCtl.Rollback(ctx, clusterID, &clusterctl.RollbackRequest{
+		PipelinerunID: prs[0].ID,
+	})
+	return err
+}
+
+// record records the decision as a message of pipelinerun and an event of cluster
+func (p *Promoter) record(ctx context.Context, cluster *clustermodels.Cluster, decision *Decision, err error) {
+	currentUser, userErr := common.UserFromContext(ctx)
+	if userErr != nil {
+		log.Errorf(ctx, "failed to get current user, err: %v", userErr)
+		return
+	}
+	if _, msgErr := p.mgr.PRMgr.Message.Create(ctx, &prmodels.PRMessage{
+		PipelineRunID: decision.PipelinerunID,
+		Content:       messageOf(decision, err),
+		CreatedBy:     currentUser.GetID(),
+		UpdatedBy:     currentUser.GetID(),
+	}); msgErr != nil {
+		log.Errorf(ctx, "failed to create pr message, err: %v", msgErr)
+	}
+
+	bts, jsonErr := json.Marshal(decision)
+	if jsonErr != nil {
+		log.Warningf(ctx, "failed to marshal event extra, err: %s", jsonErr.Error())
+	}
+	extra := string(bts)
+	p.eventSvc.CreateEventIgnoreError(ctx, common.ResourceCluster, cluster.ID,
+		eventmodels.ClusterAnalyzed, &extra)
+}
+
+func messageOf(decision *Decision, err error) string {
+	var builder strings.Builder
+	healthy := "healthy"
+	switch decision.Decision {
+	case DecisionRollback:
+		healthy = "unhealthy"
+	case DecisionAbort:
+		healthy = "inconclusive"
+	}
+	builder.WriteString(fmt.Sprintf("Step %d/%d is %s, ", decision.StepIndex+1, decision.StepTotal, healthy))
+	switch {
+	case err != nil:
+		builder.WriteString(fmt.Sprintf("failed to %s automatically: %v", decision.Decision, err))
+	case decision.Decision == DecisionRollback:
+		builder.WriteString(fmt.Sprintf("rollbacked to pipelinerun %d automatically", decision.RollbackTo))
+	case decision.Decision == DecisionAbort:
+		builder.WriteString("aborted automatically since no metrics were available")
+	default:
+		builder.WriteString("promoted automatically")
+	}
+	for _, m := range decision.Measurements {
+		if m.NoData {
+			builder.WriteString(fmt.Sprintf("\n%s: no data (threshold %g)", m.Name, m.Threshold))
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("\n%s: %g (threshold %g)", m.Name, m.Value, m.Threshold))
+	}
+	return builder.String()
+}
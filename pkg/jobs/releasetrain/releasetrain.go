@@ -0,0 +1,347 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasetrain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	prctl "github.com/horizoncd/horizon/core/controller/pipelinerun"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/lib/q"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	codemodels "github.com/horizoncd/horizon/pkg/cluster/code"
+	"github.com/horizoncd/horizon/pkg/config/releasetrain"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	eventservice "github.com/horizoncd/horizon/pkg/event/service"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/releasetrain/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const defaultJobInterval = 30 * time.Second
+
+// Driver drives the running release trains stage by stage, it creates and executes
+// the pipelineruns of a stage once its gates are passed, and moves on when they all succeed.
+type Driver struct {
+	releasetrain.Config
+	mgr        *managerparam.Manager
+	eventSvc   eventservice.Service
+	clusterCtl clusterctl.Controller
+	prCtl      prctl.Controller
+}
+
+func New(config releasetrain.Config, mgr *managerparam.Manager,
+	clusterCtl clusterctl.Controller, prCtl prctl.Controller) *Driver {
+	if config.JobInterval <= 0 {
+		config.JobInterval = defaultJobInterval
+	}
+	return &Driver{
+		Config:     config,
+		mgr:        mgr,
+		eventSvc:   eventservice.New(mgr),
+		clusterCtl: clusterCtl,
+		prCtl:      prCtl,
+	}
+}
+
+func (d *Driver) Run(ctx context.Context) {
+	log.Infof(ctx, "Starting driving release trains every %v", d.JobInterval)
+	defer log.Infof(ctx, "Stopping driving release trains")
+	ticker := time.NewTicker(d.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx := context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			d.process(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *Driver) process(ctx context.Context) {
+	runs, err := d.mgr.ReleaseTrainMgr.ListRunsByStatus(ctx, models.RunStatusRunning)
+	if err != nil {
+		log.Errorf(ctx, "failed to list running release trains, err: %v", err)
+		return
+	}
+	for _, run := range runs {
+		if err := d.advance(ctx, run); err != nil {
+			log.Errorf(ctx, "failed to drive release train run %d, err: %+v", run.ID, err)
+		}
+	}
+}
+
+// advance moves the current stage of run forward, the pipelineruns are created and executed as the creator of run
+func (d *Driver) advance(ctx context.Context, run *models.Run) error {
+	creator, err := d.mgr.UserMgr.GetUserByID(ctx, run.CreatedBy)
+	if err != nil {
+		return err
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     creator.Name,
+		FullName: creator.FullName,
+		ID:       creator.ID,
+		Email:    creator.Email,
+		Admin:    creator.Admin,
+	})
+
+	stageRuns, err := d.mgr.ReleaseTrainMgr.ListStageRuns(ctx, run.ID)
+	if err != nil {
+		return err
+	}
+	if run.CurrentStage >= len(stageRuns) {
+		return d.finish(ctx, run, models.RunStatusFailed, "current stage not found")
+	}
+	stageRun := stageRuns[run.CurrentStage]
+
+	switch stageRun.Status {
+	case models.StageStatusPending, models.StageStatusWaitingApproval, models.StageStatusSoaking:
+		return d.start(ctx, run, stageRuns)
+	case models.StageStatusWaitingChecks, models.StageStatusRunning:
+		return d.watch(ctx, run, stageRun, len(stageRuns))
+	default:
+		return nil
+	}
+}
+
+// start creates the pipelineruns of the current stage once its gates are passed
+func (d *Driver) start(ctx context.Context, run *models.Run, stageRuns []*models.StageRun) error {
+	stageRun := stageRuns[run.CurrentStage]
+	var previous *models.StageRun
+	if run.CurrentStage > 0 {
+		previous = stageRuns[run.CurrentStage-1]
+	}
+	var status string
+	switch {
+	case stageRun.RequireApproval && stageRun.ApprovedBy == 0:
+		status = models.StageStatusWaitingApproval
+	case previous != nil && previous.FinishedAt != nil &&
+		time.Since(*previous.FinishedAt) < time.Duration(stageRun.SoakTime)*time.Second:
+		status = models.StageStatusSoaking
+	}
+	if status != "" {
+		if status == stageRun.Status {
+			return nil
+		}
+		stageRun.Status = status
+		return d.mgr.ReleaseTrainMgr.UpdateStageRun(ctx, stageRun)
+	}
+
+	clusterIDs, err := d.listClusters(ctx, run, stageRun)
+	if err != nil {
+		return err
+	}
+	if len(clusterIDs) == 0 {
+		return d.failStage(ctx, run, stageRun, "no clusters to deploy")
+	}
+	// the image built by the first stage is promoted to the later stages instead of building again
+	var built *prmodels.Pipelinerun
+	if run.Action == prmodels.ActionBuildDeploy && previous != nil {
+		built, err = d.getBuiltPipelinerun(ctx, stageRuns[0])
+		if err != nil {
+			return err
+		}
+		if built == nil {
+			return d.failStage(ctx, run, stageRun, fmt.Sprintf("no image is built by stage %s", stageRuns[0].Name))
+		}
+	}
+	request := d.pipelinerunRequest(run, stageRun, built)
+
+	now := time.Now()
+	stageRun.Status = models.StageStatusRunning
+	stageRun.StartedAt = &now
+	pipelinerunIDs := make([]uint, 0, len(clusterIDs))
+	for _, clusterID := range clusterIDs {
+		pr, err := d.clusterCtl.CreatePipelineRun(ctx, clusterID, request)
+		if err != nil {
+			stageRun.PipelinerunIDs = models.FormatIDs(pipelinerunIDs)
+			return d.failStage(ctx, run, stageRun,
+				fmt.Sprintf("failed to create pipelinerun of cluster %d: %v", clusterID, err))
+		}
+		pipelinerunIDs = append(pipelinerunIDs, pr.ID)
+	}
+	stageRun.PipelinerunIDs = models.FormatIDs(pipelinerunIDs)
+	return d.mgr.ReleaseTrainMgr.UpdateStageRun(ctx, stageRun)
+}
+
+// watch executes the pipelineruns of stage when their checks pass, and completes the stage when they all succeed
+func (d *Driver) watch(ctx context.Context, run *models.Run, stageRun *models.StageRun, total int) error {
+	waitingChecks, succeeded := false, 0
+	pipelinerunIDs := models.ParseIDs(stageRun.PipelinerunIDs)
+	for _, pipelinerunID := range pipelinerunIDs {
+		pr, err := d.mgr.PRMgr.PipelineRun.GetByID(ctx, pipelinerunID)
+		if err != nil {
+			return err
+		}
+		switch prmodels.PipelineStatus(pr.Status) {
+		case prmodels.StatusPending:
+			waitingChecks = true
+		case prmodels.StatusReady:
+			if err := d.prCtl.Execute(ctx, pr.ID, false); err != nil {
+				// the pipelinerun held by deploy windows turns to pending and is executed later
+				pr, getErr := d.mgr.PRMgr.PipelineRun.GetByID(ctx, pipelinerunID)
+				if getErr != nil {
+					return getErr
+				}
+				if pr.Status == string(prmodels.StatusPending) {
+					waitingChecks = true
+					continue
+				}
+				return d.failStage(ctx, run, stageRun,
+					fmt.Sprintf("failed to execute pipelinerun %d: %v", pipelinerunID, err))
+			}
+		case prmodels.StatusOK:
+			succeeded++
+		case prmodels.StatusFailed, prmodels.StatusCancelled, prmodels.StatusUnknown:
+			return d.failStage(ctx, run, stageRun, fmt.Sprintf("pipelinerun %d is %s", pr.ID, pr.Status))
+		}
+	}
+
+	if succeeded == len(pipelinerunIDs) {
+		now := time.Now()
+		stageRun.Status = models.StageStatusSucceeded
+		stageRun.FinishedAt = &now
+		if err := d.mgr.ReleaseTrainMgr.UpdateStageRun(ctx, stageRun); err != nil {
+			return err
+		}
+		if run.CurrentStage == total-1 {
+			return d.finish(ctx, run, models.RunStatusSucceeded, "")
+		}
+		run.CurrentStage++
+		return d.mgr.ReleaseTrainMgr.UpdateRun(ctx, run)
+	}
+
+	status := models.StageStatusRunning
+	if waitingChecks {
+		status = models.StageStatusWaitingChecks
+	}
+	if status == stageRun.Status {
+		return nil
+	}
+	stageRun.Status = status
+	return d.mgr.ReleaseTrainMgr.UpdateStageRun(ctx, stageRun)
+}
+
+// listClusters returns the clusters of stage, they are the clusters of application in the environment if not specified
+func (d *Driver) listClusters(ctx context.Context, run *models.Run, stageRun *models.StageRun) ([]uint, error) {
+	if stageRun.Clusters != "" {
+		return models.ParseIDs(stageRun.Clusters), nil
+	}
+	_, clusters, err := d.mgr.ClusterMgr.List(ctx, &q.Query{
+		Keywords: q.KeyWords{
+			common.ParamApplicationID:      run.ApplicationID,
+			common.ClusterQueryEnvironment: stageRun.Environment,
+		},
+		WithoutPagination: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	clusterIDs := make([]uint, 0, len(clusters))
+	for _, cluster := range clusters {
+		clusterIDs = append(clusterIDs, cluster.ID)
+	}
+	return clusterIDs, nil
+}
+
+// getBuiltPipelinerun returns a succeeded pipelinerun of stage whose image has been built and tested,
+// nil is returned if there is none
+func (d *Driver) getBuiltPipelinerun(ctx context.Context, stageRun *models.StageRun) (*prmodels.Pipelinerun, error) {
+	for _, pipelinerunID := range models.ParseIDs(stageRun.PipelinerunIDs) {
+		pr, err := d.mgr.PRMgr.PipelineRun.GetByID(ctx, pipelinerunID)
+		if err != nil {
+			return nil, err
+		}
+		if pr.Status == string(prmodels.StatusOK) && pr.ImageURL != "" {
+			return pr, nil
+		}
+	}
+	return nil, nil
+}
+
+// pipelinerunRequest returns the request to create pipelineruns of stage,
+// the image of built is deployed if it's not nil
+func (d *Driver) pipelinerunRequest(run *models.Run, stageRun *models.StageRun,
+	built *prmodels.Pipelinerun) *clusterctl.CreatePipelineRunRequest {
+	title := run.Title
+	if title == "" {
+		title = fmt.Sprintf("release train run %d", run.ID)
+	}
+	request := &clusterctl.CreatePipelineRunRequest{
+		Title:       fmt.Sprintf("%s: %s", title, stageRun.Name),
+		Description: run.Description,
+		Action:      run.Action,
+		ImageTag:    run.ImageTag,
+	}
+	if built != nil {
+		request.Action = prmodels.ActionDeploy
+		request.Image = built.ImageURL
+		request.Git = &clusterctl.BuildDeployRequestGit{Commit: built.GitCommit}
+		return request
+	}
+	switch run.GitRefType {
+	case codemodels.GitRefTypeCommit:
+		request.Git = &clusterctl.BuildDeployRequestGit{Commit: run.GitRef}
+	case codemodels.GitRefTypeTag:
+		request.Git = &clusterctl.BuildDeployRequestGit{Tag: run.GitRef}
+	case codemodels.GitRefTypeBranch:
+		request.Git = &clusterctl.BuildDeployRequestGit{Branch: run.GitRef}
+	}
+	return request
+}
+
+// failStage fails the stage and the run, the pipelineruns of stage not executed yet are cancelled
+func (d *Driver) failStage(ctx context.Context, run *models.Run, stageRun *models.StageRun, message string) error {
+	for _, pipelinerunID := range models.ParseIDs(stageRun.PipelinerunIDs) {
+		pr, err := d.mgr.PRMgr.PipelineRun.GetByID(ctx, pipelinerunID)
+		if err != nil {
+			return err
+		}
+		if pr.Status != string(prmodels.StatusPending) && pr.Status != string(prmodels.StatusReady) {
+			continue
+		}
+		if err := d.mgr.PRMgr.PipelineRun.UpdateStatusByID(ctx, pr.ID, prmodels.StatusCancelled); err != nil {
+			return err
+		}
+		d.eventSvc.CreateEventIgnoreError(ctx, common.ResourcePipelinerun, pr.ID,
+			eventmodels.PipelinerunCancelled, nil)
+	}
+
+	now := time.Now()
+	stageRun.Status = models.StageStatusFailed
+	stageRun.FinishedAt = &now
+	stageRun.Message = message
+	if err := d.mgr.ReleaseTrainMgr.UpdateStageRun(ctx, stageRun); err != nil {
+		return err
+	}
+	return d.finish(ctx, run, models.RunStatusFailed, fmt.Sprintf("stage %s failed", stageRun.Name))
+}
+
+func (d *Driver) finish(ctx context.Context, run *models.Run, status, message string) error {
+	run.Status = status
+	run.Message = message
+	return d.mgr.ReleaseTrainMgr.UpdateRun(ctx, run)
+}
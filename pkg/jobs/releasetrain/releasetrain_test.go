@@ -0,0 +1,190 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasetrain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	prctl "github.com/horizoncd/horizon/core/controller/pipelinerun"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/config/releasetrain"
+	eventmodels "github.com/horizoncd/horizon/pkg/event/models"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	prmodels "github.com/horizoncd/horizon/pkg/pr/models"
+	"github.com/horizoncd/horizon/pkg/releasetrain/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+const _brokenClusterID = 99
+
+// fakeClusterCtl creates ready pipelineruns as if the clusters have no checks
+type fakeClusterCtl struct {
+	clusterctl.Controller
+	mgr *managerparam.Manager
+}
+
+func (f *fakeClusterCtl) CreatePipelineRun(ctx context.Context, clusterID uint,
+	r *clusterctl.CreatePipelineRunRequest) (*prmodels.PipelineBasic, error) {
+	if clusterID == _brokenClusterID {
+		return nil, errors.New("broken cluster")
+	}
+	imageURL := r.Image
+	if r.Action == prmodels.ActionBuildDeploy {
+		imageURL = fmt.Sprintf("app-%d:%s", clusterID, r.Git.Tag)
+	}
+	pr, err := f.mgr.PRMgr.PipelineRun.Create(ctx, &prmodels.Pipelinerun{
+		ClusterID: clusterID,
+		Action:    r.Action,
+		Title:     r.Title,
+		GitRef:    r.Git.Tag,
+		GitCommit: r.Git.Commit,
+		ImageURL:  imageURL,
+		Status:    string(prmodels.StatusReady),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &prmodels.PipelineBasic{ID: pr.ID, Title: pr.Title}, nil
+}
+
+type fakePRCtl struct {
+	prctl.Controller
+	mgr *managerparam.Manager
+}
+
+func (f *fakePRCtl) Execute(ctx context.Context, pipelinerunID uint, force bool) error {
+	return f.mgr.PRMgr.PipelineRun.UpdateStatusByID(ctx, pipelinerunID, prmodels.StatusRunning)
+}
+
+func TestDriver(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&usermodels.User{}, &prmodels.Pipelinerun{}, &eventmodels.Event{},
+		&models.Run{}, &models.StageRun{}))
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	mgr := managerparam.InitManager(db)
+	_, err = mgr.UserMgr.Create(ctx, &usermodels.User{Name: "Tony"})
+	assert.Nil(t, err)
+	driver := New(releasetrain.Config{}, mgr, &fakeClusterCtl{mgr: mgr}, &fakePRCtl{mgr: mgr})
+
+	startRun := func(firstClusters string) *models.Run {
+		run, err := mgr.ReleaseTrainMgr.CreateRun(ctx, &models.Run{
+			ReleaseTrainID: 1,
+			ApplicationID:  1,
+			Title:          "v1",
+			Action:         prmodels.ActionBuildDeploy,
+			GitRefType:     "tag",
+			GitRef:         "v1",
+			Status:         models.RunStatusRunning,
+			CreatedBy:      1,
+		}, []*models.StageRun{
+			{Sequence: 0, StageSpec: models.StageSpec{Name: "test", Clusters: firstClusters},
+				Status: models.StageStatusPending},
+			{Sequence: 1, StageSpec: models.StageSpec{Name: "online", Clusters: "3", RequireApproval: true},
+				Status: models.StageStatusPending},
+		})
+		assert.Nil(t, err)
+		return run
+	}
+	getRun := func(id uint) (*models.Run, []*models.StageRun) {
+		run, err := mgr.ReleaseTrainMgr.GetRun(ctx, id)
+		assert.Nil(t, err)
+		stageRuns, err := mgr.ReleaseTrainMgr.ListStageRuns(ctx, id)
+		assert.Nil(t, err)
+		return run, stageRuns
+	}
+	setStatus := func(stageRun *models.StageRun, status prmodels.PipelineStatus) {
+		for _, id := range models.ParseIDs(stageRun.PipelinerunIDs) {
+			assert.Nil(t, mgr.PRMgr.PipelineRun.UpdateStatusByID(ctx, id, status))
+		}
+	}
+
+	run := startRun("1,2")
+	// the pipelineruns of first stage are created and executed
+	driver.process(ctx)
+	_, stageRuns := getRun(run.ID)
+	assert.Equal(t, models.StageStatusRunning, stageRuns[0].Status)
+	assert.Equal(t, 2, len(models.ParseIDs(stageRuns[0].PipelinerunIDs)))
+	assert.NotNil(t, stageRuns[0].StartedAt)
+	driver.process(ctx)
+	for _, id := range models.ParseIDs(stageRuns[0].PipelinerunIDs) {
+		pr, err := mgr.PRMgr.PipelineRun.GetByID(ctx, id)
+		assert.Nil(t, err)
+		assert.Equal(t, string(prmodels.StatusRunning), pr.Status)
+		assert.Equal(t, "v1: test", pr.Title)
+	}
+
+	// the second stage waits for approval after the first one succeeded
+	setStatus(stageRuns[0], prmodels.StatusOK)
+	driver.process(ctx)
+	run, stageRuns = getRun(run.ID)
+	assert.Equal(t, 1, run.CurrentStage)
+	assert.Equal(t, models.StageStatusSucceeded, stageRuns[0].Status)
+	driver.process(ctx)
+	_, stageRuns = getRun(run.ID)
+	assert.Equal(t, models.StageStatusWaitingApproval, stageRuns[1].Status)
+
+	stageRuns[1].ApprovedBy = 1
+	assert.Nil(t, mgr.ReleaseTrainMgr.UpdateStageRun(ctx, stageRuns[1]))
+	driver.process(ctx)
+	_, stageRuns = getRun(run.ID)
+	assert.Equal(t, models.StageStatusRunning, stageRuns[1].Status)
+
+	// the image built by the first stage is deployed instead of building again
+	pr, err := mgr.PRMgr.PipelineRun.GetByID(ctx, models.ParseIDs(stageRuns[1].PipelinerunIDs)[0])
+	assert.Nil(t, err)
+	assert.Equal(t, prmodels.ActionDeploy, pr.Action)
+	assert.Equal(t, "app-1:v1", pr.ImageURL)
+
+	setStatus(stageRuns[1], prmodels.StatusOK)
+	driver.process(ctx)
+	run, stageRuns = getRun(run.ID)
+	assert.Equal(t, models.RunStatusSucceeded, run.Status)
+	assert.Equal(t, models.StageStatusSucceeded, stageRuns[1].Status)
+
+	// the run fails once a pipelinerun fails
+	run = startRun("1,2")
+	driver.process(ctx)
+	_, stageRuns = getRun(run.ID)
+	setStatus(stageRuns[0], prmodels.StatusFailed)
+	driver.process(ctx)
+	run, stageRuns = getRun(run.ID)
+	assert.Equal(t, models.RunStatusFailed, run.Status)
+	assert.Equal(t, models.StageStatusFailed, stageRuns[0].Status)
+	assert.Equal(t, models.StageStatusPending, stageRuns[1].Status)
+
+	// the pipelineruns created are cancelled if the stage fails to start
+	run = startRun(fmt.Sprintf("1,%d", _brokenClusterID))
+	driver.process(ctx)
+	run, stageRuns = getRun(run.ID)
+	assert.Equal(t, models.RunStatusFailed, run.Status)
+	assert.Equal(t, models.StageStatusFailed, stageRuns[0].Status)
+	pipelinerunIDs := models.ParseIDs(stageRuns[0].PipelinerunIDs)
+	assert.Equal(t, 1, len(pipelinerunIDs))
+	pr, err = mgr.PRMgr.PipelineRun.GetByID(ctx, pipelinerunIDs[0])
+	assert.Nil(t, err)
+	assert.Equal(t, string(prmodels.StatusCancelled), pr.Status)
+}
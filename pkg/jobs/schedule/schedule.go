@@ -0,0 +1,56 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"context"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	prctl "github.com/horizoncd/horizon/core/controller/pipelinerun"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	"github.com/horizoncd/horizon/pkg/config/schedule"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const defaultJobInterval = time.Minute
+
+// Run executes the scheduled pipelineruns and the ones held by deploy windows when their time comes
+func Run(ctx context.Context, jobConfig schedule.Config, prCtr prctl.Controller) {
+	interval := jobConfig.JobInterval
+	if interval <= 0 {
+		interval = defaultJobInterval
+	}
+
+	log.Infof(ctx, "Starting executing scheduled pipelineruns every %v", interval)
+	defer log.Infof(ctx, "Stopping executing scheduled pipelineruns")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx := context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			log.Infof(ctx, "schedule job starts to execute, rid: %v", rid)
+			if err := prCtr.ExecuteScheduled(ctx); err != nil {
+				log.Errorf(ctx, "failed to execute scheduled pipelineruns, err: %+v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatemigration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	"github.com/horizoncd/horizon/core/middleware/requestid"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/config/templatemigration"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/templatemigration/models"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const defaultJobInterval = 30 * time.Second
+
+// Migrator upgrades a batch of clusters for each running migration every interval, the migration
+// is paused once some clusters of the batch fail, and it is finished when no clusters are pending.
+type Migrator struct {
+	templatemigration.Config
+	mgr        *managerparam.Manager
+	clusterCtl clusterctl.Controller
+}
+
+func New(config templatemigration.Config, mgr *managerparam.Manager,
+	clusterCtl clusterctl.Controller) *Migrator {
+	if config.JobInterval <= 0 {
+		config.JobInterval = defaultJobInterval
+	}
+	return &Migrator{
+		Config:     config,
+		mgr:        mgr,
+		clusterCtl: clusterCtl,
+	}
+}
+
+func (m *Migrator) Run(ctx context.Context) {
+	log.Infof(ctx, "Starting migrating templates every %v", m.JobInterval)
+	defer log.Infof(ctx, "Stopping migrating templates")
+	ticker := time.NewTicker(m.JobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rid := uuid.NewV4().String()
+			// nolint
+			ctx := context.WithValue(ctx, requestid.HeaderXRequestID, rid)
+			m.process(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *Migrator) process(ctx context.Context) {
+	migrations, err := m.mgr.TemplateMigrationMgr.ListByStatus(ctx, models.StatusRunning)
+	if err != nil {
+		log.Errorf(ctx, "failed to list running template migrations, err: %v", err)
+		return
+	}
+	for _, migration := range migrations {
+		if err := m.migrate(ctx, migration); err != nil {
+			log.Errorf(ctx, "failed to migrate template by migration %d, err: %+v", migration.ID, err)
+		}
+	}
+}
+
+// migrate upgrades the next batch of clusters, the clusters are upgraded as the creator of migration
+func (m *Migrator) migrate(ctx context.Context, migration *models.Migration) error {
+	creator, err := m.mgr.UserMgr.GetUserByID(ctx, migration.CreatedBy)
+	if err != nil {
+		return err
+	}
+	ctx = common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:     creator.Name,
+		FullName: creator.FullName,
+		ID:       creator.ID,
+		Email:    creator.Email,
+		Admin:    creator.Admin,
+	})
+
+	clusters, err := m.mgr.TemplateMigrationMgr.ListClusters(ctx, migration.ID)
+	if err != nil {
+		return err
+	}
+	pending, failed := make([]*models.MigrationCluster, 0), 0
+	for _, cluster := range clusters {
+		switch cluster.Status {
+		case models.ClusterStatusPending:
+			pending = append(pending, cluster)
+		case models.ClusterStatusFailed:
+			failed++
+		}
+	}
+	if len(pending) == 0 {
+		if failed > 0 {
+			return m.setStatus(ctx, migration, models.StatusFailed,
+				fmt.Sprintf("%d of %d clusters failed", failed, len(clusters)))
+		}
+		return m.setStatus(ctx, migration, models.StatusSucceeded, "")
+	}
+
+	batch := pending
+	if migration.BatchSize > 0 && uint(len(batch)) > migration.BatchSize {
+		batch = batch[:migration.BatchSize]
+	}
+	failed = 0
+	for _, cluster := range batch {
+		// stop if the migration is paused or aborted meanwhile
+		current, err := m.mgr.TemplateMigrationMgr.Get(ctx, migration.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != models.StatusRunning {
+			return nil
+		}
+
+		cluster.Status = models.ClusterStatusSucceeded
+		if err := m.clusterCtl.UpgradeTemplateRelease(ctx, cluster.ClusterID,
+			migration.TargetRelease, false); err != nil {
+			cluster.Status = models.ClusterStatusFailed
+			cluster.Message = err.Error()
+			failed++
+		}
+		now := time.Now()
+		cluster.FinishedAt = &now
+		if err := m.mgr.TemplateMigrationMgr.UpdateCluster(ctx, cluster); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return m.setStatus(ctx, migration, models.StatusPaused,
+			fmt.Sprintf("%d clusters failed in the batch, resume to continue", failed))
+	}
+	return nil
+}
+
+// setStatus changes the status of running migration, the migration paused or aborted meanwhile is left as it is
+func (m *Migrator) setStatus(ctx context.Context, migration *models.Migration, status, message string) error {
+	migration.Status = status
+	migration.Message = message
+	updated, err := m.mgr.TemplateMigrationMgr.Update(ctx, migration, models.StatusRunning)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		log.Infof(ctx, "migration %d is not running anymore, skip changing its status to %s", migration.ID, status)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templatemigration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/core/common"
+	clusterctl "github.com/horizoncd/horizon/core/controller/cluster"
+	"github.com/horizoncd/horizon/lib/orm"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	"github.com/horizoncd/horizon/pkg/config/templatemigration"
+	"github.com/horizoncd/horizon/pkg/param/managerparam"
+	"github.com/horizoncd/horizon/pkg/templatemigration/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+// fakeClusterCtl records the upgraded clusters, and fails to upgrade the clusters in failures
+type fakeClusterCtl struct {
+	clusterctl.Controller
+	upgraded []uint
+	failures map[uint]bool
+}
+
+func (f *fakeClusterCtl) UpgradeTemplateRelease(ctx context.Context, clusterID uint,
+	release string, dryRun bool) error {
+	if f.failures[clusterID] {
+		return errors.New("replicas is required")
+	}
+	f.upgraded = append(f.upgraded, clusterID)
+	return nil
+}
+
+func TestMigrator(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&usermodels.User{}, &models.Migration{}, &models.MigrationCluster{}))
+	ctx := common.WithContext(context.Background(), &userauth.DefaultInfo{
+		Name: "Tony",
+		ID:   1,
+	})
+	mgr := managerparam.InitManager(db)
+	_, err = mgr.UserMgr.Create(ctx, &usermodels.User{Name: "Tony"})
+	assert.Nil(t, err)
+	clusterCtl := &fakeClusterCtl{failures: map[uint]bool{3: true}}
+	migrator := New(templatemigration.Config{}, mgr, clusterCtl)
+
+	createMigration := func(clusterIDs ...uint) *models.Migration {
+		clusters := make([]*models.MigrationCluster, 0, len(clusterIDs))
+		for _, id := range clusterIDs {
+			clusters = append(clusters, &models.MigrationCluster{ClusterID: id, FromRelease: "v1.0.0",
+				Status: models.ClusterStatusPending})
+		}
+		migration, err := mgr.TemplateMigrationMgr.Create(ctx, &models.Migration{
+			TemplateID:    1,
+			Template:      "javaapp",
+			TargetRelease: "v2.0.0",
+			BatchSize:     2,
+			Status:        models.StatusRunning,
+			CreatedBy:     1,
+		}, clusters)
+		assert.Nil(t, err)
+		return migration
+	}
+	getMigration := func(id uint) (*models.Migration, []*models.MigrationCluster) {
+		migration, err := mgr.TemplateMigrationMgr.Get(ctx, id)
+		assert.Nil(t, err)
+		clusters, err := mgr.TemplateMigrationMgr.ListClusters(ctx, id)
+		assert.Nil(t, err)
+		return migration, clusters
+	}
+
+	// a batch of clusters is upgraded every time
+	migration := createMigration(1, 2, 4)
+	migrator.process(ctx)
+	migration, clusters := getMigration(migration.ID)
+	assert.Equal(t, models.StatusRunning, migration.Status)
+	assert.Equal(t, []uint{1, 2}, clusterCtl.upgraded)
+	assert.Equal(t, models.ClusterStatusSucceeded, clusters[1].Status)
+	assert.NotNil(t, clusters[1].FinishedAt)
+	assert.Equal(t, models.ClusterStatusPending, clusters[2].Status)
+
+	// paused migrations are skipped
+	migration.Status = models.StatusPaused
+	_, err = mgr.TemplateMigrationMgr.Update(ctx, migration)
+	assert.Nil(t, err)
+	migrator.process(ctx)
+	assert.Equal(t, []uint{1, 2}, clusterCtl.upgraded)
+	migration.Status = models.StatusRunning
+	_, err = mgr.TemplateMigrationMgr.Update(ctx, migration)
+	assert.Nil(t, err)
+
+	migrator.process(ctx)
+	migrator.process(ctx)
+	migration, _ = getMigration(migration.ID)
+	assert.Equal(t, models.StatusSucceeded, migration.Status)
+	assert.Equal(t, []uint{1, 2, 4}, clusterCtl.upgraded)
+
+	// the migration is paused once a cluster fails, and it fails at the end
+	clusterCtl.upgraded = nil
+	migration = createMigration(3, 5, 6)
+	migrator.process(ctx)
+	migration, clusters = getMigration(migration.ID)
+	assert.Equal(t, models.StatusPaused, migration.Status)
+	assert.Equal(t, "1 clusters failed in the batch, resume to continue", migration.Message)
+	assert.Equal(t, models.ClusterStatusFailed, clusters[0].Status)
+	assert.Equal(t, "replicas is required", clusters[0].Message)
+	assert.Equal(t, []uint{5}, clusterCtl.upgraded)
+
+	migration.Status = models.StatusRunning
+	_, err = mgr.TemplateMigrationMgr.Update(ctx, migration)
+	assert.Nil(t, err)
+	migrator.process(ctx)
+	migrator.process(ctx)
+	migration, _ = getMigration(migration.ID)
+	assert.Equal(t, models.StatusFailed, migration.Status)
+	assert.Equal(t, "1 of 3 clusters failed", migration.Message)
+	assert.Equal(t, []uint{5, 6}, clusterCtl.upgraded)
+
+	// the migration aborted meanwhile is not changed by the job
+	migration = createMigration(7)
+	aborted := *migration
+	aborted.Status = models.StatusAborted
+	_, err = mgr.TemplateMigrationMgr.Update(ctx, &aborted)
+	assert.Nil(t, err)
+	assert.Nil(t, migrator.setStatus(ctx, migration, models.StatusPaused, ""))
+	migration, _ = getMigration(migration.ID)
+	assert.Equal(t, models.StatusAborted, migration.Status)
+}
@@ -29,12 +29,13 @@ import (
 // New runs the agent.
 func New(ctx context.Context, eventHandlerService eventhandlersvc.Service,
 	webhookCfg webhookcfg.Config, mgrs *managerparam.Manager) (jobs.Job, webhooksvc.Service) {
+	webhookService := webhooksvc.NewService(ctx, mgrs, webhookCfg)
+	// webhook logs generated are pushed to workers of webhook service
 	if err := eventHandlerService.RegisterEventHandler("webhook",
-		wlgenerator.NewWebhookLogGenerator(mgrs)); err != nil {
+		wlgenerator.NewWebhookLogGenerator(mgrs, webhookService)); err != nil {
 		log.Printf("failed to register event handler, error: %s", err.Error())
 		panic(err)
 	}
-	webhookService := webhooksvc.NewService(ctx, mgrs, webhookCfg)
 
 	return func(ctx context.Context) {
 		// start webhook service with multi workers to consume webhook logs and send webhook events
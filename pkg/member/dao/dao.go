@@ -44,9 +44,11 @@ type DAO interface {
 	ListDirectMemberOnCondition(ctx context.Context, resourceType models.ResourceType,
 		resourceID uint) ([]models.Member, error)
 	ListResourceOfMemberInfo(ctx context.Context, resourceType models.ResourceType, memberInfo uint) ([]uint, error)
+	ListResourceOfTeams(ctx context.Context, resourceType models.ResourceType, teamIDs []uint) ([]uint, error)
 	ListResourceOfMemberInfoByRole(ctx context.Context,
 		resourceType models.ResourceType, info uint, role string) ([]uint, error)
 	ListMembersByUserID(ctx context.Context, userID uint) ([]models.Member, error)
+	CountByRole(ctx context.Context, role string) (int64, error)
 }
 
 var (
@@ -188,6 +190,19 @@ func (d *dao) ListResourceOfMemberInfo(ctx context.Context,
 	return resources, nil
 }
 
+func (d *dao) ListResourceOfTeams(ctx context.Context,
+	resourceType models.ResourceType, teamIDs []uint) ([]uint, error) {
+	resources := make([]uint, 0)
+	if len(teamIDs) == 0 {
+		return resources, nil
+	}
+	result := d.db.WithContext(ctx).Raw(common.MemberListResourceOfTeams, resourceType, teamIDs).Scan(&resources)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return resources, nil
+}
+
 func (d *dao) ListResourceOfMemberInfoByRole(ctx context.Context,
 	resourceType models.ResourceType, info uint, role string) ([]uint, error) {
 	members := make([]uint, 0)
@@ -207,6 +222,7 @@ func (d *dao) ListResourceOfMemberInfoByRole(ctx context.Context,
 func (d *dao) ListMembersByUserID(ctx context.Context, userID uint) ([]models.Member, error) {
 	var members []models.Member
 	result := d.db.Model(model).WithContext(ctx).
+		Where("member_type = ?", models.MemberUser).
 		Where("membername_id = ?", userID).
 		Where("deleted_ts = 0").
 		Scan(&members)
@@ -215,3 +231,15 @@ func (d *dao) ListMembersByUserID(ctx context.Context, userID uint) ([]models.Me
 	}
 	return members, nil
 }
+
+func (d *dao) CountByRole(ctx context.Context, role string) (int64, error) {
+	var count int64
+	result := d.db.Model(model).WithContext(ctx).
+		Where("role = ?", role).
+		Where("deleted_ts = 0").
+		Count(&count)
+	if result.Error != nil {
+		return 0, herrors.NewErrGetFailed(herrors.MemberInfoInDB, result.Error.Error())
+	}
+	return count, nil
+}
@@ -61,10 +61,16 @@ type Manager interface {
 	ListResourceOfMemberInfo(ctx context.Context,
 		resourceType models.ResourceType, memberInfo uint) ([]uint, error)
 
+	// ListResourceOfTeams list the resource id of the specified resourceType which the teams are members of
+	ListResourceOfTeams(ctx context.Context, resourceType models.ResourceType, teamIDs []uint) ([]uint, error)
+
 	ListResourceOfMemberInfoByRole(ctx context.Context,
 		resourceType models.ResourceType, memberInfo uint, role string) ([]uint, error)
 
 	ListMembersByUserID(ctx context.Context, userID uint) ([]models.Member, error)
+
+	// CountByRole counts the members bound to the role
+	CountByRole(ctx context.Context, role string) (int64, error)
 }
 
 type manager struct {
@@ -123,6 +129,12 @@ func (m *manager) ListResourceOfMemberInfo(ctx context.Context,
 	resourceType models.ResourceType, memberInfo uint) ([]uint, error) {
 	return m.dao.ListResourceOfMemberInfo(ctx, resourceType, memberInfo)
 }
+
+func (m *manager) ListResourceOfTeams(ctx context.Context,
+	resourceType models.ResourceType, teamIDs []uint) ([]uint, error) {
+	return m.dao.ListResourceOfTeams(ctx, resourceType, teamIDs)
+}
+
 func (m *manager) ListResourceOfMemberInfoByRole(ctx context.Context,
 	resourceType models.ResourceType, memberInfo uint, role string) ([]uint, error) {
 	return m.dao.ListResourceOfMemberInfoByRole(ctx, resourceType, memberInfo, role)
@@ -131,3 +143,7 @@ func (m *manager) ListResourceOfMemberInfoByRole(ctx context.Context,
 func (m *manager) ListMembersByUserID(ctx context.Context, userID uint) ([]models.Member, error) {
 	return m.dao.ListMembersByUserID(ctx, userID)
 }
+
+func (m *manager) CountByRole(ctx context.Context, role string) (int64, error) {
+	return m.dao.CountByRole(ctx, role)
+}
@@ -45,6 +45,9 @@ const (
 	TypeTemplateRelease = ResourceType(common.ResourceTemplateRelease)
 
 	TypeRegion = ResourceType(common.ResourceRegion)
+
+	// TypeTeam teams do not have member entries, the users in them are their members
+	TypeTeam = ResourceType(common.ResourceTeam)
 )
 
 type MemberType uint8
@@ -22,20 +22,27 @@ import (
 
 	"github.com/horizoncd/horizon/core/common"
 	herror "github.com/horizoncd/horizon/core/errors"
+	admissionpolicymanager "github.com/horizoncd/horizon/pkg/admissionpolicy/manager"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
 	memberctx "github.com/horizoncd/horizon/pkg/context"
+	deploywindowmanager "github.com/horizoncd/horizon/pkg/deploywindow/manager"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
 	"github.com/horizoncd/horizon/pkg/member"
 	"github.com/horizoncd/horizon/pkg/member/models"
 	oauthmanager "github.com/horizoncd/horizon/pkg/oauth/manager"
 	"github.com/horizoncd/horizon/pkg/param/managerparam"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	roleservice "github.com/horizoncd/horizon/pkg/rbac/role"
+	releasetrainmanager "github.com/horizoncd/horizon/pkg/releasetrain/manager"
+	teammanager "github.com/horizoncd/horizon/pkg/team/manager"
+	teammodels "github.com/horizoncd/horizon/pkg/team/models"
 	templatemanager "github.com/horizoncd/horizon/pkg/template/manager"
 	templatereleasemanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
+	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	"github.com/horizoncd/horizon/pkg/util/log"
@@ -58,6 +65,9 @@ type Service interface {
 	ListMember(ctx context.Context, resourceType string, resourceID uint) ([]models.Member, error)
 	// GetMemberOfResource return the current user's role of the resource (member from direct or parent)
 	GetMemberOfResource(ctx context.Context, resourceType string, resourceID string) (*models.Member, error)
+	// ListInvisible returns the groups and applications invisible to the current user,
+	// nil means everything is visible
+	ListInvisible(ctx context.Context) (*groupmodels.Invisible, error)
 	// IsYourPermissionHigher helps to check if your permission is higher then specified member
 	RequirePermissionEqualOrHigher(ctx context.Context, role, resourceType string, resourceID uint) error
 }
@@ -74,6 +84,11 @@ type service struct {
 	oauthManager              oauthmanager.Manager
 	userManager               usermanager.Manager
 	webhookManager            webhookmanager.Manager
+	admissionPolicyManager    admissionpolicymanager.Manager
+	deployWindowManager       deploywindowmanager.Manager
+	releaseTrainManager       releasetrainmanager.Manager
+	teamManager               teammanager.Manager
+	tokenManager              tokenmanager.Manager
 }
 
 func NewService(roleService roleservice.Service, oauthManager oauthmanager.Manager,
@@ -90,6 +105,11 @@ func NewService(roleService roleservice.Service, oauthManager oauthmanager.Manag
 		oauthManager:              oauthManager,
 		userManager:               manager.UserMgr,
 		webhookManager:            manager.WebhookMgr,
+		admissionPolicyManager:    manager.AdmissionPolicyMgr,
+		deployWindowManager:       manager.DeployWindowMgr,
+		releaseTrainManager:       manager.ReleaseTrainMgr,
+		teamManager:               manager.TeamMgr,
+		tokenManager:              manager.TokenMgr,
 	}
 }
 
@@ -104,8 +124,7 @@ func (s *service) RequirePermissionEqualOrHigher(ctx context.Context, role,
 		return nil
 	}
 	var userMemberInfo *models.Member
-	userMemberInfo, err = s.getMember(ctx, resourceType,
-		resourceID, models.MemberUser, currentUser.GetID())
+	userMemberInfo, err = s.getMemberOfUser(ctx, resourceType, resourceID, currentUser.GetID())
 	if err != nil {
 		return err
 	}
@@ -130,6 +149,12 @@ func (s *service) CreateMember(ctx context.Context, postMember PostMember) (*mod
 		return nil, err
 	}
 
+	if postMember.MemberType == models.MemberGroup {
+		if err := s.checkTeamMember(ctx, postMember); err != nil {
+			return nil, err
+		}
+	}
+
 	// 1. check exist
 	memberItem, err := s.memberManager.Get(ctx, models.ResourceType(postMember.ResourceType), postMember.ResourceID,
 		postMember.MemberType, postMember.MemberInfo)
@@ -155,6 +180,24 @@ func (s *service) CreateMember(ctx context.Context, postMember PostMember) (*mod
 	return s.memberManager.Create(ctx, member)
 }
 
+// checkTeamMember checks that the team exists, and it's bound to a group, an application or a cluster
+func (s *service) checkTeamMember(ctx context.Context, postMember PostMember) error {
+	switch postMember.ResourceType {
+	case common.ResourceGroup, common.ResourceApplication, common.ResourceCluster:
+	default:
+		return perror.Wrapf(herror.ErrParamInvalid,
+			"team can not be member of %s", postMember.ResourceType)
+	}
+	_, err := s.teamManager.Get(ctx, postMember.MemberInfo)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herror.HorizonErrNotFound); ok {
+			return perror.Wrapf(herror.ErrParamInvalid, "team %d does not exist", postMember.MemberInfo)
+		}
+		return err
+	}
+	return nil
+}
+
 func (s *service) getOauthAppMember(ctx context.Context, clientID string) (*models.Member, error) {
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
@@ -167,7 +210,71 @@ func (s *service) getOauthAppMember(ctx context.Context, clientID string) (*mode
 	if !app.IsGroupOwnerType() {
 		return nil, herror.ErrOAuthNotGroupOwnerType
 	}
-	return s.getMember(ctx, common.ResourceGroup, app.OwnerID, models.MemberUser, currentUser.GetID())
+	return s.getMemberOfUser(ctx, common.ResourceGroup, app.OwnerID, currentUser.GetID())
+}
+
+// getTeamMember returns the member info of the user in the team, the owners of the team
+// have the owner role, and the others have the guest role
+func (s *service) getTeamMember(ctx context.Context, teamID uint, userID uint) (*models.Member, error) {
+	if _, err := s.teamManager.Get(ctx, teamID); err != nil {
+		return nil, err
+	}
+	teamMember, err := s.teamManager.GetMember(ctx, teamID, userID)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herror.HorizonErrNotFound); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	role := roleservice.Guest
+	if teamMember.Role == teammodels.RoleOwner {
+		role = roleservice.Owner
+	}
+	return &models.Member{
+		ResourceType: models.TypeTeam,
+		ResourceID:   teamID,
+		Role:         role,
+		MemberType:   models.MemberUser,
+		MemberNameID: userID,
+	}, nil
+}
+
+// getMemberOfMember returns the current user's member info of the resource which the member belongs to
+func (s *service) getMemberOfMember(ctx context.Context, memberID uint) (*models.Member, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	memberItem, err := s.memberManager.GetByID(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if memberItem == nil {
+		return nil, herror.NewErrNotFound(herror.MemberInfoInDB, fmt.Sprintf("member %d does not exist", memberID))
+	}
+	return s.getMemberOfUser(ctx, string(memberItem.ResourceType), memberItem.ResourceID, currentUser.GetID())
+}
+
+// getAccessTokenMember returns the current user's member info of the resource
+// which the robot of the access token belongs to
+func (s *service) getAccessTokenMember(ctx context.Context, tokenID uint) (*models.Member, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.tokenManager.LoadTokenByID(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.memberManager.ListMembersByUserID(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, herror.NewErrNotFound(herror.MemberInfoInDB,
+			fmt.Sprintf("access token %d does not belong to any resource", tokenID))
+	}
+	return s.getMemberOfUser(ctx, string(members[0].ResourceType), members[0].ResourceID, currentUser.GetID())
 }
 
 func (s *service) getCheckrunMember(ctx context.Context, checkrunID uint) (*models.Member, error) {
@@ -197,8 +304,7 @@ func (s *service) getPipelinerunMember(ctx context.Context, pipelinerunID uint)
 		log.Warningf(ctx, msg)
 		return nil, herror.NewErrNotFound(herror.MemberInfoInDB, msg)
 	}
-	return s.getMember(ctx, common.ResourceCluster,
-		pipeline.ClusterID, models.MemberUser, currentUser.GetID())
+	return s.getMemberOfUser(ctx, common.ResourceCluster, pipeline.ClusterID, currentUser.GetID())
 }
 
 func (s *service) listPipelinerunMember(ctx context.Context, pipelinerunID uint) ([]models.Member, error) {
@@ -243,6 +349,49 @@ func (s *service) listWebhookLogMember(ctx context.Context, id uint) ([]models.M
 	return s.listWebhookMember(ctx, webhookLog.WebhookID)
 }
 
+func (s *service) listAdmissionPolicyMember(ctx context.Context, id uint) ([]models.Member, error) {
+	if id == 0 {
+		return nil, nil
+	}
+	policy, err := s.admissionPolicyManager.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListMember(ctx, common.ResourceGroup, policy.GroupID)
+}
+
+// listDeployWindowMember lists the members of group which the window belongs to,
+// the windows of environments have no members.
+func (s *service) listDeployWindowMember(ctx context.Context, id uint) ([]models.Member, error) {
+	if id == 0 {
+		return nil, nil
+	}
+	window, err := s.deployWindowManager.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if window.ResourceType != common.ResourceGroup {
+		return nil, nil
+	}
+	return s.ListMember(ctx, common.ResourceGroup, window.ResourceID)
+}
+
+func (s *service) listReleaseTrainMember(ctx context.Context, id uint) ([]models.Member, error) {
+	train, err := s.releaseTrainManager.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListMember(ctx, common.ResourceApplication, train.ApplicationID)
+}
+
+func (s *service) listReleaseTrainRunMember(ctx context.Context, id uint) ([]models.Member, error) {
+	run, err := s.releaseTrainManager.GetRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListMember(ctx, common.ResourceApplication, run.ApplicationID)
+}
+
 func (s *service) GetMemberOfResource(ctx context.Context,
 	resourceType string, resourceIDStr string) (*models.Member, error) {
 	var currentUser userauth.User
@@ -260,17 +409,35 @@ func (s *service) GetMemberOfResource(ctx context.Context,
 		memberInfo, err = s.getPipelinerunMember(ctx, uint(resourceID))
 	case common.ResourceOauthApps:
 		memberInfo, err = s.getOauthAppMember(ctx, resourceIDStr)
+	case common.ResourceMember:
+		resourceID, _ := strconv.Atoi(resourceIDStr)
+		memberInfo, err = s.getMemberOfMember(ctx, uint(resourceID))
+	case common.ResourceAccessToken:
+		resourceID, _ := strconv.Atoi(resourceIDStr)
+		memberInfo, err = s.getAccessTokenMember(ctx, uint(resourceID))
+	case common.ResourceTeam:
+		// teams are invisible to the users not in them, so the default role is not used
+		resourceID, _ := strconv.Atoi(resourceIDStr)
+		return s.getTeamMember(ctx, uint(resourceID), currentUser.GetID())
 	default:
 		resourceID, _ := strconv.Atoi(resourceIDStr)
-		memberInfo, err = s.getMember(ctx, resourceType, uint(resourceID), models.MemberUser, currentUser.GetID())
+		memberInfo, err = s.getMemberOfUser(ctx, resourceType, uint(resourceID), currentUser.GetID())
 	}
 	if err != nil {
 		return nil, err
 	}
 	if memberInfo == nil {
+		// non-members use the default role if the resource is visible to them
 		defaultRole := s.roleService.GetDefaultRole(ctx)
 		if nil != defaultRole {
 			resourceID, _ := strconv.Atoi(resourceIDStr)
+			visible, err := s.visibleToNonMember(ctx, currentUser, resourceType, uint(resourceID))
+			if err != nil {
+				return nil, err
+			}
+			if !visible {
+				return nil, nil
+			}
 			memberInfo = &models.Member{
 				MemberType:   models.MemberUser,
 				Role:         defaultRole.Name,
@@ -304,12 +471,14 @@ func (s *service) RemoveMember(ctx context.Context, memberID uint) error {
 	}
 
 	// 3. check if common user
-	user, err := s.userManager.GetUserByID(ctx, memberItem.MemberNameID)
-	if err != nil {
-		return err
-	}
-	if user.UserType != usermodels.UserTypeCommon {
-		return perror.Wrapf(herror.ErrParamInvalid, "member of user type %d does not support updated", user.UserType)
+	if memberItem.MemberType == models.MemberUser {
+		user, err := s.userManager.GetUserByID(ctx, memberItem.MemberNameID)
+		if err != nil {
+			return err
+		}
+		if user.UserType != usermodels.UserTypeCommon {
+			return perror.Wrapf(herror.ErrParamInvalid, "member of user type %d does not support updated", user.UserType)
+		}
 	}
 
 	return s.memberManager.DeleteMember(ctx, memberID)
@@ -337,12 +506,15 @@ func (s *service) UpdateMember(ctx context.Context, memberID uint, role string)
 	}
 
 	// 3. check if common user
-	user, err := s.userManager.GetUserByID(ctx, memberItem.MemberNameID)
-	if err != nil {
-		return nil, err
-	}
-	if user.UserType != usermodels.UserTypeCommon {
-		return nil, perror.Wrapf(herror.ErrParamInvalid, "member of user type %d does not support updated", user.UserType)
+	if memberItem.MemberType == models.MemberUser {
+		user, err := s.userManager.GetUserByID(ctx, memberItem.MemberNameID)
+		if err != nil {
+			return nil, err
+		}
+		if user.UserType != usermodels.UserTypeCommon {
+			return nil, perror.Wrapf(herror.ErrParamInvalid,
+				"member of user type %d does not support updated", user.UserType)
+		}
 	}
 
 	// 4. update the role
@@ -370,6 +542,14 @@ func (s *service) ListMember(ctx context.Context, resourceType string, resourceI
 		allMembers, err = s.listWebhookMember(ctx, resourceID)
 	case common.ResourceWebhookLog:
 		allMembers, err = s.listWebhookLogMember(ctx, resourceID)
+	case common.ResourceAdmissionPolicy:
+		allMembers, err = s.listAdmissionPolicyMember(ctx, resourceID)
+	case common.ResourceDeployWindow:
+		allMembers, err = s.listDeployWindowMember(ctx, resourceID)
+	case common.ResourceReleaseTrain:
+		allMembers, err = s.listReleaseTrainMember(ctx, resourceID)
+	case common.ResourceReleaseTrainRun:
+		allMembers, err = s.listReleaseTrainRunMember(ctx, resourceID)
 	default:
 		err = errors.New("unsupported resourceType")
 	}
@@ -395,20 +575,64 @@ func DeduplicateMember(members []models.Member) []models.Member {
 	return retMembers
 }
 
-// getMember return the direct member or member from the parent
-func (s *service) getMember(ctx context.Context, resourceType string, resourceID uint,
-	memberType models.MemberType, memberInfo uint) (*models.Member, error) {
+// getMemberOfUser return the member entry which decides the user's role of the resource.
+// The user's own entries and the entries of the teams the user belongs to are both considered,
+// the entry of the nearest resource wins, and the highest role wins among the entries of that resource.
+func (s *service) getMemberOfUser(ctx context.Context, resourceType string, resourceID uint,
+	userID uint) (*models.Member, error) {
 	members, err := s.ListMember(ctx, resourceType, resourceID)
 	if err != nil {
 		return nil, err
 	}
+
+	var teamIDs map[uint]struct{}
 	for _, item := range members {
-		if item.MemberType == memberType &&
-			item.MemberNameID == memberInfo {
-			return &item, nil
+		if item.MemberType != models.MemberGroup {
+			continue
+		}
+		ids, err := s.teamManager.ListTeamIDsOfUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		teamIDs = make(map[uint]struct{}, len(ids))
+		for _, id := range ids {
+			teamIDs[id] = struct{}{}
+		}
+		break
+	}
+
+	var matched *models.Member
+	for i := range members {
+		item := members[i]
+		if matched != nil && (item.ResourceType != matched.ResourceType ||
+			item.ResourceID != matched.ResourceID) {
+			break
+		}
+		switch item.MemberType {
+		case models.MemberUser:
+			if item.MemberNameID != userID {
+				continue
+			}
+		case models.MemberGroup:
+			if _, ok := teamIDs[item.MemberNameID]; !ok {
+				continue
+			}
+		default:
+			continue
+		}
+		if matched == nil {
+			matched = &item
+			continue
+		}
+		result, err := s.roleService.RoleCompare(ctx, item.Role, matched.Role)
+		if err != nil {
+			return nil, err
+		}
+		if result == roleservice.RoleBigger {
+			matched = &item
 		}
 	}
-	return nil, nil
+	return matched, nil
 }
 
 func (s *service) listGroupMembers(ctx context.Context, resourceID uint) ([]models.Member, error) {
@@ -43,8 +43,11 @@ import (
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	pipelinemodels "github.com/horizoncd/horizon/pkg/pr/models"
 	roleservice "github.com/horizoncd/horizon/pkg/rbac/role"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
 	"github.com/horizoncd/horizon/pkg/server/global"
+	teammodels "github.com/horizoncd/horizon/pkg/team/models"
 	templatemodels "github.com/horizoncd/horizon/pkg/template/models"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
 	usermanager "github.com/horizoncd/horizon/pkg/user/manager"
 	usermodels "github.com/horizoncd/horizon/pkg/user/models"
 	webhookmodels "github.com/horizoncd/horizon/pkg/webhook/models"
@@ -994,6 +997,409 @@ func TestListWebhookMember(t *testing.T) {
 	assert.Equal(t, "pe", memberInfo.Role)
 }
 
+// nolint
+func TestTeamMember(t *testing.T) {
+	createEnv(t)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var (
+		group1ID       uint = 1
+		group2ID       uint = 2
+		application3ID uint = 3
+		cluster4ID     uint = 4
+	)
+	users := []usermodels.User{
+		{Name: "sph"},
+		{Name: "jerry"},
+		{Name: "cat"},
+	}
+	for i := range users {
+		_, err := manager.UserMgr.Create(ctx, &users[i])
+		assert.Nil(t, err)
+	}
+	sph, jerry, cat := users[0], users[1], users[2]
+	adminCtx := common.WithContext(ctx, &userauth.DefaultInfo{
+		Name:  "admin",
+		ID:    100,
+		Admin: true,
+	})
+	userCtx := func(user usermodels.User) context.Context {
+		return common.WithContext(ctx, &userauth.DefaultInfo{
+			Name: user.Name,
+			ID:   user.ID,
+		})
+	}
+
+	teamA, err := manager.TeamMgr.Create(ctx, &teammodels.Team{Name: "a"},
+		&teammodels.TeamMember{UserID: sph.ID, Role: teammodels.RoleOwner})
+	assert.Nil(t, err)
+	_, err = manager.TeamMgr.UpsertMember(ctx, &teammodels.TeamMember{
+		TeamID: teamA.ID, UserID: jerry.ID, Role: teammodels.RoleMember})
+	assert.Nil(t, err)
+	teamB, err := manager.TeamMgr.Create(ctx, &teammodels.Team{Name: "b"},
+		&teammodels.TeamMember{UserID: cat.ID, Role: teammodels.RoleOwner})
+	assert.Nil(t, err)
+
+	groupManager := groupmanagermock.NewMockManager(mockCtrl)
+	groupManager.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(&groupModels.Group{
+		TraversalIDs: "1,2",
+	}, nil).AnyTimes()
+	groupManager.EXPECT().IsRootGroup(gomock.Any(), gomock.Any()).AnyTimes().Return(false)
+	applicationManager := applicationmanagermock.NewMockManager(mockCtrl)
+	applicationManager.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(&applicationmodels.Application{
+		GroupID: group2ID,
+	}, nil).AnyTimes()
+	clusterManager := clustermanagermock.NewMockManager(mockCtrl)
+	clusterManager.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(&clustermodels.Cluster{
+		ApplicationID: application3ID,
+	}, nil).AnyTimes()
+
+	ranks := map[string]int{roleservice.Owner: 0, roleservice.Maintainer: 1,
+		roleservice.PE: 2, roleservice.Guest: 3}
+	roleSvc := rolemock.NewMockService(mockCtrl)
+	roleSvc.EXPECT().RoleCompare(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, role1, role2 string) (roleservice.CompResult, error) {
+			switch {
+			case ranks[role1] < ranks[role2]:
+				return roleservice.RoleBigger, nil
+			case ranks[role1] > ranks[role2]:
+				return roleservice.RoleSmaller, nil
+			default:
+				return roleservice.RoleEqual, nil
+			}
+		}).AnyTimes()
+	roleSvc.EXPECT().GetDefaultRole(gomock.Any()).Return(nil).AnyTimes()
+
+	originService := &service{
+		memberManager:             manager.MemberMgr,
+		groupManager:              groupManager,
+		applicationManager:        applicationManager,
+		applicationClusterManager: clusterManager,
+		roleService:               roleSvc,
+		userManager:               manager.UserMgr,
+		teamManager:               manager.TeamMgr,
+	}
+	s = originService
+
+	postMembers := []PostMember{
+		{
+			ResourceType: common.ResourceGroup,
+			ResourceID:   group1ID,
+			MemberInfo:   teamB.ID,
+			MemberType:   models.MemberGroup,
+			Role:         roleservice.Owner,
+		},
+		{
+			ResourceType: common.ResourceApplication,
+			ResourceID:   application3ID,
+			MemberInfo:   jerry.ID,
+			MemberType:   models.MemberUser,
+			Role:         roleservice.Guest,
+		},
+		{
+			ResourceType: common.ResourceApplication,
+			ResourceID:   application3ID,
+			MemberInfo:   teamA.ID,
+			MemberType:   models.MemberGroup,
+			Role:         roleservice.Maintainer,
+		},
+		{
+			ResourceType: common.ResourceCluster,
+			ResourceID:   cluster4ID,
+			MemberInfo:   sph.ID,
+			MemberType:   models.MemberUser,
+			Role:         roleservice.PE,
+		},
+	}
+	for _, postMember := range postMembers {
+		result, err := s.CreateMember(adminCtx, postMember)
+		assert.Nil(t, err)
+		assert.True(t, PostMemberEqualsMember(postMember, result))
+	}
+
+	// teams are only allowed to be members of groups, applications and clusters
+	_, err = s.CreateMember(adminCtx, PostMember{
+		ResourceType: common.ResourceTemplate,
+		ResourceID:   1,
+		MemberInfo:   teamA.ID,
+		MemberType:   models.MemberGroup,
+		Role:         roleservice.Owner,
+	})
+	assert.Equal(t, herror.ErrParamInvalid, perror.Cause(err))
+	_, err = s.CreateMember(adminCtx, PostMember{
+		ResourceType: common.ResourceCluster,
+		ResourceID:   cluster4ID,
+		MemberInfo:   1000,
+		MemberType:   models.MemberGroup,
+		Role:         roleservice.Owner,
+	})
+	assert.Equal(t, herror.ErrParamInvalid, perror.Cause(err))
+
+	members, err := s.ListMember(ctx, common.ResourceCluster, cluster4ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(members))
+
+	// the highest role of the same resource wins
+	member, err := s.GetMemberOfResource(userCtx(jerry), common.ResourceCluster,
+		strconv.Itoa(int(cluster4ID)))
+	assert.Nil(t, err)
+	assert.Equal(t, models.MemberGroup, member.MemberType)
+	assert.Equal(t, roleservice.Maintainer, member.Role)
+
+	// the role of the nearest resource wins
+	member, err = s.GetMemberOfResource(userCtx(sph), common.ResourceCluster,
+		strconv.Itoa(int(cluster4ID)))
+	assert.Nil(t, err)
+	assert.Equal(t, models.MemberUser, member.MemberType)
+	assert.Equal(t, roleservice.PE, member.Role)
+
+	// the role of team is inherited from the parent group
+	member, err = s.GetMemberOfResource(userCtx(cat), common.ResourceApplication,
+		strconv.Itoa(int(application3ID)))
+	assert.Nil(t, err)
+	assert.Equal(t, teamB.ID, member.MemberNameID)
+	assert.Equal(t, roleservice.Owner, member.Role)
+	assert.Nil(t, s.RequirePermissionEqualOrHigher(userCtx(cat), roleservice.Owner,
+		common.ResourceCluster, cluster4ID))
+
+	// members of team can be updated and removed
+	teamMember, err := s.UpdateMember(userCtx(cat), member.ID, roleservice.Maintainer)
+	assert.Nil(t, err)
+	assert.Equal(t, roleservice.Maintainer, teamMember.Role)
+
+	// the owners of the team have the owner role of it, and the others in it have the guest role
+	teamAID := strconv.Itoa(int(teamA.ID))
+	member, err = s.GetMemberOfResource(userCtx(sph), common.ResourceTeam, teamAID)
+	assert.Nil(t, err)
+	assert.Equal(t, roleservice.Owner, member.Role)
+	member, err = s.GetMemberOfResource(userCtx(jerry), common.ResourceTeam, teamAID)
+	assert.Nil(t, err)
+	assert.Equal(t, roleservice.Guest, member.Role)
+	member, err = s.GetMemberOfResource(userCtx(cat), common.ResourceTeam, teamAID)
+	assert.Nil(t, err)
+	assert.Nil(t, member)
+	_, err = s.GetMemberOfResource(userCtx(cat), common.ResourceTeam, "1000")
+	_, ok := perror.Cause(err).(*herror.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	// users no longer get the role once they leave the team
+	err = manager.TeamMgr.DeleteMember(ctx, teamA.ID, jerry.ID)
+	assert.Nil(t, err)
+	member, err = s.GetMemberOfResource(userCtx(jerry), common.ResourceCluster,
+		strconv.Itoa(int(cluster4ID)))
+	assert.Nil(t, err)
+	assert.Equal(t, models.MemberUser, member.MemberType)
+	assert.Equal(t, roleservice.Guest, member.Role)
+
+	// members of the team are deleted with the team
+	err = manager.TeamMgr.Delete(ctx, teamB.ID)
+	assert.Nil(t, err)
+	member, err = s.GetMemberOfResource(userCtx(cat), common.ResourceApplication,
+		strconv.Itoa(int(application3ID)))
+	assert.Nil(t, err)
+	assert.Nil(t, member)
+}
+
+func TestGetMemberOfMemberAndAccessToken(t *testing.T) {
+	createEnv(t)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	users := []usermodels.User{
+		{Name: "sph"},
+		{Name: "jerry"},
+		{Name: "robot", UserType: usermodels.UserTypeRobot},
+	}
+	for i := range users {
+		_, err := manager.UserMgr.Create(ctx, &users[i])
+		assert.Nil(t, err)
+	}
+	sph, jerry, robot := users[0], users[1], users[2]
+	userCtx := func(user usermodels.User) context.Context {
+		return common.WithContext(ctx, &userauth.DefaultInfo{
+			Name: user.Name,
+			ID:   user.ID,
+		})
+	}
+
+	groupManager := groupmanagermock.NewMockManager(mockCtrl)
+	groupManager.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(&groupModels.Group{
+		TraversalIDs: "1",
+	}, nil).AnyTimes()
+	groupManager.EXPECT().IsRootGroup(gomock.Any(), gomock.Any()).AnyTimes().Return(false)
+	roleSvc := rolemock.NewMockService(mockCtrl)
+	roleSvc.EXPECT().GetDefaultRole(gomock.Any()).Return(nil).AnyTimes()
+	s = &service{
+		memberManager: manager.MemberMgr,
+		groupManager:  groupManager,
+		roleService:   roleSvc,
+		userManager:   manager.UserMgr,
+		teamManager:   manager.TeamMgr,
+		tokenManager:  manager.TokenMgr,
+	}
+
+	owner, err := manager.MemberMgr.Create(ctx, &models.Member{ResourceType: common.ResourceGroup,
+		ResourceID: 1, Role: roleservice.Owner, MemberType: models.MemberUser, MemberNameID: sph.ID})
+	assert.Nil(t, err)
+	robotMember, err := manager.MemberMgr.Create(ctx, &models.Member{ResourceType: common.ResourceGroup,
+		ResourceID: 1, Role: roleservice.Guest, MemberType: models.MemberUser, MemberNameID: robot.ID})
+	assert.Nil(t, err)
+	token, err := manager.TokenMgr.CreateToken(ctx, &tokenmodels.Token{Name: "robot", UserID: robot.ID})
+	assert.Nil(t, err)
+
+	// members and access tokens use the member info of the resources that they belong to
+	for resourceType, id := range map[string]uint{
+		common.ResourceMember:      robotMember.ID,
+		common.ResourceAccessToken: token.ID,
+	} {
+		member, err := s.GetMemberOfResource(userCtx(sph), resourceType, strconv.Itoa(int(id)))
+		assert.Nil(t, err)
+		assert.Equal(t, owner.ID, member.ID)
+		member, err = s.GetMemberOfResource(userCtx(jerry), resourceType, strconv.Itoa(int(id)))
+		assert.Nil(t, err)
+		assert.Nil(t, member)
+	}
+
+	_, err = s.GetMemberOfResource(userCtx(sph), common.ResourceMember, "1000")
+	_, ok := perror.Cause(err).(*herror.HorizonErrNotFound)
+	assert.True(t, ok)
+}
+
+func TestVisibility(t *testing.T) {
+	createEnv(t)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	users := []usermodels.User{
+		{Name: "sph"},
+		{Name: "jerry"},
+		{Name: "robot", UserType: usermodels.UserTypeRobot},
+	}
+	for i := range users {
+		_, err := manager.UserMgr.Create(ctx, &users[i])
+		assert.Nil(t, err)
+	}
+	sph, jerry, robot := users[0], users[1], users[2]
+	userCtx := func(user usermodels.User) context.Context {
+		return common.WithContext(ctx, &userauth.DefaultInfo{
+			Name: user.Name,
+			ID:   user.ID,
+		})
+	}
+	creatorCtx := common.WithContext(ctx, &userauth.DefaultInfo{Name: "creator", ID: 100})
+
+	roleSvc := rolemock.NewMockService(mockCtrl)
+	roleSvc.EXPECT().GetDefaultRole(gomock.Any()).Return(&types.Role{Name: roleservice.Guest}).AnyTimes()
+	s = &service{
+		memberManager:      manager.MemberMgr,
+		groupManager:       manager.GroupMgr,
+		applicationManager: manager.ApplicationMgr,
+		roleService:        roleSvc,
+		userManager:        manager.UserMgr,
+		teamManager:        manager.TeamMgr,
+	}
+
+	// g1(public) -> g2(private) -> g3(public, inherits private from g2)
+	g1, err := manager.GroupMgr.Create(creatorCtx, &groupModels.Group{Name: "g1", Path: "g1",
+		VisibilityLevel: groupModels.VisibilityPublic})
+	assert.Nil(t, err)
+	g2, err := manager.GroupMgr.Create(creatorCtx, &groupModels.Group{Name: "g2", Path: "g2",
+		VisibilityLevel: groupModels.VisibilityPrivate, ParentID: g1.ID})
+	assert.Nil(t, err)
+	g3, err := manager.GroupMgr.Create(creatorCtx, &groupModels.Group{Name: "g3", Path: "g3",
+		VisibilityLevel: groupModels.VisibilityPublic, ParentID: g2.ID})
+	assert.Nil(t, err)
+	createApplication := func(name string, groupID uint, level string) *applicationmodels.Application {
+		application, err := manager.ApplicationMgr.Create(creatorCtx, &applicationmodels.Application{
+			Name: name, GroupID: groupID, VisibilityLevel: level}, nil)
+		assert.Nil(t, err)
+		return application
+	}
+	publicApp := createApplication("public", g1.ID, groupModels.VisibilityPublic)
+	privateApp := createApplication("private", g1.ID, groupModels.VisibilityPrivate)
+	internalApp := createApplication("internal", g1.ID, groupModels.VisibilityInternal)
+	inheritedApp := createApplication("inherited", g3.ID, groupModels.VisibilityPublic)
+
+	// jerry is a member of g2 by team
+	team, err := manager.TeamMgr.Create(ctx, &teammodels.Team{Name: "team"},
+		&teammodels.TeamMember{UserID: jerry.ID, Role: teammodels.RoleOwner})
+	assert.Nil(t, err)
+	_, err = manager.MemberMgr.Create(ctx, &models.Member{ResourceType: common.ResourceGroup,
+		ResourceID: g2.ID, Role: roleservice.Maintainer, MemberType: models.MemberGroup, MemberNameID: team.ID})
+	assert.Nil(t, err)
+
+	// non-members use the default role of the visible resources only
+	for _, c := range []struct {
+		user         usermodels.User
+		resourceType string
+		resourceID   uint
+		role         string
+	}{
+		{sph, common.ResourceGroup, g1.ID, roleservice.Guest},
+		{sph, common.ResourceGroup, g2.ID, ""},
+		{sph, common.ResourceGroup, g3.ID, ""},
+		{sph, common.ResourceApplication, publicApp.ID, roleservice.Guest},
+		{sph, common.ResourceApplication, privateApp.ID, ""},
+		{sph, common.ResourceApplication, internalApp.ID, roleservice.Guest},
+		{sph, common.ResourceApplication, inheritedApp.ID, ""},
+		{robot, common.ResourceApplication, publicApp.ID, roleservice.Guest},
+		{robot, common.ResourceApplication, internalApp.ID, ""},
+		{jerry, common.ResourceGroup, g3.ID, roleservice.Maintainer},
+		{jerry, common.ResourceApplication, inheritedApp.ID, roleservice.Maintainer},
+		{jerry, common.ResourceApplication, privateApp.ID, ""},
+	} {
+		member, err := s.GetMemberOfResource(userCtx(c.user), c.resourceType, strconv.Itoa(int(c.resourceID)))
+		assert.Nil(t, err)
+		if c.role == "" {
+			assert.Nil(t, member, "%s %s %d", c.user.Name, c.resourceType, c.resourceID)
+		} else {
+			assert.Equal(t, c.role, member.Role, "%s %s %d", c.user.Name, c.resourceType, c.resourceID)
+		}
+	}
+
+	// admins see everything
+	invisible, err := s.ListInvisible(common.WithContext(ctx, &userauth.DefaultInfo{Name: "admin", Admin: true}))
+	assert.Nil(t, err)
+	assert.Nil(t, invisible)
+
+	invisible, err = s.ListInvisible(userCtx(sph))
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []uint{g2.ID, g3.ID}, invisible.GroupIDs)
+	assert.True(t, invisible.GroupVisible(g1.ID))
+	assert.True(t, invisible.ApplicationVisible(publicApp.ID, publicApp.GroupID, publicApp.VisibilityLevel))
+	assert.True(t, invisible.ApplicationVisible(internalApp.ID, internalApp.GroupID, internalApp.VisibilityLevel))
+	assert.False(t, invisible.ApplicationVisible(privateApp.ID, privateApp.GroupID, privateApp.VisibilityLevel))
+	assert.False(t, invisible.ApplicationVisible(inheritedApp.ID, inheritedApp.GroupID,
+		inheritedApp.VisibilityLevel))
+	children, total, err := manager.GroupMgr.GetChildren(ctx, g1.ID, invisible, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, 2, len(children))
+
+	invisible, err = s.ListInvisible(userCtx(robot))
+	assert.Nil(t, err)
+	assert.False(t, invisible.ApplicationVisible(internalApp.ID, internalApp.GroupID, internalApp.VisibilityLevel))
+	children, total, err = manager.GroupMgr.GetChildren(ctx, g1.ID, invisible, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, publicApp.ID, children[0].ID)
+
+	invisible, err = s.ListInvisible(userCtx(jerry))
+	assert.Nil(t, err)
+	assert.Empty(t, invisible.GroupIDs)
+	assert.ElementsMatch(t, []uint{g2.ID, g3.ID}, invisible.AuthorizedGroupIDs)
+	assert.True(t, invisible.ApplicationVisible(inheritedApp.ID, inheritedApp.GroupID,
+		inheritedApp.VisibilityLevel))
+	children, total, err = manager.GroupMgr.GetChildren(ctx, g1.ID, invisible, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, 3, len(children))
+}
+
 func createEnv(t *testing.T) {
 	db, _ = orm.NewSqliteDB("")
 	err := db.AutoMigrate(&models.Member{},
@@ -1004,6 +1410,9 @@ func createEnv(t *testing.T) {
 		&templatemodels.Template{},
 		&webhookmodels.Webhook{},
 		&webhookmodels.WebhookLog{},
+		&teammodels.Team{},
+		&teammodels.TeamMember{},
+		&tokenmodels.Token{},
 	)
 
 	assert.Nil(t, err)
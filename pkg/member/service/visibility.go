@@ -0,0 +1,222 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+
+	"github.com/horizoncd/horizon/core/common"
+	herror "github.com/horizoncd/horizon/core/errors"
+	userauth "github.com/horizoncd/horizon/pkg/authentication/user"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	groupmodels "github.com/horizoncd/horizon/pkg/group/models"
+	"github.com/horizoncd/horizon/pkg/member/models"
+	usermodels "github.com/horizoncd/horizon/pkg/user/models"
+)
+
+// invisibleLevels returns the visibility levels which are invisible to the non-member user,
+// private resources are invisible to all the non-members, internal resources are invisible to robots
+func (s *service) invisibleLevels(ctx context.Context, user userauth.User) ([]string, error) {
+	levels := []string{groupmodels.VisibilityPrivate}
+	userInDB, err := s.userManager.GetUserByID(ctx, user.GetID())
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herror.HorizonErrNotFound); ok {
+			return levels, nil
+		}
+		return nil, err
+	}
+	if userInDB.UserType == usermodels.UserTypeRobot {
+		levels = append(levels, groupmodels.VisibilityInternal)
+	}
+	return levels, nil
+}
+
+// visibleToNonMember returns whether the resource is visible to the user who is not a member of it
+func (s *service) visibleToNonMember(ctx context.Context, user userauth.User,
+	resourceType string, resourceID uint) (bool, error) {
+	level, err := s.visibilityOf(ctx, resourceType, resourceID)
+	if err != nil {
+		return false, err
+	}
+	if level == groupmodels.VisibilityPublic {
+		return true, nil
+	}
+	levels, err := s.invisibleLevels(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	for _, invisibleLevel := range levels {
+		if level == invisibleLevel {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// visibilityOf returns the visibility level of the resource,
+// which is the most restrictive one among the resource and all its parents
+func (s *service) visibilityOf(ctx context.Context, resourceType string, resourceID uint) (string, error) {
+	switch resourceType {
+	case common.ResourceGroup:
+		return s.groupVisibility(ctx, resourceID)
+	case common.ResourceApplication:
+		application, err := s.applicationManager.GetByID(ctx, resourceID)
+		if err != nil {
+			return "", err
+		}
+		level, err := s.groupVisibility(ctx, application.GroupID)
+		if err != nil {
+			return "", err
+		}
+		return groupmodels.MoreRestrictive(level, application.VisibilityLevel), nil
+	case common.ResourceCluster:
+		cluster, err := s.applicationClusterManager.GetByID(ctx, resourceID)
+		if err != nil {
+			return "", err
+		}
+		return s.visibilityOf(ctx, common.ResourceApplication, cluster.ApplicationID)
+	case common.ResourcePipelinerun:
+		pipelinerun, err := s.prMgr.PipelineRun.GetByID(ctx, resourceID)
+		if err != nil {
+			return "", err
+		}
+		if pipelinerun == nil {
+			return groupmodels.VisibilityPublic, nil
+		}
+		return s.visibilityOf(ctx, common.ResourceCluster, pipelinerun.ClusterID)
+	case common.ResourceCheckrun:
+		checkrun, err := s.prMgr.Check.GetCheckRunByID(ctx, resourceID)
+		if err != nil {
+			return "", err
+		}
+		if checkrun == nil {
+			return groupmodels.VisibilityPublic, nil
+		}
+		return s.visibilityOf(ctx, common.ResourcePipelinerun, checkrun.PipelineRunID)
+	case common.ResourceMember:
+		member, err := s.memberManager.GetByID(ctx, resourceID)
+		if err != nil {
+			return "", err
+		}
+		return s.visibilityOf(ctx, string(member.ResourceType), member.ResourceID)
+	case common.ResourceWebhook:
+		webhook, err := s.webhookManager.GetWebhook(ctx, resourceID)
+		if err != nil {
+			return "", err
+		}
+		switch webhook.ResourceType {
+		case common.ResourceGroup, common.ResourceApplication, common.ResourceCluster:
+			return s.visibilityOf(ctx, webhook.ResourceType, webhook.ResourceID)
+		}
+	}
+	return groupmodels.VisibilityPublic, nil
+}
+
+// groupVisibility returns the visibility level of the group inherited from its parents
+func (s *service) groupVisibility(ctx context.Context, groupID uint) (string, error) {
+	if s.groupManager.IsRootGroup(ctx, groupID) {
+		return groupmodels.VisibilityPublic, nil
+	}
+	group, err := s.groupManager.GetByID(ctx, groupID)
+	if err != nil {
+		return "", err
+	}
+	groups, err := s.groupManager.GetByIDs(ctx, groupmanager.FormatIDsFromTraversalIDs(group.TraversalIDs))
+	if err != nil {
+		return "", err
+	}
+	level := groupmodels.VisibilityPublic
+	for _, item := range groups {
+		level = groupmodels.MoreRestrictive(level, item.VisibilityLevel)
+	}
+	return level, nil
+}
+
+func (s *service) ListInvisible(ctx context.Context) (*groupmodels.Invisible, error) {
+	currentUser, err := common.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if currentUser.IsAdmin() {
+		return nil, nil
+	}
+
+	levels, err := s.invisibleLevels(ctx, currentUser)
+	if err != nil {
+		return nil, err
+	}
+	invisible := &groupmodels.Invisible{Levels: levels}
+
+	teamIDs, err := s.teamManager.ListTeamIDsOfUser(ctx, currentUser.GetID())
+	if err != nil {
+		return nil, err
+	}
+	listAuthorized := func(resourceType models.ResourceType) ([]uint, error) {
+		ids, err := s.memberManager.ListResourceOfMemberInfo(ctx, resourceType, currentUser.GetID())
+		if err != nil {
+			return nil, err
+		}
+		idsOfTeams, err := s.memberManager.ListResourceOfTeams(ctx, resourceType, teamIDs)
+		if err != nil {
+			return nil, err
+		}
+		return append(ids, idsOfTeams...), nil
+	}
+
+	groupIDs, err := listAuthorized(models.TypeGroup)
+	if err != nil {
+		return nil, err
+	}
+	authorizedGroups := make(map[uint]struct{})
+	if len(groupIDs) != 0 {
+		groups, err := s.groupManager.GetSubGroupsByGroupIDs(ctx, groupIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			authorizedGroups[group.ID] = struct{}{}
+			invisible.AuthorizedGroupIDs = append(invisible.AuthorizedGroupIDs, group.ID)
+		}
+	}
+	if invisible.AuthorizedApplicationIDs, err = listAuthorized(models.TypeApplication); err != nil {
+		return nil, err
+	}
+	if invisible.AuthorizedClusterIDs, err = listAuthorized(models.TypeApplicationCluster); err != nil {
+		return nil, err
+	}
+
+	// the groups of invisible levels and their subgroups are invisible except the authorized ones
+	restrictedGroups, err := s.groupManager.ListByVisibilityLevels(ctx, levels)
+	if err != nil {
+		return nil, err
+	}
+	if len(restrictedGroups) != 0 {
+		restrictedIDs := make([]uint, 0, len(restrictedGroups))
+		for _, group := range restrictedGroups {
+			restrictedIDs = append(restrictedIDs, group.ID)
+		}
+		groups, err := s.groupManager.GetSubGroupsByGroupIDs(ctx, restrictedIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			if _, ok := authorizedGroups[group.ID]; !ok {
+				invisible.GroupIDs = append(invisible.GroupIDs, group.ID)
+			}
+		}
+	}
+	return invisible, nil
+}
@@ -76,6 +76,8 @@ func (d *dao) UpdateApp(ctx context.Context,
 		appInDb.HomeURL = app.HomeURL
 		appInDb.RedirectURL = app.RedirectURL
 		appInDb.Desc = app.Desc
+		appInDb.ClientType = app.ClientType
+		appInDb.Scopes = app.Scopes
 		appInDb.UpdatedBy = app.UpdatedBy
 		if err := tx.Save(&appInDb).Error; err != nil {
 			return herrors.NewErrUpdateFailed(herrors.OAuthInDB, err.Error())
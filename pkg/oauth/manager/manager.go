@@ -40,15 +40,26 @@ type AuthorizeGenerateRequest struct {
 	Scope        string
 	UserIdentify uint
 	Request      *http.Request
+
+	// CodeChallenge and CodeChallengeMethod are for PKCE
+	CodeChallenge       string
+	CodeChallengeMethod string
+	// Nonce is for OIDC
+	Nonce string
 }
 
 type OauthTokensRequest struct {
 	ClientID     string
 	ClientSecret string
 	Code         string // authorization code
+	CodeVerifier string // PKCE code verifier of the authorization code
 	RefreshToken string // refresh token
 	RedirectURL  string
 
+	// Scope and UserID are for the client credentials grant, the token acts as the user
+	Scope  string
+	UserID uint
+
 	Request *http.Request
 
 	AccessTokenGenerator  generator.CodeGenerator
@@ -58,6 +69,8 @@ type OauthTokensRequest struct {
 type OauthTokensResponse struct {
 	AccessToken  *tokenmodels.Token
 	RefreshToken *tokenmodels.Token
+	// Nonce is the OIDC nonce of the authorization code
+	Nonce string
 }
 
 type CreateOAuthAppReq struct {
@@ -68,6 +81,9 @@ type CreateOAuthAppReq struct {
 	OwnerType   models.OwnerType
 	OwnerID     uint
 	APPType     models.AppType
+	ClientType  models.ClientType
+	// Scopes are granted to the app in the client credentials grant
+	Scopes []string
 }
 
 type UpdateOauthAppReq struct {
@@ -75,6 +91,8 @@ type UpdateOauthAppReq struct {
 	HomeURL     string
 	RedirectURI string
 	Desc        string
+	ClientType  models.ClientType
+	Scopes      []string
 }
 
 type Manager interface {
@@ -88,9 +106,25 @@ type Manager interface {
 	DeleteSecret(ctx context.Context, ClientID string, clientSecretID uint) error
 	ListSecret(ctx context.Context, ClientID string) ([]models.OauthClientSecret, error)
 
+	// GenAuthorizeCode generates the authorization code, public clients must request it with a PKCE code challenge
 	GenAuthorizeCode(ctx context.Context, req *AuthorizeGenerateRequest) (*tokenmodels.Token, error)
+	// GenOauthTokens exchanges the authorization code for tokens, confidential clients are always checked
+	// by their secrets, while public clients are checked by PKCE only
 	GenOauthTokens(ctx context.Context, req *OauthTokensRequest) (*OauthTokensResponse, error)
+	// RefreshOauthTokens refreshes the tokens, public clients refresh without secrets
 	RefreshOauthTokens(ctx context.Context, req *OauthTokensRequest) (*OauthTokensResponse, error)
+
+	// AuthenticateClient checks the client secret of the confidential client and returns the app,
+	// the client should be authenticated before the following methods
+	AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*models.OauthApp, error)
+	// GenClientCredentialsToken generates an access token without refresh token for the client itself,
+	// the requested scopes must be granted to the app, all the granted ones are issued if none is requested
+	GenClientCredentialsToken(ctx context.Context, req *OauthTokensRequest) (*tokenmodels.Token, error)
+	// IntrospectToken returns the access or refresh token issued to the client, nil if the token is not active
+	IntrospectToken(ctx context.Context, clientID, code string) (*tokenmodels.Token, error)
+	// RevokeToken revokes the access or refresh token issued to the client, confidential clients are
+	// checked by their secrets, revoking a refresh token revokes its access token as well
+	RevokeToken(ctx context.Context, clientID, clientSecret, code string) error
 }
 
 var _ Manager = &OauthManager{}
@@ -145,6 +179,10 @@ func (m *OauthManager) CreateOauthApp(ctx context.Context, info *CreateOAuthAppR
 	if err != nil {
 		return nil, err
 	}
+	clientType, err := validateClientType(info.ClientType)
+	if err != nil {
+		return nil, err
+	}
 	clientID := m.clientIDGenerate(info.APPType)
 	oauthApp := models.OauthApp{
 		Name:        info.Name,
@@ -155,6 +193,8 @@ func (m *OauthManager) CreateOauthApp(ctx context.Context, info *CreateOAuthAppR
 		OwnerType:   info.OwnerType,
 		OwnerID:     info.OwnerID,
 		AppType:     info.APPType,
+		ClientType:  clientType,
+		Scopes:      strings.Join(info.Scopes, " "),
 		CreatedBy:   user.GetID(),
 		UpdatedBy:   user.GetID(),
 	}
@@ -193,15 +233,32 @@ func (m *OauthManager) UpdateOauthApp(ctx context.Context, clientID string,
 	if err != nil {
 		return nil, err
 	}
+	clientType, err := validateClientType(req.ClientType)
+	if err != nil {
+		return nil, err
+	}
 	return m.oauthAppDAO.UpdateApp(ctx, clientID, models.OauthApp{
 		Name:        req.Name,
 		RedirectURL: req.RedirectURI,
 		HomeURL:     req.HomeURL,
 		Desc:        req.Desc,
+		ClientType:  clientType,
+		Scopes:      strings.Join(req.Scopes, " "),
 		UpdatedBy:   user.GetID(),
 	})
 }
 
+// validateClientType returns the client type, apps are confidential by default
+func validateClientType(clientType models.ClientType) (models.ClientType, error) {
+	switch clientType {
+	case 0:
+		return models.ConfidentialClient, nil
+	case models.ConfidentialClient, models.PublicClient:
+		return clientType, nil
+	}
+	return 0, perror.Wrapf(herrors.ErrOAuthReqNotValid, "invalid client type: %d", clientType)
+}
+
 func (m *OauthManager) CreateSecret(ctx context.Context, clientID string) (*models.OauthClientSecret, error) {
 	user, err := common.UserFromContext(ctx)
 	if err != nil {
@@ -264,6 +321,10 @@ func (m *OauthManager) NewAuthorizationToken(req *AuthorizeGenerateRequest) *tok
 		ExpiresIn:   m.authorizeCodeExpireTime,
 		Scope:       req.Scope,
 		UserID:      req.UserIdentify,
+
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		Nonce:               req.Nonce,
 	}
 	token.Code = m.authorizationCodeGenerator.Generate(&generator.CodeGenerateInfo{
 		Token:   *token,
@@ -314,13 +375,25 @@ func (m *OauthManager) GenAuthorizeCode(ctx context.Context,
 		log.Warningf(ctx, "redirect URL not match")
 		return nil, perror.Wrapf(herrors.ErrOAuthReqNotValid, "redirect URL not match")
 	}
+	req.CodeChallengeMethod, err = validateCodeChallenge(req.CodeChallenge, req.CodeChallengeMethod)
+	if err != nil {
+		return nil, err
+	}
+	if oauthApp.IsPublicClient() && req.CodeChallenge == "" {
+		return nil, perror.Wrap(herrors.ErrOAuthReqNotValid, "code challenge is required for public clients")
+	}
 
 	authorizationToken := m.NewAuthorizationToken(req)
 	_, err = m.tokenStore.Create(ctx, authorizationToken)
 	return authorizationToken, err
 }
 
-func (m *OauthManager) checkByAuthorizationCode(req *OauthTokensRequest, codeToken *tokenmodels.Token) error {
+func (m *OauthManager) checkByAuthorizationCode(app *models.OauthApp, req *OauthTokensRequest,
+	codeToken *tokenmodels.Token) error {
+	if req.ClientID != codeToken.ClientID {
+		return perror.Wrapf(herrors.ErrOAuthReqNotValid,
+			"req client id = %s, code client id = %s", req.ClientID, codeToken.ClientID)
+	}
 	if req.RedirectURL != codeToken.RedirectURI {
 		return perror.Wrapf(herrors.ErrOAuthReqNotValid,
 			"req redirect url = %s, code redirect url = %s", req.RedirectURL, codeToken.RedirectURI)
@@ -328,12 +401,35 @@ func (m *OauthManager) checkByAuthorizationCode(req *OauthTokensRequest, codeTok
 	if codeToken.CreatedAt.Add(m.authorizeCodeExpireTime).Before(time.Now()) {
 		return perror.Wrap(herrors.ErrOAuthCodeExpired, "")
 	}
+	if codeToken.CodeChallenge != "" {
+		return verifyCodeChallenge(codeToken.CodeChallenge, codeToken.CodeChallengeMethod, req.CodeVerifier)
+	}
+	// public clients without secrets must prove themselves by PKCE
+	if app.IsPublicClient() {
+		return perror.Wrap(herrors.ErrOAuthReqNotValid, "code verifier is required for public clients")
+	}
 	return nil
 }
 
+// authenticateClient checks the client secret of confidential clients,
+// public clients are checked by PKCE or by the tokens issued to them instead
+func (m *OauthManager) authenticateClient(ctx context.Context, clientID, clientSecret string) (
+	*models.OauthApp, error) {
+	app, err := m.oauthAppDAO.GetApp(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if app.IsPublicClient() {
+		return app, nil
+	}
+	if err := m.checkClientSecret(ctx, clientID, clientSecret); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
 func (m *OauthManager) GenOauthTokens(ctx context.Context, req *OauthTokensRequest) (*OauthTokensResponse, error) {
-	// check client secret
-	err := m.checkClientSecret(ctx, req)
+	app, err := m.authenticateClient(ctx, req.ClientID, req.ClientSecret)
 	if err != nil {
 		return nil, err
 	}
@@ -347,7 +443,7 @@ func (m *OauthManager) GenOauthTokens(ctx context.Context, req *OauthTokensReque
 		return nil, err
 	}
 
-	if err := m.checkByAuthorizationCode(req, authorizationCodeToken); err != nil {
+	if err := m.checkByAuthorizationCode(app, req, authorizationCodeToken); err != nil {
 		if perror.Cause(err) == herrors.ErrOAuthCodeExpired {
 			if delErr := m.tokenStore.DeleteByCode(ctx, req.Code); delErr != nil {
 				log.Warningf(ctx, "delete expired code error, err = %v", delErr)
@@ -380,19 +476,19 @@ func (m *OauthManager) GenOauthTokens(ctx context.Context, req *OauthTokensReque
 	return &OauthTokensResponse{
 		AccessToken:  accessTokenInDB,
 		RefreshToken: refreshTokenInDB,
+		Nonce:        authorizationCodeToken.Nonce,
 	}, nil
 }
 
 func (m *OauthManager) RefreshOauthTokens(ctx context.Context,
 	req *OauthTokensRequest) (*OauthTokensResponse, error) {
-	// check client secret
-	err := m.checkClientSecret(ctx, req)
-	if err != nil {
+	// check client secret, public clients refresh without secrets
+	if _, err := m.authenticateClient(ctx, req.ClientID, req.ClientSecret); err != nil {
 		return nil, err
 	}
 
 	// check refresh token
-	refreshToken, err := m.checkRefreshToken(ctx, req.RefreshToken, req.RedirectURL)
+	refreshToken, err := m.checkRefreshToken(ctx, req.ClientID, req.RefreshToken, req.RedirectURL)
 	if err != nil {
 		return nil, err
 	}
@@ -419,22 +515,129 @@ func (m *OauthManager) RefreshOauthTokens(ctx context.Context,
 	}, nil
 }
 
-func (m *OauthManager) checkClientSecret(ctx context.Context, req *OauthTokensRequest) error {
-	secrets, err := m.oauthAppDAO.ListSecret(ctx, req.ClientID)
+func (m *OauthManager) checkClientSecret(ctx context.Context, clientID, clientSecret string) error {
+	secrets, err := m.oauthAppDAO.ListSecret(ctx, clientID)
 	if err != nil {
 		return err
 	}
 	for _, secret := range secrets {
-		if secret.ClientSecret == req.ClientSecret {
+		if secret.ClientSecret == clientSecret {
 			return nil
 		}
 	}
 	return perror.Wrapf(herrors.ErrOAuthSecretNotValid,
-		"clientId = %s, secret = %s", req.ClientID, req.ClientSecret)
+		"clientId = %s, secret = %s", clientID, clientSecret)
+}
+
+func (m *OauthManager) AuthenticateClient(ctx context.Context,
+	clientID, clientSecret string) (*models.OauthApp, error) {
+	app, err := m.oauthAppDAO.GetApp(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if app.IsPublicClient() {
+		return nil, perror.Wrapf(herrors.ErrOAuthSecretNotValid,
+			"public client %s can't be authenticated", clientID)
+	}
+	if err := m.checkClientSecret(ctx, clientID, clientSecret); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+func (m *OauthManager) GenClientCredentialsToken(ctx context.Context,
+	req *OauthTokensRequest) (*tokenmodels.Token, error) {
+	app, err := m.oauthAppDAO.GetApp(ctx, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := grantedScope(app, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+	// refresh token should not be included, ref: rfc6749 4.4.3
+	accessToken := m.NewAccessToken(&tokenmodels.Token{
+		Scope:  scope,
+		UserID: req.UserID,
+	}, req)
+	return m.tokenStore.Create(ctx, accessToken)
+}
+
+// grantedScope limits the requested scope to the scopes granted to the app, ref: rfc6749 3.3
+func grantedScope(app *models.OauthApp, scope string) (string, error) {
+	granted := strings.Fields(app.Scopes)
+	if len(granted) == 0 {
+		// an empty scope means the default scopes, which are not granted to the app either
+		return "", perror.Wrapf(herrors.ErrOAuthReqNotValid, "no scope is granted to client %s", app.ClientID)
+	}
+	requested := strings.Fields(scope)
+	if len(requested) == 0 {
+		return strings.Join(granted, " "), nil
+	}
+	for _, s := range requested {
+		found := false
+		for _, g := range granted {
+			if s == g {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", perror.Wrapf(herrors.ErrOAuthReqNotValid, "scope %s is not granted to client %s",
+				s, app.ClientID)
+		}
+	}
+	return strings.Join(requested, " "), nil
+}
+
+func (m *OauthManager) IntrospectToken(ctx context.Context, clientID, code string) (*tokenmodels.Token, error) {
+	token, err := m.getClientToken(ctx, clientID, code)
+	if err != nil || token == nil {
+		return nil, err
+	}
+	if token.ExpiresIn > 0 && token.CreatedAt.Add(token.ExpiresIn).Before(time.Now()) {
+		return nil, nil
+	}
+	return token, nil
+}
+
+func (m *OauthManager) RevokeToken(ctx context.Context, clientID, clientSecret, code string) error {
+	if _, err := m.authenticateClient(ctx, clientID, clientSecret); err != nil {
+		return err
+	}
+	token, err := m.getClientToken(ctx, clientID, code)
+	if err != nil || token == nil {
+		// invalid tokens do not cause an error, ref: rfc7009 2.2
+		return err
+	}
+	if token.RefID != 0 {
+		if err := m.tokenStore.DeleteByID(ctx, token.RefID); err != nil {
+			return err
+		}
+	}
+	return m.tokenStore.DeleteByID(ctx, token.ID)
+}
+
+// getClientToken returns the access or refresh token issued to the client, nil if there is not
+func (m *OauthManager) getClientToken(ctx context.Context, clientID, code string) (*tokenmodels.Token, error) {
+	if !generator.IsOauthToken(code) {
+		return nil, nil
+	}
+	token, err := m.tokenStore.GetByCode(ctx, code)
+	if err != nil {
+		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if token.ClientID != clientID {
+		return nil, nil
+	}
+	return token, nil
 }
 
 func (m *OauthManager) checkRefreshToken(ctx context.Context,
-	refreshToken, redirectURL string) (*tokenmodels.Token, error) {
+	clientID, refreshToken, redirectURL string) (*tokenmodels.Token, error) {
 	token, err := m.tokenStore.GetByCode(ctx, refreshToken)
 	if err != nil {
 		if _, ok := perror.Cause(err).(*herrors.HorizonErrNotFound); ok {
@@ -442,6 +645,10 @@ func (m *OauthManager) checkRefreshToken(ctx context.Context,
 		}
 		return nil, err
 	}
+	if clientID != token.ClientID {
+		return nil, perror.Wrapf(herrors.ErrOAuthReqNotValid,
+			"req client id = %s, token client id = %s", clientID, token.ClientID)
+	}
 	if redirectURL != token.RedirectURI {
 		return nil, perror.Wrapf(herrors.ErrOAuthReqNotValid,
 			"req redirect url = %s, token redirect url = %s", redirectURL, token.RedirectURI)
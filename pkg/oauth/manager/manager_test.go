@@ -513,6 +513,211 @@ func TestUpdateToken(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestPKCE(t *testing.T) {
+	oauthApp, err := oauthManager.CreateOauthApp(ctx, &CreateOAuthAppReq{
+		Name:        "PKCETest",
+		RedirectURI: "https://example.com/oauth/redirect",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     1,
+		APPType:     models.DirectOAuthAPP,
+		ClientType:  models.PublicClient,
+	})
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, oauthApp.ClientID))
+	}()
+
+	// public clients must request codes with code challenges
+	codeReq := &AuthorizeGenerateRequest{
+		ClientID:     oauthApp.ClientID,
+		RedirectURL:  oauthApp.RedirectURL,
+		UserIdentify: 43,
+	}
+	_, err = oauthManager.GenAuthorizeCode(ctx, codeReq)
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	codeReq.CodeChallenge, codeReq.CodeChallengeMethod = challenge, "unknown"
+	_, err = oauthManager.GenAuthorizeCode(ctx, codeReq)
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+
+	codeReq.CodeChallengeMethod = CodeChallengeMethodS256
+	codeReq.Scope = ScopeOpenID
+	codeReq.Nonce = "n-0S6_WzA2Mj"
+	codeToken, err := oauthManager.GenAuthorizeCode(ctx, codeReq)
+	assert.Nil(t, err)
+	assert.Equal(t, challenge, codeToken.CodeChallenge)
+
+	// public clients exchange the code without secret
+	tokensReq := &OauthTokensRequest{
+		ClientID:              oauthApp.ClientID,
+		Code:                  codeToken.Code,
+		CodeVerifier:          verifier[1:] + "a",
+		RedirectURL:           oauthApp.RedirectURL,
+		AccessTokenGenerator:  generator.NewOauthAccessGenerator(),
+		RefreshTokenGenerator: generator.NewRefreshTokenGenerator(),
+	}
+	_, err = oauthManager.GenOauthTokens(ctx, tokensReq)
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+	tokensReq.CodeVerifier = verifier
+	tokens, err := oauthManager.GenOauthTokens(ctx, tokensReq)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(43), tokens.AccessToken.UserID)
+	assert.Equal(t, "n-0S6_WzA2Mj", tokens.Nonce)
+	assert.True(t, IsOpenIDScope(tokens.AccessToken.Scope))
+
+	// and refresh without secret, but only their own tokens
+	refreshed, err := oauthManager.RefreshOauthTokens(ctx, &OauthTokensRequest{
+		ClientID:              oauthApp.ClientID,
+		RefreshToken:          tokens.RefreshToken.Code,
+		RedirectURL:           oauthApp.RedirectURL,
+		AccessTokenGenerator:  generator.NewOauthAccessGenerator(),
+		RefreshTokenGenerator: generator.NewRefreshTokenGenerator(),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, uint(43), refreshed.AccessToken.UserID)
+
+	// public clients can't be authenticated for the client credentials grant
+	secret, err := oauthManager.CreateSecret(ctx, oauthApp.ClientID)
+	assert.Nil(t, err)
+	_, err = oauthManager.AuthenticateClient(ctx, oauthApp.ClientID, secret.ClientSecret)
+	assert.Equal(t, herrors.ErrOAuthSecretNotValid, perror.Cause(err))
+
+	// confidential clients are checked by secrets even with PKCE
+	confidentialApp, err := oauthManager.CreateOauthApp(ctx, &CreateOAuthAppReq{
+		Name:        "PKCEConfidentialTest",
+		RedirectURI: "https://example.com/oauth/redirect",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     1,
+		APPType:     models.DirectOAuthAPP,
+	})
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, confidentialApp.ClientID))
+	}()
+	assert.Equal(t, models.ConfidentialClient, confidentialApp.ClientType)
+	codeReq.ClientID = confidentialApp.ClientID
+	codeToken, err = oauthManager.GenAuthorizeCode(ctx, codeReq)
+	assert.Nil(t, err)
+	tokensReq.ClientID, tokensReq.Code = confidentialApp.ClientID, codeToken.Code
+	_, err = oauthManager.GenOauthTokens(ctx, tokensReq)
+	assert.Equal(t, herrors.ErrOAuthSecretNotValid, perror.Cause(err))
+	_, err = oauthManager.RefreshOauthTokens(ctx, &OauthTokensRequest{
+		ClientID:              confidentialApp.ClientID,
+		RefreshToken:          refreshed.RefreshToken.Code,
+		RedirectURL:           oauthApp.RedirectURL,
+		AccessTokenGenerator:  generator.NewOauthAccessGenerator(),
+		RefreshTokenGenerator: generator.NewRefreshTokenGenerator(),
+	})
+	assert.Equal(t, herrors.ErrOAuthSecretNotValid, perror.Cause(err))
+}
+
+func TestClientCredentialsAndIntrospection(t *testing.T) {
+	oauthApp, err := oauthManager.CreateOauthApp(ctx, &CreateOAuthAppReq{
+		Name:        "ClientCredentialsTest",
+		RedirectURI: "https://example.com/oauth/redirect",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     1,
+		APPType:     models.DirectOAuthAPP,
+		Scopes:      []string{"applications:read-only", "clusters:read-only"},
+	})
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, oauthApp.ClientID))
+	}()
+	secret, err := oauthManager.CreateSecret(ctx, oauthApp.ClientID)
+	assert.Nil(t, err)
+
+	_, err = oauthManager.AuthenticateClient(ctx, oauthApp.ClientID, "err-secret")
+	assert.Equal(t, herrors.ErrOAuthSecretNotValid, perror.Cause(err))
+	app, err := oauthManager.AuthenticateClient(ctx, oauthApp.ClientID, secret.ClientSecret)
+	assert.Nil(t, err)
+	assert.Equal(t, oauthApp.ID, app.ID)
+
+	// the scopes are limited to the granted ones
+	credentialsReq := &OauthTokensRequest{
+		ClientID:             oauthApp.ClientID,
+		Scope:                "applications:read-write",
+		UserID:               44,
+		AccessTokenGenerator: generator.NewOauthAccessGenerator(),
+	}
+	_, err = oauthManager.GenClientCredentialsToken(ctx, credentialsReq)
+	assert.Equal(t, herrors.ErrOAuthReqNotValid, perror.Cause(err))
+	credentialsReq.Scope = ""
+	grantedToken, err := oauthManager.GenClientCredentialsToken(ctx, credentialsReq)
+	assert.Nil(t, err)
+	assert.Equal(t, "applications:read-only clusters:read-only", grantedToken.Scope)
+	credentialsReq.Scope = "applications:read-only"
+	accessToken, err := oauthManager.GenClientCredentialsToken(ctx, credentialsReq)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(44), accessToken.UserID)
+	assert.Equal(t, "applications:read-only", accessToken.Scope)
+	assert.Equal(t, accessTokenExpireIn, accessToken.ExpiresIn)
+
+	// only the tokens of the client are active
+	token, err := oauthManager.IntrospectToken(ctx, oauthApp.ClientID, accessToken.Code)
+	assert.Nil(t, err)
+	assert.Equal(t, accessToken.ID, token.ID)
+	token, err = oauthManager.IntrospectToken(ctx, "other-client", accessToken.Code)
+	assert.Nil(t, err)
+	assert.Nil(t, token)
+	token, err = oauthManager.IntrospectToken(ctx, oauthApp.ClientID, "ho_not-exist")
+	assert.Nil(t, err)
+	assert.Nil(t, token)
+
+	// revoking a refresh token revokes its access token
+	codeToken, err := oauthManager.GenAuthorizeCode(ctx, &AuthorizeGenerateRequest{
+		ClientID:     oauthApp.ClientID,
+		RedirectURL:  oauthApp.RedirectURL,
+		UserIdentify: 44,
+	})
+	assert.Nil(t, err)
+	tokens, err := oauthManager.GenOauthTokens(ctx, &OauthTokensRequest{
+		ClientID:              oauthApp.ClientID,
+		ClientSecret:          secret.ClientSecret,
+		Code:                  codeToken.Code,
+		RedirectURL:           oauthApp.RedirectURL,
+		AccessTokenGenerator:  generator.NewOauthAccessGenerator(),
+		RefreshTokenGenerator: generator.NewRefreshTokenGenerator(),
+	})
+	assert.Nil(t, err)
+	otherApp, err := oauthManager.CreateOauthApp(ctx, &CreateOAuthAppReq{
+		Name:        "ClientCredentialsOtherTest",
+		RedirectURI: "https://example.com/oauth/redirect",
+		OwnerType:   models.GroupOwnerType,
+		OwnerID:     1,
+		APPType:     models.DirectOAuthAPP,
+	})
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, oauthManager.DeleteOAuthApp(ctx, otherApp.ClientID))
+	}()
+	otherSecret, err := oauthManager.CreateSecret(ctx, otherApp.ClientID)
+	assert.Nil(t, err)
+	assert.Nil(t, oauthManager.RevokeToken(ctx, otherApp.ClientID, otherSecret.ClientSecret,
+		tokens.RefreshToken.Code))
+	_, err = tokenManager.LoadTokenByCode(ctx, tokens.RefreshToken.Code)
+	assert.Nil(t, err)
+	// confidential clients can't revoke without secrets
+	err = oauthManager.RevokeToken(ctx, oauthApp.ClientID, "", tokens.RefreshToken.Code)
+	assert.Equal(t, herrors.ErrOAuthSecretNotValid, perror.Cause(err))
+	_, err = tokenManager.LoadTokenByCode(ctx, tokens.RefreshToken.Code)
+	assert.Nil(t, err)
+	assert.Nil(t, oauthManager.RevokeToken(ctx, oauthApp.ClientID, secret.ClientSecret, tokens.RefreshToken.Code))
+	for _, code := range []string{tokens.RefreshToken.Code, tokens.AccessToken.Code} {
+		_, err = tokenManager.LoadTokenByCode(ctx, code)
+		_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+		assert.True(t, ok)
+	}
+
+	// expired tokens are not active
+	time.Sleep(accessTokenExpireIn)
+	token, err = oauthManager.IntrospectToken(ctx, oauthApp.ClientID, accessToken.Code)
+	assert.Nil(t, err)
+	assert.Nil(t, token)
+}
+
 func TestMain(m *testing.M) {
 	db, _ = orm.NewSqliteDB("")
 	if err := db.AutoMigrate(&tokenmodels.Token{}, &models.OauthApp{}, &models.OauthClientSecret{}); err != nil {
@@ -0,0 +1,69 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"regexp"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// PKCE, ref: rfc7636
+const (
+	CodeChallengeMethodPlain = "plain"
+	CodeChallengeMethodS256  = "S256"
+)
+
+// codeVerifierPattern is the format of code verifiers, and of the code challenges as well
+var codeVerifierPattern = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+// validateCodeChallenge checks the code challenge and returns its method, which defaults to plain
+func validateCodeChallenge(challenge, method string) (string, error) {
+	if challenge == "" {
+		if method != "" {
+			return "", perror.Wrap(herrors.ErrOAuthReqNotValid, "code challenge method without code challenge")
+		}
+		return "", nil
+	}
+	if method == "" {
+		method = CodeChallengeMethodPlain
+	}
+	if method != CodeChallengeMethodPlain && method != CodeChallengeMethodS256 {
+		return "", perror.Wrapf(herrors.ErrOAuthReqNotValid, "code challenge method %s not supported", method)
+	}
+	if !codeVerifierPattern.MatchString(challenge) {
+		return "", perror.Wrap(herrors.ErrOAuthReqNotValid, "code challenge format error")
+	}
+	return method, nil
+}
+
+func verifyCodeChallenge(challenge, method, verifier string) error {
+	if !codeVerifierPattern.MatchString(verifier) {
+		return perror.Wrap(herrors.ErrOAuthReqNotValid, "code verifier format error")
+	}
+	expected := verifier
+	if method == CodeChallengeMethodS256 {
+		sum := sha256.Sum256([]byte(verifier))
+		expected = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) != 1 {
+		return perror.Wrap(herrors.ErrOAuthReqNotValid, "code verifier not match")
+	}
+	return nil
+}
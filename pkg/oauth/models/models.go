@@ -38,6 +38,16 @@ const (
 	DirectOAuthAPP  AppType = 2
 )
 
+// ClientType ref: rfc6749 2.1
+type ClientType uint8
+
+const (
+	// ConfidentialClient authenticates itself by the client secret on every grant
+	ConfidentialClient ClientType = 1
+	// PublicClient can't keep secrets, it proves itself by PKCE instead
+	PublicClient ClientType = 2
+)
+
 type OauthApp struct {
 	ID          uint      `gorm:"primarykey"`
 	Name        string    `gorm:"column:name"`
@@ -48,6 +58,10 @@ type OauthApp struct {
 	OwnerType   OwnerType `gorm:"column:owner_type"`
 	OwnerID     uint      `gorm:"column:owner_id"`
 	AppType     AppType   `gorm:"column:app_type"`
+	// ClientType is ConfidentialClient if not set
+	ClientType ClientType `gorm:"column:client_type"`
+	// Scopes are the scopes granted to the app in the client credentials grant, separated by spaces
+	Scopes string `gorm:"column:scopes"`
 
 	CreatedAt time.Time `gorm:"column:created_at"`
 	CreatedBy uint      `gorm:"column:created_by"`
@@ -60,6 +74,10 @@ func (a *OauthApp) IsGroupOwnerType() bool {
 	return a.OwnerType == GroupOwnerType
 }
 
+func (a *OauthApp) IsPublicClient() bool {
+	return a.ClientType == PublicClient
+}
+
 type OauthClientSecret struct {
 	ID           uint      `gorm:"column:id" json:"id"`
 	ClientID     string    `gorm:"column:client_id" json:"clientID"`
@@ -19,23 +19,32 @@ import (
 
 	collectionmanager "github.com/horizoncd/horizon/pkg/collection/manager"
 
+	accessauditmanager "github.com/horizoncd/horizon/pkg/accessaudit/manager"
 	accesstokenmanager "github.com/horizoncd/horizon/pkg/accesstoken/manager"
+	admissionpolicymanager "github.com/horizoncd/horizon/pkg/admissionpolicy/manager"
 	applicationmanager "github.com/horizoncd/horizon/pkg/application/manager"
 	applicationregionmanager "github.com/horizoncd/horizon/pkg/applicationregion/manager"
 	badgemanager "github.com/horizoncd/horizon/pkg/badge/manager"
 	clustermanager "github.com/horizoncd/horizon/pkg/cluster/manager"
+	deploywindowmanager "github.com/horizoncd/horizon/pkg/deploywindow/manager"
 	envmanager "github.com/horizoncd/horizon/pkg/environment/manager"
 	environmentregionmanager "github.com/horizoncd/horizon/pkg/environmentregion/manager"
 	eventManager "github.com/horizoncd/horizon/pkg/event/manager"
 	groupmanager "github.com/horizoncd/horizon/pkg/group/manager"
+	hookmanager "github.com/horizoncd/horizon/pkg/hook/manager"
 	idpmanager "github.com/horizoncd/horizon/pkg/idp/manager"
+	imagepolicymanager "github.com/horizoncd/horizon/pkg/imagepolicy/manager"
 	membermanager "github.com/horizoncd/horizon/pkg/member"
 	prmanager "github.com/horizoncd/horizon/pkg/pr/manager"
 	pipelinemanager "github.com/horizoncd/horizon/pkg/pr/pipeline/manager"
+	rolemanager "github.com/horizoncd/horizon/pkg/rbac/role/manager"
 	regionmanager "github.com/horizoncd/horizon/pkg/region/manager"
 	registrymanager "github.com/horizoncd/horizon/pkg/registry/manager"
+	releasetrainmanager "github.com/horizoncd/horizon/pkg/releasetrain/manager"
 	tagmanager "github.com/horizoncd/horizon/pkg/tag/manager"
+	teammanager "github.com/horizoncd/horizon/pkg/team/manager"
 	templatemanager "github.com/horizoncd/horizon/pkg/template/manager"
+	templatemigrationmanager "github.com/horizoncd/horizon/pkg/templatemigration/manager"
 	trmanager "github.com/horizoncd/horizon/pkg/templaterelease/manager"
 	templateschematagmanager "github.com/horizoncd/horizon/pkg/templateschematag/manager"
 	trtmanager "github.com/horizoncd/horizon/pkg/templateschematag/manager"
@@ -72,6 +81,15 @@ type Manager struct {
 	EventMgr             eventManager.Manager
 	TokenMgr             tokenmanager.Manager
 	BadgeMgr             badgemanager.Manager
+	AdmissionPolicyMgr   admissionpolicymanager.Manager
+	DeployWindowMgr      deploywindowmanager.Manager
+	ReleaseTrainMgr      releasetrainmanager.Manager
+	TeamMgr              teammanager.Manager
+	RoleMgr              rolemanager.Manager
+	AccessAuditMgr       accessauditmanager.Manager
+	ImagePolicyMgr       imagepolicymanager.Manager
+	HookMgr              hookmanager.Manager
+	TemplateMigrationMgr templatemigrationmanager.Manager
 }
 
 func InitManager(db *gorm.DB) *Manager {
@@ -102,5 +120,14 @@ func InitManager(db *gorm.DB) *Manager {
 		EventMgr:             eventManager.New(db),
 		TokenMgr:             tokenmanager.New(db),
 		BadgeMgr:             badgemanager.New(db),
+		AdmissionPolicyMgr:   admissionpolicymanager.New(db),
+		DeployWindowMgr:      deploywindowmanager.New(db),
+		ReleaseTrainMgr:      releasetrainmanager.New(db),
+		TeamMgr:              teammanager.New(db),
+		RoleMgr:              rolemanager.New(db),
+		AccessAuditMgr:       accessauditmanager.New(db),
+		ImagePolicyMgr:       imagepolicymanager.New(db),
+		HookMgr:              hookmanager.New(db),
+		TemplateMigrationMgr: templatemigrationmanager.New(db),
 	}
 }
@@ -35,9 +35,11 @@ import (
 	tokenservice "github.com/horizoncd/horizon/pkg/token/service"
 
 	"github.com/horizoncd/horizon/core/controller/build"
+	deploywindowservice "github.com/horizoncd/horizon/pkg/deploywindow/service"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/templaterelease/output"
 	templateschema "github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	templatevalidate "github.com/horizoncd/horizon/pkg/templaterelease/validate"
 	userservice "github.com/horizoncd/horizon/pkg/user/service"
 )
 
@@ -47,23 +49,25 @@ type Param struct {
 
 	OauthManager oauthmanager.Manager
 	// service
-	AutoFreeSvc    *service.AutoFreeSVC
-	MemberService  memberservice.Service
-	ApplicationSvc applicationservice.Service
-	ClusterSvc     clusterservice.Service
-	GroupSvc       groupsvc.Service
-	EventSvc       eventservice.Service
-	UserSvc        userservice.Service
-	TokenSvc       tokenservice.Service
-	RoleService    role.Service
-	PRService      *prservice.Service
-	ScopeService   scope.Service
-	GrafanaService grafana.Service
+	AutoFreeSvc     *service.AutoFreeSVC
+	MemberService   memberservice.Service
+	ApplicationSvc  applicationservice.Service
+	ClusterSvc      clusterservice.Service
+	GroupSvc        groupsvc.Service
+	EventSvc        eventservice.Service
+	UserSvc         userservice.Service
+	TokenSvc        tokenservice.Service
+	RoleService     role.Service
+	PRService       *prservice.Service
+	ScopeService    scope.Service
+	GrafanaService  grafana.Service
+	DeployWindowSvc deploywindowservice.Service
 
 	// others
 	Hook                 hook.Hook
 	ApplicationGitRepo   applicationgitrepo.ApplicationGitRepo
 	TemplateSchemaGetter templateschema.Getter
+	TemplateValidator    templatevalidate.Validator
 	CD                   cd.CD
 	K8sUtil              cd.K8sUtil
 	OutputGetter         output.Getter
@@ -47,6 +47,9 @@ type PipelineRunDAO interface {
 	GetLatestSuccessByClusterID(ctx context.Context, clusterID uint) (*models.Pipelinerun, error)
 	GetFirstCanRollbackPipelinerun(ctx context.Context, clusterID uint) (*models.Pipelinerun, error)
 	UpdateColumns(ctx context.Context, id uint, columns map[string]interface{}) error
+	ListScheduled(ctx context.Context, before time.Time) ([]*models.Pipelinerun, error)
+	ListUnfinishedBuilds(ctx context.Context, since time.Time) ([]*models.Pipelinerun, error)
+	ListDeploys(ctx context.Context, query *models.DeployQuery) ([]*models.Deploy, error)
 }
 
 type pipelinerunDAO struct{ db *gorm.DB }
@@ -250,3 +253,62 @@ func (d *pipelinerunDAO) UpdateColumns(ctx context.Context, id uint, columns map
 	}
 	return res.Error
 }
+
+func (d *pipelinerunDAO) ListScheduled(ctx context.Context, before time.Time) ([]*models.Pipelinerun, error) {
+	var pipelineruns []*models.Pipelinerun
+	result := d.db.WithContext(ctx).
+		Where("scheduled_at <= ?", before).
+		Where("status in ?", []string{string(models.StatusPending), string(models.StatusReady)}).
+		Order("scheduled_at").
+		Find(&pipelineruns)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.PipelinerunInDB, result.Error.Error())
+	}
+	return pipelineruns, nil
+}
+
+func (d *pipelinerunDAO) ListUnfinishedBuilds(ctx context.Context, since time.Time) ([]*models.Pipelinerun, error) {
+	var pipelineruns []*models.Pipelinerun
+	result := d.db.WithContext(ctx).
+		Where("action = ?", models.ActionBuildDeploy).
+		Where("ci_event_id != ''").
+		Where("finished_at is null").
+		Where("created_at >= ?", since).
+		Order("created_at").
+		Find(&pipelineruns)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.PipelinerunInDB, result.Error.Error())
+	}
+	return pipelineruns, nil
+}
+
+// _taskDeploy is the task of the pipeline deploying the cluster after the image is built
+const _taskDeploy = "deploy"
+
+func (d *pipelinerunDAO) ListDeploys(ctx context.Context, query *models.DeployQuery) ([]*models.Deploy, error) {
+	var deploys []*models.Deploy
+	// the pipelineruns of deleted clusters are included, they were deployed as well.
+	// a builddeploy fails when deploying only if its deploy task fails, the others always deploy
+	statement := d.db.WithContext(ctx).Table("tb_pipelinerun").
+		Select("tb_pipelinerun.*, tb_cluster.application_id, "+
+			"case when tb_pipelinerun.action = ? then exists (select 1 from tb_task "+
+			"where tb_task.pipelinerun_id = tb_pipelinerun.id and tb_task.task = ? and tb_task.result = ?) "+
+			"else tb_pipelinerun.status = ? end as deploy_failed",
+			models.ActionBuildDeploy, _taskDeploy, string(models.StatusFailed), string(models.StatusFailed)).
+		Joins("join tb_cluster on tb_cluster.id = tb_pipelinerun.cluster_id").
+		Where("tb_pipelinerun.action in ?", []string{models.ActionBuildDeploy, models.ActionDeploy,
+			models.ActionRollback, models.ActionRestart}).
+		Where("tb_pipelinerun.status in ?", []string{string(models.StatusOK), string(models.StatusFailed)}).
+		Where("tb_pipelinerun.finished_at >= ? and tb_pipelinerun.finished_at < ?", query.Since, query.Until)
+	if query.ApplicationIDs != nil {
+		statement = statement.Where("tb_cluster.application_id in ?", query.ApplicationIDs)
+	}
+	if query.EnvironmentName != "" {
+		statement = statement.Where("tb_cluster.environment_name = ?", query.EnvironmentName)
+	}
+	result := statement.Order("tb_pipelinerun.finished_at").Scan(&deploys)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.PipelinerunInDB, result.Error.Error())
+	}
+	return deploys, nil
+}
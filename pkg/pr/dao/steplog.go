@@ -0,0 +1,126 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+const _createBatchSize = 100
+
+// _likeEscaper escapes the wildcards of like with "!", which works in both mysql and sqlite
+var _likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+type StepLogDAO interface {
+	// Create creates the step logs of a pipelinerun
+	Create(ctx context.Context, stepLogs []*models.StepLog) error
+	// ListByPipelinerunID lists the step logs of a pipelinerun order by sequence
+	ListByPipelinerunID(ctx context.Context, pipelinerunID uint) ([]*models.StepLog, error)
+	// Search lists the step logs matching search, latest first
+	Search(ctx context.Context, search *models.StepLogSearch, query *q.Query) (int, []*models.StepLog, error)
+	// DeleteBefore deletes at most limit step logs created before the time, it returns the count of deleted
+	DeleteBefore(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+type stepLogDAO struct{ db *gorm.DB }
+
+func NewStepLogDAO(db *gorm.DB) StepLogDAO {
+	return &stepLogDAO{db: db}
+}
+
+func (d *stepLogDAO) Create(ctx context.Context, stepLogs []*models.StepLog) error {
+	if len(stepLogs) == 0 {
+		return nil
+	}
+	result := d.db.WithContext(ctx).CreateInBatches(stepLogs, _createBatchSize)
+	if result.Error != nil {
+		return herrors.NewErrInsertFailed(herrors.StepLogInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *stepLogDAO) ListByPipelinerunID(ctx context.Context, pipelinerunID uint) ([]*models.StepLog, error) {
+	var stepLogs []*models.StepLog
+	result := d.db.WithContext(ctx).Where("pipelinerun_id = ?", pipelinerunID).
+		Order("sequence asc").Find(&stepLogs)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.StepLogInDB, result.Error.Error())
+	}
+	return stepLogs, nil
+}
+
+func (d *stepLogDAO) Search(ctx context.Context, search *models.StepLogSearch,
+	query *q.Query) (int, []*models.StepLog, error) {
+	var (
+		total    int64
+		stepLogs []*models.StepLog
+	)
+	statement := d.db.WithContext(ctx).Model(&models.StepLog{})
+	if search.ApplicationID != 0 {
+		statement = statement.Where("application_id = ?", search.ApplicationID)
+	}
+	if search.ClusterID != 0 {
+		statement = statement.Where("cluster_id = ?", search.ClusterID)
+	}
+	if search.Task != "" {
+		statement = statement.Where("task = ?", search.Task)
+	}
+	if search.Step != "" {
+		statement = statement.Where("step = ?", search.Step)
+	}
+	if !search.Since.IsZero() {
+		statement = statement.Where("created_at >= ?", search.Since)
+	}
+	if !search.Until.IsZero() {
+		statement = statement.Where("created_at < ?", search.Until)
+	}
+	if search.Keyword != "" {
+		statement = statement.Where("content like ? escape '!'", "%"+_likeEscaper.Replace(search.Keyword)+"%")
+	}
+	if err := statement.Count(&total).Error; err != nil {
+		return 0, nil, herrors.NewErrGetFailed(herrors.StepLogInDB, err.Error())
+	}
+	result := statement.Order("id desc").Limit(query.Limit()).Offset(query.Offset()).Find(&stepLogs)
+	if result.Error != nil {
+		return 0, nil, herrors.NewErrGetFailed(herrors.StepLogInDB, result.Error.Error())
+	}
+	return int(total), stepLogs, nil
+}
+
+func (d *stepLogDAO) DeleteBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	var ids []uint
+	result := d.db.WithContext(ctx).Model(&models.StepLog{}).Where("created_at < ?", before).
+		Order("id asc").Limit(limit).Pluck("id", &ids)
+	if result.Error != nil {
+		return 0, herrors.NewErrGetFailed(herrors.StepLogInDB, result.Error.Error())
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	// logs are large, so they are deleted physically
+	result = d.db.WithContext(ctx).Unscoped().Where("id in ?", ids).Delete(&models.StepLog{})
+	if result.Error != nil {
+		return 0, herrors.NewErrDeleteFailed(herrors.StepLogInDB, result.Error.Error())
+	}
+	return int(result.RowsAffected), nil
+}
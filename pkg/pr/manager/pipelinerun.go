@@ -16,6 +16,7 @@ package manager
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -48,6 +49,12 @@ type PipelineRunManager interface {
 	// UpdateResultByID  update the pipelinerun restore result
 	UpdateResultByID(ctx context.Context, pipelinerunID uint, result *models.Result) error
 	UpdateColumns(ctx context.Context, pipelinerunID uint, columns map[string]interface{}) error
+	// ListScheduled lists the pending or ready pipelineruns scheduled before the time
+	ListScheduled(ctx context.Context, before time.Time) ([]*models.Pipelinerun, error)
+	// ListUnfinishedBuilds lists the builddeploy pipelineruns created since the time whose builds are not collected
+	ListUnfinishedBuilds(ctx context.Context, since time.Time) ([]*models.Pipelinerun, error)
+	// ListDeploys lists the finished pipelineruns deploying the clusters matching query, order by finished_at
+	ListDeploys(ctx context.Context, query *models.DeployQuery) ([]*models.Deploy, error)
 }
 
 type pipelinerunManager struct {
@@ -131,3 +138,16 @@ func (m *pipelinerunManager) UpdateColumns(ctx context.Context,
 	pipelinerunID uint, columns map[string]interface{}) error {
 	return m.dao.UpdateColumns(ctx, pipelinerunID, columns)
 }
+
+func (m *pipelinerunManager) ListScheduled(ctx context.Context, before time.Time) ([]*models.Pipelinerun, error) {
+	return m.dao.ListScheduled(ctx, before)
+}
+
+func (m *pipelinerunManager) ListUnfinishedBuilds(ctx context.Context,
+	since time.Time) ([]*models.Pipelinerun, error) {
+	return m.dao.ListUnfinishedBuilds(ctx, since)
+}
+
+func (m *pipelinerunManager) ListDeploys(ctx context.Context, query *models.DeployQuery) ([]*models.Deploy, error) {
+	return m.dao.ListDeploys(ctx, query)
+}
@@ -257,9 +257,28 @@ func TestGetFirstCanRollbackPipelinerun(t *testing.T) {
 	assert.Nil(t, pipelinerun)
 }
 
+func TestListUnfinishedBuilds(t *testing.T) {
+	now := time.Now()
+	for _, pr := range []*models.Pipelinerun{
+		{ID: 101, ClusterID: 100, Action: models.ActionBuildDeploy, CIEventID: "101"},
+		{ID: 102, ClusterID: 100, Action: models.ActionBuildDeploy, CIEventID: "102", FinishedAt: &now},
+		{ID: 103, ClusterID: 100, Action: models.ActionBuildDeploy},
+		{ID: 104, ClusterID: 100, Action: models.ActionBuildDeploy, CIEventID: "104",
+			CreatedAt: now.Add(-2 * time.Hour)},
+	} {
+		_, err := mgr.Create(ctx, pr)
+		assert.Nil(t, err)
+	}
+
+	pipelineruns, err := mgr.ListUnfinishedBuilds(ctx, now.Add(-time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pipelineruns))
+	assert.Equal(t, uint(101), pipelineruns[0].ID)
+}
+
 func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&models.Pipelinerun{}, &models.Check{},
-		&models.CheckRun{}, &models.PRMessage{}); err != nil {
+		&models.CheckRun{}, &models.PRMessage{}, &models.StepLog{}); err != nil {
 		panic(err)
 	}
 	ctx = context.TODO()
@@ -0,0 +1,67 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/pr/dao"
+	"github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+type StepLogManager interface {
+	// Create creates the step logs of a pipelinerun
+	Create(ctx context.Context, stepLogs []*models.StepLog) error
+	// ListByPipelinerunID lists the step logs of a pipelinerun order by sequence
+	ListByPipelinerunID(ctx context.Context, pipelinerunID uint) ([]*models.StepLog, error)
+	// Search lists the step logs matching search, latest first
+	Search(ctx context.Context, search *models.StepLogSearch, query *q.Query) (int, []*models.StepLog, error)
+	// DeleteBefore deletes at most limit step logs created before the time, it returns the count of deleted
+	DeleteBefore(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+type stepLogManager struct {
+	dao dao.StepLogDAO
+}
+
+func NewStepLogManager(db *gorm.DB) StepLogManager {
+	return &stepLogManager{
+		dao: dao.NewStepLogDAO(db),
+	}
+}
+
+func (m *stepLogManager) Create(ctx context.Context, stepLogs []*models.StepLog) error {
+	return m.dao.Create(ctx, stepLogs)
+}
+
+func (m *stepLogManager) ListByPipelinerunID(ctx context.Context, pipelinerunID uint) ([]*models.StepLog, error) {
+	return m.dao.ListByPipelinerunID(ctx, pipelinerunID)
+}
+
+func (m *stepLogManager) Search(ctx context.Context, search *models.StepLogSearch,
+	query *q.Query) (int, []*models.StepLog, error) {
+	if query == nil {
+		query = &q.Query{}
+	}
+	return m.dao.Search(ctx, search, query)
+}
+
+func (m *stepLogManager) DeleteBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	return m.dao.DeleteBefore(ctx, before, limit)
+}
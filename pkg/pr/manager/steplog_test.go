@@ -0,0 +1,83 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/pr/models"
+)
+
+func TestStepLog(t *testing.T) {
+	stepLogManager := NewStepLogManager(db)
+	ctx := context.Background()
+
+	assert.Nil(t, stepLogManager.Create(ctx, nil))
+	assert.Nil(t, stepLogManager.Create(ctx, []*models.StepLog{
+		{PipelinerunID: 1, ApplicationID: 1, ClusterID: 1, Task: "build", Step: "compile", Sequence: 1,
+			Content: "[ERROR] BUILD_FAILURE"},
+		{PipelinerunID: 1, ApplicationID: 1, ClusterID: 1, Task: "build", Step: "git", Sequence: 0,
+			Content: "cloning"},
+		{PipelinerunID: 2, ApplicationID: 1, ClusterID: 2, Task: "build", Step: "compile", Sequence: 0,
+			Content: "[ERROR] BUILDXFAILURE 100%"},
+	}))
+
+	stepLogs, err := stepLogManager.ListByPipelinerunID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(stepLogs))
+	assert.Equal(t, "git", stepLogs[0].Step)
+
+	// the wildcards of like are matched literally
+	total, stepLogs, err := stepLogManager.Search(ctx, &models.StepLogSearch{
+		ApplicationID: 1,
+		Keyword:       "BUILD_FAILURE",
+	}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, uint(1), stepLogs[0].PipelinerunID)
+	total, _, err = stepLogManager.Search(ctx, &models.StepLogSearch{ApplicationID: 1, Keyword: "100%"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+
+	total, stepLogs, err = stepLogManager.Search(ctx, &models.StepLogSearch{
+		ApplicationID: 1,
+		Step:          "compile",
+	}, &q.Query{PageNumber: 1, PageSize: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, 1, len(stepLogs))
+	assert.Equal(t, uint(2), stepLogs[0].PipelinerunID)
+	total, _, err = stepLogManager.Search(ctx, &models.StepLogSearch{ClusterID: 2, Task: "build"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	total, _, err = stepLogManager.Search(ctx, &models.StepLogSearch{
+		ApplicationID: 1,
+		Until:         time.Now().Add(-time.Hour),
+	}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, total)
+
+	deleted, err := stepLogManager.DeleteBefore(ctx, time.Now().Add(time.Second), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, deleted)
+	deleted, err = stepLogManager.DeleteBefore(ctx, time.Now().Add(time.Second), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, deleted)
+}
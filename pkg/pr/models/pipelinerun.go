@@ -80,6 +80,8 @@ type Pipelinerun struct {
 	StartedAt *time.Time
 	// FinishedAt finish time of this pipelinerun
 	FinishedAt *time.Time
+	// ScheduledAt the time this pipelinerun is executed automatically at, when it's ready and deploy windows allow
+	ScheduledAt *time.Time
 	// RollbackFrom which pipelinerun this pipelinerun rollback from
 	RollbackFrom *uint
 	// CIEventID event id returned from tekton-trigger EventListener
@@ -125,6 +127,8 @@ type PipelineBasic struct {
 	StartedAt *time.Time `json:"startedAt"`
 	// FinishedAt finish time of this pipelinerun
 	FinishedAt *time.Time `json:"finishedAt"`
+	// ScheduledAt the time this pipelinerun is executed automatically at
+	ScheduledAt *time.Time `json:"scheduledAt,omitempty"`
 	// CanRollback can this pipelinerun be rollback, default is false
 	CanRollback bool `json:"canRollback"`
 	// createInfo
@@ -144,3 +148,21 @@ type Result struct {
 	StartedAt  *time.Time
 	FinishedAt *time.Time
 }
+
+// DeployQuery queries the pipelineruns deploying the clusters of applications or of an environment,
+// which are finished in a period
+type DeployQuery struct {
+	ApplicationIDs  []uint
+	EnvironmentName string
+	Since           time.Time
+	Until           time.Time
+}
+
+// Deploy is a finished pipelinerun deploying a cluster, with the application of the cluster
+type Deploy struct {
+	Pipelinerun
+	ApplicationID uint
+	// DeployFailed is true if the pipelinerun failed when deploying the cluster,
+	// rather than building the image or checking before the deploy
+	DeployFailed bool
+}
@@ -0,0 +1,55 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// StepLog is the log of a step of pipelinerun, split by task and step when the log is collected
+type StepLog struct {
+	global.Model
+	PipelinerunID uint
+	ApplicationID uint
+	ClusterID     uint
+	Task          string
+	Step          string
+	// Sequence is the order of the step in the log of pipelinerun
+	Sequence   int
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+	LineCount  int
+	// Content is the lines of the step joined by "\n"
+	Content string
+}
+
+func (StepLog) TableName() string {
+	return "tb_pipelinerun_step_log"
+}
+
+// StepLogSearch searches the step logs of the pipelineruns of an application or a cluster
+type StepLogSearch struct {
+	ApplicationID uint
+	ClusterID     uint
+	// Keyword is the text contained by the log
+	Keyword string
+	Task    string
+	Step    string
+	// Since and Until limit the creation time of step logs, ignored if zero
+	Since time.Time
+	Until time.Time
+}
@@ -37,6 +37,12 @@ type DAO interface {
 	Create(ctx context.Context, results *tekton.PipelineResults, data *global.HorizonMetaData) error
 	// ListPipelineStats list pipeline stats by query struct
 	ListPipelineStats(ctx context.Context, query *q.Query) ([]*models.PipelineStats, int64, error)
+	// ListPipelines lists the pipelines matching query order by started_at
+	ListPipelines(ctx context.Context, query *models.StatsQuery) ([]*models.Pipeline, error)
+	// ListTasks lists the tasks of the pipelines matching query order by started_at
+	ListTasks(ctx context.Context, query *models.StatsQuery) ([]*models.Task, error)
+	// ListSteps lists the steps of the pipelines matching query order by started_at
+	ListSteps(ctx context.Context, query *models.StatsQuery) ([]*models.Step, error)
 }
 
 type dao struct{ db *gorm.DB }
@@ -150,6 +156,41 @@ func (d *dao) ListPipelineStats(ctx context.Context, query *q.Query) ([]*models.
 	return formatPipelineStats(pipelines, tasks, steps), count, nil
 }
 
+func (d *dao) ListPipelines(ctx context.Context, query *models.StatsQuery) ([]*models.Pipeline, error) {
+	var pipelines []*models.Pipeline
+	result := d.statsStatement(ctx, query).Find(&pipelines)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.PipelineInDB, result.Error.Error())
+	}
+	return pipelines, nil
+}
+
+func (d *dao) ListTasks(ctx context.Context, query *models.StatsQuery) ([]*models.Task, error) {
+	var tasks []*models.Task
+	result := d.statsStatement(ctx, query).Find(&tasks)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.TaskInDB, result.Error.Error())
+	}
+	return tasks, nil
+}
+
+func (d *dao) ListSteps(ctx context.Context, query *models.StatsQuery) ([]*models.Step, error) {
+	var steps []*models.Step
+	result := d.statsStatement(ctx, query).Find(&steps)
+	if result.Error != nil {
+		return nil, herrors.NewErrListFailed(herrors.StepInDB, result.Error.Error())
+	}
+	return steps, nil
+}
+
+func (d *dao) statsStatement(ctx context.Context, query *models.StatsQuery) *gorm.DB {
+	statement := d.db.WithContext(ctx).Where("application = ?", query.Application)
+	if query.Cluster != "" {
+		statement = statement.Where("cluster = ?", query.Cluster)
+	}
+	return statement.Where("started_at >= ? and started_at < ?", query.Since, query.Until).Order("started_at")
+}
+
 func formatPipelineStats(pipelines []*models.Pipeline, tasks []*models.Task,
 	steps []*models.Step) []*models.PipelineStats {
 	stepMap := make(map[uint]map[string][]*models.StepStats)
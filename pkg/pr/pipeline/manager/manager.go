@@ -36,6 +36,12 @@ type Manager interface {
 	Create(ctx context.Context, results *tekton.PipelineResults, data *global.HorizonMetaData) error
 	ListPipelineStats(ctx context.Context, application, cluster string, pageNumber, pageSize int) (
 		[]*models.PipelineStats, int64, error)
+	// ListPipelines lists the pipelines matching query order by started_at
+	ListPipelines(ctx context.Context, query *models.StatsQuery) ([]*models.Pipeline, error)
+	// ListTasks lists the tasks of the pipelines matching query order by started_at
+	ListTasks(ctx context.Context, query *models.StatsQuery) ([]*models.Task, error)
+	// ListSteps lists the steps of the pipelines matching query order by started_at
+	ListSteps(ctx context.Context, query *models.StatsQuery) ([]*models.Step, error)
 }
 
 type manager struct {
@@ -63,6 +69,18 @@ func (m manager) Create(ctx context.Context, results *tekton.PipelineResults, da
 	return m.dao.Create(ctx, results, data)
 }
 
+func (m manager) ListPipelines(ctx context.Context, query *models.StatsQuery) ([]*models.Pipeline, error) {
+	return m.dao.ListPipelines(ctx, query)
+}
+
+func (m manager) ListTasks(ctx context.Context, query *models.StatsQuery) ([]*models.Task, error) {
+	return m.dao.ListTasks(ctx, query)
+}
+
+func (m manager) ListSteps(ctx context.Context, query *models.StatsQuery) ([]*models.Step, error) {
+	return m.dao.ListSteps(ctx, query)
+}
+
 func New(db *gorm.DB) Manager {
 	return &manager{
 		dao: dao.NewDAO(db),
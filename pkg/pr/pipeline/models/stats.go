@@ -39,3 +39,12 @@ type PipelineStats struct {
 	Tasks         []*TaskStats `json:"tasks"`
 	StartedAt     time.Time    `json:"startedAt"`
 }
+
+// StatsQuery queries the stats of the pipelines of an application started in a period
+type StatsQuery struct {
+	Application string
+	// Cluster is optional, all the clusters of the application are queried if it is empty
+	Cluster string
+	Since   time.Time
+	Until   time.Time
+}
@@ -52,6 +52,7 @@ func (s *Service) OfPipelineBasic(ctx context.Context,
 		UpdatedAt:        pr.UpdatedAt,
 		StartedAt:        pr.StartedAt,
 		FinishedAt:       pr.FinishedAt,
+		ScheduledAt:      pr.ScheduledAt,
 		CanRollback:      canRollback,
 		CreatedBy: models.UserInfo{
 			UserID:   pr.CreatedBy,
@@ -0,0 +1,172 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promotion
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"text/template"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/config/promotion"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/util/log"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// QueryParams are the values the SLO queries are rendered with
+type QueryParams struct {
+	Cluster     string
+	Environment string
+	Region      string
+}
+
+// Measurement is the result of one SLO query
+type Measurement struct {
+	Name      string  `json:"name"`
+	Query     string  `json:"query"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	Healthy   bool    `json:"healthy"`
+	// NoData is true if the query returned no sample, or only NaN
+	NoData bool `json:"noData,omitempty"`
+}
+
+type Result struct {
+	Healthy bool `json:"healthy"`
+	// Inconclusive is true if some queries returned no data and none of the others is unhealthy,
+	// the cluster is neither healthy nor unhealthy then.
+	Inconclusive bool           `json:"inconclusive"`
+	Measurements []*Measurement `json:"measurements"`
+}
+
+// Analyzer checks whether a cluster meets its SLOs by querying prometheus
+type Analyzer interface {
+	Analyze(ctx context.Context, prometheusURL string, params *QueryParams) (*Result, error)
+}
+
+type analyzer struct {
+	queries []*sloQuery
+}
+
+type sloQuery struct {
+	promotion.SLOQuery
+	tpl *template.Template
+}
+
+func NewAnalyzer(queries []promotion.SLOQuery) (Analyzer, error) {
+	a := &analyzer{queries: make([]*sloQuery, 0, len(queries))}
+	for _, query := range queries {
+		tpl, err := template.New(query.Name).Option("missingkey=error").Parse(query.Query)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"failed to parse query %s: %v", query.Name, err)
+		}
+		a.queries = append(a.queries, &sloQuery{SLOQuery: query, tpl: tpl})
+	}
+	return a, nil
+}
+
+func (a *analyzer) Analyze(ctx context.Context, prometheusURL string, params *QueryParams) (*Result, error) {
+	const op = "promotion analyzer: analyze"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	if prometheusURL == "" {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"prometheus url of region %s is empty", params.Region)
+	}
+	client, err := api.NewClient(api.Config{Address: prometheusURL})
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+	}
+	promAPI := prometheusv1.NewAPI(client)
+
+	result := &Result{Healthy: true, Measurements: make([]*Measurement, 0, len(a.queries))}
+	now := time.Now()
+	for _, query := range a.queries {
+		buf := &bytes.Buffer{}
+		if err := query.tpl.Execute(buf, params); err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid,
+				"failed to render query %s: %v", query.Name, err)
+		}
+		value, warnings, err := promAPI.Query(ctx, buf.String(), now)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrHTTPRequestFailed,
+				"failed to query %s from %s: %v", query.Name, prometheusURL, err)
+		}
+		for _, warning := range warnings {
+			log.Warningf(ctx, "query %s from %s with warning: %s", query.Name, prometheusURL, warning)
+		}
+		v, found, err := maxValue(value)
+		if err != nil {
+			return nil, perror.Wrapf(herrors.ErrParamInvalid, "query %s: %v", query.Name, err)
+		}
+		measurement := &Measurement{
+			Name:      query.Name,
+			Query:     buf.String(),
+			Value:     v,
+			Threshold: query.Threshold,
+			Healthy:   found && v <= query.Threshold,
+			NoData:    !found,
+		}
+		result.Measurements = append(result.Measurements, measurement)
+		result.Healthy = result.Healthy && measurement.Healthy
+	}
+
+	unhealthy := false
+	for _, measurement := range result.Measurements {
+		if measurement.NoData {
+			result.Inconclusive = true
+		} else if !measurement.Healthy {
+			unhealthy = true
+		}
+	}
+	result.Inconclusive = result.Inconclusive && !unhealthy
+	return result, nil
+}
+
+// maxValue returns the max sample of value, found is false if there is no sample or all samples are NaN.
+// No data is never regarded as healthy, since it's also what a mistyped query or a broken exporter returns,
+// queries whose series may not exist, such as the ones of errors, should fall back to a value like `or vector(0)`.
+func maxValue(value model.Value) (_ float64, found bool, _ error) {
+	switch v := value.(type) {
+	case *model.Scalar:
+		if math.IsNaN(float64(v.Value)) {
+			return 0, false, nil
+		}
+		return float64(v.Value), true, nil
+	case model.Vector:
+		var m float64
+		for _, sample := range v {
+			if math.IsNaN(float64(sample.Value)) {
+				continue
+			}
+			if !found || float64(sample.Value) > m {
+				m = float64(sample.Value)
+				found = true
+			}
+		}
+		return m, found, nil
+	default:
+		return 0, false, fmt.Errorf("unsupported result type %s", value.Type())
+	}
+}
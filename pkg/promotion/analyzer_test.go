@@ -0,0 +1,114 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promotion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/pkg/config/promotion"
+)
+
+func TestAnalyze(t *testing.T) {
+	values := map[string]string{
+		`error_rate{cluster="c1"}`: `{"resultType":"vector","result":[` +
+			`{"metric":{"pod":"p1"},"value":[1700000000,"0.01"]},` +
+			`{"metric":{"pod":"p2"},"value":[1700000000,"0.03"]}]}`,
+		`latency{cluster="c1"}`: `{"resultType":"scalar","result":[1700000000,"NaN"]}`,
+		`empty{cluster="c1"}`:   `{"resultType":"vector","result":[]}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(t, r.ParseForm())
+		data, ok := values[r.Form.Get("query")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"unknown query"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"status":"success","data":%s}`, data)))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	params := &QueryParams{Cluster: "c1", Environment: "test", Region: "hz"}
+
+	analyzer, err := NewAnalyzer([]promotion.SLOQuery{
+		{Name: "error-rate", Query: `error_rate{cluster="{{ .Cluster }}"}`, Threshold: 0.05},
+		{Name: "latency", Query: `latency{cluster="{{ .Cluster }}"}`, Threshold: 100},
+		{Name: "empty", Query: `empty{cluster="{{ .Cluster }}"}`, Threshold: 0},
+	})
+	assert.Nil(t, err)
+	result, err := analyzer.Analyze(ctx, server.URL, params)
+	assert.Nil(t, err)
+	// no data is inconclusive rather than healthy
+	assert.False(t, result.Healthy)
+	assert.True(t, result.Inconclusive)
+	assert.Equal(t, 3, len(result.Measurements))
+	assert.Equal(t, `error_rate{cluster="c1"}`, result.Measurements[0].Query)
+	assert.Equal(t, 0.03, result.Measurements[0].Value)
+	assert.True(t, result.Measurements[0].Healthy)
+	assert.True(t, result.Measurements[1].NoData)
+	assert.False(t, result.Measurements[1].Healthy)
+	assert.True(t, result.Measurements[2].NoData)
+
+	analyzer, err = NewAnalyzer([]promotion.SLOQuery{
+		{Name: "error-rate", Query: `error_rate{cluster="{{ .Cluster }}"}`, Threshold: 0.05},
+	})
+	assert.Nil(t, err)
+	result, err = analyzer.Analyze(ctx, server.URL, params)
+	assert.Nil(t, err)
+	assert.True(t, result.Healthy)
+	assert.False(t, result.Inconclusive)
+
+	// an unhealthy measurement is conclusive even if others have no data
+	analyzer, err = NewAnalyzer([]promotion.SLOQuery{
+		{Name: "error-rate", Query: `error_rate{cluster="{{ .Cluster }}"}`, Threshold: 0.02},
+		{Name: "empty", Query: `empty{cluster="{{ .Cluster }}"}`, Threshold: 0},
+	})
+	assert.Nil(t, err)
+	result, err = analyzer.Analyze(ctx, server.URL, params)
+	assert.Nil(t, err)
+	assert.False(t, result.Healthy)
+	assert.False(t, result.Inconclusive)
+
+	analyzer, err = NewAnalyzer([]promotion.SLOQuery{
+		{Name: "error-rate", Query: `error_rate{cluster="{{ .Cluster }}"}`, Threshold: 0.02},
+	})
+	assert.Nil(t, err)
+	result, err = analyzer.Analyze(ctx, server.URL, params)
+	assert.Nil(t, err)
+	assert.False(t, result.Healthy)
+	assert.False(t, result.Measurements[0].Healthy)
+
+	// query failed
+	analyzer, err = NewAnalyzer([]promotion.SLOQuery{{Name: "unknown", Query: "unknown"}})
+	assert.Nil(t, err)
+	_, err = analyzer.Analyze(ctx, server.URL, params)
+	assert.NotNil(t, err)
+
+	// no prometheus
+	_, err = analyzer.Analyze(ctx, "", params)
+	assert.NotNil(t, err)
+
+	// invalid template
+	_, err = NewAnalyzer([]promotion.SLOQuery{{Name: "invalid", Query: "{{ .Cluster "}})
+	assert.NotNil(t, err)
+}
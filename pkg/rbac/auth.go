@@ -17,6 +17,7 @@ package rbac
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/horizoncd/horizon/core/common"
 	"github.com/horizoncd/horizon/pkg/auth"
@@ -24,6 +25,7 @@ import (
 	memberservice "github.com/horizoncd/horizon/pkg/member/service"
 	"github.com/horizoncd/horizon/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/rbac/types"
+	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
 	"github.com/horizoncd/horizon/pkg/util/log"
 )
 
@@ -31,51 +33,88 @@ import (
 // have the permissions
 type Authorizer interface {
 	Authorize(ctx context.Context, attributes auth.Attributes) (auth.Decision, string, error)
+	// Explain tells how the decision is made, the explanation is returned even if err is not nil
+	Explain(ctx context.Context, attributes auth.Attributes) (*Explanation, error)
+}
+
+// Explanation is the detail of an authorization decision
+type Explanation struct {
+	Decision auth.Decision
+	Reason   string
+	// Member is the binding the decision came from, it may be inherited from an ancestor of the resource
+	Member *models.Member
+	Role   string
+	// RuleIndex is the index of the rule allowing the request in the role, -1 if no rule allows it
+	RuleIndex int
+	Rule      *types.PolicyRule
 }
 
 type VisitorFunc func(fmt.Stringer, *types.PolicyRule, error) bool
 
-func NewAuthorizer(roleservice role.Service, memberservice memberservice.Service) Authorizer {
+func NewAuthorizer(roleservice role.Service, memberservice memberservice.Service,
+	tokenManager tokenmanager.Manager) Authorizer {
 	return &authorizer{
 		roleService:   roleservice,
 		memberService: memberservice,
+		tokenManager:  tokenManager,
 	}
 }
 
 type authorizer struct {
 	roleService   role.Service
 	memberService memberservice.Service
+	tokenManager  tokenmanager.Manager
 }
 
 const (
-	NotChecked        = "not checked"
 	ResourceFormatErr = "format error"
 	AnonymousUser     = "anonymous user"
 	InternalError     = "internal error"
 	MemberNotExist    = "member not exist"
 	RoleNotExist      = "role not exist"
 	AdminAllow        = "admin allows everything"
+	PublicRead        = "public resources are readable by everyone"
+	AdminOnly         = "only admins could change the resource"
+	TokenOwnerAllow   = "users manage their own personal access tokens"
+	TokenOwnerDeny    = "personal access tokens could only be managed by their owners"
+	TeamCreateAllow   = "users create teams and become their owners"
 )
 
 func (a *authorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.Decision,
 	string, error) {
-	// 0. check (admin allows everything, and some are not checked)
+	explanation, err := a.Explain(ctx, attr)
+	return explanation.Decision, explanation.Reason, err
+}
+
+func (a *authorizer) Explain(ctx context.Context, attr auth.Attributes) (*Explanation, error) {
+	explain := func(decision auth.Decision, reason string) *Explanation {
+		return &Explanation{Decision: decision, Reason: reason, RuleIndex: -1}
+	}
+
+	// 0. check (admin allows everything, and some resources are not bound to members)
 	currentUser, err := common.UserFromContext(ctx)
 	if err != nil {
-		return auth.DecisionDeny, AnonymousUser, nil
+		return explain(auth.DecisionDeny, AnonymousUser), nil
 	}
 	if currentUser.IsAdmin() {
-		return auth.DecisionAllow, AdminAllow, nil
+		return explain(auth.DecisionAllow, AdminAllow), nil
 	}
 
-	// TODO(tom): members, users, accesstokens and environments need to add to auth check
-	if attr.IsResourceRequest() && (attr.GetResource() == "members" ||
-		attr.GetResource() == "environments" || attr.GetResource() == "users" ||
-		attr.GetResource() == "personalaccesstokens" ||
-		(attr.GetResource() == "accesstokens" && attr.GetVerb() == "delete")) {
-		log.Warning(ctx,
-			"members|environments|access tokens are not authed yet")
-		return auth.DecisionAllow, NotChecked, nil
+	if attr.IsResourceRequest() {
+		switch attr.GetResource() {
+		case common.ResourceEnvironment, common.ResourceUser:
+			if attr.IsReadOnly() {
+				return explain(auth.DecisionAllow, PublicRead), nil
+			}
+			return explain(auth.DecisionDeny, AdminOnly), nil
+		case common.ResourcePersonalAccessToken:
+			decision, reason, err := a.authorizePersonalAccessToken(ctx, currentUser.GetID(), attr)
+			return explain(decision, reason), err
+		case common.ResourceTeam:
+			if attr.GetName() == "" && attr.GetSubResource() == "" {
+				return explain(auth.DecisionAllow, TeamCreateAllow), nil
+			}
+		}
 	}
 
 	// 1. get the member
@@ -84,37 +123,54 @@ func (a *authorizer) Authorize(ctx context.Context, attr auth.Attributes) (auth.
 	if err != nil {
 		log.Warningf(ctx, "GetMemberOfResource error, resourceType = %s, resourceID = %s, user = %s\n",
 			attr.GetResource(), attr.GetName(), attr.GetUser().String())
-		return auth.DecisionDeny, InternalError, err
+		return explain(auth.DecisionDeny, InternalError), err
 	}
 
 	// 2. get the role
-	var role *types.Role
 	if member == nil {
-		// TODO(tom): non public resources
-		// now default resource are public, if there is a default role, non member can choose the default role
-		defaultRole := a.roleService.GetDefaultRole(ctx)
-		if defaultRole == nil {
-			log.Warningf(ctx, " user %s member and role not found of resourceType = %s, resourceID = %s",
-				attr.GetUser().String(), attr.GetResource(), attr.GetName())
-			return auth.DecisionDeny, MemberNotExist, nil
-		}
-		log.WithFiled(ctx, "user",
-			attr.GetUser().String()).Debugf(" use the default role %s", defaultRole.Name)
-		role = defaultRole
-	} else {
-		var err error
-		role, err = a.roleService.GetRole(ctx, member.Role)
-		if err != nil {
-			log.Errorf(ctx, "get role for role(%s), err = %+v", member.Role, err)
-			return auth.DecisionDeny, InternalError, err
-		}
+		// the member service falls back to the default role for the non-members if the resource is visible,
+		// so there is no member for the resources invisible to the user
+		log.Warningf(ctx, " user %s member and role not found of resourceType = %s, resourceID = %s",
+			attr.GetUser().String(), attr.GetResource(), attr.GetName())
+		return explain(auth.DecisionDeny, MemberNotExist), nil
+	}
+	role, err := a.roleService.GetRole(ctx, member.Role)
+	if err != nil {
+		log.Errorf(ctx, "get role for role(%s), err = %+v", member.Role, err)
+		return explain(auth.DecisionDeny, InternalError), err
 	}
 	if role == nil {
-		return auth.DecisionDeny, RoleNotExist, nil
+		explanation := explain(auth.DecisionDeny, RoleNotExist)
+		explanation.Member, explanation.Role = member, member.Role
+		return explanation, nil
 	}
 
 	// 3. check the permission
-	return VisitRoles(member, role, attr)
+	explanation := &Explanation{Member: member, Role: role.Name}
+	explanation.RuleIndex, explanation.Rule = MatchRule(role, attr)
+	explanation.Decision, explanation.Reason, err = VisitRoles(member, role, attr)
+	return explanation, err
+}
+
+// authorizePersonalAccessToken allows users to create and list their own personal access tokens,
+// and to revoke the tokens owned by themselves
+func (a *authorizer) authorizePersonalAccessToken(ctx context.Context, userID uint,
+	attr auth.Attributes) (auth.Decision, string, error) {
+	if attr.GetName() == "" {
+		return auth.DecisionAllow, TokenOwnerAllow, nil
+	}
+	tokenID, err := strconv.ParseUint(attr.GetName(), 10, 0)
+	if err != nil {
+		return auth.DecisionDeny, ResourceFormatErr, nil
+	}
+	token, err := a.tokenManager.LoadTokenByID(ctx, uint(tokenID))
+	if err != nil {
+		return auth.DecisionDeny, InternalError, err
+	}
+	if token.UserID != userID {
+		return auth.DecisionDeny, TokenOwnerDeny, nil
+	}
+	return auth.DecisionAllow, TokenOwnerAllow, nil
 }
 
 func VisitRoles(member *models.Member, role *types.Role,
@@ -125,13 +181,21 @@ func VisitRoles(member *models.Member, role *types.Role,
 	} else {
 		memberInfo = "null"
 	}
-	for i, rule := range role.PolicyRules {
-		if types.RuleAllow(attr, &rule) {
-			reason = fmt.Sprintf("user %s allowed by member(%s) by rule[%d]",
-				attr.GetUser().String(), memberInfo, i)
-			return auth.DecisionAllow, reason, nil
-		}
+	if i, _ := MatchRule(role, attr); i >= 0 {
+		reason = fmt.Sprintf("user %s allowed by member(%s) by rule[%d]",
+			attr.GetUser().String(), memberInfo, i)
+		return auth.DecisionAllow, reason, nil
 	}
 	reason = fmt.Sprintf("user %s denied by member(%s)", attr.GetUser().String(), memberInfo)
 	return auth.DecisionDeny, reason, nil
 }
+
+// MatchRule returns the first rule of the role allowing the request and its index, or -1 if there is none
+func MatchRule(role *types.Role, attr auth.Attributes) (int, *types.PolicyRule) {
+	for i := range role.PolicyRules {
+		if types.RuleAllow(attr, &role.PolicyRules[i]) {
+			return i, &role.PolicyRules[i]
+		}
+	}
+	return -1, nil
+}
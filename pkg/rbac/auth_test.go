@@ -17,18 +17,22 @@ package rbac
 import (
 	"context"
 	"errors"
+	"strconv"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/lib/orm"
 	servicemock "github.com/horizoncd/horizon/mock/pkg/member/service"
 	rolemock "github.com/horizoncd/horizon/mock/pkg/rbac/role"
 	"github.com/horizoncd/horizon/pkg/auth"
 	"github.com/horizoncd/horizon/pkg/authentication/user"
 	"github.com/horizoncd/horizon/pkg/member/models"
 	"github.com/horizoncd/horizon/pkg/rbac/types"
+	tokenmanager "github.com/horizoncd/horizon/pkg/token/manager"
+	tokenmodels "github.com/horizoncd/horizon/pkg/token/models"
 )
 
 // members and pipelineruns are allowed
@@ -57,15 +61,19 @@ func TestAuthMember(t *testing.T) {
 		APIGroup:        "/apis/core",
 		APIVersion:      "v1",
 		Resource:        "members",
+		Name:            "3",
 		ResourceRequest: true,
 		Path:            "",
 	}
 
+	// members are authorized by the member info of the resources that they belong to
 	ctx = context.WithValue(ctx, common.UserContextKey(), defaultUser)
-	decision, reason, err := testAuthorizer.Authorize(ctx, authRecord)
+	memberServiceMock.EXPECT().GetMemberOfResource(ctx, common.ResourceMember,
+		"3").Return(&models.Member{Role: "guest"}, nil).Times(1)
+	roleServiceMock.EXPECT().GetRole(ctx, "guest").Return(&types.Role{Name: "guest"}, nil).Times(1)
+	decision, _, err := testAuthorizer.Authorize(ctx, authRecord)
 	assert.Nil(t, err)
-	assert.Equal(t, auth.DecisionAllow, decision)
-	assert.Equal(t, NotChecked, reason)
+	assert.Equal(t, auth.DecisionDeny, decision)
 
 	authRecord = auth.AttributesRecord{
 		User:            defaultUser,
@@ -80,15 +88,14 @@ func TestAuthMember(t *testing.T) {
 	// getMember error
 	memberServiceMock.EXPECT().GetMemberOfResource(ctx, gomock.Any(),
 		gomock.Any()).Return(nil, errors.New("error")).Times(1)
-	decision, reason, err = testAuthorizer.Authorize(ctx, authRecord)
+	decision, reason, err := testAuthorizer.Authorize(ctx, authRecord)
 	assert.Equal(t, auth.DecisionDeny, decision)
 	assert.Equal(t, InternalError, reason)
 	assert.NotNil(t, err)
 
-	// member not exist
+	// member not exist, e.g. private resources of non-members
 	memberServiceMock.EXPECT().GetMemberOfResource(ctx, gomock.Any(),
 		gomock.Any()).Return(nil, nil).Times(1)
-	roleServiceMock.EXPECT().GetDefaultRole(ctx).Return(nil).Times(1)
 	decision, reason, err = testAuthorizer.Authorize(ctx, authRecord)
 	assert.Equal(t, auth.DecisionDeny, decision)
 	assert.Equal(t, MemberNotExist, reason)
@@ -146,3 +153,97 @@ func TestAuthRole(t *testing.T) {
 	assert.Equal(t, auth.DecisionDeny, decision)
 	assert.Nil(t, err)
 }
+
+func TestAuthWithoutMember(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&tokenmodels.Token{}))
+	tokenMgr := tokenmanager.New(db)
+	mockCtl := gomock.NewController(t)
+	testAuthorizer := NewAuthorizer(rolemock.NewMockService(mockCtl),
+		servicemock.NewMockService(mockCtl), tokenMgr)
+
+	// environments and users are readable by everyone, but only admins could change them
+	for _, resource := range []string{common.ResourceEnvironment, common.ResourceUser} {
+		decision, reason, err := testAuthorizer.Authorize(ctx, auth.AttributesRecord{User: defaultUser,
+			Verb: "get", Resource: resource, Name: "1", ResourceRequest: true})
+		assert.Nil(t, err)
+		assert.Equal(t, auth.DecisionAllow, decision)
+		assert.Equal(t, PublicRead, reason)
+		decision, reason, err = testAuthorizer.Authorize(ctx, auth.AttributesRecord{User: defaultUser,
+			Verb: "update", Resource: resource, Name: "1", ResourceRequest: true})
+		assert.Nil(t, err)
+		assert.Equal(t, auth.DecisionDeny, decision)
+		assert.Equal(t, AdminOnly, reason)
+	}
+	adminCtx := common.WithContext(context.Background(), &user.DefaultInfo{Name: "admin", ID: 2, Admin: true})
+	decision, _, err := testAuthorizer.Authorize(adminCtx, auth.AttributesRecord{User: defaultUser,
+		Verb: "delete", Resource: common.ResourceEnvironment, Name: "1", ResourceRequest: true})
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionAllow, decision)
+
+	// personal access tokens are managed by their owners
+	decision, _, err = testAuthorizer.Authorize(ctx, auth.AttributesRecord{User: defaultUser,
+		Verb: "create", Resource: common.ResourcePersonalAccessToken, ResourceRequest: true})
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionAllow, decision)
+	mine, err := tokenMgr.CreateToken(ctx, &tokenmodels.Token{Name: "mine", Code: "a", UserID: defaultUser.ID})
+	assert.Nil(t, err)
+	others, err := tokenMgr.CreateToken(ctx, &tokenmodels.Token{Name: "others", Code: "b", UserID: 2})
+	assert.Nil(t, err)
+	decision, reason, err := testAuthorizer.Authorize(ctx, auth.AttributesRecord{User: defaultUser,
+		Verb: "delete", Resource: common.ResourcePersonalAccessToken,
+		Name: strconv.Itoa(int(mine.ID)), ResourceRequest: true})
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionAllow, decision)
+	assert.Equal(t, TokenOwnerAllow, reason)
+	decision, reason, err = testAuthorizer.Authorize(ctx, auth.AttributesRecord{User: defaultUser,
+		Verb: "delete", Resource: common.ResourcePersonalAccessToken,
+		Name: strconv.Itoa(int(others.ID)), ResourceRequest: true})
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionDeny, decision)
+	assert.Equal(t, TokenOwnerDeny, reason)
+
+	// users create teams, which are authorized by the members of them later
+	decision, reason, err = testAuthorizer.Authorize(ctx, auth.AttributesRecord{User: defaultUser,
+		Verb: "create", Resource: common.ResourceTeam, ResourceRequest: true})
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionAllow, decision)
+	assert.Equal(t, TeamCreateAllow, reason)
+}
+
+func TestExplain(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	memberServiceMock := servicemock.NewMockService(mockCtl)
+	roleServiceMock := rolemock.NewMockService(mockCtl)
+	testAuthorizer := NewAuthorizer(roleServiceMock, memberServiceMock, nil)
+
+	// the member is inherited from the parent group of the cluster
+	member := &models.Member{ResourceType: common.ResourceGroup, ResourceID: 1, Role: "owner"}
+	rules := []types.PolicyRule{
+		{Verbs: []string{"get"}, APIGroups: []string{"*"}, Resources: []string{"clusters"}, Scopes: []string{"*"}},
+		{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"clusters/builddeploy"}, Scopes: []string{"*"}},
+	}
+	memberServiceMock.EXPECT().GetMemberOfResource(ctx, common.ResourceCluster, "2").
+		Return(member, nil).Times(2)
+	roleServiceMock.EXPECT().GetRole(ctx, "owner").
+		Return(&types.Role{Name: "owner", PolicyRules: rules}, nil).Times(2)
+
+	explanation, err := testAuthorizer.Explain(ctx, auth.AttributesRecord{User: defaultUser,
+		Verb: "create", Resource: common.ResourceCluster, SubResource: "builddeploy", Name: "2",
+		ResourceRequest: true})
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionAllow, explanation.Decision)
+	assert.Equal(t, member, explanation.Member)
+	assert.Equal(t, "owner", explanation.Role)
+	assert.Equal(t, 1, explanation.RuleIndex)
+	assert.Equal(t, &rules[1], explanation.Rule)
+
+	explanation, err = testAuthorizer.Explain(ctx, auth.AttributesRecord{User: defaultUser,
+		Verb: "delete", Resource: common.ResourceCluster, Name: "2", ResourceRequest: true})
+	assert.Nil(t, err)
+	assert.Equal(t, auth.DecisionDeny, explanation.Decision)
+	assert.Equal(t, member, explanation.Member)
+	assert.Equal(t, -1, explanation.RuleIndex)
+	assert.Nil(t, explanation.Rule)
+}
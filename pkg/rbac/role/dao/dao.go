@@ -0,0 +1,167 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/pkg/rbac/role/models"
+)
+
+type DAO interface {
+	// Seed creates the roles if there is no role yet, or upserts them if their version
+	// is newer than the roles in database
+	Seed(ctx context.Context, roles []*models.Role) error
+	Create(ctx context.Context, role *models.Role) (*models.Role, error)
+	Get(ctx context.Context, name string) (*models.Role, error)
+	List(ctx context.Context) ([]*models.Role, error)
+	Update(ctx context.Context, role *models.Role) (*models.Role, error)
+	Delete(ctx context.Context, name string) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Seed(ctx context.Context, roles []*models.Role) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(roles) == 0 {
+			return nil
+		}
+		var count int64
+		if result := tx.Model(&models.Role{}).Count(&count); result.Error != nil {
+			return herrors.NewErrGetFailed(herrors.RoleInDB, result.Error.Error())
+		}
+		if count == 0 {
+			if result := tx.Create(roles); result.Error != nil {
+				return herrors.NewErrInsertFailed(herrors.RoleInDB, result.Error.Error())
+			}
+			return nil
+		}
+
+		var version uint
+		if result := tx.Model(&models.Role{}).Select("COALESCE(MAX(version), 0)").
+			Scan(&version); result.Error != nil {
+			return herrors.NewErrGetFailed(herrors.RoleInDB, result.Error.Error())
+		}
+		if roles[0].Version <= version {
+			return nil
+		}
+		for _, role := range roles {
+			if role.IsDefault {
+				if err := unsetDefault(tx); err != nil {
+					return err
+				}
+			}
+			var existing models.Role
+			if result := tx.Where("name = ?", role.Name).First(&existing); result.Error != nil {
+				if result.Error != gorm.ErrRecordNotFound {
+					return herrors.NewErrGetFailed(herrors.RoleInDB, result.Error.Error())
+				}
+				if result := tx.Create(role); result.Error != nil {
+					return herrors.NewErrInsertFailed(herrors.RoleInDB, result.Error.Error())
+				}
+				continue
+			}
+			role.ID = existing.ID
+			if result := tx.Where("id = ?", role.ID).
+				Select("description", "rules", "rank", "is_default", "version").
+				Updates(role); result.Error != nil {
+				return herrors.NewErrUpdateFailed(herrors.RoleInDB, result.Error.Error())
+			}
+		}
+		return nil
+	})
+}
+
+func (d *dao) Create(ctx context.Context, role *models.Role) (*models.Role, error) {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if role.IsDefault {
+			if err := unsetDefault(tx); err != nil {
+				return err
+			}
+		}
+		if result := tx.Create(role); result.Error != nil {
+			return herrors.NewErrInsertFailed(herrors.RoleInDB, result.Error.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (d *dao) Get(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	if result := d.db.WithContext(ctx).Where("name = ?", name).First(&role); result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.RoleInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.RoleInDB, result.Error.Error())
+	}
+	return &role, nil
+}
+
+func (d *dao) List(ctx context.Context) ([]*models.Role, error) {
+	var roles []*models.Role
+	if result := d.db.WithContext(ctx).Order("`rank`").Order("id").Find(&roles); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.RoleInDB, result.Error.Error())
+	}
+	return roles, nil
+}
+
+func (d *dao) Update(ctx context.Context, role *models.Role) (*models.Role, error) {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if role.IsDefault {
+			if err := unsetDefault(tx); err != nil {
+				return err
+			}
+		}
+		if result := tx.Where("id = ?", role.ID).
+			Select("description", "rules", "rank", "is_default", "updated_by").
+			Updates(role); result.Error != nil {
+			return herrors.NewErrUpdateFailed(herrors.RoleInDB, result.Error.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d.Get(ctx, role.Name)
+}
+
+func (d *dao) Delete(ctx context.Context, name string) error {
+	if result := d.db.WithContext(ctx).Where("name = ?", name).
+		Delete(&models.Role{}); result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.RoleInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func unsetDefault(tx *gorm.DB) error {
+	if result := tx.Model(&models.Role{}).Where("is_default = ?", true).
+		Update("is_default", false); result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.RoleInDB, result.Error.Error())
+	}
+	return nil
+}
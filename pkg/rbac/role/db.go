@@ -0,0 +1,199 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	rolemanager "github.com/horizoncd/horizon/pkg/rbac/role/manager"
+	"github.com/horizoncd/horizon/pkg/rbac/role/models"
+	"github.com/horizoncd/horizon/pkg/rbac/types"
+	"github.com/horizoncd/horizon/pkg/util/log"
+)
+
+const _defaultReloadInterval = 10 * time.Second
+
+// ReloadableService is a role service whose roles can be changed at runtime
+type ReloadableService interface {
+	Service
+	// Reload loads the roles again, the roles in use are kept if it fails
+	Reload(ctx context.Context) error
+	// Sync reloads the roles periodically until ctx is done,
+	// so that the roles changed in any replica take effect in every replica
+	Sync(ctx context.Context)
+}
+
+type dbRoleService struct {
+	roleMgr        rolemanager.Manager
+	reloadInterval time.Duration
+
+	lock    sync.RWMutex
+	current *fileRoleService
+}
+
+// NewDBRoleService creates a role service backed by database, the roles of config are saved into
+// database if there is no role yet, or replace the roles of the same names if the version of config is newer.
+func NewDBRoleService(ctx context.Context, roleMgr rolemanager.Manager,
+	config roleconfig.Config, storeConfig roleconfig.StoreConfig) (ReloadableService, error) {
+	// check the config by building a file role service
+	if _, err := newFileRoleService(ctx, config); err != nil {
+		return nil, err
+	}
+	roles, err := ofConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := roleMgr.Seed(ctx, roles); err != nil {
+		return nil, err
+	}
+
+	s := &dbRoleService{
+		roleMgr:        roleMgr,
+		reloadInterval: storeConfig.ReloadInterval,
+	}
+	if s.reloadInterval <= 0 {
+		s.reloadInterval = _defaultReloadInterval
+	}
+	if err := s.Reload(ctx); err != nil {
+		return nil, err
+	}
+	s.warnDiff(ctx, config)
+	return s, nil
+}
+
+// warnDiff logs the roles of config which are not the same in database,
+// they are changed through api, or the version of config is not bumped
+func (s *dbRoleService) warnDiff(ctx context.Context, config roleconfig.Config) {
+	for _, role := range config.Roles {
+		current, err := s.GetRole(ctx, role.Name)
+		if err != nil {
+			log.Warningf(ctx, "role %s of the roles file is not in database", role.Name)
+			continue
+		}
+		if !reflect.DeepEqual(role, *current) {
+			log.Warningf(ctx, "role %s in database differs from the roles file of version %d, "+
+				"bump the version of roles file to replace it", role.Name, config.Version)
+		}
+	}
+}
+
+func (s *dbRoleService) Reload(ctx context.Context) error {
+	roles, err := s.roleMgr.List(ctx)
+	if err != nil {
+		return err
+	}
+	config, err := toConfig(roles)
+	if err != nil {
+		return err
+	}
+	current, err := newFileRoleService(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.current = current
+	return nil
+}
+
+func (s *dbRoleService) Sync(ctx context.Context) {
+	ticker := time.NewTicker(s.reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Reload(ctx); err != nil {
+				log.Errorf(ctx, "failed to reload roles, err: %+v", err)
+			}
+		}
+	}
+}
+
+func (s *dbRoleService) service() *fileRoleService {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.current
+}
+
+func (s *dbRoleService) ListRole(ctx context.Context) ([]types.Role, error) {
+	return s.service().ListRole(ctx)
+}
+
+func (s *dbRoleService) GetRole(ctx context.Context, roleName string) (*types.Role, error) {
+	return s.service().GetRole(ctx, roleName)
+}
+
+func (s *dbRoleService) RoleCompare(ctx context.Context, role1, role2 string) (CompResult, error) {
+	return s.service().RoleCompare(ctx, role1, role2)
+}
+
+func (s *dbRoleService) GetDefaultRole(ctx context.Context) *types.Role {
+	return s.service().GetDefaultRole(ctx)
+}
+
+// toConfig converts the roles in the order of priority to role config
+func toConfig(roles []*models.Role) (roleconfig.Config, error) {
+	var config roleconfig.Config
+	for _, role := range roles {
+		var rules []types.PolicyRule
+		if role.Rules != "" {
+			if err := json.Unmarshal([]byte(role.Rules), &rules); err != nil {
+				return config, err
+			}
+		}
+		config.RolePriorityRankDesc = append(config.RolePriorityRankDesc, role.Name)
+		config.Roles = append(config.Roles, types.Role{
+			Name:        role.Name,
+			Desc:        role.Description,
+			PolicyRules: rules,
+		})
+		if role.IsDefault {
+			config.DefaultRole = role.Name
+		}
+	}
+	return config, nil
+}
+
+func ofConfig(config roleconfig.Config) ([]*models.Role, error) {
+	ranks := make(map[string]int, len(config.RolePriorityRankDesc))
+	for i, name := range config.RolePriorityRankDesc {
+		ranks[name] = i
+	}
+	roles := make([]*models.Role, 0, len(config.Roles))
+	for _, role := range config.Roles {
+		rules, err := json.Marshal(role.PolicyRules)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, &models.Role{
+			Name:        role.Name,
+			Description: role.Desc,
+			Rules:       string(rules),
+			Rank:        ranks[role.Name],
+			IsDefault:   role.Name == config.DefaultRole,
+			Version:     config.Version,
+		})
+	}
+	return roles, nil
+}
@@ -0,0 +1,95 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	roleconfig "github.com/horizoncd/horizon/pkg/config/role"
+	rolemanager "github.com/horizoncd/horizon/pkg/rbac/role/manager"
+	"github.com/horizoncd/horizon/pkg/rbac/role/models"
+)
+
+func TestDBRoleService(t *testing.T) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&models.Role{}))
+	roleMgr := rolemanager.New(db)
+
+	var config roleconfig.Config
+	assert.Nil(t, yaml.Unmarshal([]byte(roleForTestOk), &config))
+	service, err := NewDBRoleService(ctx, roleMgr, config, roleconfig.StoreConfig{Database: true})
+	assert.Nil(t, err)
+
+	roles, err := service.ListRole(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, config.Roles, roles)
+	assert.Equal(t, "maintainer", service.GetDefaultRole(ctx).Name)
+	result, err := service.RoleCompare(ctx, "owner", "maintainer")
+	assert.Nil(t, err)
+	assert.Equal(t, RoleBigger, result)
+
+	// roles are only seeded once, the replica sharing the database loads the same roles
+	_, err = roleMgr.Create(ctx, &models.Role{Name: "deployer", Rank: 2, IsDefault: true})
+	assert.Nil(t, err)
+	replica, err := NewDBRoleService(ctx, roleMgr, config, roleconfig.StoreConfig{Database: true})
+	assert.Nil(t, err)
+	roles, err = replica.ListRole(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(roles))
+	assert.Equal(t, "deployer", replica.GetDefaultRole(ctx).Name)
+
+	// changes take effect after reloading
+	_, err = service.GetRole(ctx, "deployer")
+	assert.Equal(t, ErrorRoleNotFound, err)
+	assert.Nil(t, service.Reload(ctx))
+	role, err := service.GetRole(ctx, "deployer")
+	assert.Nil(t, err)
+	assert.Equal(t, "deployer", role.Name)
+	result, err = service.RoleCompare(ctx, "deployer", "maintainer")
+	assert.Nil(t, err)
+	assert.Equal(t, RoleSmaller, result)
+
+	// roles of the newer version replace the ones of the same names, and the others are kept
+	config.Version = 1
+	config.Roles[0].Desc = "owner of version 1"
+	upgraded, err := NewDBRoleService(ctx, roleMgr, config, roleconfig.StoreConfig{Database: true})
+	assert.Nil(t, err)
+	role, err = upgraded.GetRole(ctx, "owner")
+	assert.Nil(t, err)
+	assert.Equal(t, config.Roles[0], *role)
+	roles, err = upgraded.ListRole(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(roles))
+	assert.Equal(t, "maintainer", upgraded.GetDefaultRole(ctx).Name)
+
+	// and roles of the same version are not seeded again
+	config.Roles[0].Desc = "owner changed without version"
+	replica, err = NewDBRoleService(ctx, roleMgr, config, roleconfig.StoreConfig{Database: true})
+	assert.Nil(t, err)
+	role, err = replica.GetRole(ctx, "owner")
+	assert.Nil(t, err)
+	assert.Equal(t, "owner of version 1", role.Desc)
+
+	// invalid config is not seeded
+	var errConfig roleconfig.Config
+	assert.Nil(t, yaml.Unmarshal([]byte(roleForTestErr1), &errConfig))
+	_, err = NewDBRoleService(ctx, roleMgr, errConfig, roleconfig.StoreConfig{Database: true})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,83 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/pkg/rbac/role/dao"
+	"github.com/horizoncd/horizon/pkg/rbac/role/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Manager interface {
+	// Seed creates the roles if there is no role yet, or upserts them if their version
+	// is newer than the roles in database
+	Seed(ctx context.Context, roles []*models.Role) error
+	// Create creates the role, other roles are no longer default if the role is default
+	Create(ctx context.Context, role *models.Role) (*models.Role, error)
+	Get(ctx context.Context, name string) (*models.Role, error)
+	// List lists the roles in the order of priority
+	List(ctx context.Context) ([]*models.Role, error)
+	// Update updates the role, other roles are no longer default if the role is default
+	Update(ctx context.Context, role *models.Role) (*models.Role, error)
+	Delete(ctx context.Context, name string) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Seed(ctx context.Context, roles []*models.Role) error {
+	const op = "role manager: seed"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Seed(ctx, roles)
+}
+
+func (m *manager) Create(ctx context.Context, role *models.Role) (*models.Role, error) {
+	const op = "role manager: create"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Create(ctx, role)
+}
+
+func (m *manager) Get(ctx context.Context, name string) (*models.Role, error) {
+	const op = "role manager: get"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Get(ctx, name)
+}
+
+func (m *manager) List(ctx context.Context) ([]*models.Role, error) {
+	const op = "role manager: list"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.List(ctx)
+}
+
+func (m *manager) Update(ctx context.Context, role *models.Role) (*models.Role, error) {
+	const op = "role manager: update"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Update(ctx, role)
+}
+
+func (m *manager) Delete(ctx context.Context, name string) error {
+	const op = "role manager: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Delete(ctx, name)
+}
@@ -0,0 +1,35 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+// Role is a role stored in database, it takes effect in every replica after roles are reloaded
+type Role struct {
+	global.Model
+	Name        string
+	Description string
+	// Rules is the json encoded policy rules of the role
+	Rules string
+	// Rank decides the priority of the role, the smaller the higher
+	Rank      int
+	IsDefault bool
+	// Version is the version of the roles file which the role is seeded from
+	Version   uint
+	CreatedBy uint
+	UpdatedBy uint
+}
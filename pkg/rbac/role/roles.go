@@ -72,6 +72,10 @@ type fileRoleService struct {
 }
 
 func NewFileRoleFrom2(ctx context.Context, config roleconfig.Config) (Service, error) {
+	return newFileRoleService(ctx, config)
+}
+
+func newFileRoleService(ctx context.Context, config roleconfig.Config) (*fileRoleService, error) {
 	fRole := fileRoleService{
 		RolePriorityRankDesc: config.RolePriorityRankDesc,
 		Roles:                config.Roles,
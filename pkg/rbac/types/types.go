@@ -14,6 +14,11 @@
 
 package types
 
+import (
+	"fmt"
+	"strings"
+)
+
 // attention: rbac is refers to the kubernetes rbac
 // copy core struct and logics from the kubernetes code
 // and do same modify
@@ -38,3 +43,51 @@ type PolicyRule struct {
 	Scopes          []string `yaml:"scopes" json:"scopes"`
 	NonResourceURLs []string `yaml:"nonResourceURLs" json:"nonResourceURLs"`
 }
+
+// verbs are the verbs of requests, see auth.RequestInfoFactory
+var verbs = map[string]struct{}{
+	VerbAll:  {},
+	"get":    {},
+	"list":   {},
+	"create": {},
+	"update": {},
+	"patch":  {},
+	"delete": {},
+}
+
+// Validate checks the rule is able to match some requests
+func (r *PolicyRule) Validate() error {
+	if len(r.Verbs) == 0 {
+		return fmt.Errorf("verbs must not be empty")
+	}
+	for _, verb := range r.Verbs {
+		if _, ok := verbs[verb]; !ok {
+			return fmt.Errorf("invalid verb: %s", verb)
+		}
+	}
+	if len(r.Resources) == 0 && len(r.NonResourceURLs) == 0 {
+		return fmt.Errorf("either resources or nonResourceURLs must not be empty")
+	}
+	if len(r.Resources) > 0 && (len(r.APIGroups) == 0 || len(r.Scopes) == 0) {
+		return fmt.Errorf("apiGroups and scopes must not be empty when resources are specified")
+	}
+	for _, resource := range r.Resources {
+		parts := strings.Split(resource, "/")
+		if len(parts) > 2 || parts[0] == "" || (len(parts) == 2 && parts[1] == "") {
+			return fmt.Errorf("invalid resource: %s", resource)
+		}
+	}
+	for _, items := range [][]string{r.APIGroups, r.Scopes} {
+		for _, item := range items {
+			if item == "" {
+				return fmt.Errorf("apiGroups and scopes must not contain empty item")
+			}
+		}
+	}
+	for _, url := range r.NonResourceURLs {
+		if url != NonResourceAll && !strings.HasPrefix(url, "/") {
+			return fmt.Errorf("invalid nonResourceURL: %s", url)
+		}
+	}
+	return nil
+}
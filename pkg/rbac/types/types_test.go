@@ -269,3 +269,56 @@ func TestRuleAllow(t *testing.T) {
 		assert.Equal(t, RuleAllow(v.attr, &v.policy), v.allowed)
 	}
 }
+
+func TestValidate(t *testing.T) {
+	valid := []PolicyRule{
+		{
+			Verbs:     []string{"get", "list"},
+			APIGroups: []string{"core"},
+			Resources: []string{"clusters", "clusters/pipelineruns", "*/log"},
+			Scopes:    []string{"*"},
+		},
+		{
+			Verbs:           []string{"*"},
+			NonResourceURLs: []string{"*", "/apis/core/v2/roles"},
+		},
+	}
+	for _, rule := range valid {
+		assert.Nil(t, rule.Validate())
+	}
+
+	invalid := []PolicyRule{
+		{
+			APIGroups: []string{"core"},
+			Resources: []string{"clusters"},
+			Scopes:    []string{"*"},
+		},
+		{
+			Verbs:     []string{"watch"},
+			APIGroups: []string{"core"},
+			Resources: []string{"clusters"},
+			Scopes:    []string{"*"},
+		},
+		{
+			Verbs: []string{"get"},
+		},
+		{
+			Verbs:     []string{"get"},
+			Resources: []string{"clusters"},
+			Scopes:    []string{"*"},
+		},
+		{
+			Verbs:     []string{"get"},
+			APIGroups: []string{"core"},
+			Resources: []string{"clusters/"},
+			Scopes:    []string{"*"},
+		},
+		{
+			Verbs:           []string{"get"},
+			NonResourceURLs: []string{"apis"},
+		},
+	}
+	for _, rule := range invalid {
+		assert.NotNil(t, rule.Validate())
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/releasetrain/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, train *models.ReleaseTrain, stages []*models.Stage) (*models.ReleaseTrain, error)
+	Get(ctx context.Context, id uint) (*models.ReleaseTrain, error)
+	List(ctx context.Context, applicationID uint, query *q.Query) ([]*models.ReleaseTrain, int64, error)
+	ListStages(ctx context.Context, trainID uint) ([]*models.Stage, error)
+	Update(ctx context.Context, train *models.ReleaseTrain, stages []*models.Stage) (*models.ReleaseTrain, error)
+	Delete(ctx context.Context, id uint) error
+
+	CreateRun(ctx context.Context, run *models.Run, stageRuns []*models.StageRun) (*models.Run, error)
+	GetRun(ctx context.Context, id uint) (*models.Run, error)
+	ListRuns(ctx context.Context, trainID uint, query *q.Query) ([]*models.Run, int64, error)
+	ListRunsByStatus(ctx context.Context, status string) ([]*models.Run, error)
+	ListStageRuns(ctx context.Context, runID uint) ([]*models.StageRun, error)
+	UpdateRun(ctx context.Context, run *models.Run) error
+	UpdateStageRun(ctx context.Context, stageRun *models.StageRun) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, train *models.ReleaseTrain,
+	stages []*models.Stage) (*models.ReleaseTrain, error) {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(train).Error; err != nil {
+			return herrors.NewErrInsertFailed(herrors.ReleaseTrainInDB, err.Error())
+		}
+		return createStages(tx, train.ID, stages)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return train, nil
+}
+
+func createStages(tx *gorm.DB, trainID uint, stages []*models.Stage) error {
+	if len(stages) == 0 {
+		return nil
+	}
+	for i, stage := range stages {
+		stage.ReleaseTrainID = trainID
+		stage.Sequence = i
+	}
+	if err := tx.Create(stages).Error; err != nil {
+		return herrors.NewErrInsertFailed(herrors.ReleaseTrainInDB, err.Error())
+	}
+	return nil
+}
+
+func (d *dao) Get(ctx context.Context, id uint) (*models.ReleaseTrain, error) {
+	var train models.ReleaseTrain
+	if result := d.db.WithContext(ctx).First(&train, id); result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.ReleaseTrainInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.ReleaseTrainInDB, result.Error.Error())
+	}
+	return &train, nil
+}
+
+func (d *dao) List(ctx context.Context, applicationID uint,
+	query *q.Query) ([]*models.ReleaseTrain, int64, error) {
+	var (
+		trains []*models.ReleaseTrain
+		count  int64
+	)
+	statement := d.db.WithContext(ctx).Model(&models.ReleaseTrain{}).
+		Where("application_id = ?", applicationID)
+	if result := statement.Count(&count); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.ReleaseTrainInDB, result.Error.Error())
+	}
+	if query != nil {
+		statement = statement.Limit(query.Limit()).Offset(query.Offset())
+	}
+	if result := statement.Order("id desc").Find(&trains); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.ReleaseTrainInDB, result.Error.Error())
+	}
+	return trains, count, nil
+}
+
+func (d *dao) ListStages(ctx context.Context, trainID uint) ([]*models.Stage, error) {
+	var stages []*models.Stage
+	if result := d.db.WithContext(ctx).Where("release_train_id = ?", trainID).
+		Order("sequence").Find(&stages); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ReleaseTrainInDB, result.Error.Error())
+	}
+	return stages, nil
+}
+
+func (d *dao) Update(ctx context.Context, train *models.ReleaseTrain,
+	stages []*models.Stage) (*models.ReleaseTrain, error) {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", train.ID).
+			Select("name", "description", "updated_by").
+			Updates(train).Error; err != nil {
+			return herrors.NewErrUpdateFailed(herrors.ReleaseTrainInDB, err.Error())
+		}
+		if err := tx.Where("release_train_id = ?", train.ID).
+			Delete(&models.Stage{}).Error; err != nil {
+			return herrors.NewErrDeleteFailed(herrors.ReleaseTrainInDB, err.Error())
+		}
+		return createStages(tx, train.ID, stages)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d.Get(ctx, train.ID)
+}
+
+func (d *dao) Delete(ctx context.Context, id uint) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.ReleaseTrain{}, id).Error; err != nil {
+			return herrors.NewErrDeleteFailed(herrors.ReleaseTrainInDB, err.Error())
+		}
+		if err := tx.Where("release_train_id = ?", id).
+			Delete(&models.Stage{}).Error; err != nil {
+			return herrors.NewErrDeleteFailed(herrors.ReleaseTrainInDB, err.Error())
+		}
+		return nil
+	})
+}
+
+func (d *dao) CreateRun(ctx context.Context, run *models.Run,
+	stageRuns []*models.StageRun) (*models.Run, error) {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(run).Error; err != nil {
+			return herrors.NewErrInsertFailed(herrors.ReleaseTrainRunInDB, err.Error())
+		}
+		for _, stageRun := range stageRuns {
+			stageRun.RunID = run.ID
+		}
+		if err := tx.Create(stageRuns).Error; err != nil {
+			return herrors.NewErrInsertFailed(herrors.ReleaseTrainRunInDB, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (d *dao) GetRun(ctx context.Context, id uint) (*models.Run, error) {
+	var run models.Run
+	if result := d.db.WithContext(ctx).First(&run, id); result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.ReleaseTrainRunInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.ReleaseTrainRunInDB, result.Error.Error())
+	}
+	return &run, nil
+}
+
+func (d *dao) ListRuns(ctx context.Context, trainID uint, query *q.Query) ([]*models.Run, int64, error) {
+	var (
+		runs  []*models.Run
+		count int64
+	)
+	statement := d.db.WithContext(ctx).Model(&models.Run{}).
+		Where("release_train_id = ?", trainID)
+	if result := statement.Count(&count); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.ReleaseTrainRunInDB, result.Error.Error())
+	}
+	if query != nil {
+		statement = statement.Limit(query.Limit()).Offset(query.Offset())
+	}
+	if result := statement.Order("id desc").Find(&runs); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.ReleaseTrainRunInDB, result.Error.Error())
+	}
+	return runs, count, nil
+}
+
+func (d *dao) ListRunsByStatus(ctx context.Context, status string) ([]*models.Run, error) {
+	var runs []*models.Run
+	if result := d.db.WithContext(ctx).Where("status = ?", status).
+		Order("id").Find(&runs); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ReleaseTrainRunInDB, result.Error.Error())
+	}
+	return runs, nil
+}
+
+func (d *dao) ListStageRuns(ctx context.Context, runID uint) ([]*models.StageRun, error) {
+	var stageRuns []*models.StageRun
+	if result := d.db.WithContext(ctx).Where("run_id = ?", runID).
+		Order("sequence").Find(&stageRuns); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.ReleaseTrainRunInDB, result.Error.Error())
+	}
+	return stageRuns, nil
+}
+
+func (d *dao) UpdateRun(ctx context.Context, run *models.Run) error {
+	if result := d.db.WithContext(ctx).Where("id = ?", run.ID).
+		Select("status", "current_stage", "message", "updated_by").
+		Updates(run); result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.ReleaseTrainRunInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) UpdateStageRun(ctx context.Context, stageRun *models.StageRun) error {
+	if result := d.db.WithContext(ctx).Where("id = ?", stageRun.ID).
+		Select("status", "pipelinerun_ids", "approved_by", "started_at", "finished_at", "message").
+		Updates(stageRun); result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.ReleaseTrainRunInDB, result.Error.Error())
+	}
+	return nil
+}
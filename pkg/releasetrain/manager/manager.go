@@ -0,0 +1,135 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/releasetrain/dao"
+	"github.com/horizoncd/horizon/pkg/releasetrain/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Manager interface {
+	// Create creates the release train with its stages in the order given
+	Create(ctx context.Context, train *models.ReleaseTrain, stages []*models.Stage) (*models.ReleaseTrain, error)
+	Get(ctx context.Context, id uint) (*models.ReleaseTrain, error)
+	List(ctx context.Context, applicationID uint, query *q.Query) ([]*models.ReleaseTrain, int64, error)
+	ListStages(ctx context.Context, trainID uint) ([]*models.Stage, error)
+	// Update updates the release train and replaces its stages
+	Update(ctx context.Context, train *models.ReleaseTrain, stages []*models.Stage) (*models.ReleaseTrain, error)
+	Delete(ctx context.Context, id uint) error
+
+	CreateRun(ctx context.Context, run *models.Run, stageRuns []*models.StageRun) (*models.Run, error)
+	GetRun(ctx context.Context, id uint) (*models.Run, error)
+	ListRuns(ctx context.Context, trainID uint, query *q.Query) ([]*models.Run, int64, error)
+	ListRunsByStatus(ctx context.Context, status string) ([]*models.Run, error)
+	ListStageRuns(ctx context.Context, runID uint) ([]*models.StageRun, error)
+	UpdateRun(ctx context.Context, run *models.Run) error
+	UpdateStageRun(ctx context.Context, stageRun *models.StageRun) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, train *models.ReleaseTrain,
+	stages []*models.Stage) (*models.ReleaseTrain, error) {
+	const op = "release train manager: create"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Create(ctx, train, stages)
+}
+
+func (m *manager) Get(ctx context.Context, id uint) (*models.ReleaseTrain, error) {
+	const op = "release train manager: get"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Get(ctx, id)
+}
+
+func (m *manager) List(ctx context.Context, applicationID uint,
+	query *q.Query) ([]*models.ReleaseTrain, int64, error) {
+	const op = "release train manager: list"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.List(ctx, applicationID, query)
+}
+
+func (m *manager) ListStages(ctx context.Context, trainID uint) ([]*models.Stage, error) {
+	const op = "release train manager: list stages"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListStages(ctx, trainID)
+}
+
+func (m *manager) Update(ctx context.Context, train *models.ReleaseTrain,
+	stages []*models.Stage) (*models.ReleaseTrain, error) {
+	const op = "release train manager: update"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Update(ctx, train, stages)
+}
+
+func (m *manager) Delete(ctx context.Context, id uint) error {
+	const op = "release train manager: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Delete(ctx, id)
+}
+
+func (m *manager) CreateRun(ctx context.Context, run *models.Run,
+	stageRuns []*models.StageRun) (*models.Run, error) {
+	const op = "release train manager: create run"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.CreateRun(ctx, run, stageRuns)
+}
+
+func (m *manager) GetRun(ctx context.Context, id uint) (*models.Run, error) {
+	const op = "release train manager: get run"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetRun(ctx, id)
+}
+
+func (m *manager) ListRuns(ctx context.Context, trainID uint, query *q.Query) ([]*models.Run, int64, error) {
+	const op = "release train manager: list runs"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListRuns(ctx, trainID, query)
+}
+
+func (m *manager) ListRunsByStatus(ctx context.Context, status string) ([]*models.Run, error) {
+	const op = "release train manager: list runs by status"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListRunsByStatus(ctx, status)
+}
+
+func (m *manager) ListStageRuns(ctx context.Context, runID uint) ([]*models.StageRun, error) {
+	const op = "release train manager: list stage runs"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListStageRuns(ctx, runID)
+}
+
+func (m *manager) UpdateRun(ctx context.Context, run *models.Run) error {
+	const op = "release train manager: update run"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateRun(ctx, run)
+}
+
+func (m *manager) UpdateStageRun(ctx context.Context, stageRun *models.StageRun) error {
+	const op = "release train manager: update stage run"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateStageRun(ctx, stageRun)
+}
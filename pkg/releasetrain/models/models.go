@@ -0,0 +1,142 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+const (
+	RunStatusRunning   = "running"
+	RunStatusSucceeded = "succeeded"
+	RunStatusFailed    = "failed"
+	RunStatusCancelled = "cancelled"
+
+	StageStatusPending         = "pending"
+	StageStatusWaitingApproval = "waitingApproval"
+	StageStatusSoaking         = "soaking"
+	StageStatusWaitingChecks   = "waitingChecks"
+	StageStatusRunning         = "running"
+	StageStatusSucceeded       = "succeeded"
+	StageStatusFailed          = "failed"
+	StageStatusCancelled       = "cancelled"
+)
+
+// ReleaseTrain releases an application by deploying its clusters stage by stage
+type ReleaseTrain struct {
+	global.Model
+	ApplicationID uint
+	Name          string
+	Description   string
+	CreatedBy     uint
+	UpdatedBy     uint
+}
+
+// StageSpec defines the clusters of a stage and the gates to pass before they are deployed
+type StageSpec struct {
+	Name string
+	// Environment selects all the clusters of application in the environment when Clusters is empty
+	Environment string
+	// Clusters are comma separated cluster ids
+	Clusters string
+	// RequireApproval requires someone to approve before the stage starts
+	RequireApproval bool
+	// SoakTime is the seconds to wait after the previous stage succeeded
+	SoakTime uint
+}
+
+type Stage struct {
+	global.Model
+	ReleaseTrainID uint
+	// Sequence is the order of stage in the release train, starting from 0
+	Sequence int
+	StageSpec
+}
+
+func (Stage) TableName() string {
+	return "tb_release_train_stage"
+}
+
+// Run is an execution of release train, the stages are copied when it starts,
+// so later changes of the release train do not affect it.
+type Run struct {
+	global.Model
+	ReleaseTrainID uint
+	ApplicationID  uint
+	Title          string
+	Description    string
+	// Action is deploy or builddeploy
+	Action     string
+	ImageTag   string
+	GitRefType string
+	GitRef     string
+	Status     string
+	// CurrentStage is the sequence of the stage in progress
+	CurrentStage int
+	Message      string
+	CreatedBy    uint
+	UpdatedBy    uint
+}
+
+func (Run) TableName() string {
+	return "tb_release_train_run"
+}
+
+type StageRun struct {
+	global.Model
+	RunID    uint
+	Sequence int
+	StageSpec
+	Status string
+	// PipelinerunIDs are comma separated ids of the pipelineruns created for the clusters
+	PipelinerunIDs string
+	ApprovedBy     uint
+	StartedAt      *time.Time
+	FinishedAt     *time.Time
+	Message        string
+}
+
+func (StageRun) TableName() string {
+	return "tb_release_train_stage_run"
+}
+
+// ParseIDs parses the comma separated ids, invalid ones are ignored
+func ParseIDs(ids string) []uint {
+	if ids == "" {
+		return nil
+	}
+	result := make([]uint, 0)
+	for _, idStr := range strings.Split(ids, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(idStr), 10, 0)
+		if err != nil {
+			continue
+		}
+		result = append(result, uint(id))
+	}
+	return result
+}
+
+// FormatIDs formats the ids to be comma separated
+func FormatIDs(ids []uint) string {
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, strconv.FormatUint(uint64(id), 10))
+	}
+	return strings.Join(result, ",")
+}
@@ -0,0 +1,212 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/core/common"
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	membermodels "github.com/horizoncd/horizon/pkg/member/models"
+	"github.com/horizoncd/horizon/pkg/team/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, team *models.Team, owner *models.TeamMember) (*models.Team, error)
+	Get(ctx context.Context, id uint) (*models.Team, error)
+	GetByName(ctx context.Context, name string) (*models.Team, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]*models.Team, error)
+	List(ctx context.Context, query *q.Query) ([]*models.Team, int64, error)
+	Update(ctx context.Context, team *models.Team) (*models.Team, error)
+	Delete(ctx context.Context, id uint) error
+	GetMember(ctx context.Context, teamID, userID uint) (*models.TeamMember, error)
+	ListMembers(ctx context.Context, teamID uint) ([]*models.TeamMember, error)
+	UpsertMember(ctx context.Context, member *models.TeamMember) (*models.TeamMember, error)
+	DeleteMember(ctx context.Context, teamID, userID uint) error
+	ListTeamIDsOfUser(ctx context.Context, userID uint) ([]uint, error)
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, team *models.Team,
+	owner *models.TeamMember) (*models.Team, error) {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if result := tx.Create(team); result.Error != nil {
+			return herrors.NewErrInsertFailed(herrors.TeamInDB, result.Error.Error())
+		}
+		owner.TeamID = team.ID
+		if result := tx.Create(owner); result.Error != nil {
+			return herrors.NewErrInsertFailed(herrors.TeamMemberInDB, result.Error.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+func (d *dao) Get(ctx context.Context, id uint) (*models.Team, error) {
+	var team models.Team
+	if result := d.db.WithContext(ctx).First(&team, id); result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.TeamInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.TeamInDB, result.Error.Error())
+	}
+	return &team, nil
+}
+
+func (d *dao) GetByName(ctx context.Context, name string) (*models.Team, error) {
+	var team models.Team
+	if result := d.db.WithContext(ctx).Where("name = ?", name).First(&team); result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.TeamInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.TeamInDB, result.Error.Error())
+	}
+	return &team, nil
+}
+
+func (d *dao) GetByIDs(ctx context.Context, ids []uint) ([]*models.Team, error) {
+	var teams []*models.Team
+	if result := d.db.WithContext(ctx).Where("id in ?", ids).Find(&teams); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.TeamInDB, result.Error.Error())
+	}
+	return teams, nil
+}
+
+func (d *dao) List(ctx context.Context, query *q.Query) ([]*models.Team, int64, error) {
+	var (
+		teams []*models.Team
+		count int64
+	)
+	statement := d.db.WithContext(ctx).Model(&models.Team{})
+	if query != nil {
+		if v, ok := query.Keywords[common.TeamQueryName]; ok {
+			statement = statement.Where("name like ?", fmt.Sprintf("%%%v%%", v))
+		}
+		if v, ok := query.Keywords[common.TeamQueryByUser]; ok {
+			statement = statement.Where("id in (?)", d.db.Model(&models.TeamMember{}).
+				Select("team_id").Where("user_id = ?", v))
+		}
+	}
+	if result := statement.Count(&count); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.TeamInDB, result.Error.Error())
+	}
+	if query != nil {
+		statement = statement.Limit(query.Limit()).Offset(query.Offset())
+	}
+	if result := statement.Order("id desc").Find(&teams); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.TeamInDB, result.Error.Error())
+	}
+	return teams, count, nil
+}
+
+func (d *dao) Update(ctx context.Context, team *models.Team) (*models.Team, error) {
+	if result := d.db.WithContext(ctx).Where("id = ?", team.ID).
+		Select("name", "description", "updated_by").
+		Updates(team); result.Error != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.TeamInDB, result.Error.Error())
+	}
+	return d.Get(ctx, team.ID)
+}
+
+// Delete deletes the team together with its users and the member entries it is bound to
+func (d *dao) Delete(ctx context.Context, id uint) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if result := tx.Where("member_type = ? and membername_id = ?", membermodels.MemberGroup, id).
+			Delete(&membermodels.Member{}); result.Error != nil {
+			return herrors.NewErrDeleteFailed(herrors.MemberInfoInDB, result.Error.Error())
+		}
+		if result := tx.Where("team_id = ?", id).Delete(&models.TeamMember{}); result.Error != nil {
+			return herrors.NewErrDeleteFailed(herrors.TeamMemberInDB, result.Error.Error())
+		}
+		if result := tx.Delete(&models.Team{}, id); result.Error != nil {
+			return herrors.NewErrDeleteFailed(herrors.TeamInDB, result.Error.Error())
+		}
+		return nil
+	})
+}
+
+func (d *dao) GetMember(ctx context.Context, teamID, userID uint) (*models.TeamMember, error) {
+	var member models.TeamMember
+	if result := d.db.WithContext(ctx).Where("team_id = ? and user_id = ?", teamID, userID).
+		First(&member); result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.TeamMemberInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.TeamMemberInDB, result.Error.Error())
+	}
+	return &member, nil
+}
+
+func (d *dao) ListMembers(ctx context.Context, teamID uint) ([]*models.TeamMember, error) {
+	var members []*models.TeamMember
+	if result := d.db.WithContext(ctx).Where("team_id = ?", teamID).
+		Order("id").Find(&members); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.TeamMemberInDB, result.Error.Error())
+	}
+	return members, nil
+}
+
+// UpsertMember adds the user to the team, or updates the role if the user is already in it
+func (d *dao) UpsertMember(ctx context.Context, member *models.TeamMember) (*models.TeamMember, error) {
+	var existing models.TeamMember
+	result := d.db.WithContext(ctx).Where("team_id = ? and user_id = ?", member.TeamID, member.UserID).
+		Limit(1).Find(&existing)
+	if result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.TeamMemberInDB, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		if result := d.db.WithContext(ctx).Create(member); result.Error != nil {
+			return nil, herrors.NewErrInsertFailed(herrors.TeamMemberInDB, result.Error.Error())
+		}
+		return member, nil
+	}
+	member.ID = existing.ID
+	if result := d.db.WithContext(ctx).Where("id = ?", existing.ID).
+		Select("role", "updated_by").Updates(member); result.Error != nil {
+		return nil, herrors.NewErrUpdateFailed(herrors.TeamMemberInDB, result.Error.Error())
+	}
+	return d.GetMember(ctx, member.TeamID, member.UserID)
+}
+
+func (d *dao) DeleteMember(ctx context.Context, teamID, userID uint) error {
+	if result := d.db.WithContext(ctx).Where("team_id = ? and user_id = ?", teamID, userID).
+		Delete(&models.TeamMember{}); result.Error != nil {
+		return herrors.NewErrDeleteFailed(herrors.TeamMemberInDB, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *dao) ListTeamIDsOfUser(ctx context.Context, userID uint) ([]uint, error) {
+	var ids []uint
+	if result := d.db.WithContext(ctx).Model(&models.TeamMember{}).
+		Where("user_id = ?", userID).Pluck("team_id", &ids); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.TeamMemberInDB, result.Error.Error())
+	}
+	return ids, nil
+}
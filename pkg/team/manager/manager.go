@@ -0,0 +1,126 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/team/dao"
+	"github.com/horizoncd/horizon/pkg/team/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Manager interface {
+	// Create creates the team with its first owner
+	Create(ctx context.Context, team *models.Team, owner *models.TeamMember) (*models.Team, error)
+	Get(ctx context.Context, id uint) (*models.Team, error)
+	GetByName(ctx context.Context, name string) (*models.Team, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]*models.Team, error)
+	List(ctx context.Context, query *q.Query) ([]*models.Team, int64, error)
+	Update(ctx context.Context, team *models.Team) (*models.Team, error)
+	// Delete deletes the team, its users and all the member entries of the team
+	Delete(ctx context.Context, id uint) error
+	GetMember(ctx context.Context, teamID, userID uint) (*models.TeamMember, error)
+	ListMembers(ctx context.Context, teamID uint) ([]*models.TeamMember, error)
+	// UpsertMember adds the user to the team or updates the user's role in the team
+	UpsertMember(ctx context.Context, member *models.TeamMember) (*models.TeamMember, error)
+	DeleteMember(ctx context.Context, teamID, userID uint) error
+	// ListTeamIDsOfUser lists the ids of the teams which the user belongs to
+	ListTeamIDsOfUser(ctx context.Context, userID uint) ([]uint, error)
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, team *models.Team,
+	owner *models.TeamMember) (*models.Team, error) {
+	const op = "team manager: create"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Create(ctx, team, owner)
+}
+
+func (m *manager) Get(ctx context.Context, id uint) (*models.Team, error) {
+	const op = "team manager: get"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Get(ctx, id)
+}
+
+func (m *manager) GetByName(ctx context.Context, name string) (*models.Team, error) {
+	const op = "team manager: get by name"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetByName(ctx, name)
+}
+
+func (m *manager) GetByIDs(ctx context.Context, ids []uint) ([]*models.Team, error) {
+	const op = "team manager: get by ids"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetByIDs(ctx, ids)
+}
+
+func (m *manager) List(ctx context.Context, query *q.Query) ([]*models.Team, int64, error) {
+	const op = "team manager: list"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.List(ctx, query)
+}
+
+func (m *manager) Update(ctx context.Context, team *models.Team) (*models.Team, error) {
+	const op = "team manager: update"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Update(ctx, team)
+}
+
+func (m *manager) Delete(ctx context.Context, id uint) error {
+	const op = "team manager: delete"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Delete(ctx, id)
+}
+
+func (m *manager) GetMember(ctx context.Context, teamID, userID uint) (*models.TeamMember, error) {
+	const op = "team manager: get member"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.GetMember(ctx, teamID, userID)
+}
+
+func (m *manager) ListMembers(ctx context.Context, teamID uint) ([]*models.TeamMember, error) {
+	const op = "team manager: list members"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListMembers(ctx, teamID)
+}
+
+func (m *manager) UpsertMember(ctx context.Context, member *models.TeamMember) (*models.TeamMember, error) {
+	const op = "team manager: upsert member"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpsertMember(ctx, member)
+}
+
+func (m *manager) DeleteMember(ctx context.Context, teamID, userID uint) error {
+	const op = "team manager: delete member"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.DeleteMember(ctx, teamID, userID)
+}
+
+func (m *manager) ListTeamIDsOfUser(ctx context.Context, userID uint) ([]uint, error) {
+	const op = "team manager: list team ids of user"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListTeamIDsOfUser(ctx, userID)
+}
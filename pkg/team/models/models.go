@@ -0,0 +1,45 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+const (
+	// RoleOwner can manage the team and its members
+	RoleOwner = "owner"
+	// RoleMember only belongs to the team
+	RoleMember = "member"
+)
+
+// Team is a set of users, it can be bound to a group, an application or a cluster
+// as a member of type MemberGroup, then its users inherit the role of the binding.
+type Team struct {
+	global.Model
+	Name        string
+	Description string
+	CreatedBy   uint
+	UpdatedBy   uint
+}
+
+type TeamMember struct {
+	global.Model
+	TeamID    uint
+	UserID    uint
+	Role      string
+	CreatedBy uint
+	UpdatedBy uint
+}
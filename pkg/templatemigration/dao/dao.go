@@ -0,0 +1,138 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/templatemigration/models"
+)
+
+type DAO interface {
+	Create(ctx context.Context, migration *models.Migration,
+		clusters []*models.MigrationCluster) (*models.Migration, error)
+	Get(ctx context.Context, id uint) (*models.Migration, error)
+	List(ctx context.Context, templateID uint, query *q.Query) ([]*models.Migration, int64, error)
+	ListByStatus(ctx context.Context, status string) ([]*models.Migration, error)
+	ListClusters(ctx context.Context, migrationID uint) ([]*models.MigrationCluster, error)
+	// Update updates the status of migration, only if its current status is one of from when from is given.
+	// It returns false if the migration is not in any status of from, e.g. it has been changed meanwhile.
+	Update(ctx context.Context, migration *models.Migration, from ...string) (bool, error)
+	UpdateCluster(ctx context.Context, cluster *models.MigrationCluster) error
+}
+
+type dao struct {
+	db *gorm.DB
+}
+
+func NewDAO(db *gorm.DB) DAO {
+	return &dao{db: db}
+}
+
+func (d *dao) Create(ctx context.Context, migration *models.Migration,
+	clusters []*models.MigrationCluster) (*models.Migration, error) {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(migration).Error; err != nil {
+			return herrors.NewErrInsertFailed(herrors.TemplateMigrationInDB, err.Error())
+		}
+		if len(clusters) == 0 {
+			return nil
+		}
+		for _, cluster := range clusters {
+			cluster.MigrationID = migration.ID
+		}
+		if err := tx.Create(clusters).Error; err != nil {
+			return herrors.NewErrInsertFailed(herrors.TemplateMigrationInDB, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return migration, nil
+}
+
+func (d *dao) Get(ctx context.Context, id uint) (*models.Migration, error) {
+	var migration models.Migration
+	if result := d.db.WithContext(ctx).First(&migration, id); result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, herrors.NewErrNotFound(herrors.TemplateMigrationInDB, result.Error.Error())
+		}
+		return nil, herrors.NewErrGetFailed(herrors.TemplateMigrationInDB, result.Error.Error())
+	}
+	return &migration, nil
+}
+
+func (d *dao) List(ctx context.Context, templateID uint, query *q.Query) ([]*models.Migration, int64, error) {
+	var (
+		migrations []*models.Migration
+		count      int64
+	)
+	statement := d.db.WithContext(ctx).Model(&models.Migration{}).
+		Where("template_id = ?", templateID)
+	if result := statement.Count(&count); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.TemplateMigrationInDB, result.Error.Error())
+	}
+	if query != nil {
+		statement = statement.Limit(query.Limit()).Offset(query.Offset())
+	}
+	if result := statement.Order("id desc").Find(&migrations); result.Error != nil {
+		return nil, 0, herrors.NewErrGetFailed(herrors.TemplateMigrationInDB, result.Error.Error())
+	}
+	return migrations, count, nil
+}
+
+func (d *dao) ListByStatus(ctx context.Context, status string) ([]*models.Migration, error) {
+	var migrations []*models.Migration
+	if result := d.db.WithContext(ctx).Where("status = ?", status).
+		Order("id").Find(&migrations); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.TemplateMigrationInDB, result.Error.Error())
+	}
+	return migrations, nil
+}
+
+func (d *dao) ListClusters(ctx context.Context, migrationID uint) ([]*models.MigrationCluster, error) {
+	var clusters []*models.MigrationCluster
+	if result := d.db.WithContext(ctx).Where("migration_id = ?", migrationID).
+		Order("id").Find(&clusters); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.TemplateMigrationInDB, result.Error.Error())
+	}
+	return clusters, nil
+}
+
+func (d *dao) Update(ctx context.Context, migration *models.Migration, from ...string) (bool, error) {
+	statement := d.db.WithContext(ctx).Where("id = ?", migration.ID)
+	if len(from) > 0 {
+		statement = statement.Where("status in ?", from)
+	}
+	result := statement.Select("status", "message", "updated_by").Updates(migration)
+	if result.Error != nil {
+		return false, herrors.NewErrUpdateFailed(herrors.TemplateMigrationInDB, result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (d *dao) UpdateCluster(ctx context.Context, cluster *models.MigrationCluster) error {
+	if result := d.db.WithContext(ctx).Where("id = ?", cluster.ID).
+		Select("status", "message", "finished_at").
+		Updates(cluster); result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.TemplateMigrationInDB, result.Error.Error())
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/horizoncd/horizon/lib/q"
+	"github.com/horizoncd/horizon/pkg/templatemigration/dao"
+	"github.com/horizoncd/horizon/pkg/templatemigration/models"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+type Manager interface {
+	// Create creates the migration with the clusters selected to migrate
+	Create(ctx context.Context, migration *models.Migration,
+		clusters []*models.MigrationCluster) (*models.Migration, error)
+	Get(ctx context.Context, id uint) (*models.Migration, error)
+	List(ctx context.Context, templateID uint, query *q.Query) ([]*models.Migration, int64, error)
+	ListByStatus(ctx context.Context, status string) ([]*models.Migration, error)
+	ListClusters(ctx context.Context, migrationID uint) ([]*models.MigrationCluster, error)
+	// Update updates the status of migration, only if its current status is one of from when from is given.
+	// It returns false if the migration is not in any status of from, e.g. it has been changed meanwhile.
+	Update(ctx context.Context, migration *models.Migration, from ...string) (bool, error)
+	UpdateCluster(ctx context.Context, cluster *models.MigrationCluster) error
+}
+
+type manager struct {
+	dao dao.DAO
+}
+
+func New(db *gorm.DB) Manager {
+	return &manager{dao: dao.NewDAO(db)}
+}
+
+func (m *manager) Create(ctx context.Context, migration *models.Migration,
+	clusters []*models.MigrationCluster) (*models.Migration, error) {
+	const op = "template migration manager: create"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Create(ctx, migration, clusters)
+}
+
+func (m *manager) Get(ctx context.Context, id uint) (*models.Migration, error) {
+	const op = "template migration manager: get"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Get(ctx, id)
+}
+
+func (m *manager) List(ctx context.Context, templateID uint,
+	query *q.Query) ([]*models.Migration, int64, error) {
+	const op = "template migration manager: list"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.List(ctx, templateID, query)
+}
+
+func (m *manager) ListByStatus(ctx context.Context, status string) ([]*models.Migration, error) {
+	const op = "template migration manager: list by status"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListByStatus(ctx, status)
+}
+
+func (m *manager) ListClusters(ctx context.Context, migrationID uint) ([]*models.MigrationCluster, error) {
+	const op = "template migration manager: list clusters"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.ListClusters(ctx, migrationID)
+}
+
+func (m *manager) Update(ctx context.Context, migration *models.Migration, from ...string) (bool, error) {
+	const op = "template migration manager: update"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.Update(ctx, migration, from...)
+}
+
+func (m *manager) UpdateCluster(ctx context.Context, cluster *models.MigrationCluster) error {
+	const op = "template migration manager: update cluster"
+	defer wlog.Start(ctx, op).StopPrint()
+	return m.dao.UpdateCluster(ctx, cluster)
+}
@@ -0,0 +1,74 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/horizoncd/horizon/pkg/server/global"
+)
+
+const (
+	StatusRunning   = "running"
+	StatusPaused    = "paused"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusAborted   = "aborted"
+
+	ClusterStatusPending   = "pending"
+	ClusterStatusSucceeded = "succeeded"
+	ClusterStatusFailed    = "failed"
+	ClusterStatusAborted   = "aborted"
+)
+
+// Migration upgrades the clusters of a template to the target release batch by batch
+type Migration struct {
+	global.Model
+	TemplateID uint
+	Template   string
+	// SourceReleases is a semver constraint of the releases to migrate from,
+	// all the releases except the target one are selected if it is empty
+	SourceReleases string
+	TargetRelease  string
+	// GroupID, Environment and TagSelector select the clusters to migrate, they are ignored if empty
+	GroupID     uint
+	Environment string
+	TagSelector string
+	BatchSize   uint
+	Status      string
+	Message     string
+	CreatedBy   uint
+	UpdatedBy   uint
+}
+
+func (Migration) TableName() string {
+	return "tb_template_migration"
+}
+
+// MigrationCluster is the progress of a cluster in migration, the clusters are selected when the migration is created
+type MigrationCluster struct {
+	global.Model
+	MigrationID uint
+	ClusterID   uint
+	Cluster     string
+	FromRelease string
+	Status      string
+	Message     string
+	FinishedAt  *time.Time
+}
+
+func (MigrationCluster) TableName() string {
+	return "tb_template_migration_cluster"
+}
@@ -38,6 +38,7 @@ type DAO interface {
 	GetRefOfApplication(ctx context.Context, id uint) ([]*amodels.Application, uint, error)
 	GetRefOfCluster(ctx context.Context, id uint) ([]*cmodel.Cluster, uint, error)
 	UpdateByID(ctx context.Context, releaseID uint, release *models.TemplateRelease) error
+	UpdateSyncStatus(ctx context.Context, releaseID uint, release *models.TemplateRelease) error
 	DeleteByID(ctx context.Context, id uint) error
 }
 
@@ -156,6 +157,16 @@ func (d dao) UpdateByID(ctx context.Context, releaseID uint, release *models.Tem
 	})
 }
 
+func (d dao) UpdateSyncStatus(ctx context.Context, releaseID uint, release *models.TemplateRelease) error {
+	result := d.db.WithContext(ctx).Model(&models.TemplateRelease{}).Where("id = ?", releaseID).
+		Select("sync_status", "failed_reason", "commit_id", "chart_version", "last_sync_at").
+		Updates(release)
+	if result.Error != nil {
+		return herrors.NewErrUpdateFailed(herrors.TemplateReleaseInDB, result.Error.Error())
+	}
+	return nil
+}
+
 func (d dao) DeleteByID(ctx context.Context, id uint) error {
 	if res := d.db.Exec(common.TemplateReleaseDelete, id); res.Error != nil {
 		return perror.Wrap(herrors.NewErrDeleteFailed(herrors.TemplateInDB, res.Error.Error()),
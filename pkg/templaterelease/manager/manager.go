@@ -42,6 +42,9 @@ type Manager interface {
 	GetRefOfApplication(ctx context.Context, id uint) ([]*amodels.Application, uint, error)
 	GetRefOfCluster(ctx context.Context, id uint) ([]*cmodel.Cluster, uint, error)
 	UpdateByID(ctx context.Context, releaseID uint, release *models.TemplateRelease) error
+	// UpdateSyncStatus updates the sync status, failed reason, commit, chart version and last sync time of release,
+	// zero values are updated as well, which are ignored by UpdateByID
+	UpdateSyncStatus(ctx context.Context, releaseID uint, release *models.TemplateRelease) error
 	DeleteByID(ctx context.Context, id uint) error
 }
 
@@ -93,6 +96,10 @@ func (m *manager) UpdateByID(ctx context.Context, releaseID uint, release *model
 	return m.dao.UpdateByID(ctx, releaseID, release)
 }
 
+func (m *manager) UpdateSyncStatus(ctx context.Context, releaseID uint, release *models.TemplateRelease) error {
+	return m.dao.UpdateSyncStatus(ctx, releaseID, release)
+}
+
 func (m *manager) DeleteByID(ctx context.Context, id uint) error {
 	return m.dao.DeleteByID(ctx, id)
 }
@@ -53,11 +53,15 @@ const (
 )
 
 func (s *SyncStatus) Scan(value interface{}) error {
-	bytes, ok := value.([]byte)
-	if !ok {
+	var str string
+	switch v := value.(type) {
+	case []byte:
+		str = string(v)
+	case string:
+		str = v
+	default:
 		*s = 0
 	}
-	str := string(bytes)
 
 	switch str {
 	case "status_succeed":
@@ -55,8 +55,11 @@ func RenderFiles(params map[string]string, files ...[]byte) (retFiles [][]byte,
 	for _, file := range files {
 		if file != nil {
 			var b bytes.Buffer
-			doTemplate := template.Must(template.New("").Funcs(sprig.TxtFuncMap()).Parse(string(file)))
-			err := doTemplate.ExecuteTemplate(&b, "", params)
+			doTemplate, err := template.New("").Funcs(sprig.TxtFuncMap()).Parse(string(file))
+			if err != nil {
+				return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
+			}
+			err = doTemplate.ExecuteTemplate(&b, "", params)
 			if err != nil {
 				return nil, perror.Wrap(herrors.ErrParamInvalid, err.Error())
 			}
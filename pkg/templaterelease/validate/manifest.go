@@ -0,0 +1,121 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/googleapis/gnostic/compiler"
+	openapi_v2 "github.com/googleapis/gnostic/openapiv2"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/kubectl/pkg/util/openapi"
+	"k8s.io/kubectl/pkg/util/openapi/validation"
+	"sigs.k8s.io/yaml"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+var (
+	documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+	// sourceComment is the comment added by templaterepo.RenderChart before the manifests of each template
+	sourceComment = regexp.MustCompile(`(?m)^# Source: (.+)$`)
+)
+
+// manifestValidator validates a rendered kubernetes manifest
+type manifestValidator interface {
+	ValidateBytes(data []byte) error
+}
+
+// newManifestValidator validates manifests against the OpenAPI document of kubernetes at schemaPath,
+// or decodes them into the built-in types of client-go if schemaPath is empty.
+// Kinds unknown to either of them, such as custom resources, are not validated.
+func newManifestValidator(schemaPath string) (manifestValidator, error) {
+	if schemaPath == "" {
+		// client-go may be older than the kubernetes deployed to, so the decoding is not strict,
+		// otherwise the fields added in newer kubernetes versions are rejected
+		return &typedValidator{
+			decoder: json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme.Scheme, scheme.Scheme,
+				json.SerializerOptions{Yaml: true}),
+		}, nil
+	}
+
+	data, err := ioutil.ReadFile(schemaPath)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrReadFailed,
+			"failed to read kubernetes schema %s: %v", schemaPath, err)
+	}
+	info, err := compiler.ReadInfoFromBytes(schemaPath, data)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"failed to parse kubernetes schema %s: %v", schemaPath, err)
+	}
+	doc, err := openapi_v2.NewDocument(info, compiler.NewContext("$root", nil))
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"failed to parse kubernetes schema %s: %v", schemaPath, err)
+	}
+	resources, err := openapi.NewOpenAPIData(doc)
+	if err != nil {
+		return nil, perror.Wrapf(herrors.ErrParamInvalid,
+			"failed to parse kubernetes schema %s: %v", schemaPath, err)
+	}
+	return validation.NewSchemaValidation(resources), nil
+}
+
+type typedValidator struct {
+	decoder runtime.Decoder
+}
+
+func (v *typedValidator) ValidateBytes(data []byte) error {
+	_, _, err := v.decoder.Decode(data, nil, nil)
+	if runtime.IsNotRegisteredError(err) {
+		return nil
+	}
+	return err
+}
+
+// checkManifests splits the manifests rendered by templaterepo.RenderChart into documents,
+// and checks each of them is a valid kubernetes object.
+func (v *validator) checkManifests(manifests, example string) Failures {
+	var (
+		failures Failures
+		source   string
+	)
+	for _, document := range documentSeparator.Split(manifests, -1) {
+		if match := sourceComment.FindStringSubmatch(document); match != nil {
+			source = strings.TrimSpace(match[1])
+		}
+
+		obj := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(document), &obj); err != nil {
+			failures = append(failures, &Failure{Check: CheckRender, File: source, Example: example,
+				Message: "rendered manifest is not valid yaml: " + err.Error()})
+			continue
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		if err := v.manifestValidator.ValidateBytes([]byte(document)); err != nil {
+			failures = append(failures, &Failure{Check: CheckKubernetes, File: source, Example: example,
+				Message: err.Error()})
+		}
+	}
+	return failures
+}
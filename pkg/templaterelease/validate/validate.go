@@ -0,0 +1,287 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"sigs.k8s.io/yaml"
+
+	"github.com/horizoncd/horizon/core/common"
+	"github.com/horizoncd/horizon/pkg/config/templatevalidation"
+	"github.com/horizoncd/horizon/pkg/templaterelease/schema"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"github.com/horizoncd/horizon/pkg/util/wlog"
+)
+
+// Check is a kind of check run on the chart of a template release
+type Check string
+
+const (
+	// CheckLint checks the metadata, the default values and the templates of chart like `helm lint` does
+	CheckLint Check = "lint"
+	// CheckSchema checks the json schemas and ui schemas in chart can be parsed
+	CheckSchema Check = "schema"
+	// CheckRender checks chart can be rendered with the example values in chart
+	CheckRender Check = "render"
+	// CheckKubernetes checks the rendered manifests against the kubernetes schemas
+	CheckKubernetes Check = "kubernetes"
+)
+
+const (
+	// ExamplesDir is the directory in chart holding the example values files,
+	// each of them is rendered with the chart when validating a release
+	ExamplesDir = "examples"
+	schemaDir   = "schema"
+
+	valuesFileName       = "values.yaml"
+	valuesSchemaFileName = "values.schema.json"
+	chartFileName        = "Chart.yaml"
+	templatesDir         = "templates/"
+
+	renderReleaseName = "validation"
+	renderNamespace   = "default"
+
+	// failures are saved as the failed reason of release, so both the count and the length are limited
+	maxFailures      = 20
+	maxMessageLength = 512
+)
+
+// Failure is a problem found in the chart of a template release
+type Failure struct {
+	Check Check `json:"check"`
+	// File is the file in chart which the failure is found in
+	File string `json:"file,omitempty"`
+	// Example is the example values file used to render the chart when the failure is found
+	Example string `json:"example,omitempty"`
+	Message string `json:"message"`
+}
+
+type Failures []*Failure
+
+func (f Failures) String() string {
+	messages := make([]string, 0, len(f))
+	for _, failure := range f {
+		message := fmt.Sprintf("[%s]", failure.Check)
+		if failure.Example != "" {
+			message += fmt.Sprintf(" example %s:", failure.Example)
+		}
+		if failure.File != "" {
+			message += fmt.Sprintf(" %s:", failure.File)
+		}
+		messages = append(messages, fmt.Sprintf("%s %s", message, failure.Message))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Encode encodes failures as json, which is saved as the failed reason of release
+func (f Failures) Encode() string {
+	data, _ := json.Marshal(f)
+	return string(data)
+}
+
+// DecodeFailures decodes failures from the failed reason of release,
+// nil is returned if the release did not fail in validation.
+func DecodeFailures(failedReason string) Failures {
+	if !strings.HasPrefix(failedReason, "[") {
+		return nil
+	}
+	var failures Failures
+	if err := json.Unmarshal([]byte(failedReason), &failures); err != nil {
+		return nil
+	}
+	return failures
+}
+
+// Validator validates the chart of template release before it's uploaded to template repo
+type Validator interface {
+	// Validate runs all the checks on chart, and returns the failures found
+	Validate(ctx context.Context, chrt *chart.Chart) Failures
+}
+
+type validator struct {
+	manifestValidator manifestValidator
+}
+
+var _ Validator = (*validator)(nil)
+
+// noopValidator is used when validation is disabled
+type noopValidator struct{}
+
+func (noopValidator) Validate(context.Context, *chart.Chart) Failures {
+	return nil
+}
+
+func NewValidator(config templatevalidation.Config) (Validator, error) {
+	if !config.Enabled {
+		return noopValidator{}, nil
+	}
+	manifestValidator, err := newManifestValidator(config.KubernetesSchemaPath)
+	if err != nil {
+		return nil, err
+	}
+	return &validator{manifestValidator: manifestValidator}, nil
+}
+
+func (v *validator) Validate(ctx context.Context, chrt *chart.Chart) Failures {
+	const op = "template release validator: validate"
+	defer wlog.Start(ctx, op).StopPrint()
+
+	manifests, failure := v.lint(chrt)
+	if failure != nil {
+		// the other checks make no sense if chart is broken
+		return limit(Failures{failure})
+	}
+	failures := v.checkManifests(manifests, "")
+	failures = append(failures, v.checkSchemas(chrt)...)
+	failures = append(failures, v.checkExamples(chrt)...)
+	return limit(failures)
+}
+
+// lint checks the metadata, validates the default values against values.schema.json,
+// and renders the chart with the default values.
+func (v *validator) lint(chrt *chart.Chart) (string, *Failure) {
+	if err := chrt.Metadata.Validate(); err != nil {
+		return "", &Failure{Check: CheckLint, File: chartFileName, Message: err.Error()}
+	}
+	if err := chartutil.ValidateAgainstSchema(chrt, chrt.Values); err != nil {
+		return "", &Failure{Check: CheckLint, File: valuesFileName, Message: err.Error()}
+	}
+	manifests, err := templaterepo.RenderChart(chrt, injectedValues(), renderReleaseName, renderNamespace)
+	if err != nil {
+		return "", &Failure{Check: CheckLint, File: templatesDir, Message: err.Error()}
+	}
+	return manifests, nil
+}
+
+// checkSchemas parses the json schemas and ui schemas under the schema directory,
+// the same way as they are parsed when users create applications and clusters.
+func (v *validator) checkSchemas(chrt *chart.Chart) Failures {
+	var failures Failures
+	for _, file := range sortedFiles(chrt, schemaDir, ".json") {
+		files, err := schema.RenderFiles(nil, file.Data)
+		if err == nil {
+			var s map[string]interface{}
+			err = json.Unmarshal(files[0], &s)
+		}
+		if err != nil {
+			failures = append(failures, &Failure{Check: CheckSchema, File: file.Name, Message: err.Error()})
+		}
+	}
+	return failures
+}
+
+// checkExamples renders the chart with every example values file, and checks the rendered manifests.
+func (v *validator) checkExamples(chrt *chart.Chart) Failures {
+	var failures Failures
+	for _, file := range sortedFiles(chrt, ExamplesDir, ".yaml", ".yml") {
+		values := map[string]interface{}{}
+		if err := yaml.Unmarshal(file.Data, &values); err != nil {
+			failures = append(failures, &Failure{Check: CheckRender, Example: file.Name,
+				Message: fmt.Sprintf("failed to parse values: %v", err)})
+			continue
+		}
+		coalesced, err := chartutil.CoalesceValues(chrt, values)
+		if err == nil {
+			err = chartutil.ValidateAgainstSchema(chrt, coalesced)
+		}
+		if err != nil {
+			failures = append(failures, &Failure{Check: CheckRender, File: valuesSchemaFileName,
+				Example: file.Name, Message: err.Error()})
+			continue
+		}
+		manifests, err := templaterepo.RenderChart(chrt, chartutil.CoalesceTables(values, injectedValues()),
+			renderReleaseName, renderNamespace)
+		if err != nil {
+			failures = append(failures, &Failure{Check: CheckRender, Example: file.Name, Message: err.Error()})
+			continue
+		}
+		failures = append(failures, v.checkManifests(manifests, file.Name)...)
+	}
+	return failures
+}
+
+// injectedValues returns the stubs of the values injected by horizon when clusters are deployed,
+// which are not in the default values of chart, but are usually referenced by templates.
+// See the values assembled in pkg/cluster/gitrepo.
+func injectedValues() map[string]interface{} {
+	return map[string]interface{}{
+		common.GitopsBaseValueNamespace: map[string]interface{}{
+			"application": "validation",
+			"clusterID":   1,
+			"cluster":     "validation",
+			"template": map[string]interface{}{
+				"name":    "validation",
+				"release": "v0.0.1",
+			},
+			"priority": "P0",
+		},
+		common.GitopsEnvValueNamespace: map[string]interface{}{
+			"environment":   "validation",
+			"region":        "validation",
+			"namespace":     renderNamespace,
+			"baseRegistry":  "registry.example.com",
+			"ingressDomain": "example.com",
+		},
+		common.GitopsKeyTags: map[string]interface{}{},
+		// pipeline output
+		"image": "registry.example.com/validation/validation:v0.0.1",
+		"git": map[string]interface{}{
+			"url":      "https://git.example.com/validation/validation.git",
+			"commitID": "0000000000000000000000000000000000000000",
+			"branch":   "master",
+		},
+	}
+}
+
+func sortedFiles(chrt *chart.Chart, dir string, exts ...string) []*chart.File {
+	var files []*chart.File
+	for _, file := range chrt.Files {
+		if path.Dir(file.Name) != dir {
+			continue
+		}
+		for _, ext := range exts {
+			if path.Ext(file.Name) == ext {
+				files = append(files, file)
+				break
+			}
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	return files
+}
+
+func limit(failures Failures) Failures {
+	if len(failures) > maxFailures {
+		omitted := len(failures) - maxFailures + 1
+		failures = append(failures[:maxFailures-1], &Failure{Check: failures[maxFailures-1].Check,
+			Message: fmt.Sprintf("%d more failures are omitted", omitted)})
+	}
+	for _, failure := range failures {
+		if len(failure.Message) > maxMessageLength {
+			failure.Message = failure.Message[:maxMessageLength] + "..."
+		}
+	}
+	return failures
+}
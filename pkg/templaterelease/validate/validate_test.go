@@ -0,0 +1,175 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+
+	"github.com/horizoncd/horizon/pkg/config/templatevalidation"
+)
+
+func newChart() *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "javaapp", Version: "v1.0.0-abcdef"},
+		Values:   map[string]interface{}{"replicas": 1, "image": "nginx"},
+		Templates: []*chart.File{
+			{
+				Name: "templates/deployment.yaml",
+				Data: []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+spec:
+  replicas: {{ .Values.replicas }}
+  selector:
+    matchLabels:
+      app: {{ .Release.Name }}
+  template:
+    metadata:
+      labels:
+        app: {{ .Release.Name }}
+    spec:
+      containers:
+      - name: app
+        image: {{ .Values.image }}
+---
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: {{ .Release.Name }}
+spec:
+  anything: goes`),
+			},
+			{Name: "templates/NOTES.txt", Data: []byte(`notes`)},
+		},
+		Files: []*chart.File{
+			{Name: "schema/application.schema.json", Data: []byte(
+				`{"type": "object", "properties": {"clusterID": {"default": "{{ .clusterID }}"}}}`)},
+			{Name: "schema/application.ui.schema.json", Data: []byte(`{}`)},
+			{Name: "examples/default.yaml", Data: []byte(`replicas: 2`)},
+			{Name: "README.md", Data: []byte(`readme`)},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+	v, err := NewValidator(templatevalidation.Config{Enabled: true})
+	assert.Nil(t, err)
+
+	assert.Empty(t, v.Validate(ctx, newChart()))
+
+	// nothing is validated if validation is disabled
+	noop, err := NewValidator(templatevalidation.Config{})
+	assert.Nil(t, err)
+	chrt := newChart()
+	chrt.Metadata.Name = ""
+	assert.Empty(t, noop.Validate(ctx, chrt))
+
+	// the values injected by horizon are stubbed
+	chrt = newChart()
+	chrt.Templates = append(chrt.Templates, &chart.File{Name: "templates/configmap.yaml",
+		Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Values.horizon.cluster }}
+  namespace: {{ .Values.env.namespace }}
+data:
+  template: {{ .Values.horizon.template.name | quote }}
+  commit: {{ .Values.git.commitID | quote }}`)})
+	assert.Empty(t, v.Validate(ctx, chrt))
+
+	// chart metadata is invalid
+	chrt = newChart()
+	chrt.Metadata.Name = ""
+	failures := v.Validate(ctx, chrt)
+	assert.Equal(t, 1, len(failures))
+	assert.Equal(t, CheckLint, failures[0].Check)
+	assert.Equal(t, chartFileName, failures[0].File)
+
+	// templates cannot be rendered
+	chrt = newChart()
+	chrt.Templates[0].Data = []byte(`kind: {{ .Values.kind `)
+	failures = v.Validate(ctx, chrt)
+	assert.Equal(t, 1, len(failures))
+	assert.Equal(t, CheckLint, failures[0].Check)
+
+	// schema is not valid json
+	chrt = newChart()
+	chrt.Files[1].Data = []byte(`{"type": `)
+	failures = v.Validate(ctx, chrt)
+	assert.Equal(t, 1, len(failures))
+	assert.Equal(t, &Failure{Check: CheckSchema, File: "schema/application.ui.schema.json",
+		Message: failures[0].Message}, failures[0])
+
+	// example values produce invalid manifests
+	chrt = newChart()
+	chrt.Files = append(chrt.Files, &chart.File{Name: "examples/invalid.yaml", Data: []byte(`replicas: two`)})
+	failures = v.Validate(ctx, chrt)
+	assert.Equal(t, 1, len(failures))
+	assert.Equal(t, CheckKubernetes, failures[0].Check)
+	assert.Equal(t, "javaapp/templates/deployment.yaml", failures[0].File)
+	assert.Equal(t, "examples/invalid.yaml", failures[0].Example)
+
+	// fields of built-in kinds in wrong types are reported, while unknown fields are not
+	chrt = newChart()
+	chrt.Templates[0].Data = []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Release.Name }}
+spec:
+  portz: []
+  ports: 80`)
+	failures = v.Validate(ctx, chrt)
+	assert.Equal(t, 2, len(failures))
+	assert.Equal(t, CheckKubernetes, failures[0].Check)
+	assert.Equal(t, "", failures[0].Example)
+	assert.Equal(t, "examples/default.yaml", failures[1].Example)
+
+	// the values of examples are validated against values.schema.json
+	chrt = newChart()
+	chrt.Schema = []byte(`{"properties": {"replicas": {"type": "integer", "maximum": 1}}}`)
+	failures = v.Validate(ctx, chrt)
+	assert.Equal(t, 1, len(failures))
+	assert.Equal(t, CheckRender, failures[0].Check)
+	assert.Equal(t, valuesSchemaFileName, failures[0].File)
+	assert.Equal(t, "examples/default.yaml", failures[0].Example)
+}
+
+func TestFailures(t *testing.T) {
+	failures := Failures{
+		{Check: CheckSchema, File: "schema/application.schema.json", Message: "unexpected end of JSON input"},
+		{Check: CheckKubernetes, File: "javaapp/templates/service.yaml", Example: "examples/default.yaml",
+			Message: "unknown field \"portz\""},
+	}
+	assert.Equal(t, failures, DecodeFailures(failures.Encode()))
+	assert.Nil(t, DecodeFailures("failed to load archive"))
+	assert.Equal(t, `[schema] schema/application.schema.json: unexpected end of JSON input; `+
+		`[kubernetes] example examples/default.yaml: javaapp/templates/service.yaml: unknown field "portz"`,
+		failures.String())
+
+	failures = nil
+	for i := 0; i < maxFailures+5; i++ {
+		failures = append(failures, &Failure{Check: CheckRender, Message: string(make([]byte, maxMessageLength+1))})
+	}
+	failures = limit(failures)
+	assert.Equal(t, maxFailures, len(failures))
+	assert.Equal(t, "6 more failures are omitted", failures[maxFailures-1].Message)
+	assert.Equal(t, maxMessageLength+3, len(failures[0].Message))
+}
@@ -0,0 +1,500 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	config "github.com/horizoncd/horizon/pkg/config/templaterepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"github.com/horizoncd/horizon/pkg/templaterepo"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"k8s.io/helm/pkg/tlsutil"
+)
+
+// kind of the repo storing charts as OCI artifacts in registries, such as harbor 2.x,
+// ref: https://helm.sh/docs/topics/registries/
+const kind = "oci"
+
+const (
+	mediaTypeManifest = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeConfig   = "application/vnd.cncf.helm.config.v1+json"
+	mediaTypeChart    = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	// mediaTypeLegacyChart is the media type of the charts pushed by helm before 3.7
+	mediaTypeLegacyChart = "application/tar+gzip"
+
+	headerDigest = "Docker-Content-Digest"
+	// digestSeparator separates the version and the digest of charts pinned to digests, such as v1.0.0@sha256:...
+	digestSeparator = "@"
+)
+
+var _challengeParamPattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+func init() {
+	templaterepo.Register(kind, NewRepo)
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int    `json:"size"`
+}
+
+type manifest struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType,omitempty"`
+	Config        descriptor   `json:"config"`
+	Layers        []descriptor `json:"layers"`
+}
+
+// Repo implements TemplateRepo by OCI Distribution Spec, the chart of name is stored in the repository
+// repoName/name and tagged by its version. Versions in the form of version@digest pin the charts to digests.
+type Repo struct {
+	host     *url.URL
+	repoName string
+	username string
+	password string
+	token    string
+	client   *http.Client
+	// tokens caches the bearer tokens by scope
+	tokens sync.Map
+}
+
+func NewRepo(config config.Repo) (templaterepo.TemplateRepo, error) {
+	host, err := url.Parse(config.Host)
+	if err != nil || host.Host == "" {
+		return nil, perror.Wrap(herrors.ErrParamInvalid,
+			fmt.Sprintf("url is incorrect: %v", config.Host))
+	}
+	if host.Scheme == "" || host.Scheme == kind {
+		host.Scheme = "https"
+	}
+
+	tlsConf, err := tlsutil.NewClientTLS(config.CertFile, config.KeyFile, config.CAFile)
+	if err != nil {
+		return nil, perror.Wrap(herrors.NewErrCreateFailed(herrors.TLS, err.Error()),
+			"failed to create TLS: %v")
+	}
+	tlsConf.InsecureSkipVerify = config.Insecure
+
+	return &Repo{
+		host:     host,
+		repoName: strings.Trim(config.RepoName, "/"),
+		username: config.Username,
+		password: config.Password,
+		token:    config.Token,
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: tlsConf,
+			},
+		},
+	}, nil
+}
+
+func (r *Repo) GetLoc() string {
+	return fmt.Sprintf("%s://%s", kind, path.Join(r.host.Host, r.repoName))
+}
+
+func (r *Repo) UploadChart(chartPkg *chart.Chart) error {
+	var buf bytes.Buffer
+	if err := templaterepo.ChartSerialize(chartPkg, &buf); err != nil {
+		return err
+	}
+	configBlob, err := json.Marshal(chartPkg.Metadata)
+	if err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid,
+			fmt.Sprintf("failed to marshal chart metadata: %v", err))
+	}
+
+	repository := r.repository(chartPkg.Metadata.Name)
+	configDesc, err := r.uploadBlob(repository, mediaTypeConfig, configBlob)
+	if err != nil {
+		return err
+	}
+	chartDesc, err := r.uploadBlob(repository, mediaTypeChart, buf.Bytes())
+	if err != nil {
+		return err
+	}
+	manifestBlob, err := json.Marshal(&manifest{
+		SchemaVersion: 2,
+		MediaType:     mediaTypeManifest,
+		Config:        *configDesc,
+		Layers:        []descriptor{*chartDesc},
+	})
+	if err != nil {
+		return perror.Wrap(herrors.ErrParamInvalid,
+			fmt.Sprintf("failed to marshal manifest: %v", err))
+	}
+
+	resp, err := r.do(http.MethodPut, repository, r.link(repository, "manifests", tag(chartPkg.Metadata.Version)),
+		manifestBlob, http.Header{"Content-Type": []string{mediaTypeManifest}})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusCreated {
+		return unexpected(resp)
+	}
+	return nil
+}
+
+func (r *Repo) DeleteChart(name string, version string) error {
+	repository := r.repository(name)
+	digest, err := r.resolve(repository, version)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.do(http.MethodDelete, repository, r.link(repository, "manifests", digest), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return unexpected(resp)
+	}
+	return nil
+}
+
+func (r *Repo) ExistChart(name string, version string) (bool, error) {
+	repository := r.repository(name)
+	resp, err := r.do(http.MethodHead, repository, r.link(repository, "manifests", reference(version)), nil,
+		http.Header{"Accept": []string{mediaTypeManifest}})
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, unexpected(resp)
+	}
+}
+
+func (r *Repo) GetChart(name string, version string, lastSyncAt time.Time) (*chart.Chart, error) {
+	repository := r.repository(name)
+	m, err := r.getManifest(repository, version)
+	if err != nil {
+		return nil, err
+	}
+	var chartDesc *descriptor
+	for i, layer := range m.Layers {
+		if layer.MediaType == mediaTypeChart || layer.MediaType == mediaTypeLegacyChart {
+			chartDesc = &m.Layers[i]
+			break
+		}
+	}
+	if chartDesc == nil {
+		return nil, perror.Wrapf(herrors.ErrLoadChartArchive,
+			"no chart in the artifact of %s:%s", repository, version)
+	}
+
+	resp, err := r.do(http.MethodGet, repository, r.link(repository, "blobs", chartDesc.Digest), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, unexpected(resp)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrReadFailed,
+			fmt.Sprintf("failed to read response: %v", err))
+	}
+	if digestOf(b) != chartDesc.Digest {
+		return nil, perror.Wrapf(herrors.ErrLoadChartArchive,
+			"digest of chart %s:%s mismatches, expected %s", repository, version, chartDesc.Digest)
+	}
+	chartPackage, err := loader.LoadArchive(bytes.NewReader(b))
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrLoadChartArchive,
+			fmt.Sprintf("failed to load archive: %v", err))
+	}
+	// a digest may be pinned to another version by mistake
+	if v := strings.Split(version, digestSeparator)[0]; v != "" && chartPackage.Metadata.Version != v {
+		return nil, perror.Wrapf(herrors.ErrLoadChartArchive,
+			"version of chart %s is %s, expected %s", repository, chartPackage.Metadata.Version, v)
+	}
+	return chartPackage, nil
+}
+
+func (r *Repo) getManifest(repository, version string) (*manifest, error) {
+	resp, err := r.do(http.MethodGet, repository, r.link(repository, "manifests", reference(version)), nil,
+		http.Header{"Accept": []string{mediaTypeManifest}})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, perror.Wrap(herrors.NewErrNotFound(herrors.TemplateReleaseInRepo,
+			fmt.Sprintf("%s: %s:%s", resp.Status, repository, version)), "not found")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, unexpected(resp)
+	}
+	var m manifest
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, perror.Wrap(herrors.ErrParamInvalid,
+			fmt.Sprintf("could not unmarshal manifest: %v", err))
+	}
+	return &m, nil
+}
+
+// resolve returns the digest of the manifest of version
+func (r *Repo) resolve(repository, version string) (string, error) {
+	if parts := strings.SplitN(version, digestSeparator, 2); len(parts) == 2 {
+		return parts[1], nil
+	}
+	resp, err := r.do(http.MethodHead, repository, r.link(repository, "manifests", tag(version)), nil,
+		http.Header{"Accept": []string{mediaTypeManifest}})
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", herrors.NewErrNotFound(herrors.TemplateReleaseInRepo,
+			fmt.Sprintf("%s: %s:%s", resp.Status, repository, version))
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(headerDigest) == "" {
+		return "", unexpected(resp)
+	}
+	return resp.Header.Get(headerDigest), nil
+}
+
+// uploadBlob uploads the blob monolithically unless it exists already
+func (r *Repo) uploadBlob(repository, mediaType string, blob []byte) (*descriptor, error) {
+	desc := &descriptor{MediaType: mediaType, Digest: digestOf(blob), Size: len(blob)}
+	resp, err := r.do(http.MethodHead, repository, r.link(repository, "blobs", desc.Digest), nil)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return desc, nil
+	}
+
+	resp, err = r.do(http.MethodPost, repository, r.link(repository, "blobs", "uploads")+"/", nil)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return nil, unexpected(resp)
+	}
+	location, err := r.host.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRespNotAsExpected,
+			fmt.Sprintf("upload location is incorrect: %v", err))
+	}
+	query := location.Query()
+	query.Set("digest", desc.Digest)
+	location.RawQuery = query.Encode()
+
+	resp, err = r.do(http.MethodPut, repository, location.String(), blob,
+		http.Header{"Content-Type": []string{"application/octet-stream"}})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusCreated {
+		return nil, unexpected(resp)
+	}
+	return desc, nil
+}
+
+// do sends the request to the repository, and authorizes it by the challenge of registry if unauthorized
+func (r *Repo) do(method, repository, link string, body []byte, headers ...http.Header) (*http.Response, error) {
+	scope := fmt.Sprintf("repository:%s:pull", repository)
+	if method != http.MethodGet && method != http.MethodHead {
+		scope += ",push"
+		if method == http.MethodDelete {
+			scope = fmt.Sprintf("repository:%s:delete", repository)
+		}
+	}
+
+	var authorization string
+	if r.token != "" {
+		authorization = "Bearer " + r.token
+	} else if token, ok := r.tokens.Load(scope); ok {
+		authorization = token.(string)
+	}
+	resp, err := r.send(method, link, body, authorization, headers...)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || r.token != "" {
+		return resp, err
+	}
+	_ = resp.Body.Close()
+
+	authorization, err = r.authorize(resp.Header.Get("WWW-Authenticate"), scope)
+	if err != nil {
+		return nil, err
+	}
+	r.tokens.Store(scope, authorization)
+	return r.send(method, link, body, authorization, headers...)
+}
+
+func (r *Repo) send(method, link string, body []byte, authorization string,
+	headers ...http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(method, link, bytes.NewReader(body))
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRequestFailed,
+			fmt.Sprintf("failed to create request: %v", err))
+	}
+	for _, header := range headers {
+		for k, values := range header {
+			for _, v := range values {
+				req.Header.Add(k, v)
+			}
+		}
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, perror.Wrap(herrors.ErrHTTPRequestFailed,
+			fmt.Sprintf("failed to create send request: %v", err))
+	}
+	return resp, nil
+}
+
+// authorize returns the authorization answering the challenge, the bearer token is requested from the realm,
+// ref: https://docs.docker.com/registry/spec/auth/token/
+func (r *Repo) authorize(challenge, scope string) (string, error) {
+	scheme := strings.SplitN(challenge, " ", 2)[0]
+	if strings.EqualFold(scheme, "Basic") {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(r.username+":"+r.password)), nil
+	}
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", perror.Wrap(herrors.ErrHTTPRespNotAsExpected,
+			fmt.Sprintf("unsupported challenge: %s", challenge))
+	}
+
+	params := make(map[string]string)
+	for _, match := range _challengeParamPattern.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", perror.Wrap(herrors.ErrHTTPRespNotAsExpected,
+			fmt.Sprintf("realm of challenge is incorrect: %s", challenge))
+	}
+	if params["scope"] != "" {
+		scope = params["scope"]
+	}
+	query := realm.Query()
+	query.Set("service", params["service"])
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", perror.Wrap(herrors.ErrHTTPRequestFailed,
+			fmt.Sprintf("failed to create request: %v", err))
+	}
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", perror.Wrap(herrors.ErrHTTPRequestFailed,
+			fmt.Sprintf("failed to request token: %v", err))
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return "", unexpected(resp)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", perror.Wrap(herrors.ErrParamInvalid,
+			fmt.Sprintf("could not unmarshal token: %v", err))
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	return "Bearer " + token.Token, nil
+}
+
+func (r *Repo) repository(name string) string {
+	return path.Join(r.repoName, name)
+}
+
+func (r *Repo) link(repository, resource, reference string) string {
+	return fmt.Sprintf("%s://%s/v2/%s/%s/%s", r.host.Scheme, r.host.Host, repository, resource, reference)
+}
+
+// reference returns the digest of pinned version, or the tag of version
+func reference(version string) string {
+	if parts := strings.SplitN(version, digestSeparator, 2); len(parts) == 2 {
+		return parts[1]
+	}
+	return tag(version)
+}
+
+// tag returns the tag of version, as "+" of semantic versions is not allowed in tags
+func tag(version string) string {
+	return strings.ReplaceAll(version, "+", "_")
+}
+
+func digestOf(blob []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(blob))
+}
+
+func unexpected(resp *http.Response) error {
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return perror.Wrap(herrors.ErrReadFailed,
+			fmt.Sprintf("failed to read response: %v", err))
+	}
+	return perror.Wrap(herrors.ErrHTTPRespNotAsExpected,
+		fmt.Sprintf("%s: %s", resp.Status, string(b)))
+}
@@ -0,0 +1,190 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	config "github.com/horizoncd/horizon/pkg/config/templaterepo"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// fakeRegistry stores blobs and manifests in memory, and authorizes requests by bearer tokens
+type fakeRegistry struct {
+	server    *httptest.Server
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	// tags maps repository:tag to digest of manifest
+	tags   map[string]string
+	tokens int
+}
+
+func newFakeRegistry() *fakeRegistry {
+	f := &fakeRegistry{
+		blobs:     make(map[string][]byte),
+		manifests: make(map[string][]byte),
+		tags:      make(map[string]string),
+	}
+	f.server = httptest.NewServer(f)
+	return f
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/token" {
+		if user, password, ok := r.BasicAuth(); !ok || user != "admin" || password != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.tokens++
+		_, _ = w.Write([]byte(`{"token": "token"}`))
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.Header().Set("WWW-Authenticate",
+			fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, f.server.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	body, _ := ioutil.ReadAll(r.Body)
+	resource := strings.TrimPrefix(r.URL.Path, "/v2/")
+	switch {
+	case strings.HasSuffix(resource, "/blobs/uploads/") && r.Method == http.MethodPost:
+		w.Header().Set("Location", "/v2/"+resource+"upload-id?state=1")
+		w.WriteHeader(http.StatusAccepted)
+	case strings.Contains(resource, "/blobs/uploads/") && r.Method == http.MethodPut:
+		digest := r.URL.Query().Get("digest")
+		if digest != fmt.Sprintf("sha256:%x", sha256.Sum256(body)) || r.URL.Query().Get("state") != "1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.blobs[digest] = body
+		w.WriteHeader(http.StatusCreated)
+	case strings.Contains(resource, "/blobs/"):
+		blob, ok := f.blobs[resource[strings.LastIndex(resource, "/")+1:]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(blob)
+		}
+	case strings.Contains(resource, "/manifests/"):
+		parts := strings.SplitN(resource, "/manifests/", 2)
+		repository, reference := parts[0], parts[1]
+		digest := reference
+		if !strings.HasPrefix(reference, "sha256:") {
+			digest = f.tags[repository+":"+reference]
+		}
+		switch r.Method {
+		case http.MethodPut:
+			digest = fmt.Sprintf("sha256:%x", sha256.Sum256(body))
+			f.manifests[digest] = body
+			f.tags[repository+":"+reference] = digest
+			w.WriteHeader(http.StatusCreated)
+			return
+		case http.MethodDelete:
+			for key, d := range f.tags {
+				if d == digest {
+					delete(f.tags, key)
+				}
+			}
+			delete(f.manifests, digest)
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		manifest, ok := f.manifests[digest]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set(headerDigest, digest)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(manifest)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestRepo(t *testing.T) {
+	registry := newFakeRegistry()
+	defer registry.server.Close()
+
+	repo, err := NewRepo(config.Repo{
+		Kind:     kind,
+		Host:     registry.server.URL,
+		Username: "admin",
+		Password: "password",
+		RepoName: "horizon-template",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "oci://"+strings.TrimPrefix(registry.server.URL, "http://")+"/horizon-template",
+		repo.GetLoc())
+
+	exist, err := repo.ExistChart("javaapp", "v1.0.0")
+	assert.Nil(t, err)
+	assert.False(t, exist)
+	_, err = repo.GetChart("javaapp", "v1.0.0", time.Now())
+	_, ok := perror.Cause(err).(*herrors.HorizonErrNotFound)
+	assert.True(t, ok)
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "javaapp", Version: "v1.0.0+build"},
+		Files:    []*chart.File{{Name: "README.md", Data: []byte("hello, world")}},
+	}
+	assert.Nil(t, repo.UploadChart(c))
+	assert.Equal(t, 2, len(registry.blobs))
+	// the blobs uploaded already are skipped, while the archive of chart may differ
+	// as the files in it are stamped with the time archived
+	assert.Nil(t, repo.UploadChart(c))
+	assert.LessOrEqual(t, len(registry.blobs), 3)
+	digest := registry.tags["horizon-template/javaapp:v1.0.0_build"]
+	assert.NotEmpty(t, digest)
+
+	exist, err = repo.ExistChart("javaapp", "v1.0.0+build")
+	assert.Nil(t, err)
+	assert.True(t, exist)
+	got, err := repo.GetChart("javaapp", "v1.0.0+build", time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.0.0+build", got.Metadata.Version)
+	assert.Equal(t, []byte("hello, world"), got.Files[0].Data)
+
+	// pinned to digest
+	got, err = repo.GetChart("javaapp", "v1.0.0+build@"+digest, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.0.0+build", got.Metadata.Version)
+	_, err = repo.GetChart("javaapp", "v2.0.0@"+digest, time.Now())
+	assert.Equal(t, herrors.ErrLoadChartArchive, perror.Cause(err))
+
+	assert.Nil(t, repo.DeleteChart("javaapp", "v1.0.0+build"))
+	exist, err = repo.ExistChart("javaapp", "v1.0.0+build")
+	assert.Nil(t, err)
+	assert.False(t, exist)
+
+	// tokens are cached by scope
+	assert.Equal(t, 3, registry.tokens)
+}
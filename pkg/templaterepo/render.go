@@ -0,0 +1,70 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templaterepo
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	herrors "github.com/horizoncd/horizon/core/errors"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+)
+
+const (
+	notesFileName = "NOTES.txt"
+	manifestSep   = "---\n"
+)
+
+// RenderChart renders the templates of chart with values like `helm template` does,
+// the manifests are joined in the order of their file names.
+func RenderChart(chrt *chart.Chart, values map[string]interface{},
+	releaseName, namespace string) (string, error) {
+	renderValues, err := chartutil.ToRenderValues(chrt, values, chartutil.ReleaseOptions{
+		Name:      releaseName,
+		Namespace: namespace,
+		Revision:  1,
+		IsInstall: true,
+	}, nil)
+	if err != nil {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "failed to render chart: %v", err)
+	}
+	files, err := engine.Render(chrt, renderValues)
+	if err != nil {
+		return "", perror.Wrapf(herrors.ErrParamInvalid, "failed to render chart: %v", err)
+	}
+
+	fileNames := make([]string, 0, len(files))
+	for fileName, content := range files {
+		if path.Base(fileName) == notesFileName || strings.TrimSpace(content) == "" {
+			continue
+		}
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	var builder strings.Builder
+	for _, fileName := range fileNames {
+		builder.WriteString(manifestSep)
+		builder.WriteString(fmt.Sprintf("# Source: %s\n", fileName))
+		builder.WriteString(strings.TrimSpace(files[fileName]))
+		builder.WriteString("\n")
+	}
+	return builder.String(), nil
+}
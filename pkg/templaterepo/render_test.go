@@ -0,0 +1,82 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templaterepo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestRenderChart(t *testing.T) {
+	templateChart := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "javaapp", Version: "v1.0.0"},
+		Values:   map[string]interface{}{"replicas": 1, "image": "nginx"},
+		Templates: []*chart.File{
+			{
+				Name: "templates/deployment.yaml",
+				Data: []byte(`kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+  namespace: {{ .Release.Namespace }}
+spec:
+  replicas: {{ .Values.replicas }}
+  image: {{ .Values.image }}`),
+			},
+			{
+				// functions of helm v3 are supported, lookup returns nothing when rendering without cluster
+				Name: "templates/secret.yaml",
+				Data: []byte(`{{- if not (lookup "v1" "Secret" .Release.Namespace .Release.Name) }}
+kind: Secret
+metadata:
+  name: {{ include "name" . }}
+{{- end }}`),
+			},
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{- define "name" -}}x{{- end -}}`)},
+			{Name: "templates/empty.yaml", Data: []byte(`{{- if .Values.enabled }}kind: Service{{ end }}`)},
+			{Name: "templates/NOTES.txt", Data: []byte(`notes`)},
+		},
+	}
+	clusterChart := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "cluster", Version: "1.0.0"},
+	}
+	clusterChart.AddDependency(templateChart)
+
+	manifest, err := RenderChart(clusterChart, map[string]interface{}{
+		"javaapp": map[string]interface{}{"replicas": 2},
+	}, "cluster", "test-1")
+	assert.Nil(t, err)
+	assert.Equal(t, `---
+# Source: cluster/charts/javaapp/templates/deployment.yaml
+kind: Deployment
+metadata:
+  name: cluster
+  namespace: test-1
+spec:
+  replicas: 2
+  image: nginx
+---
+# Source: cluster/charts/javaapp/templates/secret.yaml
+kind: Secret
+metadata:
+  name: x
+`, manifest)
+
+	templateChart.Templates = append(templateChart.Templates,
+		&chart.File{Name: "templates/invalid.yaml", Data: []byte(`{{ .Values.replicas`)})
+	_, err = RenderChart(clusterChart, nil, "cluster", "test-1")
+	assert.NotNil(t, err)
+}
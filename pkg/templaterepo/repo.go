@@ -15,7 +15,12 @@
 package templaterepo
 
 import (
+	"bytes"
+	"container/list"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -23,10 +28,12 @@ import (
 	"github.com/horizoncd/horizon/pkg/config/templaterepo"
 	perror "github.com/horizoncd/horizon/pkg/errors"
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
 )
 
 const (
-	cacheKeyFormat = "%s-%s"
+	cacheKeyFormat   = "%s-%s"
+	defaultCacheSize = 100
 )
 
 type Constructor func(repo templaterepo.Repo) (TemplateRepo, error)
@@ -43,7 +50,7 @@ func NewRepo(config templaterepo.Repo) (TemplateRepo, error) {
 		if err != nil {
 			return nil, err
 		}
-		return NewRepoWithCache(repo), nil
+		return NewRepoWithCache(repo, config.Cache)
 	}
 	return nil, perror.Wrapf(herrors.ErrParamInvalid, "type (%s) not implement", config.Kind)
 }
@@ -57,20 +64,39 @@ type TemplateRepo interface {
 	GetChart(name string, version string, lastSyncAt time.Time) (*chart.Chart, error)
 }
 
+// RepoWithCache caches the latest used charts in memory, the charts evicted from memory
+// are kept on disk if the cache directory is configured
 type RepoWithCache struct {
 	TemplateRepo
-	cache map[string]*ChartWithTime
-	m     sync.Mutex
+	size  int
+	dir   string
+	cache map[string]*list.Element
+	// lru orders the cached charts from the most recently used to the least
+	lru *list.List
+	m   sync.Mutex
 }
 
-func NewRepoWithCache(repo TemplateRepo) TemplateRepo {
+func NewRepoWithCache(repo TemplateRepo, config templaterepo.Cache) (TemplateRepo, error) {
+	size := config.Size
+	if size <= 0 {
+		size = defaultCacheSize
+	}
+	if config.Dir != "" {
+		if err := os.MkdirAll(config.Dir, 0755); err != nil {
+			return nil, perror.Wrapf(herrors.ErrWriteFailed, "failed to create cache dir: %v", err)
+		}
+	}
 	return &RepoWithCache{
 		TemplateRepo: repo,
-		cache:        make(map[string]*ChartWithTime),
-	}
+		size:         size,
+		dir:          config.Dir,
+		cache:        make(map[string]*list.Element),
+		lru:          list.New(),
+	}, nil
 }
 
 type ChartWithTime struct {
+	key        string
 	chartPkg   *chart.Chart
 	lastSyncAt time.Time
 }
@@ -79,19 +105,110 @@ func (r *RepoWithCache) GetChart(name string, version string, lastSyncAt time.Ti
 	r.m.Lock()
 	defer r.m.Unlock()
 	cacheKey := fmt.Sprintf(cacheKeyFormat, name, version)
-	if chartPkg, ok := r.cache[cacheKey]; ok && chartPkg != nil {
+	if elem, ok := r.cache[cacheKey]; ok {
+		chartPkg := elem.Value.(*ChartWithTime)
 		if chartPkg.lastSyncAt.Sub(lastSyncAt) >= 0 {
+			r.lru.MoveToFront(elem)
 			return chartPkg.chartPkg, nil
 		}
 	}
+	if chartPkg := r.load(cacheKey, lastSyncAt); chartPkg != nil {
+		r.add(&ChartWithTime{key: cacheKey, chartPkg: chartPkg, lastSyncAt: lastSyncAt})
+		return chartPkg, nil
+	}
 
 	chartPkg, err := r.TemplateRepo.GetChart(name, version, lastSyncAt)
 	if err != nil {
 		return nil, err
 	}
-	r.cache[cacheKey] = &ChartWithTime{
+	r.add(&ChartWithTime{
+		key:        cacheKey,
 		chartPkg:   chartPkg,
 		lastSyncAt: lastSyncAt,
-	}
+	})
+	r.store(cacheKey, chartPkg, lastSyncAt)
 	return chartPkg, err
 }
+
+func (r *RepoWithCache) UploadChart(chartPkg *chart.Chart) error {
+	r.evict(chartPkg.Metadata.Name, chartPkg.Metadata.Version)
+	return r.TemplateRepo.UploadChart(chartPkg)
+}
+
+func (r *RepoWithCache) DeleteChart(name string, version string) error {
+	r.evict(name, version)
+	return r.TemplateRepo.DeleteChart(name, version)
+}
+
+// add caches the chart in memory, and removes the least recently used one if the cache is full
+func (r *RepoWithCache) add(chartPkg *ChartWithTime) {
+	if elem, ok := r.cache[chartPkg.key]; ok {
+		elem.Value = chartPkg
+		r.lru.MoveToFront(elem)
+		return
+	}
+	r.cache[chartPkg.key] = r.lru.PushFront(chartPkg)
+	for r.lru.Len() > r.size {
+		oldest := r.lru.Back()
+		r.lru.Remove(oldest)
+		delete(r.cache, oldest.Value.(*ChartWithTime).key)
+	}
+}
+
+func (r *RepoWithCache) evict(name, version string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	cacheKey := fmt.Sprintf(cacheKeyFormat, name, version)
+	if elem, ok := r.cache[cacheKey]; ok {
+		r.lru.Remove(elem)
+		delete(r.cache, cacheKey)
+	}
+	if r.dir != "" {
+		_ = os.Remove(r.path(cacheKey))
+	}
+}
+
+func (r *RepoWithCache) path(cacheKey string) string {
+	return filepath.Join(r.dir, cacheKey+".tgz")
+}
+
+// load returns the chart cached on disk if it is synced after lastSyncAt, the modification time
+// of the archive is the time it's synced
+func (r *RepoWithCache) load(cacheKey string, lastSyncAt time.Time) *chart.Chart {
+	if r.dir == "" {
+		return nil
+	}
+	info, err := os.Stat(r.path(cacheKey))
+	if err != nil || info.ModTime().Before(lastSyncAt) {
+		return nil
+	}
+	chartPkg, err := loader.LoadFile(r.path(cacheKey))
+	if err != nil {
+		return nil
+	}
+	return chartPkg
+}
+
+// store caches the chart on disk, it's fine to fail as the chart is cached in memory
+func (r *RepoWithCache) store(cacheKey string, chartPkg *chart.Chart, lastSyncAt time.Time) {
+	if r.dir == "" {
+		return
+	}
+	var buf bytes.Buffer
+	if err := ChartSerialize(chartPkg, &buf); err != nil {
+		return
+	}
+	tmp, err := ioutil.TempFile(r.dir, cacheKey)
+	if err != nil {
+		return
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	_, err = tmp.Write(buf.Bytes())
+	if closeErr := tmp.Close(); err != nil || closeErr != nil {
+		return
+	}
+	if err := os.Chtimes(tmp.Name(), lastSyncAt, lastSyncAt); err != nil {
+		return
+	}
+	_ = os.Rename(tmp.Name(), r.path(cacheKey))
+}
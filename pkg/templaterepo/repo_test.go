@@ -0,0 +1,85 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templaterepo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+
+	"github.com/horizoncd/horizon/pkg/config/templaterepo"
+)
+
+// countingRepo returns charts of any name and version, and counts the charts got
+type countingRepo struct {
+	TemplateRepo
+	got int
+}
+
+func (r *countingRepo) GetChart(name string, version string, lastSyncAt time.Time) (*chart.Chart, error) {
+	r.got++
+	return &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: name, Version: version},
+		Files:    []*chart.File{{Name: "README.md", Data: []byte(name)}},
+	}, nil
+}
+
+func (r *countingRepo) DeleteChart(name string, version string) error {
+	return nil
+}
+
+func TestRepoWithCache(t *testing.T) {
+	remote := &countingRepo{}
+	repo, err := NewRepoWithCache(remote, templaterepo.Cache{Size: 2})
+	assert.Nil(t, err)
+	now := time.Now()
+
+	for _, name := range []string{"a", "b", "a", "c", "a"} {
+		_, err := repo.GetChart(name, "v1", now)
+		assert.Nil(t, err)
+	}
+	// b is evicted by c as a is used recently
+	assert.Equal(t, 3, remote.got)
+	_, _ = repo.GetChart("c", "v1", now)
+	assert.Equal(t, 3, remote.got)
+	_, _ = repo.GetChart("b", "v1", now)
+	assert.Equal(t, 4, remote.got)
+
+	// synced after cached
+	_, _ = repo.GetChart("b", "v1", now.Add(time.Second))
+	assert.Equal(t, 5, remote.got)
+	assert.Nil(t, repo.DeleteChart("b", "v1"))
+	_, _ = repo.GetChart("b", "v1", now.Add(time.Second))
+	assert.Equal(t, 6, remote.got)
+
+	// the charts evicted from memory are loaded from disk
+	remote = &countingRepo{}
+	repo, err = NewRepoWithCache(remote, templaterepo.Cache{Size: 1, Dir: t.TempDir()})
+	assert.Nil(t, err)
+	for _, name := range []string{"a", "b", "a"} {
+		c, err := repo.GetChart(name, "v1", now)
+		assert.Nil(t, err)
+		assert.Equal(t, []byte(name), c.Files[0].Data)
+	}
+	assert.Equal(t, 2, remote.got)
+	_, _ = repo.GetChart("a", "v1", now.Add(time.Second))
+	assert.Equal(t, 3, remote.got)
+	assert.Nil(t, repo.DeleteChart("a", "v1"))
+	_, _ = repo.GetChart("b", "v1", now)
+	_, _ = repo.GetChart("a", "v1", now)
+	assert.Equal(t, 4, remote.got)
+}
@@ -33,6 +33,18 @@ const (
 	RefreshTokenPrefix                      = "hr_"
 )
 
+// IsOauthToken tells whether the code is an access token or a refresh token issued to oauth apps,
+// rather than an authorization code or a personal access token
+func IsOauthToken(code string) bool {
+	for _, prefix := range []string{HorizonAppUserToServerAccessTokenPrefix,
+		OauthAPPAccessTokenPrefix, RefreshTokenPrefix} {
+		if strings.HasPrefix(code, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func NewAuthorizeGenerator() CodeGenerator {
 	return &authorizationCodeGenerator{}
 }
@@ -25,6 +25,11 @@ type Token struct {
 	ClientID    string `gorm:"column:client_id"`
 	RedirectURI string `gorm:"column:redirect_uri"`
 	State       string `gorm:"column:state"`
+	// PKCE challenge of the authorization code, ref: rfc7636
+	CodeChallenge       string `gorm:"column:code_challenge"`
+	CodeChallengeMethod string `gorm:"column:code_challenge_method"`
+	// Nonce of the OIDC authentication request, it is returned in the ID token
+	Nonce string `gorm:"column:nonce"`
 
 	// token basic info
 	Name string `gorm:"column:name"`
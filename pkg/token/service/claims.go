@@ -30,3 +30,12 @@ func WithPipelinerunID(pipelinerunID uint) ClaimsOption {
 		claims.PipelinerunID = &pipelinerunID
 	}
 }
+
+// IDTokenClaims ref: https://openid.net/specs/openid-connect-core-1_0.html#IDToken
+type IDTokenClaims struct {
+	Nonce             string `json:"nonce,omitempty"`
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Email             string `json:"email,omitempty"`
+	jwt.RegisteredClaims
+}
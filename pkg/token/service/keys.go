@@ -0,0 +1,116 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	herror "github.com/horizoncd/horizon/core/errors"
+	tokenconfig "github.com/horizoncd/horizon/pkg/config/token"
+	perror "github.com/horizoncd/horizon/pkg/errors"
+)
+
+// JWK ref: https://www.rfc-editor.org/rfc/rfc7517
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	// RSA public key
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC public key
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type signingKey struct {
+	id         string
+	method     jwt.SigningMethod
+	privateKey crypto.Signer
+}
+
+func loadSigningKeys(configs []tokenconfig.SigningKey) ([]*signingKey, error) {
+	keys := make([]*signingKey, 0, len(configs))
+	ids := make(map[string]struct{}, len(configs))
+	for _, config := range configs {
+		if config.KeyID == "" {
+			return nil, perror.Wrap(herror.ErrParamInvalid, "keyID of signing key is empty")
+		}
+		if _, ok := ids[config.KeyID]; ok {
+			return nil, perror.Wrapf(herror.ErrParamInvalid, "duplicate signing key %s", config.KeyID)
+		}
+		ids[config.KeyID] = struct{}{}
+
+		key := &signingKey{id: config.KeyID}
+		var err error
+		switch config.Algorithm {
+		case jwt.SigningMethodRS256.Alg():
+			key.method = jwt.SigningMethodRS256
+			key.privateKey, err = jwt.ParseRSAPrivateKeyFromPEM([]byte(config.PrivateKey))
+		case jwt.SigningMethodES256.Alg():
+			key.method = jwt.SigningMethodES256
+			var ecKey *ecdsa.PrivateKey
+			ecKey, err = jwt.ParseECPrivateKeyFromPEM([]byte(config.PrivateKey))
+			if err == nil && ecKey.Curve != elliptic.P256() {
+				return nil, perror.Wrapf(herror.ErrParamInvalid,
+					"signing key %s of ES256 must be on curve P-256", config.KeyID)
+			}
+			key.privateKey = ecKey
+		default:
+			return nil, perror.Wrapf(herror.ErrParamInvalid,
+				"unsupported algorithm %s of signing key %s", config.Algorithm, config.KeyID)
+		}
+		if err != nil {
+			return nil, perror.Wrapf(herror.ErrParamInvalid,
+				"failed to parse signing key %s, error: %s", config.KeyID, err.Error())
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (k *signingKey) jwk() JWK {
+	jwk := JWK{
+		Use:       "sig",
+		Algorithm: k.method.Alg(),
+		KeyID:     k.id,
+	}
+	switch publicKey := k.privateKey.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = "EC"
+		jwk.Curve = publicKey.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, size)))
+	}
+	return jwk
+}
@@ -36,18 +36,34 @@ type Service interface {
 		userID uint, scopes []string) (*tokenmodels.Token, error)
 	CreateJWTToken(subject string, expiresIn time.Duration, options ...ClaimsOption) (string, error)
 	ParseJWTToken(tokenStr string) (Claims, error)
+	// Issuer returns the OIDC issuer, it is empty if horizon is not an OIDC provider
+	Issuer() string
+	// JWKS returns the public keys to verify tokens signed by horizon
+	JWKS() *JWKS
+	// CreateIDToken signs an OIDC ID token, the issuer and timestamps of claims are filled by it
+	CreateIDToken(claims *IDTokenClaims) (string, error)
 }
 
-func NewService(manager *managerparam.Manager, config tokenconfig.Config) Service {
+func NewService(manager *managerparam.Manager, config tokenconfig.Config) (Service, error) {
+	signingKeys, err := loadSigningKeys(config.SigningKeys)
+	if err != nil {
+		return nil, err
+	}
+	if config.Issuer != "" && len(signingKeys) == 0 {
+		return nil, perror.Wrap(herror.ErrParamInvalid,
+			"signing keys are required when horizon acts as an OIDC provider")
+	}
 	return &service{
 		tokenManager: manager.TokenMgr,
 		TokenConfig:  config,
-	}
+		signingKeys:  signingKeys,
+	}, nil
 }
 
 type service struct {
 	tokenManager tokenmanager.Manager
 	TokenConfig  tokenconfig.Config
+	signingKeys  []*signingKey
 }
 
 func (s *service) CreateAccessToken(ctx context.Context, name, expiresAtStr string,
@@ -113,20 +129,48 @@ func (s *service) CreateJWTToken(subject string, expiresIn time.Duration, option
 		opt(claims)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.TokenConfig.JwtSigningKey))
+	if len(s.signingKeys) == 0 {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(s.TokenConfig.JwtSigningKey))
+	}
+	return s.sign(claims)
+}
+
+func (s *service) CreateIDToken(claims *IDTokenClaims) (string, error) {
+	if s.TokenConfig.Issuer == "" {
+		return "", perror.Wrap(herror.ErrParamInvalid, "horizon is not configured as an OIDC provider")
+	}
+	now := time.Now().UTC()
+	claims.Issuer = s.TokenConfig.Issuer
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(s.TokenConfig.IDTokenExpireIn))
+	return s.sign(claims)
+}
+
+// sign signs claims by the current signing key
+func (s *service) sign(claims jwt.Claims) (string, error) {
+	key := s.signingKeys[0]
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.privateKey)
+}
+
+func (s *service) Issuer() string {
+	return s.TokenConfig.Issuer
+}
+
+func (s *service) JWKS() *JWKS {
+	jwks := &JWKS{Keys: make([]JWK, 0, len(s.signingKeys))}
+	for _, key := range s.signingKeys {
+		jwks.Keys = append(jwks.Keys, key.jwk())
+	}
+	return jwks
 }
 
 // ParseJWTToken parses string and return claims
 func (s *service) ParseJWTToken(tokenStr string) (Claims, error) {
 	var claims Claims
-	_, err := jwt.ParseWithClaims(tokenStr, &claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, perror.Wrapf(herror.ErrTokenInvalid,
-				"unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.TokenConfig.JwtSigningKey), nil
-	})
+	_, err := jwt.ParseWithClaims(tokenStr, &claims, s.verificationKey)
 	if err != nil {
 		return Claims{}, err
 	}
@@ -137,3 +181,25 @@ func (s *service) ParseJWTToken(tokenStr string) (Claims, error) {
 	}
 	return claims, nil
 }
+
+// verificationKey finds the key to verify token, tokens signed by the rotated keys
+// can still be verified as long as the keys are configured
+func (s *service) verificationKey(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if s.TokenConfig.JwtSigningKey == "" {
+			return nil, perror.Wrap(herror.ErrTokenInvalid, "jwt signing key is not configured")
+		}
+		return []byte(s.TokenConfig.JwtSigningKey), nil
+	}
+	kid, _ := token.Header["kid"].(string)
+	for _, key := range s.signingKeys {
+		if key.id == kid {
+			if key.method.Alg() != token.Method.Alg() {
+				return nil, perror.Wrapf(herror.ErrTokenInvalid,
+					"unexpected signing method %v of key %s", token.Header["alg"], kid)
+			}
+			return key.privateKey.Public(), nil
+		}
+	}
+	return nil, perror.Wrapf(herror.ErrTokenInvalid, "unknown signing key: %v", token.Header["kid"])
+}
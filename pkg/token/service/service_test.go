@@ -16,12 +16,19 @@ package service
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"os"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 
@@ -60,10 +67,14 @@ func TestMain(m *testing.M) {
 
 	manager := managerparam.InitManager(db)
 	tokenManager = manager.TokenMgr
-	tokenSvc = NewService(manager, tokenconfig.Config{
+	var err error
+	tokenSvc, err = NewService(manager, tokenconfig.Config{
 		JwtSigningKey:         "UZMccEsEgXA/phl3w/OK1gZU6lhKJIswZqsyfQEPqpc=",
 		CallbackTokenExpireIn: 2 * time.Hour,
 	})
+	if err != nil {
+		panic(err)
+	}
 
 	os.Exit(m.Run())
 }
@@ -96,3 +107,98 @@ func TestService(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, aUser.GetID(), uint(userID))
 }
+
+func TestSigningKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	assert.Nil(t, err)
+	oldKey := tokenconfig.SigningKey{
+		KeyID:     "old",
+		Algorithm: "RS256",
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{
+			Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})),
+	}
+	newKey := tokenconfig.SigningKey{
+		KeyID:      "new",
+		Algorithm:  "ES256",
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER})),
+	}
+	config := tokenconfig.Config{
+		Issuer:          "https://horizon.example.com",
+		IDTokenExpireIn: time.Hour,
+		SigningKeys:     []tokenconfig.SigningKey{oldKey},
+	}
+
+	// invalid configs
+	_, err = NewService(managerparam.InitManager(db), tokenconfig.Config{Issuer: config.Issuer})
+	assert.NotNil(t, err)
+	_, err = NewService(managerparam.InitManager(db), tokenconfig.Config{
+		SigningKeys: []tokenconfig.SigningKey{{KeyID: "old", Algorithm: "ES256", PrivateKey: oldKey.PrivateKey}},
+	})
+	assert.NotNil(t, err)
+	_, err = NewService(managerparam.InitManager(db), tokenconfig.Config{
+		SigningKeys: []tokenconfig.SigningKey{oldKey, oldKey},
+	})
+	assert.NotNil(t, err)
+
+	oldSvc, err := NewService(managerparam.InitManager(db), config)
+	assert.Nil(t, err)
+	oldToken, err := oldSvc.CreateJWTToken("32", time.Hour)
+	assert.Nil(t, err)
+	claims, err := oldSvc.ParseJWTToken(oldToken)
+	assert.Nil(t, err)
+	assert.Equal(t, "32", claims.Subject)
+
+	// tokens signed by HS256 are not accepted without jwt signing key
+	hsToken, err := tokenSvc.CreateJWTToken("32", time.Hour)
+	assert.Nil(t, err)
+	_, err = oldSvc.ParseJWTToken(hsToken)
+	assert.NotNil(t, err)
+
+	// rotate keys, tokens signed by the old key are still valid
+	config.SigningKeys = []tokenconfig.SigningKey{newKey, oldKey}
+	newSvc, err := NewService(managerparam.InitManager(db), config)
+	assert.Nil(t, err)
+	_, err = newSvc.ParseJWTToken(oldToken)
+	assert.Nil(t, err)
+	newToken, err := newSvc.CreateJWTToken("32", time.Hour)
+	assert.Nil(t, err)
+	_, err = newSvc.ParseJWTToken(newToken)
+	assert.Nil(t, err)
+	_, err = oldSvc.ParseJWTToken(newToken)
+	assert.NotNil(t, err)
+
+	jwks := newSvc.JWKS()
+	assert.Equal(t, 2, len(jwks.Keys))
+	assert.Equal(t, "new", jwks.Keys[0].KeyID)
+	assert.Equal(t, "EC", jwks.Keys[0].KeyType)
+	assert.Equal(t, "P-256", jwks.Keys[0].Curve)
+	assert.Equal(t, "old", jwks.Keys[1].KeyID)
+	assert.Equal(t, "RSA", jwks.Keys[1].KeyType)
+	assert.Equal(t, "AQAB", jwks.Keys[1].E)
+
+	// ID token is verified by the published key
+	idToken, err := newSvc.CreateIDToken(&IDTokenClaims{
+		Nonce: "n-0S6_WzA2Mj",
+		Name:  "alias",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  "32",
+			Audience: jwt.ClaimStrings{"client"},
+		},
+	})
+	assert.Nil(t, err)
+	var idClaims IDTokenClaims
+	parsed, err := jwt.ParseWithClaims(idToken, &idClaims, func(token *jwt.Token) (interface{}, error) {
+		assert.Equal(t, "new", token.Header["kid"])
+		return &ecKey.PublicKey, nil
+	})
+	assert.Nil(t, err)
+	assert.True(t, parsed.Valid)
+	assert.Equal(t, "ES256", parsed.Method.Alg())
+	assert.Equal(t, config.Issuer, idClaims.Issuer)
+	assert.Equal(t, "n-0S6_WzA2Mj", idClaims.Nonce)
+	assert.True(t, idClaims.VerifyAudience("client", true))
+}
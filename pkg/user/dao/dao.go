@@ -70,6 +70,8 @@ func (d *dao) List(ctx context.Context, query *q.Query) (int64, []*models.User,
 				tx = tx.Where("name like ?", fmt.Sprintf("%%%v%%", v))
 			case corecommon.UserQueryType:
 				tx = tx.Where("user_type in ?", v)
+			case corecommon.UserQueryEmail:
+				tx = tx.Where("email = ?", v)
 			case corecommon.UserQueryID:
 				if reflect.TypeOf(v).Kind() == reflect.Slice {
 					tx = tx.Where("id in ?", v)
@@ -17,6 +17,7 @@ package dao
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -41,6 +42,7 @@ type DAO interface {
 		resources map[string][]uint) ([]*models.WebhookLogWithEventInfo, int64, error)
 	ListWebhookLogsByStatus(ctx context.Context, wID uint,
 		status string) ([]*models.WebhookLog, error)
+	ListDueWebhookLogs(ctx context.Context, wID uint, now time.Time) ([]*models.WebhookLog, error)
 	ListWebhookLogsByMap(ctx context.Context,
 		webhookEventMap map[uint][]uint) ([]*models.WebhookLog, error)
 	UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error)
@@ -130,7 +132,7 @@ func (d *dao) ListWebhookOfResources(ctx context.Context,
 func (d *dao) UpdateWebhook(ctx context.Context, id uint,
 	w *models.Webhook) (*models.Webhook, error) {
 	if result := d.db.WithContext(ctx).Where("id = ?", id).
-		Select("enabled", "url", "enable_ssl_verify", "description", "secret", "triggers").
+		Select("enabled", "url", "enable_ssl_verify", "description", "secret", "triggers", "max_attempts").
 		Updates(w); result.Error != nil {
 		return nil, herrors.NewErrUpdateFailed(herrors.WebhookInDB, result.Error.Error())
 	}
@@ -268,10 +270,24 @@ func (d *dao) ListWebhookLogsByStatus(ctx context.Context, wID uint,
 	return ws, nil
 }
 
+// ListDueWebhookLogs lists logs waiting to be sent or to be retried before now
+func (d *dao) ListDueWebhookLogs(ctx context.Context, wID uint,
+	now time.Time) ([]*models.WebhookLog, error) {
+	var ws []*models.WebhookLog
+	if result := d.db.WithContext(ctx).Where("webhook_id = ?", wID).
+		Where("status in ?", []string{models.StatusWaiting, models.StatusRetrying}).
+		Where("next_attempt_at is null or next_attempt_at <= ?", now).
+		Order("id").
+		Find(&ws); result.Error != nil {
+		return nil, herrors.NewErrGetFailed(herrors.WebhookLogInDB, result.Error.Error())
+	}
+	return ws, nil
+}
+
 func (d *dao) UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error) {
 	if result := d.db.WithContext(ctx).Where("id = ?", wl.ID).
 		Select("status", "response_headers", "response_body",
-			"status", "error_message").
+			"error_message", "attempts", "next_attempt_at").
 		Updates(wl); result.Error != nil {
 		return nil, herrors.NewErrUpdateFailed(herrors.WebhookLogInDB, result.Error.Error())
 	}
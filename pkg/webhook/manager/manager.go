@@ -16,6 +16,7 @@ package manager
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -41,6 +42,7 @@ type Manager interface {
 		webhookEventMap map[uint][]uint) ([]*models.WebhookLog, error)
 	ListWebhookLogsByStatus(ctx context.Context, wID uint,
 		status string) ([]*models.WebhookLog, error)
+	ListDueWebhookLogs(ctx context.Context, wID uint, now time.Time) ([]*models.WebhookLog, error)
 	UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error)
 	GetWebhookLog(ctx context.Context, id uint) (*models.WebhookLog, error)
 	ResendWebhook(ctx context.Context, id uint) (*models.WebhookLog, error)
@@ -130,6 +132,11 @@ func (m *manager) ListWebhookLogsByStatus(ctx context.Context, wID uint,
 	return m.dao.ListWebhookLogsByStatus(ctx, wID, status)
 }
 
+func (m *manager) ListDueWebhookLogs(ctx context.Context, wID uint,
+	now time.Time) ([]*models.WebhookLog, error) {
+	return m.dao.ListDueWebhookLogs(ctx, wID, now)
+}
+
 func (m *manager) UpdateWebhookLog(ctx context.Context, wl *models.WebhookLog) (*models.WebhookLog, error) {
 	const op = "webhook manager: update  webhook log"
 	defer wlog.Start(ctx, op).StopPrint()
@@ -20,6 +20,10 @@ const (
 	StatusWaiting = "waiting"
 	StatusSuccess = "success"
 	StatusFailed  = "failed"
+	// StatusRetrying means the last attempt failed and the log will be sent again at NextAttemptAt
+	StatusRetrying = "retrying"
+	// StatusDead means the log failed in all attempts and will not be sent again
+	StatusDead = "dead"
 )
 
 type Webhook struct {
@@ -32,6 +36,7 @@ type Webhook struct {
 	Triggers         string
 	ResourceType     string
 	ResourceID       uint
+	MaxAttempts      uint // 0 means using the default max attempts in config
 	CreatedAt        time.Time
 	CreatedBy        uint
 	UpdatedAt        time.Time
@@ -49,6 +54,8 @@ type WebhookLog struct {
 	ResponseBody    string
 	Status          string
 	ErrorMessage    string
+	Attempts        uint
+	NextAttemptAt   *time.Time
 	CreatedAt       time.Time
 	CreatedBy       uint
 	UpdatedAt       time.Time
@@ -0,0 +1,56 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"net/url"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// hostLimiters keeps a token bucket for each receiver host, so that webhooks
+// sharing a receiver are limited together
+type hostLimiters struct {
+	sync.Mutex
+	limit    rate.Limit
+	burst    int
+	limiters map[string]*rate.Limiter
+}
+
+func newHostLimiters(qps float64, burst int) *hostLimiters {
+	return &hostLimiters{
+		limit:    rate.Limit(qps),
+		burst:    burst,
+		limiters: map[string]*rate.Limiter{},
+	}
+}
+
+// get returns the limiter of the host in rawURL
+func (h *hostLimiters) get(rawURL string) *rate.Limiter {
+	host := rawURL
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	h.Lock()
+	defer h.Unlock()
+	limiter, ok := h.limiters[host]
+	if !ok {
+		limiter = rate.NewLimiter(h.limit, h.burst)
+		h.limiters[host] = limiter
+	}
+	return limiter
+}
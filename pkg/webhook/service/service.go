@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"runtime/debug"
 	"sync"
@@ -39,17 +40,31 @@ import (
 	webhookmanager "github.com/horizoncd/horizon/pkg/webhook/manager"
 	"github.com/horizoncd/horizon/pkg/webhook/models"
 	webhookmodels "github.com/horizoncd/horizon/pkg/webhook/models"
+	"github.com/horizoncd/horizon/pkg/webhook/signature"
 )
 
 type worker struct {
 	idleWaitInterval         uint
 	responseBodyTruncateSize uint
+	maxAttempts              uint
+	retryInitialInterval     time.Duration
+	retryMaxInterval         time.Duration
+	retryMultiplier          float64
+	requestTimeout           time.Duration
 
 	ctx            context.Context
 	insecureClient http.Client
 	secureClient   http.Client
 	quit           chan bool
+	notifyCh       chan struct{}
 	webhook        atomic.Value
+	limiters       *hostLimiters
+
+	// sem limits the concurrent deliveries, and inflight keeps the logs being delivered
+	sem        chan struct{}
+	inflight   map[uint]struct{}
+	inflightMu sync.Mutex
+	wg         sync.WaitGroup
 
 	webhookManager webhookmanager.Manager
 	eventManager   eventmanager.Manager
@@ -71,13 +86,17 @@ func (w *worker) getWebhook() (*models.Webhook, error) {
 type Service interface {
 	Start()
 	StopAndWait()
+	// Notify wakes up workers of the webhooks to send their new logs
+	Notify(webhookIDs ...uint)
 }
 
 type service struct {
-	config  webhookconfig.Config
-	ctx     context.Context
-	quit    chan bool
-	workers map[uint]*worker
+	config    webhookconfig.Config
+	ctx       context.Context
+	quit      chan bool
+	workers   map[uint]*worker
+	workersMu sync.RWMutex
+	limiters  *hostLimiters
 
 	webhookManager webhookmanager.Manager
 	eventManager   eventmanager.Manager
@@ -88,14 +107,28 @@ func NewService(ctx context.Context, manager *managerparam.Manager, config webho
 	return &service{
 		config:         config,
 		ctx:            ctx,
+		quit:           make(chan bool),
 		workers:        make(map[uint]*worker),
+		limiters:       newHostLimiters(config.RateLimitPerHost, int(config.RateLimitBurst)),
 		webhookManager: manager.WebhookMgr,
 		eventManager:   manager.EventMgr,
 		userManager:    manager.UserMgr,
 	}
 }
 
+func (s *service) Notify(webhookIDs ...uint) {
+	s.workersMu.RLock()
+	defer s.workersMu.RUnlock()
+	for _, id := range webhookIDs {
+		if w, ok := s.workers[id]; ok {
+			w.notify()
+		}
+	}
+}
+
 func (s *service) stopWorkersAndWait() {
+	s.workersMu.RLock()
+	defer s.workersMu.RUnlock()
 	wg := sync.WaitGroup{}
 	wg.Add(len(s.workers))
 	for _, w := range s.workers {
@@ -115,6 +148,8 @@ func (s *service) reconcileWorkers() {
 		return
 	}
 	// 2. compare and reconcile workers
+	s.workersMu.Lock()
+	defer s.workersMu.Unlock()
 	reconciled := map[uint]bool{}
 	for _, webhook := range webhooks {
 		id := webhook.ID
@@ -131,8 +166,7 @@ func (s *service) reconcileWorkers() {
 		} else {
 			// 2.2 create workers
 			s.workers[id] = newWebhookWorker(s.webhookManager, s.eventManager,
-				s.userManager, webhook, s.config.IdleWaitInterval,
-				s.config.ClientTimeout, s.config.ResponseBodyTruncateSize)
+				s.userManager, webhook, s.config, s.limiters)
 		}
 		reconciled[id] = true
 	}
@@ -181,24 +215,28 @@ func (s *service) StopAndWait() {
 
 func newWebhookWorker(webhookMgr webhookmanager.Manager,
 	eventMgr eventmanager.Manager, userMgr usermanager.Manager,
-	webhook *models.Webhook, idleWaitInterval uint,
-	clientTimeout, responseBodyTruncateSize uint) *worker {
+	webhook *models.Webhook, config webhookconfig.Config, limiters *hostLimiters) *worker {
 	ww := &worker{
-		idleWaitInterval:         idleWaitInterval,
-		responseBodyTruncateSize: responseBodyTruncateSize,
+		idleWaitInterval:         config.IdleWaitInterval,
+		responseBodyTruncateSize: config.ResponseBodyTruncateSize,
+		maxAttempts:              config.MaxAttempts,
+		retryInitialInterval:     time.Second * time.Duration(config.RetryInitialInterval),
+		retryMaxInterval:         time.Second * time.Duration(config.RetryMaxInterval),
+		retryMultiplier:          config.RetryMultiplier,
+		requestTimeout:           time.Second * time.Duration(config.ClientTimeout),
 		ctx:                      context.Background(),
 		quit:                     make(chan bool, 1),
+		notifyCh:                 make(chan struct{}, 1),
+		limiters:                 limiters,
+		sem:                      make(chan struct{}, config.WorkerConcurrency),
+		inflight:                 map[uint]struct{}{},
 		insecureClient: http.Client{
-			Timeout: time.Second * time.Duration(clientTimeout),
 			Transport: &http.Transport{
 				TLSClientConfig: &tls.Config{
 					InsecureSkipVerify: true,
 				},
 			},
 		},
-		secureClient: http.Client{
-			Timeout: time.Second * time.Duration(clientTimeout),
-		},
 		webhookManager: webhookMgr,
 		eventManager:   eventMgr,
 		userManager:    userMgr,
@@ -209,6 +247,11 @@ func newWebhookWorker(webhookMgr webhookmanager.Manager,
 }
 
 func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models.WebhookLog {
+	// 0. clean up the result of last attempt
+	wl.ErrorMessage = ""
+	wl.ResponseHeaders = ""
+	wl.ResponseBody = ""
+
 	// 1. make request and set body
 	reqBody, err := addWebhookLogID([]byte(wl.RequestData), wl.ID)
 	if err != nil {
@@ -216,7 +259,7 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 		log.Errorf(ctx, wl.ErrorMessage)
 		return wl
 	}
-	req, err := http.NewRequest(http.MethodPost, wl.URL,
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wl.URL,
 		bytes.NewBuffer(reqBody))
 	if err != nil {
 		wl.ErrorMessage = fmt.Sprintf("failed to new request, error: %+v", err)
@@ -231,17 +274,26 @@ func (w *worker) sendWebhook(ctx context.Context, wl *models.WebhookLog) *models
 		log.Errorf(ctx, wl.ErrorMessage)
 		return wl
 	}
-	req.Header = headers
-
-	// 3. send request
-	cli := w.secureClient
 	webhook, err := w.getWebhook()
 	if err != nil {
 		log.Error(ctx, err)
 		wl.ErrorMessage = err.Error()
 		return wl
 	}
+	// never send the raw secret kept in logs of old versions
+	headers.Del(wlgenerator.WebhookSecretHeader)
+	if webhook.Secret != "" {
+		signature.SetHeaders(headers, webhook.Secret, reqBody, time.Now())
+	}
+	req.Header = headers
 
+	// 3. wait for the rate limit of receiver and send request
+	if err := w.limiters.get(wl.URL).Wait(ctx); err != nil {
+		wl.ErrorMessage = fmt.Sprintf("failed to wait for rate limit, error: %+v", err)
+		log.Errorf(ctx, wl.ErrorMessage)
+		return wl
+	}
+	cli := w.secureClient
 	if !webhook.SSLVerifyEnabled {
 		cli = w.insecureClient
 	}
@@ -288,47 +340,140 @@ func (w *worker) start() {
 			log.Errorf(ctx, "webhook worker panic: %s", string(debug.Stack()))
 		}
 	}()
-L:
 	for {
 		select {
 		case <-w.quit:
-			close(w.quit)
-			break L
+			w.stopped()
+			return
 		default:
-			// TODO: set limit and find a way to avoid this invoke
-			webhook, err := w.getWebhook()
-			if err != nil {
-				log.Error(ctx, err)
-				continue
-			}
-			wls, err := w.webhookManager.ListWebhookLogsByStatus(ctx, webhook.ID,
-				webhookmodels.StatusWaiting)
-			if err != nil {
-				log.Errorf(ctx, "failed to list webhook logs of %d, error: %s", webhook.ID, err.Error())
-				continue
-			}
-			if len(wls) == 0 {
-				time.Sleep(time.Second * time.Duration(w.idleWaitInterval))
-				continue
-			}
-			for _, wl := range wls {
-				saveResult := func() {
-					if wl.ErrorMessage != "" {
-						wl.Status = webhookmodels.StatusFailed
-					} else {
-						wl.Status = webhookmodels.StatusSuccess
-					}
-					_, err := w.webhookManager.UpdateWebhookLog(ctx, wl)
-					if err != nil {
-						log.Errorf(ctx, "failed to update webhook log %d, error: %s", wl.ID, err.Error())
-					}
-				}
-
-				wl = w.sendWebhook(ctx, wl)
-				saveResult()
-			}
 		}
+		if dispatched := w.dispatchDueLogs(ctx); dispatched > 0 {
+			continue
+		}
+		// new logs are notified by event handler, and the interval is for
+		// retries and logs created in other ways, such as resending
+		select {
+		case <-w.quit:
+			w.stopped()
+			return
+		case <-w.notifyCh:
+		case <-time.After(time.Second * time.Duration(w.idleWaitInterval)):
+		}
+	}
+}
+
+func (w *worker) stopped() {
+	w.wg.Wait()
+	close(w.quit)
+}
+
+func (w *worker) notify() {
+	select {
+	case w.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchDueLogs sends the logs which are due concurrently, and returns the count of logs dispatched
+func (w *worker) dispatchDueLogs(ctx context.Context) int {
+	webhook, err := w.getWebhook()
+	if err != nil {
+		log.Error(ctx, err)
+		return 0
+	}
+	wls, err := w.webhookManager.ListDueWebhookLogs(ctx, webhook.ID, time.Now())
+	if err != nil {
+		log.Errorf(ctx, "failed to list webhook logs of %d, error: %s", webhook.ID, err.Error())
+		return 0
+	}
+	dispatched := 0
+	for _, wl := range wls {
+		if !w.startDelivery(wl.ID) {
+			continue
+		}
+		dispatched++
+		w.sem <- struct{}{}
+		w.wg.Add(1)
+		go func(wl *models.WebhookLog) {
+			defer func() {
+				<-w.sem
+				w.finishDelivery(wl.ID)
+				w.wg.Done()
+			}()
+			w.deliver(ctx, webhook, wl)
+		}(wl)
+	}
+	return dispatched
+}
+
+// deliver sends the log in request timeout and saves the result
+func (w *worker) deliver(ctx context.Context, webhook *models.Webhook, wl *models.WebhookLog) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Errorf(ctx, "webhook delivery panic: %s", string(debug.Stack()))
+		}
+	}()
+	reqCtx, cancel := context.WithTimeout(ctx, w.requestTimeout)
+	wl = w.sendWebhook(reqCtx, wl)
+	cancel()
+	w.updateAttempt(webhook, wl, time.Now())
+	if _, err := w.webhookManager.UpdateWebhookLog(ctx, wl); err != nil {
+		log.Errorf(ctx, "failed to update webhook log %d, error: %s", wl.ID, err.Error())
+	}
+}
+
+// startDelivery marks the log as inflight, returns false if it is already inflight
+func (w *worker) startDelivery(id uint) bool {
+	w.inflightMu.Lock()
+	defer w.inflightMu.Unlock()
+	if _, ok := w.inflight[id]; ok {
+		return false
+	}
+	w.inflight[id] = struct{}{}
+	return true
+}
+
+func (w *worker) finishDelivery(id uint) {
+	w.inflightMu.Lock()
+	defer w.inflightMu.Unlock()
+	delete(w.inflight, id)
+}
+
+// updateAttempt records the attempt and decides whether the log should be retried
+func (w *worker) updateAttempt(webhook *models.Webhook, wl *models.WebhookLog, now time.Time) {
+	wl.Attempts++
+	wl.NextAttemptAt = nil
+	if wl.ErrorMessage == "" {
+		wl.Status = webhookmodels.StatusSuccess
+		return
+	}
+
+	maxAttempts := w.maxAttempts
+	if webhook.MaxAttempts > 0 {
+		maxAttempts = webhook.MaxAttempts
+	}
+	switch {
+	case maxAttempts <= 1:
+		wl.Status = webhookmodels.StatusFailed
+	case wl.Attempts >= maxAttempts:
+		wl.Status = webhookmodels.StatusDead
+	default:
+		next := now.Add(w.backoff(wl.Attempts))
+		wl.Status = webhookmodels.StatusRetrying
+		wl.NextAttemptAt = &next
+	}
+}
+
+// backoff returns the wait before the next attempt, which grows exponentially with attempts
+func (w *worker) backoff(attempts uint) time.Duration {
+	if attempts == 0 {
+		attempts = 1
+	}
+	wait := float64(w.retryInitialInterval) * math.Pow(w.retryMultiplier, float64(attempts-1))
+	if w.retryMaxInterval > 0 && wait > float64(w.retryMaxInterval) {
+		return w.retryMaxInterval
 	}
+	return time.Duration(wait)
 }
 
 func (w *worker) Stop() *worker {
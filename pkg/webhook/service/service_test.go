@@ -0,0 +1,224 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/horizoncd/horizon/lib/orm"
+	webhookmanager "github.com/horizoncd/horizon/pkg/webhook/manager"
+	"github.com/horizoncd/horizon/pkg/webhook/models"
+	"github.com/horizoncd/horizon/pkg/webhook/signature"
+)
+
+func newTestWorker(t *testing.T, webhook *models.Webhook) (*worker, webhookmanager.Manager) {
+	db, err := orm.NewSqliteDB("")
+	assert.Nil(t, err)
+	assert.Nil(t, db.AutoMigrate(&models.Webhook{}, &models.WebhookLog{}))
+	mgr := webhookmanager.New(db)
+
+	webhook, err = mgr.CreateWebhook(context.Background(), webhook)
+	assert.Nil(t, err)
+
+	w := &worker{
+		responseBodyTruncateSize: 1024,
+		maxAttempts:              3,
+		retryInitialInterval:     time.Second,
+		retryMaxInterval:         time.Second * 3,
+		retryMultiplier:          2,
+		requestTimeout:           time.Second * 5,
+		ctx:                      context.Background(),
+		notifyCh:                 make(chan struct{}, 1),
+		limiters:                 newHostLimiters(100, 10),
+		sem:                      make(chan struct{}, 2),
+		inflight:                 map[uint]struct{}{},
+		webhookManager:           mgr,
+	}
+	w.setWebhook(webhook)
+	return w, mgr
+}
+
+func TestBackoff(t *testing.T) {
+	w := &worker{
+		retryInitialInterval: time.Second * 10,
+		retryMaxInterval:     time.Second * 60,
+		retryMultiplier:      2,
+	}
+	assert.Equal(t, time.Second*10, w.backoff(1))
+	assert.Equal(t, time.Second*20, w.backoff(2))
+	assert.Equal(t, time.Second*40, w.backoff(3))
+	assert.Equal(t, time.Second*60, w.backoff(4))
+	assert.Equal(t, time.Second*60, w.backoff(10))
+}
+
+func TestUpdateAttempt(t *testing.T) {
+	w := &worker{
+		maxAttempts:          3,
+		retryInitialInterval: time.Second,
+		retryMaxInterval:     time.Minute,
+		retryMultiplier:      2,
+	}
+	now := time.Now()
+
+	wl := &models.WebhookLog{ErrorMessage: "failed"}
+	w.updateAttempt(&models.Webhook{}, wl, now)
+	assert.Equal(t, models.StatusRetrying, wl.Status)
+	assert.Equal(t, uint(1), wl.Attempts)
+	assert.Equal(t, now.Add(time.Second), *wl.NextAttemptAt)
+
+	w.updateAttempt(&models.Webhook{}, wl, now)
+	assert.Equal(t, models.StatusRetrying, wl.Status)
+	assert.Equal(t, now.Add(time.Second*2), *wl.NextAttemptAt)
+
+	w.updateAttempt(&models.Webhook{}, wl, now)
+	assert.Equal(t, models.StatusDead, wl.Status)
+	assert.Nil(t, wl.NextAttemptAt)
+
+	// max attempts of webhook overrides the config, and 1 means no retry
+	wl = &models.WebhookLog{ErrorMessage: "failed"}
+	w.updateAttempt(&models.Webhook{MaxAttempts: 1}, wl, now)
+	assert.Equal(t, models.StatusFailed, wl.Status)
+
+	wl = &models.WebhookLog{}
+	w.updateAttempt(&models.Webhook{}, wl, now)
+	assert.Equal(t, models.StatusSuccess, wl.Status)
+}
+
+func TestSendDueLogs(t *testing.T) {
+	ctx := context.Background()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		if _, err := signature.VerifyRequest(r, "secret", signature.DefaultTolerance); err != nil ||
+			r.Header.Get("X-Horizon-Webhook-Secret") != "" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if requests < 2 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	w, mgr := newTestWorker(t, &models.Webhook{URL: server.URL, Enabled: true, Secret: "secret"})
+	webhook, err := w.getWebhook()
+	assert.Nil(t, err)
+	wl, err := mgr.CreateWebhookLog(ctx, &models.WebhookLog{
+		WebhookID:   webhook.ID,
+		URL:         server.URL,
+		RequestData: "{}",
+		// headers generated by old versions carry the raw secret
+		RequestHeaders: "X-Horizon-Webhook-Secret:\n    - secret\n",
+		Status:         models.StatusWaiting,
+	})
+	assert.Nil(t, err)
+
+	// the first attempt fails and the log is retried later
+	assert.Equal(t, 1, w.dispatchDueLogs(ctx))
+	w.wg.Wait()
+	wl, err = mgr.GetWebhookLog(ctx, wl.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusRetrying, wl.Status)
+	assert.Equal(t, uint(1), wl.Attempts)
+	assert.NotNil(t, wl.NextAttemptAt)
+	assert.Contains(t, wl.ErrorMessage, "503")
+
+	// nothing is due before the next attempt time
+	assert.Equal(t, 0, w.dispatchDueLogs(ctx))
+
+	// the retry succeeds and clears the previous error
+	past := time.Now().Add(-time.Second)
+	wl.NextAttemptAt = &past
+	_, err = mgr.UpdateWebhookLog(ctx, wl)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, w.dispatchDueLogs(ctx))
+	w.wg.Wait()
+	wl, err = mgr.GetWebhookLog(ctx, wl.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, models.StatusSuccess, wl.Status)
+	assert.Equal(t, uint(2), wl.Attempts)
+	assert.Nil(t, wl.NextAttemptAt)
+	assert.Empty(t, wl.ErrorMessage)
+	assert.Equal(t, 2, requests)
+}
+
+func TestDispatchConcurrently(t *testing.T) {
+	ctx := context.Background()
+	var (
+		mu      sync.Mutex
+		running int
+		peak    int
+	)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		running--
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	w, mgr := newTestWorker(t, &models.Webhook{URL: server.URL, Enabled: true})
+	webhook, err := w.getWebhook()
+	assert.Nil(t, err)
+	for i := 0; i < 4; i++ {
+		_, err := mgr.CreateWebhookLog(ctx, &models.WebhookLog{
+			WebhookID:   webhook.ID,
+			URL:         server.URL,
+			RequestData: "{}",
+			Status:      models.StatusWaiting,
+		})
+		assert.Nil(t, err)
+	}
+
+	done := make(chan int)
+	go func() { done <- w.dispatchDueLogs(ctx) }()
+	// the dispatch blocks when all slots are taken, and inflight logs are not dispatched again
+	time.Sleep(time.Millisecond * 100)
+	close(release)
+	assert.Equal(t, 4, <-done)
+	w.wg.Wait()
+	assert.Equal(t, 2, peak)
+
+	wls, err := mgr.ListWebhookLogsByStatus(ctx, webhook.ID, models.StatusSuccess)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(wls))
+	assert.Equal(t, 0, w.dispatchDueLogs(ctx))
+}
+
+func TestHostLimiters(t *testing.T) {
+	limiters := newHostLimiters(1, 1)
+	a := limiters.get("http://example.com/a")
+	assert.True(t, a == limiters.get("https://example.com/b"))
+	assert.False(t, a == limiters.get("http://example.com:8080/a"))
+
+	assert.True(t, a.Allow())
+	assert.False(t, a.Allow())
+}
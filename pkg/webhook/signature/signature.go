@@ -0,0 +1,119 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signature signs webhook deliveries of horizon and verifies them on the receiver side.
+//
+// The signature is the hex encoded HMAC-SHA256 of "<timestamp>.<body>" keyed by the webhook secret,
+// where timestamp is the unix seconds when the request is sent. A receiver can verify a request by:
+//
+//	body, err := signature.VerifyRequest(r, secret, signature.DefaultTolerance)
+//	if err != nil {
+//		w.WriteHeader(http.StatusUnauthorized)
+//		return
+//	}
+package signature
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderSignature = "X-Horizon-Webhook-Signature"
+	HeaderTimestamp = "X-Horizon-Webhook-Timestamp"
+
+	// DefaultTolerance is the max age of a request accepted by receivers
+	DefaultTolerance = 5 * time.Minute
+
+	signaturePrefix = "sha256="
+)
+
+var (
+	ErrSignatureMissing  = errors.New("webhook signature or timestamp header is missing")
+	ErrSignatureMismatch = errors.New("webhook signature does not match")
+	ErrTimestampInvalid  = errors.New("webhook timestamp is invalid")
+	ErrTimestampExpired  = errors.New("webhook timestamp is out of tolerance")
+)
+
+// Sign returns the signature of body sent at timestamp, in the form of "sha256=<hex>"
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SetHeaders signs body with the current time and sets the signature and timestamp headers
+func SetHeaders(header http.Header, secret string, body []byte, now time.Time) {
+	timestamp := now.Unix()
+	header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	header.Set(HeaderSignature, Sign(secret, timestamp, body))
+}
+
+// Verify checks the signature headers against body, requests older or newer than
+// tolerance are rejected to prevent replays, tolerance <= 0 disables the check
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	return verify(secret, header, body, tolerance, time.Now())
+}
+
+// VerifyRequest reads the body of r and verifies it, the body is returned and
+// also restored to r so that it can be read again
+func VerifyRequest(r *http.Request, secret string, tolerance time.Duration) ([]byte, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(r.Body); err != nil {
+			return nil, err
+		}
+		r.Body.Close()
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err := Verify(secret, r.Header, body, tolerance); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func verify(secret string, header http.Header, body []byte,
+	tolerance time.Duration, now time.Time) error {
+	sig := header.Get(HeaderSignature)
+	ts := header.Get(HeaderTimestamp)
+	if sig == "" || ts == "" {
+		return ErrSignatureMissing
+	}
+	timestamp, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrTimestampInvalid
+	}
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(timestamp, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrTimestampExpired
+		}
+	}
+	if !strings.HasPrefix(sig, signaturePrefix) ||
+		!hmac.Equal([]byte(sig), []byte(Sign(secret, timestamp, body))) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
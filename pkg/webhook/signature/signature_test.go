@@ -0,0 +1,73 @@
+// Copyright © 2023 Horizoncd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{"id":1}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11",
+		Sign("secret", 1700000000, []byte(`{"id":1}`)))
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	now := time.Now()
+	header := http.Header{}
+	SetHeaders(header, "secret", body, now)
+
+	assert.Nil(t, verify("secret", header, body, DefaultTolerance, now))
+	assert.Equal(t, ErrSignatureMismatch, verify("other", header, body, DefaultTolerance, now))
+	assert.Equal(t, ErrSignatureMismatch, verify("secret", header, []byte(`{"id":2}`), DefaultTolerance, now))
+
+	// replayed requests are rejected once they are out of tolerance
+	assert.Equal(t, ErrTimestampExpired,
+		verify("secret", header, body, DefaultTolerance, now.Add(DefaultTolerance+time.Second)))
+	assert.Nil(t, verify("secret", header, body, 0, now.Add(time.Hour)))
+
+	// a tampered timestamp breaks the signature
+	tampered := header.Clone()
+	tampered.Set(HeaderTimestamp, strconv.FormatInt(now.Unix()+1, 10))
+	assert.Equal(t, ErrSignatureMismatch, verify("secret", tampered, body, DefaultTolerance, now))
+
+	tampered.Set(HeaderTimestamp, "now")
+	assert.Equal(t, ErrTimestampInvalid, verify("secret", tampered, body, DefaultTolerance, now))
+	assert.Equal(t, ErrSignatureMissing, verify("secret", http.Header{}, body, DefaultTolerance, now))
+}
+
+func TestVerifyRequest(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	req, err := http.NewRequest(http.MethodPost, "http://localhost", bytes.NewReader(body))
+	assert.Nil(t, err)
+	SetHeaders(req.Header, "secret", body, time.Now())
+
+	got, err := VerifyRequest(req, "secret", DefaultTolerance)
+	assert.Nil(t, err)
+	assert.Equal(t, body, got)
+
+	// body can be read again after verification
+	again, err := ioutil.ReadAll(req.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, body, again)
+}